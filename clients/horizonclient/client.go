@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -132,6 +133,10 @@ func (c *Client) stream(
 	streamURL string,
 	handler func(data []byte) error,
 ) error {
+	if c.StreamMultiplexer != nil {
+		return c.StreamMultiplexer.stream(ctx, streamURL, handler)
+	}
+
 	su, err := url.Parse(streamURL)
 	if err != nil {
 		return errors.Wrap(err, "error parsing stream url")
@@ -514,6 +519,137 @@ func (c *Client) SubmitTransactionWithOptions(transaction *txnbuild.Transaction,
 	return c.SubmitTransactionXDR(txeBase64)
 }
 
+// SubmitTransactionXDRAsync submits a transaction represented as a base64 XDR
+// string to the network without waiting for it to be included in a ledger.
+// The response reports the stellar-core status of the submission: PENDING,
+// DUPLICATE, TRY_AGAIN_LATER or ERROR. err is a horizon.Error object when
+// horizon rejected the request before submitting it to stellar-core.
+func (c *Client) SubmitTransactionXDRAsync(transactionXdr string) (resp hProtocol.AsyncTransactionSubmissionResponse,
+	err error) {
+	request := submitRequest{endpoint: "transactions_async", transactionXdr: transactionXdr}
+	req, err := request.HTTPRequest(c.fixHorizonURL())
+	if err != nil {
+		return
+	}
+	err = c.sendAsyncSubmitRequest(req, &resp)
+	return
+}
+
+// sendAsyncSubmitRequest sends an asynchronous submission to horizon. The
+// submission responses are returned with a non 2xx status code when
+// stellar-core did not accept the transaction, so they are decoded before
+// falling back to decodeResponse.
+func (c *Client) sendAsyncSubmitRequest(req *http.Request, resp *hProtocol.AsyncTransactionSubmissionResponse) error {
+	c.setClientAppHeaders(req)
+	c.setDefaultClient()
+
+	if c.horizonTimeout == 0 {
+		c.horizonTimeout = HorizonTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.horizonTimeout)
+	defer cancel()
+
+	httpResp, err := c.HTTP.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(httpResp.Body)
+	httpResp.Body.Close()
+	if err != nil {
+		return errors.Wrap(err, "error reading response")
+	}
+
+	if json.Unmarshal(body, resp) == nil && resp.TxStatus != "" {
+		resp.HTTPStatus = httpResp.StatusCode
+		return nil
+	}
+	*resp = hProtocol.AsyncTransactionSubmissionResponse{}
+	httpResp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return decodeResponse(httpResp, resp, c)
+}
+
+// SubmitTransactionAsync submits a transaction to the network without waiting
+// for it to be included in a ledger. err can be either an error object or a
+// horizon.Error object.
+//
+// This function will always check if the destination account requires a memo in the transaction as
+// defined in SEP0029: https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0029.md
+//
+// If you want to skip this check, use SubmitTransactionWithOptionsAsync.
+func (c *Client) SubmitTransactionAsync(transaction *txnbuild.Transaction) (resp hProtocol.AsyncTransactionSubmissionResponse, err error) {
+	return c.SubmitTransactionWithOptionsAsync(transaction, SubmitTxOpts{})
+}
+
+// SubmitTransactionWithOptionsAsync submits a transaction to the network
+// without waiting for it to be included in a ledger, allowing you to pass
+// SubmitTxOpts. err can be either an error object or a horizon.Error object.
+func (c *Client) SubmitTransactionWithOptionsAsync(transaction *txnbuild.Transaction, opts SubmitTxOpts) (resp hProtocol.AsyncTransactionSubmissionResponse, err error) {
+	// only check if memo is required if skip is false and the transaction
+	// doesn't have a memo.
+	if !opts.SkipMemoRequiredCheck && transaction.Memo() == nil {
+		err = c.checkMemoRequired(transaction)
+		if err != nil {
+			return
+		}
+	}
+
+	txeBase64, err := transaction.Base64()
+	if err != nil {
+		err = errors.Wrap(err, "Unable to convert transaction object to base64 string")
+		return
+	}
+
+	return c.SubmitTransactionXDRAsync(txeBase64)
+}
+
+// SubmitFeeBumpTransactionAsync submits a fee bump transaction to the network
+// without waiting for it to be included in a ledger. err can be either an
+// error object or a horizon.Error object.
+//
+// This function will always check if the destination account requires a memo in the transaction as
+// defined in SEP0029: https://github.com/stellar/stellar-protocol/blob/master/ecosystem/sep-0029.md
+//
+// If you want to skip this check, use SubmitFeeBumpTransactionWithOptionsAsync.
+func (c *Client) SubmitFeeBumpTransactionAsync(transaction *txnbuild.FeeBumpTransaction) (resp hProtocol.AsyncTransactionSubmissionResponse, err error) {
+	return c.SubmitFeeBumpTransactionWithOptionsAsync(transaction, SubmitTxOpts{})
+}
+
+// SubmitFeeBumpTransactionWithOptionsAsync submits a fee bump transaction to
+// the network without waiting for it to be included in a ledger, allowing you
+// to pass SubmitTxOpts. err can be either an error object or a horizon.Error
+// object.
+func (c *Client) SubmitFeeBumpTransactionWithOptionsAsync(transaction *txnbuild.FeeBumpTransaction, opts SubmitTxOpts) (resp hProtocol.AsyncTransactionSubmissionResponse, err error) {
+	// only check if memo is required if skip is false and the inner transaction
+	// doesn't have a memo.
+	if inner := transaction.InnerTransaction(); !opts.SkipMemoRequiredCheck && inner.Memo() == nil {
+		err = c.checkMemoRequired(inner)
+		if err != nil {
+			return
+		}
+	}
+
+	txeBase64, err := transaction.Base64()
+	if err != nil {
+		err = errors.Wrap(err, "Unable to convert transaction object to base64 string")
+		return
+	}
+
+	return c.SubmitTransactionXDRAsync(txeBase64)
+}
+
+// AsyncTransactionStatus returns the status of a transaction submitted with
+// one of the asynchronous submission methods: PENDING until it is included in
+// a ledger, then SUCCESS or FAILED. A horizon.Error with a 404 status code is
+// returned when horizon does not know the transaction.
+func (c *Client) AsyncTransactionStatus(txHash string) (status hProtocol.AsyncTransactionStatus, err error) {
+	if txHash == "" {
+		return status, errors.New("no transaction hash provided")
+	}
+
+	err = c.sendGetRequest(c.fixHorizonURL()+"transactions_async/"+url.PathEscape(txHash), &status)
+	return
+}
+
 // Transactions returns stellar transactions (https://developers.stellar.org/api/resources/transactions/list/)
 // It can be used to return transactions for an account, a ledger,and all transactions on the network.
 func (c *Client) Transactions(request TransactionRequest) (txs hProtocol.TransactionsPage, err error) {
@@ -841,5 +977,32 @@ func (c *Client) PrevLiquidityPoolsPage(page hProtocol.LiquidityPoolsPage) (lp h
 	return
 }
 
+// CoinInCirculation returns coin in circulation records.
+func (c *Client) CoinInCirculation(request CoinInCirculationRequest) (records hProtocol.KinesisCoinInCirculationRecordsPage, err error) {
+	err = c.sendRequest(request, &records)
+	return
+}
+
+// NextCoinInCirculationPage returns the next page of coin in circulation records.
+func (c *Client) NextCoinInCirculationPage(page hProtocol.KinesisCoinInCirculationRecordsPage) (records hProtocol.KinesisCoinInCirculationRecordsPage, err error) {
+	err = c.sendGetRequest(page.Links.Next.Href, &records)
+	return
+}
+
+// PrevCoinInCirculationPage returns the previous page of coin in circulation records.
+func (c *Client) PrevCoinInCirculationPage(page hProtocol.KinesisCoinInCirculationRecordsPage) (records hProtocol.KinesisCoinInCirculationRecordsPage, err error) {
+	err = c.sendGetRequest(page.Links.Prev.Href, &records)
+	return
+}
+
+// StreamCoinInCirculation streams coin in circulation records. A record is
+// received whenever a ledger with mints or redemptions closes, for interval
+// resolutions the record of the current interval is received again with the
+// updated amounts. Use context.WithCancel to stop streaming or
+// context.Background() if you want to stream indefinitely.
+func (c *Client) StreamCoinInCirculation(ctx context.Context, request CoinInCirculationRequest, handler CoinInCirculationHandler) error {
+	return request.StreamCoinInCirculation(ctx, c, handler)
+}
+
 // ensure that the horizon client implements ClientInterface
 var _ ClientInterface = &Client{}
@@ -0,0 +1,74 @@
+package horizonclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+)
+
+// BuildURL creates the endpoint to be queried based on the data in the CoinInCirculationRequest struct.
+func (cr CoinInCirculationRequest) BuildURL() (endpoint string, err error) {
+	endpoint = "coin_in_circulation/records"
+
+	paramMap := make(map[string]string)
+	if !cr.From.IsZero() {
+		paramMap["from"] = cr.From.UTC().Format(time.RFC3339)
+	}
+	if !cr.To.IsZero() {
+		paramMap["to"] = cr.To.UTC().Format(time.RFC3339)
+	}
+	paramMap["resolution"] = string(cr.Resolution)
+
+	queryParams := addQueryParams(paramMap, cursor(cr.Cursor), limit(cr.Limit), cr.Order)
+	if queryParams != "" {
+		endpoint = fmt.Sprintf("%s?%s", endpoint, queryParams)
+	}
+
+	_, err = url.Parse(endpoint)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse endpoint")
+	}
+
+	return endpoint, err
+}
+
+// HTTPRequest returns the http request for the coin in circulation endpoint
+func (cr CoinInCirculationRequest) HTTPRequest(horizonURL string) (*http.Request, error) {
+	endpoint, err := cr.BuildURL()
+	if err != nil {
+		return nil, err
+	}
+
+	return http.NewRequest("GET", horizonURL+endpoint, nil)
+}
+
+// CoinInCirculationHandler is a function that is called when a coin in circulation record is received
+type CoinInCirculationHandler func(hProtocol.KinesisCoinInCirculationRecord)
+
+// StreamCoinInCirculation streams coin in circulation records. Use context.WithCancel
+// to stop streaming or context.Background() if you want to stream indefinitely.
+// CoinInCirculationHandler is a user-supplied function that is executed for each streamed record received.
+func (cr CoinInCirculationRequest) StreamCoinInCirculation(ctx context.Context, client *Client,
+	handler CoinInCirculationHandler) (err error) {
+	endpoint, err := cr.BuildURL()
+	if err != nil {
+		return errors.Wrap(err, "unable to build endpoint for coin in circulation request")
+	}
+
+	url := fmt.Sprintf("%s%s", client.fixHorizonURL(), endpoint)
+	return client.stream(ctx, url, func(data []byte) error {
+		var record hProtocol.KinesisCoinInCirculationRecord
+		err = json.Unmarshal(data, &record)
+		if err != nil {
+			return errors.Wrap(err, "error unmarshaling data for coin in circulation request")
+		}
+		handler(record)
+		return nil
+	})
+}
@@ -0,0 +1,143 @@
+package horizonclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/http/httptest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoinInCirculationRequestBuildUrl(t *testing.T) {
+	cr := CoinInCirculationRequest{}
+	endpoint, err := cr.BuildURL()
+
+	// It should return valid coin in circulation endpoint and no errors
+	require.NoError(t, err)
+	assert.Equal(t, "coin_in_circulation/records", endpoint)
+
+	cr = CoinInCirculationRequest{
+		From:       time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		To:         time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+		Resolution: CoinInCirculationWeek,
+		Cursor:     "now",
+		Order:      OrderDesc,
+		Limit:      20,
+	}
+	endpoint, err = cr.BuildURL()
+
+	// It should return valid coin in circulation endpoint with all the parameters
+	require.NoError(t, err)
+	assert.Equal(t, "coin_in_circulation/records?cursor=now&from=2021-06-01T00%3A00%3A00Z&limit=20&order=desc&resolution=week&to=2021-07-01T00%3A00%3A00Z", endpoint)
+}
+
+func TestCoinInCirculation(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/coin_in_circulation/records?limit=1&resolution=day",
+	).ReturnString(200, coinInCirculationPage)
+
+	records, err := client.CoinInCirculation(CoinInCirculationRequest{Resolution: CoinInCirculationDay, Limit: 1})
+	if assert.NoError(t, err) && assert.Len(t, records.Embedded.Records, 1) {
+		record := records.Embedded.Records[0]
+		assert.Equal(t, "85899345920", record.PagingToken())
+		assert.Equal(t, uint32(20), record.Ledger)
+		assert.Equal(t, "70.0000000", record.Circulation)
+	}
+
+	hmock.On(
+		"GET",
+		"https://localhost/coin_in_circulation/records?cursor=85899345920&limit=1&order=asc&resolution=day",
+	).ReturnString(200, emptyCoinInCirculationPage)
+
+	nextPage, err := client.NextCoinInCirculationPage(records)
+	if assert.NoError(t, err) {
+		assert.Len(t, nextPage.Embedded.Records, 0)
+	}
+}
+
+func TestStreamCoinInCirculation(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	hmock.On(
+		"GET",
+		"https://localhost/coin_in_circulation/records?cursor=now&resolution=ledger",
+	).ReturnString(200, coinInCirculationStreamResponse)
+
+	var records []hProtocol.KinesisCoinInCirculationRecord
+	err := client.StreamCoinInCirculation(ctx, CoinInCirculationRequest{
+		Resolution: CoinInCirculationLedger,
+		Cursor:     "now",
+	}, func(record hProtocol.KinesisCoinInCirculationRecord) {
+		records = append(records, record)
+		cancel()
+	})
+
+	if assert.NoError(t, err) && assert.Len(t, records, 1) {
+		assert.Equal(t, uint32(30), records[0].Ledger)
+		assert.Equal(t, "120.0000000", records[0].Circulation)
+	}
+}
+
+var coinInCirculationPage = `{
+  "_links": {
+    "self": {
+      "href": "https://localhost/coin_in_circulation/records?cursor=&limit=1&order=asc&resolution=day"
+    },
+    "next": {
+      "href": "https://localhost/coin_in_circulation/records?cursor=85899345920&limit=1&order=asc&resolution=day"
+    },
+    "prev": {
+      "href": "https://localhost/coin_in_circulation/records?cursor=85899345920&limit=1&order=desc&resolution=day"
+    }
+  },
+  "_embedded": {
+    "records": [
+      {
+        "paging_token": "85899345920",
+        "resolution": "day",
+        "timestamp": "2021-06-01T00:00:00Z",
+        "ledger": 20,
+        "mint": "100.0000000",
+        "redemption": "30.0000000",
+        "total_mint": "100.0000000",
+        "total_redemption": "30.0000000",
+        "circulation": "70.0000000"
+      }
+    ]
+  }
+}`
+
+var emptyCoinInCirculationPage = `{
+  "_links": {
+    "self": {
+      "href": "https://localhost/coin_in_circulation/records?cursor=85899345920&limit=1&order=asc&resolution=day"
+    },
+    "next": {
+      "href": "https://localhost/coin_in_circulation/records?cursor=85899345920&limit=1&order=asc&resolution=day"
+    },
+    "prev": {
+      "href": "https://localhost/coin_in_circulation/records?cursor=85899345920&limit=1&order=desc&resolution=day"
+    }
+  },
+  "_embedded": {
+    "records": []
+  }
+}`
+
+var coinInCirculationStreamResponse = `data: {"paging_token":"128849018880","resolution":"ledger","timestamp":"2021-06-02T01:00:00Z","ledger":30,"mint":"50.0000000","redemption":"0.0000000","total_mint":"150.0000000","total_redemption":"30.0000000","circulation":"120.0000000"}
+`
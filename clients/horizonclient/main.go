@@ -147,6 +147,10 @@ type Client struct {
 
 	// clock is a Clock returning the current time.
 	clock *clock.Clock
+
+	// StreamMultiplexer, when set, multiplexes the streams of the client over
+	// a single WebSocket connection, see NewStreamMultiplexer.
+	StreamMultiplexer *StreamMultiplexer
 }
 
 // SubmitTxOpts represents the submit transaction options
@@ -173,6 +177,12 @@ type ClientInterface interface {
 	SubmitTransactionWithOptions(transaction *txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.Transaction, error)
 	SubmitFeeBumpTransaction(transaction *txnbuild.FeeBumpTransaction) (hProtocol.Transaction, error)
 	SubmitTransaction(transaction *txnbuild.Transaction) (hProtocol.Transaction, error)
+	SubmitTransactionXDRAsync(transactionXdr string) (hProtocol.AsyncTransactionSubmissionResponse, error)
+	SubmitFeeBumpTransactionWithOptionsAsync(transaction *txnbuild.FeeBumpTransaction, opts SubmitTxOpts) (hProtocol.AsyncTransactionSubmissionResponse, error)
+	SubmitTransactionWithOptionsAsync(transaction *txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.AsyncTransactionSubmissionResponse, error)
+	SubmitFeeBumpTransactionAsync(transaction *txnbuild.FeeBumpTransaction) (hProtocol.AsyncTransactionSubmissionResponse, error)
+	SubmitTransactionAsync(transaction *txnbuild.Transaction) (hProtocol.AsyncTransactionSubmissionResponse, error)
+	AsyncTransactionStatus(txHash string) (hProtocol.AsyncTransactionStatus, error)
 	Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error)
 	TransactionDetail(txHash string) (hProtocol.Transaction, error)
 	OrderBook(request OrderBookRequest) (hProtocol.OrderBookSummary, error)
@@ -214,6 +224,10 @@ type ClientInterface interface {
 	LiquidityPools(request LiquidityPoolsRequest) (hProtocol.LiquidityPoolsPage, error)
 	NextLiquidityPoolsPage(hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
 	PrevLiquidityPoolsPage(hProtocol.LiquidityPoolsPage) (hProtocol.LiquidityPoolsPage, error)
+	CoinInCirculation(request CoinInCirculationRequest) (hProtocol.KinesisCoinInCirculationRecordsPage, error)
+	NextCoinInCirculationPage(hProtocol.KinesisCoinInCirculationRecordsPage) (hProtocol.KinesisCoinInCirculationRecordsPage, error)
+	PrevCoinInCirculationPage(hProtocol.KinesisCoinInCirculationRecordsPage) (hProtocol.KinesisCoinInCirculationRecordsPage, error)
+	StreamCoinInCirculation(ctx context.Context, request CoinInCirculationRequest, handler CoinInCirculationHandler) error
 }
 
 // DefaultTestNetClient is a default client to connect to test network.
@@ -293,6 +307,31 @@ type LedgerRequest struct {
 	forSequence uint32
 }
 
+// CoinInCirculationResolution is the interval coin in circulation records are
+// aggregated by.
+type CoinInCirculationResolution string
+
+// Supported coin in circulation resolutions
+const (
+	CoinInCirculationLedger CoinInCirculationResolution = "ledger"
+	CoinInCirculationHour   CoinInCirculationResolution = "hour"
+	CoinInCirculationDay    CoinInCirculationResolution = "day"
+	CoinInCirculationWeek   CoinInCirculationResolution = "week"
+	CoinInCirculationMonth  CoinInCirculationResolution = "month"
+)
+
+// CoinInCirculationRequest struct contains data for getting coin in
+// circulation records from a horizon server. All fields are optional: records
+// are returned for the whole history and aggregated by day by default.
+type CoinInCirculationRequest struct {
+	From       time.Time
+	To         time.Time
+	Resolution CoinInCirculationResolution
+	Order      Order
+	Cursor     string
+	Limit      uint
+}
+
 type feeStatsRequest struct {
 	endpoint string
 }
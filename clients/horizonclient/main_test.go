@@ -506,7 +506,13 @@ func TestFeeStats(t *testing.T) {
 	if assert.NoError(t, err) {
 		assert.Equal(t, uint32(22606298), fees.LastLedger)
 		assert.Equal(t, int64(100), fees.LastLedgerBaseFee)
+		assert.Equal(t, int64(45), fees.LastLedgerBasePercentageFee)
 		assert.Equal(t, 0.97, fees.LedgerCapacityUsage)
+		assert.Equal(t, int64(45), fees.FeeChargedPercentage.Min)
+		assert.Equal(t, int64(120), fees.FeeChargedPercentage.Max)
+		assert.Equal(t, int64(46), fees.FeeChargedPercentage.Mode)
+		assert.Equal(t, int64(46), fees.FeeChargedPercentage.P50)
+		assert.Equal(t, int64(100), fees.FeeChargedPercentage.P95)
 		assert.Equal(t, int64(130), fees.MaxFee.Min)
 		assert.Equal(t, int64(8000), fees.MaxFee.Max)
 		assert.Equal(t, int64(250), fees.MaxFee.Mode)
@@ -856,6 +862,92 @@ func TestSubmitTransactionXDRRequest(t *testing.T) {
 	}
 }
 
+func TestSubmitTransactionXDRAsyncRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	txXdr := `AAAAABB90WssODNIgi6BHveqzxTRmIpvAFRyVNM+Hm2GVuCcAAAAZAAABD0AAuV/AAAAAAAAAAAAAAABAAAAAAAAAAAAAAAAyTBGxOgfSApppsTnb/YRr6gOR8WT0LZNrhLh4y3FCgoAAAAXSHboAAAAAAAAAAABhlbgnAAAAEAivKe977CQCxMOKTuj+cWTFqc2OOJU8qGr9afrgu2zDmQaX5Q0cNshc3PiBwe0qw/+D/qJk5QqM5dYeSUGeDQP`
+
+	// malformed transaction
+	hmock.
+		On("POST", "https://localhost/transactions_async").
+		ReturnString(400, `{"type": "https://stellar.org/horizon-errors/transaction_malformed", "title": "Transaction Malformed", "status": 400}`)
+
+	_, err := client.SubmitTransactionXDRAsync(txXdr)
+	if assert.Error(t, err) {
+		horizonError, ok := errors.Cause(err).(*Error)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, horizonError.Problem.Title, "Transaction Malformed")
+	}
+
+	// rejected by stellar-core
+	hmock.
+		On("POST", "https://localhost/transactions_async").
+		ReturnString(400, asyncTxError)
+
+	resp, err := client.SubmitTransactionXDRAsync(txXdr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, hProtocol.AsyncTxStatusError, resp.TxStatus)
+		assert.Equal(t, "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA=", resp.ErrorResultXDR)
+		assert.Equal(t, http.StatusBadRequest, resp.HTTPStatus)
+	}
+
+	// accepted by stellar-core
+	hmock.On(
+		"POST",
+		"https://localhost/transactions_async",
+	).Return(func(request *http.Request) (*http.Response, error) {
+		val := request.FormValue("tx")
+		assert.Equal(t, val, txXdr)
+		return httpmock.NewStringResponse(http.StatusCreated, asyncTxPending), nil
+	})
+
+	resp, err = client.SubmitTransactionXDRAsync(txXdr)
+	if assert.NoError(t, err) {
+		assert.Equal(t, hProtocol.AsyncTxStatusPending, resp.TxStatus)
+		assert.Equal(t, "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca", resp.Hash)
+		assert.Equal(t, "https://localhost/transactions_async/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca", resp.Links.Status.Href)
+		assert.Empty(t, resp.ErrorResultXDR)
+		assert.Equal(t, http.StatusCreated, resp.HTTPStatus)
+	}
+}
+
+func TestAsyncTransactionStatusRequest(t *testing.T) {
+	hmock := httptest.NewClient()
+	client := &Client{
+		HorizonURL: "https://localhost/",
+		HTTP:       hmock,
+	}
+
+	_, err := client.AsyncTransactionStatus("")
+	assert.EqualError(t, err, "no transaction hash provided")
+
+	hmock.
+		On("GET", "https://localhost/transactions_async/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca").
+		ReturnString(200, asyncTxStatusSuccess)
+
+	status, err := client.AsyncTransactionStatus("bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hProtocol.AsyncTxStatusSuccess, status.TxStatus)
+		assert.Equal(t, int32(354811), status.Ledger)
+		assert.Equal(t, "AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAA=", status.ResultXDR)
+	}
+
+	hmock.
+		On("GET", "https://localhost/transactions_async/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca").
+		ReturnString(404, notFoundResponse)
+
+	_, err = client.AsyncTransactionStatus("bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca")
+	if assert.Error(t, err) {
+		horizonError, ok := err.(*Error)
+		assert.Equal(t, ok, true)
+		assert.Equal(t, horizonError.Response.StatusCode, 404)
+	}
+}
+
 func TestSubmitTransactionRequest(t *testing.T) {
 	hmock := httptest.NewClient()
 	client := &Client{
@@ -2006,7 +2098,24 @@ var metricsResponse = `{
 var feesResponse = `{
   "last_ledger": "22606298",
   "last_ledger_base_fee": "100",
+  "last_ledger_base_percentage_fee": "45",
   "ledger_capacity_usage": "0.97",
+  "fee_charged_percentage": {
+    "min": "45",
+    "max": "120",
+    "mode": "46",
+    "p10": "45",
+    "p20": "45",
+    "p30": "46",
+    "p40": "46",
+    "p50": "46",
+    "p60": "47",
+    "p70": "50",
+    "p80": "60",
+    "p90": "80",
+    "p95": "100",
+    "p99": "120"
+  },
   "max_fee": {
     "min": "130",
     "max": "8000",
@@ -2626,3 +2735,39 @@ var paymentsResponse = `{
     ]
   }
 }`
+
+var asyncTxPending = `{
+  "_links": {
+    "status": {
+      "href": "https://localhost/transactions_async/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca"
+    }
+  },
+  "tx_status": "PENDING",
+  "hash": "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca"
+}`
+
+var asyncTxError = `{
+  "_links": {
+    "status": {
+      "href": "https://localhost/transactions_async/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca"
+    }
+  },
+  "error_result_xdr": "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA=",
+  "tx_status": "ERROR",
+  "hash": "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca"
+}`
+
+var asyncTxStatusSuccess = `{
+  "_links": {
+    "self": {
+      "href": "https://localhost/transactions_async/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca"
+    },
+    "transaction": {
+      "href": "https://localhost/transactions/bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca"
+    }
+  },
+  "tx_status": "SUCCESS",
+  "hash": "bcc7a97264dca0a51a63f7ea971b5e7458e334489673078bb2a34eb0cce910ca",
+  "ledger": 354811,
+  "result_xdr": "AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAA="
+}`
@@ -117,6 +117,42 @@ func (m *MockClient) SubmitTransactionWithOptions(transaction *txnbuild.Transact
 	return a.Get(0).(hProtocol.Transaction), a.Error(1)
 }
 
+// SubmitTransactionXDRAsync is a mocking method
+func (m *MockClient) SubmitTransactionXDRAsync(transactionXdr string) (hProtocol.AsyncTransactionSubmissionResponse, error) {
+	a := m.Called(transactionXdr)
+	return a.Get(0).(hProtocol.AsyncTransactionSubmissionResponse), a.Error(1)
+}
+
+// SubmitFeeBumpTransactionAsync is a mocking method
+func (m *MockClient) SubmitFeeBumpTransactionAsync(transaction *txnbuild.FeeBumpTransaction) (hProtocol.AsyncTransactionSubmissionResponse, error) {
+	a := m.Called(transaction)
+	return a.Get(0).(hProtocol.AsyncTransactionSubmissionResponse), a.Error(1)
+}
+
+// SubmitTransactionAsync is a mocking method
+func (m *MockClient) SubmitTransactionAsync(transaction *txnbuild.Transaction) (hProtocol.AsyncTransactionSubmissionResponse, error) {
+	a := m.Called(transaction)
+	return a.Get(0).(hProtocol.AsyncTransactionSubmissionResponse), a.Error(1)
+}
+
+// SubmitFeeBumpTransactionWithOptionsAsync is a mocking method
+func (m *MockClient) SubmitFeeBumpTransactionWithOptionsAsync(transaction *txnbuild.FeeBumpTransaction, opts SubmitTxOpts) (hProtocol.AsyncTransactionSubmissionResponse, error) {
+	a := m.Called(transaction, opts)
+	return a.Get(0).(hProtocol.AsyncTransactionSubmissionResponse), a.Error(1)
+}
+
+// SubmitTransactionWithOptionsAsync is a mocking method
+func (m *MockClient) SubmitTransactionWithOptionsAsync(transaction *txnbuild.Transaction, opts SubmitTxOpts) (hProtocol.AsyncTransactionSubmissionResponse, error) {
+	a := m.Called(transaction, opts)
+	return a.Get(0).(hProtocol.AsyncTransactionSubmissionResponse), a.Error(1)
+}
+
+// AsyncTransactionStatus is a mocking method
+func (m *MockClient) AsyncTransactionStatus(txHash string) (hProtocol.AsyncTransactionStatus, error) {
+	a := m.Called(txHash)
+	return a.Get(0).(hProtocol.AsyncTransactionStatus), a.Error(1)
+}
+
 // Transactions is a mocking method
 func (m *MockClient) Transactions(request TransactionRequest) (hProtocol.TransactionsPage, error) {
 	a := m.Called(request)
@@ -349,5 +385,28 @@ func (m *MockClient) PrevLiquidityPoolsPage(page hProtocol.LiquidityPoolsPage) (
 	return a.Get(0).(hProtocol.LiquidityPoolsPage), a.Error(1)
 }
 
+// CoinInCirculation is a mocking method
+func (m *MockClient) CoinInCirculation(request CoinInCirculationRequest) (hProtocol.KinesisCoinInCirculationRecordsPage, error) {
+	a := m.Called(request)
+	return a.Get(0).(hProtocol.KinesisCoinInCirculationRecordsPage), a.Error(1)
+}
+
+// NextCoinInCirculationPage is a mocking method
+func (m *MockClient) NextCoinInCirculationPage(page hProtocol.KinesisCoinInCirculationRecordsPage) (hProtocol.KinesisCoinInCirculationRecordsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.KinesisCoinInCirculationRecordsPage), a.Error(1)
+}
+
+// PrevCoinInCirculationPage is a mocking method
+func (m *MockClient) PrevCoinInCirculationPage(page hProtocol.KinesisCoinInCirculationRecordsPage) (hProtocol.KinesisCoinInCirculationRecordsPage, error) {
+	a := m.Called(page)
+	return a.Get(0).(hProtocol.KinesisCoinInCirculationRecordsPage), a.Error(1)
+}
+
+// StreamCoinInCirculation is a mocking method
+func (m *MockClient) StreamCoinInCirculation(ctx context.Context, request CoinInCirculationRequest, handler CoinInCirculationHandler) error {
+	return m.Called(ctx, request, handler).Error(0)
+}
+
 // ensure that the MockClient implements ClientInterface
 var _ ClientInterface = &MockClient{}
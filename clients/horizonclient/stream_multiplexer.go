@@ -0,0 +1,294 @@
+package horizonclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/support/errors"
+)
+
+// ErrStreamMultiplexerClosed is returned by the streams of a client whose
+// StreamMultiplexer was closed.
+var ErrStreamMultiplexerClosed = errors.New("stream multiplexer is closed")
+
+// StreamMultiplexer multiplexes the streams of a Client over a single
+// WebSocket connection to the /ws endpoint of Horizon. When it is set as the
+// StreamMultiplexer of a client, the Stream* methods of the client subscribe
+// over the connection instead of opening a connection per stream:
+//
+//	client := &horizonclient.Client{HorizonURL: "https://horizon.example.com/"}
+//	client.StreamMultiplexer = horizonclient.NewStreamMultiplexer(client)
+//	defer client.StreamMultiplexer.Close()
+//
+//	go client.StreamPayments(ctx, horizonclient.OperationRequest{ForAccount: a}, handler)
+//	go client.StreamPayments(ctx, horizonclient.OperationRequest{ForAccount: b}, handler)
+//	go client.StreamLedgers(ctx, horizonclient.LedgerRequest{}, ledgerHandler)
+//
+// The connection is opened by the first stream. When it is lost, a new
+// connection is opened and the streams are resumed from their last event. The
+// events of all the streams are read in order, a slow handler delays the
+// events of the other streams.
+type StreamMultiplexer struct {
+	client *Client
+
+	mutex  sync.Mutex
+	conn   *multiplexedConnection
+	nextID uint64
+	closed bool
+}
+
+// NewStreamMultiplexer returns a StreamMultiplexer connecting to the Horizon
+// server of client.
+func NewStreamMultiplexer(client *Client) *StreamMultiplexer {
+	return &StreamMultiplexer{client: client}
+}
+
+// Close closes the connection, the running streams return
+// ErrStreamMultiplexerClosed.
+func (m *StreamMultiplexer) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.closed = true
+	if m.conn != nil {
+		return m.conn.conn.Close()
+	}
+	return nil
+}
+
+// webSocketURL returns the URL of the /ws endpoint of the server.
+func (m *StreamMultiplexer) webSocketURL() (string, error) {
+	u, err := url.Parse(m.client.fixHorizonURL())
+	if err != nil {
+		return "", errors.Wrap(err, "error parsing horizon url")
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path += "ws"
+	return u.String(), nil
+}
+
+// connection returns the current connection, it opens a new connection if
+// there is none or if it was lost.
+func (m *StreamMultiplexer) connection(ctx context.Context) (*multiplexedConnection, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.closed {
+		return nil, ErrStreamMultiplexerClosed
+	}
+	if m.conn != nil && !m.conn.isDone() {
+		return m.conn, nil
+	}
+
+	wsURL, err := m.webSocketURL()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", wsURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating HTTP request")
+	}
+	m.client.setClientAppHeaders(req)
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, req.Header)
+	if err != nil {
+		if resp != nil {
+			return nil, errors.Wrapf(err, "got bad HTTP status code %d", resp.StatusCode)
+		}
+		return nil, errors.Wrap(err, "error opening WebSocket connection")
+	}
+	m.conn = newMultiplexedConnection(conn)
+	return m.conn, nil
+}
+
+// stream streams the resource at streamURL over the connection, it is the
+// counterpart of Client.stream.
+func (m *StreamMultiplexer) stream(
+	ctx context.Context,
+	streamURL string,
+	handler func(data []byte) error,
+) error {
+	su, err := url.Parse(streamURL)
+	if err != nil {
+		return errors.Wrap(err, "error parsing stream url")
+	}
+	hu, err := url.Parse(m.client.fixHorizonURL())
+	if err != nil {
+		return errors.Wrap(err, "error parsing horizon url")
+	}
+	// the subscriptions are paths relative to the root of the server
+	path := "/" + strings.TrimPrefix(su.Path, hu.Path)
+
+	query := su.Query()
+	if query.Get("cursor") == "" {
+		query.Set("cursor", "now")
+	}
+
+	for {
+		conn, err := m.connection(ctx)
+		if err != nil {
+			return err
+		}
+
+		m.mutex.Lock()
+		m.nextID++
+		id := strconv.FormatUint(m.nextID, 10)
+		m.mutex.Unlock()
+
+		// the query is updated with the cursor of the last event, which
+		// resumes the stream if the connection is lost
+		reconnect, err := conn.stream(ctx, id, path+"?"+query.Encode(), func(message hProtocol.WebSocketMessage) error {
+			if message.EventID != "" {
+				query.Set("cursor", message.EventID)
+			}
+			return handler(message.Data)
+		})
+		if !reconnect {
+			return err
+		}
+	}
+}
+
+type multiplexedSubscription struct {
+	messages chan hProtocol.WebSocketMessage
+	// ended is closed when the subscription is removed from the connection.
+	ended chan struct{}
+}
+
+type multiplexedConnection struct {
+	conn       *websocket.Conn
+	writeMutex sync.Mutex
+	// done is closed when the connection is lost.
+	done chan struct{}
+
+	mutex         sync.Mutex
+	subscriptions map[string]*multiplexedSubscription
+}
+
+func newMultiplexedConnection(conn *websocket.Conn) *multiplexedConnection {
+	c := &multiplexedConnection{
+		conn:          conn,
+		done:          make(chan struct{}),
+		subscriptions: map[string]*multiplexedSubscription{},
+	}
+	go c.readMessages()
+	return c
+}
+
+func (c *multiplexedConnection) isDone() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// readMessages dispatches the messages to their subscriptions until the
+// connection is lost.
+func (c *multiplexedConnection) readMessages() {
+	defer close(c.done)
+	defer c.conn.Close()
+	for {
+		var message hProtocol.WebSocketMessage
+		if err := c.conn.ReadJSON(&message); err != nil {
+			return
+		}
+
+		c.mutex.Lock()
+		subscription, ok := c.subscriptions[message.ID]
+		c.mutex.Unlock()
+		if !ok {
+			// e.g. the reply to an unsubscribe request
+			continue
+		}
+		select {
+		case subscription.messages <- message:
+		case <-subscription.ended:
+		}
+	}
+}
+
+func (c *multiplexedConnection) write(request hProtocol.WebSocketRequest) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	return c.conn.WriteJSON(request)
+}
+
+func (c *multiplexedConnection) remove(id string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if subscription, ok := c.subscriptions[id]; ok {
+		delete(c.subscriptions, id)
+		close(subscription.ended)
+	}
+}
+
+// stream subscribes to path and calls handler with the events of the
+// subscription until ctx is done or an error occurs. It returns true when the
+// connection was lost and the stream should be resumed over a new connection.
+func (c *multiplexedConnection) stream(
+	ctx context.Context,
+	id, path string,
+	handler func(message hProtocol.WebSocketMessage) error,
+) (bool, error) {
+	subscription := &multiplexedSubscription{
+		messages: make(chan hProtocol.WebSocketMessage),
+		ended:    make(chan struct{}),
+	}
+	c.mutex.Lock()
+	c.subscriptions[id] = subscription
+	c.mutex.Unlock()
+	defer c.remove(id)
+
+	err := c.write(hProtocol.WebSocketRequest{
+		Type: hProtocol.WebSocketSubscribe,
+		ID:   id,
+		Path: path,
+	})
+	if err != nil {
+		c.conn.Close()
+		return true, nil
+	}
+
+	unsubscribe := func() {
+		c.remove(id)
+		// the connection may be lost already, in which case there is
+		// nothing to unsubscribe from
+		c.write(hProtocol.WebSocketRequest{Type: hProtocol.WebSocketUnsubscribe, ID: id})
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			unsubscribe()
+			return false, nil
+		case <-c.done:
+			return true, nil
+		case message := <-subscription.messages:
+			switch message.Type {
+			case hProtocol.WebSocketEvent:
+				if err := handler(message); err != nil {
+					unsubscribe()
+					return false, errors.Wrap(err, "handler error")
+				}
+			case hProtocol.WebSocketError:
+				herr := &Error{}
+				if message.Error != nil {
+					herr.Problem = *message.Error
+				}
+				return false, herr
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package horizonclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// webSocketTestServer is a fake /ws endpoint, serve is called with every
+// connection.
+func webSocketTestServer(t *testing.T, serve func(conn *websocket.Conn)) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/horizon/ws", r.URL.Path)
+		assert.Equal(t, "go-stellar-sdk", r.Header.Get("X-Client-Name"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		serve(conn)
+	}))
+}
+
+func readSubscription(t *testing.T, conn *websocket.Conn) hProtocol.WebSocketRequest {
+	var request hProtocol.WebSocketRequest
+	require.NoError(t, conn.ReadJSON(&request))
+	return request
+}
+
+func TestStreamMultiplexerStreamLedgers(t *testing.T) {
+	connections := 0
+	server := webSocketTestServer(t, func(conn *websocket.Conn) {
+		connections++
+		request := readSubscription(t, conn)
+		assert.Equal(t, hProtocol.WebSocketSubscribe, request.Type)
+
+		if connections == 1 {
+			assert.Equal(t, "/ledgers?cursor=now", request.Path)
+			require.NoError(t, conn.WriteJSON(hProtocol.WebSocketMessage{Type: hProtocol.WebSocketSubscribed, ID: request.ID}))
+			require.NoError(t, conn.WriteJSON(hProtocol.WebSocketMessage{
+				Type:    hProtocol.WebSocketEvent,
+				ID:      request.ID,
+				EventID: "2406364278935552",
+				Data:    []byte(`{"sequence": 560270}`),
+			}))
+			// the connection is lost
+			return
+		}
+
+		// the stream is resumed from the last event
+		assert.Equal(t, "/ledgers?cursor=2406364278935552", request.Path)
+		require.NoError(t, conn.WriteJSON(hProtocol.WebSocketMessage{
+			Type:    hProtocol.WebSocketEvent,
+			ID:      request.ID,
+			EventID: "2406364278939648",
+			Data:    []byte(`{"sequence": 560271}`),
+		}))
+
+		request = readSubscription(t, conn)
+		assert.Equal(t, hProtocol.WebSocketUnsubscribe, request.Type)
+	})
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL + "/horizon"}
+	client.StreamMultiplexer = NewStreamMultiplexer(client)
+	defer client.StreamMultiplexer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var sequences []int32
+	err := client.StreamLedgers(ctx, LedgerRequest{}, func(ledger hProtocol.Ledger) {
+		sequences = append(sequences, ledger.Sequence)
+		if len(sequences) == 2 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int32{560270, 560271}, sequences)
+	assert.Equal(t, 2, connections)
+}
+
+func TestStreamMultiplexerSharedConnection(t *testing.T) {
+	connections := 0
+	server := webSocketTestServer(t, func(conn *websocket.Conn) {
+		connections++
+		paths := map[string]string{}
+		for i := 0; i < 2; i++ {
+			request := readSubscription(t, conn)
+			paths[request.Path] = request.ID
+		}
+
+		paymentsID := paths["/accounts/GA/payments?cursor=now"]
+		require.NoError(t, conn.WriteJSON(hProtocol.WebSocketMessage{
+			Type:    hProtocol.WebSocketEvent,
+			ID:      paymentsID,
+			EventID: "1",
+			Data:    []byte(`{"id": "1", "type": "payment", "type_i": 1, "amount": "10.0000000"}`),
+		}))
+
+		p := problem.NotFound
+		require.NoError(t, conn.WriteJSON(hProtocol.WebSocketMessage{
+			Type:  hProtocol.WebSocketError,
+			ID:    paths["/accounts/GB/payments?cursor=now"],
+			Error: &p,
+		}))
+
+		request := readSubscription(t, conn)
+		assert.Equal(t, hProtocol.WebSocketUnsubscribe, request.Type)
+		assert.Equal(t, paymentsID, request.ID)
+	})
+	defer server.Close()
+
+	client := &Client{HorizonURL: server.URL + "/horizon/"}
+	client.StreamMultiplexer = NewStreamMultiplexer(client)
+	defer client.StreamMultiplexer.Close()
+
+	errs := make(chan error, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		errs <- client.StreamPayments(ctx, OperationRequest{ForAccount: "GA"}, func(op operations.Operation) {
+			payment, ok := op.(operations.Payment)
+			assert.True(t, ok)
+			assert.Equal(t, "10.0000000", payment.Amount)
+			cancel()
+		})
+	}()
+	go func() {
+		errs <- client.StreamPayments(context.Background(), OperationRequest{ForAccount: "GB"}, func(op operations.Operation) {
+			assert.Fail(t, "unexpected payment")
+		})
+	}()
+
+	var herr *Error
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			var ok bool
+			herr, ok = err.(*Error)
+			require.True(t, ok, err.Error())
+		}
+	}
+	require.NotNil(t, herr)
+	assert.Equal(t, "not_found", herr.Problem.Type)
+	assert.Equal(t, 1, connections)
+}
+
+func TestStreamMultiplexerClosed(t *testing.T) {
+	client := &Client{HorizonURL: "http://localhost/"}
+	client.StreamMultiplexer = NewStreamMultiplexer(client)
+	require.NoError(t, client.StreamMultiplexer.Close())
+
+	err := client.StreamLedgers(context.Background(), LedgerRequest{}, func(hProtocol.Ledger) {})
+	assert.Equal(t, ErrStreamMultiplexerClosed, err)
+}
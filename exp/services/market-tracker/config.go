@@ -18,6 +18,10 @@ type Asset struct {
 	IssuerAddress     string `json:"issuerAddress"`
 	IssuerName        string `json:"issuerName"`
 	Currency          string `json:"currency"`
+	// Metal and MetalOunces describe the backing of metal-backed assets,
+	// e.g. "XAU" and 0.0321507466 for an asset backed by one gram of gold.
+	Metal       string  `json:"metal"`
+	MetalOunces float64 `json:"metalOunces"`
 }
 
 func (a Asset) String() string {
@@ -37,10 +41,28 @@ func (tp TradePair) String() string {
 	return fmt.Sprintf("%s / %s", tp.BuyingAsset, tp.SellingAsset)
 }
 
+// ReferencePair represents a metal-backed asset traded against an anchored fiat asset,
+// whose DEX price is compared to the spot price of the metal
+type ReferencePair struct {
+	// HorizonURL is the Horizon of the network the pair is traded on,
+	// the public network if empty
+	HorizonURL   string `json:"horizonURL"`
+	Asset        Asset  `json:"asset"`
+	CounterAsset Asset  `json:"counterAsset"`
+}
+
+func (rp ReferencePair) String() string {
+	return fmt.Sprintf("%s / %s", rp.Asset, rp.CounterAsset)
+}
+
 // Config represents the overall config of the application
 type Config struct {
 	TradePairs           []TradePair `json:"tradePairs"`
 	CheckIntervalSeconds int64       `json:"checkIntervalSeconds"`
+	// ReferencePriceURL is the HTTP(S) URL or file path of the JSON
+	// document with the reference prices of metals and currencies
+	ReferencePriceURL string          `json:"referencePriceURL"`
+	ReferencePairs    []ReferencePair `json:"referencePairs"`
 }
 
 func computeAssetType(a *Asset) (err error) {
@@ -83,5 +105,13 @@ func loadConfig() Config {
 		check(err)
 	}
 
+	for n := range config.ReferencePairs {
+		err = computeAssetType(&config.ReferencePairs[n].Asset)
+		check(err)
+
+		err = computeAssetType(&config.ReferencePairs[n].CounterAsset)
+		check(err)
+	}
+
 	return config
 }
@@ -26,6 +26,11 @@ func main() {
 	trackSpreads(cfg, c, &watchedTPs)
 	trackVolumes(cfg, c, &watchedTPs)
 
+	if cfg.ReferencePriceURL != "" {
+		watchedRPs := configPremiumWatchers(cfg.ReferencePairs)
+		trackPremiums(cfg, newJSONReferencePriceSource(cfg.ReferencePriceURL), watchedRPs)
+	}
+
 	http.Handle("/metrics", promhttp.Handler())
 	http.ListenAndServe(":2112", nil)
 }
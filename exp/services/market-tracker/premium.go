@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	hClient "github.com/stellar/go/clients/horizonclient"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+)
+
+// Premium tracks the premium (or discount, if negative) of the DEX price of a
+// metal-backed asset over the spot price of its metal.
+type Premium struct {
+	Percent  prometheus.Gauge
+	RefPrice prometheus.Gauge
+	DexPrice prometheus.Gauge
+}
+
+type prometheusWatchedRP struct {
+	ReferencePair ReferencePair
+	Premium       Premium
+}
+
+func trackPremiums(cfg Config, src ReferencePriceSource, watchedRPs []prometheusWatchedRP) {
+	go func() {
+		for {
+			prices, err := src.ReferencePrices()
+			if err != nil {
+				fmt.Printf("error while getting reference prices: %s\n", err)
+			}
+
+			for _, wrp := range watchedRPs {
+				if prices == nil {
+					break
+				}
+
+				refPrice, err := calcReferencePrice(prices, wrp.ReferencePair)
+				if err != nil {
+					fmt.Printf("error while computing reference price for %s: %s\n", wrp.ReferencePair, err)
+					continue
+				}
+
+				obStats, err := getOrderBookForReferencePair(wrp.ReferencePair)
+				if err != nil {
+					fmt.Printf("error while getting orderbook stats for asset pair %s: %s\n", wrp.ReferencePair, err)
+					continue
+				}
+
+				dexPrice := calcDexMidPrice(obStats)
+				wrp.Premium.DexPrice.Set(dexPrice)
+				wrp.Premium.RefPrice.Set(refPrice)
+				wrp.Premium.Percent.Set(calcPremiumPct(dexPrice, refPrice))
+			}
+
+			time.Sleep(time.Duration(cfg.CheckIntervalSeconds) * time.Second)
+		}
+	}()
+}
+
+// getOrderBookForReferencePair returns the orderbook of a reference pair, with prices
+// in units of the counter asset.
+func getOrderBookForReferencePair(rp ReferencePair) (hProtocol.OrderBookSummary, error) {
+	horizon := hClient.DefaultPublicNetClient
+	if rp.HorizonURL != "" {
+		horizon = &hClient.Client{HorizonURL: rp.HorizonURL}
+	}
+
+	req := hClient.OrderBookRequest{
+		SellingAssetType: rp.Asset.ProtocolAssetType,
+		BuyingAssetType:  rp.CounterAsset.ProtocolAssetType,
+		Limit:            200,
+	}
+	// native assets are identified by their type only
+	if rp.Asset.ProtocolAssetType != hClient.AssetTypeNative {
+		req.SellingAssetCode = rp.Asset.Code
+		req.SellingAssetIssuer = rp.Asset.IssuerAddress
+	}
+	if rp.CounterAsset.ProtocolAssetType != hClient.AssetTypeNative {
+		req.BuyingAssetCode = rp.CounterAsset.Code
+		req.BuyingAssetIssuer = rp.CounterAsset.IssuerAddress
+	}
+	return horizon.OrderBook(req)
+}
+
+// calcDexMidPrice returns the mid price of an orderbook, or the best price of
+// its only side if the other one is empty.
+func calcDexMidPrice(obStats hProtocol.OrderBookSummary) float64 {
+	highestBid := calcHighestBid(obStats.Bids)
+	lowestAsk := calcLowestAsk(obStats.Asks)
+	switch {
+	case math.IsInf(highestBid, -1) && math.IsInf(lowestAsk, 1):
+		return 0
+	case math.IsInf(highestBid, -1):
+		return lowestAsk
+	case math.IsInf(lowestAsk, 1):
+		return highestBid
+	}
+	return (highestBid + lowestAsk) / 2
+}
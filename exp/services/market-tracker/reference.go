@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ReferencePriceSource provides the spot prices of currencies and metals, in USD
+// per unit. Metal prices are per troy ounce, e.g. "XAU" and "XAG".
+type ReferencePriceSource interface {
+	ReferencePrices() (map[string]float64, error)
+}
+
+// referencePriceDoc is the JSON document served by a jsonReferencePriceSource, e.g.
+// {"prices": {"XAU": 2350.1, "XAG": 29.6, "EUR": 1.08}}
+type referencePriceDoc struct {
+	Prices map[string]float64 `json:"prices"`
+}
+
+// jsonReferencePriceSource reads reference prices from a JSON document, either
+// served over HTTP(S) or stored in a local file.
+type jsonReferencePriceSource struct {
+	location string
+	client   *http.Client
+}
+
+func newJSONReferencePriceSource(location string) *jsonReferencePriceSource {
+	return &jsonReferencePriceSource{
+		location: location,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *jsonReferencePriceSource) ReferencePrices() (map[string]float64, error) {
+	body, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	var doc referencePriceDoc
+	if err = json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse reference prices: %s", err)
+	}
+	if len(doc.Prices) == 0 {
+		return nil, fmt.Errorf("no reference prices in %s", s.location)
+	}
+	return doc.Prices, nil
+}
+
+func (s *jsonReferencePriceSource) read() ([]byte, error) {
+	if !strings.HasPrefix(s.location, "http://") && !strings.HasPrefix(s.location, "https://") {
+		return ioutil.ReadFile(strings.TrimPrefix(s.location, "file://"))
+	}
+
+	resp, err := s.client.Get(s.location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status code %d from reference price source", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// calcReferencePrice returns the spot price of one unit of a metal-backed asset, in
+// units of the fiat currency of the counter asset.
+func calcReferencePrice(prices map[string]float64, rp ReferencePair) (float64, error) {
+	metalUsdPrice, ok := prices[rp.Asset.Metal]
+	if !ok {
+		return 0, fmt.Errorf("no reference price for %s", rp.Asset.Metal)
+	}
+
+	currencyUsdPrice := 1.0
+	if rp.CounterAsset.Currency != "USD" {
+		if currencyUsdPrice, ok = prices[rp.CounterAsset.Currency]; !ok || currencyUsdPrice == 0 {
+			return 0, fmt.Errorf("no reference price for %s", rp.CounterAsset.Currency)
+		}
+	}
+
+	return metalUsdPrice * rp.Asset.MetalOunces / currencyUsdPrice, nil
+}
+
+// calcPremiumPct returns the percentage by which the DEX price is above (premium) or
+// below (discount) the reference price.
+func calcPremiumPct(dexPrice, refPrice float64) float64 {
+	if dexPrice == 0 || refPrice == 0 {
+		return 0
+	}
+	return 100. * (dexPrice - refPrice) / refPrice
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const referencePricesBody = `{"prices": {"XAU": 2000.0, "XAG": 25.0, "EUR": 1.25}}`
+
+func TestJSONReferencePriceSourceHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prices.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(referencePricesBody))
+	}))
+	defer server.Close()
+
+	prices, err := newJSONReferencePriceSource(server.URL + "/prices.json").ReferencePrices()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"XAU": 2000.0, "XAG": 25.0, "EUR": 1.25}, prices)
+
+	_, err = newJSONReferencePriceSource(server.URL + "/missing.json").ReferencePrices()
+	assert.EqualError(t, err, "got status code 404 from reference price source")
+}
+
+func TestJSONReferencePriceSourceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "market-tracker")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "prices.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(referencePricesBody), 0644))
+
+	prices, err := newJSONReferencePriceSource(path).ReferencePrices()
+	require.NoError(t, err)
+	assert.Equal(t, 2000.0, prices["XAU"])
+
+	prices, err = newJSONReferencePriceSource("file://" + path).ReferencePrices()
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, prices["XAG"])
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"prices": {}}`), 0644))
+	_, err = newJSONReferencePriceSource(path).ReferencePrices()
+	assert.EqualError(t, err, "no reference prices in "+path)
+}
+
+func TestCalcReferencePrice(t *testing.T) {
+	prices := map[string]float64{"XAU": 2000.0, "XAG": 25.0, "EUR": 1.25}
+	kau := Asset{Code: "KAU", Metal: "XAU", MetalOunces: 0.5}
+
+	refPrice, err := calcReferencePrice(prices, ReferencePair{Asset: kau, CounterAsset: Asset{Code: "USD", Currency: "USD"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1000.0, refPrice)
+
+	refPrice, err = calcReferencePrice(prices, ReferencePair{Asset: kau, CounterAsset: Asset{Code: "EUR", Currency: "EUR"}})
+	require.NoError(t, err)
+	assert.Equal(t, 800.0, refPrice)
+
+	_, err = calcReferencePrice(prices, ReferencePair{Asset: kau, CounterAsset: Asset{Code: "GBP", Currency: "GBP"}})
+	assert.EqualError(t, err, "no reference price for GBP")
+
+	_, err = calcReferencePrice(prices, ReferencePair{Asset: Asset{Metal: "XPT"}, CounterAsset: Asset{Currency: "USD"}})
+	assert.EqualError(t, err, "no reference price for XPT")
+}
+
+func TestCalcPremiumPct(t *testing.T) {
+	assert.Equal(t, 5.0, calcPremiumPct(1050.0, 1000.0))
+	assert.Equal(t, -2.5, calcPremiumPct(975.0, 1000.0))
+	assert.Equal(t, 0.0, calcPremiumPct(0.0, 1000.0))
+	assert.Equal(t, 0.0, calcPremiumPct(1000.0, 0.0))
+}
+
+func TestCalcDexMidPrice(t *testing.T) {
+	bids := []hProtocol.PriceLevel{hLowOrder}
+	asks := []hProtocol.PriceLevel{hHighOrder}
+
+	assert.Equal(t, 2.25, calcDexMidPrice(hProtocol.OrderBookSummary{Bids: bids, Asks: asks}))
+	assert.Equal(t, 2.0, calcDexMidPrice(hProtocol.OrderBookSummary{Bids: bids}))
+	assert.Equal(t, 2.5, calcDexMidPrice(hProtocol.OrderBookSummary{Asks: asks}))
+	assert.Equal(t, 0.0, calcDexMidPrice(hProtocol.OrderBookSummary{}))
+}
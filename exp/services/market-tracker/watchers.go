@@ -28,6 +28,24 @@ func configPrometheusWatchers(tps []TradePair) (watchedTPs []prometheusWatchedTP
 	return
 }
 
+func configPremiumWatchers(rps []ReferencePair) (watchedRPs []prometheusWatchedRP) {
+	for _, rp := range rps {
+		labels := prometheus.Labels{
+			"tradePair":    fmt.Sprintf("%s", rp),
+			"asset":        fmt.Sprintf("%s", rp.Asset),
+			"counterAsset": fmt.Sprintf("%s", rp.CounterAsset),
+			"metal":        rp.Asset.Metal,
+		}
+
+		pwrp := prometheusWatchedRP{
+			ReferencePair: rp,
+			Premium:       createPremium(labels),
+		}
+		watchedRPs = append(watchedRPs, pwrp)
+	}
+	return
+}
+
 func createSpread(labels prometheus.Labels) Spread {
 	return Spread{
 		Top:  createSpreadGauge("", "", labels),
@@ -155,3 +173,23 @@ func createFairValue(labels prometheus.Labels) FairValue {
 		}),
 	}
 }
+
+func createPremium(labels prometheus.Labels) Premium {
+	return Premium{
+		Percent: promauto.NewGauge(prometheus.GaugeOpts{
+			Name:        "stellar_market_tracker_metal_premium",
+			ConstLabels: labels,
+			Help:        "Pct premium (or discount, if negative) of DEX price over metal spot price",
+		}),
+		RefPrice: promauto.NewGauge(prometheus.GaugeOpts{
+			Name:        "stellar_market_tracker_metal_refprice",
+			ConstLabels: labels,
+			Help:        "Metal spot price of the asset (in counter currency)",
+		}),
+		DexPrice: promauto.NewGauge(prometheus.GaugeOpts{
+			Name:        "stellar_market_tracker_metal_price",
+			ConstLabels: labels,
+			Help:        "Mid-market price on the DEX (in counter currency)",
+		}),
+	}
+}
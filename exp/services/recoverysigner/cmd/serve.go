@@ -60,7 +60,7 @@ func (c *ServeCommand) Command() *cobra.Command {
 		},
 		{
 			Name:      "sep10-jwks",
-			Usage:     "JSON Web Key Set (JWKS) containing one or more keys used to validate SEP-10 JWTs (if the key is an asymmetric key that has separate public and private key, the JWK need only contain the public key) (if multiple keys are provided they will all attempt verification the key ID will be ignored although logged)",
+			Usage:     "JSON Web Key Set (JWKS) containing one or more keys used to validate SEP-10 JWTs (if the key is an asymmetric key that has separate public and private key, the JWK need only contain the public key) (if multiple keys are provided the keys with the key ID (kid) of the JWT are used for verification, or all keys if the JWT has no key ID or no key matches it, so previous keys can be kept during key rotations)",
 			OptType:   types.String,
 			ConfigKey: &opts.SEP10JWKS,
 			Required:  true,
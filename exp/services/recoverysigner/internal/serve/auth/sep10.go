@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/stellar/go/exp/support/jwtkey"
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/support/http/httpauthz"
 	"github.com/stellar/go/support/log"
@@ -62,10 +63,14 @@ func sep10ClaimsFromRequest(r *http.Request, issuer string, ks jose.JSONWebKeySe
 	if err != nil {
 		return "", jose.JSONWebKey{}, false
 	}
+	kid := ""
+	if len(token.Headers) > 0 {
+		kid = token.Headers[0].KeyID
+	}
 	tokenClaims := sep10JWTClaims{}
 	verified := false
 	verifiedWithKey := jose.JSONWebKey{}
-	for _, k := range ks.Keys {
+	for _, k := range jwtkey.VerificationKeys(ks, kid) {
 		err = token.Claims(k, &tokenClaims)
 		if err == nil {
 			verified = true
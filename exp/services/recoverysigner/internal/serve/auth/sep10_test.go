@@ -128,6 +128,67 @@ func TestSEP10_addsAddressToClaimIfJWTValidMultipleJWKS(t *testing.T) {
 	})
 }
 
+func TestSEP10_selectsJWKSKeysByKeyID(t *testing.T) {
+	issuer := "https://webauth.example.com"
+
+	current, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	previous, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwks := jose.JSONWebKeySet{
+		Keys: []jose.JSONWebKey{
+			{Key: &current.PublicKey, KeyID: "current"},
+			{Key: &previous.PublicKey, KeyID: "previous"},
+		},
+	}
+
+	ctx := context.Context(nil)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})
+	middleware := SEP10Middleware(issuer, jwks)
+	handler := middleware(next)
+
+	testCases := []struct {
+		name   string
+		key    *ecdsa.PrivateKey
+		kid    string
+		wantOK bool
+	}{
+		{"current key", current, "current", true},
+		{"previous key", previous, "previous", true},
+		{"no key ID", previous, "", true},
+		{"unknown key ID", previous, "other", true},
+		{"mismatched key ID", previous, "current", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			jwtClaims := jwt.MapClaims{
+				"iss": "https://webauth.example.com",
+				"sub": "GDKABHI4LTLG7UCE6O7Y4D6REHJVS4DLXTVVXTE3BPRRLXPASHSOKG2D",
+				"iat": time.Now().Unix(),
+				"exp": time.Now().Add(time.Hour).Unix(),
+			}
+			jwtToken := jwt.NewWithClaims(jwt.SigningMethodES256, jwtClaims)
+			if tc.kid != "" {
+				jwtToken.Header["kid"] = tc.kid
+			}
+			jwtTokenStr, err := jwtToken.SignedString(tc.key)
+			require.NoError(t, err)
+			r.Header.Set("Authorization", "Bearer "+jwtTokenStr)
+			handler.ServeHTTP(nil, r)
+
+			assert.NotNil(t, ctx)
+			claims, ok := FromContext(ctx)
+			assert.Equal(t, tc.wantOK, ok)
+			if tc.wantOK {
+				assert.Equal(t, "GDKABHI4LTLG7UCE6O7Y4D6REHJVS4DLXTVVXTE3BPRRLXPASHSOKG2D", claims.Address)
+			}
+		})
+	}
+}
+
 func TestSEP10_doesNotAddAddressToClaimIfJWTNotPresent(t *testing.T) {
 	issuer := "https://webauth.example.com"
 
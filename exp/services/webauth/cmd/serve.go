@@ -46,7 +46,7 @@ func (c *ServeCommand) Command() *cobra.Command {
 		},
 		{
 			Name:      "signing-key",
-			Usage:     "Stellar signing key(s) used for signing transactions comma separated (first key is used for signing, others used for verifying challenges)",
+			Usage:     "Stellar signing key(s) used for signing transactions comma separated (first key is used for signing and must be the SIGNING_KEY of the stellar.toml, others used for verifying challenges)",
 			OptType:   types.String,
 			ConfigKey: &opts.SigningKeys,
 			Required:  true,
@@ -81,6 +81,13 @@ func (c *ServeCommand) Command() *cobra.Command {
 			ConfigKey: &opts.JWK,
 			Required:  true,
 		},
+		{
+			Name:      "previous-jwks",
+			Usage:     "JSON Web Key Set (JWKS) of the JWKs previously used for signing JWTs, published along with the current JWK on /.well-known/jwks.json so that the JWTs they signed can be verified until they expire (only the public keys are published)",
+			OptType:   types.String,
+			ConfigKey: &opts.PreviousJWKS,
+			Required:  false,
+		},
 		{
 			Name:      "jwt-issuer",
 			Usage:     "The issuer to set in the JWT iss claim",
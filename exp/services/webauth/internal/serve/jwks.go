@@ -0,0 +1,18 @@
+package serve
+
+import (
+	"net/http"
+
+	"github.com/stellar/go/support/render/httpjson"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// jwksHandler publishes the public keys of the current and previous JWKs, so
+// that services consuming the JWTs can verify them across key rotations.
+type jwksHandler struct {
+	JWKS jose.JSONWebKeySet
+}
+
+func (h jwksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	httpjson.Render(w, h.JWKS, httpjson.JSON)
+}
@@ -0,0 +1,114 @@
+package serve
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stellar/go/exp/support/jwtkey"
+	"github.com/stellar/go/keypair"
+	supportlog "github.com/stellar/go/support/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestJWKS_publishesCurrentAndPreviousPublicKeys(t *testing.T) {
+	currentKey, err := jwtkey.GenerateKey()
+	require.NoError(t, err)
+	currentJWK, err := json.Marshal(jose.JSONWebKey{Key: currentKey, Algorithm: string(jose.ES256)})
+	require.NoError(t, err)
+
+	previousKey, err := jwtkey.GenerateKey()
+	require.NoError(t, err)
+	previousJWKS, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: previousKey, KeyID: "previous", Algorithm: string(jose.ES256)},
+	}})
+	require.NoError(t, err)
+
+	h, err := handler(Options{
+		Logger:          supportlog.DefaultLogger,
+		SigningKeys:     keypair.MustRandom().Seed(),
+		AuthHomeDomains: "example.com",
+		JWK:             string(currentJWK),
+		PreviousJWKS:    string(previousJWKS),
+	})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	resp := w.Result()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	jwks := jose.JSONWebKeySet{}
+	err = json.NewDecoder(resp.Body).Decode(&jwks)
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+
+	currentKID, err := jwtkey.KeyID(jose.JSONWebKey{Key: currentKey})
+	require.NoError(t, err)
+	assert.Equal(t, currentKID, jwks.Keys[0].KeyID)
+	assert.True(t, jwks.Keys[0].IsPublic())
+	assert.Equal(t, currentKey.PublicKey.X, jwks.Keys[0].Key.(*ecdsa.PublicKey).X)
+	assert.Equal(t, "previous", jwks.Keys[1].KeyID)
+	assert.True(t, jwks.Keys[1].IsPublic())
+	assert.Equal(t, previousKey.PublicKey.X, jwks.Keys[1].Key.(*ecdsa.PublicKey).X)
+}
+
+func TestJWKS_invalidPreviousJWKS(t *testing.T) {
+	currentKey, err := jwtkey.GenerateKey()
+	require.NoError(t, err)
+	currentJWK, err := json.Marshal(jose.JSONWebKey{Key: currentKey, Algorithm: string(jose.ES256)})
+	require.NoError(t, err)
+
+	_, err = handler(Options{
+		Logger:          supportlog.DefaultLogger,
+		SigningKeys:     keypair.MustRandom().Seed(),
+		AuthHomeDomains: "example.com",
+		JWK:             string(currentJWK),
+		PreviousJWKS:    "{",
+	})
+	assert.EqualError(t, err, "parsing previous JSON Web Key Set (JWKS): unexpected end of JSON input")
+}
+
+func TestJWKS_symmetricJWKIsNotPublished(t *testing.T) {
+	currentJWK, err := json.Marshal(jose.JSONWebKey{Key: []byte("secret"), Algorithm: string(jose.HS256)})
+	require.NoError(t, err)
+
+	previousKey, err := jwtkey.GenerateKey()
+	require.NoError(t, err)
+	previousJWKS, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: previousKey, Algorithm: string(jose.ES256)},
+	}})
+	require.NoError(t, err)
+
+	h, err := handler(Options{
+		Logger:          supportlog.DefaultLogger,
+		SigningKeys:     keypair.MustRandom().Seed(),
+		AuthHomeDomains: "example.com",
+		JWK:             string(currentJWK),
+		PreviousJWKS:    string(previousJWKS),
+	})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	resp := w.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	jwks := jose.JSONWebKeySet{}
+	err = json.NewDecoder(resp.Body).Decode(&jwks)
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 1)
+
+	previousKID, err := jwtkey.KeyID(jose.JSONWebKey{Key: previousKey})
+	require.NoError(t, err)
+	assert.Equal(t, previousKID, jwks.Keys[0].KeyID)
+	assert.True(t, jwks.Keys[0].IsPublic())
+}
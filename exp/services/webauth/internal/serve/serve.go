@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/exp/support/jwtkey"
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/support/errors"
 	supporthttp "github.com/stellar/go/support/http"
@@ -26,6 +27,7 @@ type Options struct {
 	AuthHomeDomains             string
 	ChallengeExpiresIn          time.Duration
 	JWK                         string
+	PreviousJWKS                string
 	JWTIssuer                   string
 	JWTExpiresIn                time.Duration
 	AllowAccountsThatDoNotExist bool
@@ -79,6 +81,44 @@ func handler(opts Options) (http.Handler, error) {
 	if jwk.Algorithm == "" {
 		return nil, errors.New("algorithm (alg) field must be set")
 	}
+	if jwk.KeyID == "" {
+		jwk.KeyID, err = jwtkey.KeyID(jwk)
+		if err != nil {
+			return nil, err
+		}
+	}
+	opts.Logger.Info("JWK key ID: ", jwk.KeyID)
+
+	previousJWKS := jose.JSONWebKeySet{}
+	if opts.PreviousJWKS != "" {
+		err = json.Unmarshal([]byte(opts.PreviousJWKS), &previousJWKS)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing previous JSON Web Key Set (JWKS)")
+		}
+	}
+	// Symmetric keys, e.g. HS256 keys, can sign JWTs but cannot be published,
+	// so only asymmetric keys are part of the JWKS.
+	publishedKeys := []jose.JSONWebKey{}
+	for i, k := range append([]jose.JSONWebKey{jwk}, previousJWKS.Keys...) {
+		if k.KeyID == "" {
+			k.KeyID, err = jwtkey.KeyID(k)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if i > 0 {
+			opts.Logger.Info("Previous JWK key ID ", i-1, ": ", k.KeyID)
+		}
+		if jwtkey.IsSymmetric(k) {
+			opts.Logger.Warn("JWK ", k.KeyID, " is a symmetric key, it is not published in the JSON Web Key Set (JWKS)")
+			continue
+		}
+		publishedKeys = append(publishedKeys, k)
+	}
+	jwks, err := jwtkey.PublicKeySet(publishedKeys...)
+	if err != nil {
+		return nil, errors.Wrap(err, "building JSON Web Key Set (JWKS)")
+	}
 
 	horizonTimeout := horizonclient.HorizonTimeout
 	httpClient := &http.Client{
@@ -96,6 +136,7 @@ func handler(opts Options) (http.Handler, error) {
 	mux.MethodNotAllowed(errorHandler{Error: methodNotAllowed}.ServeHTTP)
 
 	mux.Get("/health", health.PassHandler{}.ServeHTTP)
+	mux.Get("/.well-known/jwks.json", jwksHandler{JWKS: jwks}.ServeHTTP)
 	mux.Get("/", challengeHandler{
 		Logger:             opts.Logger,
 		NetworkPassphrase:  opts.NetworkPassphrase,
@@ -133,6 +133,9 @@ func (h tokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	jwsOptions := &jose.SignerOptions{}
 	jwsOptions.WithType("JWT")
+	if h.JWK.KeyID != "" {
+		jwsOptions.WithHeader("kid", h.JWK.KeyID)
+	}
 	jws, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.SignatureAlgorithm(h.JWK.Algorithm), Key: h.JWK.Key}, jwsOptions)
 	if err != nil {
 		l.WithStack(err).Error(err)
@@ -32,7 +32,7 @@ func TestToken_formInputSuccess(t *testing.T) {
 
 	jwtPrivateKey, err := jwtkey.GenerateKey()
 	require.NoError(t, err)
-	jwk := jose.JSONWebKey{Key: jwtPrivateKey, Algorithm: string(jose.ES256)}
+	jwk := jose.JSONWebKey{Key: jwtPrivateKey, KeyID: "jwtkey1", Algorithm: string(jose.ES256)}
 
 	account := keypair.MustRandom()
 	t.Logf("Client account: %s", account.Address())
@@ -116,6 +116,7 @@ func TestToken_formInputSuccess(t *testing.T) {
 		return &jwtPrivateKey.PublicKey, nil
 	})
 	require.NoError(t, err)
+	assert.Equal(t, "jwtkey1", token.Header["kid"])
 
 	claims := token.Claims.(jwt.MapClaims)
 	assert.Equal(t, "https://example.com", claims["iss"])
@@ -1,5 +1,6 @@
 // Package jwtkey provides utility functions for generating, serializing and
-// deserializing JWT ECDSA keys.
+// deserializing JWT ECDSA keys, and for publishing and selecting the keys of a
+// JSON Web Key Set (JWKS) by key ID so that keys can be rotated.
 //
 // TODO: Replace EC function usages with PKCS8 functions for supporting ECDSA
 // and RSA keys instead of only supporting ECDSA. The fact this package only
@@ -7,11 +8,15 @@
 package jwtkey
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 
 	"github.com/stellar/go/support/errors"
+	"gopkg.in/square/go-jose.v2"
 )
 
 // GenerateKey is a convenience function for generating an ECDSA key for use as
@@ -24,3 +29,64 @@ func GenerateKey() (*ecdsa.PrivateKey, error) {
 	}
 	return k, nil
 }
+
+// KeyID returns the RFC 7638 SHA-256 thumbprint of the key, base64url
+// encoded, for use as the key ID (kid) of a JWK that does not have one. The
+// thumbprint of a private key is the same as the one of its public key.
+// Symmetric keys are supported too, although they cannot be published.
+func KeyID(k jose.JSONWebKey) (string, error) {
+	if key, ok := k.Key.([]byte); ok {
+		// the members of the thumbprint of symmetric keys, in lexicographic
+		// order, as defined by RFC 7638 section 3.2
+		input := `{"k":"` + base64.RawURLEncoding.EncodeToString(key) + `","kty":"oct"}`
+		t := sha256.Sum256([]byte(input))
+		return base64.RawURLEncoding.EncodeToString(t[:]), nil
+	}
+	t, err := k.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errors.Wrap(err, "calculating JWK thumbprint")
+	}
+	return base64.RawURLEncoding.EncodeToString(t), nil
+}
+
+// IsSymmetric returns true if the key is a symmetric key, e.g. a HS256 key,
+// which cannot be part of a published JWKS.
+func IsSymmetric(k jose.JSONWebKey) bool {
+	_, ok := k.Key.([]byte)
+	return ok
+}
+
+// PublicKeySet returns a JWKS containing the public keys of the given keys,
+// that can be published for verifying the JWTs signed with them. Keys without
+// a key ID are given their thumbprint as key ID.
+func PublicKeySet(keys ...jose.JSONWebKey) (jose.JSONWebKeySet, error) {
+	ks := jose.JSONWebKeySet{Keys: make([]jose.JSONWebKey, 0, len(keys))}
+	for _, k := range keys {
+		pk := k.Public()
+		if !pk.Valid() {
+			return jose.JSONWebKeySet{}, errors.New("key is not a valid asymmetric key")
+		}
+		if pk.KeyID == "" {
+			kid, err := KeyID(pk)
+			if err != nil {
+				return jose.JSONWebKeySet{}, err
+			}
+			pk.KeyID = kid
+		}
+		ks.Keys = append(ks.Keys, pk)
+	}
+	return ks, nil
+}
+
+// VerificationKeys returns the keys of the JWKS that should be used to verify
+// a JWT with the given key ID (kid) header. These are the keys with that key
+// ID, or all the keys of the set if the JWT has no key ID or none of the keys
+// has it, which keeps JWTs signed before keys had IDs verifiable.
+func VerificationKeys(ks jose.JSONWebKeySet, kid string) []jose.JSONWebKey {
+	if kid != "" {
+		if keys := ks.Key(kid); len(keys) > 0 {
+			return keys
+		}
+	}
+	return ks.Keys
+}
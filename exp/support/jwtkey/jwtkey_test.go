@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
 )
 
 func TestGenerate(t *testing.T) {
@@ -13,3 +14,68 @@ func TestGenerate(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, elliptic.P256(), key.Curve)
 }
+
+func TestKeyID(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	kid, err := KeyID(jose.JSONWebKey{Key: key})
+	require.NoError(t, err)
+	assert.Len(t, kid, 43)
+
+	// the private and public keys have the same key ID
+	publicKID, err := KeyID(jose.JSONWebKey{Key: &key.PublicKey})
+	require.NoError(t, err)
+	assert.Equal(t, kid, publicKID)
+
+	otherKey, err := GenerateKey()
+	require.NoError(t, err)
+	otherKID, err := KeyID(jose.JSONWebKey{Key: otherKey})
+	require.NoError(t, err)
+	assert.NotEqual(t, kid, otherKID)
+
+	// the thumbprint of a symmetric key is the SHA-256 hash of
+	// {"k":"c2VjcmV0","kty":"oct"}
+	symmetricKID, err := KeyID(jose.JSONWebKey{Key: []byte("secret")})
+	require.NoError(t, err)
+	assert.Equal(t, "DWBh0SEIAPYh1x5uvot4z3AhaikHkxNJa3Ada2fT-Cg", symmetricKID)
+	assert.True(t, IsSymmetric(jose.JSONWebKey{Key: []byte("secret")}))
+	assert.False(t, IsSymmetric(jose.JSONWebKey{Key: key}))
+}
+
+func TestPublicKeySet(t *testing.T) {
+	current, err := GenerateKey()
+	require.NoError(t, err)
+	previous, err := GenerateKey()
+	require.NoError(t, err)
+
+	ks, err := PublicKeySet(
+		jose.JSONWebKey{Key: current, KeyID: "current", Algorithm: string(jose.ES256)},
+		jose.JSONWebKey{Key: previous, Algorithm: string(jose.ES256)},
+	)
+	require.NoError(t, err)
+	require.Len(t, ks.Keys, 2)
+
+	assert.Equal(t, "current", ks.Keys[0].KeyID)
+	assert.Equal(t, &current.PublicKey, ks.Keys[0].Key)
+	assert.Equal(t, string(jose.ES256), ks.Keys[0].Algorithm)
+	previousKID, err := KeyID(jose.JSONWebKey{Key: previous})
+	require.NoError(t, err)
+	assert.Equal(t, previousKID, ks.Keys[1].KeyID)
+	assert.Equal(t, &previous.PublicKey, ks.Keys[1].Key)
+
+	_, err = PublicKeySet(jose.JSONWebKey{Key: []byte("secret")})
+	assert.EqualError(t, err, "key is not a valid asymmetric key")
+}
+
+func TestVerificationKeys(t *testing.T) {
+	ks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{KeyID: "a"},
+		{KeyID: "b"},
+		{},
+	}}
+
+	assert.Equal(t, []jose.JSONWebKey{{KeyID: "b"}}, VerificationKeys(ks, "b"))
+	assert.Equal(t, ks.Keys, VerificationKeys(ks, ""))
+	assert.Equal(t, ks.Keys, VerificationKeys(ks, "c"))
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/stellar/go/support/errors"
@@ -46,9 +47,21 @@ type ConnectOptions struct {
 	S3Region          string
 	S3Endpoint        string
 	UnsignedRequests  bool
+	// GCSEndpoint overrides the Google Cloud Storage endpoint, e.g. to use
+	// fake-gcs-server.
+	GCSEndpoint string
+	// AzureAccount is the Azure storage account of azblob:// archives, it
+	// defaults to the AZURE_STORAGE_ACCOUNT environment variable.
+	AzureAccount string
+	// AzureEndpoint overrides the Azure Blob Storage endpoint of the account,
+	// e.g. http://127.0.0.1:10000/devstoreaccount1 to use Azurite.
+	AzureEndpoint string
 	// CheckpointFrequency is the number of ledgers between checkpoints
 	// if unset, DefaultCheckpointFrequency will be used
 	CheckpointFrequency uint32
+	// Cache keeps the files read from the archive on disk when its Path is
+	// set, see ArchiveBackendCache.
+	Cache CacheOptions
 }
 
 type Ledger struct {
@@ -115,6 +128,14 @@ type Archive struct {
 	backend ArchiveBackend
 }
 
+// RegisterMetrics registers the prometheus metrics of the archive cache, if
+// the archive is cached.
+func (a *Archive) RegisterMetrics(registry *prometheus.Registry) {
+	if cache, ok := a.backend.(*ArchiveBackendCache); ok {
+		cache.RegisterMetrics(registry)
+	}
+}
+
 func (arch *Archive) GetCheckpointManager() CheckpointManager {
 	return arch.checkpointManager
 }
@@ -414,6 +435,11 @@ func Connect(u string, opts ConnectOptions) (*Archive, error) {
 			pth = pth[1:]
 		}
 		arch.backend, err = makeS3Backend(parsed.Host, pth, opts)
+	} else if parsed.Scheme == "gcs" {
+		// Like s3, object names do not start with a /
+		arch.backend, err = makeGCSBackend(parsed.Host, strings.TrimPrefix(pth, "/"), opts)
+	} else if parsed.Scheme == "azblob" {
+		arch.backend, err = makeAzureBlobBackend(parsed.Host, strings.TrimPrefix(pth, "/"), opts)
 	} else if parsed.Scheme == "file" {
 		pth = path.Join(parsed.Host, pth)
 		arch.backend = makeFsBackend(pth, opts)
@@ -424,6 +450,9 @@ func Connect(u string, opts ConnectOptions) (*Archive, error) {
 	} else {
 		err = errors.New("unknown URL scheme: '" + parsed.Scheme + "'")
 	}
+	if err == nil && opts.Cache.Path != "" {
+		arch.backend, err = makeArchiveBackendCache(arch.backend, opts.Cache)
+	}
 	return &arch, err
 }
 
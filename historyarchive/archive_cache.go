@@ -0,0 +1,452 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"container/list"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// cacheTempPrefix is the prefix of files which are being downloaded in the
+// cache directory.
+const cacheTempPrefix = ".download-"
+
+var bucketPathRegexp = regexp.MustCompile("^bucket" + hexPrefixPat + "bucket-([0-9a-f]{64})\\.xdr\\.gz$")
+
+// CacheOptions configures the on-disk cache of an archive.
+type CacheOptions struct {
+	// Path is the directory of the cached files, the cache is disabled when
+	// it is empty.
+	Path string
+	// MaxSize is the maximum total size in bytes of the cached files.
+	MaxSize int64
+}
+
+// CacheMetrics are the prometheus metrics of an ArchiveBackendCache.
+type CacheMetrics struct {
+	// Hits counts the files read from the cache.
+	Hits prometheus.Counter
+	// Misses counts the files downloaded from the archive.
+	Misses prometheus.Counter
+	// Evictions counts the files removed from the cache to make room for
+	// new files.
+	Evictions prometheus.Counter
+	// InvalidBuckets counts the downloaded buckets which did not match their
+	// hash.
+	InvalidBuckets prometheus.Counter
+	// Size is the total size in bytes of the cached files.
+	Size prometheus.Gauge
+	// Files is the number of cached files.
+	Files prometheus.Gauge
+}
+
+func newCacheMetrics() CacheMetrics {
+	return CacheMetrics{
+		Hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "history_archive", Subsystem: "cache", Name: "hits_total",
+			Help: "number of files read from the history archive cache",
+		}),
+		Misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "history_archive", Subsystem: "cache", Name: "misses_total",
+			Help: "number of files downloaded because they were not in the history archive cache",
+		}),
+		Evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "history_archive", Subsystem: "cache", Name: "evictions_total",
+			Help: "number of files evicted from the history archive cache",
+		}),
+		InvalidBuckets: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "history_archive", Subsystem: "cache", Name: "invalid_buckets_total",
+			Help: "number of downloaded buckets which did not match their hash",
+		}),
+		Size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "history_archive", Subsystem: "cache", Name: "size_bytes",
+			Help: "total size of the files in the history archive cache",
+		}),
+		Files: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "history_archive", Subsystem: "cache", Name: "files",
+			Help: "number of files in the history archive cache",
+		}),
+	}
+}
+
+type cacheEntry struct {
+	path string
+	size int64
+}
+
+// ArchiveBackendCache is an ArchiveBackend which keeps the files read from
+// another backend on disk, evicting the least recently used files when the
+// cache exceeds its maximum size. Buckets are checked against their hash
+// before being cached. The root HAS, which changes as checkpoints are
+// published, is never cached.
+//
+// The caches of all the archives connected with the same cache path share
+// their size accounting, LRU state and metrics, so that archives used
+// concurrently, e.g. by parallel reingestion workers, do not evict each
+// other's files. A cache path should only be used for a single network.
+type ArchiveBackendCache struct {
+	backend ArchiveBackend
+	*cacheStore
+}
+
+// cacheStore is the state of the files in a cache directory.
+type cacheStore struct {
+	path    string
+	maxSize int64
+	metrics CacheMetrics
+
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	// lru is ordered from the most to the least recently used entry
+	lru  *list.List
+	size int64
+}
+
+// cacheStores are the cache stores in use, by cache path.
+var cacheStores = struct {
+	sync.Mutex
+	stores map[string]*cacheStore
+}{stores: map[string]*cacheStore{}}
+
+func (c *ArchiveBackendCache) cacheable(pth string) bool {
+	return path.Clean(pth) != rootHASPath
+}
+
+func (c *cacheStore) localPath(pth string) string {
+	return filepath.Join(c.path, filepath.FromSlash(path.Clean("/"+pth)))
+}
+
+func (c *cacheStore) lookup(pth string) (cacheEntry, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[pth]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return *elem.Value.(*cacheEntry), true
+}
+
+// add adds a file to the cache and evicts the least recently used files
+// until the cache fits its maximum size.
+func (c *cacheStore) add(pth string, size int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, ok := c.entries[pth]; ok {
+		c.size -= elem.Value.(*cacheEntry).size
+		elem.Value.(*cacheEntry).size = size
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[pth] = c.lru.PushFront(&cacheEntry{path: pth, size: size})
+	}
+	c.size += size
+
+	for c.size > c.maxSize && c.lru.Len() > 1 {
+		entry := c.lru.Remove(c.lru.Back()).(*cacheEntry)
+		delete(c.entries, entry.path)
+		c.size -= entry.size
+		if err := os.Remove(c.localPath(entry.path)); err != nil && !os.IsNotExist(err) {
+			log.WithField("path", entry.path).WithError(err).Warn("cache: could not evict file")
+		}
+		c.metrics.Evictions.Inc()
+	}
+	c.updateGauges()
+}
+
+func (c *cacheStore) remove(pth string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[pth]
+	if !ok {
+		return
+	}
+	c.lru.Remove(elem)
+	delete(c.entries, pth)
+	c.size -= elem.Value.(*cacheEntry).size
+	os.Remove(c.localPath(pth))
+	c.updateGauges()
+}
+
+func (c *cacheStore) updateGauges() {
+	c.metrics.Size.Set(float64(c.size))
+	c.metrics.Files.Set(float64(c.lru.Len()))
+}
+
+// open opens a cached file, the modification time of the file is updated so
+// the order of the entries is kept when the cache is loaded again.
+func (c *ArchiveBackendCache) open(pth string) (io.ReadCloser, bool) {
+	if _, ok := c.lookup(pth); !ok {
+		return nil, false
+	}
+	local := c.localPath(pth)
+	f, err := os.Open(local)
+	if err != nil {
+		// the file was removed behind our back
+		log.WithField("path", pth).WithError(err).Warn("cache: could not open cached file")
+		c.remove(pth)
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(local, now, now)
+	return f, true
+}
+
+// validateBucket checks that the uncompressed content of a bucket matches the
+// hash in its name.
+func validateBucket(pth string, f *os.File) error {
+	matches := bucketPathRegexp.FindStringSubmatch(path.Clean(pth))
+	if matches == nil {
+		return nil
+	}
+	expected, err := DecodeHash(matches[1])
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	rdr, err := gzip.NewReader(f)
+	if err != nil {
+		return errors.Wrapf(err, "could not decompress bucket %s", pth)
+	}
+	defer rdr.Close()
+	hsh := sha256.New()
+	if _, err = io.Copy(hsh, rdr); err != nil {
+		return errors.Wrapf(err, "could not decompress bucket %s", pth)
+	}
+	if !bytes.Equal(hsh.Sum(nil), expected[:]) {
+		return errors.Errorf("bucket %s does not match its hash", pth)
+	}
+	return nil
+}
+
+// removeOnClose is a downloaded file which is too large to be cached.
+type removeOnClose struct {
+	*os.File
+}
+
+func (f removeOnClose) Close() error {
+	err := f.File.Close()
+	os.Remove(f.File.Name())
+	return err
+}
+
+func (c *ArchiveBackendCache) download(pth string) (io.ReadCloser, error) {
+	rdr, err := c.backend.GetFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+
+	tmp, err := ioutil.TempFile(c.path, cacheTempPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cache file")
+	}
+	fail := func(err error) (io.ReadCloser, error) {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	size, err := io.Copy(tmp, rdr)
+	if err != nil {
+		return fail(errors.Wrapf(err, "could not download %s", pth))
+	}
+	if err = validateBucket(pth, tmp); err != nil {
+		c.metrics.InvalidBuckets.Inc()
+		return fail(err)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+
+	if size > c.maxSize {
+		return removeOnClose{tmp}, nil
+	}
+	local := c.localPath(pth)
+	if err = os.MkdirAll(filepath.Dir(local), 0755); err != nil {
+		return fail(errors.Wrap(err, "could not create cache directory"))
+	}
+	// tmp stays readable after it is renamed, or evicted
+	if err = os.Rename(tmp.Name(), local); err != nil {
+		return fail(errors.Wrap(err, "could not move downloaded file to the cache"))
+	}
+	c.add(pth, size)
+	return tmp, nil
+}
+
+func (c *ArchiveBackendCache) GetFile(pth string) (io.ReadCloser, error) {
+	if !c.cacheable(pth) {
+		return c.backend.GetFile(pth)
+	}
+	if f, ok := c.open(pth); ok {
+		log.WithField("path", pth).Trace("cache: hit")
+		c.metrics.Hits.Inc()
+		return f, nil
+	}
+	log.WithField("path", pth).Trace("cache: miss")
+	c.metrics.Misses.Inc()
+	return c.download(pth)
+}
+
+func (c *ArchiveBackendCache) Exists(pth string) (bool, error) {
+	if _, ok := c.lookup(pth); ok {
+		return true, nil
+	}
+	return c.backend.Exists(pth)
+}
+
+func (c *ArchiveBackendCache) Size(pth string) (int64, error) {
+	if entry, ok := c.lookup(pth); ok {
+		return entry.size, nil
+	}
+	return c.backend.Size(pth)
+}
+
+func (c *ArchiveBackendCache) PutFile(pth string, in io.ReadCloser) error {
+	c.remove(pth)
+	return c.backend.PutFile(pth, in)
+}
+
+func (c *ArchiveBackendCache) ListFiles(pth string) (chan string, chan error) {
+	return c.backend.ListFiles(pth)
+}
+
+func (c *ArchiveBackendCache) CanListFiles() bool {
+	return c.backend.CanListFiles()
+}
+
+// Metrics returns the metrics of the cache.
+func (c *ArchiveBackendCache) Metrics() CacheMetrics {
+	return c.metrics
+}
+
+// RegisterMetrics registers the prometheus metrics of the cache. The metrics
+// are shared by the caches with the same path, so registering them again is a
+// no-op.
+func (c *ArchiveBackendCache) RegisterMetrics(registry *prometheus.Registry) {
+	for _, collector := range []prometheus.Collector{
+		c.metrics.Hits,
+		c.metrics.Misses,
+		c.metrics.Evictions,
+		c.metrics.InvalidBuckets,
+		c.metrics.Size,
+		c.metrics.Files,
+	} {
+		if err := registry.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				panic(err)
+			}
+		}
+	}
+}
+
+// load adds the files left in the cache directory by a previous run, the
+// least recently used first.
+func (c *cacheStore) load() error {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	err := filepath.Walk(c.path, func(local string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(info.Name(), cacheTempPrefix) {
+			// interrupted download
+			return os.Remove(local)
+		}
+		rel, err := filepath.Rel(c.path, local)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{filepath.ToSlash(rel), info.Size(), info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		c.add(f.path, f.size)
+	}
+	return nil
+}
+
+func newCacheStore(opts CacheOptions) (*cacheStore, error) {
+	if err := os.MkdirAll(opts.Path, 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create cache directory")
+	}
+	store := &cacheStore{
+		path:    opts.Path,
+		maxSize: opts.MaxSize,
+		metrics: newCacheMetrics(),
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+	if err := store.load(); err != nil {
+		return nil, errors.Wrap(err, "could not load cache directory")
+	}
+	return store, nil
+}
+
+// openCacheStore returns the cache store of a path, which is loaded from disk
+// the first time the path is used.
+func openCacheStore(opts CacheOptions) (*cacheStore, error) {
+	key, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cache path")
+	}
+
+	cacheStores.Lock()
+	defer cacheStores.Unlock()
+	if store, ok := cacheStores.stores[key]; ok {
+		if store.maxSize != opts.MaxSize {
+			return nil, errors.Errorf("cache %s is already used with a size of %d", opts.Path, store.maxSize)
+		}
+		return store, nil
+	}
+	store, err := newCacheStore(opts)
+	if err != nil {
+		return nil, err
+	}
+	cacheStores.stores[key] = store
+	return store, nil
+}
+
+func makeArchiveBackendCache(backend ArchiveBackend, opts CacheOptions) (*ArchiveBackendCache, error) {
+	if opts.MaxSize <= 0 {
+		return nil, errors.New("cache size must be positive")
+	}
+	log.WithFields(log.Fields{"path": opts.Path,
+		"max_size": opts.MaxSize}).Debug("cache: making backend")
+
+	store, err := openCacheStore(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveBackendCache{backend: backend, cacheStore: store}, nil
+}
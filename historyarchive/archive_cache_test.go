@@ -0,0 +1,152 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, content []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func readCachedFile(t *testing.T, cache *ArchiveBackendCache, pth string) string {
+	rdr, err := cache.GetFile(pth)
+	require.NoError(t, err)
+	defer rdr.Close()
+	content, err := ioutil.ReadAll(rdr)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestArchiveBackendCache(t *testing.T) {
+	backend := makeMockBackend(ConnectOptions{}).(*MockArchiveBackend)
+	backend.files["a"] = []byte("aaaa")
+	backend.files["b"] = []byte("bbbb")
+	backend.files["c"] = []byte("cccc")
+	backend.files[rootHASPath] = []byte("{}")
+
+	dir := t.TempDir()
+	cache, err := makeArchiveBackendCache(backend, CacheOptions{Path: dir, MaxSize: 8})
+	require.NoError(t, err)
+	metrics := cache.Metrics()
+
+	assert.Equal(t, "aaaa", readCachedFile(t, cache, "a"))
+	assert.Equal(t, "aaaa", readCachedFile(t, cache, "a"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Misses))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Hits))
+
+	// cached files are served without the backend
+	backend.files["a"] = []byte("changed")
+	assert.Equal(t, "aaaa", readCachedFile(t, cache, "a"))
+	size, err := cache.Size("a")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), size)
+
+	// the root HAS is always read from the backend
+	assert.Equal(t, "{}", readCachedFile(t, cache, rootHASPath))
+	backend.files[rootHASPath] = []byte("{ }")
+	assert.Equal(t, "{ }", readCachedFile(t, cache, rootHASPath))
+	assert.NoFileExists(t, filepath.Join(dir, rootHASPath))
+
+	// b is evicted, as a was used more recently
+	readCachedFile(t, cache, "b")
+	readCachedFile(t, cache, "a")
+	readCachedFile(t, cache, "c")
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Evictions))
+	assert.Equal(t, 8.0, testutil.ToFloat64(metrics.Size))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.Files))
+	assert.FileExists(t, filepath.Join(dir, "a"))
+	assert.NoFileExists(t, filepath.Join(dir, "b"))
+	assert.FileExists(t, filepath.Join(dir, "c"))
+
+	// files larger than the cache are not cached
+	backend.files["large"] = []byte("0123456789")
+	assert.Equal(t, "0123456789", readCachedFile(t, cache, "large"))
+	assert.NoFileExists(t, filepath.Join(dir, "large"))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.Files))
+
+	// written files are not served from the cache
+	require.NoError(t, cache.PutFile("c", ioutil.NopCloser(bytes.NewReader([]byte("dddd")))))
+	assert.Equal(t, "dddd", readCachedFile(t, cache, "c"))
+
+	// the cache is kept across restarts
+	store, err := newCacheStore(CacheOptions{Path: dir, MaxSize: 8})
+	require.NoError(t, err)
+	cache = &ArchiveBackendCache{backend: backend, cacheStore: store}
+	assert.Equal(t, "aaaa", readCachedFile(t, cache, "a"))
+	assert.Equal(t, 1.0, testutil.ToFloat64(cache.Metrics().Hits))
+}
+
+func TestArchiveBackendCacheBucketHash(t *testing.T) {
+	backend := makeMockBackend(ConnectOptions{}).(*MockArchiveBackend)
+	content := []byte("bucket entries")
+	hash := Hash(sha256.Sum256(content))
+	backend.files[BucketPath(hash)] = gzipBytes(t, content)
+
+	cache, err := makeArchiveBackendCache(backend, CacheOptions{Path: t.TempDir(), MaxSize: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, string(gzipBytes(t, content)), readCachedFile(t, cache, BucketPath(hash)))
+
+	corrupted := Hash(sha256.Sum256([]byte("other entries")))
+	backend.files[BucketPath(corrupted)] = gzipBytes(t, content)
+	_, err = cache.GetFile(BucketPath(corrupted))
+	assert.EqualError(t, err, "bucket "+BucketPath(corrupted)+" does not match its hash")
+	assert.Equal(t, 1.0, testutil.ToFloat64(cache.Metrics().InvalidBuckets))
+	exists, err := cache.Exists(BucketPath(corrupted))
+	assert.NoError(t, err)
+	assert.True(t, exists, "exists in the backend")
+	assert.Equal(t, 1.0, testutil.ToFloat64(cache.Metrics().Files))
+
+	entries, err := os.ReadDir(cache.path)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), cacheTempPrefix)
+	}
+}
+
+func TestConnectWithCache(t *testing.T) {
+	arch, err := Connect("mock://test", ConnectOptions{
+		Cache: CacheOptions{Path: t.TempDir(), MaxSize: 1024},
+	})
+	require.NoError(t, err)
+	assert.IsType(t, &ArchiveBackendCache{}, arch.backend)
+}
+
+func TestConnectWithSharedCache(t *testing.T) {
+	opts := ConnectOptions{Cache: CacheOptions{Path: t.TempDir(), MaxSize: 1024}}
+	first, err := Connect("mock://test", opts)
+	require.NoError(t, err)
+	second, err := Connect("mock://test", opts)
+	require.NoError(t, err)
+
+	firstCache := first.backend.(*ArchiveBackendCache)
+	secondCache := second.backend.(*ArchiveBackendCache)
+	assert.Same(t, firstCache.cacheStore, secondCache.cacheStore)
+
+	registry := prometheus.NewRegistry()
+	firstCache.RegisterMetrics(registry)
+	secondCache.RegisterMetrics(registry)
+
+	opts.Cache.MaxSize = 2048
+	_, err = Connect("mock://test", opts)
+	assert.EqualError(t, err, "cache "+opts.Cache.Path+" is already used with a size of 1024")
+}
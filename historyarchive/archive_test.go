@@ -7,18 +7,22 @@ package historyarchive
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/big"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 
+	"cloud.google.com/go/storage"
 	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
 )
 
 func GetTestS3Archive() *Archive {
@@ -38,6 +42,71 @@ func GetTestS3Archive() *Archive {
 	return MustConnect(bucket, ConnectOptions{S3Region: region, CheckpointFrequency: 64})
 }
 
+// GetTestGCSArchive connects to a Google Cloud Storage bucket, set
+// STORAGE_EMULATOR_HOST to run the tests against fake-gcs-server, e.g.:
+//
+//	docker run -p 4443:4443 fsouza/fake-gcs-server -scheme http -public-host localhost:4443
+//	STORAGE_EMULATOR_HOST=localhost:4443 ARCHIVIST_TEST_TYPE=gcs go test ./historyarchive/...
+func GetTestGCSArchive() *Archive {
+	mx := big.NewInt(0xffffffff)
+	r, e := rand.Int(rand.Reader, mx)
+	if e != nil {
+		panic(e)
+	}
+	bucket := "archivist-test"
+	if env_bucket := os.Getenv("ARCHIVIST_TEST_GCS_BUCKET"); env_bucket != "" {
+		bucket = env_bucket
+	}
+	if os.Getenv("STORAGE_EMULATOR_HOST") != "" {
+		// buckets have to be created in the emulator
+		client, err := storage.NewClient(context.Background(), option.WithoutAuthentication())
+		if err != nil {
+			panic(err)
+		}
+		err = client.Bucket(bucket).Create(context.Background(), "test", nil)
+		if err != nil && !strings.Contains(err.Error(), "409") {
+			panic(err)
+		}
+	}
+	return MustConnect(
+		fmt.Sprintf("gcs://%s/archivist/test-%s", bucket, r),
+		ConnectOptions{CheckpointFrequency: 64},
+	)
+}
+
+// GetTestAzureBlobArchive connects to an Azure Blob Storage container, set
+// ARCHIVIST_TEST_AZBLOB_ENDPOINT to run the tests against Azurite, e.g.:
+//
+//	docker run -p 10000:10000 mcr.microsoft.com/azure-storage/azurite azurite-blob --blobHost 0.0.0.0
+//	ARCHIVIST_TEST_TYPE=azblob ARCHIVIST_TEST_AZBLOB_ENDPOINT=http://127.0.0.1:10000/devstoreaccount1 \
+//	AZURE_STORAGE_ACCOUNT=devstoreaccount1 AZURE_STORAGE_KEY=<azurite account key> go test ./historyarchive/...
+func GetTestAzureBlobArchive() *Archive {
+	mx := big.NewInt(0xffffffff)
+	r, e := rand.Int(rand.Reader, mx)
+	if e != nil {
+		panic(e)
+	}
+	container := "archivist-test"
+	if env_container := os.Getenv("ARCHIVIST_TEST_AZBLOB_CONTAINER"); env_container != "" {
+		container = env_container
+	}
+	arch := MustConnect(
+		fmt.Sprintf("azblob://%s/archivist/test-%s", container, r),
+		ConnectOptions{
+			CheckpointFrequency: 64,
+			AzureEndpoint:       os.Getenv("ARCHIVIST_TEST_AZBLOB_ENDPOINT"),
+		},
+	)
+	// containers have to be created in the emulator
+	backend := arch.backend.(*AzureBlobArchiveBackend)
+	resp, err := backend.do("PUT", backend.url("", url.Values{"restype": {"container"}}), []byte{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	resp.Body.Close()
+	return arch
+}
+
 func GetTestMockArchive() *Archive {
 	return MustConnect("mock://test", ConnectOptions{CheckpointFrequency: 64})
 }
@@ -69,6 +138,10 @@ func GetTestArchive() *Archive {
 		return GetTestFileArchive()
 	} else if ty == "s3" {
 		return GetTestS3Archive()
+	} else if ty == "gcs" {
+		return GetTestGCSArchive()
+	} else if ty == "azblob" {
+		return GetTestAzureBlobArchive()
 	} else {
 		return GetTestMockArchive()
 	}
@@ -0,0 +1,361 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/stellar/go/support/errors"
+)
+
+const azureBlobAPIVersion = "2020-04-08"
+
+// azureBlockSize is the size of the blocks files larger than one block are
+// uploaded in, which bounds the memory used by PutFile.
+const azureBlockSize = 4 << 20
+
+// AzureBlobArchiveBackend is an ArchiveBackend for archives stored in Azure
+// Blob Storage, accessed with the Blob service REST API. Requests are signed
+// with the account key when one is configured, otherwise the SAS token, if
+// any, is appended to every request.
+type AzureBlobArchiveBackend struct {
+	ctx        context.Context
+	client     http.Client
+	endpoint   url.URL
+	account    string
+	accountKey []byte
+	sasToken   url.Values
+	container  string
+	prefix     string
+}
+
+// azureBlobList is the response of the List Blobs operation.
+type azureBlobList struct {
+	Blobs struct {
+		Blob []struct {
+			Name string `xml:"Name"`
+		} `xml:"Blob"`
+	} `xml:"Blobs"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+func (b *AzureBlobArchiveBackend) url(key string, query url.Values) url.URL {
+	derived := b.endpoint
+	derived.Path = path.Join("/", derived.Path, b.container, key)
+	if query == nil {
+		query = url.Values{}
+	}
+	for k, v := range b.sasToken {
+		query[k] = v
+	}
+	derived.RawQuery = query.Encode()
+	return derived
+}
+
+func (b *AzureBlobArchiveBackend) do(method string, u url.URL, body []byte, header http.Header) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	if b.accountKey != nil {
+		req.Header.Set("Authorization", "SharedKey "+b.account+":"+b.sign(req))
+	}
+	req = req.WithContext(b.ctx)
+	logReq(req)
+	resp, err := b.client.Do(req)
+	logResp(resp)
+	return resp, err
+}
+
+// sign returns the Shared Key signature of req, see
+// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (b *AzureBlobArchiveBackend) sign(req *http.Request) string {
+	mac := hmac.New(sha256.New, b.accountKey)
+	mac.Write([]byte(b.stringToSign(req)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalizedResource returns the resource part of the string to sign of a
+// request to u.
+func (b *AzureBlobArchiveBackend) canonicalizedResource(u *url.URL) string {
+	resource := "/" + b.account + u.EscapedPath()
+	query := u.Query()
+	var params []string
+	for k := range query {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		values := query[k]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(k) + ":" + strings.Join(values, ",")
+	}
+	return resource
+}
+
+func (b *AzureBlobArchiveBackend) stringToSign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for k := range req.Header {
+		if lower := strings.ToLower(k); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(req.Header.Get(k)))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		b.canonicalizedResource(req.URL),
+	}, "\n")
+}
+
+func (b *AzureBlobArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
+	resp, err := b.do("GET", b.url(path.Join(b.prefix, pth), nil), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkResp(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (b *AzureBlobArchiveBackend) Head(pth string) (*http.Response, error) {
+	resp, err := b.do("HEAD", b.url(path.Join(b.prefix, pth), nil), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+func (b *AzureBlobArchiveBackend) Exists(pth string) (bool, error) {
+	resp, err := b.Head(pth)
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return true, nil
+	} else if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	} else {
+		return false, errors.Errorf("Unkown status code=%d", resp.StatusCode)
+	}
+}
+
+func (b *AzureBlobArchiveBackend) Size(pth string) (int64, error) {
+	resp, err := b.Head(pth)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
+		return resp.ContentLength, nil
+	} else if resp.StatusCode == http.StatusNotFound {
+		return 0, nil
+	} else {
+		return 0, errors.Errorf("Unkown status code=%d", resp.StatusCode)
+	}
+}
+
+// PutFile uploads files that fit in a single block with one Put Blob request.
+// Larger files are streamed as a sequence of Put Block requests committed with
+// Put Block List, so that at most one block is held in memory.
+func (b *AzureBlobArchiveBackend) PutFile(pth string, in io.ReadCloser) error {
+	defer in.Close()
+	key := path.Join(b.prefix, pth)
+
+	buf := make([]byte, azureBlockSize)
+	n, err := io.ReadFull(in, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		header := http.Header{}
+		header.Set("x-ms-blob-type", "BlockBlob")
+		return b.put(b.url(key, nil), buf[:n], header)
+	} else if err != nil {
+		return err
+	}
+
+	var blockIDs []string
+	for n > 0 {
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%08d", len(blockIDs))))
+		query := url.Values{"comp": {"block"}, "blockid": {id}}
+		if err = b.put(b.url(key, query), buf[:n], nil); err != nil {
+			return errors.Wrapf(err, "could not put block %d of %s", len(blockIDs), pth)
+		}
+		blockIDs = append(blockIDs, id)
+
+		n, err = io.ReadFull(in, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+	}
+
+	var list bytes.Buffer
+	list.WriteString(xml.Header)
+	list.WriteString("<BlockList>")
+	for _, id := range blockIDs {
+		list.WriteString("<Latest>" + id + "</Latest>")
+	}
+	list.WriteString("</BlockList>")
+	query := url.Values{"comp": {"blocklist"}}
+	return b.put(b.url(key, query), list.Bytes(), nil)
+}
+
+func (b *AzureBlobArchiveBackend) put(u url.URL, body []byte, header http.Header) error {
+	resp, err := b.do("PUT", u, body, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResp(resp)
+}
+
+func (b *AzureBlobArchiveBackend) listPage(prefix, marker string) (azureBlobList, error) {
+	var list azureBlobList
+	query := url.Values{
+		"restype": {"container"},
+		"comp":    {"list"},
+		"prefix":  {prefix},
+	}
+	if marker != "" {
+		query.Set("marker", marker)
+	}
+	resp, err := b.do("GET", b.url("", query), nil, nil)
+	if err != nil {
+		return list, err
+	}
+	defer resp.Body.Close()
+	if err = checkResp(resp); err != nil {
+		return list, err
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&list)
+	return list, errors.Wrap(err, "could not decode blob list")
+}
+
+func (b *AzureBlobArchiveBackend) ListFiles(pth string) (chan string, chan error) {
+	prefix := path.Join(b.prefix, pth)
+	ch := make(chan string)
+	errs := make(chan error)
+
+	go func() {
+		marker := ""
+		for {
+			list, err := b.listPage(prefix, marker)
+			if err != nil {
+				errs <- err
+				break
+			}
+			for _, blob := range list.Blobs.Blob {
+				log.WithField("key", blob.Name).Trace("azblob: ListFiles")
+				ch <- blob.Name
+			}
+			if list.NextMarker == "" {
+				break
+			}
+			marker = list.NextMarker
+		}
+		close(ch)
+		close(errs)
+	}()
+	return ch, errs
+}
+
+func (b *AzureBlobArchiveBackend) CanListFiles() bool {
+	return true
+}
+
+// makeAzureBlobBackend creates a backend for the given container. The
+// account, key and SAS token default to the AZURE_STORAGE_ACCOUNT,
+// AZURE_STORAGE_KEY and AZURE_STORAGE_SAS_TOKEN environment variables.
+func makeAzureBlobBackend(container string, prefix string, opts ConnectOptions) (ArchiveBackend, error) {
+	account := opts.AzureAccount
+	if account == "" {
+		account = os.Getenv("AZURE_STORAGE_ACCOUNT")
+	}
+	if account == "" {
+		return nil, errors.New("azure storage account is not set")
+	}
+
+	endpoint := opts.AzureEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid azure endpoint")
+	}
+
+	log.WithFields(log.Fields{"container": container,
+		"prefix":   prefix,
+		"account":  account,
+		"endpoint": endpoint}).Debug("azblob: making backend")
+
+	backend := AzureBlobArchiveBackend{
+		ctx:       opts.Context,
+		endpoint:  *parsed,
+		account:   account,
+		container: container,
+		prefix:    prefix,
+	}
+
+	if opts.UnsignedRequests {
+		return &backend, nil
+	}
+
+	if key := os.Getenv("AZURE_STORAGE_KEY"); key != "" {
+		backend.accountKey, err = base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid AZURE_STORAGE_KEY")
+		}
+	} else if token := os.Getenv("AZURE_STORAGE_SAS_TOKEN"); token != "" {
+		backend.sasToken, err = url.ParseQuery(strings.TrimPrefix(token, "?"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid AZURE_STORAGE_SAS_TOKEN")
+		}
+	}
+	return &backend, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Well known key of the Azurite emulator
+const azuriteKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+func TestAzureBlobCanonicalizedResource(t *testing.T) {
+	// Examples from
+	// https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key#shared-key-format-for-2009-09-19-and-later
+	backend := &AzureBlobArchiveBackend{account: "myaccount"}
+	for _, tc := range []struct {
+		url      string
+		resource string
+	}{
+		{
+			"https://myaccount.blob.core.windows.net/mycontainer?restype=container&comp=metadata",
+			"/myaccount/mycontainer\ncomp:metadata\nrestype:container",
+		},
+		{
+			"https://myaccount.blob.core.windows.net/mycontainer?restype=container&comp=list&include=snapshots&include=metadata&include=uncommittedblobs",
+			"/myaccount/mycontainer\ncomp:list\ninclude:metadata,snapshots,uncommittedblobs\nrestype:container",
+		},
+	} {
+		u, err := url.Parse(tc.url)
+		require.NoError(t, err)
+		assert.Equal(t, tc.resource, backend.canonicalizedResource(u), tc.url)
+	}
+
+	// The emulator takes the account from the path, so it appears twice
+	backend = &AzureBlobArchiveBackend{account: "devstoreaccount1"}
+	u, err := url.Parse("http://127.0.0.1:10000/devstoreaccount1/mycontainer?restype=container&comp=list")
+	require.NoError(t, err)
+	assert.Equal(t, "/devstoreaccount1/devstoreaccount1/mycontainer\ncomp:list\nrestype:container", backend.canonicalizedResource(u))
+}
+
+func TestAzureBlobSign(t *testing.T) {
+	key, err := base64.StdEncoding.DecodeString(azuriteKey)
+	require.NoError(t, err)
+	backend := &AzureBlobArchiveBackend{account: "devstoreaccount1", accountKey: key}
+
+	// The signatures are the base64 HMAC-SHA256 of the strings to sign with
+	// the Azurite key, e.g.
+	//   printf '%s' "$STRING_TO_SIGN" | openssl dgst -sha256 -binary \
+	//     -mac HMAC -macopt hexkey:$(echo $KEY | base64 -d | xxd -p -c 64) | base64
+	req, err := http.NewRequest(
+		"GET",
+		"http://127.0.0.1:10000/devstoreaccount1/history?comp=list&prefix=bucket&restype=container",
+		nil,
+	)
+	require.NoError(t, err)
+	req.Header.Set("x-ms-date", "Mon, 07 Jun 2021 00:00:00 GMT")
+	req.Header.Set("x-ms-version", "2020-04-08")
+	assert.Equal(t, "GET\n\n\n\n\n\n\n\n\n\n\n\n"+
+		"x-ms-date:Mon, 07 Jun 2021 00:00:00 GMT\n"+
+		"x-ms-version:2020-04-08\n"+
+		"/devstoreaccount1/devstoreaccount1/history\ncomp:list\nprefix:bucket\nrestype:container",
+		backend.stringToSign(req))
+	assert.Equal(t, "ngSMc7ipQIAzOSQsXHaFWAhYb7Kdn4c+Thtja3vG2O0=", backend.sign(req))
+
+	req, err = http.NewRequest(
+		"PUT",
+		"http://127.0.0.1:10000/devstoreaccount1/history/prefix/.well-known/stellar-history.json",
+		bytes.NewReader([]byte("hello")),
+	)
+	require.NoError(t, err)
+	req.Header.Set("x-ms-date", "Mon, 07 Jun 2021 00:00:00 GMT")
+	req.Header.Set("x-ms-version", "2020-04-08")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	assert.Equal(t, "PUT\n\n\n5\n\n\n\n\n\n\n\n\n"+
+		"x-ms-blob-type:BlockBlob\n"+
+		"x-ms-date:Mon, 07 Jun 2021 00:00:00 GMT\n"+
+		"x-ms-version:2020-04-08\n"+
+		"/devstoreaccount1/devstoreaccount1/history/prefix/.well-known/stellar-history.json",
+		backend.stringToSign(req))
+	assert.Equal(t, "p1Ii5VlhT9pF8gmJuV1/+ubTX7a/GSSnscxDZpHbRVQ=", backend.sign(req))
+}
+
+// fakeBlobService is a minimal in-memory Blob service for a single container,
+// returning at most two blobs per list page.
+type fakeBlobService struct {
+	sync.Mutex
+	prefix string
+	blobs  map[string][]byte
+	blocks map[string][]byte
+	puts   int
+}
+
+func (s *fakeBlobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey devstoreaccount1:") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, s.prefix)
+	switch {
+	case r.Method == "GET" && r.URL.Query().Get("comp") == "list":
+		var names []string
+		for n := range s.blobs {
+			if strings.HasPrefix(n, r.URL.Query().Get("prefix")) && n > r.URL.Query().Get("marker") {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		var list azureBlobList
+		if len(names) > 2 {
+			names = names[:2]
+			list.NextMarker = names[1]
+		}
+		for _, n := range names {
+			list.Blobs.Blob = append(list.Blobs.Blob, struct {
+				Name string `xml:"Name"`
+			}{n})
+		}
+		xml.NewEncoder(w).Encode(list)
+	case r.Method == "PUT" && r.URL.Query().Get("comp") == "block":
+		body, _ := ioutil.ReadAll(r.Body)
+		s.blocks[name+"#"+r.URL.Query().Get("blockid")] = body
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "PUT" && r.URL.Query().Get("comp") == "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&list); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var blob []byte
+		for _, id := range list.Latest {
+			block, ok := s.blocks[name+"#"+id]
+			if !ok {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			blob = append(blob, block...)
+		}
+		s.blobs[name] = blob
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "PUT":
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		s.blobs[name] = body
+		s.puts++
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "GET" || r.Method == "HEAD":
+		body, ok := s.blobs[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAzureBlobBackend(t *testing.T) {
+	service := &fakeBlobService{
+		prefix: "/devstoreaccount1/history/",
+		blobs:  map[string][]byte{},
+		blocks: map[string][]byte{},
+	}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	t.Setenv("AZURE_STORAGE_KEY", azuriteKey)
+	arch, err := Connect("azblob://history/prefix", ConnectOptions{
+		AzureAccount:  "devstoreaccount1",
+		AzureEndpoint: server.URL + "/devstoreaccount1",
+	})
+	require.NoError(t, err)
+	backend := arch.backend
+
+	exists, err := backend.Exists("a/1.json")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	for _, name := range []string{"a/1.json", "a/2.json", "a/3.json", "b/1.json"} {
+		require.NoError(t, backend.PutFile(name, ioutil.NopCloser(strings.NewReader(name))))
+	}
+	assert.Contains(t, service.blobs, "prefix/a/1.json")
+	assert.Equal(t, 4, service.puts)
+	assert.Empty(t, service.blocks)
+
+	// Files larger than a block are uploaded in blocks
+	large := bytes.Repeat([]byte("0123456789"), azureBlockSize/5)
+	require.NoError(t, backend.PutFile("large.xdr", ioutil.NopCloser(bytes.NewReader(large))))
+	assert.Equal(t, large, service.blobs["prefix/large.xdr"])
+	assert.Len(t, service.blocks, 2)
+	assert.Equal(t, 4, service.puts)
+
+	exists, err = backend.Exists("a/1.json")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	size, err := backend.Size("a/2.json")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len("a/2.json")), size)
+
+	r, err := backend.GetFile("a/3.json")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(r)
+	r.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "a/3.json", string(body))
+
+	_, err = backend.GetFile("c/1.json")
+	assert.Error(t, err)
+
+	ch, errs := backend.ListFiles("a")
+	var names []string
+	for name := range ch {
+		names = append(names, name)
+	}
+	assert.NoError(t, <-errs)
+	assert.Equal(t, []string{"prefix/a/1.json", "prefix/a/2.json", "prefix/a/3.json"}, names)
+}
@@ -0,0 +1,240 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// CheckpointWriterOptions configures a CheckpointWriter.
+type CheckpointWriterOptions struct {
+	// Server is the server of the written HAS.
+	Server string
+	// BucketSource is an archive which the bucket list of every checkpoint
+	// is copied from, along with the buckets missing in the written archive.
+	// The meta of closed ledgers does not contain the bucket list, so one of
+	// BucketSource or WithoutBuckets must be set.
+	BucketSource *Archive
+	// WithoutBuckets writes the HAS with an empty bucket list when there is
+	// no BucketSource. Such an archive can only be used to replay ledgers,
+	// not to catch up from a checkpoint state.
+	WithoutBuckets bool
+	// CommandOptions are the options of the writes, existing files are only
+	// overwritten with Force and nothing is written with DryRun.
+	CommandOptions *CommandOptions
+}
+
+// CheckpointWriter writes the ledger, transactions and results category
+// files and the HAS of the checkpoints of an archive from the meta of the
+// closed ledgers, as a validator publishing to the archive would. Only
+// LedgerCloseMeta V0, the only version of the XDR this package is built
+// with, is supported.
+type CheckpointWriter struct {
+	archive *Archive
+	opts    CheckpointWriterOptions
+
+	nextLedger   uint32
+	previousHash xdr.Hash
+	// entries of the category files of the current checkpoint
+	headers      []interface{}
+	transactions []interface{}
+	results      []interface{}
+}
+
+// NewCheckpointWriter returns a CheckpointWriter for the given archive.
+func NewCheckpointWriter(archive *Archive, opts CheckpointWriterOptions) *CheckpointWriter {
+	if opts.CommandOptions == nil {
+		opts.CommandOptions = &CommandOptions{}
+	}
+	return &CheckpointWriter{archive: archive, opts: opts}
+}
+
+// AddLedger adds a closed ledger to the current checkpoint. Ledgers must be
+// added in order, starting with the first ledger of a checkpoint. The files of
+// the checkpoint are written when its last ledger is added. Meta other than
+// V0 is rejected.
+func (w *CheckpointWriter) AddLedger(meta xdr.LedgerCloseMeta) error {
+	if w.opts.BucketSource == nil && !w.opts.WithoutBuckets {
+		return errors.New("a bucket source is required to write the bucket list of checkpoints")
+	}
+	v0, ok := meta.GetV0()
+	if !ok {
+		return errors.Errorf("unsupported ledger close meta version %d", meta.V)
+	}
+	seq := meta.LedgerSequence()
+	manager := w.archive.checkpointManager
+
+	if w.nextLedger == 0 {
+		if first := manager.GetCheckpointRange(seq).Low; seq != first {
+			return errors.Errorf("the first ledger must start a checkpoint, expected %d but got %d", first, seq)
+		}
+	} else if seq != w.nextLedger {
+		return errors.Errorf("expected ledger %d but got %d", w.nextLedger, seq)
+	} else if meta.PreviousLedgerHash() != w.previousHash {
+		return errors.Errorf("previous ledger hash of ledger %d does not match ledger %d", seq, seq-1)
+	}
+
+	results := xdr.TransactionResultSet{Results: make([]xdr.TransactionResultPair, 0, len(v0.TxProcessing))}
+	for _, tx := range v0.TxProcessing {
+		results.Results = append(results.Results, tx.Result)
+	}
+	resultsHash, err := HashXdr(&results)
+	if err != nil {
+		return errors.Wrap(err, "could not hash transaction results")
+	}
+	if resultsHash != Hash(v0.LedgerHeader.Header.TxSetResultHash) {
+		return errors.Errorf("transaction results of ledger %d do not match the ledger header", seq)
+	}
+
+	w.headers = append(w.headers, &v0.LedgerHeader)
+	// like stellar-core, only ledgers with transactions are part of the
+	// transactions and results files
+	if len(v0.TxSet.Txs) > 0 {
+		w.transactions = append(w.transactions, &xdr.TransactionHistoryEntry{
+			LedgerSeq: xdr.Uint32(seq),
+			TxSet:     v0.TxSet,
+		})
+		w.results = append(w.results, &xdr.TransactionHistoryResultEntry{
+			LedgerSeq:   xdr.Uint32(seq),
+			TxResultSet: results,
+		})
+	}
+	w.nextLedger = seq + 1
+	w.previousHash = meta.LedgerHash()
+
+	if manager.IsCheckpoint(seq) {
+		if err := w.writeCheckpoint(seq); err != nil {
+			return errors.Wrapf(err, "could not write checkpoint %d", seq)
+		}
+		w.headers = nil
+		w.transactions = nil
+		w.results = nil
+	}
+	return nil
+}
+
+func (w *CheckpointWriter) putXdrGzFile(pth string, entries []interface{}) error {
+	opts := w.opts.CommandOptions
+	exists, err := w.archive.backend.Exists(pth)
+	if err != nil {
+		return err
+	}
+	if exists && !opts.Force {
+		log.Printf("skipping existing " + pth)
+		return nil
+	}
+	if opts.DryRun {
+		log.Printf("dryrun skipping " + pth)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, entry := range entries {
+		if err := xdr.MarshalFramed(gz, entry); err != nil {
+			return errors.Wrapf(err, "could not encode %s", pth)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return w.archive.backend.PutFile(pth, ioutil.NopCloser(&buf))
+}
+
+// copyBuckets copies the buckets of has which are missing in the archive
+// from the bucket source.
+func (w *CheckpointWriter) copyBuckets(has HistoryArchiveState) error {
+	buckets, err := has.Buckets()
+	if err != nil {
+		return err
+	}
+	for _, bucket := range buckets {
+		pth := BucketPath(bucket)
+		exists, err := w.archive.backend.Exists(pth)
+		if err != nil {
+			return err
+		}
+		if exists || w.opts.CommandOptions.DryRun {
+			continue
+		}
+		rdr, err := w.opts.BucketSource.backend.GetFile(pth)
+		if err != nil {
+			return errors.Wrapf(err, "could not get bucket %s", bucket)
+		}
+		if err = w.archive.backend.PutFile(pth, rdr); err != nil {
+			return errors.Wrapf(err, "could not put bucket %s", bucket)
+		}
+	}
+	return nil
+}
+
+func (w *CheckpointWriter) writeCheckpoint(chk uint32) error {
+	for _, file := range []struct {
+		category string
+		entries  []interface{}
+	}{
+		{"ledger", w.headers},
+		{"transactions", w.transactions},
+		{"results", w.results},
+	} {
+		if err := w.putXdrGzFile(CategoryCheckpointPath(file.category, chk), file.entries); err != nil {
+			return err
+		}
+	}
+
+	has := HistoryArchiveState{
+		Version:           1,
+		Server:            w.opts.Server,
+		CurrentLedger:     chk,
+		NetworkPassphrase: w.archive.networkPassphrase,
+	}
+	if w.opts.BucketSource != nil {
+		source, err := w.opts.BucketSource.GetCheckpointHAS(chk)
+		if err != nil {
+			return errors.Wrap(err, "could not get HAS from the bucket source")
+		}
+		has.CurrentBuckets = source.CurrentBuckets
+		if err = w.copyBuckets(has); err != nil {
+			return err
+		}
+	} else {
+		zero := Hash{}.String()
+		for i := range has.CurrentBuckets {
+			has.CurrentBuckets[i].Curr = zero
+			has.CurrentBuckets[i].Snap = zero
+		}
+	}
+
+	if w.opts.CommandOptions.DryRun {
+		log.Printf("dryrun skipping checkpoint %d HAS", chk)
+		return nil
+	}
+	if err := w.archive.PutCheckpointHAS(chk, has, w.opts.CommandOptions); err != nil {
+		return err
+	}
+	log.WithField("checkpoint", chk).Info("published checkpoint")
+
+	exists, err := w.archive.backend.Exists(rootHASPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		root, err := w.archive.GetRootHAS()
+		if err != nil {
+			return err
+		}
+		if root.CurrentLedger >= chk {
+			return nil
+		}
+	}
+	return w.archive.PutRootHAS(has, w.opts.CommandOptions)
+}
@@ -0,0 +1,185 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeLedgerCloseMeta returns the meta of a ledger following the ledger with
+// the given hash, with a payment when seq is even.
+func makeLedgerCloseMeta(t *testing.T, seq uint32, previousHash xdr.Hash) xdr.LedgerCloseMeta {
+	var txs []xdr.TransactionEnvelope
+	var processing []xdr.TransactionResultMeta
+	results := xdr.TransactionResultSet{Results: []xdr.TransactionResultPair{}}
+	if seq%2 == 0 {
+		txs = append(txs, xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: xdr.MustMuxedAddress("GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"),
+					Fee:           100,
+					SeqNum:        xdr.SequenceNumber(seq),
+				},
+			},
+		})
+		result := xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				FeeCharged: 100,
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		}
+		processing = append(processing, xdr.TransactionResultMeta{Result: result})
+		results.Results = append(results.Results, result)
+	}
+	txSet := xdr.TransactionSet{PreviousLedgerHash: previousHash, Txs: txs}
+	txSetHash, err := HashTxSet(&txSet)
+	require.NoError(t, err)
+	resultsHash, err := HashXdr(&results)
+	require.NoError(t, err)
+
+	header := xdr.LedgerHeader{
+		LedgerSeq:          xdr.Uint32(seq),
+		PreviousLedgerHash: previousHash,
+		ScpValue:           xdr.StellarValue{TxSetHash: xdr.Hash(txSetHash)},
+		TxSetResultHash:    xdr.Hash(resultsHash),
+	}
+	hash, err := HashXdr(&header)
+	require.NoError(t, err)
+
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{Hash: xdr.Hash(hash), Header: header},
+			TxSet:        txSet,
+			TxProcessing: processing,
+		},
+	}
+}
+
+func addLedgers(t *testing.T, writer *CheckpointWriter, from, to uint32) map[uint32]xdr.Hash {
+	hashes := map[uint32]xdr.Hash{}
+	for seq := from; seq <= to; seq++ {
+		meta := makeLedgerCloseMeta(t, seq, hashes[seq-1])
+		require.NoError(t, writer.AddLedger(meta))
+		hashes[seq] = meta.LedgerHash()
+	}
+	return hashes
+}
+
+func TestCheckpointWriter(t *testing.T) {
+	arch, err := Connect("mock://test", ConnectOptions{
+		CheckpointFrequency: 64,
+		NetworkPassphrase:   "test",
+	})
+	require.NoError(t, err)
+	writer := NewCheckpointWriter(arch, CheckpointWriterOptions{Server: "test", WithoutBuckets: true})
+	hashes := addLedgers(t, writer, 1, 127)
+
+	has, err := arch.GetRootHAS()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(127), has.CurrentLedger)
+	assert.Equal(t, "test", has.NetworkPassphrase)
+	assert.Equal(t, "test", has.Server)
+	buckets, err := has.Buckets()
+	assert.NoError(t, err)
+	assert.Empty(t, buckets)
+
+	has, err = arch.GetCheckpointHAS(63)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(63), has.CurrentLedger)
+
+	ledgers, err := arch.GetLedgers(1, 127)
+	require.NoError(t, err)
+	assert.Len(t, ledgers, 127)
+	for seq, ledger := range ledgers {
+		assert.Equal(t, hashes[seq], ledger.Header.Hash)
+		if seq%2 == 0 {
+			assert.Len(t, ledger.Transaction.TxSet.Txs, 1)
+			assert.Len(t, ledger.TransactionResult.TxResultSet.Results, 1)
+		} else {
+			assert.Empty(t, ledger.Transaction.TxSet.Txs)
+		}
+	}
+
+	// the written files pass the verification of the archive
+	opts := &CommandOptions{Range: Range{Low: 63, High: 127}, Concurrency: 1, Verify: true}
+	require.NoError(t, arch.Scan(opts))
+	invalid, err := arch.ReportInvalid(opts)
+	assert.NoError(t, err)
+	assert.False(t, invalid)
+}
+
+func TestCheckpointWriterBucketSource(t *testing.T) {
+	source := GetTestMockArchive()
+	require.NoError(t, source.AddRandomCheckpoint(63))
+	sourceHAS, err := source.GetCheckpointHAS(63)
+	require.NoError(t, err)
+
+	arch, err := Connect("mock://test", ConnectOptions{})
+	require.NoError(t, err)
+	writer := NewCheckpointWriter(arch, CheckpointWriterOptions{BucketSource: source})
+	addLedgers(t, writer, 1, 63)
+
+	has, err := arch.GetCheckpointHAS(63)
+	require.NoError(t, err)
+	assert.Equal(t, sourceHAS.CurrentBuckets, has.CurrentBuckets)
+	buckets, err := has.Buckets()
+	require.NoError(t, err)
+	assert.NotEmpty(t, buckets)
+	for _, bucket := range buckets {
+		exists, err := arch.BucketExists(bucket)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+	}
+}
+
+func TestCheckpointWriterInvalidLedgers(t *testing.T) {
+	arch, err := Connect("mock://test", ConnectOptions{CheckpointFrequency: 8})
+	require.NoError(t, err)
+
+	writer := NewCheckpointWriter(arch, CheckpointWriterOptions{})
+	assert.EqualError(
+		t,
+		writer.AddLedger(makeLedgerCloseMeta(t, 1, xdr.Hash{})),
+		"a bucket source is required to write the bucket list of checkpoints",
+	)
+
+	writer = NewCheckpointWriter(arch, CheckpointWriterOptions{WithoutBuckets: true})
+	assert.EqualError(
+		t,
+		writer.AddLedger(makeLedgerCloseMeta(t, 2, xdr.Hash{})),
+		"the first ledger must start a checkpoint, expected 1 but got 2",
+	)
+
+	writer = NewCheckpointWriter(arch, CheckpointWriterOptions{WithoutBuckets: true})
+	hashes := addLedgers(t, writer, 8, 8)
+	assert.EqualError(
+		t,
+		writer.AddLedger(makeLedgerCloseMeta(t, 10, xdr.Hash{})),
+		"expected ledger 9 but got 10",
+	)
+
+	assert.EqualError(
+		t,
+		writer.AddLedger(makeLedgerCloseMeta(t, 9, xdr.Hash{})),
+		"previous ledger hash of ledger 9 does not match ledger 8",
+	)
+
+	meta := makeLedgerCloseMeta(t, 9, hashes[8])
+	meta.V0.LedgerHeader.Header.TxSetResultHash = xdr.Hash{}
+	assert.EqualError(
+		t,
+		writer.AddLedger(meta),
+		"transaction results of ledger 9 do not match the ledger header",
+	)
+}
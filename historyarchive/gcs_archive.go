@@ -0,0 +1,128 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"context"
+	"io"
+	"path"
+
+	"cloud.google.com/go/storage"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// GCSArchiveBackend is an ArchiveBackend for archives stored in Google Cloud
+// Storage. Credentials are found with the application default credentials
+// unless ConnectOptions.UnsignedRequests is set. The STORAGE_EMULATOR_HOST
+// environment variable or ConnectOptions.GCSEndpoint can be used to connect
+// to an emulator such as fake-gcs-server.
+type GCSArchiveBackend struct {
+	ctx    context.Context
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+func (b *GCSArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
+	key := path.Join(b.prefix, pth)
+	log.WithField("key", key).Trace("gcs: GetFile")
+	return b.bucket.Object(key).NewReader(b.ctx)
+}
+
+func (b *GCSArchiveBackend) attrs(pth string) (*storage.ObjectAttrs, error) {
+	key := path.Join(b.prefix, pth)
+	log.WithField("key", key).Trace("gcs: Attrs")
+	attrs, err := b.bucket.Object(key).Attrs(b.ctx)
+	if err == storage.ErrObjectNotExist {
+		return nil, nil
+	}
+	return attrs, err
+}
+
+func (b *GCSArchiveBackend) Exists(pth string) (bool, error) {
+	attrs, err := b.attrs(pth)
+	if err != nil {
+		return false, err
+	}
+	return attrs != nil, nil
+}
+
+func (b *GCSArchiveBackend) Size(pth string) (int64, error) {
+	attrs, err := b.attrs(pth)
+	if err != nil || attrs == nil {
+		return 0, err
+	}
+	return attrs.Size, nil
+}
+
+func (b *GCSArchiveBackend) PutFile(pth string, in io.ReadCloser) error {
+	defer in.Close()
+	key := path.Join(b.prefix, pth)
+	log.WithField("key", key).Trace("gcs: PutFile")
+	w := b.bucket.Object(key).NewWriter(b.ctx)
+	if _, err := io.Copy(w, in); err != nil {
+		w.Close()
+		return errors.Wrapf(err, "could not write %s", key)
+	}
+	return w.Close()
+}
+
+func (b *GCSArchiveBackend) ListFiles(pth string) (chan string, chan error) {
+	prefix := path.Join(b.prefix, pth)
+	ch := make(chan string)
+	errs := make(chan error)
+
+	it := b.bucket.Objects(b.ctx, &storage.Query{Prefix: prefix})
+	go func() {
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				errs <- err
+				break
+			}
+			log.WithField("key", attrs.Name).Trace("gcs: ListFiles")
+			ch <- attrs.Name
+		}
+		close(ch)
+		close(errs)
+	}()
+	return ch, errs
+}
+
+func (b *GCSArchiveBackend) CanListFiles() bool {
+	return true
+}
+
+func makeGCSBackend(bucket string, prefix string, opts ConnectOptions) (ArchiveBackend, error) {
+	log.WithFields(log.Fields{"bucket": bucket,
+		"prefix":   prefix,
+		"endpoint": opts.GCSEndpoint}).Debug("gcs: making backend")
+
+	var clientOpts []option.ClientOption
+	if opts.GCSEndpoint != "" {
+		clientOpts = append(clientOpts, option.WithEndpoint(opts.GCSEndpoint))
+	}
+	if opts.UnsignedRequests {
+		clientOpts = append(clientOpts, option.WithoutAuthentication())
+	}
+
+	client, err := storage.NewClient(opts.Context, clientOpts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create gcs client")
+	}
+
+	backend := GCSArchiveBackend{
+		ctx:    opts.Context,
+		bucket: client.Bucket(bucket),
+		prefix: prefix,
+	}
+	return &backend, nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGCSService is a minimal in-memory Cloud Storage JSON and XML API for a
+// single bucket, returning at most two objects per list page.
+type fakeGCSService struct {
+	sync.Mutex
+	bucket  string
+	objects map[string][]byte
+}
+
+type fakeGCSObject struct {
+	Bucket string `json:"bucket"`
+	Name   string `json:"name"`
+	Size   string `json:"size"`
+}
+
+func (s *fakeGCSService) object(name string) fakeGCSObject {
+	return fakeGCSObject{Bucket: s.bucket, Name: name, Size: strconv.Itoa(len(s.objects[name]))}
+}
+
+func (s *fakeGCSService) notFound(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"error": {"code": 404, "message": "Not Found"}}`))
+}
+
+func (s *fakeGCSService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	objectsPath := "/storage/v1/b/" + s.bucket + "/o"
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/upload"+objectsPath:
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || r.URL.Query().Get("uploadType") != "multipart" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		parts := multipart.NewReader(r.Body, params["boundary"])
+		var meta fakeGCSObject
+		part, err := parts.NextPart()
+		if err == nil {
+			err = json.NewDecoder(part).Decode(&meta)
+		}
+		if err == nil {
+			part, err = parts.NextPart()
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[meta.Name], _ = ioutil.ReadAll(part)
+		json.NewEncoder(w).Encode(s.object(meta.Name))
+	case r.Method == "GET" && r.URL.Path == objectsPath:
+		var names []string
+		for n := range s.objects {
+			if strings.HasPrefix(n, r.URL.Query().Get("prefix")) && n > r.URL.Query().Get("pageToken") {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+		var list struct {
+			Items         []fakeGCSObject `json:"items"`
+			NextPageToken string          `json:"nextPageToken,omitempty"`
+		}
+		if len(names) > 2 {
+			names = names[:2]
+			list.NextPageToken = names[1]
+		}
+		for _, n := range names {
+			list.Items = append(list.Items, s.object(n))
+		}
+		json.NewEncoder(w).Encode(list)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, objectsPath+"/"):
+		name := strings.TrimPrefix(r.URL.Path, objectsPath+"/")
+		if _, ok := s.objects[name]; !ok {
+			s.notFound(w)
+			return
+		}
+		json.NewEncoder(w).Encode(s.object(name))
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/"+s.bucket+"/"):
+		body, ok := s.objects[strings.TrimPrefix(r.URL.Path, "/"+s.bucket+"/")]
+		if !ok {
+			s.notFound(w)
+			return
+		}
+		w.Write(body)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestGCSBackend(t *testing.T) {
+	service := &fakeGCSService{bucket: "history", objects: map[string][]byte{}}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	arch, err := Connect("gcs://history/prefix", ConnectOptions{
+		GCSEndpoint:      server.URL + "/storage/v1/",
+		UnsignedRequests: true,
+	})
+	require.NoError(t, err)
+	backend := arch.backend
+
+	exists, err := backend.Exists("a/1.json")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	size, err := backend.Size("a/1.json")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	for _, name := range []string{"a/1.json", "a/2.json", "a/3.json", "b/1.json"} {
+		require.NoError(t, backend.PutFile(name, ioutil.NopCloser(strings.NewReader(name))))
+	}
+	assert.Equal(t, []byte("a/1.json"), service.objects["prefix/a/1.json"])
+
+	exists, err = backend.Exists("a/1.json")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	size, err = backend.Size("a/2.json")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len("a/2.json")), size)
+
+	r, err := backend.GetFile("a/3.json")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(r)
+	r.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "a/3.json", string(body))
+
+	_, err = backend.GetFile("c/1.json")
+	assert.Error(t, err)
+
+	ch, errs := backend.ListFiles("a")
+	var names []string
+	for name := range ch {
+		names = append(names, name)
+	}
+	assert.NoError(t, <-errs)
+	assert.Equal(t, []string{"prefix/a/1.json", "prefix/a/2.json", "prefix/a/3.json"}, names)
+}
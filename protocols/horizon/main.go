@@ -15,6 +15,7 @@ import (
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
 	"github.com/stellar/go/xdr"
 )
 
@@ -714,12 +715,16 @@ type FeeDistribution struct {
 // FeeStats represents a response of fees from horizon
 // To do: implement fee suggestions if agreement is reached in https://github.com/stellar/go/issues/926
 type FeeStats struct {
-	LastLedger          uint32  `json:"last_ledger,string"`
-	LastLedgerBaseFee   int64   `json:"last_ledger_base_fee,string"`
-	LedgerCapacityUsage float64 `json:"ledger_capacity_usage,string"`
+	LastLedger                  uint32  `json:"last_ledger,string"`
+	LastLedgerBaseFee           int64   `json:"last_ledger_base_fee,string"`
+	LastLedgerBasePercentageFee int64   `json:"last_ledger_base_percentage_fee,string"`
+	LedgerCapacityUsage         float64 `json:"ledger_capacity_usage,string"`
 
 	FeeCharged FeeDistribution `json:"fee_charged"`
 	MaxFee     FeeDistribution `json:"max_fee"`
+	// FeeChargedPercentage is the distribution of fees charged expressed in
+	// basis points of the native amount transferred by each transaction.
+	FeeChargedPercentage FeeDistribution `json:"fee_charged_percentage"`
 }
 
 // TransactionsPage contains records of transaction information returned by Horizon
@@ -824,17 +829,29 @@ type LiquidityPoolReserve struct {
 // Kinesis Coin-in-Circulation
 type KinesisCoinInCirculation struct {
 	Links struct {
-		Self   hal.Link `json:"self"`
-		Ledger hal.Link `json:"ledger"`
+		Self    hal.Link `json:"self"`
+		Ledger  hal.Link `json:"ledger"`
+		Records hal.Link `json:"records"`
+		Events  hal.Link `json:"events"`
 	} `json:"_links"`
 
 	IngestSequence        uint32                          `json:"ingest_latest_ledger"`
 	HorizonSequence       int32                           `json:"history_latest_ledger"`
 	HorizonLatestClosedAt time.Time                       `json:"history_latest_ledger_closed_at"`
 	HistoryElderSequence  int32                           `json:"history_elder_ledger"`
+	Accounts              KinesisTreasuryAccounts         `json:"accounts"`
 	Records               []KinesisDailyCoinInCirculation `json:"records"`
 }
 
+// KinesisTreasuryAccounts are the accounts used to classify payments as coin
+// mints or redemptions.
+type KinesisTreasuryAccounts struct {
+	Root       string   `json:"root"`
+	Emission   string   `json:"emission"`
+	HotWallets []string `json:"hot_wallets"`
+	Feepool    string   `json:"feepool"`
+}
+
 type KinesisDailyCoinInCirculation struct {
 	Circulation string `json:"circulation"`
 	Mint        string `json:"mint"`
@@ -843,6 +860,69 @@ type KinesisDailyCoinInCirculation struct {
 	Ledger      uint32 `json:"ledger"`
 }
 
+// KinesisCoinInCirculationRecord is the coin in circulation of an interval of
+// the requested resolution. Mint and Redemption are the amounts minted and
+// redeemed within the interval, the totals are as of Ledger, the last ledger of
+// the interval in which coins were minted or redeemed.
+type KinesisCoinInCirculationRecord struct {
+	ID              string    `json:"paging_token"`
+	Resolution      string    `json:"resolution"`
+	Timestamp       time.Time `json:"timestamp"`
+	Ledger          uint32    `json:"ledger"`
+	Mint            string    `json:"mint"`
+	Redemption      string    `json:"redemption"`
+	TotalMint       string    `json:"total_mint"`
+	TotalRedemption string    `json:"total_redemption"`
+	Circulation     string    `json:"circulation"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res KinesisCoinInCirculationRecord) PagingToken() string {
+	return res.ID
+}
+
+// KinesisCoinInCirculationRecordsPage is a page of coin in circulation records.
+type KinesisCoinInCirculationRecordsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []KinesisCoinInCirculationRecord `json:"records"`
+	} `json:"_embedded"`
+}
+
+// KinesisCoinInCirculationEvent is a successful operation which minted or
+// redeemed coins.
+type KinesisCoinInCirculationEvent struct {
+	Links struct {
+		Operation   hal.Link `json:"operation"`
+		Transaction hal.Link `json:"transaction"`
+	} `json:"_links"`
+
+	ID              string    `json:"id"`
+	PT              string    `json:"paging_token"`
+	Type            string    `json:"type"`
+	Ledger          int32     `json:"ledger"`
+	LedgerCloseTime time.Time `json:"ledger_close_time"`
+	TransactionHash string    `json:"transaction_hash"`
+	OperationID     string    `json:"operation_id"`
+	OperationType   string    `json:"operation_type"`
+	Source          string    `json:"source"`
+	Destination     string    `json:"destination"`
+	Amount          string    `json:"amount"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res KinesisCoinInCirculationEvent) PagingToken() string {
+	return res.PT
+}
+
+// KinesisCoinInCirculationEventsPage is a page of coin in circulation events.
+type KinesisCoinInCirculationEventsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []KinesisCoinInCirculationEvent `json:"records"`
+	} `json:"_embedded"`
+}
+
 type KinesisDailyCoinInCirculationByLedger struct {
 	Circulation string `json:"circulation"`
 	Mint        string `json:"mint"`
@@ -850,3 +930,205 @@ type KinesisDailyCoinInCirculationByLedger struct {
 	Timestamp   string `json:"last_ledger_timestamp"`
 	Ledger      uint32 `json:"last_ledger"`
 }
+
+// WebhookEvent is the payload delivered to a webhook for every operation, or
+// effect, matching its filters. The events of effects, of type "effect",
+// include the operation of the effect. Events are delivered at least once and
+// are not ordered, the operation and effect ids order them.
+type WebhookEvent struct {
+	WebhookID       int64            `json:"webhook_id"`
+	Type            string           `json:"type"`
+	Ledger          uint32           `json:"ledger"`
+	LedgerClosedAt  time.Time        `json:"ledger_closed_at"`
+	TransactionHash string           `json:"transaction_hash"`
+	Operation       WebhookOperation `json:"operation"`
+	Effect          *WebhookEffect   `json:"effect,omitempty"`
+}
+
+// WebhookOperation is the operation of a WebhookEvent, with the details of
+// the operations of the /operations endpoints.
+type WebhookOperation struct {
+	ID            string                 `json:"id"`
+	PT            string                 `json:"paging_token"`
+	SourceAccount string                 `json:"source_account"`
+	Type          string                 `json:"type"`
+	TypeI         int32                  `json:"type_i"`
+	Details       map[string]interface{} `json:"details"`
+}
+
+// WebhookEffect is the effect of a WebhookEvent, with the details of the
+// effects of the /effects endpoints.
+type WebhookEffect struct {
+	ID           string                 `json:"id"`
+	PT           string                 `json:"paging_token"`
+	Account      string                 `json:"account"`
+	AccountMuxed string                 `json:"account_muxed,omitempty"`
+	Type         string                 `json:"type"`
+	TypeI        int32                  `json:"type_i"`
+	Details      map[string]interface{} `json:"details"`
+}
+
+// Statuses of the asynchronous transaction submissions. The submission
+// statuses are the ones returned by stellar-core, the transactions then become
+// SUCCESS or FAILED once they are ingested.
+const (
+	AsyncTxStatusPending       = "PENDING"
+	AsyncTxStatusDuplicate     = "DUPLICATE"
+	AsyncTxStatusTryAgainLater = "TRY_AGAIN_LATER"
+	AsyncTxStatusError         = "ERROR"
+	AsyncTxStatusSuccess       = "SUCCESS"
+	AsyncTxStatusFailed        = "FAILED"
+)
+
+// AsyncTransactionSubmissionResponse is the response of POST
+// /transactions_async. ErrorResultXDR is only set when TxStatus is ERROR.
+type AsyncTransactionSubmissionResponse struct {
+	Links struct {
+		Status hal.Link `json:"status"`
+	} `json:"_links"`
+	ErrorResultXDR string `json:"error_result_xdr,omitempty"`
+	TxStatus       string `json:"tx_status"`
+	Hash           string `json:"hash"`
+	// HTTPStatus is the status code of the response, which depends on
+	// TxStatus.
+	HTTPStatus int `json:"-"`
+}
+
+// GetStatus returns the status code of the response.
+func (response AsyncTransactionSubmissionResponse) GetStatus() int {
+	return response.HTTPStatus
+}
+
+// AsyncTransactionStatus is the response of GET /transactions_async/{hash}.
+// TxStatus is PENDING while the transaction waits to be included in a
+// ledger, SUCCESS or FAILED once it was ingested.
+type AsyncTransactionStatus struct {
+	Links struct {
+		Self        hal.Link `json:"self"`
+		Transaction hal.Link `json:"transaction"`
+	} `json:"_links"`
+	TxStatus  string `json:"tx_status"`
+	Hash      string `json:"hash"`
+	Ledger    int32  `json:"ledger,omitempty"`
+	ResultXDR string `json:"result_xdr,omitempty"`
+}
+
+// TransactionBatchResult is the result of one of the transactions submitted
+// with POST /transactions_batch. Transaction is set when the transaction was
+// included in a ledger, Error when it could not be submitted or failed.
+type TransactionBatchResult struct {
+	Index       int          `json:"index"`
+	Hash        string       `json:"hash,omitempty"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Error       *problem.P   `json:"error,omitempty"`
+}
+
+// TransactionBatchResponse is the response of POST /transactions_batch. It
+// contains a result per submitted transaction, in the order of the request.
+type TransactionBatchResponse struct {
+	Results []TransactionBatchResult `json:"results"`
+}
+
+// TransactionSimulation is the response of POST /transactions/simulate. It
+// reports whether a transaction would obviously fail when applied on top of
+// the ledger state ingested by Horizon. The result codes use the same
+// vocabulary as the result codes of submitted transactions.
+type TransactionSimulation struct {
+	Hash                 string                 `json:"hash"`
+	InnerTransactionHash string                 `json:"inner_transaction_hash,omitempty"`
+	Ledger               int32                  `json:"ledger"`
+	Successful           bool                   `json:"successful"`
+	MinFee               int64                  `json:"min_fee,string"`
+	FeeCharged           int64                  `json:"fee_charged,string"`
+	ResultCodes          TransactionResultCodes `json:"result_codes"`
+	// Reason and InnerReason describe why the transaction, and the inner
+	// transaction of a fee bump transaction, would fail.
+	Reason      string                      `json:"reason,omitempty"`
+	InnerReason string                      `json:"inner_reason,omitempty"`
+	Operations  []OperationSimulationResult `json:"operations"`
+}
+
+// OperationSimulationResult is the simulated result of one of the operations
+// of a transaction.
+type OperationSimulationResult struct {
+	Index      int    `json:"index"`
+	Type       string `json:"type"`
+	ResultCode string `json:"result_code"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Types of the messages exchanged over the WebSocket connections of the /ws
+// endpoint.
+const (
+	WebSocketSubscribe    = "subscribe"
+	WebSocketUnsubscribe  = "unsubscribe"
+	WebSocketSubscribed   = "subscribed"
+	WebSocketUnsubscribed = "unsubscribed"
+	WebSocketEvent        = "event"
+	WebSocketError        = "error"
+)
+
+// WebSocketRequest is a message sent by a client over a WebSocket connection
+// of the /ws endpoint. A subscribe request streams the resource at Path, e.g.
+// /accounts/{id}/payments?cursor=now, as if it was requested with Server Sent
+// Events. ID is chosen by the client and identifies the subscription in the
+// messages of the connection.
+type WebSocketRequest struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Path string `json:"path,omitempty"`
+}
+
+// WebSocketMessage is a message sent by Horizon over a WebSocket connection of
+// the /ws endpoint. Data is set for events, EventID being the paging token of
+// the record when the stream is a page of records. Error is set for errors,
+// the subscription is ended after an error.
+type WebSocketMessage struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	EventID string          `json:"event_id,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   *problem.P      `json:"error,omitempty"`
+}
+
+// AccountBalanceRecord is the balance of an account in an asset at the end of
+// an interval of the requested resolution. Ledger is the last ledger of the
+// interval in which the balance changed, Removed is true when the account or
+// the trust line was removed by that ledger.
+type AccountBalanceRecord struct {
+	ID         string    `json:"paging_token"`
+	Resolution string    `json:"resolution"`
+	Timestamp  time.Time `json:"timestamp"`
+	Ledger     uint32    `json:"ledger"`
+	Balance    string    `json:"balance"`
+	Removed    bool      `json:"removed"`
+}
+
+// PagingToken implementation for hal.Pageable
+func (res AccountBalanceRecord) PagingToken() string {
+	return res.ID
+}
+
+// AccountBalanceRecordsPage is a page of account balance records.
+type AccountBalanceRecordsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []AccountBalanceRecord `json:"records"`
+	} `json:"_embedded"`
+}
+
+// AccountBalancesAtLedger is the response of /accounts/{account_id}?ledger=N,
+// the balances of an account as of the close of a ledger.
+type AccountBalancesAtLedger struct {
+	AccountID string                   `json:"account_id"`
+	Ledger    uint32                   `json:"ledger"`
+	Balances  []AccountBalanceAtLedger `json:"balances"`
+}
+
+// AccountBalanceAtLedger is one of the balances of an AccountBalancesAtLedger.
+type AccountBalanceAtLedger struct {
+	Balance            string    `json:"balance"`
+	LastModifiedLedger uint32    `json:"last_modified_ledger"`
+	LastModifiedTime   time.Time `json:"last_modified_time"`
+	base.Asset
+}
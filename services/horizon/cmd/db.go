@@ -211,6 +211,27 @@ var dbReapCmd = &cobra.Command{
 	},
 }
 
+var dbRebackfillCoinInCirculationCmd = &cobra.Command{
+	Use:   "rebackfill-coin-in-circulation",
+	Short: "recomputes the coin in circulation with the configured treasury accounts",
+	Long: "rebackfill-coin-in-circulation clears the coin in circulation tables and recomputes them from the ingested " +
+		"history with the configured kinesis treasury accounts, which are recorded as the accounts of the coin in " +
+		"circulation. Horizon refuses to start when the treasury accounts change until this command is run. The " +
+		"ingesting instances should be stopped while it runs.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		app, err := horizon.NewAppFromFlags(config, flags)
+		if err != nil {
+			return err
+		}
+		backfilled, err := app.RebackfillKinesisCoinInCirculation(context.Background())
+		if err != nil {
+			return err
+		}
+		hlog.Infof("Recomputed the coin in circulation, %d ledgers with mints or redemptions", backfilled)
+		return nil
+	},
+}
+
 var dbReingestCmd = &cobra.Command{
 	Use:   "reingest",
 	Short: "reingest commands",
@@ -381,11 +402,17 @@ func runDBReingestRange(ledgerRanges []history.LedgerRange, reingestForce bool,
 	if err != nil {
 		return fmt.Errorf("cannot open Horizon DB: %v", err)
 	}
+	// the reingested ledgers are classified with the configured accounts
+	err = horizon.CheckKinesisTreasuryAccounts(context.Background(), &history.Q{horizonSession}, config.KinesisTreasuryAccounts, true)
+	if err != nil {
+		return err
+	}
 
 	ingestConfig := ingest.Config{
 		NetworkPassphrase:           config.NetworkPassphrase,
 		HistorySession:              horizonSession,
 		HistoryArchiveURL:           config.HistoryArchiveURLs[0],
+		HistoryArchiveCache:         config.HistoryArchiveCache,
 		CheckpointFrequency:         config.CheckpointFrequency,
 		MaxReingestRetries:          int(retries),
 		ReingestRetryBackoffSeconds: int(retryBackoffSeconds),
@@ -398,6 +425,7 @@ func runDBReingestRange(ledgerRanges []history.LedgerRange, reingestForce bool,
 		StellarCoreCursor:           config.CursorName,
 		StellarCoreURL:              config.StellarCoreURL,
 		RoundingSlippageFilter:      config.RoundingSlippageFilter,
+		KinesisTreasuryAccounts:     config.KinesisTreasuryAccounts,
 	}
 
 	if !ingestConfig.EnableCaptiveCore {
@@ -508,6 +536,7 @@ func init() {
 		dbInitCmd,
 		dbMigrateCmd,
 		dbReapCmd,
+		dbRebackfillCoinInCirculationCmd,
 		dbReingestCmd,
 		dbDetectGapsCmd,
 		dbFillGapsCmd,
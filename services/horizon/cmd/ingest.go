@@ -104,17 +104,19 @@ var ingestVerifyRangeCmd = &cobra.Command{
 		}
 
 		ingestConfig := ingest.Config{
-			NetworkPassphrase:      config.NetworkPassphrase,
-			HistorySession:         horizonSession,
-			HistoryArchiveURL:      config.HistoryArchiveURLs[0],
-			EnableCaptiveCore:      config.EnableCaptiveCoreIngestion,
-			CaptiveCoreBinaryPath:  config.CaptiveCoreBinaryPath,
-			CaptiveCoreConfigUseDB: config.CaptiveCoreConfigUseDB,
-			RemoteCaptiveCoreURL:   config.RemoteCaptiveCoreURL,
-			CheckpointFrequency:    config.CheckpointFrequency,
-			CaptiveCoreToml:        config.CaptiveCoreToml,
-			CaptiveCoreStoragePath: config.CaptiveCoreStoragePath,
-			RoundingSlippageFilter: config.RoundingSlippageFilter,
+			NetworkPassphrase:       config.NetworkPassphrase,
+			HistorySession:          horizonSession,
+			HistoryArchiveURL:       config.HistoryArchiveURLs[0],
+			HistoryArchiveCache:     config.HistoryArchiveCache,
+			EnableCaptiveCore:       config.EnableCaptiveCoreIngestion,
+			CaptiveCoreBinaryPath:   config.CaptiveCoreBinaryPath,
+			CaptiveCoreConfigUseDB:  config.CaptiveCoreConfigUseDB,
+			RemoteCaptiveCoreURL:    config.RemoteCaptiveCoreURL,
+			CheckpointFrequency:     config.CheckpointFrequency,
+			CaptiveCoreToml:         config.CaptiveCoreToml,
+			CaptiveCoreStoragePath:  config.CaptiveCoreStoragePath,
+			RoundingSlippageFilter:  config.RoundingSlippageFilter,
+			KinesisTreasuryAccounts: config.KinesisTreasuryAccounts,
 		}
 
 		if !ingestConfig.EnableCaptiveCore {
@@ -199,11 +201,13 @@ var ingestStressTestCmd = &cobra.Command{
 		}
 
 		ingestConfig := ingest.Config{
-			NetworkPassphrase:      config.NetworkPassphrase,
-			HistorySession:         horizonSession,
-			HistoryArchiveURL:      config.HistoryArchiveURLs[0],
-			EnableCaptiveCore:      config.EnableCaptiveCoreIngestion,
-			RoundingSlippageFilter: config.RoundingSlippageFilter,
+			NetworkPassphrase:       config.NetworkPassphrase,
+			HistorySession:          horizonSession,
+			HistoryArchiveURL:       config.HistoryArchiveURLs[0],
+			HistoryArchiveCache:     config.HistoryArchiveCache,
+			EnableCaptiveCore:       config.EnableCaptiveCoreIngestion,
+			RoundingSlippageFilter:  config.RoundingSlippageFilter,
+			KinesisTreasuryAccounts: config.KinesisTreasuryAccounts,
 		}
 
 		if config.EnableCaptiveCoreIngestion {
@@ -290,12 +294,14 @@ var ingestInitGenesisStateCmd = &cobra.Command{
 		}
 
 		ingestConfig := ingest.Config{
-			NetworkPassphrase:      config.NetworkPassphrase,
-			HistorySession:         horizonSession,
-			HistoryArchiveURL:      config.HistoryArchiveURLs[0],
-			EnableCaptiveCore:      config.EnableCaptiveCoreIngestion,
-			CheckpointFrequency:    config.CheckpointFrequency,
-			RoundingSlippageFilter: config.RoundingSlippageFilter,
+			NetworkPassphrase:       config.NetworkPassphrase,
+			HistorySession:          horizonSession,
+			HistoryArchiveURL:       config.HistoryArchiveURLs[0],
+			HistoryArchiveCache:     config.HistoryArchiveCache,
+			EnableCaptiveCore:       config.EnableCaptiveCoreIngestion,
+			CheckpointFrequency:     config.CheckpointFrequency,
+			RoundingSlippageFilter:  config.RoundingSlippageFilter,
+			KinesisTreasuryAccounts: config.KinesisTreasuryAccounts,
 		}
 
 		if config.EnableCaptiveCoreIngestion {
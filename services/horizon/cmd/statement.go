@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"go/types"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	horizon "github.com/stellar/go/services/horizon/internal"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/statement"
+	support "github.com/stellar/go/support/config"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+var (
+	statementFrom   string
+	statementTo     string
+	statementAsset  string
+	statementFormat string
+	statementOutput string
+)
+
+var statementCmdOpts = support.ConfigOptions{
+	{
+		Name:      "from",
+		EnvVar:    "STATEMENT_FROM",
+		ConfigKey: &statementFrom,
+		OptType:   types.String,
+		Required:  true,
+		Usage:     "start of the statement period, RFC 3339 time e.g. 2021-06-01T00:00:00Z",
+	},
+	{
+		Name:      "to",
+		EnvVar:    "STATEMENT_TO",
+		ConfigKey: &statementTo,
+		OptType:   types.String,
+		Required:  true,
+		Usage:     "end of the statement period (exclusive), RFC 3339 time",
+	},
+	{
+		Name:      "asset",
+		EnvVar:    "STATEMENT_ASSET",
+		ConfigKey: &statementAsset,
+		OptType:   types.String,
+		Required:  false,
+		Usage:     "[optional] restricts the statement to an asset, native or CODE:ISSUER",
+	},
+	{
+		Name:        "format",
+		EnvVar:      "STATEMENT_FORMAT",
+		ConfigKey:   &statementFormat,
+		OptType:     types.String,
+		Required:    false,
+		FlagDefault: string(statement.FormatCSV),
+		Usage:       "format of the statement: csv, jsonl or ofx",
+	},
+	{
+		Name:      "output",
+		EnvVar:    "STATEMENT_OUTPUT",
+		ConfigKey: &statementOutput,
+		OptType:   types.String,
+		Required:  false,
+		Usage:     "[optional] file the statement is written to, stdout by default",
+	},
+}
+
+var statementCmd = &cobra.Command{
+	Use:   "statement ACCOUNT",
+	Short: "exports the statement of an account",
+	Long: "statement exports the credits, debits and fees of an account over a period of time, " +
+		"along with its opening and closing balances, as CSV, JSON Lines or OFX.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireAndSetFlag(horizon.DatabaseURLFlagName); err != nil {
+			return err
+		}
+		if err := statementCmdOpts.RequireE(); err != nil {
+			return err
+		}
+		if err := statementCmdOpts.SetValues(); err != nil {
+			return err
+		}
+		if len(args) != 1 {
+			return ErrUsage{cmd}
+		}
+
+		from, err := time.Parse(time.RFC3339, statementFrom)
+		if err != nil {
+			return errors.Wrap(err, "invalid --from")
+		}
+		to, err := time.Parse(time.RFC3339, statementTo)
+		if err != nil {
+			return errors.Wrap(err, "invalid --to")
+		}
+		if !to.After(from) {
+			return errors.New("--to must be after --from")
+		}
+		format, err := statement.ParseFormat(statementFormat)
+		if err != nil {
+			return err
+		}
+
+		session, err := db.Open("postgres", config.DatabaseURL)
+		if err != nil {
+			return errors.Wrap(err, "cannot open Horizon DB")
+		}
+		defer session.Close()
+
+		s, err := statement.Build(context.Background(), &history.Q{session}, statement.Params{
+			AccountID: args[0],
+			Asset:     statementAsset,
+			From:      from,
+			To:        to,
+		})
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = os.Stdout
+		if statementOutput != "" {
+			file, err := os.Create(statementOutput)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		if err := statement.Write(w, format, s); err != nil {
+			return err
+		}
+		log.Infof("Exported %d entries of ledgers %d to %d", len(s.Entries), s.StartLedger, s.EndLedger)
+		return nil
+	},
+}
+
+func init() {
+	if err := statementCmdOpts.Init(statementCmd); err != nil {
+		log.Fatal(err.Error())
+	}
+	viper.BindPFlags(statementCmd.PersistentFlags())
+
+	RootCmd.AddCommand(statementCmd)
+}
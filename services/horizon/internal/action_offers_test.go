@@ -31,7 +31,7 @@ func TestOfferActions_Show(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	ht.Assert.NoError(err)
 
 	issuer := xdr.MustAddress("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
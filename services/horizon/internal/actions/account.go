@@ -286,10 +286,13 @@ func getLedgerBySequence(ctx context.Context, hq *history.Q, sequence int32) (*h
 // AccountByIDQuery query struct for accounts/{account_id} end-point
 type AccountByIDQuery struct {
 	AccountID string `schema:"account_id" valid:"accountID,optional"`
+	Ledger    uint32 `schema:"ledger" valid:"-"`
 }
 
 // GetAccountByIDHandler is the action handler for the /accounts/{account_id} endpoint
-type GetAccountByIDHandler struct{}
+type GetAccountByIDHandler struct {
+	LedgerState *ledger.State
+}
 
 type Account protocol.Account
 
@@ -316,6 +319,10 @@ func (handler GetAccountByIDHandler) GetResource(
 	if err != nil {
 		return nil, err
 	}
+	// the balances as of a past ledger are read from the balance history
+	if qp.Ledger != 0 {
+		return accountBalancesAtLedger(r, handler.LedgerState, qp.AccountID, qp.Ledger)
+	}
 	account, err := AccountInfo(r.Context(), historyQ, qp.AccountID)
 	if err != nil {
 		return Account{}, err
@@ -0,0 +1,254 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	horizonProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountBalanceHistoryQuery query struct for the
+// /accounts/{account_id}/balances/history endpoint
+type AccountBalanceHistoryQuery struct {
+	AccountID  string `schema:"account_id" valid:"accountID"`
+	Asset      string `schema:"asset" valid:"asset"`
+	From       string `schema:"from" valid:"-"`
+	To         string `schema:"to" valid:"-"`
+	Resolution string `schema:"resolution" valid:"-"`
+}
+
+// Validate runs extra validations on the query parameters
+func (q AccountBalanceHistoryQuery) Validate() error {
+	from, err := q.FromTime()
+	if err != nil {
+		return err
+	}
+	to, err := q.ToTime()
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return problem.MakeInvalidFieldProblem(
+			"to",
+			errors.New("`to` must be after `from`"),
+		)
+	}
+	_, err = q.ResolutionValue()
+	return err
+}
+
+// FromTime returns the parsed `from` parameter, zero if it is not set.
+func (q AccountBalanceHistoryQuery) FromTime() (time.Time, error) {
+	return parseKinesisCoinInCirculationTime("from", q.From)
+}
+
+// ToTime returns the parsed `to` parameter, zero if it is not set.
+func (q AccountBalanceHistoryQuery) ToTime() (time.Time, error) {
+	return parseKinesisCoinInCirculationTime("to", q.To)
+}
+
+// ResolutionValue returns the requested resolution, `ledger` by default.
+func (q AccountBalanceHistoryQuery) ResolutionValue() (history.AccountBalanceResolution, error) {
+	if q.Resolution == "" {
+		return history.AccountBalanceLedgerResolution, nil
+	}
+	for _, resolution := range history.AccountBalanceResolutions {
+		if string(resolution) == q.Resolution {
+			return resolution, nil
+		}
+	}
+	return "", problem.MakeInvalidFieldProblem(
+		"resolution",
+		errors.New("illegal resolution. allowed resolutions are: ledger, hour, day, week and month"),
+	)
+}
+
+// AssetValue returns the type, code and issuer of the `asset` parameter.
+func (q AccountBalanceHistoryQuery) AssetValue() (assetType, code, issuer string) {
+	if strings.ToLower(q.Asset) == "native" {
+		return xdr.AssetTypeToString[xdr.AssetTypeAssetTypeNative], "", ""
+	}
+	parts := strings.Split(q.Asset, ":")
+	asset := xdr.MustNewCreditAsset(parts[0], parts[1])
+	// errors were checked by the asset validator
+	_ = asset.Extract(&assetType, &code, &issuer)
+	return assetType, code, issuer
+}
+
+// GetAccountBalanceHistoryHandler is the action handler for the
+// /accounts/{account_id}/balances/history endpoint
+type GetAccountBalanceHistoryHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of the balances of an account in an asset.
+// Only the intervals in which the balance changed are included.
+func (handler GetAccountBalanceHistoryHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	qp := AccountBalanceHistoryQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCursorWithinHistory(handler.LedgerState, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	// errors were checked by getParams
+	resolution, _ := qp.ResolutionValue()
+	from, _ := qp.FromTime()
+	to, _ := qp.ToTime()
+	assetType, code, issuer := qp.AssetValue()
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.AccountBalanceHistory(ctx, history.AccountBalanceHistoryQuery{
+		AccountID:   qp.AccountID,
+		AssetType:   assetType,
+		AssetCode:   code,
+		AssetIssuer: issuer,
+		Resolution:  resolution,
+		From:        from,
+		To:          to,
+		Page:        pq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		result = append(result, horizon.AccountBalanceRecord{
+			ID:         record.PagingToken(),
+			Resolution: string(resolution),
+			Timestamp:  record.Timestamp,
+			Ledger:     record.LedgerSequence,
+			Balance:    amount.StringFromInt64(record.Balance),
+			Removed:    record.Removed,
+		})
+	}
+
+	return result, nil
+}
+
+// CSVHeader returns the header of the text/csv representation of the records.
+func (handler GetAccountBalanceHistoryHandler) CSVHeader() []string {
+	return []string{
+		"paging_token",
+		"resolution",
+		"timestamp",
+		"ledger",
+		"balance",
+		"removed",
+	}
+}
+
+// CSVRecord returns the text/csv representation of a record returned by
+// GetResourcePage.
+func (handler GetAccountBalanceHistoryHandler) CSVRecord(pageable hal.Pageable) []string {
+	record := pageable.(horizon.AccountBalanceRecord)
+	return []string{
+		record.ID,
+		record.Resolution,
+		record.Timestamp.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(record.Ledger), 10),
+		record.Balance,
+		strconv.FormatBool(record.Removed),
+	}
+}
+
+// AccountBalancesAtLedger is the response of /accounts/{account_id}?ledger=N.
+type AccountBalancesAtLedger horizon.AccountBalancesAtLedger
+
+func (a AccountBalancesAtLedger) Equals(other StreamableObjectResponse) bool {
+	otherBalances, ok := other.(AccountBalancesAtLedger)
+	if !ok {
+		return false
+	}
+	// the balances of a closed ledger do not change
+	return a.AccountID == otherBalances.AccountID && a.Ledger == otherBalances.Ledger
+}
+
+// accountBalancesAtLedger returns the balances of an account as of the close
+// of the given ledger from the balance history.
+func accountBalancesAtLedger(r *http.Request, ledgerState *ledger.State, accountID string, sequence uint32) (AccountBalancesAtLedger, error) {
+	status := ledgerState.CurrentStatus()
+	if int32(sequence) < status.HistoryElder {
+		return AccountBalancesAtLedger{}, horizonProblem.BeforeHistory
+	}
+	if int32(sequence) > status.HistoryLatest {
+		return AccountBalancesAtLedger{}, problem.MakeInvalidFieldProblem(
+			"ledger",
+			errors.New("ledger has not been ingested yet"),
+		)
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return AccountBalancesAtLedger{}, err
+	}
+	// the balances are only complete from the ledger of the first snapshot of
+	// the state on, before it the balances which did not change are missing.
+	snapshotLedger, err := historyQ.GetAccountBalancesSnapshotLedger(r.Context())
+	if err != nil {
+		return AccountBalancesAtLedger{}, err
+	}
+	if snapshotLedger == 0 || sequence < snapshotLedger {
+		return AccountBalancesAtLedger{}, horizonProblem.BeforeHistory
+	}
+	balances, err := historyQ.AccountBalancesAtLedger(r.Context(), accountID, sequence)
+	if err != nil {
+		return AccountBalancesAtLedger{}, err
+	}
+
+	result := AccountBalancesAtLedger{
+		AccountID: accountID,
+		Ledger:    sequence,
+		Balances:  []horizon.AccountBalanceAtLedger{},
+	}
+	exists := false
+	for _, balance := range balances {
+		if balance.AssetType == xdr.AssetTypeToString[xdr.AssetTypeAssetTypeNative] {
+			exists = !balance.Removed
+		}
+		if balance.Removed {
+			continue
+		}
+		result.Balances = append(result.Balances, horizon.AccountBalanceAtLedger{
+			Balance:            amount.StringFromInt64(balance.Balance),
+			LastModifiedLedger: balance.LedgerSequence,
+			LastModifiedTime:   balance.ClosedAt,
+			Asset: base.Asset{
+				Type:   balance.AssetType,
+				Code:   balance.AssetCode,
+				Issuer: balance.AssetIssuer,
+			},
+		})
+	}
+	// the account did not exist
+	if !exists {
+		return AccountBalancesAtLedger{}, problem.NotFound
+	}
+	return result, nil
+}
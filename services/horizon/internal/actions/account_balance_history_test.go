@@ -0,0 +1,151 @@
+package actions
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/render/problem"
+)
+
+const (
+	balanceHistoryTestAccount = "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	balanceHistoryTestIssuer  = "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+)
+
+func TestAccountBalanceHistoryQueryValidate(t *testing.T) {
+	for _, testCase := range []struct {
+		query AccountBalanceHistoryQuery
+		field string
+	}{
+		{AccountBalanceHistoryQuery{}, ""},
+		{AccountBalanceHistoryQuery{From: "2021-06-01T00:00:00Z", To: "2021-07-01T00:00:00Z", Resolution: "day"}, ""},
+		{AccountBalanceHistoryQuery{From: "2021-06-01"}, "from"},
+		{AccountBalanceHistoryQuery{From: "2021-06-01T00:00:00Z", To: "2021-05-01T00:00:00Z"}, "to"},
+		{AccountBalanceHistoryQuery{Resolution: "minute"}, "resolution"},
+	} {
+		err := testCase.query.Validate()
+		if testCase.field == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"])
+		}
+	}
+
+	resolution, err := AccountBalanceHistoryQuery{}.ResolutionValue()
+	assert.NoError(t, err)
+	assert.Equal(t, history.AccountBalanceLedgerResolution, resolution)
+
+	assetType, code, issuer := AccountBalanceHistoryQuery{Asset: "native"}.AssetValue()
+	assert.Equal(t, []string{"native", "", ""}, []string{assetType, code, issuer})
+	assetType, code, issuer = AccountBalanceHistoryQuery{Asset: "USD:" + balanceHistoryTestIssuer}.AssetValue()
+	assert.Equal(t, []string{"credit_alphanum4", "USD", balanceHistoryTestIssuer}, []string{assetType, code, issuer})
+}
+
+func TestGetAccountBalancesAtLedgerOutsideHistory(t *testing.T) {
+	ledgerState := &ledger.State{}
+	ledgerState.SetHorizonStatus(ledger.HorizonStatus{HistoryElder: 2, HistoryLatest: 100})
+	handler := GetAccountByIDHandler{LedgerState: ledgerState}
+	routeParams := map[string]string{"account_id": balanceHistoryTestAccount}
+
+	_, err := handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{"ledger": "1"}, routeParams, &db.MockSession{},
+	))
+	assert.Equal(t, hProblem.BeforeHistory, err)
+
+	for _, ledger := range []string{"101", "abc"} {
+		_, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+			t, map[string]string{"ledger": ledger}, routeParams, &db.MockSession{},
+		))
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, "ledger", err.(*problem.P).Extras["invalid_field"])
+		}
+	}
+}
+
+func TestGetAccountBalanceHistory(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &history.Q{tt.HorizonSession()}
+
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	tt.Assert.NoError(q.InsertAccountBalances(tt.Ctx, []history.AccountBalance{
+		{LedgerSequence: 10, ClosedAt: day, AccountID: balanceHistoryTestAccount, AssetType: "native", Balance: 1000},
+		{LedgerSequence: 20, ClosedAt: day.Add(time.Hour), AccountID: balanceHistoryTestAccount, AssetType: "native", Balance: 500},
+		{
+			LedgerSequence: 20,
+			ClosedAt:       day.Add(time.Hour),
+			AccountID:      balanceHistoryTestAccount,
+			AssetType:      "credit_alphanum4",
+			AssetCode:      "USD",
+			AssetIssuer:    balanceHistoryTestIssuer,
+			Balance:        250,
+		},
+	}, 100))
+
+	records, err := GetAccountBalanceHistoryHandler{LedgerState: &ledger.State{}}.GetResourcePage(
+		httptest.NewRecorder(),
+		makeRequest(
+			t,
+			map[string]string{"asset": "native", "resolution": "day"},
+			map[string]string{"account_id": balanceHistoryTestAccount},
+			q,
+		),
+	)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		record := records[0].(protocol.AccountBalanceRecord)
+		tt.Assert.Equal("day", record.Resolution)
+		tt.Assert.True(day.Equal(record.Timestamp))
+		tt.Assert.Equal(uint32(20), record.Ledger)
+		tt.Assert.Equal("0.0000500", record.Balance)
+	}
+
+	ledgerState := &ledger.State{}
+	ledgerState.SetHorizonStatus(ledger.HorizonStatus{HistoryElder: 1, HistoryLatest: 100})
+	handler := GetAccountByIDHandler{LedgerState: ledgerState}
+
+	// the balances are unknown until the state is recorded
+	_, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"ledger": "15"},
+		map[string]string{"account_id": balanceHistoryTestAccount},
+		q,
+	))
+	tt.Assert.Equal(hProblem.BeforeHistory, err)
+
+	tt.Assert.NoError(q.UpdateAccountBalancesSnapshotLedger(tt.Ctx, 2))
+	response, err := handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"ledger": "15"},
+		map[string]string{"account_id": balanceHistoryTestAccount},
+		q,
+	))
+	tt.Assert.NoError(err)
+	balances := response.(AccountBalancesAtLedger)
+	tt.Assert.Equal(uint32(15), balances.Ledger)
+	if tt.Assert.Len(balances.Balances, 1) {
+		tt.Assert.Equal("0.0001000", balances.Balances[0].Balance)
+		tt.Assert.Equal(uint32(10), balances.Balances[0].LastModifiedLedger)
+	}
+
+	// the account did not exist yet
+	_, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"ledger": "5"},
+		map[string]string{"account_id": balanceHistoryTestAccount},
+		q,
+	))
+	tt.Assert.Equal(problem.NotFound, err)
+}
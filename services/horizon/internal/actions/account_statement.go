@@ -0,0 +1,121 @@
+package actions
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/stellar/go/services/horizon/internal/context"
+	horizonProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/statement"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// AccountStatementQuery query struct for the /accounts/{account_id}/statement
+// endpoint
+type AccountStatementQuery struct {
+	AccountID string `schema:"account_id" valid:"accountID"`
+	Asset     string `schema:"asset" valid:"asset"`
+	From      string `schema:"from" valid:"required"`
+	To        string `schema:"to" valid:"required"`
+	Format    string `schema:"format" valid:"-"`
+}
+
+// Validate runs extra validations on the query parameters
+func (q AccountStatementQuery) Validate() error {
+	from, err := parseKinesisCoinInCirculationTime("from", q.From)
+	if err != nil {
+		return err
+	}
+	to, err := parseKinesisCoinInCirculationTime("to", q.To)
+	if err != nil {
+		return err
+	}
+	if !to.After(from) {
+		return problem.MakeInvalidFieldProblem(
+			"to",
+			errors.New("`to` must be after `from`"),
+		)
+	}
+	_, err = q.FormatValue()
+	return err
+}
+
+// FormatValue returns the requested format, csv by default.
+func (q AccountStatementQuery) FormatValue() (statement.Format, error) {
+	if q.Format == "" {
+		return statement.FormatCSV, nil
+	}
+	format, err := statement.ParseFormat(q.Format)
+	if err != nil {
+		return "", problem.MakeInvalidFieldProblem(
+			"format",
+			errors.New("illegal format. allowed formats are: csv, jsonl and ofx"),
+		)
+	}
+	return format, nil
+}
+
+// Params returns the parameters of the statement.
+func (q AccountStatementQuery) Params() statement.Params {
+	// errors were checked by Validate
+	from, _ := parseKinesisCoinInCirculationTime("from", q.From)
+	to, _ := parseKinesisCoinInCirculationTime("to", q.To)
+	asset := q.Asset
+	if strings.ToLower(asset) == statement.NativeAsset {
+		asset = statement.NativeAsset
+	}
+	return statement.Params{
+		AccountID: q.AccountID,
+		Asset:     asset,
+		From:      from,
+		To:        to,
+	}
+}
+
+// GetAccountStatementHandler is the action handler for the
+// /accounts/{account_id}/statement endpoint
+type GetAccountStatementHandler struct{}
+
+// WriteRawResponse writes the statement of an account over a period of time
+// in the requested format.
+func (handler GetAccountStatementHandler) WriteRawResponse(w io.Writer, r *http.Request) error {
+	qp := AccountStatementQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return err
+	}
+	// errors were checked by getParams
+	format, _ := qp.FormatValue()
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return err
+	}
+	// Build checks the range of the statement before loading its entries
+	s, err := statement.Build(r.Context(), historyQ, qp.Params())
+	switch err {
+	case nil:
+	case statement.ErrTooManyEntries:
+		return problem.MakeInvalidFieldProblem(
+			"to",
+			errors.New("the statement is too long, please request a shorter period"),
+		)
+	case statement.ErrBeforeHistory:
+		return horizonProblem.BeforeHistory
+	default:
+		return err
+	}
+
+	// the statement is buffered so that errors can still be rendered as problems
+	var buf bytes.Buffer
+	if err = statement.Write(&buf, format, s); err != nil {
+		return err
+	}
+	if hw, ok := w.(HeaderWriter); ok {
+		hw.Header().Set("Content-Type", format.ContentType())
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,58 @@
+package actions
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/services/horizon/internal/statement"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestAccountStatementQueryValidate(t *testing.T) {
+	from, to := "2021-06-01T00:00:00Z", "2021-07-01T00:00:00Z"
+	for _, testCase := range []struct {
+		query AccountStatementQuery
+		field string
+	}{
+		{AccountStatementQuery{From: from, To: to}, ""},
+		{AccountStatementQuery{From: from, To: to, Format: "OFX"}, ""},
+		{AccountStatementQuery{From: "2021-06-01", To: to}, "from"},
+		{AccountStatementQuery{From: to, To: from}, "to"},
+		{AccountStatementQuery{From: from, To: to, Format: "pdf"}, "format"},
+	} {
+		err := testCase.query.Validate()
+		if testCase.field == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"])
+		}
+	}
+
+	format, err := AccountStatementQuery{}.FormatValue()
+	assert.NoError(t, err)
+	assert.Equal(t, statement.FormatCSV, format)
+
+	params := AccountStatementQuery{AccountID: balanceHistoryTestAccount, Asset: "Native", From: from, To: to}.Params()
+	assert.Equal(t, statement.NativeAsset, params.Asset)
+	assert.Equal(t, 2021, params.From.Year())
+}
+
+func TestGetAccountStatementRequiredParams(t *testing.T) {
+	handler := GetAccountStatementHandler{}
+	for _, query := range []map[string]string{
+		{"to": "2021-07-01T00:00:00Z"},
+		{"from": "2021-06-01T00:00:00Z"},
+	} {
+		err := handler.WriteRawResponse(httptest.NewRecorder(), makeRequest(
+			t, query, map[string]string{"account_id": balanceHistoryTestAccount}, &db.MockSession{},
+		))
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, problem.BadRequest.Status, err.(*problem.P).Status)
+		}
+	}
+}
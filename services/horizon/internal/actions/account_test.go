@@ -226,7 +226,7 @@ func TestAccountInfo(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerFourCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	assert.NoError(t, err)
 
 	account, err := AccountInfo(tt.Ctx, &history.Q{tt.HorizonSession()}, accountID)
@@ -406,7 +406,7 @@ func TestGetAccountsHandlerPageResultsByAsset(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	assert.NoError(t, err)
 
 	for _, row := range accountSigners {
@@ -509,7 +509,7 @@ func TestGetAccountsHandlerPageResultsByLiquidityPool(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	assert.NoError(t, err)
 	var assetType, code, issuer string
 	usd.MustExtract(&assetType, &code, &issuer)
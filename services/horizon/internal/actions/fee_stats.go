@@ -18,6 +18,7 @@ func (handler FeeStatsHandler) GetResource(w HeaderWriter, r *http.Request) (int
 
 	cur, ok := operationfeestats.CurrentState()
 	feeStats.LastLedgerBaseFee = cur.LastBaseFee
+	feeStats.LastLedgerBasePercentageFee = cur.LastBasePercentageFee
 	feeStats.LastLedger = cur.LastLedger
 
 	// LedgerCapacityUsage is the empty string when operationfeestats has not had its state set
@@ -64,5 +65,21 @@ func (handler FeeStatsHandler) GetResource(w HeaderWriter, r *http.Request) (int
 	feeStats.MaxFee.P95 = cur.MaxFeeP95
 	feeStats.MaxFee.P99 = cur.MaxFeeP99
 
+	// FeeChargedPercentage
+	feeStats.FeeChargedPercentage.Max = cur.FeeChargedPercentageMax
+	feeStats.FeeChargedPercentage.Min = cur.FeeChargedPercentageMin
+	feeStats.FeeChargedPercentage.Mode = cur.FeeChargedPercentageMode
+	feeStats.FeeChargedPercentage.P10 = cur.FeeChargedPercentageP10
+	feeStats.FeeChargedPercentage.P20 = cur.FeeChargedPercentageP20
+	feeStats.FeeChargedPercentage.P30 = cur.FeeChargedPercentageP30
+	feeStats.FeeChargedPercentage.P40 = cur.FeeChargedPercentageP40
+	feeStats.FeeChargedPercentage.P50 = cur.FeeChargedPercentageP50
+	feeStats.FeeChargedPercentage.P60 = cur.FeeChargedPercentageP60
+	feeStats.FeeChargedPercentage.P70 = cur.FeeChargedPercentageP70
+	feeStats.FeeChargedPercentage.P80 = cur.FeeChargedPercentageP80
+	feeStats.FeeChargedPercentage.P90 = cur.FeeChargedPercentageP90
+	feeStats.FeeChargedPercentage.P95 = cur.FeeChargedPercentageP95
+	feeStats.FeeChargedPercentage.P99 = cur.FeeChargedPercentageP99
+
 	return feeStats, nil
 }
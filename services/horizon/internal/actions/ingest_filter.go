@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ingest/filters"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// FilterRule is the admin resource of the rules of an ingestion filter.
+type FilterRule struct {
+	Name    string          `json:"name"`
+	Enabled bool            `json:"enabled"`
+	Rules   json.RawMessage `json:"rules"`
+	// Version is the version of the rules, 0 if the rules were never set.
+	Version      int64      `json:"version"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+}
+
+var filterNames = []string{history.FilterAccountName, history.FilterAssetName}
+
+func newFilterRule(rule history.FilterRule) FilterRule {
+	resource := FilterRule{
+		Name:    rule.Name,
+		Enabled: rule.Enabled,
+		Rules:   rule.Rules,
+		Version: rule.Version,
+	}
+	if !rule.LastModified.IsZero() {
+		lastModified := rule.LastModified
+		resource.LastModified = &lastModified
+	}
+	return resource
+}
+
+func getFilterName(r *http.Request) (string, error) {
+	name, err := getStringFromURLParam(r, "name")
+	if err != nil {
+		return "", err
+	}
+	for _, filterName := range filterNames {
+		if name == filterName {
+			return name, nil
+		}
+	}
+	return "", problem.NotFound
+}
+
+func getFilterRule(r *http.Request, historyQ *history.Q, name string) (FilterRule, error) {
+	rule, err := historyQ.GetFilterRule(r.Context(), name)
+	if errors.Cause(err) == sql.ErrNoRows {
+		// filters whose rules were never set are disabled
+		return FilterRule{Name: name, Rules: json.RawMessage(`{"whitelist":[]}`)}, nil
+	}
+	if err != nil {
+		return FilterRule{}, err
+	}
+	return newFilterRule(rule), nil
+}
+
+// GetFilterRulesHandler is the admin action handler for the
+// /ingestion/filters endpoint, which returns the rules of all the ingestion
+// filters.
+type GetFilterRulesHandler struct{}
+
+// GetResource returns the rules of all the ingestion filters.
+func (handler GetFilterRulesHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]FilterRule, 0, len(filterNames))
+	for _, name := range filterNames {
+		rule, err := getFilterRule(r, historyQ, name)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// GetFilterRuleHandler is the admin action handler for the
+// /ingestion/filters/{name} endpoint.
+type GetFilterRuleHandler struct{}
+
+// GetResource returns the rules of an ingestion filter.
+func (handler GetFilterRuleHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	name, err := getFilterName(r)
+	if err != nil {
+		return nil, err
+	}
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return getFilterRule(r, historyQ, name)
+}
+
+// UpdateFilterRuleRequest is the body of the requests updating the rules of
+// an ingestion filter.
+type UpdateFilterRuleRequest struct {
+	Enabled bool            `json:"enabled"`
+	Rules   json.RawMessage `json:"rules"`
+}
+
+// UpdateFilterRuleHandler is the admin action handler updating the rules of
+// an ingestion filter with PUT /ingestion/filters/{name}. The rules are used
+// by ingestion from the next ledgers on, see filters.Filters.
+type UpdateFilterRuleHandler struct{}
+
+// GetResource updates the rules of an ingestion filter and returns them.
+func (handler UpdateFilterRuleHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	name, err := getFilterName(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var request UpdateFilterRuleRequest
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, problem.NewProblemWithInvalidField(problem.BadRequest, "body", err)
+	}
+	if len(request.Rules) == 0 {
+		return nil, problem.MakeInvalidFieldProblem("rules", errors.New("rules are required"))
+	}
+	if _, err = filters.ParseRules(name, request.Rules); err != nil {
+		return nil, problem.MakeInvalidFieldProblem("rules", err)
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := historyQ.UpsertFilterRule(r.Context(), name, request.Enabled, request.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return newFilterRule(rule), nil
+}
@@ -0,0 +1,76 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestFilterRuleHandlers(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	getHandler := GetFilterRuleHandler{}
+	response, err := getHandler.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"name": history.FilterAccountName}, tt.HorizonSession()),
+	)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(FilterRule{
+		Name:  history.FilterAccountName,
+		Rules: json.RawMessage(`{"whitelist":[]}`),
+	}, response)
+
+	_, err = getHandler.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"name": "unknown"}, tt.HorizonSession()),
+	)
+	tt.Assert.Equal(problem.NotFound, err)
+
+	updateHandler := UpdateFilterRuleHandler{}
+	update := func(name, body string) (interface{}, error) {
+		request := makeRequest(t, map[string]string{}, map[string]string{"name": name}, tt.HorizonSession())
+		request.Body = ioutil.NopCloser(bytes.NewBufferString(body))
+		return updateHandler.GetResource(httptest.NewRecorder(), request)
+	}
+
+	_, err = update(history.FilterAssetName, `{"enabled": true, "rules": {"whitelist": ["USD"]}}`)
+	if tt.Assert.IsType(&problem.P{}, err) {
+		tt.Assert.Equal("rules", err.(*problem.P).Extras["invalid_field"])
+	}
+
+	response, err = update(
+		history.FilterAssetName,
+		`{"enabled": true, "rules": {"whitelist": ["USD:GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"]}}`,
+	)
+	tt.Assert.NoError(err)
+	rule := response.(FilterRule)
+	tt.Assert.True(rule.Enabled)
+	tt.Assert.NotNil(rule.LastModified)
+	firstVersion := rule.Version
+
+	response, err = update(history.FilterAccountName, `{"enabled": false, "rules": {"whitelist": []}}`)
+	tt.Assert.NoError(err)
+	tt.Assert.Greater(response.(FilterRule).Version, firstVersion)
+
+	response, err = GetFilterRulesHandler{}.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{}, tt.HorizonSession()),
+	)
+	tt.Assert.NoError(err)
+	rules := response.([]FilterRule)
+	tt.Assert.Len(rules, 2)
+	tt.Assert.False(rules[0].Enabled)
+	tt.Assert.True(rules[1].Enabled)
+	tt.Assert.JSONEq(
+		`{"whitelist": ["USD:GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"]}`,
+		string(rules[1].Rules),
+	)
+}
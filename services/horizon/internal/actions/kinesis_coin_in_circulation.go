@@ -1,10 +1,14 @@
 package actions
 
 import (
+	"database/sql"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/stellar/go/amount"
 	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
 	"github.com/stellar/go/services/horizon/internal/context"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
@@ -21,8 +25,7 @@ type KinesisCoinInCirculationByLedgerIDQuery struct {
 
 // KinesisCoinInCirculationByLedger is the action handler for the /ledger/{ledger_id} endpoint
 type GetKinesisCoinInCirculationByLedgerHandler struct {
-	LedgerState       *ledger.State
-	NetworkPassphrase string
+	LedgerState *ledger.State
 }
 
 // GetResource returns a kinesis coin in circulation by ledger id.
@@ -37,10 +40,17 @@ func (handler GetKinesisCoinInCirculationByLedgerHandler) GetResource(w HeaderWr
 		return nil, err
 	}
 
+	ledgerState := handler.LedgerState.CurrentStatus()
+	if qp.LedgerID < uint64(ledgerState.HistoryElder) {
+		return nil, horizonProblem.BeforeHistory
+	}
+	if qp.LedgerID > uint64(ledgerState.HistoryLatest) {
+		return nil, horizonProblem.AfterHistory
+	}
+
 	criteria := history.KinesisCoinInCirculationByLedgerQuery{
 		LedgerID: qp.LedgerID,
 	}
-	criteria.PopulateAccounts(handler.NetworkPassphrase)
 
 	// get data
 	historyQ, err := context.HistoryQFromRequest(r)
@@ -48,18 +58,41 @@ func (handler GetKinesisCoinInCirculationByLedgerHandler) GetResource(w HeaderWr
 		return nil, err
 	}
 
-	cic := horizon.KinesisDailyCoinInCirculationByLedger{}
-	records, _ := historyQ.KinesisCoinInCirculationByLedger(ctx, criteria)
+	if err = checkKinesisCoinInCirculationBackfilled(r, historyQ); err != nil {
+		return nil, err
+	}
 
-	if len(records) > 0 {
-		cic.Timestamp = records[0].Timestamp
-		cic.Ledger = records[0].Ledger
-		cic.Circulation = records[0].Circulation
-		cic.Mint = records[0].Mint
-		cic.Redemption = records[0].Redemption
+	records, err := historyQ.KinesisCoinInCirculationByLedger(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+	// no coins were minted up to the ledger, so none are in circulation as
+	// long as the ledger was ingested
+	if len(records) == 0 {
+		var ledger history.Ledger
+		err = historyQ.LedgerBySequence(ctx, &ledger, int32(qp.LedgerID))
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, supportProblem.NotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return horizon.KinesisDailyCoinInCirculationByLedger{
+			Timestamp:   ledger.ClosedAt.UTC().Format(time.RFC3339Nano),
+			Ledger:      uint32(ledger.Sequence),
+			Circulation: amount.StringFromInt64(0),
+			Mint:        amount.StringFromInt64(0),
+			Redemption:  amount.StringFromInt64(0),
+		}, nil
 	}
 
-	return cic, nil
+	return horizon.KinesisDailyCoinInCirculationByLedger{
+		Timestamp:   records[0].Timestamp,
+		Ledger:      records[0].Ledger,
+		Circulation: amount.StringFromInt64(records[0].Circulation),
+		Mint:        amount.StringFromInt64(records[0].Mint),
+		Redemption:  amount.StringFromInt64(records[0].Redemption),
+	}, nil
 }
 
 type KinesisCoinInCirculationQuery struct {
@@ -67,8 +100,8 @@ type KinesisCoinInCirculationQuery struct {
 }
 
 type KinesisCoinInCirculationHandler struct {
-	LedgerState       *ledger.State
-	NetworkPassphrase string
+	LedgerState      *ledger.State
+	TreasuryAccounts history.KinesisTreasuryAccounts
 }
 
 func (handler KinesisCoinInCirculationHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
@@ -79,6 +112,8 @@ func (handler KinesisCoinInCirculationHandler) GetResource(w HeaderWriter, r *ht
 	self := "/coin_in_circulation"
 	cic.Links.Self = lb.Link(self)
 	cic.Links.Ledger = lb.Link(self, "ledger/{sequence}")
+	cic.Links.Records = lb.Link(KinesisCoinInCirculationRecordsQuery{}.URITemplate())
+	cic.Links.Events = lb.Link(KinesisCoinInCirculationEventsQuery{}.URITemplate())
 
 	if handler.LedgerState.CurrentStatus().HorizonStatus.HistoryElder > 2 {
 		return nil, horizonProblem.PartialLedgerIngested
@@ -96,7 +131,6 @@ func (handler KinesisCoinInCirculationHandler) GetResource(w HeaderWriter, r *ht
 		}
 		criteria.FromDate = date.Format("2006-01-02")
 	}
-	criteria.PopulateAccounts(handler.NetworkPassphrase)
 
 	// get data
 	historyQ, err := context.HistoryQFromRequest(r)
@@ -104,13 +138,20 @@ func (handler KinesisCoinInCirculationHandler) GetResource(w HeaderWriter, r *ht
 		return nil, err
 	}
 
-	records, _ := historyQ.KinesisCoinInCirculation(ctx, criteria)
+	if err = checkKinesisCoinInCirculationBackfilled(r, historyQ); err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.KinesisCoinInCirculation(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
 	cic.Records = make([]horizon.KinesisDailyCoinInCirculation, len(records))
 	for i, record := range records {
 		cic.Records[i].Date = record.TxDate
-		cic.Records[i].Circulation = record.Circulation
-		cic.Records[i].Mint = record.Mint
-		cic.Records[i].Redemption = record.Redemption
+		cic.Records[i].Circulation = amount.StringFromInt64(record.Circulation)
+		cic.Records[i].Mint = amount.StringFromInt64(record.Mint)
+		cic.Records[i].Redemption = amount.StringFromInt64(record.Redemption)
 		cic.Records[i].Ledger = record.Ledger
 	}
 
@@ -119,6 +160,290 @@ func (handler KinesisCoinInCirculationHandler) GetResource(w HeaderWriter, r *ht
 	cic.HorizonSequence = ledgerState.HistoryLatest
 	cic.HorizonLatestClosedAt = ledgerState.HistoryLatestClosedAt
 	cic.HistoryElderSequence = ledgerState.HistoryElder
+	cic.Accounts = horizon.KinesisTreasuryAccounts{
+		Root:       handler.TreasuryAccounts.RootAccount,
+		Emission:   handler.TreasuryAccounts.EmissionAccount,
+		HotWallets: handler.TreasuryAccounts.HotWalletAccounts,
+		Feepool:    handler.TreasuryAccounts.FeepoolAccount,
+	}
 
 	return cic, nil
 }
+
+// KinesisCoinInCirculationRecordsQuery query struct for the
+// /coin_in_circulation/records endpoint
+type KinesisCoinInCirculationRecordsQuery struct {
+	From       string `schema:"from" valid:"-"`
+	To         string `schema:"to" valid:"-"`
+	Resolution string `schema:"resolution" valid:"-"`
+}
+
+// URITemplate returns a rfc6570 URI template the query struct
+func (q KinesisCoinInCirculationRecordsQuery) URITemplate() string {
+	return getURITemplate(&q, "coin_in_circulation/records", true)
+}
+
+// Validate runs extra validations on the query parameters
+func (q KinesisCoinInCirculationRecordsQuery) Validate() error {
+	from, err := q.FromTime()
+	if err != nil {
+		return err
+	}
+	to, err := q.ToTime()
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		return supportProblem.MakeInvalidFieldProblem(
+			"to",
+			errors.New("`to` must be after `from`"),
+		)
+	}
+	_, err = q.ResolutionValue()
+	return err
+}
+
+// FromTime returns the parsed `from` parameter, zero if it is not set.
+func (q KinesisCoinInCirculationRecordsQuery) FromTime() (time.Time, error) {
+	return parseKinesisCoinInCirculationTime("from", q.From)
+}
+
+// ToTime returns the parsed `to` parameter, zero if it is not set.
+func (q KinesisCoinInCirculationRecordsQuery) ToTime() (time.Time, error) {
+	return parseKinesisCoinInCirculationTime("to", q.To)
+}
+
+// ResolutionValue returns the requested resolution, `day` by default.
+func (q KinesisCoinInCirculationRecordsQuery) ResolutionValue() (history.KinesisCoinInCirculationResolution, error) {
+	if q.Resolution == "" {
+		return history.KinesisCoinInCirculationDayResolution, nil
+	}
+	for _, resolution := range history.KinesisCoinInCirculationResolutions {
+		if string(resolution) == q.Resolution {
+			return resolution, nil
+		}
+	}
+	return "", supportProblem.MakeInvalidFieldProblem(
+		"resolution",
+		errors.New("illegal resolution. allowed resolutions are: ledger, hour, day, week and month"),
+	)
+}
+
+func parseKinesisCoinInCirculationTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, supportProblem.MakeInvalidFieldProblem(
+			name,
+			errors.New("`"+name+"` is not a valid date format. Please use ISO8061 format e.g 2020-04-30T04:00:00.000Z"),
+		)
+	}
+	return t, nil
+}
+
+// KinesisCoinInCirculationRecordsHandler is the action handler for the
+// /coin_in_circulation/records endpoint
+type KinesisCoinInCirculationRecordsHandler struct {
+	LedgerState *ledger.State
+}
+
+// GetResourcePage returns a page of coin in circulation records.
+func (handler KinesisCoinInCirculationRecordsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	if handler.LedgerState.CurrentStatus().HorizonStatus.HistoryElder > 2 {
+		return nil, horizonProblem.PartialLedgerIngested
+	}
+
+	qp := KinesisCoinInCirculationRecordsQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCursorWithinHistory(handler.LedgerState, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	// errors were checked by getParams
+	resolution, _ := qp.ResolutionValue()
+	from, _ := qp.FromTime()
+	to, _ := qp.ToTime()
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkKinesisCoinInCirculationBackfilled(r, historyQ); err != nil {
+		return nil, err
+	}
+
+	records, err := historyQ.KinesisCoinInCirculationRecords(ctx, history.KinesisCoinInCirculationRecordsQuery{
+		Resolution: resolution,
+		From:       from,
+		To:         to,
+		Page:       pq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []hal.Pageable
+	for _, record := range records {
+		result = append(result, horizon.KinesisCoinInCirculationRecord{
+			ID:              record.PagingToken(),
+			Resolution:      string(resolution),
+			Timestamp:       record.Timestamp,
+			Ledger:          record.LedgerSequence,
+			Mint:            amount.StringFromInt64(record.Mint),
+			Redemption:      amount.StringFromInt64(record.Redemption),
+			TotalMint:       amount.StringFromInt64(record.TotalMint),
+			TotalRedemption: amount.StringFromInt64(record.TotalRedemption),
+			Circulation:     amount.StringFromInt64(record.Circulation),
+		})
+	}
+
+	return result, nil
+}
+
+// CSVHeader returns the header of the text/csv representation of the records.
+func (handler KinesisCoinInCirculationRecordsHandler) CSVHeader() []string {
+	return []string{
+		"paging_token",
+		"resolution",
+		"timestamp",
+		"ledger",
+		"mint",
+		"redemption",
+		"total_mint",
+		"total_redemption",
+		"circulation",
+	}
+}
+
+// CSVRecord returns the text/csv representation of a record returned by
+// GetResourcePage.
+func (handler KinesisCoinInCirculationRecordsHandler) CSVRecord(pageable hal.Pageable) []string {
+	record := pageable.(horizon.KinesisCoinInCirculationRecord)
+	return []string{
+		record.ID,
+		record.Resolution,
+		record.Timestamp.UTC().Format(time.RFC3339),
+		strconv.FormatUint(uint64(record.Ledger), 10),
+		record.Mint,
+		record.Redemption,
+		record.TotalMint,
+		record.TotalRedemption,
+		record.Circulation,
+	}
+}
+
+// KinesisCoinInCirculationEventsQuery query struct for the
+// /coin_in_circulation/events endpoint
+type KinesisCoinInCirculationEventsQuery struct {
+	Type string `schema:"type" valid:"-"`
+}
+
+// URITemplate returns a rfc6570 URI template the query struct
+func (q KinesisCoinInCirculationEventsQuery) URITemplate() string {
+	return getURITemplate(&q, "coin_in_circulation/events", true)
+}
+
+// Validate runs extra validations on the query parameters
+func (q KinesisCoinInCirculationEventsQuery) Validate() error {
+	switch q.Type {
+	case "", history.KinesisCoinInCirculationMintEvent, history.KinesisCoinInCirculationRedemptionEvent:
+		return nil
+	default:
+		return supportProblem.MakeInvalidFieldProblem(
+			"type",
+			errors.New("illegal type. allowed types are: mint and redemption"),
+		)
+	}
+}
+
+// KinesisCoinInCirculationEventsHandler is the action handler for the
+// /coin_in_circulation/events endpoint
+type KinesisCoinInCirculationEventsHandler struct {
+	LedgerState      *ledger.State
+	TreasuryAccounts history.KinesisTreasuryAccounts
+}
+
+// GetResourcePage returns a page of the operations which minted or redeemed
+// coins.
+func (handler KinesisCoinInCirculationEventsHandler) GetResourcePage(w HeaderWriter, r *http.Request) ([]hal.Pageable, error) {
+	ctx := r.Context()
+	qp := KinesisCoinInCirculationEventsQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	pq, err := GetPageQuery(handler.LedgerState, r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCursorWithinHistory(handler.LedgerState, pq)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := historyQ.KinesisCoinInCirculationEvents(ctx, history.KinesisCoinInCirculationEventsQuery{
+		Accounts:  handler.TreasuryAccounts,
+		EventType: qp.Type,
+		Page:      pq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lb := hal.LinkBuilder{Base: horizonContext.BaseURL(ctx)}
+	var result []hal.Pageable
+	for _, event := range events {
+		id := strconv.FormatInt(event.OperationID, 10)
+		resource := horizon.KinesisCoinInCirculationEvent{
+			ID:              id,
+			PT:              id,
+			Type:            event.EventType,
+			Ledger:          event.LedgerSequence,
+			LedgerCloseTime: event.LedgerCloseTime,
+			TransactionHash: event.TransactionHash,
+			OperationID:     id,
+			OperationType:   operations.TypeNames[event.OperationType],
+			Source:          event.Source,
+			Destination:     event.Destination,
+			Amount:          event.Amount,
+		}
+		resource.Links.Operation = lb.Linkf("/operations/%s", id)
+		resource.Links.Transaction = lb.Linkf("/transactions/%s", event.TransactionHash)
+		result = append(result, resource)
+	}
+
+	return result, nil
+}
+
+// checkKinesisCoinInCirculationBackfilled returns a problem while the coin in
+// circulation tables do not cover the ledgers ingested before they were
+// created, rather than serving partial totals.
+func checkKinesisCoinInCirculationBackfilled(r *http.Request, historyQ *history.Q) error {
+	pending, err := historyQ.KinesisCoinInCirculationBackfillPending(r.Context())
+	if err != nil {
+		return err
+	}
+	if pending {
+		return horizonProblem.KinesisCoinInCirculationBackfilling
+	}
+	return nil
+}
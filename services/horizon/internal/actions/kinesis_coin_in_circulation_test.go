@@ -0,0 +1,211 @@
+package actions
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKinesisCoinInCirculationRecordsQueryValidate(t *testing.T) {
+	for _, testCase := range []struct {
+		query KinesisCoinInCirculationRecordsQuery
+		field string
+	}{
+		{KinesisCoinInCirculationRecordsQuery{}, ""},
+		{KinesisCoinInCirculationRecordsQuery{From: "2021-06-01T00:00:00Z", To: "2021-07-01T00:00:00Z", Resolution: "week"}, ""},
+		{KinesisCoinInCirculationRecordsQuery{From: "2021-06-01"}, "from"},
+		{KinesisCoinInCirculationRecordsQuery{To: "yesterday"}, "to"},
+		{KinesisCoinInCirculationRecordsQuery{From: "2021-06-01T00:00:00Z", To: "2021-06-01T00:00:00Z"}, "to"},
+		{KinesisCoinInCirculationRecordsQuery{Resolution: "minute"}, "resolution"},
+	} {
+		err := testCase.query.Validate()
+		if testCase.field == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"])
+		}
+	}
+
+	resolution, err := KinesisCoinInCirculationRecordsQuery{}.ResolutionValue()
+	assert.NoError(t, err)
+	assert.Equal(t, history.KinesisCoinInCirculationDayResolution, resolution)
+}
+
+func TestGetKinesisCoinInCirculationRecords(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &history.Q{tt.HorizonSession()}
+
+	day := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, row := range []history.KinesisCoinInCirculationLedger{
+		{LedgerSequence: 10, ClosedAt: day.Add(time.Hour), Mint: 100},
+		{LedgerSequence: 20, ClosedAt: day.Add(90 * time.Minute), Redemption: 30},
+		{LedgerSequence: 30, ClosedAt: day.AddDate(0, 0, 1), Mint: 50},
+	} {
+		tt.Assert.NoError(q.InsertKinesisCoinInCirculationLedger(tt.Ctx, row))
+	}
+	tt.Assert.NoError(q.RebuildKinesisCoinInCirculation(tt.Ctx, 10))
+
+	handler := KinesisCoinInCirculationRecordsHandler{LedgerState: &ledger.State{}}
+	records, err := handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"resolution": "hour"},
+		map[string]string{},
+		q,
+	))
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 2) {
+		record := records[0].(protocol.KinesisCoinInCirculationRecord)
+		tt.Assert.Equal("hour", record.Resolution)
+		tt.Assert.True(day.Add(time.Hour).Equal(record.Timestamp))
+		tt.Assert.Equal(uint32(20), record.Ledger)
+		tt.Assert.Equal("0.0000100", record.Mint)
+		tt.Assert.Equal("0.0000030", record.Redemption)
+		tt.Assert.Equal("0.0000070", record.Circulation)
+		tt.Assert.Equal(uint32(30), records[1].(protocol.KinesisCoinInCirculationRecord).Ledger)
+	}
+
+	// pages continue after the last ledger of the previous page
+	records, err = handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"resolution": "ledger", "cursor": records[0].PagingToken()},
+		map[string]string{},
+		q,
+	))
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		tt.Assert.Equal("0.0000120", records[0].(protocol.KinesisCoinInCirculationRecord).Circulation)
+	}
+
+	records, err = handler.GetResourcePage(httptest.NewRecorder(), makeRequest(
+		t,
+		map[string]string{"to": "2021-06-02T00:00:00Z", "order": "desc"},
+		map[string]string{},
+		q,
+	))
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		record := records[0].(protocol.KinesisCoinInCirculationRecord)
+		tt.Assert.Equal("day", record.Resolution)
+		tt.Assert.Equal(uint32(20), record.Ledger)
+	}
+}
+
+func TestGetKinesisCoinInCirculationByLedgerOutsideHistory(t *testing.T) {
+	ledgerState := &ledger.State{}
+	ledgerState.SetHorizonStatus(ledger.HorizonStatus{HistoryElder: 2, HistoryLatest: 100})
+	handler := GetKinesisCoinInCirculationByLedgerHandler{LedgerState: ledgerState}
+
+	_, err := handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"ledger_id": "1"}, nil,
+	))
+	assert.Equal(t, hProblem.BeforeHistory, err)
+
+	_, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"ledger_id": "101"}, nil,
+	))
+	assert.Equal(t, hProblem.AfterHistory, err)
+
+	_, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"ledger_id": "abc"}, nil,
+	))
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "ledger_id", err.(*problem.P).Extras["invalid_field"])
+	}
+}
+
+func TestGetKinesisCoinInCirculationByLedger(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &history.Q{tt.HorizonSession()}
+
+	ledgerState := &ledger.State{}
+	ledgerState.SetHorizonStatus(ledger.HorizonStatus{HistoryElder: 1, HistoryLatest: 100})
+	handler := GetKinesisCoinInCirculationByLedgerHandler{LedgerState: ledgerState}
+
+	// the ledger was not ingested
+	_, err := handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"ledger_id": "5"}, q,
+	))
+	tt.Assert.Equal(problem.NotFound, err)
+
+	// no coins were minted yet
+	_, err = q.InsertLedger(tt.Ctx, xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq: 5,
+			ScpValue: xdr.StellarValue{
+				CloseTime: xdr.TimePoint(time.Date(2021, 5, 31, 0, 0, 0, 0, time.UTC).Unix()),
+			},
+		},
+	}, 0, 0, 0, 0, 0, 0)
+	tt.Assert.NoError(err)
+	response, err := handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"ledger_id": "5"}, q,
+	))
+	tt.Assert.NoError(err)
+	resource := response.(protocol.KinesisDailyCoinInCirculationByLedger)
+	tt.Assert.Equal(uint32(5), resource.Ledger)
+	tt.Assert.Equal("2021-05-31T00:00:00Z", resource.Timestamp)
+	tt.Assert.Equal("0.0000000", resource.Circulation)
+	tt.Assert.Equal("0.0000000", resource.Mint)
+	tt.Assert.Equal("0.0000000", resource.Redemption)
+
+	tt.Assert.NoError(q.InsertKinesisCoinInCirculationLedger(tt.Ctx, history.KinesisCoinInCirculationLedger{
+		LedgerSequence: 10,
+		ClosedAt:       time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+		Mint:           100,
+	}))
+	tt.Assert.NoError(q.RebuildKinesisCoinInCirculation(tt.Ctx, 10))
+
+	response, err = handler.GetResource(httptest.NewRecorder(), makeRequest(
+		t, map[string]string{}, map[string]string{"ledger_id": "50"}, q,
+	))
+	tt.Assert.NoError(err)
+	resource = response.(protocol.KinesisDailyCoinInCirculationByLedger)
+	tt.Assert.Equal(uint32(10), resource.Ledger)
+	tt.Assert.Equal("0.0000100", resource.Circulation)
+
+	// db errors are not hidden behind an empty response
+	request := makeRequest(t, map[string]string{}, map[string]string{"ledger_id": "50"}, q)
+	ctx, cancel := context.WithTimeout(request.Context(), 0)
+	defer cancel()
+	_, err = handler.GetResource(httptest.NewRecorder(), request.WithContext(ctx))
+	tt.Assert.ErrorIs(err, db.ErrTimeout)
+
+	_, err = KinesisCoinInCirculationHandler{LedgerState: ledgerState}.GetResource(
+		httptest.NewRecorder(),
+		request.WithContext(ctx),
+	)
+	tt.Assert.ErrorIs(err, db.ErrTimeout)
+}
+
+func TestKinesisCoinInCirculationEventsQueryValidate(t *testing.T) {
+	assert.NoError(t, KinesisCoinInCirculationEventsQuery{}.Validate())
+	assert.NoError(t, KinesisCoinInCirculationEventsQuery{Type: "mint"}.Validate())
+	assert.NoError(t, KinesisCoinInCirculationEventsQuery{Type: "redemption"}.Validate())
+
+	err := KinesisCoinInCirculationEventsQuery{Type: "burn"}.Validate()
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, "type", err.(*problem.P).Extras["invalid_field"])
+	}
+	assert.Equal(
+		t,
+		"/coin_in_circulation/events{?type,cursor,limit,order}",
+		KinesisCoinInCirculationEventsQuery{}.URITemplate(),
+	)
+}
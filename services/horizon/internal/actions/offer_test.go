@@ -86,7 +86,7 @@ func TestGetOfferByIDHandler(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	tt.Assert.NoError(err)
 
 	err = q.UpsertOffers(tt.Ctx, []history.Offer{eurOffer, usdOffer})
@@ -193,7 +193,7 @@ func TestGetOffersHandler(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	tt.Assert.NoError(err)
 
 	err = q.UpsertOffers(tt.Ctx, []history.Offer{eurOffer, twoEurOffer, usdOffer})
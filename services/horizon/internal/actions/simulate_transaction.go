@@ -0,0 +1,90 @@
+package actions
+
+import (
+	"net/http"
+
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/operations"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/simulation"
+	"github.com/stellar/go/support/errors"
+)
+
+// SimulateTransactionHandler is the action handler for POST
+// /transactions/simulate, which checks whether a transaction would obviously
+// fail without submitting it. The transaction is simulated on top of the
+// state of the last ingested ledger.
+type SimulateTransactionHandler struct {
+	NetworkPassphrase string
+}
+
+func (handler SimulateTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	if err := (SubmitTransactionHandler{}).validateBodyType(r); err != nil {
+		return nil, err
+	}
+
+	raw, err := getString(r, "tx")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+	if err != nil {
+		return nil, transactionMalformedProblem(raw)
+	}
+
+	ctx := r.Context()
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lastIngestedLedger, err := historyQ.GetLastLedgerIngestNonBlocking(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load last ingested ledger")
+	}
+	var ledger history.Ledger
+	if err = historyQ.LedgerBySequence(ctx, &ledger, int32(lastIngestedLedger)); err != nil {
+		return nil, errors.Wrap(err, "could not load last ingested ledger")
+	}
+
+	simulator := simulation.Simulator{
+		State:             historyQ,
+		NetworkPassphrase: handler.NetworkPassphrase,
+		Ledger:            ledger,
+	}
+	result, err := simulator.Simulate(ctx, info.raw)
+	if errors.Cause(err) == simulation.ErrInvalidTransaction {
+		return nil, transactionMalformedProblem(raw)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not simulate transaction")
+	}
+
+	response := horizon.TransactionSimulation{
+		Hash:                 result.Hash,
+		InnerTransactionHash: result.InnerTransactionHash,
+		Ledger:               ledger.Sequence,
+		Successful:           result.Successful(),
+		MinFee:               result.MinFee,
+		FeeCharged:           result.FeeCharged,
+		ResultCodes: horizon.TransactionResultCodes{
+			TransactionCode:      result.TransactionCode,
+			InnerTransactionCode: result.InnerTransactionCode,
+		},
+		Reason:      result.TransactionReason,
+		InnerReason: result.InnerTransactionReason,
+		Operations:  []horizon.OperationSimulationResult{},
+	}
+	ops := info.parsed.Operations()
+	for i, opResult := range result.Operations {
+		response.ResultCodes.OperationCodes = append(response.ResultCodes.OperationCodes, opResult.Code)
+		response.Operations = append(response.Operations, horizon.OperationSimulationResult{
+			Index:      i,
+			Type:       operations.TypeNames[ops[i].Body.Type],
+			ResultCode: opResult.Code,
+			Reason:     opResult.Reason,
+		})
+	}
+	return response, nil
+}
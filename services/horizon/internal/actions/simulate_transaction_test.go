@@ -0,0 +1,105 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+func newSimulateRequest(t *testing.T, tx string, session db.SessionInterface) *http.Request {
+	form := url.Values{}
+	form.Set("tx", tx)
+	request, err := http.NewRequest(
+		"POST",
+		"https://horizon.stellar.org/transactions/simulate",
+		strings.NewReader(form.Encode()),
+	)
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	ctx := context.WithValue(context.Background(), &horizonContext.SessionContextKey, session)
+	return request.WithContext(ctx)
+}
+
+func TestSimulateTransactionMalformedTx(t *testing.T) {
+	handler := SimulateTransactionHandler{NetworkPassphrase: network.PublicNetworkPassphrase}
+
+	_, err := handler.GetResource(httptest.NewRecorder(), newSimulateRequest(t, "AAAA", nil))
+	require.Error(t, err)
+	p, ok := err.(*problem.P)
+	require.True(t, ok)
+	assert.Equal(t, "transaction_malformed", p.Type)
+}
+
+func TestSimulateTransaction(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	q := &history.Q{tt.HorizonSession()}
+	handler := SimulateTransactionHandler{NetworkPassphrase: network.PublicNetworkPassphrase}
+	root := keypair.Root(network.PublicNetworkPassphrase).Address()
+
+	_, err := q.InsertLedger(tt.Ctx, xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq:         10,
+			BaseFee:           100,
+			BasePercentageFee: 45,
+			BaseReserve:       5000000,
+			ScpValue: xdr.StellarValue{
+				CloseTime: xdr.TimePoint(time.Now().Unix()),
+			},
+		},
+	}, 0, 0, 0, 0, 0, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.NoError(q.UpdateLastLedgerIngest(tt.Ctx, 10))
+
+	err = q.UpsertAccounts(tt.Ctx, []history.AccountEntry{{
+		AccountID:          root,
+		Balance:            1000000000000,
+		SequenceNumber:     100,
+		MasterWeight:       1,
+		LastModifiedLedger: 10,
+	}})
+	tt.Assert.NoError(err)
+	_, err = q.CreateAccountSigner(tt.Ctx, root, root, 1, nil)
+	tt.Assert.NoError(err)
+
+	result, err := handler.GetResource(
+		httptest.NewRecorder(),
+		newSimulateRequest(t, signedTestTx(t, 101), q),
+	)
+	tt.Assert.NoError(err)
+	simulation := result.(horizon.TransactionSimulation)
+	tt.Assert.True(simulation.Successful)
+	tt.Assert.Equal(int32(10), simulation.Ledger)
+	tt.Assert.Equal("tx_success", simulation.ResultCodes.TransactionCode)
+	tt.Assert.Equal([]string{"op_success"}, simulation.ResultCodes.OperationCodes)
+	tt.Assert.Len(simulation.Operations, 1)
+	tt.Assert.Equal("payment", simulation.Operations[0].Type)
+
+	result, err = handler.GetResource(
+		httptest.NewRecorder(),
+		newSimulateRequest(t, signedTestTx(t, 200), q),
+	)
+	tt.Assert.NoError(err)
+	simulation = result.(horizon.TransactionSimulation)
+	tt.Assert.False(simulation.Successful)
+	tt.Assert.Equal("tx_bad_seq", simulation.ResultCodes.TransactionCode)
+}
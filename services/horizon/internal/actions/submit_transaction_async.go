@@ -0,0 +1,148 @@
+package actions
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/stellar/go/protocols/horizon"
+	proto "github.com/stellar/go/protocols/stellarcore"
+	horizonContext "github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+type AsyncNetworkSubmitter interface {
+	SubmitAsync(
+		ctx context.Context,
+		rawTx string,
+		envelope xdr.TransactionEnvelope,
+		hash string) txsub.SubmissionResult
+}
+
+// AsyncSubmitTransactionHandler is the action handler for POST
+// /transactions_async, which returns as soon as stellar-core responded to the
+// submission instead of waiting for the transaction to be ingested.
+type AsyncSubmitTransactionHandler struct {
+	Submitter         AsyncNetworkSubmitter
+	NetworkPassphrase string
+	CoreStateGetter
+}
+
+// asyncTxStatusCodes maps the stellar-core statuses to the status codes of
+// the responses.
+var asyncTxStatusCodes = map[string]int{
+	proto.TXStatusPending:       http.StatusCreated,
+	proto.TXStatusDuplicate:     http.StatusConflict,
+	proto.TXStatusTryAgainLater: http.StatusServiceUnavailable,
+	proto.TXStatusError:         http.StatusBadRequest,
+}
+
+func (handler AsyncSubmitTransactionHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	if err := (SubmitTransactionHandler{}).validateBodyType(r); err != nil {
+		return nil, err
+	}
+
+	raw, err := getString(r, "tx")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+	if err != nil {
+		return nil, transactionMalformedProblem(raw)
+	}
+
+	coreState := handler.GetCoreState()
+	if !coreState.Synced {
+		return nil, hProblem.StaleHistory
+	}
+
+	result := handler.Submitter.SubmitAsync(r.Context(), info.raw, info.parsed, info.hash)
+	if result.Status == "" {
+		// stellar-core could not be reached or its response was invalid
+		if result.Err == nil {
+			result.Err = errors.New("missing stellar-core status")
+		}
+		return nil, errors.Wrap(result.Err, "could not submit transaction to stellar-core")
+	}
+
+	statusCode, ok := asyncTxStatusCodes[result.Status]
+	if !ok {
+		return nil, errors.Errorf("unrecognized stellar-core status: %s", result.Status)
+	}
+
+	response := horizon.AsyncTransactionSubmissionResponse{
+		TxStatus:   result.Status,
+		Hash:       info.hash,
+		HTTPStatus: statusCode,
+	}
+	if failed, ok := result.Err.(*txsub.FailedTransactionError); ok {
+		response.ErrorResultXDR = failed.ResultXDR
+	}
+	lb := hal.LinkBuilder{Base: horizonContext.BaseURL(r.Context())}
+	response.Links.Status = lb.Link("/transactions_async", info.hash)
+	return response, nil
+}
+
+// AsyncTransactionStatusGetter returns whether a transaction submitted to
+// stellar-core is still waiting to be ingested.
+type AsyncTransactionStatusGetter interface {
+	IsPending(ctx context.Context, hash string) bool
+}
+
+// GetAsyncTransactionStatusHandler is the action handler for GET
+// /transactions_async/{tx_id}, which reports the status of a transaction
+// submitted with POST /transactions_async.
+type GetAsyncTransactionStatusHandler struct {
+	Submitter AsyncTransactionStatusGetter
+}
+
+// GetResource returns the status of a transaction. The transaction is
+// PENDING while it is tracked by the submission system and SUCCESS or FAILED
+// once it was ingested. It is not found when it was never accepted by
+// stellar-core, or when it expired from the submission system before being
+// included in a ledger.
+func (handler GetAsyncTransactionStatusHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	qp := TransactionQuery{}
+	if err := getParams(&qp, r); err != nil {
+		return nil, err
+	}
+
+	historyQ, err := horizonContext.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lb := hal.LinkBuilder{Base: horizonContext.BaseURL(ctx)}
+	response := horizon.AsyncTransactionStatus{Hash: qp.TransactionHash}
+	response.Links.Self = lb.Link("/transactions_async", qp.TransactionHash)
+	response.Links.Transaction = lb.Link("/transactions", qp.TransactionHash)
+
+	var record history.Transaction
+	err = historyQ.TransactionByHash(ctx, &record, qp.TransactionHash)
+	switch {
+	case err == nil:
+		response.TxStatus = horizon.AsyncTxStatusFailed
+		if record.Successful {
+			response.TxStatus = horizon.AsyncTxStatusSuccess
+		}
+		response.Ledger = record.LedgerSequence
+		response.ResultXDR = record.TxResult
+		return response, nil
+	case errors.Cause(err) != sql.ErrNoRows:
+		return nil, errors.Wrap(err, "loading transaction record")
+	}
+
+	if handler.Submitter.IsPending(ctx, qp.TransactionHash) {
+		response.TxStatus = horizon.AsyncTxStatusPending
+		return response, nil
+	}
+	return nil, problem.NotFound
+}
@@ -0,0 +1,202 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/corestate"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// signedTestTx returns a signed payment envelope with the given sequence
+// number, encoded as base64.
+func signedTestTx(t *testing.T, sequence int64) string {
+	kp := keypair.Root(network.PublicNetworkPassphrase)
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount: &txnbuild.SimpleAccount{AccountID: kp.Address(), Sequence: sequence - 1},
+		Operations: []txnbuild.Operation{&txnbuild.Payment{
+			Destination: kp.Address(),
+			Amount:      "10",
+			Asset:       txnbuild.NativeAsset{},
+		}},
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	tx, err = tx.Sign(network.PublicNetworkPassphrase, kp)
+	require.NoError(t, err)
+	raw, err := tx.Base64()
+	require.NoError(t, err)
+	return raw
+}
+
+type asyncSubmitterMock struct {
+	mock.Mock
+}
+
+func (m *asyncSubmitterMock) SubmitAsync(
+	ctx context.Context,
+	rawTx string,
+	envelope xdr.TransactionEnvelope,
+	hash string) txsub.SubmissionResult {
+	a := m.Called(rawTx, hash)
+	return a.Get(0).(txsub.SubmissionResult)
+}
+
+func (m *asyncSubmitterMock) IsPending(ctx context.Context, hash string) bool {
+	a := m.Called(hash)
+	return a.Bool(0)
+}
+
+func newAsyncSubmitRequest(t *testing.T, tx string) *http.Request {
+	form := url.Values{}
+	form.Set("tx", tx)
+	request, err := http.NewRequest(
+		"POST",
+		"https://horizon.stellar.org/transactions_async",
+		strings.NewReader(form.Encode()),
+	)
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	return request
+}
+
+func TestAsyncSubmitTransactionMalformedTx(t *testing.T) {
+	handler := AsyncSubmitTransactionHandler{NetworkPassphrase: network.PublicNetworkPassphrase}
+
+	_, err := handler.GetResource(httptest.NewRecorder(), newAsyncSubmitRequest(t, "AAAA"))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadRequest, err.(*problem.P).Status)
+	assert.Equal(t, "transaction_malformed", err.(*problem.P).Type)
+}
+
+func TestAsyncSubmitTransactionCoreNotSynced(t *testing.T) {
+	coreStateGetter := &coreStateGetterMock{}
+	coreStateGetter.On("GetCoreState").Return(corestate.State{Synced: false})
+
+	handler := AsyncSubmitTransactionHandler{
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		CoreStateGetter:   coreStateGetter,
+	}
+
+	_, err := handler.GetResource(httptest.NewRecorder(), newAsyncSubmitRequest(t, signedTestTx(t, 1)))
+	assert.Error(t, err)
+	assert.Equal(t, "stale_history", err.(problem.P).Type)
+}
+
+func TestAsyncSubmitTransactionStatuses(t *testing.T) {
+	coreStateGetter := &coreStateGetterMock{}
+	coreStateGetter.On("GetCoreState").Return(corestate.State{Synced: true})
+
+	raw := signedTestTx(t, 1)
+	info, err := extractEnvelopeInfo(raw, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+
+	for _, testCase := range []struct {
+		result         txsub.SubmissionResult
+		statusCode     int
+		errorResultXDR string
+	}{
+		{txsub.SubmissionResult{Status: "PENDING"}, http.StatusCreated, ""},
+		{txsub.SubmissionResult{Status: "DUPLICATE"}, http.StatusConflict, ""},
+		{txsub.SubmissionResult{Status: "TRY_AGAIN_LATER"}, http.StatusServiceUnavailable, ""},
+		{
+			txsub.SubmissionResult{Status: "ERROR", Err: &txsub.FailedTransactionError{ResultXDR: "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA="}},
+			http.StatusBadRequest,
+			"AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA=",
+		},
+	} {
+		submitter := &asyncSubmitterMock{}
+		submitter.On("SubmitAsync", raw, info.hash).Return(testCase.result).Once()
+
+		handler := AsyncSubmitTransactionHandler{
+			Submitter:         submitter,
+			NetworkPassphrase: network.PublicNetworkPassphrase,
+			CoreStateGetter:   coreStateGetter,
+		}
+		resource, err := handler.GetResource(httptest.NewRecorder(), newAsyncSubmitRequest(t, raw))
+		require.NoError(t, err)
+		response := resource.(horizon.AsyncTransactionSubmissionResponse)
+		assert.Equal(t, testCase.result.Status, response.TxStatus)
+		assert.Equal(t, info.hash, response.Hash)
+		assert.Equal(t, testCase.statusCode, response.GetStatus())
+		assert.Equal(t, testCase.errorResultXDR, response.ErrorResultXDR)
+		assert.True(t, strings.HasSuffix(response.Links.Status.Href, "/transactions_async/"+info.hash))
+		submitter.AssertExpectations(t)
+	}
+}
+
+func TestAsyncSubmitTransactionCoreUnreachable(t *testing.T) {
+	coreStateGetter := &coreStateGetterMock{}
+	coreStateGetter.On("GetCoreState").Return(corestate.State{Synced: true})
+
+	raw := signedTestTx(t, 1)
+	info, err := extractEnvelopeInfo(raw, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+	submitter := &asyncSubmitterMock{}
+	submitter.On("SubmitAsync", raw, info.hash).
+		Return(txsub.SubmissionResult{Err: errors.New("connection refused")}).Once()
+
+	handler := AsyncSubmitTransactionHandler{
+		Submitter:         submitter,
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		CoreStateGetter:   coreStateGetter,
+	}
+	_, err = handler.GetResource(httptest.NewRecorder(), newAsyncSubmitRequest(t, raw))
+	assert.EqualError(t, err, "could not submit transaction to stellar-core: connection refused")
+}
+
+func TestGetAsyncTransactionStatus(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	tt.Scenario("base")
+
+	submitter := &asyncSubmitterMock{}
+	handler := GetAsyncTransactionStatusHandler{Submitter: submitter}
+	getStatus := func(hash string) (interface{}, error) {
+		return handler.GetResource(
+			httptest.NewRecorder(),
+			makeRequest(t, map[string]string{}, map[string]string{"tx_id": hash}, tt.HorizonSession()),
+		)
+	}
+
+	// ingested transaction
+	ingested := "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d"
+	resource, err := getStatus(ingested)
+	tt.Assert.NoError(err)
+	status := resource.(horizon.AsyncTransactionStatus)
+	tt.Assert.Equal(horizon.AsyncTxStatusSuccess, status.TxStatus)
+	tt.Assert.Equal(int32(3), status.Ledger)
+	tt.Assert.NotEmpty(status.ResultXDR)
+
+	pending := "aa168f12124b7c196c0adaee7c73a64d37f99428cacb59a91ff389626845e7cf"
+	submitter.On("IsPending", pending).Return(true).Once()
+	resource, err = getStatus(pending)
+	tt.Assert.NoError(err)
+	status = resource.(horizon.AsyncTransactionStatus)
+	tt.Assert.Equal(horizon.AsyncTxStatusPending, status.TxStatus)
+	tt.Assert.Equal(pending, status.Hash)
+
+	submitter.On("IsPending", pending).Return(false).Once()
+	_, err = getStatus(pending)
+	tt.Assert.Equal(problem.NotFound, err)
+	submitter.AssertExpectations(t)
+}
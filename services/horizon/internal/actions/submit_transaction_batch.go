@@ -0,0 +1,200 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/stellar/go/protocols/horizon"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/support/render/problem"
+)
+
+// MaxTransactionBatchSize is the maximum number of transactions of a batch
+// submission.
+const MaxTransactionBatchSize = 100
+
+// SubmitTransactionBatchRequest is the body of the requests to POST
+// /transactions_batch.
+type SubmitTransactionBatchRequest struct {
+	Transactions []string `json:"transactions"`
+}
+
+// SubmitTransactionBatchHandler is the action handler for POST
+// /transactions_batch, which submits a batch of transaction envelopes. The
+// envelopes are independent from each other: each of them is submitted with
+// the transaction submission system, which submits the envelopes sharing a
+// source account in the order of their sequence numbers.
+type SubmitTransactionBatchHandler struct {
+	Submitter         NetworkSubmitter
+	NetworkPassphrase string
+	CoreStateGetter
+}
+
+func (handler SubmitTransactionBatchHandler) validateBodyType(r *http.Request) error {
+	c := r.Header.Get("Content-Type")
+	if c == "" {
+		return nil
+	}
+
+	mt, _, err := mime.ParseMediaType(c)
+	if err != nil {
+		return errors.Wrap(err, "Could not determine mime type")
+	}
+
+	if mt != "application/json" {
+		return &problem.P{
+			Type:   "unsupported_media_type",
+			Title:  "Unsupported Media Type",
+			Status: http.StatusUnsupportedMediaType,
+			Detail: "The request has an unsupported content type. Transaction " +
+				"batches must be submitted as application/json.",
+		}
+	}
+	return nil
+}
+
+// Submit validates the envelopes of the batch concurrently and submits them
+// to the network. It returns a channel emitting the result of every envelope
+// as soon as it is final, which is closed after the last result. The returned
+// error is set when the batch itself is invalid, in which case nothing was
+// submitted.
+func (handler SubmitTransactionBatchHandler) Submit(r *http.Request) (<-chan horizon.TransactionBatchResult, error) {
+	if err := handler.validateBodyType(r); err != nil {
+		return nil, err
+	}
+
+	var request SubmitTransactionBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, problem.NewProblemWithInvalidField(problem.BadRequest, "body", err)
+	}
+	if len(request.Transactions) == 0 {
+		return nil, problem.MakeInvalidFieldProblem(
+			"transactions", errors.New("at least one transaction is required"),
+		)
+	}
+	if len(request.Transactions) > MaxTransactionBatchSize {
+		return nil, problem.MakeInvalidFieldProblem(
+			"transactions", errors.Errorf("at most %d transactions can be submitted at once", MaxTransactionBatchSize),
+		)
+	}
+
+	infos := make([]envelopeInfo, len(request.Transactions))
+	errs := make([]error, len(request.Transactions))
+	var wg sync.WaitGroup
+	for i, raw := range request.Transactions {
+		wg.Add(1)
+		go func(i int, raw string) {
+			defer wg.Done()
+			infos[i], errs[i] = extractEnvelopeInfo(raw, handler.NetworkPassphrase)
+		}(i, raw)
+	}
+	wg.Wait()
+
+	indexes := map[string]int{}
+	for i, info := range infos {
+		if errs[i] != nil {
+			continue
+		}
+		if j, ok := indexes[info.hash]; ok {
+			return nil, problem.MakeInvalidFieldProblem(
+				"transactions", errors.Errorf("transaction %d is a duplicate of transaction %d", i, j),
+			)
+		}
+		indexes[info.hash] = i
+	}
+
+	coreState := handler.GetCoreState()
+	if !coreState.Synced {
+		return nil, hProblem.StaleHistory
+	}
+
+	results := make(chan horizon.TransactionBatchResult, len(infos))
+	for i := range infos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if errs[i] != nil {
+				results <- horizon.TransactionBatchResult{
+					Index: i,
+					Error: transactionMalformedProblem(request.Transactions[i]),
+				}
+				return
+			}
+			results <- handler.submit(r, i, infos[i])
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results, nil
+}
+
+func (handler SubmitTransactionBatchHandler) submit(r *http.Request, index int, info envelopeInfo) horizon.TransactionBatchResult {
+	result := horizon.TransactionBatchResult{Index: index, Hash: info.hash}
+
+	var (
+		resource interface{}
+		err      error
+	)
+	submission := handler.Submitter.Submit(r.Context(), info.raw, info.parsed, info.hash)
+	select {
+	case submitted := <-submission:
+		resource, err = SubmitTransactionHandler{}.response(r, info, submitted)
+	case <-r.Context().Done():
+		err = hProblem.Timeout
+		if r.Context().Err() == context.Canceled {
+			err = hProblem.ClientDisconnected
+		}
+	}
+
+	if err != nil {
+		result.Error = batchResultProblem(r.Context(), err)
+		return result
+	}
+	transaction := resource.(horizon.Transaction)
+	result.Transaction = &transaction
+	return result
+}
+
+// batchResultProblem converts the error of a transaction of a batch into a
+// problem. Unexpected errors are logged and replaced by a server error, as
+// problem.Render does.
+func batchResultProblem(ctx context.Context, err error) *problem.P {
+	switch p := errors.Cause(err).(type) {
+	case problem.P:
+		return &p
+	case *problem.P:
+		return p
+	}
+	if known, ok := problem.IsKnownError(err).(problem.P); ok {
+		return &known
+	}
+	log.Ctx(ctx).WithStack(err).Error(err)
+	serverError := problem.ServerError
+	return &serverError
+}
+
+// GetResource submits a batch and returns the results of all its
+// transactions once they are final.
+func (handler SubmitTransactionBatchHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	results, err := handler.Submit(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response := horizon.TransactionBatchResponse{}
+	for result := range results {
+		response.Results = append(response.Results, result)
+	}
+	sort.Slice(response.Results, func(i, j int) bool {
+		return response.Results[i].Index < response.Results[j].Index
+	})
+	return response, nil
+}
@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/corestate"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+type batchSubmitterMock struct {
+	mock.Mock
+}
+
+func (m *batchSubmitterMock) Submit(
+	ctx context.Context,
+	rawTx string,
+	envelope xdr.TransactionEnvelope,
+	hash string) <-chan txsub.Result {
+	a := m.Called(hash)
+	response := make(chan txsub.Result, 1)
+	response <- a.Get(0).(txsub.Result)
+	return response
+}
+
+func newBatchSubmitRequest(t *testing.T, body string) *http.Request {
+	request, err := http.NewRequest(
+		"POST",
+		"https://horizon.stellar.org/transactions_batch",
+		strings.NewReader(body),
+	)
+	require.NoError(t, err)
+	request.Header.Add("Content-Type", "application/json")
+	return request
+}
+
+func TestSubmitTransactionBatchInvalidBatch(t *testing.T) {
+	coreStateGetter := &coreStateGetterMock{}
+	coreStateGetter.On("GetCoreState").Return(corestate.State{Synced: true})
+	handler := SubmitTransactionBatchHandler{
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		CoreStateGetter:   coreStateGetter,
+	}
+
+	tx := signedTestTx(t, 1)
+	for _, testCase := range []struct {
+		body  string
+		field string
+	}{
+		{`{"transactions": "AAAA"}`, "body"},
+		{`{"transactions": []}`, "transactions"},
+		{`{"transactions": ["` + strings.Repeat(`AAAA", "`, MaxTransactionBatchSize) + `AAAA"]}`, "transactions"},
+		{`{"transactions": ["` + tx + `", "` + tx + `"]}`, "transactions"},
+	} {
+		_, err := handler.GetResource(httptest.NewRecorder(), newBatchSubmitRequest(t, testCase.body))
+		if assert.IsType(t, &problem.P{}, err) {
+			assert.Equal(t, testCase.field, err.(*problem.P).Extras["invalid_field"])
+		}
+	}
+
+	request := newBatchSubmitRequest(t, `{"transactions": ["`+tx+`"]}`)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err := handler.GetResource(httptest.NewRecorder(), request)
+	if assert.IsType(t, &problem.P{}, err) {
+		assert.Equal(t, http.StatusUnsupportedMediaType, err.(*problem.P).Status)
+	}
+}
+
+func TestSubmitTransactionBatchCoreNotSynced(t *testing.T) {
+	coreStateGetter := &coreStateGetterMock{}
+	coreStateGetter.On("GetCoreState").Return(corestate.State{Synced: false})
+	handler := SubmitTransactionBatchHandler{
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		CoreStateGetter:   coreStateGetter,
+	}
+
+	_, err := handler.GetResource(
+		httptest.NewRecorder(),
+		newBatchSubmitRequest(t, `{"transactions": ["`+signedTestTx(t, 1)+`"]}`),
+	)
+	assert.Equal(t, "stale_history", err.(problem.P).Type)
+}
+
+func TestSubmitTransactionBatch(t *testing.T) {
+	coreStateGetter := &coreStateGetterMock{}
+	coreStateGetter.On("GetCoreState").Return(corestate.State{Synced: true})
+
+	first, second := signedTestTx(t, 1), signedTestTx(t, 2)
+	firstInfo, err := extractEnvelopeInfo(first, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+	secondInfo, err := extractEnvelopeInfo(second, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+
+	submitter := &batchSubmitterMock{}
+	submitter.On("Submit", firstInfo.hash).Return(txsub.Result{
+		Transaction: history.Transaction{
+			TransactionWithoutLedger: history.TransactionWithoutLedger{
+				TransactionHash: firstInfo.hash,
+				LedgerSequence:  3,
+				TxEnvelope:      first,
+				Successful:      true,
+			},
+		},
+	}).Once()
+	submitter.On("Submit", secondInfo.hash).Return(txsub.Result{
+		Err: &txsub.FailedTransactionError{ResultXDR: "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA="},
+	}).Once()
+
+	handler := SubmitTransactionBatchHandler{
+		Submitter:         submitter,
+		NetworkPassphrase: network.PublicNetworkPassphrase,
+		CoreStateGetter:   coreStateGetter,
+	}
+	resource, err := handler.GetResource(
+		httptest.NewRecorder(),
+		newBatchSubmitRequest(t, `{"transactions": ["`+first+`", "AAAA", "`+second+`"]}`),
+	)
+	require.NoError(t, err)
+	submitter.AssertExpectations(t)
+
+	results := resource.(horizon.TransactionBatchResponse).Results
+	require.Len(t, results, 3)
+
+	assert.Equal(t, 0, results[0].Index)
+	assert.Equal(t, firstInfo.hash, results[0].Hash)
+	assert.Nil(t, results[0].Error)
+	if assert.NotNil(t, results[0].Transaction) {
+		assert.Equal(t, int32(3), results[0].Transaction.Ledger)
+		assert.True(t, results[0].Transaction.Successful)
+	}
+
+	assert.Equal(t, 1, results[1].Index)
+	assert.Empty(t, results[1].Hash)
+	assert.Nil(t, results[1].Transaction)
+	if assert.NotNil(t, results[1].Error) {
+		assert.Equal(t, "transaction_malformed", results[1].Error.Type)
+	}
+
+	assert.Equal(t, 2, results[2].Index)
+	assert.Equal(t, secondInfo.hash, results[2].Hash)
+	assert.Nil(t, results[2].Transaction)
+	if assert.NotNil(t, results[2].Error) {
+		assert.Equal(t, "transaction_failed", results[2].Error.Type)
+		assert.Equal(t, "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA=", results[2].Error.Extras["result_xdr"])
+	}
+}
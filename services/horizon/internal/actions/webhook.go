@@ -0,0 +1,364 @@
+package actions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/services/horizon/internal/context"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+// Webhook is the admin resource of a webhook. The secret is only returned
+// when the webhook is created.
+type Webhook struct {
+	ID            int64     `json:"id"`
+	URL           string    `json:"url"`
+	Secret        string    `json:"secret,omitempty"`
+	Account       string    `json:"account,omitempty"`
+	Asset         string    `json:"asset,omitempty"`
+	OperationType string    `json:"operation_type,omitempty"`
+	EventTypes    []string  `json:"event_types"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is the admin resource of a webhook delivery.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	Ledger         int32           `json:"ledger"`
+	OperationID    string          `json:"operation_id"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	LastStatusCode *int64          `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+func newWebhook(webhook history.Webhook) Webhook {
+	resource := Webhook{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		Account:    webhook.Account.String,
+		Asset:      webhook.Asset.String,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+	if webhook.OperationType.Valid {
+		resource.OperationType = operations.TypeNames[xdr.OperationType(webhook.OperationType.Int64)]
+	}
+	return resource
+}
+
+func newWebhookDelivery(delivery history.WebhookDelivery) WebhookDelivery {
+	resource := WebhookDelivery{
+		ID:          delivery.ID,
+		WebhookID:   delivery.WebhookID,
+		Ledger:      delivery.LedgerSequence,
+		OperationID: strconv.FormatInt(delivery.OperationID, 10),
+		Status:      delivery.Status,
+		Attempts:    delivery.Attempts,
+		LastError:   delivery.LastError.String,
+		CreatedAt:   delivery.CreatedAt,
+		Payload:     delivery.Payload,
+	}
+	if delivery.Status == history.WebhookDeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		resource.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.LastAttemptAt.Valid {
+		resource.LastAttemptAt = &delivery.LastAttemptAt.Time
+	}
+	if delivery.LastStatusCode.Valid {
+		resource.LastStatusCode = &delivery.LastStatusCode.Int64
+	}
+	return resource
+}
+
+func getWebhookID(r *http.Request) (int64, error) {
+	s, err := getStringFromURLParam(r, "id")
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, problem.NotFound
+	}
+	return id, nil
+}
+
+// GetWebhooksHandler is the admin action handler for the /webhooks endpoint,
+// which returns all the registered webhooks.
+type GetWebhooksHandler struct{}
+
+// GetResource returns all the registered webhooks.
+func (handler GetWebhooksHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := historyQ.GetWebhooks(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		resources = append(resources, newWebhook(webhook))
+	}
+	return resources, nil
+}
+
+// GetWebhookHandler is the admin action handler for the /webhooks/{id}
+// endpoint.
+type GetWebhookHandler struct{}
+
+// GetResource returns a webhook.
+func (handler GetWebhookHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return nil, err
+	}
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := historyQ.GetWebhookByID(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	return newWebhook(webhook), nil
+}
+
+// CreateWebhookRequest is the body of the requests registering a webhook. A
+// webhook receives the operations and/or effects, depending on its event
+// types, matching all of its filters, at least one filter is required. A
+// webhook receives operations when no event types are given and a random
+// secret is generated when none is given.
+type CreateWebhookRequest struct {
+	URL           string   `json:"url"`
+	Secret        string   `json:"secret"`
+	Account       string   `json:"account"`
+	Asset         string   `json:"asset"`
+	OperationType string   `json:"operation_type"`
+	EventTypes    []string `json:"event_types"`
+}
+
+func (request CreateWebhookRequest) webhook() (history.Webhook, error) {
+	u, err := url.Parse(request.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return history.Webhook{}, problem.MakeInvalidFieldProblem(
+			"url", errors.New("url must be an absolute http or https URL"),
+		)
+	}
+	if len(request.URL) > 2048 {
+		return history.Webhook{}, problem.MakeInvalidFieldProblem(
+			"url", errors.New("url must be at most 2048 characters long"),
+		)
+	}
+	webhook := history.Webhook{URL: request.URL, Secret: request.Secret}
+
+	if len(request.Secret) > 256 {
+		return history.Webhook{}, problem.MakeInvalidFieldProblem(
+			"secret", errors.New("secret must be at most 256 characters long"),
+		)
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err = rand.Read(secret); err != nil {
+			return history.Webhook{}, errors.Wrap(err, "could not generate secret")
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	if request.Account != "" {
+		if !strkey.IsValidEd25519PublicKey(request.Account) {
+			return history.Webhook{}, problem.MakeInvalidFieldProblem(
+				"account", errors.New("account must be a valid account id"),
+			)
+		}
+		webhook.Account = null.StringFrom(request.Account)
+	}
+
+	if request.Asset != "" {
+		assets, err := xdr.BuildAssets(request.Asset)
+		if err != nil || len(assets) != 1 {
+			return history.Webhook{}, problem.MakeInvalidFieldProblem(
+				"asset", errors.New("asset must be `native` or `CODE:ISSUER`"),
+			)
+		}
+		webhook.Asset = null.StringFrom(assets[0].StringCanonical())
+	}
+
+	if request.OperationType != "" {
+		for operationType, name := range operations.TypeNames {
+			if name == request.OperationType {
+				webhook.OperationType = null.IntFrom(int64(operationType))
+				break
+			}
+		}
+		if !webhook.OperationType.Valid {
+			return history.Webhook{}, problem.MakeInvalidFieldProblem(
+				"operation_type", errors.New("unknown operation type"),
+			)
+		}
+	}
+
+	webhook.EventTypes = []string{history.WebhookEventTypeOperation}
+	if len(request.EventTypes) > 0 {
+		webhook.EventTypes = nil
+		seen := map[string]bool{}
+		for _, eventType := range request.EventTypes {
+			if eventType != history.WebhookEventTypeOperation && eventType != history.WebhookEventTypeEffect {
+				return history.Webhook{}, problem.MakeInvalidFieldProblem(
+					"event_types", errors.New("event types must be `operation` or `effect`"),
+				)
+			}
+			if !seen[eventType] {
+				seen[eventType] = true
+				webhook.EventTypes = append(webhook.EventTypes, eventType)
+			}
+		}
+	}
+
+	if !webhook.Account.Valid && !webhook.Asset.Valid && !webhook.OperationType.Valid {
+		return history.Webhook{}, problem.MakeInvalidFieldProblem(
+			"account", errors.New("at least one of account, asset or operation_type is required"),
+		)
+	}
+	return webhook, nil
+}
+
+// CreateWebhookHandler is the admin action handler registering a webhook
+// with POST /webhooks. The webhook is notified of the operations ingested
+// from the next ledgers on.
+type CreateWebhookHandler struct{}
+
+// GetResource registers a webhook and returns it, including its secret.
+func (handler CreateWebhookHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	var request CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, problem.NewProblemWithInvalidField(problem.BadRequest, "body", err)
+	}
+	webhook, err := request.webhook()
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err = historyQ.InsertWebhook(r.Context(), webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	resource := newWebhook(webhook)
+	resource.Secret = webhook.Secret
+	return resource, nil
+}
+
+// DeleteWebhookHandler is the admin action handler removing a webhook and
+// its deliveries with DELETE /webhooks/{id}.
+type DeleteWebhookHandler struct{}
+
+// GetResource removes a webhook and returns it.
+func (handler DeleteWebhookHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return nil, err
+	}
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := historyQ.GetWebhookByID(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = historyQ.DeleteWebhook(r.Context(), id); err != nil {
+		return nil, err
+	}
+	return newWebhook(webhook), nil
+}
+
+// GetWebhookDeliveriesHandler is the admin action handler for the
+// /webhooks/{id}/deliveries endpoint, which returns the delivery log of a
+// webhook, most recent first. The log can be filtered by `status` and paged
+// with `cursor`, the id of the last delivery of the previous page, and
+// `limit`.
+type GetWebhookDeliveriesHandler struct{}
+
+// GetResource returns the deliveries of a webhook.
+func (handler GetWebhookDeliveriesHandler) GetResource(w HeaderWriter, r *http.Request) (interface{}, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := getString(r, "status")
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case "", history.WebhookDeliveryPending, history.WebhookDeliveryDelivered, history.WebhookDeliveryFailed:
+	default:
+		return nil, problem.MakeInvalidFieldProblem(
+			"status", errors.New("status must be one of pending, delivered or failed"),
+		)
+	}
+
+	var cursor int64
+	s, err := getString(r, ParamCursor)
+	if err != nil {
+		return nil, err
+	}
+	if s != "" {
+		if cursor, err = strconv.ParseInt(s, 10, 64); err != nil || cursor < 0 {
+			return nil, problem.MakeInvalidFieldProblem(ParamCursor, errors.New("cursor must be a delivery id"))
+		}
+	}
+
+	limit, err := getLimit(r, ParamLimit, defaultWebhookDeliveriesLimit, maxWebhookDeliveriesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	historyQ, err := context.HistoryQFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = historyQ.GetWebhookByID(r.Context(), id); err != nil {
+		return nil, err
+	}
+	deliveries, err := historyQ.WebhookDeliveries(r.Context(), id, status, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resources = append(resources, newWebhookDelivery(delivery))
+	}
+	return resources, nil
+}
@@ -0,0 +1,134 @@
+package actions
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+
+	create := func(body string) (interface{}, error) {
+		request := makeRequest(t, map[string]string{}, map[string]string{}, tt.HorizonSession())
+		request.Body = ioutil.NopCloser(bytes.NewBufferString(body))
+		return CreateWebhookHandler{}.GetResource(httptest.NewRecorder(), request)
+	}
+
+	for _, testCase := range []struct {
+		body  string
+		field string
+	}{
+		{`{"url": "localhost:8000", "operation_type": "payment"}`, "url"},
+		{`{"url": "ftp://localhost:8000", "operation_type": "payment"}`, "url"},
+		{`{"url": "http://localhost:8000"}`, "account"},
+		{`{"url": "http://localhost:8000", "account": "GA"}`, "account"},
+		{`{"url": "http://localhost:8000", "asset": "USD"}`, "asset"},
+		{`{"url": "http://localhost:8000", "operation_type": "unknown"}`, "operation_type"},
+		{`{"url": "http://localhost:8000", "asset": "native", "event_types": ["trade"]}`, "event_types"},
+	} {
+		_, err := create(testCase.body)
+		if tt.Assert.IsType(&problem.P{}, err, testCase.body) {
+			tt.Assert.Equal(testCase.field, err.(*problem.P).Extras["invalid_field"], testCase.body)
+		}
+	}
+
+	response, err := create(`{
+		"url": "http://localhost:8000/webhook",
+		"account": "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+		"asset": "USD:GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+		"operation_type": "payment"
+	}`)
+	tt.Assert.NoError(err)
+	created := response.(Webhook)
+	tt.Assert.Len(created.Secret, 64)
+	tt.Assert.Equal("payment", created.OperationType)
+	tt.Assert.Equal([]string{"operation"}, created.EventTypes)
+
+	response, err = create(`{
+		"url": "https://localhost:8000",
+		"secret": "s3cr3t",
+		"asset": "native",
+		"event_types": ["effect", "operation", "effect"]
+	}`)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal("s3cr3t", response.(Webhook).Secret)
+	tt.Assert.Equal("native", response.(Webhook).Asset)
+	tt.Assert.Equal([]string{"effect", "operation"}, response.(Webhook).EventTypes)
+
+	response, err = GetWebhooksHandler{}.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{}, tt.HorizonSession()),
+	)
+	tt.Assert.NoError(err)
+	webhooks := response.([]Webhook)
+	tt.Assert.Len(webhooks, 2)
+	tt.Assert.Empty(webhooks[0].Secret)
+
+	id := strconv.FormatInt(created.ID, 10)
+	response, err = GetWebhookHandler{}.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"id": id}, tt.HorizonSession()),
+	)
+	tt.Assert.NoError(err)
+	webhook := response.(Webhook)
+	tt.Assert.Equal("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", webhook.Account)
+	tt.Assert.Equal("USD:GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", webhook.Asset)
+
+	q := &history.Q{SessionInterface: tt.HorizonSession()}
+	tt.Assert.NoError(q.InsertWebhookDeliveries(tt.Ctx, []history.WebhookDelivery{
+		{WebhookID: created.ID, LedgerSequence: 10, OperationID: 100, Payload: json.RawMessage(`{"id":1}`)},
+		{WebhookID: created.ID, LedgerSequence: 11, OperationID: 101, Payload: json.RawMessage(`{"id":2}`)},
+	}))
+
+	getDeliveries := func(query map[string]string) (interface{}, error) {
+		return GetWebhookDeliveriesHandler{}.GetResource(
+			httptest.NewRecorder(),
+			makeRequest(t, query, map[string]string{"id": id}, tt.HorizonSession()),
+		)
+	}
+	response, err = getDeliveries(map[string]string{"limit": "1"})
+	tt.Assert.NoError(err)
+	deliveries := response.([]WebhookDelivery)
+	tt.Assert.Len(deliveries, 1)
+	tt.Assert.Equal("101", deliveries[0].OperationID)
+	tt.Assert.Equal(history.WebhookDeliveryPending, deliveries[0].Status)
+	tt.Assert.NotNil(deliveries[0].NextAttemptAt)
+
+	response, err = getDeliveries(map[string]string{"cursor": strconv.FormatInt(deliveries[0].ID, 10)})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(response.([]WebhookDelivery), 1)
+	tt.Assert.Equal("100", response.([]WebhookDelivery)[0].OperationID)
+
+	response, err = getDeliveries(map[string]string{"status": history.WebhookDeliveryFailed})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(response.([]WebhookDelivery), 0)
+
+	_, err = getDeliveries(map[string]string{"status": "unknown"})
+	if tt.Assert.IsType(&problem.P{}, err) {
+		tt.Assert.Equal("status", err.(*problem.P).Extras["invalid_field"])
+	}
+
+	response, err = DeleteWebhookHandler{}.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"id": id}, tt.HorizonSession()),
+	)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(created.ID, response.(Webhook).ID)
+
+	_, err = GetWebhookHandler{}.GetResource(
+		httptest.NewRecorder(),
+		makeRequest(t, map[string]string{}, map[string]string{"id": id}, tt.HorizonSession()),
+	)
+	tt.Assert.Equal(sql.ErrNoRows, err)
+}
@@ -22,7 +22,7 @@ func TestAccountActions_InvalidID(t *testing.T) {
 		Header: xdr.LedgerHeader{
 			LedgerSeq: 100,
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	ht.Assert.NoError(err)
 
 	// existing account
@@ -48,7 +48,7 @@ func TestDataActions_Show(t *testing.T) {
 		Header: xdr.LedgerHeader{
 			LedgerSeq: 100,
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	ht.Assert.NoError(err)
 
 	err = q.UpsertAccountData(ht.Ctx, []history.Data{data1, data2})
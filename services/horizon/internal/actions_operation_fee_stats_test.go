@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/operationfeestats"
 )
 
 func TestOperationFeeTestsActions_Show(t *testing.T) {
@@ -255,6 +256,46 @@ func TestOperationFeeTestsActions_ShowMultiOp(t *testing.T) {
 	}
 }
 
+// TestOperationFeeTestsActions_ShowPercentage tests fee charged stats relative to the
+// native amount transferred by each transaction.
+func TestOperationFeeTestsActions_ShowPercentage(t *testing.T) {
+	ht := StartHTTPTest(t, "operation_fee_stats_3")
+	defer ht.Finish()
+
+	ht.App.UpdateFeeStatsState(ht.Ctx)
+	w := ht.Get("/fee_stats")
+	if ht.Assert.Equal(200, w.Code) {
+		var result hProtocol.FeeStats
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		ht.Require.NoError(err)
+		// no transaction transferred a native amount so the base percentage fee is used
+		ht.Assert.Equal(int64(45), result.LastLedgerBasePercentageFee, "base_percentage_fee")
+		ht.Assert.Equal(int64(45), result.FeeChargedPercentage.Min, "fee_charged_percentage_min")
+		ht.Assert.Equal(int64(45), result.FeeChargedPercentage.P50, "fee_charged_percentage_p50")
+		ht.Assert.Equal(int64(45), result.FeeChargedPercentage.Max, "fee_charged_percentage_max")
+	}
+
+	// every transaction is charged 1% of the amount it transferred
+	_, err := ht.HorizonSession().ExecRaw(ht.Ctx, "UPDATE history_transactions SET transferred_amount = fee_charged * 100")
+	ht.Require.NoError(err)
+	operationfeestats.ResetState()
+	ht.App.UpdateFeeStatsState(ht.Ctx)
+
+	w = ht.Get("/fee_stats")
+	if ht.Assert.Equal(200, w.Code) {
+		var result hProtocol.FeeStats
+		err := json.Unmarshal(w.Body.Bytes(), &result)
+		ht.Require.NoError(err)
+		ht.Assert.Equal(int64(45), result.LastLedgerBasePercentageFee, "base_percentage_fee")
+		ht.Assert.Equal(int64(100), result.FeeChargedPercentage.Min, "fee_charged_percentage_min")
+		ht.Assert.Equal(int64(100), result.FeeChargedPercentage.Mode, "fee_charged_percentage_mode")
+		ht.Assert.Equal(int64(100), result.FeeChargedPercentage.P10, "fee_charged_percentage_p10")
+		ht.Assert.Equal(int64(100), result.FeeChargedPercentage.P50, "fee_charged_percentage_p50")
+		ht.Assert.Equal(int64(100), result.FeeChargedPercentage.P99, "fee_charged_percentage_p99")
+		ht.Assert.Equal(int64(100), result.FeeChargedPercentage.Max, "fee_charged_percentage_max")
+	}
+}
+
 func TestEmptyFeeStats(t *testing.T) {
 	ht := StartHTTPTestWithoutScenario(t)
 	defer ht.Finish()
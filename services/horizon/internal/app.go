@@ -22,8 +22,10 @@ import (
 	"github.com/stellar/go/services/horizon/internal/logmetrics"
 	"github.com/stellar/go/services/horizon/internal/operationfeestats"
 	"github.com/stellar/go/services/horizon/internal/paths"
+	"github.com/stellar/go/services/horizon/internal/ratelimit"
 	"github.com/stellar/go/services/horizon/internal/reap"
 	"github.com/stellar/go/services/horizon/internal/txsub"
+	"github.com/stellar/go/services/horizon/internal/webhooks"
 	"github.com/stellar/go/support/app"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/errors"
@@ -47,7 +49,12 @@ type App struct {
 	paths           paths.Finder
 	ingester        ingest.System
 	reaper          *reap.System
+	webhooks        *webhooks.System
 	ticks           *time.Ticker
+	rateLimiter     *ratelimit.Limiter
+	// rateLimitSource is the source of the accounts reloaded by rateLimiter,
+	// it is nil when no account can authenticate with API keys.
+	rateLimitSource ratelimit.AccountSource
 	ledgerState     *ledger.State
 
 	// metrics
@@ -83,6 +90,10 @@ func NewApp(config Config) (*App, error) {
 // the shutdown signals.
 func (a *App) Serve() error {
 
+	if err := CheckKinesisTreasuryAccounts(a.ctx, a.primaryQ(), a.config.KinesisTreasuryAccounts, a.config.Ingest); err != nil {
+		return err
+	}
+
 	log.Infof("Starting horizon on :%d (ingest: %v)", a.config.Port, a.config.Ingest)
 
 	if a.config.AdminPort != 0 {
@@ -91,6 +102,9 @@ func (a *App) Serve() error {
 
 	go a.run()
 	go a.orderBookStream.Run(a.ctx)
+	if a.rateLimitSource != nil {
+		go a.rateLimiter.Run(a.ctx, a.rateLimitSource, rateLimitAccountsReloadInterval)
+	}
 
 	// WaitGroup for all go routines. Makes sure that DB is closed when
 	// all services gracefully shutdown.
@@ -104,6 +118,22 @@ func (a *App) Serve() error {
 		}()
 	}
 
+	if a.config.Ingest {
+		wg.Add(1)
+		go func() {
+			a.backfillKinesisCoinInCirculation()
+			wg.Done()
+		}()
+	}
+
+	if a.submitter.Store != nil {
+		wg.Add(1)
+		go func() {
+			a.submitter.Recover(a.ctx)
+			wg.Done()
+		}()
+	}
+
 	if a.reaper != nil {
 		wg.Add(1)
 		go func() {
@@ -112,6 +142,14 @@ func (a *App) Serve() error {
 		}()
 	}
 
+	if a.webhooks != nil {
+		wg.Add(1)
+		go func() {
+			a.webhooks.Run()
+			wg.Done()
+		}()
+	}
+
 	// configure shutdown signal handler
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -179,6 +217,9 @@ func (a *App) waitForDone() {
 	if a.reaper != nil {
 		a.reaper.Shutdown()
 	}
+	if a.webhooks != nil {
+		a.webhooks.Shutdown()
+	}
 	a.ticks.Stop()
 }
 
@@ -195,6 +236,15 @@ func (a *App) HistoryQ() *history.Q {
 	return a.historyQ
 }
 
+// primaryQ returns a helper object for performing sql queries against the
+// primary history portion of horizon's database, which can be written to.
+func (a *App) primaryQ() *history.Q {
+	if a.primaryHistoryQ != nil {
+		return a.primaryHistoryQ
+	}
+	return a.historyQ
+}
+
 // HorizonSession returns a new session that loads data from the horizon
 // database.
 func (a *App) HorizonSession() db.SessionInterface {
@@ -299,6 +349,7 @@ func (a *App) UpdateFeeStatsState(ctx context.Context) {
 	}
 
 	next.LastBaseFee = int64(latest.BaseFee)
+	next.LastBasePercentageFee = int64(latest.BasePercentageFee)
 	next.LastLedger = uint32(latest.Sequence)
 
 	err = a.HistoryQ().FeeStats(ctx, latest.Sequence, &feeStats)
@@ -384,6 +435,40 @@ func (a *App) UpdateFeeStatsState(ctx context.Context) {
 		next.FeeChargedP99 = feeStats.FeeChargedP99.Int64
 	}
 
+	// if no transaction transferred a native amount in last 5 ledgers,
+	// return latest ledger's base percentage fee for all
+	if !feeStats.FeeChargedPercentageMode.Valid && !feeStats.FeeChargedPercentageMin.Valid {
+		next.FeeChargedPercentageMax = next.LastBasePercentageFee
+		next.FeeChargedPercentageMin = next.LastBasePercentageFee
+		next.FeeChargedPercentageMode = next.LastBasePercentageFee
+		next.FeeChargedPercentageP10 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP20 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP30 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP40 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP50 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP60 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP70 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP80 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP90 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP95 = next.LastBasePercentageFee
+		next.FeeChargedPercentageP99 = next.LastBasePercentageFee
+	} else {
+		next.FeeChargedPercentageMax = feeStats.FeeChargedPercentageMax.Int64
+		next.FeeChargedPercentageMin = feeStats.FeeChargedPercentageMin.Int64
+		next.FeeChargedPercentageMode = feeStats.FeeChargedPercentageMode.Int64
+		next.FeeChargedPercentageP10 = feeStats.FeeChargedPercentageP10.Int64
+		next.FeeChargedPercentageP20 = feeStats.FeeChargedPercentageP20.Int64
+		next.FeeChargedPercentageP30 = feeStats.FeeChargedPercentageP30.Int64
+		next.FeeChargedPercentageP40 = feeStats.FeeChargedPercentageP40.Int64
+		next.FeeChargedPercentageP50 = feeStats.FeeChargedPercentageP50.Int64
+		next.FeeChargedPercentageP60 = feeStats.FeeChargedPercentageP60.Int64
+		next.FeeChargedPercentageP70 = feeStats.FeeChargedPercentageP70.Int64
+		next.FeeChargedPercentageP80 = feeStats.FeeChargedPercentageP80.Int64
+		next.FeeChargedPercentageP90 = feeStats.FeeChargedPercentageP90.Int64
+		next.FeeChargedPercentageP95 = feeStats.FeeChargedPercentageP95.Int64
+		next.FeeChargedPercentageP99 = feeStats.FeeChargedPercentageP99.Int64
+	}
+
 	operationfeestats.SetState(next)
 }
 
@@ -485,14 +570,21 @@ func (a *App) init() error {
 	if a.config.Ingest {
 		// ingester
 		initIngester(a)
+		// webhooks, delivered by the ingesting instances which have access
+		// to the primary database
+		initWebhooks(a)
 	}
 	initPathFinder(a)
 
 	// txsub
 	initSubmissionSystem(a)
 
-	// reaper
-	a.reaper = reap.New(a.config.HistoryRetentionCount, a.HorizonSession(), a.ledgerState)
+	// reaper, webhook deliveries are only queued by the ingesting instances
+	var webhookDeliveryRetention time.Duration
+	if a.config.Ingest {
+		webhookDeliveryRetention = a.config.WebhookDeliveryRetention
+	}
+	a.reaper = reap.New(a.config.HistoryRetentionCount, webhookDeliveryRetention, a.HorizonSession(), a.ledgerState)
 
 	// go metrics
 	initGoMetrics(a)
@@ -509,10 +601,15 @@ func (a *App) init() error {
 	// txsub.metrics
 	initTxSubMetrics(a)
 
+	// rate limiting
+	if err := initRateLimiter(a); err != nil {
+		return errors.Wrap(err, "could not initialize rate limiting")
+	}
+
 	routerConfig := httpx.RouterConfig{
 		DBSession:               a.historyQ.SessionInterface,
 		TxSubmitter:             a.submitter,
-		RateQuota:               a.config.RateQuota,
+		RateLimiter:             a.rateLimiter,
 		BehindCloudflare:        a.config.BehindCloudflare,
 		BehindAWSLoadBalancer:   a.config.BehindAWSLoadBalancer,
 		SSEUpdateFrequency:      a.config.SSEUpdateFrequency,
@@ -526,6 +623,7 @@ func (a *App) init() error {
 		CoreGetter:              a,
 		HorizonVersion:          a.horizonVersion,
 		FriendbotURL:            a.config.FriendbotURL,
+		KinesisTreasuryAccounts: a.config.KinesisTreasuryAccounts,
 		HealthCheck: healthCheck{
 			session: a.historyQ.SessionInterface,
 			ctx:     a.ctx,
@@ -581,3 +679,41 @@ func (a *App) run() {
 		}
 	}
 }
+
+// RebackfillKinesisCoinInCirculation recomputes the coin in circulation from
+// the ingested history with the configured treasury accounts, and returns the
+// number of ledgers with mints or redemptions.
+func (a *App) RebackfillKinesisCoinInCirculation(ctx context.Context) (int64, error) {
+	q := &history.Q{SessionInterface: a.primaryQ().Clone()}
+	if err := q.ResetKinesisCoinInCirculation(ctx, a.config.KinesisTreasuryAccounts); err != nil {
+		return 0, err
+	}
+	return q.BackfillKinesisCoinInCirculation(ctx, a.config.KinesisTreasuryAccounts)
+}
+
+// backfillKinesisCoinInCirculation fills the coin in circulation tables with
+// the ledgers ingested before they were created, if needed. It runs along
+// with ingestion, whose rebuilds of the running totals are serialized with it.
+func (a *App) backfillKinesisCoinInCirculation() {
+	q := &history.Q{SessionInterface: a.HistoryQ().Clone()}
+	pending, err := q.KinesisCoinInCirculationBackfillPending(a.ctx)
+	if err != nil {
+		log.Errorf("Error checking the coin in circulation backfill: %v", err)
+		return
+	}
+	if !pending {
+		return
+	}
+
+	log.Info("Backfilling coin in circulation from ingested history")
+	startTime := time.Now()
+	backfilled, err := q.BackfillKinesisCoinInCirculation(a.ctx, a.config.KinesisTreasuryAccounts)
+	if err != nil {
+		log.Errorf("Error backfilling coin in circulation: %v", err)
+		return
+	}
+	log.WithFields(log.F{
+		"ledgers":  backfilled,
+		"duration": time.Since(startTime).Seconds(),
+	}).Info("Finished backfilling coin in circulation")
+}
@@ -4,7 +4,9 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/stellar/go/historyarchive"
 	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stellar/throttled"
@@ -16,8 +18,11 @@ type Config struct {
 	DatabaseURL        string
 	RoDatabaseURL      string
 	HistoryArchiveURLs []string
-	Port               uint
-	AdminPort          uint
+	// HistoryArchiveCache configures the on-disk cache of the files read from
+	// the history archive, it is disabled when its Path is empty.
+	HistoryArchiveCache historyarchive.CacheOptions
+	Port                uint
+	AdminPort           uint
 
 	EnableCaptiveCoreIngestion  bool
 	UsingDefaultPubnetConfig    bool
@@ -55,6 +60,21 @@ type Config struct {
 	// MaxPathFindingRequests is the maximum number of path finding requests horizon will allow
 	// in a 1-second period. A value of 0 disables the limit.
 	MaxPathFindingRequests uint
+	// StreamRateQuota and PathFindingRateQuota are the rate quotas of the
+	// anonymous streaming and path finding requests. These requests are
+	// counted against RateQuota when they are nil.
+	StreamRateQuota      *throttled.RateQuota
+	PathFindingRateQuota *throttled.RateQuota
+	// RateLimitAccountsPath is the path of a TOML file listing the accounts
+	// which can authenticate with API keys and their rate limits.
+	RateLimitAccountsPath string
+	// RateLimitAccountsFromDB loads the accounts which can authenticate with
+	// API keys from the rate_limit_accounts and rate_limit_api_keys tables.
+	RateLimitAccountsFromDB bool
+	// RateLimitRedisURL is the URL of a Redis compatible server storing the
+	// state of the rate limits, so that they are shared by all the Horizon
+	// instances using it. The state is kept in memory when empty.
+	RateLimitRedisURL string
 
 	NetworkPassphrase string
 	SentryDSN         string
@@ -89,6 +109,23 @@ type Config struct {
 	// IngestEnableExtendedLogLedgerStats enables extended ledger stats in
 	// logging.
 	IngestEnableExtendedLogLedgerStats bool
+	// WebhookMaxAttempts is the number of attempts after which a webhook
+	// delivery is marked as failed.
+	WebhookMaxAttempts uint
+	// WebhookTimeout is the timeout of a webhook delivery request.
+	WebhookTimeout time.Duration
+	// WebhookDeliveryRetention is the duration the delivered and failed
+	// webhook deliveries are kept for, 0 keeps them all.
+	WebhookDeliveryRetention time.Duration
+	// TxSubPersistentQueue enables the persistent transaction submission
+	// queue, which records the submissions to stellar-core in the Horizon DB.
+	TxSubPersistentQueue bool
+	// TxSubPendingExpiry is the duration after which the pending submissions
+	// of the persistent queue which were not ingested expire.
+	TxSubPendingExpiry time.Duration
+	// TxSubRetention is the duration the finished submissions of the
+	// persistent queue are kept for.
+	TxSubRetention time.Duration
 	// ApplyMigrations will apply pending migrations to the horizon database
 	// before starting the horizon service
 	ApplyMigrations bool
@@ -103,4 +140,11 @@ type Config struct {
 	BehindAWSLoadBalancer bool
 	// RoundingSlippageFilter excludes trades from /trade_aggregations with rounding slippage >x bps
 	RoundingSlippageFilter int
+	// KinesisTreasuryConfigPath is the path of a TOML file listing the treasury
+	// accounts used to compute the coin in circulation.
+	KinesisTreasuryConfigPath string
+	// KinesisTreasuryAccounts are the treasury accounts used to compute the coin
+	// in circulation. Accounts which are not configured with flags or in
+	// KinesisTreasuryConfigPath are derived from the network passphrase.
+	KinesisTreasuryAccounts history.KinesisTreasuryAccounts
 }
@@ -0,0 +1,289 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/db"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+const accountBalancesTable = "history_account_balances"
+
+// AccountBalance is a row of data from the `history_account_balances` table.
+// Balances are in stroops, the asset code and issuer of native balances are
+// empty.
+type AccountBalance struct {
+	LedgerSequence uint32    `db:"ledger_sequence"`
+	ClosedAt       time.Time `db:"closed_at"`
+	AccountID      string    `db:"account_id"`
+	AssetType      string    `db:"asset_type"`
+	AssetCode      string    `db:"asset_code"`
+	AssetIssuer    string    `db:"asset_issuer"`
+	Balance        int64     `db:"balance"`
+	Removed        bool      `db:"removed"`
+}
+
+// QAccountBalances defines account balance history related queries used
+// during ingestion.
+type QAccountBalances interface {
+	DeleteAccountBalancesLedger(ctx context.Context, sequence uint32) (int64, error)
+	InsertAccountBalances(ctx context.Context, balances []AccountBalance, batchSize int) error
+	SnapshotAccountBalances(ctx context.Context, sequence uint32, closedAt time.Time) error
+}
+
+// DeleteAccountBalancesLedger removes the balances which changed in the given
+// ledger. It returns the number of rows removed.
+func (q *Q) DeleteAccountBalancesLedger(ctx context.Context, sequence uint32) (int64, error) {
+	result, err := q.Exec(ctx, sq.Delete(accountBalancesTable).
+		Where(sq.Eq{"history_ledger_id": toid.New(int32(sequence), 0, 0).ToInt64(), "snapshot": false}))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not delete account balances")
+	}
+	return result.RowsAffected()
+}
+
+// InsertAccountBalances inserts the balances which changed in a ledger.
+func (q *Q) InsertAccountBalances(ctx context.Context, balances []AccountBalance, batchSize int) error {
+	builder := &db.BatchInsertBuilder{
+		Table:        q.GetTable(accountBalancesTable),
+		MaxBatchSize: batchSize,
+	}
+
+	for _, balance := range balances {
+		err := builder.Row(ctx, map[string]interface{}{
+			"history_ledger_id": toid.New(int32(balance.LedgerSequence), 0, 0).ToInt64(),
+			"ledger_sequence":   balance.LedgerSequence,
+			"closed_at":         balance.ClosedAt.UTC(),
+			"account_id":        balance.AccountID,
+			"asset_type":        balance.AssetType,
+			"asset_code":        balance.AssetCode,
+			"asset_issuer":      balance.AssetIssuer,
+			"balance":           balance.Balance,
+			"removed":           balance.Removed,
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not insert account balance row")
+		}
+	}
+
+	if err := builder.Exec(ctx); err != nil {
+		return errors.Wrap(err, "could not exec account balances insert builder")
+	}
+	return nil
+}
+
+// SnapshotAccountBalances records the balances of all the accounts and trust
+// lines of the ingested state as of the given ledger. The balances are known
+// from the ledger on.
+func (q *Q) SnapshotAccountBalances(ctx context.Context, sequence uint32, closedAt time.Time) error {
+	id := toid.New(int32(sequence), 0, 0).ToInt64()
+	_, err := q.Exec(ctx, sq.Delete(accountBalancesTable).Where(sq.Eq{"history_ledger_id": id, "snapshot": true}))
+	if err != nil {
+		return errors.Wrap(err, "could not delete account balances snapshot")
+	}
+
+	_, err = q.ExecRaw(ctx, `
+		INSERT INTO history_account_balances (history_ledger_id, ledger_sequence, closed_at,
+			account_id, asset_type, asset_code, asset_issuer, balance, snapshot)
+		SELECT $1, $2, $3, account_id, $4, '', '', balance, true FROM accounts
+		UNION ALL
+		SELECT $1, $2, $3, account_id, CASE asset_type WHEN $5 THEN $6 ELSE $7 END,
+			asset_code, asset_issuer, balance, true
+		FROM trust_lines WHERE asset_type IN ($5, $8)`,
+		id, sequence, closedAt.UTC(),
+		xdr.AssetTypeToString[xdr.AssetTypeAssetTypeNative],
+		int32(xdr.AssetTypeAssetTypeCreditAlphanum4),
+		xdr.AssetTypeToString[xdr.AssetTypeAssetTypeCreditAlphanum4],
+		xdr.AssetTypeToString[xdr.AssetTypeAssetTypeCreditAlphanum12],
+		int32(xdr.AssetTypeAssetTypeCreditAlphanum12),
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not insert account balances snapshot")
+	}
+	return q.UpdateAccountBalancesSnapshotLedger(ctx, sequence)
+}
+
+// RebaseAccountBalances moves the balances recorded before the given ledger
+// to a snapshot at the ledger, so that the balances which did not change
+// since are still known when the history before the ledger is removed.
+func (q *Q) RebaseAccountBalances(ctx context.Context, sequence uint32) error {
+	id := toid.New(int32(sequence), 0, 0).ToInt64()
+	_, err := q.ExecRaw(ctx, `
+		INSERT INTO history_account_balances (history_ledger_id, ledger_sequence, closed_at,
+			account_id, asset_type, asset_code, asset_issuer, balance, snapshot)
+		SELECT $1, ledger_sequence, closed_at, account_id, asset_type, asset_code, asset_issuer, balance, true
+		FROM (
+			SELECT DISTINCT ON (account_id, asset_type, asset_code, asset_issuer) *
+			FROM history_account_balances
+			WHERE history_ledger_id < $1
+			ORDER BY account_id, asset_type, asset_code, asset_issuer, history_ledger_id DESC, snapshot
+		) latest
+		WHERE NOT removed
+		ON CONFLICT DO NOTHING`,
+		id,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not rebase account balances")
+	}
+
+	_, err = q.Exec(ctx, sq.Delete(accountBalancesTable).
+		Where(sq.Eq{"snapshot": true}).
+		Where(sq.Lt{"history_ledger_id": id}))
+	if err != nil {
+		return errors.Wrap(err, "could not delete account balances snapshot")
+	}
+
+	snapshotLedger, err := q.GetAccountBalancesSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	if snapshotLedger == 0 || snapshotLedger >= sequence {
+		return nil
+	}
+	return q.UpdateAccountBalancesSnapshotLedger(ctx, sequence)
+}
+
+// AccountBalanceResolution is the interval the balance history of an account
+// is aggregated by.
+type AccountBalanceResolution string
+
+const (
+	AccountBalanceLedgerResolution AccountBalanceResolution = "ledger"
+	AccountBalanceHourResolution   AccountBalanceResolution = "hour"
+	AccountBalanceDayResolution    AccountBalanceResolution = "day"
+	AccountBalanceWeekResolution   AccountBalanceResolution = "week"
+	AccountBalanceMonthResolution  AccountBalanceResolution = "month"
+)
+
+// AccountBalanceResolutions lists the supported resolutions.
+var AccountBalanceResolutions = []AccountBalanceResolution{
+	AccountBalanceLedgerResolution,
+	AccountBalanceHourResolution,
+	AccountBalanceDayResolution,
+	AccountBalanceWeekResolution,
+	AccountBalanceMonthResolution,
+}
+
+// AccountBalanceRecord is the balance of an account at the end of an interval
+// in which it changed. LedgerSequence is the last ledger of the interval in
+// which the balance changed.
+type AccountBalanceRecord struct {
+	Timestamp      time.Time `db:"timestamp"`
+	LedgerSequence uint32    `db:"ledger_sequence"`
+	Balance        int64     `db:"balance"`
+	Removed        bool      `db:"removed"`
+}
+
+// PagingToken returns a cursor for this record. Records are paged by their
+// last ledger so `cursor=now` and streaming behave like other history
+// endpoints.
+func (r AccountBalanceRecord) PagingToken() string {
+	return toid.New(int32(r.LedgerSequence), 0, 0).String()
+}
+
+// AccountBalanceHistoryQuery filters the records returned by
+// AccountBalanceHistory. Records are included when the start of their
+// interval is within [From, To), zero values are unbounded.
+type AccountBalanceHistoryQuery struct {
+	AccountID   string
+	AssetType   string
+	AssetCode   string
+	AssetIssuer string
+	Resolution  AccountBalanceResolution
+	From        time.Time
+	To          time.Time
+	Page        db2.PageQuery
+}
+
+// AccountBalanceHistory returns a page of the balances of an account in an
+// asset aggregated by criteria.Resolution. Intervals in which the balance did
+// not change are omitted.
+func (q *Q) AccountBalanceHistory(ctx context.Context, criteria AccountBalanceHistoryQuery) ([]AccountBalanceRecord, error) {
+	var inner sq.SelectBuilder
+	switch criteria.Resolution {
+	case AccountBalanceLedgerResolution:
+		inner = sq.Select(
+			"closed_at AS timestamp",
+			"ledger_sequence",
+			"balance",
+			"removed",
+			"history_ledger_id AS id",
+		).From(accountBalancesTable).Where(sq.Eq{"snapshot": false})
+	case AccountBalanceHourResolution, AccountBalanceDayResolution,
+		AccountBalanceWeekResolution, AccountBalanceMonthResolution:
+		bucket := fmt.Sprintf("date_trunc('%s', closed_at)", criteria.Resolution)
+		inner = sq.Select().
+			Column(bucket + " AS timestamp").
+			Column("MAX(ledger_sequence) AS ledger_sequence").
+			Column("(ARRAY_AGG(balance ORDER BY history_ledger_id DESC))[1] AS balance").
+			Column("(ARRAY_AGG(removed ORDER BY history_ledger_id DESC))[1] AS removed").
+			Column("MAX(history_ledger_id) AS id").
+			From(accountBalancesTable).
+			Where(sq.Eq{"snapshot": false}).
+			GroupBy(bucket)
+	default:
+		return nil, errors.Errorf("invalid account balance resolution: %s", criteria.Resolution)
+	}
+	inner = inner.Where(sq.Eq{
+		"account_id":   criteria.AccountID,
+		"asset_type":   criteria.AssetType,
+		"asset_code":   criteria.AssetCode,
+		"asset_issuer": criteria.AssetIssuer,
+	})
+
+	sql := sq.Select(
+		"r.timestamp",
+		"r.ledger_sequence",
+		"r.balance",
+		"r.removed",
+	).FromSelect(inner, "r")
+	if !criteria.From.IsZero() {
+		sql = sql.Where("r.timestamp >= ?", criteria.From.UTC())
+	}
+	if !criteria.To.IsZero() {
+		sql = sql.Where("r.timestamp < ?", criteria.To.UTC())
+	}
+
+	sql, err := criteria.Page.ApplyTo(sql, "r.id")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not apply query to page")
+	}
+
+	var results []AccountBalanceRecord
+	if err := q.Select(ctx, &results, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+	return results, nil
+}
+
+// AccountBalancesAtLedger returns the last recorded balance of every asset of
+// an account as of the given ledger, including the balances which were
+// removed. The balances are only complete from the ledger returned by
+// GetAccountBalancesSnapshotLedger on.
+func (q *Q) AccountBalancesAtLedger(ctx context.Context, accountID string, sequence uint32) ([]AccountBalance, error) {
+	sql := sq.Select(
+		"DISTINCT ON (asset_type, asset_code, asset_issuer) ledger_sequence",
+		"closed_at",
+		"account_id",
+		"asset_type",
+		"asset_code",
+		"asset_issuer",
+		"balance",
+		"removed",
+	).From(accountBalancesTable).
+		Where(sq.Eq{"account_id": accountID}).
+		Where(sq.Lt{"history_ledger_id": toid.New(int32(sequence)+1, 0, 0).ToInt64()}).
+		OrderBy("asset_type", "asset_code", "asset_issuer", "history_ledger_id DESC", "snapshot")
+
+	var results []AccountBalance
+	if err := q.Select(ctx, &results, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+	return results, nil
+}
@@ -0,0 +1,194 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+)
+
+func TestAccountBalanceHistory(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	account := "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	issuer := "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	june := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	native := func(sequence uint32, closedAt time.Time, balance int64) AccountBalance {
+		return AccountBalance{
+			LedgerSequence: sequence,
+			ClosedAt:       closedAt,
+			AccountID:      account,
+			AssetType:      "native",
+			Balance:        balance,
+		}
+	}
+	usd := AccountBalance{
+		LedgerSequence: 20,
+		ClosedAt:       june.Add(2 * time.Hour),
+		AccountID:      account,
+		AssetType:      "credit_alphanum4",
+		AssetCode:      "USD",
+		AssetIssuer:    issuer,
+		Balance:        300,
+	}
+
+	tt.Assert.NoError(q.InsertAccountBalances(tt.Ctx, []AccountBalance{
+		native(10, june.Add(time.Hour), 100),
+		native(11, june.Add(time.Hour+5*time.Second), 90),
+	}, 100))
+	tt.Assert.NoError(q.InsertAccountBalances(tt.Ctx, []AccountBalance{
+		native(20, june.Add(2*time.Hour), 80),
+		usd,
+	}, 100))
+	usdRemoved := usd
+	usdRemoved.LedgerSequence = 30
+	usdRemoved.ClosedAt = june.AddDate(0, 0, 1)
+	usdRemoved.Balance = 0
+	usdRemoved.Removed = true
+	tt.Assert.NoError(q.InsertAccountBalances(tt.Ctx, []AccountBalance{usdRemoved}, 100))
+
+	page := db2.PageQuery{Order: db2.OrderAscending, Limit: db2.DefaultPageSize}
+	criteria := AccountBalanceHistoryQuery{
+		AccountID:  account,
+		AssetType:  "native",
+		Resolution: AccountBalanceHourResolution,
+		Page:       page,
+	}
+	records, err := q.AccountBalanceHistory(tt.Ctx, criteria)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 2) {
+		tt.Assert.True(june.Add(time.Hour).Equal(records[0].Timestamp))
+		tt.Assert.Equal(uint32(11), records[0].LedgerSequence)
+		tt.Assert.Equal(int64(90), records[0].Balance)
+		tt.Assert.Equal(uint32(20), records[1].LedgerSequence)
+		tt.Assert.Equal(int64(80), records[1].Balance)
+	}
+
+	criteria.Resolution = AccountBalanceLedgerResolution
+	criteria.Page.Cursor = toid.New(10, 0, 0).String()
+	criteria.To = june.Add(2 * time.Hour)
+	records, err = q.AccountBalanceHistory(tt.Ctx, criteria)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		tt.Assert.Equal(uint32(11), records[0].LedgerSequence)
+	}
+
+	criteria.Resolution = "minute"
+	_, err = q.AccountBalanceHistory(tt.Ctx, criteria)
+	tt.Assert.EqualError(err, "invalid account balance resolution: minute")
+
+	balances, err := q.AccountBalancesAtLedger(tt.Ctx, account, 25)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 2) {
+		tt.Assert.Equal("credit_alphanum4", balances[0].AssetType)
+		tt.Assert.Equal(int64(300), balances[0].Balance)
+		tt.Assert.Equal("native", balances[1].AssetType)
+		tt.Assert.Equal(int64(80), balances[1].Balance)
+	}
+
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, account, 30)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 2) {
+		tt.Assert.True(balances[0].Removed)
+	}
+
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, account, 9)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(balances)
+
+	// ledgers are cleared with the rest of the history
+	deleted, err := q.DeleteAccountBalancesLedger(tt.Ctx, 20)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(2), deleted)
+	tt.Assert.NoError(q.DeleteRangeAll(tt.Ctx, toid.New(10, 0, 0).ToInt64(), toid.New(11, 0, 0).ToInt64()))
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, account, 25)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 1) {
+		tt.Assert.Equal(uint32(11), balances[0].LedgerSequence)
+	}
+}
+
+func TestAccountBalancesSnapshot(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	tt.Assert.NoError(q.UpsertAccounts(tt.Ctx, []AccountEntry{account1}))
+	tt.Assert.NoError(q.UpsertTrustLines(tt.Ctx, []TrustLine{usdTrustLine}))
+
+	snapshotLedger, err := q.GetAccountBalancesSnapshotLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), snapshotLedger)
+
+	closedAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	tt.Assert.NoError(q.SnapshotAccountBalances(tt.Ctx, 10, closedAt))
+	// recording the state again at the same ledger replaces the snapshot
+	tt.Assert.NoError(q.SnapshotAccountBalances(tt.Ctx, 10, closedAt))
+	snapshotLedger, err = q.GetAccountBalancesSnapshotLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(10), snapshotLedger)
+
+	balances, err := q.AccountBalancesAtLedger(tt.Ctx, account1.AccountID, 12)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 1) {
+		tt.Assert.Equal("native", balances[0].AssetType)
+		tt.Assert.Equal(int64(20000), balances[0].Balance)
+		tt.Assert.Equal(uint32(10), balances[0].LedgerSequence)
+	}
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, usdTrustLine.AccountID, 12)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 1) {
+		tt.Assert.Equal("credit_alphanum4", balances[0].AssetType)
+		tt.Assert.Equal("USD", balances[0].AssetCode)
+		tt.Assert.Equal(int64(10000), balances[0].Balance)
+	}
+
+	// the snapshot is not a change of the balance
+	records, err := q.AccountBalanceHistory(tt.Ctx, AccountBalanceHistoryQuery{
+		AccountID:  account1.AccountID,
+		AssetType:  "native",
+		Resolution: AccountBalanceLedgerResolution,
+		Page:       db2.PageQuery{Order: "asc", Limit: 10},
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(records, 0)
+
+	tt.Assert.NoError(q.InsertAccountBalances(tt.Ctx, []AccountBalance{{
+		LedgerSequence: 20,
+		ClosedAt:       closedAt.Add(time.Minute),
+		AccountID:      account1.AccountID,
+		AssetType:      "native",
+		Balance:        15000,
+	}}, 100))
+
+	// the history before ledger 30 is removed, the balances are kept in a
+	// snapshot at ledger 30
+	tt.Assert.NoError(q.RebaseAccountBalances(tt.Ctx, 30))
+	tt.Assert.NoError(q.DeleteRangeAll(tt.Ctx, 0, toid.New(30, 0, 0).ToInt64()))
+	_, err = q.DeleteAccountBalancesLedger(tt.Ctx, 30)
+	tt.Assert.NoError(err)
+	snapshotLedger, err = q.GetAccountBalancesSnapshotLedger(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(30), snapshotLedger)
+
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, account1.AccountID, 30)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 1) {
+		tt.Assert.Equal(int64(15000), balances[0].Balance)
+		tt.Assert.Equal(uint32(20), balances[0].LedgerSequence)
+	}
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, usdTrustLine.AccountID, 30)
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(balances, 1) {
+		tt.Assert.Equal(int64(10000), balances[0].Balance)
+	}
+	balances, err = q.AccountBalancesAtLedger(tt.Ctx, account1.AccountID, 29)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(balances, 0)
+}
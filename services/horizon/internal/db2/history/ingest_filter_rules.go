@@ -0,0 +1,76 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/support/errors"
+)
+
+// Names of the ingestion filters stored in the ingest_filter_rules table.
+const (
+	FilterAccountName = "account"
+	FilterAssetName   = "asset"
+)
+
+// FilterRule is a row of data from the `ingest_filter_rules` table.
+type FilterRule struct {
+	Name         string          `db:"name"`
+	Enabled      bool            `db:"enabled"`
+	Rules        json.RawMessage `db:"rules"`
+	Version      int64           `db:"version"`
+	LastModified time.Time       `db:"last_modified"`
+}
+
+// QFilterRules defines ingestion filter rules related queries.
+type QFilterRules interface {
+	GetFilterRules(ctx context.Context) ([]FilterRule, error)
+	GetFilterRule(ctx context.Context, name string) (FilterRule, error)
+	UpsertFilterRule(ctx context.Context, name string, enabled bool, rules json.RawMessage) (FilterRule, error)
+}
+
+var selectFilterRules = sq.Select(
+	"name",
+	"enabled",
+	"rules",
+	"version",
+	"last_modified",
+).From("ingest_filter_rules")
+
+// GetFilterRules returns the rules of all the ingestion filters.
+func (q *Q) GetFilterRules(ctx context.Context) ([]FilterRule, error) {
+	var rules []FilterRule
+	if err := q.Select(ctx, &rules, selectFilterRules.OrderBy("name")); err != nil {
+		return nil, errors.Wrap(err, "could not select filter rules")
+	}
+	return rules, nil
+}
+
+// GetFilterRule returns the rules of the ingestion filter with the given name.
+// sql.ErrNoRows is returned when the rules of the filter were never set.
+func (q *Q) GetFilterRule(ctx context.Context, name string) (FilterRule, error) {
+	var rule FilterRule
+	err := q.Get(ctx, &rule, selectFilterRules.Where("name = ?", name))
+	return rule, err
+}
+
+// UpsertFilterRule sets the rules of the ingestion filter with the given name
+// and bumps its version.
+func (q *Q) UpsertFilterRule(ctx context.Context, name string, enabled bool, rules json.RawMessage) (FilterRule, error) {
+	var rule FilterRule
+	sql := sq.Insert("ingest_filter_rules").
+		Columns("name", "enabled", "rules", "version", "last_modified").
+		Values(name, enabled, string(rules), sq.Expr("nextval('ingest_filter_rules_version_seq')"), time.Now().UTC()).
+		Suffix(`ON CONFLICT (name) DO UPDATE SET
+			enabled = excluded.enabled,
+			rules = excluded.rules,
+			version = excluded.version,
+			last_modified = excluded.last_modified
+		RETURNING name, enabled, rules, version, last_modified`)
+	if err := q.Get(ctx, &rule, sql); err != nil {
+		return FilterRule{}, errors.Wrapf(err, "could not upsert %s filter rules", name)
+	}
+	return rule, nil
+}
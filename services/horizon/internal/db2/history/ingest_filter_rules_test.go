@@ -0,0 +1,41 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestUpsertFilterRule(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	_, err := q.GetFilterRule(tt.Ctx, FilterAccountName)
+	tt.Assert.Equal(sql.ErrNoRows, err)
+
+	account, err := q.UpsertFilterRule(tt.Ctx, FilterAccountName, true, []byte(`{"whitelist": ["GA"]}`))
+	tt.Assert.NoError(err)
+	tt.Assert.True(account.Enabled)
+	asset, err := q.UpsertFilterRule(tt.Ctx, FilterAssetName, false, []byte(`{"whitelist": []}`))
+	tt.Assert.NoError(err)
+	tt.Assert.Greater(asset.Version, account.Version)
+
+	updated, err := q.UpsertFilterRule(tt.Ctx, FilterAccountName, false, []byte(`{"whitelist": ["GB"]}`))
+	tt.Assert.NoError(err)
+	tt.Assert.False(updated.Enabled)
+	tt.Assert.Greater(updated.Version, asset.Version)
+
+	rule, err := q.GetFilterRule(tt.Ctx, FilterAccountName)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(updated.Version, rule.Version)
+	tt.Assert.JSONEq(`{"whitelist": ["GB"]}`, string(rule.Rules))
+
+	rules, err := q.GetFilterRules(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(rules, 2)
+	tt.Assert.Equal(FilterAccountName, rules[0].Name)
+	tt.Assert.Equal(FilterAssetName, rules[1].Name)
+}
@@ -18,6 +18,7 @@ const (
 	stateInvalid                    = "exp_state_invalid"
 	offerCompactionSequence         = "offer_compaction_sequence"
 	liquidityPoolCompactionSequence = "liquidity_pool_compaction_sequence"
+	accountBalancesSnapshotLedger   = "account_balances_snapshot_ledger"
 )
 
 // GetLastLedgerIngestNonBlocking works like GetLastLedgerIngest but
@@ -181,6 +182,27 @@ func (q *Q) UpdateLiquidityPoolCompactionSequence(ctx context.Context, sequence
 	)
 }
 
+// GetAccountBalancesSnapshotLedger returns the first ledger from which the
+// balances of all the accounts are recorded in the balance history, zero if
+// the balances were never recorded.
+func (q *Q) GetAccountBalancesSnapshotLedger(ctx context.Context) (uint32, error) {
+	parsed, err := q.getIntValueFromStore(ctx, accountBalancesSnapshotLedger, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "Error converting sequence value")
+	}
+	return uint32(parsed), nil
+}
+
+// UpdateAccountBalancesSnapshotLedger sets the first ledger from which the
+// balances of all the accounts are recorded in the balance history.
+func (q *Q) UpdateAccountBalancesSnapshotLedger(ctx context.Context, sequence uint32) error {
+	return q.updateValueInStore(
+		ctx,
+		accountBalancesSnapshotLedger,
+		strconv.FormatUint(uint64(sequence), 10),
+	)
+}
+
 // getValueFromStore returns a value for a given key from KV store. If value
 // is not present in the key value store "" will be returned.
 func (q *Q) getValueFromStore(ctx context.Context, key string, forUpdate bool) (string, error) {
@@ -2,72 +2,572 @@ package history
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/lib/pq"
 	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/services/horizon/internal/db2"
 	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
 )
 
-type KinesisCoinInCirculationBaseQuery struct {
-	RootAccount      string
-	EmissionAccount  string
-	HotWalletAccount string
-	FeepoolAccount   string
+const (
+	kinesisCoinInCirculationLedgersTable = "history_kinesis_coin_in_circulation_ledgers"
+	kinesisCoinInCirculationDaysTable    = "history_kinesis_coin_in_circulation_days"
+	// kinesisCoinInCirculationBackfillKey is set by migration 58 to the last
+	// ledger ingested before the coin in circulation tables were created. It
+	// is removed once BackfillKinesisCoinInCirculation has filled the tables
+	// from the ingested history.
+	kinesisCoinInCirculationBackfillKey = "kinesis_coin_in_circulation_backfill_to"
+	// kinesisTreasuryAccountsKey stores, as JSON, the treasury accounts the
+	// mints and redemptions of the coin in circulation tables were
+	// classified with.
+	kinesisTreasuryAccountsKey = "kinesis_treasury_accounts"
+)
+
+// ErrKinesisTreasuryAccountsChanged is returned by
+// CheckKinesisTreasuryAccounts when the coin in circulation tables were
+// computed with other treasury accounts than the given ones.
+var ErrKinesisTreasuryAccountsChanged = errors.New("kinesis treasury accounts changed")
+
+// KinesisTreasuryAccounts are the accounts used to classify payments as mints
+// or redemptions. A network can have several hot wallets.
+type KinesisTreasuryAccounts struct {
+	RootAccount       string
+	EmissionAccount   string
+	HotWalletAccounts []string
+	FeepoolAccount    string
+}
+
+// IsHotWallet returns true if address is one of the hot wallet accounts.
+func (t KinesisTreasuryAccounts) IsHotWallet(address string) bool {
+	for _, hotWallet := range t.HotWalletAccounts {
+		if hotWallet == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Equals returns true if t and other list the same accounts, in any order.
+func (t KinesisTreasuryAccounts) Equals(other KinesisTreasuryAccounts) bool {
+	if t.RootAccount != other.RootAccount ||
+		t.EmissionAccount != other.EmissionAccount ||
+		t.FeepoolAccount != other.FeepoolAccount ||
+		len(t.HotWalletAccounts) != len(other.HotWalletAccounts) {
+		return false
+	}
+	hotWallets := append([]string{}, t.HotWalletAccounts...)
+	otherHotWallets := append([]string{}, other.HotWalletAccounts...)
+	sort.Strings(hotWallets)
+	sort.Strings(otherHotWallets)
+	for i := range hotWallets {
+		if hotWallets[i] != otherHotWallets[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// KinesisCoinInCirculationLedger is a row of data from the
+// `history_kinesis_coin_in_circulation_ledgers` table. Amounts are in stroops.
+type KinesisCoinInCirculationLedger struct {
+	LedgerSequence  uint32    `db:"ledger_sequence"`
+	ClosedAt        time.Time `db:"closed_at"`
+	Mint            int64     `db:"mint"`
+	Redemption      int64     `db:"redemption"`
+	TotalMint       int64     `db:"total_mint"`
+	TotalRedemption int64     `db:"total_redemption"`
+	Circulation     int64     `db:"circulation"`
+}
+
+// QKinesisCoinInCirculation defines coin in circulation related queries used
+// during ingestion.
+type QKinesisCoinInCirculation interface {
+	DeleteKinesisCoinInCirculationLedger(ctx context.Context, sequence uint32) (int64, error)
+	InsertKinesisCoinInCirculationLedger(ctx context.Context, row KinesisCoinInCirculationLedger) error
+	RebuildKinesisCoinInCirculation(ctx context.Context, fromLedger uint32) error
+}
+
+// DeleteKinesisCoinInCirculationLedger removes the mint and redemption amounts
+// of the given ledger. It returns the number of rows removed.
+func (q *Q) DeleteKinesisCoinInCirculationLedger(ctx context.Context, sequence uint32) (int64, error) {
+	result, err := q.Exec(ctx, sq.Delete(kinesisCoinInCirculationLedgersTable).
+		Where(sq.Eq{"ledger_sequence": sequence}))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not delete coin in circulation ledger")
+	}
+	return result.RowsAffected()
+}
+
+// InsertKinesisCoinInCirculationLedger inserts the mint and redemption amounts
+// of a ledger. The running totals are computed by RebuildKinesisCoinInCirculation.
+func (q *Q) InsertKinesisCoinInCirculationLedger(ctx context.Context, row KinesisCoinInCirculationLedger) error {
+	sql := sq.Insert(kinesisCoinInCirculationLedgersTable).SetMap(map[string]interface{}{
+		"ledger_sequence": row.LedgerSequence,
+		"closed_at":       row.ClosedAt,
+		"mint":            row.Mint,
+		"redemption":      row.Redemption,
+	})
+	if _, err := q.Exec(ctx, sql); err != nil {
+		return errors.Wrap(err, "could not insert coin in circulation ledger")
+	}
+	return nil
+}
+
+// RebuildKinesisCoinInCirculation recomputes the running totals of every
+// ledger starting at fromLedger and the daily aggregation of the affected
+// days. Rebuilds are serialized with an advisory lock held until the end of
+// the current transaction so parallel reingestion workers, which commit
+// ledgers out of order, always see each other's rows.
+func (q *Q) RebuildKinesisCoinInCirculation(ctx context.Context, fromLedger uint32) error {
+	_, err := q.ExecRaw(ctx, "SELECT pg_advisory_xact_lock(hashtext(?))", kinesisCoinInCirculationLedgersTable)
+	if err != nil {
+		return errors.Wrap(err, "could not acquire coin in circulation lock")
+	}
+
+	// Find the first day affected by the change before the running totals are
+	// updated: either a day which was aggregated from a ledger >= fromLedger
+	// or the day of the first ledger >= fromLedger.
+	var fromDay null.String
+	err = q.GetRaw(ctx, &fromDay, `
+		SELECT to_char(LEAST(
+			(SELECT MIN(day) FROM `+kinesisCoinInCirculationDaysTable+` WHERE ledger_sequence >= ?),
+			(SELECT MIN(closed_at)::date FROM `+kinesisCoinInCirculationLedgersTable+` WHERE ledger_sequence >= ?)
+		), 'YYYY-MM-DD')`,
+		fromLedger, fromLedger,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not find first coin in circulation day to rebuild")
+	}
+	if !fromDay.Valid {
+		return nil
+	}
+
+	_, err = q.ExecRaw(ctx, `
+		WITH previous AS (
+			SELECT
+				COALESCE(SUM(total_mint), 0) AS total_mint,
+				COALESCE(SUM(total_redemption), 0) AS total_redemption
+			FROM (
+				SELECT total_mint, total_redemption
+				FROM `+kinesisCoinInCirculationLedgersTable+`
+				WHERE ledger_sequence < ?
+				ORDER BY ledger_sequence DESC
+				LIMIT 1
+			) p
+		), running AS (
+			SELECT
+				l.ledger_sequence,
+				previous.total_mint + SUM(l.mint) OVER w AS total_mint,
+				previous.total_redemption + SUM(l.redemption) OVER w AS total_redemption
+			FROM `+kinesisCoinInCirculationLedgersTable+` l, previous
+			WHERE l.ledger_sequence >= ?
+			WINDOW w AS (ORDER BY l.ledger_sequence)
+		)
+		UPDATE `+kinesisCoinInCirculationLedgersTable+` l SET
+			total_mint = running.total_mint,
+			total_redemption = running.total_redemption,
+			circulation = running.total_mint - running.total_redemption
+		FROM running
+		WHERE l.ledger_sequence = running.ledger_sequence`,
+		fromLedger, fromLedger,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not rebuild coin in circulation ledgers")
+	}
+
+	_, err = q.ExecRaw(ctx,
+		"DELETE FROM "+kinesisCoinInCirculationDaysTable+" WHERE day >= ?::date",
+		fromDay.String,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not clear coin in circulation days")
+	}
+
+	_, err = q.ExecRaw(ctx, `
+		INSERT INTO `+kinesisCoinInCirculationDaysTable+` (
+			day,
+			ledger_sequence,
+			mint,
+			redemption,
+			total_mint,
+			total_redemption,
+			circulation
+		)
+		SELECT DISTINCT ON (closed_at::date)
+			closed_at::date,
+			ledger_sequence,
+			SUM(mint) OVER (PARTITION BY closed_at::date),
+			SUM(redemption) OVER (PARTITION BY closed_at::date),
+			total_mint,
+			total_redemption,
+			circulation
+		FROM `+kinesisCoinInCirculationLedgersTable+`
+		WHERE closed_at >= ?::date
+		ORDER BY closed_at::date, ledger_sequence DESC`,
+		fromDay.String,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not rebuild coin in circulation days")
+	}
+	return nil
+}
+
+// KinesisCoinInCirculationBackfillPending returns true if the ledgers
+// ingested before the coin in circulation tables were created have not been
+// backfilled yet, in which case the tables do not cover the whole history.
+func (q *Q) KinesisCoinInCirculationBackfillPending(ctx context.Context) (bool, error) {
+	value, err := q.getValueFromStore(ctx, kinesisCoinInCirculationBackfillKey, false)
+	if err != nil {
+		return false, errors.Wrap(err, "could not get coin in circulation backfill ledger")
+	}
+	return value != "", nil
+}
+
+// BackfillKinesisCoinInCirculation fills the coin in circulation tables with
+// the mints and redemptions of the ledgers ingested before the tables were
+// created, classified as in processors.KinesisCoinInCirculationProcessor, and
+// returns the number of ledgers backfilled. It does nothing if there is no
+// backfill pending. Ledgers already ingested into the tables are kept.
+func (q *Q) BackfillKinesisCoinInCirculation(ctx context.Context, accounts KinesisTreasuryAccounts) (int64, error) {
+	if err := q.Begin(); err != nil {
+		return 0, errors.Wrap(err, "could not start transaction")
+	}
+	defer q.Rollback()
+
+	// Locking the key makes concurrent backfills wait for the first one.
+	value, err := q.getValueFromStore(ctx, kinesisCoinInCirculationBackfillKey, true)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get coin in circulation backfill ledger")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	toLedger, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not parse coin in circulation backfill ledger")
+	}
+
+	sources := append([]string{accounts.EmissionAccount}, accounts.HotWalletAccounts...)
+	result, err := q.ExecRaw(ctx, `
+		INSERT INTO `+kinesisCoinInCirculationLedgersTable+` (ledger_sequence, closed_at, mint, redemption)
+		SELECT ledger_sequence, closed_at, mint, redemption FROM (
+			SELECT
+				ht.ledger_sequence,
+				hl.closed_at,
+				COALESCE(SUM(e.amount) FILTER (WHERE e.source = ? AND e.destination <> ?), 0) AS mint,
+				COALESCE(SUM(e.amount) FILTER (WHERE e.source = ANY(?) AND e.destination IN (?, ?)), 0) AS redemption
+			FROM (
+				SELECT
+					hop.transaction_id,
+					hop.source_account AS source,
+					`+kinesisEventDestination+` AS destination,
+					ROUND((`+kinesisEventAmount+`)::numeric * 10000000)::bigint AS amount
+				FROM history_operations hop
+				WHERE hop.type IN (0, 1, 8)
+				AND (hop.type <> 1 OR hop.details->>'asset_type' = 'native')
+				AND hop.source_account = ANY(?)
+			) e
+			JOIN history_transactions ht ON ht.id = e.transaction_id
+			JOIN history_ledgers hl ON hl.sequence = ht.ledger_sequence
+			WHERE (ht.successful = true OR ht.successful IS NULL)
+			AND ht.ledger_sequence <= ?
+			AND e.destination <> ?
+			GROUP BY ht.ledger_sequence, hl.closed_at
+		) l
+		WHERE mint <> 0 OR redemption <> 0
+		ON CONFLICT (ledger_sequence) DO NOTHING`,
+		accounts.EmissionAccount, accounts.RootAccount,
+		pq.Array(accounts.HotWalletAccounts), accounts.EmissionAccount, accounts.RootAccount,
+		pq.Array(sources),
+		toLedger,
+		accounts.FeepoolAccount,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not backfill coin in circulation ledgers")
+	}
+	backfilled, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not count backfilled coin in circulation ledgers")
+	}
+
+	if err = q.RebuildKinesisCoinInCirculation(ctx, 0); err != nil {
+		return 0, err
+	}
+	_, err = q.Exec(ctx, sq.Delete("key_value_store").Where(sq.Eq{"key": kinesisCoinInCirculationBackfillKey}))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not clear coin in circulation backfill ledger")
+	}
+	if err = q.Commit(); err != nil {
+		return 0, errors.Wrap(err, "could not commit coin in circulation backfill")
+	}
+	return backfilled, nil
+}
+
+// GetKinesisTreasuryAccounts returns the treasury accounts the coin in
+// circulation tables were computed with, and false if they were not recorded
+// yet.
+func (q *Q) GetKinesisTreasuryAccounts(ctx context.Context) (KinesisTreasuryAccounts, bool, error) {
+	var accounts KinesisTreasuryAccounts
+	value, err := q.getValueFromStore(ctx, kinesisTreasuryAccountsKey, false)
+	if err != nil {
+		return accounts, false, errors.Wrap(err, "could not get kinesis treasury accounts")
+	}
+	if value == "" {
+		return accounts, false, nil
+	}
+	if err = json.Unmarshal([]byte(value), &accounts); err != nil {
+		return accounts, false, errors.Wrap(err, "could not parse kinesis treasury accounts")
+	}
+	return accounts, true, nil
+}
+
+// UpdateKinesisTreasuryAccounts records the treasury accounts the coin in
+// circulation tables are computed with.
+func (q *Q) UpdateKinesisTreasuryAccounts(ctx context.Context, accounts KinesisTreasuryAccounts) error {
+	encoded, err := json.Marshal(accounts)
+	if err != nil {
+		return errors.Wrap(err, "could not encode kinesis treasury accounts")
+	}
+	return q.updateValueInStore(ctx, kinesisTreasuryAccountsKey, string(encoded))
+}
+
+// CheckKinesisTreasuryAccounts returns ErrKinesisTreasuryAccountsChanged if
+// the coin in circulation tables were computed with other treasury accounts
+// than accounts. When no accounts were recorded yet, accounts are recorded if
+// record is true, i.e. when they are about to be used for ingestion.
+func (q *Q) CheckKinesisTreasuryAccounts(ctx context.Context, accounts KinesisTreasuryAccounts, record bool) error {
+	stored, found, err := q.GetKinesisTreasuryAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if !record {
+			return nil
+		}
+		return q.UpdateKinesisTreasuryAccounts(ctx, accounts)
+	}
+	if !stored.Equals(accounts) {
+		return ErrKinesisTreasuryAccountsChanged
+	}
+	return nil
+}
+
+// ResetKinesisCoinInCirculation clears the coin in circulation tables and
+// records accounts as their treasury accounts. The ingested history is
+// scheduled to be backfilled with BackfillKinesisCoinInCirculation, the
+// ledgers ingested afterwards are classified with accounts.
+func (q *Q) ResetKinesisCoinInCirculation(ctx context.Context, accounts KinesisTreasuryAccounts) error {
+	if err := q.Begin(); err != nil {
+		return errors.Wrap(err, "could not start transaction")
+	}
+	defer q.Rollback()
+
+	for _, table := range []string{kinesisCoinInCirculationLedgersTable, kinesisCoinInCirculationDaysTable} {
+		if _, err := q.Exec(ctx, sq.Delete(table)); err != nil {
+			return errors.Wrapf(err, "could not clear %s", table)
+		}
+	}
+	latest, err := q.GetLatestHistoryLedger(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get latest history ledger")
+	}
+	if latest > 0 {
+		err = q.updateValueInStore(ctx, kinesisCoinInCirculationBackfillKey, strconv.FormatUint(uint64(latest), 10))
+		if err != nil {
+			return errors.Wrap(err, "could not set coin in circulation backfill ledger")
+		}
+	}
+	if err = q.UpdateKinesisTreasuryAccounts(ctx, accounts); err != nil {
+		return err
+	}
+	return errors.Wrap(q.Commit(), "could not commit coin in circulation reset")
 }
 
 type KinesisCoinInCirculationQuery struct {
-	KinesisCoinInCirculationBaseQuery
 	FromDate string
 }
 
+// KinesisCoinInCirculation returns the daily coin in circulation starting at
+// criteria.FromDate or, when it is empty, of the last 7 days with mints or
+// redemptions.
 func (q *Q) KinesisCoinInCirculation(ctx context.Context, criteria KinesisCoinInCirculationQuery) ([]KinesisCoinInCirculation, error) {
-	fn := fmt.Sprintf("kinesis_coin_in_circulation('%s', '%s', '%s', '%s')",
-		criteria.RootAccount,
-		criteria.EmissionAccount,
-		criteria.HotWalletAccount,
-		criteria.FeepoolAccount)
-
-	selectQuery := sq.Select(`
-		tx_date,
-		ledger,
-		circulation,
-		mint,
-		redemption
-	`).From(fn)
+	selectQuery := sq.Select(
+		"day AS tx_date",
+		"ledger_sequence AS ledger",
+		"circulation",
+		"total_mint AS mint",
+		"total_redemption AS redemption",
+	).From(kinesisCoinInCirculationDaysTable)
 
 	if criteria.FromDate != "" {
-		selectFromDate := selectQuery.Where(sq.GtOrEq{"tx_date": criteria.FromDate})
+		selectFromDate := selectQuery.Where(sq.GtOrEq{"day": criteria.FromDate}).OrderBy("day ASC")
 		return q.queryKinesisCoinInCirculation(ctx, selectFromDate)
 	}
 
 	// last 7 records
-	subQ := selectQuery.Limit(7).OrderBy("tx_date DESC")
+	subQ := selectQuery.Limit(7).OrderBy("day DESC")
 	reverseOrderQuery := sq.Select("*").FromSelect(subQ, "cic").OrderBy("tx_date ASC")
 
 	return q.queryKinesisCoinInCirculation(ctx, reverseOrderQuery)
 }
 
+// KinesisCoinInCirculationResolution is the interval coin in circulation
+// records are aggregated by.
+type KinesisCoinInCirculationResolution string
+
+const (
+	KinesisCoinInCirculationLedgerResolution KinesisCoinInCirculationResolution = "ledger"
+	KinesisCoinInCirculationHourResolution   KinesisCoinInCirculationResolution = "hour"
+	KinesisCoinInCirculationDayResolution    KinesisCoinInCirculationResolution = "day"
+	KinesisCoinInCirculationWeekResolution   KinesisCoinInCirculationResolution = "week"
+	KinesisCoinInCirculationMonthResolution  KinesisCoinInCirculationResolution = "month"
+)
+
+// KinesisCoinInCirculationResolutions lists the supported resolutions.
+var KinesisCoinInCirculationResolutions = []KinesisCoinInCirculationResolution{
+	KinesisCoinInCirculationLedgerResolution,
+	KinesisCoinInCirculationHourResolution,
+	KinesisCoinInCirculationDayResolution,
+	KinesisCoinInCirculationWeekResolution,
+	KinesisCoinInCirculationMonthResolution,
+}
+
+// KinesisCoinInCirculationRecord is the coin in circulation of an interval.
+// Mint and Redemption are the amounts minted and redeemed within the interval,
+// the totals are the running totals as of its last ledger with mints or
+// redemptions.
+type KinesisCoinInCirculationRecord struct {
+	Timestamp       time.Time `db:"timestamp"`
+	LedgerSequence  uint32    `db:"ledger_sequence"`
+	Mint            int64     `db:"mint"`
+	Redemption      int64     `db:"redemption"`
+	TotalMint       int64     `db:"total_mint"`
+	TotalRedemption int64     `db:"total_redemption"`
+	Circulation     int64     `db:"circulation"`
+}
+
+// PagingToken returns a cursor for this record. Records are paged by their
+// last ledger so `cursor=now` and streaming behave like other history
+// endpoints.
+func (r KinesisCoinInCirculationRecord) PagingToken() string {
+	return toid.New(int32(r.LedgerSequence), 0, 0).String()
+}
+
+// KinesisCoinInCirculationRecordsQuery filters the records returned by
+// KinesisCoinInCirculationRecords. Records are included when the start of
+// their interval is within [From, To), zero values are unbounded.
+type KinesisCoinInCirculationRecordsQuery struct {
+	Resolution KinesisCoinInCirculationResolution
+	From       time.Time
+	To         time.Time
+	Page       db2.PageQuery
+}
+
+// KinesisCoinInCirculationRecords returns a page of coin in circulation
+// records aggregated by criteria.Resolution.
+func (q *Q) KinesisCoinInCirculationRecords(ctx context.Context, criteria KinesisCoinInCirculationRecordsQuery) ([]KinesisCoinInCirculationRecord, error) {
+	var inner sq.SelectBuilder
+	switch criteria.Resolution {
+	case KinesisCoinInCirculationLedgerResolution:
+		inner = selectKinesisCoinInCirculationRows(kinesisCoinInCirculationLedgersTable, "closed_at", "ledger_sequence")
+	case KinesisCoinInCirculationDayResolution:
+		inner = selectKinesisCoinInCirculationRows(kinesisCoinInCirculationDaysTable, "day::timestamp", "ledger_sequence")
+	case KinesisCoinInCirculationHourResolution:
+		inner = selectKinesisCoinInCirculationBuckets(kinesisCoinInCirculationLedgersTable, "date_trunc('hour', closed_at)")
+	case KinesisCoinInCirculationWeekResolution, KinesisCoinInCirculationMonthResolution:
+		inner = selectKinesisCoinInCirculationBuckets(
+			kinesisCoinInCirculationDaysTable,
+			fmt.Sprintf("date_trunc('%s', day::timestamp)", criteria.Resolution),
+		)
+	default:
+		return nil, errors.Errorf("invalid coin in circulation resolution: %s", criteria.Resolution)
+	}
+
+	sql := sq.Select(
+		"r.timestamp",
+		"r.ledger_sequence",
+		"r.mint",
+		"r.redemption",
+		"r.total_mint",
+		"r.total_redemption",
+		"r.circulation",
+	).FromSelect(inner, "r")
+	if !criteria.From.IsZero() {
+		sql = sql.Where("r.timestamp >= ?", criteria.From.UTC())
+	}
+	if !criteria.To.IsZero() {
+		sql = sql.Where("r.timestamp < ?", criteria.To.UTC())
+	}
+
+	sql, err := criteria.Page.ApplyTo(sql, "r.id")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not apply query to page")
+	}
+
+	var results []KinesisCoinInCirculationRecord
+	if err := q.Select(ctx, &results, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+	return results, nil
+}
+
+// selectKinesisCoinInCirculationRows selects the rows of one of the
+// materialized tables as they are.
+func selectKinesisCoinInCirculationRows(table, timestamp, ledger string) sq.SelectBuilder {
+	return sq.Select(
+		timestamp+" AS timestamp",
+		ledger+" AS ledger_sequence",
+		"mint",
+		"redemption",
+		"total_mint",
+		"total_redemption",
+		"circulation",
+		"("+ledger+"::bigint << 32) AS id",
+	).From(table)
+}
+
+// selectKinesisCoinInCirculationBuckets aggregates the rows of one of the
+// materialized tables in the intervals given by bucket. The running totals of
+// an interval are the ones of its last row.
+func selectKinesisCoinInCirculationBuckets(table, bucket string) sq.SelectBuilder {
+	return sq.Select().
+		Column(bucket + " AS timestamp").
+		Column("MAX(ledger_sequence) AS ledger_sequence").
+		Column("SUM(mint)::bigint AS mint").
+		Column("SUM(redemption)::bigint AS redemption").
+		Column("(ARRAY_AGG(total_mint ORDER BY ledger_sequence DESC))[1] AS total_mint").
+		Column("(ARRAY_AGG(total_redemption ORDER BY ledger_sequence DESC))[1] AS total_redemption").
+		Column("(ARRAY_AGG(circulation ORDER BY ledger_sequence DESC))[1] AS circulation").
+		Column("(MAX(ledger_sequence)::bigint << 32) AS id").
+		From(table).
+		GroupBy(bucket)
+}
+
 type KinesisCoinInCirculationByLedgerQuery struct {
-	KinesisCoinInCirculationBaseQuery
 	LedgerID uint64
 }
 
+// KinesisCoinInCirculationByLedger returns the coin in circulation as of
+// criteria.LedgerID, along with the last ledger up to it in which coins were
+// minted or redeemed.
 func (q *Q) KinesisCoinInCirculationByLedger(ctx context.Context, criteria KinesisCoinInCirculationByLedgerQuery) ([]KinesisCoinInCirculationByLedger, error) {
-	fn := fmt.Sprintf("kinesis_coin_in_circulation_at_ledger('%s', '%s', '%s', '%s', %d)",
-		criteria.RootAccount,
-		criteria.EmissionAccount,
-		criteria.HotWalletAccount,
-		criteria.FeepoolAccount,
-		criteria.LedgerID)
-
-	selectQuery := sq.Select(`
-		last_ledger_timestamp,
-		last_ledger,
-		circulation,
-		mint,
-		redemption
-	`).From(fn)
+	selectQuery := sq.Select(
+		"closed_at AS last_ledger_timestamp",
+		"ledger_sequence AS last_ledger",
+		"circulation",
+		"total_mint AS mint",
+		"total_redemption AS redemption",
+	).From(kinesisCoinInCirculationLedgersTable).
+		Where(sq.LtOrEq{"ledger_sequence": criteria.LedgerID}).
+		OrderBy("ledger_sequence DESC")
 
 	return q.queryKinesisCoinInCirculationByLeader(ctx, selectQuery.Limit(1))
 }
@@ -95,9 +595,19 @@ func getPublicKeyFromSeedPhrase(seedPhrase string) string {
 	return kp.Address()
 }
 
-func (t *KinesisCoinInCirculationBaseQuery) PopulateAccounts(networkPassphrase string) {
-	t.RootAccount = getPublicKeyFromSeedPhrase(networkPassphrase)
-	t.EmissionAccount = getPublicKeyFromSeedPhrase(networkPassphrase + "emission")
-	t.HotWalletAccount = getPublicKeyFromSeedPhrase(networkPassphrase + "exchange")
-	t.FeepoolAccount = getPublicKeyFromSeedPhrase(networkPassphrase + "feepool")
+// PopulateAccounts sets the accounts which are not configured to the default
+// treasury accounts derived from the network passphrase.
+func (t *KinesisTreasuryAccounts) PopulateAccounts(networkPassphrase string) {
+	if t.RootAccount == "" {
+		t.RootAccount = getPublicKeyFromSeedPhrase(networkPassphrase)
+	}
+	if t.EmissionAccount == "" {
+		t.EmissionAccount = getPublicKeyFromSeedPhrase(networkPassphrase + "emission")
+	}
+	if len(t.HotWalletAccounts) == 0 {
+		t.HotWalletAccounts = []string{getPublicKeyFromSeedPhrase(networkPassphrase + "exchange")}
+	}
+	if t.FeepoolAccount == "" {
+		t.FeepoolAccount = getPublicKeyFromSeedPhrase(networkPassphrase + "feepool")
+	}
 }
@@ -0,0 +1,130 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Types of coin in circulation events
+const (
+	KinesisCoinInCirculationMintEvent       = "mint"
+	KinesisCoinInCirculationRedemptionEvent = "redemption"
+)
+
+// KinesisCoinInCirculationEvent is an operation classified as a mint or a
+// redemption of coins. Amount is formatted as in the operation details.
+type KinesisCoinInCirculationEvent struct {
+	OperationID     int64             `db:"id"`
+	OperationType   xdr.OperationType `db:"type"`
+	TransactionHash string            `db:"transaction_hash"`
+	LedgerSequence  int32             `db:"ledger_sequence"`
+	LedgerCloseTime time.Time         `db:"ledger_close_time"`
+	Source          string            `db:"source"`
+	Destination     string            `db:"destination"`
+	Amount          string            `db:"amount"`
+	EventType       string            `db:"event_type"`
+}
+
+// KinesisCoinInCirculationEventsQuery filters the events returned by
+// KinesisCoinInCirculationEvents. EventType is optional.
+type KinesisCoinInCirculationEventsQuery struct {
+	Accounts  KinesisTreasuryAccounts
+	EventType string
+	Page      db2.PageQuery
+}
+
+// The destination and amount of the operations which can mint or redeem coins,
+// see processors.KinesisCoinInCirculationProcessor.
+const (
+	kinesisEventDestination = `CASE hop.type
+		WHEN 0 THEN hop.details->>'account'
+		WHEN 1 THEN hop.details->>'to'
+		WHEN 8 THEN hop.details->>'into'
+	END`
+	kinesisEventAmount = `CASE hop.type
+		WHEN 0 THEN hop.details->>'starting_balance'
+		WHEN 1 THEN hop.details->>'amount'
+		WHEN 8 THEN (
+			SELECT he.details->>'amount' FROM history_effects he
+			WHERE he.history_operation_id = hop.id AND he.type = 2
+			LIMIT 1
+		)
+	END`
+)
+
+// KinesisCoinInCirculationEvents returns a page of the successful operations
+// which minted or redeemed coins. Operations are found through the
+// participants of the emission and hot wallet accounts.
+func (q *Q) KinesisCoinInCirculationEvents(ctx context.Context, criteria KinesisCoinInCirculationEventsQuery) ([]KinesisCoinInCirculationEvent, error) {
+	accounts := criteria.Accounts
+	sources := append([]string{accounts.EmissionAccount}, accounts.HotWalletAccounts...)
+
+	mint := sq.And{
+		sq.Eq{"hop.source_account": accounts.EmissionAccount},
+		sq.Expr(kinesisEventDestination+" NOT IN (?, ?)", accounts.RootAccount, accounts.FeepoolAccount),
+	}
+	redemption := sq.And{
+		sq.NotEq{"hop.source_account": accounts.EmissionAccount},
+		sq.Expr(kinesisEventDestination+" IN (?, ?)", accounts.EmissionAccount, accounts.RootAccount),
+	}
+
+	sql := sq.Select(
+		"hop.id",
+		"hop.type",
+		"ht.transaction_hash",
+		"ht.ledger_sequence",
+		"hl.closed_at AS ledger_close_time",
+		"hop.source_account AS source",
+		kinesisEventDestination+" AS destination",
+		kinesisEventAmount+" AS amount",
+	).
+		Column(sq.Expr(
+			"CASE WHEN hop.source_account = ? THEN ? ELSE ? END AS event_type",
+			accounts.EmissionAccount,
+			KinesisCoinInCirculationMintEvent,
+			KinesisCoinInCirculationRedemptionEvent,
+		)).
+		From("history_operation_participants hopp").
+		Join("history_accounts ha ON ha.id = hopp.history_account_id").
+		Join("history_operations hop ON hop.id = hopp.history_operation_id").
+		Join("history_transactions ht ON ht.id = hop.transaction_id").
+		Join("history_ledgers hl ON hl.sequence = ht.ledger_sequence").
+		Where(sq.Eq{"ha.address": sources}).
+		// only keep the participant which is the source of the operation
+		Where("ha.address = hop.source_account").
+		Where("(ht.successful = true OR ht.successful IS NULL)").
+		Where(sq.Or{
+			sq.Eq{"hop.type": []xdr.OperationType{xdr.OperationTypeCreateAccount, xdr.OperationTypeAccountMerge}},
+			sq.And{
+				sq.Eq{"hop.type": xdr.OperationTypePayment},
+				sq.Expr("hop.details->>'asset_type' = 'native'"),
+			},
+		})
+
+	switch criteria.EventType {
+	case "":
+		sql = sql.Where(sq.Or{mint, redemption})
+	case KinesisCoinInCirculationMintEvent:
+		sql = sql.Where(mint)
+	case KinesisCoinInCirculationRedemptionEvent:
+		sql = sql.Where(redemption)
+	default:
+		return nil, errors.Errorf("invalid coin in circulation event type: %s", criteria.EventType)
+	}
+
+	sql, err := criteria.Page.ApplyTo(sql, "hopp.history_operation_id")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not apply query to page")
+	}
+
+	var results []KinesisCoinInCirculationEvent
+	if err := q.Select(ctx, &results, sql); err != nil {
+		return nil, errors.Wrap(err, "could not run select query")
+	}
+	return results, nil
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestKinesisCoinInCirculationEvents(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	accounts := KinesisTreasuryAccounts{}
+	accounts.PopulateAccounts("Kinesis UAT")
+	hotWallet := accounts.HotWalletAccounts[0]
+	user := keypair.MustRandom().Address()
+
+	sequence := int32(56)
+	_, err := q.InsertLedger(tt.Ctx, xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)},
+	}, 1, 0, 5, 5, 1, 0)
+	tt.Assert.NoError(err)
+
+	transactionBuilder := q.NewTransactionBatchInsertBuilder(1)
+	tt.Assert.NoError(transactionBuilder.Add(tt.Ctx, buildLedgerTransaction(tt.T, testTransaction{
+		index:         1,
+		envelopeXDR:   "AAAAACiSTRmpH6bHC6Ekna5e82oiGY5vKDEEUgkq9CB//t+rAAAAyAEXUhsAADDRAAAAAAAAAAAAAAABAAAAAAAAAAsBF1IbAABX4QAAAAAAAAAA",
+		resultXDR:     "AAAAAAAAASwAAAAAAAAAAwAAAAAAAAAAAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAFAAAAAAAAAAA=",
+		feeChangesXDR: "AAAAAA==",
+		metaXDR:       "AAAAAQAAAAAAAAAA",
+		hash:          "19aaa18db88605aedec04659fb45e06f240b022eb2d429e05133e4d53cd945ba",
+	}), uint32(sequence)))
+	tt.Assert.NoError(transactionBuilder.Exec(tt.Ctx))
+
+	accountIDs, err := q.CreateAccounts(tt.Ctx, []string{
+		accounts.RootAccount, accounts.EmissionAccount, hotWallet, accounts.FeepoolAccount, user,
+	}, 5)
+	tt.Assert.NoError(err)
+
+	operationBuilder := q.NewOperationBatchInsertBuilder(5)
+	participantBuilder := q.NewOperationParticipantBatchInsertBuilder(10)
+	addOperation := func(index int32, opType xdr.OperationType, source, destination string, details map[string]interface{}) int64 {
+		opID := toid.New(sequence, 1, index).ToInt64()
+		encoded, err := json.Marshal(details)
+		tt.Assert.NoError(err)
+		tt.Assert.NoError(operationBuilder.Add(
+			tt.Ctx,
+			opID,
+			toid.New(sequence, 1, 0).ToInt64(),
+			uint32(index),
+			opType,
+			encoded,
+			source,
+			null.String{},
+		))
+		tt.Assert.NoError(participantBuilder.Add(tt.Ctx, opID, accountIDs[source]))
+		tt.Assert.NoError(participantBuilder.Add(tt.Ctx, opID, accountIDs[destination]))
+		return opID
+	}
+	payment := func(from, to, assetType string) map[string]interface{} {
+		return map[string]interface{}{"from": from, "to": to, "amount": "10.0000000", "asset_type": assetType}
+	}
+
+	mint := addOperation(1, xdr.OperationTypePayment, accounts.EmissionAccount, user,
+		payment(accounts.EmissionAccount, user, "native"))
+	redemption := addOperation(2, xdr.OperationTypePayment, hotWallet, accounts.EmissionAccount,
+		payment(hotWallet, accounts.EmissionAccount, "native"))
+	addOperation(3, xdr.OperationTypePayment, accounts.EmissionAccount, accounts.FeepoolAccount,
+		payment(accounts.EmissionAccount, accounts.FeepoolAccount, "native"))
+	addOperation(4, xdr.OperationTypePayment, accounts.EmissionAccount, user,
+		payment(accounts.EmissionAccount, user, "credit_alphanum4"))
+	createAccount := addOperation(5, xdr.OperationTypeCreateAccount, accounts.EmissionAccount, user,
+		map[string]interface{}{"funder": accounts.EmissionAccount, "account": user, "starting_balance": "5.0000000"})
+	tt.Assert.NoError(operationBuilder.Exec(tt.Ctx))
+	tt.Assert.NoError(participantBuilder.Exec(tt.Ctx))
+
+	page := db2.PageQuery{Order: db2.OrderAscending, Limit: db2.DefaultPageSize}
+	assertEvents := func(eventType string, page db2.PageQuery, expected ...int64) []KinesisCoinInCirculationEvent {
+		events, err := q.KinesisCoinInCirculationEvents(tt.Ctx, KinesisCoinInCirculationEventsQuery{
+			Accounts:  accounts,
+			EventType: eventType,
+			Page:      page,
+		})
+		tt.Assert.NoError(err)
+		ids := make([]int64, len(events))
+		for i, event := range events {
+			ids[i] = event.OperationID
+		}
+		tt.Assert.Equal(expected, ids)
+		return events
+	}
+
+	events := assertEvents("", page, mint, redemption, createAccount)
+	tt.Assert.Equal(KinesisCoinInCirculationMintEvent, events[0].EventType)
+	tt.Assert.Equal(user, events[0].Destination)
+	tt.Assert.Equal("10.0000000", events[0].Amount)
+	tt.Assert.Equal(sequence, events[0].LedgerSequence)
+	tt.Assert.Equal("19aaa18db88605aedec04659fb45e06f240b022eb2d429e05133e4d53cd945ba", events[0].TransactionHash)
+	tt.Assert.Equal(KinesisCoinInCirculationRedemptionEvent, events[1].EventType)
+	tt.Assert.Equal(hotWallet, events[1].Source)
+	tt.Assert.Equal("5.0000000", events[2].Amount)
+
+	assertEvents(KinesisCoinInCirculationMintEvent, page, mint, createAccount)
+	assertEvents(KinesisCoinInCirculationRedemptionEvent, page, redemption)
+
+	page.Order = db2.OrderDescending
+	page.Limit = 1
+	assertEvents("", page, createAccount)
+}
@@ -0,0 +1,274 @@
+package history
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/test"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+func TestRebuildKinesisCoinInCirculation(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	day1 := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	insert := func(row KinesisCoinInCirculationLedger) {
+		tt.Assert.NoError(q.InsertKinesisCoinInCirculationLedger(tt.Ctx, row))
+		tt.Assert.NoError(q.RebuildKinesisCoinInCirculation(tt.Ctx, row.LedgerSequence))
+	}
+	assertCirculation := func(expected []KinesisCoinInCirculation) {
+		records, err := q.KinesisCoinInCirculation(tt.Ctx, KinesisCoinInCirculationQuery{})
+		tt.Assert.NoError(err)
+		tt.Assert.Len(records, len(expected))
+		for i := range expected {
+			tt.Assert.Equal(expected[i].Ledger, records[i].Ledger)
+			tt.Assert.Equal(expected[i].Mint, records[i].Mint)
+			tt.Assert.Equal(expected[i].Redemption, records[i].Redemption)
+			tt.Assert.Equal(expected[i].Circulation, records[i].Circulation)
+		}
+	}
+
+	insert(KinesisCoinInCirculationLedger{LedgerSequence: 10, ClosedAt: day1.Add(10 * time.Hour), Mint: 100})
+	insert(KinesisCoinInCirculationLedger{LedgerSequence: 20, ClosedAt: day1.Add(20 * time.Hour), Redemption: 30})
+	insert(KinesisCoinInCirculationLedger{LedgerSequence: 30, ClosedAt: day2.Add(time.Hour), Mint: 50})
+	assertCirculation([]KinesisCoinInCirculation{
+		{Ledger: 20, Mint: 100, Redemption: 30, Circulation: 70},
+		{Ledger: 30, Mint: 150, Redemption: 30, Circulation: 120},
+	})
+
+	// ledgers reingested out of order update the running totals of later ledgers
+	insert(KinesisCoinInCirculationLedger{LedgerSequence: 15, ClosedAt: day1.Add(15 * time.Hour), Mint: 5})
+	assertCirculation([]KinesisCoinInCirculation{
+		{Ledger: 20, Mint: 105, Redemption: 30, Circulation: 75},
+		{Ledger: 30, Mint: 155, Redemption: 30, Circulation: 125},
+	})
+
+	byLedger, err := q.KinesisCoinInCirculationByLedger(tt.Ctx, KinesisCoinInCirculationByLedgerQuery{LedgerID: 29})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(byLedger, 1) {
+		tt.Assert.Equal(uint32(20), byLedger[0].Ledger)
+		tt.Assert.Equal(int64(105), byLedger[0].Mint)
+		tt.Assert.Equal(int64(30), byLedger[0].Redemption)
+		tt.Assert.Equal(int64(75), byLedger[0].Circulation)
+	}
+
+	// removing a ledger, e.g. when reingesting it, restores the previous totals
+	deleted, err := q.DeleteKinesisCoinInCirculationLedger(tt.Ctx, 15)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), deleted)
+	tt.Assert.NoError(q.RebuildKinesisCoinInCirculation(tt.Ctx, 15))
+	assertCirculation([]KinesisCoinInCirculation{
+		{Ledger: 20, Mint: 100, Redemption: 30, Circulation: 70},
+		{Ledger: 30, Mint: 150, Redemption: 30, Circulation: 120},
+	})
+
+	byLedger, err = q.KinesisCoinInCirculationByLedger(tt.Ctx, KinesisCoinInCirculationByLedgerQuery{LedgerID: 5})
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(byLedger)
+}
+
+func TestKinesisCoinInCirculationRecords(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	june := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, row := range []KinesisCoinInCirculationLedger{
+		{LedgerSequence: 10, ClosedAt: june.Add(time.Hour), Mint: 100},
+		{LedgerSequence: 20, ClosedAt: june.AddDate(0, 0, 10), Redemption: 30},
+		{LedgerSequence: 30, ClosedAt: june.AddDate(0, 1, 0), Mint: 50},
+	} {
+		tt.Assert.NoError(q.InsertKinesisCoinInCirculationLedger(tt.Ctx, row))
+	}
+	tt.Assert.NoError(q.RebuildKinesisCoinInCirculation(tt.Ctx, 10))
+
+	page := db2.PageQuery{Order: db2.OrderAscending, Limit: db2.DefaultPageSize}
+	records, err := q.KinesisCoinInCirculationRecords(tt.Ctx, KinesisCoinInCirculationRecordsQuery{
+		Resolution: KinesisCoinInCirculationMonthResolution,
+		Page:       page,
+	})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 2) {
+		tt.Assert.True(june.Equal(records[0].Timestamp))
+		tt.Assert.Equal(uint32(20), records[0].LedgerSequence)
+		tt.Assert.Equal(int64(100), records[0].Mint)
+		tt.Assert.Equal(int64(30), records[0].Redemption)
+		tt.Assert.Equal(int64(70), records[0].Circulation)
+		tt.Assert.Equal(uint32(30), records[1].LedgerSequence)
+		tt.Assert.Equal(int64(120), records[1].Circulation)
+	}
+
+	// intervals are filtered by their start
+	records, err = q.KinesisCoinInCirculationRecords(tt.Ctx, KinesisCoinInCirculationRecordsQuery{
+		Resolution: KinesisCoinInCirculationLedgerResolution,
+		From:       june.Add(time.Hour),
+		To:         june.AddDate(0, 1, 0),
+		Page:       page,
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.Len(records, 2)
+
+	page.Order = db2.OrderDescending
+	page.Cursor = records[1].PagingToken()
+	page.Limit = 1
+	records, err = q.KinesisCoinInCirculationRecords(tt.Ctx, KinesisCoinInCirculationRecordsQuery{
+		Resolution: KinesisCoinInCirculationDayResolution,
+		Page:       page,
+	})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(records, 1) {
+		tt.Assert.Equal(uint32(10), records[0].LedgerSequence)
+	}
+
+	_, err = q.KinesisCoinInCirculationRecords(tt.Ctx, KinesisCoinInCirculationRecordsQuery{
+		Resolution: "minute",
+		Page:       page,
+	})
+	tt.Assert.EqualError(err, "invalid coin in circulation resolution: minute")
+}
+
+func TestBackfillKinesisCoinInCirculation(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	accounts := KinesisTreasuryAccounts{}
+	accounts.PopulateAccounts("Kinesis UAT")
+	hotWallet := accounts.HotWalletAccounts[0]
+	user := keypair.MustRandom().Address()
+
+	sequence := int32(56)
+	_, err := q.InsertLedger(tt.Ctx, xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)},
+	}, 1, 0, 5, 5, 1, 0)
+	tt.Assert.NoError(err)
+
+	transactionBuilder := q.NewTransactionBatchInsertBuilder(1)
+	tt.Assert.NoError(transactionBuilder.Add(tt.Ctx, buildLedgerTransaction(tt.T, testTransaction{
+		index:         1,
+		envelopeXDR:   "AAAAACiSTRmpH6bHC6Ekna5e82oiGY5vKDEEUgkq9CB//t+rAAAAyAEXUhsAADDRAAAAAAAAAAAAAAABAAAAAAAAAAsBF1IbAABX4QAAAAAAAAAA",
+		resultXDR:     "AAAAAAAAASwAAAAAAAAAAwAAAAAAAAAAAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAFAAAAAAAAAAA=",
+		feeChangesXDR: "AAAAAA==",
+		metaXDR:       "AAAAAQAAAAAAAAAA",
+		hash:          "19aaa18db88605aedec04659fb45e06f240b022eb2d429e05133e4d53cd945ba",
+	}), uint32(sequence)))
+	tt.Assert.NoError(transactionBuilder.Exec(tt.Ctx))
+
+	operationBuilder := q.NewOperationBatchInsertBuilder(5)
+	addOperation := func(index int32, opType xdr.OperationType, source string, details map[string]interface{}) {
+		encoded, err := json.Marshal(details)
+		tt.Assert.NoError(err)
+		tt.Assert.NoError(operationBuilder.Add(
+			tt.Ctx,
+			toid.New(sequence, 1, index).ToInt64(),
+			toid.New(sequence, 1, 0).ToInt64(),
+			uint32(index),
+			opType,
+			encoded,
+			source,
+			null.String{},
+		))
+	}
+	payment := func(from, to, assetType string) map[string]interface{} {
+		return map[string]interface{}{"from": from, "to": to, "amount": "10.0000000", "asset_type": assetType}
+	}
+
+	addOperation(1, xdr.OperationTypePayment, accounts.EmissionAccount, payment(accounts.EmissionAccount, user, "native"))
+	addOperation(2, xdr.OperationTypePayment, hotWallet, payment(hotWallet, accounts.EmissionAccount, "native"))
+	addOperation(3, xdr.OperationTypePayment, accounts.EmissionAccount, payment(accounts.EmissionAccount, accounts.FeepoolAccount, "native"))
+	addOperation(4, xdr.OperationTypePayment, accounts.EmissionAccount, payment(accounts.EmissionAccount, user, "credit_alphanum4"))
+	addOperation(5, xdr.OperationTypeCreateAccount, accounts.EmissionAccount,
+		map[string]interface{}{"funder": accounts.EmissionAccount, "account": user, "starting_balance": "5.0000000"})
+	tt.Assert.NoError(operationBuilder.Exec(tt.Ctx))
+
+	// nothing to backfill until the migration records the last ingested ledger
+	backfilled, err := q.BackfillKinesisCoinInCirculation(tt.Ctx, accounts)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), backfilled)
+
+	tt.Assert.NoError(q.updateValueInStore(tt.Ctx, kinesisCoinInCirculationBackfillKey, "56"))
+	pending, err := q.KinesisCoinInCirculationBackfillPending(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.True(pending)
+
+	backfilled, err = q.BackfillKinesisCoinInCirculation(tt.Ctx, accounts)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), backfilled)
+
+	pending, err = q.KinesisCoinInCirculationBackfillPending(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.False(pending)
+
+	byLedger, err := q.KinesisCoinInCirculationByLedger(tt.Ctx, KinesisCoinInCirculationByLedgerQuery{LedgerID: 56})
+	tt.Assert.NoError(err)
+	if tt.Assert.Len(byLedger, 1) {
+		tt.Assert.Equal(uint32(56), byLedger[0].Ledger)
+		tt.Assert.Equal(int64(150000000), byLedger[0].Mint)
+		tt.Assert.Equal(int64(100000000), byLedger[0].Redemption)
+		tt.Assert.Equal(int64(50000000), byLedger[0].Circulation)
+	}
+}
+
+func TestKinesisTreasuryAccountsEquals(t *testing.T) {
+	accounts := KinesisTreasuryAccounts{HotWalletAccounts: []string{"GA", "GB"}}
+	accounts.PopulateAccounts("Kinesis UAT")
+	reordered := accounts
+	reordered.HotWalletAccounts = []string{"GB", "GA"}
+	if !accounts.Equals(reordered) {
+		t.Error("the order of the hot wallets should not matter")
+	}
+	changed := accounts
+	changed.FeepoolAccount = "GC"
+	if accounts.Equals(changed) {
+		t.Error("the feepool account changed")
+	}
+}
+
+func TestKinesisTreasuryAccountsCheck(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	accounts := KinesisTreasuryAccounts{}
+	accounts.PopulateAccounts("Kinesis UAT")
+	other := KinesisTreasuryAccounts{}
+	other.PopulateAccounts("Kinesis Mainnet")
+
+	// nothing is recorded by the instances which do not ingest
+	tt.Assert.NoError(q.CheckKinesisTreasuryAccounts(tt.Ctx, other, false))
+	_, found, err := q.GetKinesisTreasuryAccounts(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.False(found)
+
+	tt.Assert.NoError(q.CheckKinesisTreasuryAccounts(tt.Ctx, accounts, true))
+	stored, found, err := q.GetKinesisTreasuryAccounts(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.True(found)
+	tt.Assert.True(accounts.Equals(stored))
+
+	tt.Assert.NoError(q.CheckKinesisTreasuryAccounts(tt.Ctx, accounts, false))
+	tt.Assert.Equal(ErrKinesisTreasuryAccountsChanged, q.CheckKinesisTreasuryAccounts(tt.Ctx, other, true))
+
+	_, err = q.InsertLedger(tt.Ctx, xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{LedgerSeq: 56},
+	}, 1, 0, 5, 5, 1, 0)
+	tt.Assert.NoError(err)
+	tt.Assert.NoError(q.ResetKinesisCoinInCirculation(tt.Ctx, other))
+	tt.Assert.NoError(q.CheckKinesisTreasuryAccounts(tt.Ctx, other, false))
+	pending, err := q.KinesisCoinInCirculationBackfillPending(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.True(pending)
+}
@@ -24,6 +24,24 @@ func (q *Q) LedgerBySequence(ctx context.Context, dest interface{}, seq int32) e
 	return q.Get(ctx, dest, sql)
 }
 
+// LedgerSequenceClosedBefore returns the sequence of the last ledger closed
+// before t, 0 if there is none.
+func (q *Q) LedgerSequenceClosedBefore(ctx context.Context, t time.Time) (uint32, error) {
+	var sequence uint32
+	sql := sq.Select("sequence").
+		From("history_ledgers").
+		Where("closed_at < ?", t.UTC()).
+		OrderBy("closed_at DESC", "sequence DESC").
+		Limit(1)
+	if err := q.Get(ctx, &sequence, sql); err != nil {
+		if q.NoRows(err) {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "could not get ledger closed before time")
+	}
+	return sequence, nil
+}
+
 // Ledgers provides a helper to filter rows from the `history_ledgers` table
 // with pre-defined filters.  See `LedgersQ` methods for the available filters.
 func (q *Q) Ledgers() *LedgersQ {
@@ -97,10 +115,13 @@ type QLedgers interface {
 		opCount int,
 		txSetOpCount int,
 		ingestVersion int,
+		filterVersion int64,
 	) (int64, error)
 }
 
-// InsertLedger creates a row in the history_ledgers table.
+// InsertLedger creates a row in the history_ledgers table. filterVersion is
+// the version of the ingestion filter rules the ledger was ingested with, 0
+// when it was ingested without filters.
 // Returns number of rows affected and error.
 func (q *Q) InsertLedger(ctx context.Context,
 	ledger xdr.LedgerHeaderHistoryEntry,
@@ -109,6 +130,7 @@ func (q *Q) InsertLedger(ctx context.Context,
 	opCount int,
 	txSetOpCount int,
 	ingestVersion int,
+	filterVersion int64,
 ) (int64, error) {
 	m, err := ledgerHeaderToMap(
 		ledger,
@@ -117,6 +139,7 @@ func (q *Q) InsertLedger(ctx context.Context,
 		opCount,
 		txSetOpCount,
 		ingestVersion,
+		filterVersion,
 	)
 	if err != nil {
 		return 0, err
@@ -231,6 +254,7 @@ func ledgerHeaderToMap(
 	opCount int,
 	txSetOpCount int,
 	importerVersion int,
+	filterVersion int64,
 ) (map[string]interface{}, error) {
 	ledgerHeaderBase64, err := xdr.MarshalBase64(ledger.Header)
 	if err != nil {
@@ -260,6 +284,7 @@ func ledgerHeaderToMap(
 		"protocol_version":             ledger.Header.LedgerVersion,
 		"ledger_header":                ledgerHeaderBase64,
 		"max_fee":                      ledger.Header.MaxFee,
+		"filter_version":               null.NewInt(filterVersion, filterVersion > 0),
 	}, nil
 }
 
@@ -286,4 +311,5 @@ var selectLedger = sq.Select(
 	"hl.protocol_version",
 	"hl.ledger_header",
 	"hl.max_fee",
+	"hl.filter_version",
 ).From("history_ledgers hl")
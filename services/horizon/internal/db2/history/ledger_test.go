@@ -52,6 +52,15 @@ func TestLedgerQueries(t *testing.T) {
 		tt.Assert.Contains(foundSeqs, int32(2))
 		tt.Assert.Contains(foundSeqs, int32(3))
 	}
+
+	// LedgerSequenceClosedBefore
+	sequence, err := q.LedgerSequenceClosedBefore(tt.Ctx, time.Now().AddDate(100, 0, 0))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(3), sequence)
+
+	sequence, err = q.LedgerSequenceClosedBefore(tt.Ctx, time.Time{})
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(uint32(0), sequence)
 }
 
 func TestInsertLedger(t *testing.T) {
@@ -84,6 +93,7 @@ func TestInsertLedger(t *testing.T) {
 		ProtocolVersion:            12,
 		BaseFee:                    100,
 		ClosedAt:                   time.Now().UTC().Truncate(time.Second),
+		FilterVersion:              null.IntFrom(7),
 	}
 	*expectedLedger.SuccessfulTransactionCount = 12
 	*expectedLedger.FailedTransactionCount = 3
@@ -126,6 +136,7 @@ func TestInsertLedger(t *testing.T) {
 		23,
 		26,
 		int(expectedLedger.ImporterVersion),
+		expectedLedger.FilterVersion.Int64,
 	)
 	tt.Assert.NoError(err)
 	tt.Assert.Equal(rowsAffected, int64(1))
@@ -211,6 +222,7 @@ func insertLedgerWithSequence(tt *test.T, q *Q, seq uint32) {
 		23,
 		26,
 		int(expectedLedger.ImporterVersion),
+		0,
 	)
 	tt.Assert.NoError(err)
 	tt.Assert.Equal(rowsAffected, int64(1))
@@ -237,14 +237,17 @@ type AccountEntry struct {
 
 type IngestionQ interface {
 	QAccounts
+	QAccountBalances
 	QAssetStats
 	QClaimableBalances
 	QHistoryClaimableBalances
 	QData
 	QEffects
+	QFilterRules
 	QLedgers
 	QLiquidityPools
 	QHistoryLiquidityPools
+	QKinesisCoinInCirculation
 	QOffers
 	QOperations
 	// QParticipants
@@ -260,6 +263,7 @@ type IngestionQ interface {
 	CreateAssets(ctx context.Context, assets []xdr.Asset, batchSize int) (map[string]Asset, error)
 	QTransactions
 	QTrustLines
+	QWebhooks
 
 	Begin() error
 	BeginTx(*sql.TxOptions) error
@@ -454,18 +458,18 @@ type QCreateAccountsHistory interface {
 
 type KinesisCoinInCirculation struct {
 	TxDate      string `db:"tx_date"`
-	Circulation string `db:"circulation"`
-	Mint        string `db:"mint"`
-	Redemption  string `db:"redemption"`
+	Circulation int64  `db:"circulation"`
+	Mint        int64  `db:"mint"`
+	Redemption  int64  `db:"redemption"`
 	Ledger      uint32 `db:"ledger"`
 }
 
 type KinesisCoinInCirculationByLedger struct {
 	Timestamp   string `db:"last_ledger_timestamp"`
 	Ledger      uint32 `db:"last_ledger"`
-	Circulation string `db:"circulation"`
-	Mint        string `db:"mint"`
-	Redemption  string `db:"redemption"`
+	Circulation int64  `db:"circulation"`
+	Mint        int64  `db:"mint"`
+	Redemption  int64  `db:"redemption"`
 }
 
 // Effect is a row of data from the `history_effects` table
@@ -517,41 +521,56 @@ type EffectType int
 // FeeStats is a row of data from the min, mode, percentile aggregate functions over the
 // `history_transactions` table.
 type FeeStats struct {
-	FeeChargedMax  null.Int `db:"fee_charged_max"`
-	FeeChargedMin  null.Int `db:"fee_charged_min"`
-	FeeChargedMode null.Int `db:"fee_charged_mode"`
-	FeeChargedP10  null.Int `db:"fee_charged_p10"`
-	FeeChargedP20  null.Int `db:"fee_charged_p20"`
-	FeeChargedP30  null.Int `db:"fee_charged_p30"`
-	FeeChargedP40  null.Int `db:"fee_charged_p40"`
-	FeeChargedP50  null.Int `db:"fee_charged_p50"`
-	FeeChargedP60  null.Int `db:"fee_charged_p60"`
-	FeeChargedP70  null.Int `db:"fee_charged_p70"`
-	FeeChargedP80  null.Int `db:"fee_charged_p80"`
-	FeeChargedP90  null.Int `db:"fee_charged_p90"`
-	FeeChargedP95  null.Int `db:"fee_charged_p95"`
-	FeeChargedP99  null.Int `db:"fee_charged_p99"`
-	MaxFeeMax      null.Int `db:"max_fee_max"`
-	MaxFeeMin      null.Int `db:"max_fee_min"`
-	MaxFeeMode     null.Int `db:"max_fee_mode"`
-	MaxFeeP10      null.Int `db:"max_fee_p10"`
-	MaxFeeP20      null.Int `db:"max_fee_p20"`
-	MaxFeeP30      null.Int `db:"max_fee_p30"`
-	MaxFeeP40      null.Int `db:"max_fee_p40"`
-	MaxFeeP50      null.Int `db:"max_fee_p50"`
-	MaxFeeP60      null.Int `db:"max_fee_p60"`
-	MaxFeeP70      null.Int `db:"max_fee_p70"`
-	MaxFeeP80      null.Int `db:"max_fee_p80"`
-	MaxFeeP90      null.Int `db:"max_fee_p90"`
-	MaxFeeP95      null.Int `db:"max_fee_p95"`
-	MaxFeeP99      null.Int `db:"max_fee_p99"`
+	FeeChargedMax            null.Int `db:"fee_charged_max"`
+	FeeChargedMin            null.Int `db:"fee_charged_min"`
+	FeeChargedMode           null.Int `db:"fee_charged_mode"`
+	FeeChargedP10            null.Int `db:"fee_charged_p10"`
+	FeeChargedP20            null.Int `db:"fee_charged_p20"`
+	FeeChargedP30            null.Int `db:"fee_charged_p30"`
+	FeeChargedP40            null.Int `db:"fee_charged_p40"`
+	FeeChargedP50            null.Int `db:"fee_charged_p50"`
+	FeeChargedP60            null.Int `db:"fee_charged_p60"`
+	FeeChargedP70            null.Int `db:"fee_charged_p70"`
+	FeeChargedP80            null.Int `db:"fee_charged_p80"`
+	FeeChargedP90            null.Int `db:"fee_charged_p90"`
+	FeeChargedP95            null.Int `db:"fee_charged_p95"`
+	FeeChargedP99            null.Int `db:"fee_charged_p99"`
+	MaxFeeMax                null.Int `db:"max_fee_max"`
+	MaxFeeMin                null.Int `db:"max_fee_min"`
+	MaxFeeMode               null.Int `db:"max_fee_mode"`
+	MaxFeeP10                null.Int `db:"max_fee_p10"`
+	MaxFeeP20                null.Int `db:"max_fee_p20"`
+	MaxFeeP30                null.Int `db:"max_fee_p30"`
+	MaxFeeP40                null.Int `db:"max_fee_p40"`
+	MaxFeeP50                null.Int `db:"max_fee_p50"`
+	MaxFeeP60                null.Int `db:"max_fee_p60"`
+	MaxFeeP70                null.Int `db:"max_fee_p70"`
+	MaxFeeP80                null.Int `db:"max_fee_p80"`
+	MaxFeeP90                null.Int `db:"max_fee_p90"`
+	MaxFeeP95                null.Int `db:"max_fee_p95"`
+	MaxFeeP99                null.Int `db:"max_fee_p99"`
+	FeeChargedPercentageMax  null.Int `db:"fee_charged_percentage_max"`
+	FeeChargedPercentageMin  null.Int `db:"fee_charged_percentage_min"`
+	FeeChargedPercentageMode null.Int `db:"fee_charged_percentage_mode"`
+	FeeChargedPercentageP10  null.Int `db:"fee_charged_percentage_p10"`
+	FeeChargedPercentageP20  null.Int `db:"fee_charged_percentage_p20"`
+	FeeChargedPercentageP30  null.Int `db:"fee_charged_percentage_p30"`
+	FeeChargedPercentageP40  null.Int `db:"fee_charged_percentage_p40"`
+	FeeChargedPercentageP50  null.Int `db:"fee_charged_percentage_p50"`
+	FeeChargedPercentageP60  null.Int `db:"fee_charged_percentage_p60"`
+	FeeChargedPercentageP70  null.Int `db:"fee_charged_percentage_p70"`
+	FeeChargedPercentageP80  null.Int `db:"fee_charged_percentage_p80"`
+	FeeChargedPercentageP90  null.Int `db:"fee_charged_percentage_p90"`
+	FeeChargedPercentageP95  null.Int `db:"fee_charged_percentage_p95"`
+	FeeChargedPercentageP99  null.Int `db:"fee_charged_percentage_p99"`
 }
 
 // LatestLedger represents a response from the raw LatestLedgerBaseFeeAndSequence
 // query.
 type LatestLedger struct {
-	BaseFee  int32 `db:"base_fee"`
-	Sequence int32 `db:"sequence"`
+	BaseFee           int32 `db:"base_fee"`
+	BasePercentageFee int32 `db:"base_percentage_fee"`
+	Sequence          int32 `db:"sequence"`
 }
 
 // Ledger is a row of data from the `history_ledgers` table
@@ -578,6 +597,7 @@ type Ledger struct {
 	ProtocolVersion            int32       `db:"protocol_version"`
 	LedgerHeaderXDR            null.String `db:"ledger_header"`
 	MaxFee                     int64       `db:"max_fee"`
+	FilterVersion              null.Int    `db:"filter_version"`
 }
 
 // LedgerCapacityUsageStats contains ledgers fullness stats.
@@ -806,11 +826,11 @@ func (q *Q) LatestLedgerSequenceClosedAt(ctx context.Context) (int32, time.Time,
 	return ledger.Sequence, ledger.ClosedAt, err
 }
 
-// LatestLedgerBaseFeeAndSequence loads the latest known ledger's base fee and
-// sequence number.
+// LatestLedgerBaseFeeAndSequence loads the latest known ledger's base fee, base
+// percentage fee and sequence number.
 func (q *Q) LatestLedgerBaseFeeAndSequence(ctx context.Context, dest interface{}) error {
 	return q.GetRaw(ctx, dest, `
-		SELECT base_fee, sequence
+		SELECT base_fee, base_percentage_fee, sequence
 		FROM history_ledgers
 		WHERE sequence = (SELECT COALESCE(MAX(sequence), 0) FROM history_ledgers)
 	`)
@@ -843,6 +863,14 @@ func (q *Q) DeleteRangeAll(ctx context.Context, start, end int64) error {
 			return errors.Wrapf(err, "Error clearing %s", table)
 		}
 	}
+	// the snapshots of the balances are kept, they are moved forward by
+	// RebaseAccountBalances before the history they depend on is removed.
+	_, err := q.Exec(ctx, sq.Delete("history_account_balances").
+		Where("history_ledger_id >= ? AND history_ledger_id < ?", start, end).
+		Where(sq.Eq{"snapshot": false}))
+	if err != nil {
+		return errors.Wrap(err, "Error clearing history_account_balances")
+	}
 	return nil
 }
 
@@ -0,0 +1,27 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQAccountBalances struct {
+	mock.Mock
+}
+
+func (m *MockQAccountBalances) DeleteAccountBalancesLedger(ctx context.Context, sequence uint32) (int64, error) {
+	a := m.Called(ctx, sequence)
+	return a.Get(0).(int64), a.Error(1)
+}
+
+func (m *MockQAccountBalances) InsertAccountBalances(ctx context.Context, balances []AccountBalance, batchSize int) error {
+	a := m.Called(ctx, balances, batchSize)
+	return a.Error(0)
+}
+
+func (m *MockQAccountBalances) SnapshotAccountBalances(ctx context.Context, sequence uint32, closedAt time.Time) error {
+	a := m.Called(ctx, sequence, closedAt)
+	return a.Error(0)
+}
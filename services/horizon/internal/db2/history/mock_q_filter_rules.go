@@ -0,0 +1,27 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQFilterRules struct {
+	mock.Mock
+}
+
+func (m *MockQFilterRules) GetFilterRules(ctx context.Context) ([]FilterRule, error) {
+	a := m.Called(ctx)
+	return a.Get(0).([]FilterRule), a.Error(1)
+}
+
+func (m *MockQFilterRules) GetFilterRule(ctx context.Context, name string) (FilterRule, error) {
+	a := m.Called(ctx, name)
+	return a.Get(0).(FilterRule), a.Error(1)
+}
+
+func (m *MockQFilterRules) UpsertFilterRule(ctx context.Context, name string, enabled bool, rules json.RawMessage) (FilterRule, error) {
+	a := m.Called(ctx, name, enabled, rules)
+	return a.Get(0).(FilterRule), a.Error(1)
+}
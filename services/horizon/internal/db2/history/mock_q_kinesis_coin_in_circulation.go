@@ -0,0 +1,26 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQKinesisCoinInCirculation struct {
+	mock.Mock
+}
+
+func (m *MockQKinesisCoinInCirculation) DeleteKinesisCoinInCirculationLedger(ctx context.Context, sequence uint32) (int64, error) {
+	a := m.Called(ctx, sequence)
+	return a.Get(0).(int64), a.Error(1)
+}
+
+func (m *MockQKinesisCoinInCirculation) InsertKinesisCoinInCirculationLedger(ctx context.Context, row KinesisCoinInCirculationLedger) error {
+	a := m.Called(ctx, row)
+	return a.Error(0)
+}
+
+func (m *MockQKinesisCoinInCirculation) RebuildKinesisCoinInCirculation(ctx context.Context, fromLedger uint32) error {
+	a := m.Called(ctx, fromLedger)
+	return a.Error(0)
+}
@@ -19,7 +19,8 @@ func (m *MockQLedgers) InsertLedger(ctx context.Context,
 	opCount int,
 	txSetOpCount int,
 	ingestVersion int,
+	filterVersion int64,
 ) (int64, error) {
-	a := m.Called(ctx, ledger, successTxsCount, failedTxsCount, opCount, txSetOpCount, ingestVersion)
+	a := m.Called(ctx, ledger, successTxsCount, failedTxsCount, opCount, txSetOpCount, ingestVersion, filterVersion)
 	return a.Get(0).(int64), a.Error(1)
 }
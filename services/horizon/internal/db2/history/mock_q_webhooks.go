@@ -0,0 +1,21 @@
+package history
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQWebhooks struct {
+	mock.Mock
+}
+
+func (m *MockQWebhooks) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	a := m.Called(ctx)
+	return a.Get(0).([]Webhook), a.Error(1)
+}
+
+func (m *MockQWebhooks) InsertWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error {
+	a := m.Called(ctx, deliveries)
+	return a.Error(0)
+}
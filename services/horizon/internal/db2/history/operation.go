@@ -60,6 +60,7 @@ func preprocessDetails(details string) ([]byte, error) {
 
 var feeStatsQueryTemplate = template.Must(template.New("trade_aggregations_query").Parse(`
 {{define "operation_count"}}(CASE WHEN new_max_fee IS NULL THEN operation_count ELSE operation_count + 1 END){{end}}
+{{define "fee_charged_percentage"}}ceil(fee_charged * 10000.0 / NULLIF(transferred_amount, 0)){{end}}
 SELECT
 	{{range .}}
 	ceil(percentile_disc(0.{{ . }}) WITHIN GROUP (ORDER BY fee_charged/{{template "operation_count"}}))::bigint AS "fee_charged_p{{ . }}",
@@ -73,12 +74,21 @@ SELECT
 	{{end}}
 	ceil(max(COALESCE(new_max_fee, max_fee)/{{template "operation_count"}}))::bigint AS "max_fee_max",
 	ceil(min(COALESCE(new_max_fee, max_fee)/{{template "operation_count"}}))::bigint AS "max_fee_min",
-	ceil(mode() within group (order by COALESCE(new_max_fee, max_fee)/{{template "operation_count"}}))::bigint AS "max_fee_mode"
+	ceil(mode() within group (order by COALESCE(new_max_fee, max_fee)/{{template "operation_count"}}))::bigint AS "max_fee_mode",
+
+	{{range .}}
+	ceil(percentile_disc(0.{{ . }}) WITHIN GROUP (ORDER BY {{template "fee_charged_percentage"}}))::bigint AS "fee_charged_percentage_p{{ . }}",
+	{{end}}
+	ceil(max({{template "fee_charged_percentage"}}))::bigint AS "fee_charged_percentage_max",
+	ceil(min({{template "fee_charged_percentage"}}))::bigint AS "fee_charged_percentage_min",
+	ceil(mode() within group (order by {{template "fee_charged_percentage"}}))::bigint AS "fee_charged_percentage_mode"
 FROM history_transactions
 WHERE ledger_sequence > $1 AND ledger_sequence <= $2`))
 
 // FeeStats returns operation fee stats for the last 5 ledgers.
 // Currently, we hard code the query to return the last 5 ledgers worth of transactions.
+// The fee charged percentage stats are expressed in basis points of the native amount
+// transferred by each transaction and ignore transactions which transferred nothing.
 // TODO: make the number of ledgers configurable.
 func (q *Q) FeeStats(ctx context.Context, currentSeq int32, dest *FeeStats) error {
 	percentiles := []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99}
@@ -106,6 +116,29 @@ func (q *Q) Operations() *OperationsQ {
 	return query
 }
 
+// OperationsByIDs fetches the operations which match the given ids, by id.
+func (q *Q) OperationsByIDs(ctx context.Context, ids ...int64) (map[int64]Operation, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("no id arguments provided")
+	}
+
+	sql := selectOperation.Where(map[string]interface{}{
+		"hop.id": ids,
+	})
+
+	var operations []Operation
+	if err := q.Select(ctx, &operations, sql); err != nil {
+		return nil, err
+	}
+
+	byID := map[int64]Operation{}
+	for _, operation := range operations {
+		byID[operation.ID] = operation
+	}
+
+	return byID, nil
+}
+
 // OperationByID returns an Operation and optionally a Transaction given an operation id
 func (q *Q) OperationByID(ctx context.Context, includeTransactions bool, id int64) (Operation, *Transaction, error) {
 	sql := selectOperation.
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// RateLimitAccount is a row of data from the `rate_limit_accounts` table with
+// the hashes of its API keys from the `rate_limit_api_keys` table.
+type RateLimitAccount struct {
+	Name                        string         `db:"name"`
+	PerHourRateLimit            int32          `db:"per_hour_rate_limit"`
+	PerHourStreamRateLimit      int32          `db:"per_hour_stream_rate_limit"`
+	PerHourPathFindingRateLimit int32          `db:"per_hour_path_finding_rate_limit"`
+	MaxBurst                    int32          `db:"max_burst"`
+	KeyHashes                   pq.StringArray `db:"key_hashes"`
+}
+
+// QRateLimitAccounts defines rate limit accounts related queries.
+type QRateLimitAccounts interface {
+	GetRateLimitAccounts(ctx context.Context) ([]RateLimitAccount, error)
+	UpsertRateLimitAccount(ctx context.Context, account RateLimitAccount) error
+	InsertRateLimitAPIKey(ctx context.Context, account, keyHash string) error
+}
+
+// GetRateLimitAccounts returns all the rate limit accounts with the hashes of
+// their API keys.
+func (q *Q) GetRateLimitAccounts(ctx context.Context) ([]RateLimitAccount, error) {
+	var accounts []RateLimitAccount
+	sql := sq.Select(
+		"a.name",
+		"a.per_hour_rate_limit",
+		"a.per_hour_stream_rate_limit",
+		"a.per_hour_path_finding_rate_limit",
+		"a.max_burst",
+		"COALESCE(array_agg(k.key_hash ORDER BY k.key_hash) FILTER (WHERE k.key_hash IS NOT NULL), '{}') AS key_hashes",
+	).
+		From("rate_limit_accounts a").
+		LeftJoin("rate_limit_api_keys k ON k.account = a.name").
+		GroupBy("a.name").
+		OrderBy("a.name")
+	if err := q.Select(ctx, &accounts, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select rate limit accounts")
+	}
+	return accounts, nil
+}
+
+// UpsertRateLimitAccount creates a rate limit account or updates its limits.
+// The KeyHashes of the account are ignored, see InsertRateLimitAPIKey.
+func (q *Q) UpsertRateLimitAccount(ctx context.Context, account RateLimitAccount) error {
+	sql := sq.Insert("rate_limit_accounts").
+		Columns(
+			"name",
+			"per_hour_rate_limit",
+			"per_hour_stream_rate_limit",
+			"per_hour_path_finding_rate_limit",
+			"max_burst",
+		).
+		Values(
+			account.Name,
+			account.PerHourRateLimit,
+			account.PerHourStreamRateLimit,
+			account.PerHourPathFindingRateLimit,
+			account.MaxBurst,
+		).
+		Suffix(`ON CONFLICT (name) DO UPDATE SET
+			per_hour_rate_limit = excluded.per_hour_rate_limit,
+			per_hour_stream_rate_limit = excluded.per_hour_stream_rate_limit,
+			per_hour_path_finding_rate_limit = excluded.per_hour_path_finding_rate_limit,
+			max_burst = excluded.max_burst`)
+	if _, err := q.Exec(ctx, sql); err != nil {
+		return errors.Wrapf(err, "could not upsert rate limit account %s", account.Name)
+	}
+	return nil
+}
+
+// InsertRateLimitAPIKey adds the API key with the given hash to an account.
+func (q *Q) InsertRateLimitAPIKey(ctx context.Context, account, keyHash string) error {
+	sql := sq.Insert("rate_limit_api_keys").
+		Columns("key_hash", "account").
+		Values(keyHash, account)
+	if _, err := q.Exec(ctx, sql); err != nil {
+		return errors.Wrapf(err, "could not insert API key of rate limit account %s", account)
+	}
+	return nil
+}
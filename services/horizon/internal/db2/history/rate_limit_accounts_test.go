@@ -0,0 +1,54 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestRateLimitAccounts(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	accounts, err := q.GetRateLimitAccounts(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Empty(accounts)
+
+	tt.Assert.NoError(q.UpsertRateLimitAccount(tt.Ctx, RateLimitAccount{
+		Name:             "partner-a",
+		PerHourRateLimit: 1000,
+		MaxBurst:         100,
+	}))
+	tt.Assert.NoError(q.UpsertRateLimitAccount(tt.Ctx, RateLimitAccount{
+		Name:             "partner-b",
+		PerHourRateLimit: 10,
+		MaxBurst:         1,
+	}))
+	tt.Assert.NoError(q.InsertRateLimitAPIKey(tt.Ctx, "partner-a", "bb"))
+	tt.Assert.NoError(q.InsertRateLimitAPIKey(tt.Ctx, "partner-a", "aa"))
+	tt.Assert.Error(q.InsertRateLimitAPIKey(tt.Ctx, "unknown", "cc"))
+
+	tt.Assert.NoError(q.UpsertRateLimitAccount(tt.Ctx, RateLimitAccount{
+		Name:                        "partner-a",
+		PerHourRateLimit:            2000,
+		PerHourStreamRateLimit:      500,
+		PerHourPathFindingRateLimit: 50,
+		MaxBurst:                    10,
+	}))
+
+	accounts, err = q.GetRateLimitAccounts(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(accounts, 2)
+	tt.Assert.Equal(RateLimitAccount{
+		Name:                        "partner-a",
+		PerHourRateLimit:            2000,
+		PerHourStreamRateLimit:      500,
+		PerHourPathFindingRateLimit: 50,
+		MaxBurst:                    10,
+		KeyHashes:                   []string{"aa", "bb"},
+	}, accounts[0])
+	tt.Assert.Equal("partner-b", accounts[1].Name)
+	tt.Assert.Empty(accounts[1].KeyHashes)
+}
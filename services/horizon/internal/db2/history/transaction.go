@@ -256,6 +256,7 @@ var selectTransaction = sq.Select(
 		"ht.fee_account, " +
 		"ht.fee_account_muxed, " +
 		"ht.new_max_fee, " +
-		"ht.inner_signatures").
+		"ht.inner_signatures, " +
+		"ht.transferred_amount").
 	From("history_transactions ht").
 	LeftJoin("history_ledgers hl ON ht.ledger_sequence = hl.sequence")
@@ -202,6 +202,57 @@ func memo(transaction ingest.LedgerTransaction) null.String {
 	return null.NewString(value, valid)
 }
 
+// transferredAmount returns the native amount moved by the payment, create
+// account, path payment and account merge operations of a successful
+// transaction. This is the amount a Kinesis network charges its percentage fee
+// on. It returns null when the transaction failed or did not move any native
+// amount.
+func transferredAmount(transaction ingest.LedgerTransaction) null.Int {
+	if !transaction.Result.Successful() {
+		return null.Int{}
+	}
+	results, ok := transaction.Result.OperationResults()
+	if !ok {
+		return null.Int{}
+	}
+
+	var total int64
+	for i, op := range transaction.Envelope.Operations() {
+		if i >= len(results) || results[i].Tr == nil {
+			continue
+		}
+		result := results[i].MustTr()
+		switch op.Body.Type {
+		case xdr.OperationTypePayment:
+			payment := op.Body.MustPaymentOp()
+			if payment.Asset.Type == xdr.AssetTypeAssetTypeNative {
+				total += int64(payment.Amount)
+			}
+		case xdr.OperationTypeCreateAccount:
+			total += int64(op.Body.MustCreateAccountOp().StartingBalance)
+		case xdr.OperationTypePathPaymentStrictReceive:
+			if op.Body.MustPathPaymentStrictReceiveOp().SendAsset.Type == xdr.AssetTypeAssetTypeNative {
+				pathResult := result.MustPathPaymentStrictReceiveResult()
+				total += int64(pathResult.SendAmount())
+			}
+		case xdr.OperationTypePathPaymentStrictSend:
+			pathPayment := op.Body.MustPathPaymentStrictSendOp()
+			if pathPayment.SendAsset.Type == xdr.AssetTypeAssetTypeNative {
+				total += int64(pathPayment.SendAmount)
+			}
+		case xdr.OperationTypeAccountMerge:
+			if balance := result.MustAccountMergeResult().SourceAccountBalance; balance != nil {
+				total += int64(*balance)
+			}
+		}
+	}
+
+	if total == 0 {
+		return null.Int{}
+	}
+	return null.IntFrom(total)
+}
+
 type TransactionWithoutLedger struct {
 	TotalOrderID
 	TransactionHash      string         `db:"transaction_hash"`
@@ -229,6 +280,7 @@ type TransactionWithoutLedger struct {
 	InnerTransactionHash null.String    `db:"inner_transaction_hash"`
 	NewMaxFee            null.Int       `db:"new_max_fee"`
 	InnerSignatures      pq.StringArray `db:"inner_signatures"`
+	TransferredAmount    null.Int       `db:"transferred_amount"`
 }
 
 func (i *transactionBatchInsertBuilder) transactionToRow(transaction ingest.LedgerTransaction, sequence uint32) (TransactionWithoutLedger, error) {
@@ -256,25 +308,26 @@ func (i *transactionBatchInsertBuilder) transactionToRow(transaction ingest.Ledg
 		accountMuxed = null.StringFrom(source.Address())
 	}
 	t := TransactionWithoutLedger{
-		TransactionHash:  hex.EncodeToString(transaction.Result.TransactionHash[:]),
-		LedgerSequence:   int32(sequence),
-		ApplicationOrder: int32(transaction.Index),
-		Account:          account.Address(),
-		AccountMuxed:     accountMuxed,
-		AccountSequence:  strconv.FormatInt(transaction.Envelope.SeqNum(), 10),
-		MaxFee:           int64(transaction.Envelope.Fee()),
-		FeeCharged:       int64(transaction.Result.Result.FeeCharged),
-		OperationCount:   int32(len(transaction.Envelope.Operations())),
-		TxEnvelope:       envelopeBase64,
-		TxResult:         resultBase64,
-		TxMeta:           metaBase64,
-		TxFeeMeta:        feeMetaBase64,
-		TimeBounds:       formatTimeBounds(transaction),
-		MemoType:         memoType(transaction),
-		Memo:             memo(transaction),
-		CreatedAt:        time.Now().UTC(),
-		UpdatedAt:        time.Now().UTC(),
-		Successful:       transaction.Result.Successful(),
+		TransactionHash:   hex.EncodeToString(transaction.Result.TransactionHash[:]),
+		LedgerSequence:    int32(sequence),
+		ApplicationOrder:  int32(transaction.Index),
+		Account:           account.Address(),
+		AccountMuxed:      accountMuxed,
+		AccountSequence:   strconv.FormatInt(transaction.Envelope.SeqNum(), 10),
+		MaxFee:            int64(transaction.Envelope.Fee()),
+		FeeCharged:        int64(transaction.Result.Result.FeeCharged),
+		OperationCount:    int32(len(transaction.Envelope.Operations())),
+		TxEnvelope:        envelopeBase64,
+		TxResult:          resultBase64,
+		TxMeta:            metaBase64,
+		TxFeeMeta:         feeMetaBase64,
+		TimeBounds:        formatTimeBounds(transaction),
+		MemoType:          memoType(transaction),
+		Memo:              memo(transaction),
+		CreatedAt:         time.Now().UTC(),
+		UpdatedAt:         time.Now().UTC(),
+		Successful:        transaction.Result.Successful(),
+		TransferredAmount: transferredAmount(transaction),
 	}
 	t.TotalOrderID.ID = toid.New(int32(sequence), int32(transaction.Index), 0).ToInt64()
 
@@ -3,6 +3,7 @@ package history
 import (
 	"testing"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
@@ -180,3 +181,112 @@ func TestTransactionToMap_SourceMuxedAndFeeSourceUnmuxed(t *testing.T) {
 
 	assert.False(t, row.FeeAccountMuxed.Valid)
 }
+
+func TestTransactionToMap_transferredAmount(t *testing.T) {
+	source := xdr.MustMuxedAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	issuer := xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	mergedBalance := xdr.Int64(500)
+	tx := ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source,
+					Operations: []xdr.Operation{
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypePayment,
+								PaymentOp: &xdr.PaymentOp{
+									Destination: source,
+									Asset:       xdr.Asset{Type: xdr.AssetTypeAssetTypeNative},
+									Amount:      100,
+								},
+							},
+						},
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypePayment,
+								PaymentOp: &xdr.PaymentOp{
+									Destination: source,
+									Asset:       xdr.MustNewCreditAsset("KAU", issuer.Address()),
+									Amount:      1000,
+								},
+							},
+						},
+						{
+							Body: xdr.OperationBody{
+								Type: xdr.OperationTypeCreateAccount,
+								CreateAccountOp: &xdr.CreateAccountOp{
+									Destination:     issuer,
+									StartingBalance: 20,
+								},
+							},
+						},
+						{
+							Body: xdr.OperationBody{
+								Type:        xdr.OperationTypeAccountMerge,
+								Destination: &source,
+							},
+						},
+					},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			TransactionHash: xdr.Hash{1, 2, 3},
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code: xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{
+						{
+							Tr: &xdr.OperationResultTr{
+								Type:          xdr.OperationTypePayment,
+								PaymentResult: &xdr.PaymentResult{},
+							},
+						},
+						{
+							Tr: &xdr.OperationResultTr{
+								Type:          xdr.OperationTypePayment,
+								PaymentResult: &xdr.PaymentResult{},
+							},
+						},
+						{
+							Tr: &xdr.OperationResultTr{
+								Type:                xdr.OperationTypeCreateAccount,
+								CreateAccountResult: &xdr.CreateAccountResult{},
+							},
+						},
+						{
+							Tr: &xdr.OperationResultTr{
+								Type: xdr.OperationTypeAccountMerge,
+								AccountMergeResult: &xdr.AccountMergeResult{
+									SourceAccountBalance: &mergedBalance,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{
+			V:          1,
+			Operations: &[]xdr.OperationMeta{},
+			V1: &xdr.TransactionMetaV1{
+				TxChanges:  []xdr.LedgerEntryChange{},
+				Operations: []xdr.OperationMeta{},
+			},
+		},
+	}
+	b := &transactionBatchInsertBuilder{
+		encodingBuffer: xdr.NewEncodingBuffer(),
+	}
+	row, err := b.transactionToRow(tx, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, null.IntFrom(620), row.TransferredAmount)
+
+	tx.Result.Result.Result.Code = xdr.TransactionResultCodeTxFailed
+	row, err = b.transactionToRow(tx, 20)
+	assert.NoError(t, err)
+	assert.False(t, row.TransferredAmount.Valid)
+}
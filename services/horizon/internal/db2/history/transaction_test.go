@@ -51,7 +51,7 @@ func TestTransactionByLiquidityPool(t *testing.T) {
 				CloseTime: xdr.TimePoint(ledgerCloseTime),
 			},
 		},
-	}, 0, 0, 0, 0, 0)
+	}, 0, 0, 0, 0, 0, 0)
 	tt.Assert.NoError(err)
 
 	// Insert a phony transaction
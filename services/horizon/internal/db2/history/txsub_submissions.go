@@ -0,0 +1,153 @@
+package history
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/stellar/go/support/errors"
+)
+
+// Statuses of the transaction submissions.
+const (
+	// TxSubmissionPending is the status of a submission accepted by
+	// stellar-core which was not ingested yet.
+	TxSubmissionPending = "pending"
+	// TxSubmissionSuccess is the status of a submission ingested as a
+	// successful transaction.
+	TxSubmissionSuccess = "success"
+	// TxSubmissionFailed is the status of a submission ingested as a failed
+	// transaction.
+	TxSubmissionFailed = "failed"
+	// TxSubmissionRejected is the status of a submission which was not
+	// accepted by stellar-core.
+	TxSubmissionRejected = "rejected"
+	// TxSubmissionExpired is the status of a pending submission which was not
+	// ingested before the submission timeout.
+	TxSubmissionExpired = "expired"
+)
+
+// TxSubmission is a row of data from the `txsub_submissions` table.
+type TxSubmission struct {
+	TransactionHash    string      `db:"transaction_hash"`
+	EnvelopeXDR        string      `db:"envelope_xdr"`
+	SubmittedAt        time.Time   `db:"submitted_at"`
+	CoreStatus         string      `db:"core_status"`
+	CoreErrorResultXDR null.String `db:"core_error_result_xdr"`
+	Status             string      `db:"status"`
+	LedgerSequence     null.Int    `db:"ledger_sequence"`
+	ResultXDR          null.String `db:"result_xdr"`
+	FinishedAt         null.Time   `db:"finished_at"`
+}
+
+var selectTxSubmissions = sq.Select(
+	"transaction_hash",
+	"envelope_xdr",
+	"submitted_at",
+	"core_status",
+	"core_error_result_xdr",
+	"status",
+	"ledger_sequence",
+	"result_xdr",
+	"finished_at",
+).From("txsub_submissions")
+
+// UpsertTxSubmission records a submission to stellar-core. A submission of
+// a transaction which is already recorded replaces it, unless the
+// transaction was ingested.
+func (q *Q) UpsertTxSubmission(ctx context.Context, submission TxSubmission) error {
+	sql := sq.Insert("txsub_submissions").
+		SetMap(map[string]interface{}{
+			"transaction_hash":      submission.TransactionHash,
+			"envelope_xdr":          submission.EnvelopeXDR,
+			"submitted_at":          submission.SubmittedAt.UTC(),
+			"core_status":           submission.CoreStatus,
+			"core_error_result_xdr": submission.CoreErrorResultXDR,
+			"status":                submission.Status,
+			"ledger_sequence":       submission.LedgerSequence,
+			"result_xdr":            submission.ResultXDR,
+			"finished_at":           submission.FinishedAt,
+		}).
+		Suffix(`ON CONFLICT (transaction_hash) DO UPDATE SET
+			envelope_xdr = EXCLUDED.envelope_xdr,
+			submitted_at = EXCLUDED.submitted_at,
+			core_status = EXCLUDED.core_status,
+			core_error_result_xdr = EXCLUDED.core_error_result_xdr,
+			status = EXCLUDED.status,
+			ledger_sequence = EXCLUDED.ledger_sequence,
+			result_xdr = EXCLUDED.result_xdr,
+			finished_at = EXCLUDED.finished_at
+		WHERE txsub_submissions.status NOT IN (?, ?)`, TxSubmissionSuccess, TxSubmissionFailed)
+	if _, err := q.Exec(ctx, sql); err != nil {
+		return errors.Wrap(err, "could not upsert transaction submission")
+	}
+	return nil
+}
+
+// GetTxSubmission returns the submission of the transaction with the given
+// hash. sql.ErrNoRows is returned when it does not exist.
+func (q *Q) GetTxSubmission(ctx context.Context, hash string) (TxSubmission, error) {
+	var submission TxSubmission
+	err := q.Get(ctx, &submission, selectTxSubmissions.Where("transaction_hash = ?", hash))
+	return submission, err
+}
+
+// GetPendingTxSubmissions returns the pending submissions, oldest first.
+func (q *Q) GetPendingTxSubmissions(ctx context.Context) ([]TxSubmission, error) {
+	var submissions []TxSubmission
+	sql := selectTxSubmissions.
+		Where("status = ?", TxSubmissionPending).
+		OrderBy("submitted_at")
+	if err := q.Select(ctx, &submissions, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select pending transaction submissions")
+	}
+	return submissions, nil
+}
+
+// FinishTxSubmission sets the final status of a pending submission. It
+// returns the number of submissions updated, which is 0 when the submission
+// was already finished.
+func (q *Q) FinishTxSubmission(ctx context.Context, hash, status string, ledger int32, resultXDR string) (int64, error) {
+	sql := sq.Update("txsub_submissions").
+		SetMap(map[string]interface{}{
+			"status":          status,
+			"ledger_sequence": ledger,
+			"result_xdr":      resultXDR,
+			"finished_at":     time.Now().UTC(),
+		}).
+		Where("transaction_hash = ? AND status = ?", hash, TxSubmissionPending)
+	result, err := q.Exec(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not finish transaction submission")
+	}
+	return result.RowsAffected()
+}
+
+// ExpireTxSubmissions marks the pending submissions submitted before the
+// given time as expired. It returns the number of expired submissions.
+func (q *Q) ExpireTxSubmissions(ctx context.Context, submittedBefore time.Time) (int64, error) {
+	sql := sq.Update("txsub_submissions").
+		SetMap(map[string]interface{}{
+			"status":      TxSubmissionExpired,
+			"finished_at": time.Now().UTC(),
+		}).
+		Where("status = ? AND submitted_at < ?", TxSubmissionPending, submittedBefore.UTC())
+	result, err := q.Exec(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not expire transaction submissions")
+	}
+	return result.RowsAffected()
+}
+
+// DeleteTxSubmissionsFinishedBefore removes the submissions finished before
+// the given time. It returns the number of submissions removed.
+func (q *Q) DeleteTxSubmissionsFinishedBefore(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	sql := sq.Delete("txsub_submissions").
+		Where("status <> ? AND finished_at < ?", TxSubmissionPending, finishedBefore.UTC())
+	result, err := q.Exec(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not delete transaction submissions")
+	}
+	return result.RowsAffected()
+}
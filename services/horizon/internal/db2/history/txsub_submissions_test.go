@@ -0,0 +1,92 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestTxSubmissions(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	first := "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d"
+	second := "aa168f12124b7c196c0adaee7c73a64d37f99428cacb59a91ff389626845e7cf"
+	now := time.Now().UTC()
+
+	_, err := q.GetTxSubmission(tt.Ctx, first)
+	tt.Assert.Equal(sql.ErrNoRows, err)
+
+	tt.Assert.NoError(q.UpsertTxSubmission(tt.Ctx, TxSubmission{
+		TransactionHash: first,
+		EnvelopeXDR:     "AAAA",
+		SubmittedAt:     now.Add(-time.Minute),
+		CoreStatus:      "PENDING",
+		Status:          TxSubmissionPending,
+	}))
+	tt.Assert.NoError(q.UpsertTxSubmission(tt.Ctx, TxSubmission{
+		TransactionHash: second,
+		EnvelopeXDR:     "BBBB",
+		SubmittedAt:     now,
+		CoreStatus:      "PENDING",
+		Status:          TxSubmissionPending,
+	}))
+
+	pending, err := q.GetPendingTxSubmissions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(pending, 2)
+	tt.Assert.Equal(first, pending[0].TransactionHash)
+	tt.Assert.Equal(second, pending[1].TransactionHash)
+
+	updated, err := q.FinishTxSubmission(tt.Ctx, first, TxSubmissionSuccess, 3, "result")
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), updated)
+	updated, err = q.FinishTxSubmission(tt.Ctx, first, TxSubmissionFailed, 4, "result")
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), updated)
+
+	submission, err := q.GetTxSubmission(tt.Ctx, first)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(TxSubmissionSuccess, submission.Status)
+	tt.Assert.Equal(int64(3), submission.LedgerSequence.Int64)
+	tt.Assert.Equal("result", submission.ResultXDR.String)
+	tt.Assert.True(submission.FinishedAt.Valid)
+
+	// ingested submissions are not replaced by a new submission
+	tt.Assert.NoError(q.UpsertTxSubmission(tt.Ctx, TxSubmission{
+		TransactionHash:    first,
+		EnvelopeXDR:        "AAAA",
+		SubmittedAt:        now,
+		CoreStatus:         "ERROR",
+		CoreErrorResultXDR: null.StringFrom("error"),
+		Status:             TxSubmissionRejected,
+		FinishedAt:         null.TimeFrom(now),
+	}))
+	submission, err = q.GetTxSubmission(tt.Ctx, first)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(TxSubmissionSuccess, submission.Status)
+
+	expired, err := q.ExpireTxSubmissions(tt.Ctx, now.Add(-time.Second))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), expired)
+	expired, err = q.ExpireTxSubmissions(tt.Ctx, now.Add(time.Second))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), expired)
+
+	pending, err = q.GetPendingTxSubmissions(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(pending, 0)
+
+	deleted, err := q.DeleteTxSubmissionsFinishedBefore(tt.Ctx, now.Add(-time.Hour))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), deleted)
+	deleted, err = q.DeleteTxSubmissionsFinishedBefore(tt.Ctx, time.Now().Add(time.Hour))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(2), deleted)
+}
@@ -0,0 +1,285 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/guregu/null"
+	"github.com/lib/pq"
+	"github.com/stellar/go/support/errors"
+)
+
+// Statuses of the webhook deliveries.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Types of the events delivered to webhooks.
+const (
+	WebhookEventTypeOperation = "operation"
+	WebhookEventTypeEffect    = "effect"
+)
+
+// webhookDeliveriesBatchSize is the number of deliveries inserted per query.
+const webhookDeliveriesBatchSize = 1000
+
+// Webhook is a row of data from the `webhooks` table. A webhook matches the
+// operations and/or effects, depending on its event types, of successful
+// transactions which satisfy all of its filters, null filters match any
+// operation or effect.
+type Webhook struct {
+	ID            int64          `db:"id"`
+	URL           string         `db:"url"`
+	Secret        string         `db:"secret"`
+	Account       null.String    `db:"account"`
+	Asset         null.String    `db:"asset"`
+	OperationType null.Int       `db:"operation_type"`
+	EventTypes    pq.StringArray `db:"event_types"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
+
+// HasEventType returns true when the webhook is notified of the events of the
+// given type. Webhooks without event types are notified of operations.
+func (w Webhook) HasEventType(eventType string) bool {
+	if len(w.EventTypes) == 0 {
+		return eventType == WebhookEventTypeOperation
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a row of data from the `webhook_deliveries` table.
+type WebhookDelivery struct {
+	ID             int64           `db:"id"`
+	WebhookID      int64           `db:"webhook_id"`
+	LedgerSequence int32           `db:"ledger_sequence"`
+	OperationID    int64           `db:"operation_id"`
+	Payload        json.RawMessage `db:"payload"`
+	Status         string          `db:"status"`
+	Attempts       int32           `db:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at"`
+	LastAttemptAt  null.Time       `db:"last_attempt_at"`
+	LastStatusCode null.Int        `db:"last_status_code"`
+	LastError      null.String     `db:"last_error"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// PendingWebhookDelivery is a delivery claimed by the webhook delivery system
+// together with the target of its webhook.
+type PendingWebhookDelivery struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+// QWebhooks defines webhook related queries used during ingestion.
+type QWebhooks interface {
+	GetWebhooks(ctx context.Context) ([]Webhook, error)
+	InsertWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error
+}
+
+var selectWebhooks = sq.Select(
+	"id",
+	"url",
+	"secret",
+	"account",
+	"asset",
+	"operation_type",
+	"event_types",
+	"created_at",
+).From("webhooks")
+
+var selectWebhookDeliveries = sq.Select(
+	"id",
+	"webhook_id",
+	"ledger_sequence",
+	"operation_id",
+	"payload",
+	"status",
+	"attempts",
+	"next_attempt_at",
+	"last_attempt_at",
+	"last_status_code",
+	"last_error",
+	"created_at",
+).From("webhook_deliveries")
+
+// GetWebhooks returns all the registered webhooks.
+func (q *Q) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if err := q.Select(ctx, &webhooks, selectWebhooks.OrderBy("id")); err != nil {
+		return nil, errors.Wrap(err, "could not select webhooks")
+	}
+	return webhooks, nil
+}
+
+// GetWebhookByID returns the webhook with the given id. sql.ErrNoRows is
+// returned when it does not exist.
+func (q *Q) GetWebhookByID(ctx context.Context, id int64) (Webhook, error) {
+	var webhook Webhook
+	err := q.Get(ctx, &webhook, selectWebhooks.Where("id = ?", id))
+	return webhook, err
+}
+
+// InsertWebhook registers a webhook and returns it with its id. The webhook
+// is notified of operations when it has no event types.
+func (q *Q) InsertWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	eventTypes := webhook.EventTypes
+	if len(eventTypes) == 0 {
+		eventTypes = pq.StringArray{WebhookEventTypeOperation}
+	}
+	sql := sq.Insert("webhooks").
+		SetMap(map[string]interface{}{
+			"url":            webhook.URL,
+			"secret":         webhook.Secret,
+			"account":        webhook.Account,
+			"asset":          webhook.Asset,
+			"operation_type": webhook.OperationType,
+			"event_types":    eventTypes,
+			"created_at":     time.Now().UTC(),
+		}).
+		Suffix("RETURNING id, url, secret, account, asset, operation_type, event_types, created_at")
+	var inserted Webhook
+	if err := q.Get(ctx, &inserted, sql); err != nil {
+		return Webhook{}, errors.Wrap(err, "could not insert webhook")
+	}
+	return inserted, nil
+}
+
+// DeleteWebhook removes the webhook with the given id and its deliveries. It
+// returns the number of webhooks removed.
+func (q *Q) DeleteWebhook(ctx context.Context, id int64) (int64, error) {
+	result, err := q.Exec(ctx, sq.Delete("webhooks").Where("id = ?", id))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not delete webhook")
+	}
+	return result.RowsAffected()
+}
+
+// InsertWebhookDeliveries queues the given deliveries, which are due
+// immediately. The deliveries of webhooks which no longer exist are skipped,
+// since ingestion may still match the webhooks it loaded before they were
+// deleted.
+func (q *Q) InsertWebhookDeliveries(ctx context.Context, deliveries []WebhookDelivery) error {
+	now := time.Now().UTC()
+	for start := 0; start < len(deliveries); start += webhookDeliveriesBatchSize {
+		end := start + webhookDeliveriesBatchSize
+		if end > len(deliveries) {
+			end = len(deliveries)
+		}
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, 3+4*(end-start))
+		args = append(args, WebhookDeliveryPending, now, now)
+		for _, delivery := range deliveries[start:end] {
+			values = append(values, "(?::bigint, ?::integer, ?::bigint, ?::jsonb)")
+			args = append(args,
+				delivery.WebhookID,
+				delivery.LedgerSequence,
+				delivery.OperationID,
+				string(delivery.Payload),
+			)
+		}
+		// locking the webhooks makes a concurrent delete either wait for the
+		// insert or remove the webhook before its deliveries are selected
+		sql := `INSERT INTO webhook_deliveries (
+				webhook_id, ledger_sequence, operation_id, payload, status, next_attempt_at, created_at
+			)
+			SELECT d.webhook_id, d.ledger_sequence, d.operation_id, d.payload, ?::varchar, ?::timestamp, ?::timestamp
+			FROM (VALUES ` + strings.Join(values, ", ") + `) AS d (webhook_id, ledger_sequence, operation_id, payload)
+			JOIN webhooks w ON w.id = d.webhook_id
+			FOR KEY SHARE OF w`
+		if _, err := q.ExecRaw(ctx, sql, args...); err != nil {
+			return errors.Wrap(err, "could not insert webhook deliveries")
+		}
+	}
+	return nil
+}
+
+// WebhookDeliveries returns the deliveries of a webhook, most recent first.
+// Only deliveries with an id lower than cursor are returned when cursor is
+// set, and only deliveries with the given status when status is set.
+func (q *Q) WebhookDeliveries(ctx context.Context, webhookID int64, status string, cursor int64, limit uint64) ([]WebhookDelivery, error) {
+	sql := selectWebhookDeliveries.
+		Where("webhook_id = ?", webhookID).
+		OrderBy("id DESC").
+		Limit(limit)
+	if status != "" {
+		sql = sql.Where("status = ?", status)
+	}
+	if cursor > 0 {
+		sql = sql.Where("id < ?", cursor)
+	}
+
+	var deliveries []WebhookDelivery
+	if err := q.Select(ctx, &deliveries, sql); err != nil {
+		return nil, errors.Wrap(err, "could not select webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries which are due
+// at now and postpones them until leaseUntil, so that they are not claimed
+// again by another Horizon instance while they are sent.
+func (q *Q) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]PendingWebhookDelivery, error) {
+	var deliveries []PendingWebhookDelivery
+	err := q.SelectRaw(ctx, &deliveries, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = $1
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= $2
+				ORDER BY next_attempt_at, id
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT claimed.*, w.url, w.secret
+		FROM claimed JOIN webhooks w ON w.id = claimed.webhook_id
+		ORDER BY claimed.id`,
+		leaseUntil, now, limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not claim webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// DeleteWebhookDeliveriesBefore removes the delivered and failed deliveries
+// created before the given time, pending deliveries are kept until they are
+// delivered or fail. It returns the number of deliveries removed.
+func (q *Q) DeleteWebhookDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	sql := sq.Delete("webhook_deliveries").
+		Where("status <> ?", WebhookDeliveryPending).
+		Where("created_at < ?", before.UTC())
+	result, err := q.Exec(ctx, sql)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not delete webhook deliveries")
+	}
+	return result.RowsAffected()
+}
+
+// UpdateWebhookDelivery stores the outcome of a delivery attempt.
+func (q *Q) UpdateWebhookDelivery(ctx context.Context, delivery WebhookDelivery) error {
+	sql := sq.Update("webhook_deliveries").SetMap(map[string]interface{}{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_attempt_at":  delivery.LastAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+	}).Where("id = ?", delivery.ID)
+	if _, err := q.Exec(ctx, sql); err != nil {
+		return errors.Wrap(err, "could not update webhook delivery")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/services/horizon/internal/test"
+)
+
+func TestWebhooks(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	_, err := q.GetWebhookByID(tt.Ctx, 1)
+	tt.Assert.Equal(sql.ErrNoRows, err)
+
+	first, err := q.InsertWebhook(tt.Ctx, Webhook{
+		URL:     "http://localhost:8001/first",
+		Secret:  "secret",
+		Account: null.StringFrom("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"),
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.NotZero(first.ID)
+	tt.Assert.False(first.Asset.Valid)
+	tt.Assert.Equal([]string{WebhookEventTypeOperation}, []string(first.EventTypes))
+	second, err := q.InsertWebhook(tt.Ctx, Webhook{
+		URL:           "http://localhost:8001/second",
+		Secret:        "secret",
+		OperationType: null.IntFrom(1),
+		EventTypes:    []string{WebhookEventTypeOperation, WebhookEventTypeEffect},
+	})
+	tt.Assert.NoError(err)
+	tt.Assert.True(second.HasEventType(WebhookEventTypeEffect))
+
+	webhooks, err := q.GetWebhooks(tt.Ctx)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(webhooks, 2)
+	tt.Assert.Equal(first.ID, webhooks[0].ID)
+	tt.Assert.Equal(second.ID, webhooks[1].ID)
+	tt.Assert.Equal(int64(1), webhooks[1].OperationType.Int64)
+
+	webhook, err := q.GetWebhookByID(tt.Ctx, second.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(second.URL, webhook.URL)
+
+	deleted, err := q.DeleteWebhook(tt.Ctx, second.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), deleted)
+	deleted, err = q.DeleteWebhook(tt.Ctx, second.ID)
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), deleted)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	tt := test.Start(t)
+	defer tt.Finish()
+	test.ResetHorizonDB(t, tt.HorizonDB)
+	q := &Q{tt.HorizonSession()}
+
+	webhook, err := q.InsertWebhook(tt.Ctx, Webhook{
+		URL:           "http://localhost:8001/webhook",
+		Secret:        "secret",
+		OperationType: null.IntFrom(1),
+	})
+	tt.Assert.NoError(err)
+
+	tt.Assert.NoError(q.InsertWebhookDeliveries(tt.Ctx, []WebhookDelivery{
+		{WebhookID: webhook.ID, LedgerSequence: 10, OperationID: 100, Payload: json.RawMessage(`{"id":1}`)},
+		{WebhookID: webhook.ID, LedgerSequence: 10, OperationID: 101, Payload: json.RawMessage(`{"id":2}`)},
+	}))
+
+	now := time.Now().Add(time.Second)
+	claimed, err := q.ClaimWebhookDeliveries(tt.Ctx, now, now.Add(time.Minute), 1)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(claimed, 1)
+	tt.Assert.Equal(webhook.URL, claimed[0].URL)
+	tt.Assert.Equal(webhook.Secret, claimed[0].Secret)
+	tt.Assert.Equal(int64(100), claimed[0].OperationID)
+	tt.Assert.JSONEq(`{"id":1}`, string(claimed[0].Payload))
+
+	// the claimed delivery is leased
+	claimed2, err := q.ClaimWebhookDeliveries(tt.Ctx, now, now.Add(time.Minute), 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(claimed2, 1)
+	tt.Assert.Equal(int64(101), claimed2[0].OperationID)
+
+	delivery := claimed[0].WebhookDelivery
+	delivery.Status = WebhookDeliveryDelivered
+	delivery.Attempts = 1
+	delivery.LastAttemptAt = null.TimeFrom(now)
+	delivery.LastStatusCode = null.IntFrom(200)
+	tt.Assert.NoError(q.UpdateWebhookDelivery(tt.Ctx, delivery))
+
+	deliveries, err := q.WebhookDeliveries(tt.Ctx, webhook.ID, "", 0, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 2)
+	tt.Assert.Equal(int64(101), deliveries[0].OperationID)
+	tt.Assert.Equal(WebhookDeliveryPending, deliveries[0].Status)
+	tt.Assert.Equal(WebhookDeliveryDelivered, deliveries[1].Status)
+	tt.Assert.Equal(int64(200), deliveries[1].LastStatusCode.Int64)
+
+	deliveries, err = q.WebhookDeliveries(tt.Ctx, webhook.ID, WebhookDeliveryDelivered, 0, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 1)
+	deliveries, err = q.WebhookDeliveries(tt.Ctx, webhook.ID, "", deliveries[0].ID, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 0)
+
+	// only the delivered and failed deliveries expire
+	deleted, err := q.DeleteWebhookDeliveriesBefore(tt.Ctx, time.Now().Add(-time.Hour))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(0), deleted)
+	deleted, err = q.DeleteWebhookDeliveriesBefore(tt.Ctx, time.Now().Add(time.Hour))
+	tt.Assert.NoError(err)
+	tt.Assert.Equal(int64(1), deleted)
+	deliveries, err = q.WebhookDeliveries(tt.Ctx, webhook.ID, "", 0, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 1)
+	tt.Assert.Equal(WebhookDeliveryPending, deliveries[0].Status)
+
+	// deliveries are removed with their webhook
+	_, err = q.DeleteWebhook(tt.Ctx, webhook.ID)
+	tt.Assert.NoError(err)
+	deliveries, err = q.WebhookDeliveries(tt.Ctx, webhook.ID, "", 0, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 0)
+
+	// the deliveries of a deleted webhook are skipped
+	tt.Assert.NoError(q.InsertWebhookDeliveries(tt.Ctx, []WebhookDelivery{
+		{WebhookID: webhook.ID, LedgerSequence: 11, OperationID: 102, Payload: json.RawMessage(`{"id":3}`)},
+	}))
+	deliveries, err = q.WebhookDeliveries(tt.Ctx, webhook.ID, "", 0, 10)
+	tt.Assert.NoError(err)
+	tt.Assert.Len(deliveries, 0)
+}
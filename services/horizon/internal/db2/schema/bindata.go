@@ -52,7 +52,14 @@
 // migrations/54_kinesis_coin_in_circulation.sql (4.528kB)
 // migrations/55_kinesis_coin_in_circulation_v2.sql (5.213kB)
 // migrations/56_kinesis_coin_in_circulation_at_ledger.sql (3.119kB)
+// migrations/57_transactions_transferred_amount.sql (352B)
+// migrations/58_kinesis_coin_in_circulation_tables.sql (1.885kB)
+// migrations/59_ingest_filter_rules.sql (943B)
 // migrations/5_create_trades_table.sql (1.1kB)
+// migrations/60_webhooks.sql (1.754kB)
+// migrations/61_txsub_submissions.sql (1.011kB)
+// migrations/62_rate_limit_accounts.sql (1.09kB)
+// migrations/63_account_balance_history.sql (1.296kB)
 // migrations/6_create_assets_table.sql (366B)
 // migrations/7_modify_trades_table.sql (2.303kB)
 // migrations/8_add_aggregators.sql (907B)
@@ -1166,6 +1173,66 @@ func migrations56_kinesis_coin_in_circulation_at_ledgerSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations57_transactions_transferred_amountSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\xd0\xc1\x6a\x83\x40\x10\xc6\xf1\xfb\x3e\xc5\x77\x6f\xcd\x0b\xe4\x64\x6b\x6e\x36\x29\x21\x9e\xc3\xe8\x8e\xba\xd0\x9d\x91\xdd\x31\xe2\xdb\x97\x26\x3d\x58\x28\xe4\x3e\xfc\xfe\xc3\x57\x14\x78\x89\x61\x48\x64\x8c\x66\x72\xae\x28\x70\x24\x0b\x37\x06\x45\x9d\xc5\x10\xf5\xc6\x1e\xed\x0a\x82\x25\x92\x4c\x9d\x05\x95\x57\xcc\x99\x3d\x4c\xd1\x69\x9c\x66\x63\x4c\x9c\x3a\x16\xa3\x81\xd1\x33\x23\x1b\x59\xde\xdd\xb9\xa6\xae\xb1\x8c\x2c\xb0\x91\xb7\x06\x7c\xf0\x10\x7d\x24\x40\xb2\x42\xfe\x94\x35\x61\xa1\x8c\x20\x03\x67\x63\xff\x63\xb5\xdc\x6b\x62\xd8\x18\x32\x3a\xfd\x9a\xa3\xdc\x4f\xc8\x7b\xf6\x3b\x57\xd6\x97\xc3\x19\x97\xf2\xad\x3e\x60\x0c\xd9\x34\xad\xd7\x4d\x2f\xa3\xac\xaa\xc7\x03\x3d\xa7\xc4\xfe\xfa\x1b\x6a\xc3\x10\xc4\xf6\xce\x6d\xc7\xa8\x74\x11\xf7\x9c\xac\xce\xa7\x4f\xbc\x9f\xea\xe6\xe3\xf8\x0f\xbd\x77\xdf\x03\x00\xed\x81\x0a\xa8\x60\x01\x00\x00")
+
+func migrations57_transactions_transferred_amountSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations57_transactions_transferred_amountSql,
+		"migrations/57_transactions_transferred_amount.sql",
+	)
+}
+
+func migrations57_transactions_transferred_amountSql() (*asset, error) {
+	bytes, err := migrations57_transactions_transferred_amountSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/57_transactions_transferred_amount.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x89, 0x3d, 0xfa, 0x84, 0x90, 0x61, 0x94, 0xdb, 0x67, 0xe, 0xa3, 0xc0, 0xa2, 0x7b, 0x5, 0x66, 0xa2, 0x3e, 0xf0, 0x41, 0x42, 0x4d, 0x9a, 0x78, 0x7e, 0x3b, 0x8a, 0xc4, 0xcc, 0xa1, 0xc7, 0x9}}
+	return a, nil
+}
+
+var _migrations58_kinesis_coin_in_circulation_tablesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x95\xd1\x6f\xda\x30\x10\xc6\xdf\xf3\x57\x7c\x6f\x85\x0d\xaa\x3e\x17\x6d\x12\x2b\xe9\x8a\x06\xa1\xa2\x61\x6b\x9f\x22\x13\x1f\xc1\x22\xb1\x99\xed\xd0\x65\x7f\xfd\x74\x09\x30\x8a\xda\xb5\x54\xda\x13\x91\xed\xfb\x7c\xf7\xdd\xcf\x47\xb7\x8b\x8f\x85\xca\xac\xf0\x84\xd9\x3a\x08\xba\x5d\x8c\x95\xf6\x10\x5a\xc2\x92\xa4\x62\xed\x95\xd1\x10\x85\x29\xb5\x77\x68\x29\x0d\xe7\xad\x31\x6b\xd7\x86\x59\x80\x36\x64\x2b\xe4\x24\x33\xb2\x50\x1a\x8f\x4b\x95\x2e\xe1\x97\xc4\x42\xde\x92\x70\xa5\xad\x20\xd2\xb4\x09\x2f\x94\xf6\x24\x61\x6c\xad\x4d\x05\x49\xa4\x46\x69\xd7\x81\x37\x19\xf9\x25\x59\x3c\x2a\x5f\x0b\xc0\x96\x5a\x2b\x9d\xc1\x1b\x2f\x72\xc7\x7a\xe5\x1a\xde\xd4\x99\x29\x9d\xe6\xa5\xac\x77\x97\xb4\xbd\xfe\x3c\xb8\x9a\x86\xfd\x38\x44\xdc\xff\x32\x0a\xb1\x54\xce\x1b\x5b\x25\x2b\xa5\xc9\x29\x97\xf0\x3d\x89\xd2\x49\xaa\x6c\x5a\xe6\x82\xab\x4a\x9a\x40\x87\x56\x00\x60\x2b\x93\x38\xfa\x59\x92\x4e\x09\x9c\x2b\x97\x15\x4d\x62\x44\xb3\xd1\x08\xb7\xd3\xe1\xb8\x3f\x7d\xc0\xb7\xf0\xa1\x53\x47\xa4\xb9\x71\x24\x13\xe1\xe1\x55\x41\xce\x8b\x62\x5d\xe7\x6f\xca\x66\x05\xbf\x8d\xa6\x7d\x7c\x13\xc3\x16\x60\xae\x32\xfe\x79\xba\x73\x60\xf7\xb3\xfb\xb5\x11\xc9\x73\xf1\x18\x84\xd7\xfd\xd9\x28\xc6\xc5\xe1\xc9\x97\xf5\x8e\xcf\x1f\x78\xf2\xf2\xd1\xa0\xdd\x0b\x76\x16\x0f\xa3\x41\x78\x7f\x8a\xc5\xc9\xbc\x4a\xfe\xba\x35\x89\x4e\x6a\xcf\xec\x6e\x18\x7d\xc5\xdc\x5b\x22\xb4\xf6\x2a\x9c\x4e\xb7\x8b\x81\x50\x79\x05\x91\x65\x96\xb2\xa6\x02\xb3\x38\x45\xfd\x1c\x71\x43\xeb\x71\xfb\x99\xb3\xa7\x0c\x42\x58\xaa\xd1\x34\x9a\x1c\xd3\xcf\xdf\xb9\x70\x7e\x1b\xbb\x5b\x92\xa2\x7a\x07\x8c\x52\x54\x3b\x12\xa5\xa8\x20\xf9\x45\xbe\x8c\xde\x6b\xb0\xfe\x3f\xd8\xde\x86\xd8\xab\x60\xbd\x0f\x27\x36\x89\x59\xda\x1a\xfe\x46\x90\x38\xea\x29\x45\x47\xf6\x6d\x59\x8a\xf7\xc3\xc4\x41\xe9\x8c\x1c\x0f\xab\x39\x2d\x4c\xdd\x77\xc5\xf3\x8b\x27\x25\x57\xc3\x28\xcc\x45\xba\x5a\xa8\x3c\x27\x89\x85\x35\xc5\x2e\x15\xcc\x2b\xe6\x89\x49\x68\x44\x98\x20\xa5\x9d\x17\xdc\x28\xc3\x03\x54\x58\x5f\xae\x3b\xdb\x69\x69\xa9\x30\x1b\x72\xcd\x0d\x2b\xaa\x60\xf8\x9c\x34\x9a\xf6\x6c\xb2\x15\x3c\x5e\x0f\x8a\x02\x69\xb9\x36\x8a\xa7\x2a\xe7\x52\x6a\xb1\x11\x2a\x17\xf3\x9c\xbf\xbd\xca\x19\x54\x7d\x1e\x0c\xa3\xbb\x70\x1a\x63\x18\xc5\x13\xac\xa8\x4a\x36\x22\x2f\x29\x61\xa3\x09\xad\x15\x55\x1d\xd4\x2b\xed\xe0\x2e\x1c\x85\x57\x31\xce\xfe\xe5\xe3\xae\xe0\xc4\x9b\xb3\x0e\xc6\xfd\xfb\xd6\xde\xc0\xcb\x4b\x4f\xbf\x7c\x70\x3d\x9d\x8c\xf7\x2d\xd9\x5a\x19\xdc\xf4\xbf\xb3\xf5\x57\x93\x59\x14\xb7\x3e\xb4\xf1\x19\x17\x8d\xdf\xfb\x7f\x9e\x81\x79\xd4\x41\x30\x08\x47\x61\x1c\xa2\xd6\x38\xce\xf5\xc7\x4d\x38\x0d\xb9\x02\x7c\x7a\x7b\x8e\xbd\x20\x18\x4c\x27\xb7\xa7\x3e\xc2\x54\xb8\x54\x48\xea\x9d\x1a\xbc\x43\x27\x15\x2e\x15\x92\x7a\xc1\x9f\x01\x00\x3c\xb8\x46\x88\x5d\x07\x00\x00")
+
+func migrations58_kinesis_coin_in_circulation_tablesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations58_kinesis_coin_in_circulation_tablesSql,
+		"migrations/58_kinesis_coin_in_circulation_tables.sql",
+	)
+}
+
+func migrations58_kinesis_coin_in_circulation_tablesSql() (*asset, error) {
+	bytes, err := migrations58_kinesis_coin_in_circulation_tablesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/58_kinesis_coin_in_circulation_tables.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xbf, 0xd8, 0xb5, 0x53, 0x31, 0xc0, 0x32, 0xdb, 0x5f, 0xa3, 0x7, 0x24, 0xc7, 0xf4, 0x77, 0x77, 0x6b, 0x29, 0x0, 0x5f, 0x1f, 0xc6, 0x51, 0x3, 0xb9, 0x69, 0x6a, 0x7b, 0xd7, 0x1, 0x90, 0x98}}
+	return a, nil
+}
+
+var _migrations59_ingest_filter_rulesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x93\x41\x6f\xda\x40\x10\x85\xef\xfe\x15\xef\x98\xa8\xc0\xa9\xea\x85\x93\x0b\xae\xd4\xd6\x81\xd4\x31\x95\x72\xb2\x16\x76\xf0\x6e\x63\xef\x92\x9d\x35\xc8\xfd\xf5\xd5\xae\x0d\x29\x51\x1b\xe5\x84\xc4\xbc\xf9\x66\xde\xdb\xf1\x74\x8a\x0f\xad\xae\x9d\xf0\x84\xcd\x21\x49\xa6\x53\x14\x5d\x43\x0c\xbb\x87\x57\x04\x6d\x6a\x62\xaf\xad\xc1\x5e\x37\x9e\x1c\x4f\xf0\x44\x3d\x49\x6c\xfb\x58\x37\xa2\xa5\xb3\x76\x50\xcc\x50\x2a\x82\x0b\x90\x40\x13\x8e\xc0\xde\x3a\x92\x10\x8c\x6f\x0f\xeb\x55\xf8\xf5\x8a\xb4\x03\x2b\x71\x20\x48\x3a\x90\x91\x0c\x6b\xae\x28\x47\x72\x1c\xe6\x6a\x86\x17\x4f\x64\x02\x6c\xef\x6c\x0b\x01\xa6\xe7\x8e\xcc\x8e\x02\x20\x80\xb7\x3d\x44\xd3\xfc\xd5\x1d\x61\x74\x24\xd7\xa3\x3b\x48\xe1\x69\x02\xb6\xf0\x4a\xf8\x20\x0a\x24\xa5\x6b\x45\xec\x5f\xa6\x48\x32\x5e\xef\x35\xc5\xdd\xc0\xe4\x83\xab\xe8\x02\xda\xa0\x63\x9a\x25\x8b\x22\x4b\xcb\x0c\x0f\xd9\x8f\x4d\xb6\x5a\x64\x63\x36\xd5\x60\xbb\x8a\xda\x6a\xe4\x55\x4c\xcf\xf3\xe4\xdc\x51\xa6\x9f\xf3\x7f\xca\x71\x93\x00\x18\x42\xdc\x29\xe1\xc4\xce\x93\xc3\x51\xb8\x5e\x9b\xfa\xe6\xd3\xc7\x5b\xac\xd6\x25\x56\x9b\x3c\xc7\x7d\xf1\xf5\x2e\x2d\x1e\xf1\x3d\x7b\x9c\xc4\x26\x32\x62\xdb\x04\xf3\xd6\x36\x24\xcc\x8b\x72\x99\x7d\x49\x37\x79\x89\xbd\x68\x98\x06\xed\x30\xec\x17\x5b\xb3\xbd\xe8\x86\xca\xd9\xff\x56\xd7\xda\xf8\x57\xc5\x46\xb0\xaf\x5a\x2b\x43\x2e\x12\x5e\xb7\xc4\x5e\xb4\x07\x9c\xb4\x57\xb6\xf3\xf1\x1f\xfc\xb6\x86\x2e\x7d\xc9\xed\x3c\xde\xd0\xcf\x11\x7b\x75\x19\xe3\x1a\x21\x5f\xa5\xc3\x4d\xf4\xe7\xcb\x69\x48\xd6\xe4\x70\x12\x3c\xa6\x44\x32\x0e\x99\x04\x56\xb4\x7f\x52\x64\xde\x94\x86\x7d\xc6\xc7\x9f\x25\x69\x5e\x66\xc5\x18\xfb\x38\xaa\x1a\x1a\x19\xe9\x72\x39\x0a\xab\x6b\xf3\xc3\xe6\x97\xaf\x61\x69\x4f\x26\x79\x93\xb4\x2c\xd6\xf7\x58\xac\xf3\xcd\xdd\xea\x15\x71\x9e\xc4\xda\xff\xdf\x7d\x27\x78\x27\x24\x8d\xba\xf7\x5f\xd4\x9f\x01\x00\x91\x75\x3b\x8a\xaf\x03\x00\x00")
+
+func migrations59_ingest_filter_rulesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations59_ingest_filter_rulesSql,
+		"migrations/59_ingest_filter_rules.sql",
+	)
+}
+
+func migrations59_ingest_filter_rulesSql() (*asset, error) {
+	bytes, err := migrations59_ingest_filter_rulesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/59_ingest_filter_rules.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf, 0xf8, 0x68, 0x5d, 0x49, 0xfa, 0xd6, 0xf4, 0x25, 0x14, 0x78, 0xd6, 0x63, 0x7c, 0xb3, 0xbd, 0x37, 0x55, 0xa2, 0xb3, 0xf1, 0x88, 0xd2, 0xfd, 0xf0, 0x8, 0x99, 0x9, 0xc4, 0x11, 0x87, 0xec}}
+	return a, nil
+}
+
 var _migrations5_create_trades_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x94\x51\x6f\xaa\x40\x10\x85\xdf\xf9\x15\x13\x9f\x30\x17\x93\x7b\x6f\x5a\x5f\x4c\x9a\x58\x25\xad\xa9\xc1\xd6\x4a\xd2\x37\xb2\xb0\x23\x6c\xa2\x2c\x99\x1d\xda\xf0\xef\x1b\x68\x69\x10\x57\xad\xaf\x9c\x39\x67\x38\xbb\x5f\x76\x34\x82\x3f\x7b\x95\x92\x60\x84\xb0\x70\x66\x6b\x7f\xba\xf1\x61\x33\xbd\x5f\xfa\x90\x29\xc3\x9a\xaa\x88\x49\x48\x34\xe0\x3a\x00\xf0\xf3\x51\x17\x48\x82\x95\xce\x23\x25\x21\x56\xa9\xca\x19\x82\xd5\x06\x82\x70\xb9\xf4\x9a\xc9\x81\x26\x89\x34\x00\x95\x33\xa6\x48\x1d\xb5\x91\xf5\x76\x8b\x64\x35\x37\xb2\xc1\xdd\xee\x84\x5e\xcb\x71\x59\x9d\x75\xeb\x9d\x8c\x84\x31\xc8\x11\x57\x05\x42\x92\x09\x12\x09\x23\xc1\xbb\xa0\x4a\xe5\xa9\x3b\xbe\x19\xf6\x22\x3b\x1e\x65\x4c\x89\x64\x71\xdd\x8e\xcf\xb8\x12\x2d\x6d\x9b\xfe\xfd\xb7\x7b\xf6\xba\xcc\xb9\xff\xff\x30\x7b\xf4\x67\x4f\xe0\x76\x47\xee\xe0\xef\xf0\xbb\x57\xac\xcb\x34\xe3\x6b\x9b\x1d\xb8\xae\xe8\x76\xe0\xfb\x75\xbb\xd6\x75\xb6\xdf\xe1\x50\xdd\xd0\x19\x4e\x9c\x96\xbf\x30\x58\xbc\x84\x3e\x2c\x82\xb9\xff\x06\x19\x93\x8c\x0a\x25\x61\x15\xf4\x91\x0c\x5f\x17\xc1\x03\xc4\x4c\x88\xe0\xda\xc8\xf4\x5a\x0a\x3b\xe1\x9d\xd4\xb8\x8a\x1a\x0c\x2f\x45\xb7\xac\xda\x52\xea\x90\xfa\xb6\x2e\x65\xf4\x90\xf4\xfa\xe4\x78\xc7\x00\x9e\x5a\xf7\x75\x78\x97\x16\x1e\xb1\xe2\x1d\x5f\xa8\x67\x63\xa3\x5e\xdb\x7d\x17\xe6\xfa\x23\x77\xe6\xeb\xd5\xb3\xfd\x5d\x48\x84\x49\x84\xc4\x89\xf3\x19\x00\x00\xff\xff\x79\x87\x24\x6b\x4c\x04\x00\x00")
 
 func migrations5_create_trades_tableSqlBytes() ([]byte, error) {
@@ -1186,6 +1253,86 @@ func migrations5_create_trades_tableSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations60_webhooksSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x95\x6f\x6f\xe3\x44\x10\xc6\xdf\xfb\x53\xcc\xbb\x26\x22\x29\x05\x71\x05\x29\x80\x64\x92\x3d\x88\x08\x69\x95\x26\x3a\x4e\x08\x59\xeb\xdd\xb1\xbd\x9c\xbd\x9b\xdb\x19\x37\x35\x88\xef\x8e\xfc\xf7\xa2\x34\x55\xc3\xbd\xdd\xf9\xcd\x33\xb3\x33\xcf\xda\xd3\x29\x7c\x51\x98\xd4\x4b\x46\xd8\xed\x83\x60\x3a\x85\x77\x18\x67\xce\x7d\x20\xf0\x98\x1a\x62\xf4\xa8\x81\x33\xef\xca\x34\x03\xce\x10\xa4\x2e\x8c\x85\xf0\x7e\x79\x0d\x21\x1c\x5a\x18\x0a\xc9\x2a\x43\x6a\x00\xb7\x47\x2f\xd9\x38\x4b\x93\x5a\x4f\x5a\xfd\xa5\xf3\x4d\x04\x93\x04\x15\xd3\x04\x5c\x02\x54\x2a\x85\x44\x49\x99\x03\x7b\x69\x49\xaa\x26\x05\x0e\x99\x51\x19\x90\x64\x43\x49\x05\x32\xcf\x6b\xd8\x30\xd5\x52\xd6\xd9\xa9\x2d\xf3\x1c\x12\x93\x33\x7a\xba\x0e\xe6\x1b\x11\x6e\x05\x6c\xc3\x9f\x56\xa2\x6f\x86\x60\x14\x00\x00\x18\x0d\xb1\x49\x09\xbd\x91\x39\xac\xef\xb6\xb0\xde\xad\x56\x70\xbf\x59\xfe\x16\x6e\xde\xc3\xaf\xe2\xfd\xa4\xc1\x4a\x9f\x83\xca\xa4\x97\x8a\xd1\xc3\xa3\xf4\x95\xb1\xe9\xe8\xeb\x9b\x6f\xbe\x1b\x0f\x59\x2d\x49\xa8\x3c\xf2\x39\xf8\xcd\xed\x29\x2b\x95\x72\xa5\x3d\x07\xbf\xb9\x1d\x77\x08\xd1\x59\xb5\x6f\x6f\x3a\x60\x18\x64\xc4\xd5\x1e\xc1\x58\xc6\x14\x7d\x1b\xc3\x47\xb4\xdc\x9c\xd3\x19\x89\xaf\x6e\xc7\x7f\xfc\x39\x74\x04\x0b\xf1\x36\xdc\xad\xb6\x70\xf5\xcf\x20\xf9\xef\x55\xab\xa3\x3c\x4a\x46\x1d\x49\x06\x36\x05\x12\xcb\x62\x0f\x07\xc3\x99\x2b\xdb\x13\xf8\xdb\x59\x1c\xa4\x82\xf1\xac\x31\xc9\x02\x73\xf3\x88\xbe\x82\x8f\x25\x96\x58\xaf\xa8\xde\x6f\xbf\x80\xeb\x3e\x6e\x90\x40\xfa\xba\x75\x42\xcf\xa8\x21\xae\xc0\xd8\x14\xa9\xde\x35\x18\x5b\xbb\xa2\x96\x3b\x72\x40\xaf\x95\xa3\x4e\xb1\xb1\x4d\x05\x31\xe6\xce\xa6\xc0\x0e\xa4\xd5\x8d\x20\xa1\xe5\x5a\xec\xa8\x6a\xad\xa3\xfb\xb6\xa8\x22\xc6\x62\xd2\xb9\xc9\x23\x37\xad\x70\x86\x45\x73\x39\x88\xa5\xfa\xe0\x92\x04\x4a\xcb\x26\x87\x42\x3e\x81\x64\xc6\x62\xcf\x2f\x78\x2a\xea\x94\x0d\xfe\x4f\x77\xf5\xf9\x2d\x6e\x2c\x7f\x62\x37\xe2\xad\xd8\x88\xf5\x5c\x3c\xf4\x55\x08\x46\x46\x8f\xe1\x6e\x0d\x0b\xb1\x12\x5b\x01\xf3\xf0\x61\x1e\x2e\x44\xbb\xaa\x76\x22\x11\xe1\xc7\x12\xad\x1a\xfc\x30\x08\x9e\x9a\xe6\x79\xc9\x96\xd8\xcb\x2a\x77\x52\xc3\x5f\xe4\x6c\x7c\x12\x23\x96\x5c\xbe\xe0\xa8\x13\xb4\x1f\xd8\xb3\x3e\x06\xbb\xdd\xb4\xf5\x2c\x3e\x71\xd4\xd1\x97\xfa\xac\xbb\xb1\xa4\x4b\x33\x8f\x12\xda\x3b\x44\xca\xe9\x93\x37\xd3\x44\xd1\xfb\xfa\x6b\x84\x4f\xfc\xf9\x0f\xa0\x73\xc8\x72\xbd\x10\xbf\x9f\x71\x48\x14\x57\x51\x77\x5a\xef\xf2\x39\x00\xbb\x87\xe5\xfa\x67\x88\xd9\x23\xc2\xa8\x8f\x1b\x3d\x01\xa3\xc7\xb3\x57\xe5\xf7\x68\xb5\xb1\xe9\x25\xda\x27\xb3\x1f\xc3\xbb\x5f\xc4\x46\xf4\x6b\xfe\x01\xae\x3a\xad\xab\xd7\xab\xc6\x55\x74\x34\xab\x0b\x6a\x7f\xa2\x4f\xca\x7e\xff\xe3\x71\xdd\xe0\xf8\x1f\xb4\x70\x07\x1b\x04\x8b\xcd\xdd\xfd\xcb\xef\x4f\x49\x52\x52\xe3\xec\x0c\x46\xa0\x24\x29\xa9\x71\x16\xfc\x37\x00\xe6\xe0\xa9\x7a\xda\x06\x00\x00")
+
+func migrations60_webhooksSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations60_webhooksSql,
+		"migrations/60_webhooks.sql",
+	)
+}
+
+func migrations60_webhooksSql() (*asset, error) {
+	bytes, err := migrations60_webhooksSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/60_webhooks.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x4a, 0xe0, 0x7, 0xd8, 0x48, 0x82, 0xad, 0x4f, 0x75, 0xd1, 0x7f, 0x4d, 0x9c, 0x4f, 0x1, 0xbc, 0x9, 0xf5, 0xea, 0x49, 0x67, 0x63, 0x6, 0x4b, 0x14, 0x10, 0x5f, 0x35, 0x25, 0xfa, 0x99, 0x81}}
+	return a, nil
+}
+
+var _migrations61_txsub_submissionsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x93\x4f\x6f\xda\x40\x10\xc5\xef\xfe\x14\xef\x16\x50\x21\x52\xff\x28\x17\xda\x4a\xa4\x58\x0d\x2a\x85\x88\x82\xda\x9c\xac\xc1\x1e\xd8\x55\xed\x5d\x67\x67\x1c\x20\x9f\xbe\x32\x04\x62\x51\xa2\xe4\x66\x79\x66\x7f\xef\xcd\x9b\xdd\x6e\x17\xef\x0a\xbb\x0a\xa4\x8c\x79\x19\x45\xdd\x2e\x66\x81\x9c\x50\xaa\xd6\x3b\x81\x54\x8b\xc2\xaa\x72\x06\xf5\x10\xe5\x3c\xa7\xd0\x4d\x7d\x60\xac\x0d\x3b\xa8\x61\x94\x1c\xc4\x8a\xb2\xd3\x7d\xb7\x88\xf5\x0e\xf7\x15\x57\x5c\xe3\xac\x80\x1d\x2d\x72\xce\x2e\x31\x33\x0c\xad\xbf\x61\x05\x62\x28\x70\x86\xc5\x16\x94\xe7\x3b\xd2\x8d\x0f\xf6\xd1\x3b\x58\x27\x4a\x2e\x65\x41\x25\xd6\xad\x76\x35\xa1\x62\x87\x1b\x5c\x77\x20\x1e\x6a\x48\x41\x6e\x0b\xbf\xac\xcb\x05\x52\x72\xd0\x40\xe9\x5f\x10\x4a\x76\x59\x7d\xae\x61\x87\x5c\x86\xc0\xa5\x0f\x0a\xab\x52\x83\x44\x49\x2b\xe9\xc0\xba\x34\xaf\x76\xed\xb4\x54\x0e\x35\xed\x68\x00\x6b\x63\x53\xd3\x08\xc1\x2a\xd6\x24\x08\x2c\x4a\x41\x39\xbb\x8c\xbe\x4d\xe3\xfe\x2c\xc6\xac\x7f\x3d\x8a\xa1\x1b\xa9\x16\xc9\xb3\xac\xa0\x15\x01\x80\x3e\x47\x9a\x18\x12\x83\xd4\x50\xa0\xb4\x96\x7b\xa0\xb0\xb5\x6e\xd5\xba\xfa\xd4\xc6\x78\x32\xc3\x78\x3e\x1a\xe1\x76\x3a\xfc\xd9\x9f\xde\xe1\x47\x7c\xd7\xd9\x01\xd8\x3d\x70\xee\x4b\x4e\x36\x59\x80\xf2\x46\x8f\xbd\xfb\xfa\xd1\x61\x42\x0a\xb5\x45\xed\xaf\x28\xb1\xb6\x6a\x7c\xb5\xff\x83\x47\xef\xf8\xe4\x58\xbd\xc8\x64\x1f\xc4\x19\x4b\x1f\x3f\xb4\xcf\xf5\x73\x08\x3e\x24\x81\xa5\xca\xf5\xe8\xe7\xc9\xc6\x4b\xa8\xf7\x57\xa7\xa8\x9c\xb3\x15\x87\x44\xf8\xbe\xe2\x3a\x69\xeb\x94\x57\x1c\xf6\xc5\xb3\xf0\xa5\x75\x56\xcc\xab\x23\x46\xed\x5e\x74\xd8\xca\x70\x3c\x88\xff\xfc\xbf\x95\xe4\x70\x41\x26\xe3\x33\x2b\x9b\xff\x1a\x8e\xbf\x63\xa1\x81\x19\xad\x66\xb0\x6d\xfc\xbe\x89\xa7\xf1\x61\xca\x2f\xb8\x78\xe2\x5c\xf4\x5e\x13\x3c\x78\x7f\x83\x62\x63\xcc\x13\xc1\xcf\x5f\x9b\x8a\x51\xf3\xe9\x0e\xfc\xda\x45\xd1\x60\x3a\xb9\x7d\xf1\x26\xa6\x24\x29\x65\xdc\x8b\xfe\x0d\x00\x17\xe8\x20\x90\xf3\x03\x00\x00")
+
+func migrations61_txsub_submissionsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations61_txsub_submissionsSql,
+		"migrations/61_txsub_submissions.sql",
+	)
+}
+
+func migrations61_txsub_submissionsSql() (*asset, error) {
+	bytes, err := migrations61_txsub_submissionsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/61_txsub_submissions.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa0, 0x37, 0x62, 0x9b, 0x86, 0xe0, 0x1e, 0xcd, 0x3c, 0x40, 0x8b, 0xa5, 0x7a, 0x82, 0xb6, 0xbe, 0xbb, 0x4, 0xa6, 0x2c, 0x14, 0xef, 0x58, 0x99, 0xe7, 0x7, 0xb3, 0xf3, 0x12, 0x1f, 0x22, 0xc2}}
+	return a, nil
+}
+
+var _migrations62_rate_limit_accountsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x53\xd1\x6e\xda\x30\x14\x7d\xcf\x57\x9c\x47\xd0\x4a\xc5\xaa\x95\x17\x9e\x32\xe2\x6e\x68\x2c\xa0\x00\xd2\xfa\x14\x5d\x92\x0b\xb1\x4a\x6c\x66\x3b\xa3\xec\xeb\x27\x3b\xa4\x95\x5a\xc6\xb4\x37\xcb\x3e\xe7\xdc\x7b\xcf\xb9\x1e\x0c\xf0\xa1\x96\x3b\x43\x8e\xb1\x3e\x44\xd1\x60\x80\xb8\x28\x74\xa3\x9c\x05\xed\xf7\xfa\xc8\x25\x9c\x06\x35\xae\x62\xe5\x64\xe1\x71\x47\xe9\x2a\xc4\x8b\x29\x9e\xf8\x64\x41\xaa\x84\xab\x58\x1a\x04\x91\xbd\xac\xa5\xb3\x37\x90\xca\x6b\x19\xfe\xd9\xb0\x75\x16\x07\x36\xa8\x74\x63\x6e\x11\xfb\x73\xee\xcf\xb9\x27\xe4\x81\x00\xbd\xc5\x10\xa5\xb4\xb4\xd9\xb3\xf5\x7a\x08\xf7\x37\xb0\xce\x30\xd5\xbe\x8a\xd7\x3b\x90\xab\xb0\x95\xaa\x94\x6a\xd7\x22\x6c\x4b\x0d\x2d\x7b\x9e\xe5\xd7\xa2\xb4\x23\xa9\xac\xbb\x54\xf0\x36\x9a\x64\x22\x5e\x09\xac\xe2\xcf\x33\x81\xd7\x87\x9c\xba\xf1\x7b\x11\x00\x28\xaa\x19\x45\x45\x86\x0a\xc7\x06\xbf\xc8\x9c\xa4\xda\xf5\xee\xee\x47\x7d\xa4\xf3\x15\xd2\xf5\x6c\x86\x45\x36\xfd\x1e\x67\x8f\xf8\x26\x1e\x6f\x02\xeb\xd2\x88\x52\x39\xde\xb1\x79\x61\xbd\x41\xb6\x83\x5e\x23\x20\x11\x0f\xf1\x7a\xb6\xc2\xf0\x0d\xd5\xbb\x92\x9f\x5d\xf9\x1f\x81\x9a\x9e\xf3\x4d\x63\xec\x15\xe4\xc7\xe1\x30\xea\x8f\xdb\xbd\xe8\x12\xd7\x5b\x6f\x34\x3a\xa3\x6e\x31\x57\xfb\x53\xb8\xaa\xf8\x19\xac\x0a\x5d\x72\x89\xe5\xd7\x78\x70\x77\x3f\x42\x45\xb6\xea\x28\x61\x61\xa4\xf5\x6a\xd6\x69\xc3\xe5\x95\x18\x0e\x32\x0f\xf0\x36\x86\x27\x3e\xe5\x41\xe9\x7d\x14\xa3\x4f\xd7\x92\x38\x77\xf9\xcf\x08\x33\xf1\x20\x32\x91\x4e\xc4\xf2\xf2\x32\xf8\x3d\xe8\x63\x9e\x22\x11\x33\xb1\x12\x98\xc4\xcb\x49\x9c\x88\xb6\x48\x61\x98\x1c\x97\x39\x39\x38\x59\xb3\x75\x54\x1f\xc2\x37\xd1\x4d\x7b\x83\xdf\x5a\xf1\x7b\x77\x95\x3e\xf6\xfa\xc1\xdf\xb3\x0d\xd3\x34\x11\x3f\x2e\xd9\xd0\x75\xe2\x3b\xb8\xf0\x8c\xf5\x72\x9a\x7e\xc1\xc6\x19\x66\xf4\xce\xd8\x73\x6e\x2f\xff\x3b\xd1\x47\x15\x45\x49\x36\x5f\x5c\xb1\xbb\x20\x5b\x50\xc9\xe3\xbf\xe1\x3a\x43\x0a\xb2\x05\x95\x3c\x8e\xfe\x0c\x00\x13\x13\x37\xe4\x42\x04\x00\x00")
+
+func migrations62_rate_limit_accountsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations62_rate_limit_accountsSql,
+		"migrations/62_rate_limit_accounts.sql",
+	)
+}
+
+func migrations62_rate_limit_accountsSql() (*asset, error) {
+	bytes, err := migrations62_rate_limit_accountsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/62_rate_limit_accounts.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe3, 0xcd, 0xec, 0xca, 0x5f, 0x42, 0x72, 0x81, 0x52, 0xa8, 0xa9, 0x85, 0x2f, 0xbd, 0xd1, 0xd9, 0xd5, 0xa1, 0x60, 0x45, 0xa6, 0xc1, 0x67, 0x65, 0xa, 0x2f, 0x5b, 0x70, 0x60, 0x38, 0xea, 0x95}}
+	return a, nil
+}
+
+var _migrations63_account_balance_historySql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x54\x5f\x6f\xda\x30\x10\x7f\xcf\xa7\xb8\x47\xd0\x12\xb4\x4d\x5b\x5f\xfa\x44\x47\x36\xa1\x31\xa8\x28\x48\xeb\x13\x32\xf6\x91\x58\x0b\x36\xf3\x1d\x44\xd9\xa7\x9f\x1c\x3b\xd9\x4a\x53\xb5\x6f\x28\xf7\xf3\xef\xdf\x9d\xc8\x32\x78\x77\xd4\x85\x13\x8c\xb0\x3d\x25\x49\x96\xc1\x9d\xa8\x84\x91\x48\x60\x0f\xc0\x25\x82\x90\xd2\x9e\x0d\x13\x08\xa3\x80\xdd\x99\x18\x2a\x6d\x90\x40\x1c\x18\x1d\xe0\x05\x5d\x03\x15\xaa\x02\x1d\x68\x03\x75\xa9\x65\xe9\x1f\x36\x9e\x4c\x96\xc2\x14\xa8\x26\x50\x6a\x62\xeb\x9a\x5d\x00\xee\xb4\x02\x4d\x1e\x05\x6c\xb5\xea\xa4\x22\x0b\xd9\x76\xe2\x6c\x4d\x20\x1c\x82\xac\x50\x38\x54\x9e\x6f\xdf\x84\x11\x8a\x13\xba\xd6\x91\x43\x6d\x0a\x24\xd6\xd6\x40\xad\xb9\x8c\x73\xe2\x8e\x34\x2a\x4f\x60\x8d\x47\x7b\x41\xd5\x07\xf2\x7c\xd7\x99\x4a\x71\x41\x10\xb0\x0f\x1d\x78\x8a\xf7\x51\x25\xbc\x25\x64\x60\xeb\x6b\xc0\x49\x92\x65\x9e\xe2\xc1\x88\x13\x95\x96\x83\xdf\xd2\x56\xaa\xb5\x10\x29\xda\x1a\x45\x55\xbd\x52\x65\x80\x79\xba\xd0\x41\xea\xf1\x4d\x9b\xbe\x76\x9a\x19\x7d\xb1\x68\xfc\x57\x20\xf6\xdb\xd2\x04\x21\xb8\x4f\x64\x14\x04\x7f\x07\xeb\x6a\xe1\x54\xec\xc9\xf3\x85\xaa\xd2\x50\xaa\xe0\xa7\xde\xc2\xb2\x94\x56\x60\x2c\xc7\x65\x01\x69\x23\xb1\x95\xfe\x65\x6c\x6d\x26\xc9\x97\x75\x3e\xdd\xe4\xb0\x99\xde\x2d\xf2\x7e\x91\x31\xcb\xae\xe7\x1a\x25\x00\x30\xb0\xe7\xbd\x2e\xb4\x61\x58\xae\x36\xb0\xdc\x2e\x16\x69\x0b\x8b\x63\xc2\xdf\x67\xf4\x6a\xda\x30\xfa\x03\x7a\x8a\x92\x95\x25\x54\x3b\xef\x5a\x1f\x91\x58\x1c\x4f\xed\x8e\xed\x39\x7c\x81\x3f\xd6\xe0\xd5\x9b\xce\x97\x56\x3e\x8f\x13\xd2\x1f\xe9\x45\xb8\x46\x9b\x62\xf4\xf9\x66\x7c\x0d\x27\x42\xde\x71\x73\xc2\x01\xf8\xcd\xa7\x61\xb8\xb4\x6a\x08\xfe\xe1\xe3\x30\x5c\x13\x9d\xd1\xbd\xc9\x4e\x6c\x73\xb8\xb4\xee\x08\xf7\xd6\x56\x28\x4c\x3f\x85\x59\xfe\x75\xba\x5d\x6c\xe0\x20\x2a\xc2\xa0\x4c\xdd\x55\xbe\x05\x7c\xbf\x9e\xff\x98\xae\x1f\xe1\x7b\xfe\x08\xa3\x7f\x05\xa6\xd1\xbf\x6f\xa7\xfb\xed\xa3\x77\xbf\x43\xae\xf4\xf9\xce\xd3\x5e\x7e\x9c\x8c\x6f\x93\xee\x80\xe6\xcb\x59\xfe\xb3\x47\x5f\x1f\x50\x7c\x0e\xab\xe5\x8b\x10\xd8\x3e\xcc\x97\xdf\x60\xcf\x0e\x11\x46\xcf\x64\xbd\xd4\xff\x7f\x68\x33\x5b\x9b\x24\x99\xad\x57\xf7\xaf\xdd\xae\x14\x24\x85\xc2\xdb\xe4\xef\x00\xcc\xed\x59\xd4\x10\x05\x00\x00")
+
+func migrations63_account_balance_historySqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations63_account_balance_historySql,
+		"migrations/63_account_balance_history.sql",
+	)
+}
+
+func migrations63_account_balance_historySql() (*asset, error) {
+	bytes, err := migrations63_account_balance_historySqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/63_account_balance_history.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3a, 0xbd, 0x58, 0x30, 0xc6, 0x68, 0x9e, 0x79, 0x7f, 0xd9, 0x52, 0x84, 0x44, 0xa4, 0xe7, 0x3b, 0x69, 0x21, 0xd9, 0x6d, 0xa7, 0x16, 0xf6, 0x19, 0xba, 0xff, 0x9b, 0x6c, 0xfc, 0x28, 0xd3, 0x2f}}
+	return a, nil
+}
+
 var _migrations6_create_assets_tableSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\x90\x3d\x4f\xc3\x30\x18\x84\x77\xff\x8a\x1b\x1d\x91\x0e\x20\xe8\x92\xc9\x34\x16\x58\x18\xa7\xb8\x31\xa2\x53\xe5\x26\x16\x78\x80\x54\xb6\x11\xca\xbf\x47\xaa\x28\xf9\x50\xe6\x7b\xf4\xbc\xef\xdd\x6a\x85\xab\x4f\xff\x1e\x6c\x72\x30\x27\xb2\xd1\x9c\xd5\x1c\x35\xbb\x97\x1c\x1f\x3e\xa6\x2e\xf4\x07\x1b\xa3\x4b\x11\x94\x00\x80\x6f\xb1\xe3\x5a\x30\x89\xad\x16\xcf\x4c\xef\xf1\xc4\xf7\xc8\xcf\xd9\x19\x3c\xa4\xfe\xe4\xf0\xca\xf4\xe6\x91\x69\xba\xbe\xcd\xa0\xaa\x1a\xca\x48\x39\x86\x9a\xae\x1d\xa0\xeb\x9b\x65\xc8\xc7\xf8\xed\xc2\x3f\x76\xb7\x9e\x63\x46\x89\x17\xc3\xe9\xa0\xcc\x47\x3f\xe4\x13\x4b\x46\xb2\x82\x5c\xfa\x09\x55\xf2\xb7\xbf\xf8\xd8\x5f\xee\x54\x6a\x5e\xd9\xec\x84\x7a\xc0\x31\x05\xe7\x40\x27\xb6\x82\x90\xf1\x74\x65\xf7\xf3\x45\x4a\x5d\x6d\x97\xa7\x6b\x6c\x6c\x6c\xeb\x8a\xdf\x00\x00\x00\xff\xff\xfb\x53\x3e\x81\x6e\x01\x00\x00")
 
 func migrations6_create_assets_tableSqlBytes() ([]byte, error) {
@@ -1429,7 +1576,14 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/54_kinesis_coin_in_circulation.sql":                      migrations54_kinesis_coin_in_circulationSql,
 	"migrations/55_kinesis_coin_in_circulation_v2.sql":                   migrations55_kinesis_coin_in_circulation_v2Sql,
 	"migrations/56_kinesis_coin_in_circulation_at_ledger.sql":            migrations56_kinesis_coin_in_circulation_at_ledgerSql,
+	"migrations/57_transactions_transferred_amount.sql":                  migrations57_transactions_transferred_amountSql,
+	"migrations/58_kinesis_coin_in_circulation_tables.sql":               migrations58_kinesis_coin_in_circulation_tablesSql,
+	"migrations/59_ingest_filter_rules.sql":                              migrations59_ingest_filter_rulesSql,
 	"migrations/5_create_trades_table.sql":                               migrations5_create_trades_tableSql,
+	"migrations/60_webhooks.sql":                                         migrations60_webhooksSql,
+	"migrations/61_txsub_submissions.sql":                                migrations61_txsub_submissionsSql,
+	"migrations/62_rate_limit_accounts.sql":                              migrations62_rate_limit_accountsSql,
+	"migrations/63_account_balance_history.sql":                          migrations63_account_balance_historySql,
 	"migrations/6_create_assets_table.sql":                               migrations6_create_assets_tableSql,
 	"migrations/7_modify_trades_table.sql":                               migrations7_modify_trades_tableSql,
 	"migrations/8_add_aggregators.sql":                                   migrations8_add_aggregatorsSql,
@@ -1531,7 +1685,14 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"54_kinesis_coin_in_circulation.sql":                      &bintree{migrations54_kinesis_coin_in_circulationSql, map[string]*bintree{}},
 		"55_kinesis_coin_in_circulation_v2.sql":                   &bintree{migrations55_kinesis_coin_in_circulation_v2Sql, map[string]*bintree{}},
 		"56_kinesis_coin_in_circulation_at_ledger.sql":            &bintree{migrations56_kinesis_coin_in_circulation_at_ledgerSql, map[string]*bintree{}},
+		"57_transactions_transferred_amount.sql":                  &bintree{migrations57_transactions_transferred_amountSql, map[string]*bintree{}},
+		"58_kinesis_coin_in_circulation_tables.sql":               &bintree{migrations58_kinesis_coin_in_circulation_tablesSql, map[string]*bintree{}},
+		"59_ingest_filter_rules.sql":                              &bintree{migrations59_ingest_filter_rulesSql, map[string]*bintree{}},
 		"5_create_trades_table.sql":                               &bintree{migrations5_create_trades_tableSql, map[string]*bintree{}},
+		"60_webhooks.sql":                                         &bintree{migrations60_webhooksSql, map[string]*bintree{}},
+		"61_txsub_submissions.sql":                                &bintree{migrations61_txsub_submissionsSql, map[string]*bintree{}},
+		"62_rate_limit_accounts.sql":                              &bintree{migrations62_rate_limit_accountsSql, map[string]*bintree{}},
+		"63_account_balance_history.sql":                          &bintree{migrations63_account_balance_historySql, map[string]*bintree{}},
 		"6_create_assets_table.sql":                               &bintree{migrations6_create_assets_tableSql, map[string]*bintree{}},
 		"7_modify_trades_table.sql":                               &bintree{migrations7_modify_trades_tableSql, map[string]*bintree{}},
 		"8_add_aggregators.sql":                                   &bintree{migrations8_add_aggregatorsSql, map[string]*bintree{}},
@@ -0,0 +1,10 @@
+-- +migrate Up
+
+-- Native amount moved by a transaction, used to compute percentage fee stats.
+-- NULL when the transaction did not move any native amount or was ingested
+-- before this column was added.
+ALTER TABLE history_transactions ADD transferred_amount bigint;
+
+-- +migrate Down
+
+ALTER TABLE history_transactions DROP COLUMN transferred_amount;
@@ -0,0 +1,45 @@
+-- +migrate Up
+
+-- Mint and redemption amounts (in stroops) of every ledger in which the
+-- treasury accounts minted or redeemed coins, together with the running totals
+-- up to and including the ledger.
+CREATE TABLE history_kinesis_coin_in_circulation_ledgers (
+    ledger_sequence integer NOT NULL PRIMARY KEY,
+    closed_at timestamp without time zone NOT NULL,
+    mint bigint NOT NULL,
+    redemption bigint NOT NULL,
+    total_mint bigint NOT NULL DEFAULT 0,
+    total_redemption bigint NOT NULL DEFAULT 0,
+    circulation bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX history_kinesis_coin_in_circulation_ledgers_by_closed_at ON history_kinesis_coin_in_circulation_ledgers USING btree (closed_at);
+
+-- Daily aggregation of history_kinesis_coin_in_circulation_ledgers. The
+-- ledger_sequence and running totals are the ones of the last ledger of the day.
+CREATE TABLE history_kinesis_coin_in_circulation_days (
+    day date NOT NULL PRIMARY KEY,
+    ledger_sequence integer NOT NULL,
+    mint bigint NOT NULL,
+    redemption bigint NOT NULL,
+    total_mint bigint NOT NULL,
+    total_redemption bigint NOT NULL,
+    circulation bigint NOT NULL
+);
+
+CREATE INDEX history_kinesis_coin_in_circulation_days_by_ledger ON history_kinesis_coin_in_circulation_days USING btree (ledger_sequence);
+
+-- The ledgers ingested before this migration are backfilled from history by
+-- the ingesting instance on startup, which removes this key once done. The
+-- coin in circulation endpoints are unavailable until then.
+INSERT INTO key_value_store (key, value)
+SELECT 'kinesis_coin_in_circulation_backfill_to', MAX(sequence)::text
+FROM history_ledgers
+HAVING COUNT(*) > 0;
+
+-- +migrate Down
+
+DELETE FROM key_value_store WHERE key = 'kinesis_coin_in_circulation_backfill_to';
+
+DROP TABLE history_kinesis_coin_in_circulation_days cascade;
+DROP TABLE history_kinesis_coin_in_circulation_ledgers cascade;
@@ -0,0 +1,25 @@
+-- +migrate Up
+
+-- Rules of the ingestion filters, keyed by the name of the filter. The rules
+-- are stored as JSON as their shape depends on the filter. version is taken
+-- from a sequence shared by all the filters on every update, so that the
+-- highest version identifies the set of rules in use.
+CREATE SEQUENCE ingest_filter_rules_version_seq;
+
+CREATE TABLE ingest_filter_rules (
+    name character varying(64) NOT NULL PRIMARY KEY,
+    enabled boolean NOT NULL DEFAULT false,
+    rules jsonb NOT NULL,
+    version bigint NOT NULL,
+    last_modified timestamp without time zone NOT NULL
+);
+
+-- Version of the filter rules the history of the ledger was ingested with,
+-- NULL when the ledger was ingested without filters.
+ALTER TABLE history_ledgers ADD filter_version bigint;
+
+-- +migrate Down
+
+ALTER TABLE history_ledgers DROP COLUMN filter_version;
+DROP TABLE ingest_filter_rules cascade;
+DROP SEQUENCE ingest_filter_rules_version_seq;
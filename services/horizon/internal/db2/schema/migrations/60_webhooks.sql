@@ -0,0 +1,42 @@
+-- +migrate Up
+
+-- Webhooks registered through the admin API. A webhook matches the operations,
+-- and/or the effects, of successful transactions which satisfy all of its
+-- non-null filters.
+CREATE TABLE webhooks (
+    id bigserial NOT NULL PRIMARY KEY,
+    url character varying(2048) NOT NULL,
+    secret character varying(256) NOT NULL,
+    account character varying(56),
+    asset character varying(70),
+    operation_type integer,
+    event_types character varying(16)[] NOT NULL DEFAULT '{operation}',
+    created_at timestamp without time zone NOT NULL
+);
+
+-- Delivery queue of the webhooks. Deliveries are inserted by ingestion in the
+-- transaction of the ledger they belong to and are sent by the webhook
+-- delivery system, which retries them with backoff until max attempts.
+CREATE TABLE webhook_deliveries (
+    id bigserial NOT NULL PRIMARY KEY,
+    webhook_id bigint NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    ledger_sequence integer NOT NULL,
+    operation_id bigint NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(16) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    next_attempt_at timestamp without time zone NOT NULL,
+    last_attempt_at timestamp without time zone,
+    last_status_code integer,
+    last_error text,
+    created_at timestamp without time zone NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_by_webhook ON webhook_deliveries USING btree (webhook_id, id);
+CREATE INDEX webhook_deliveries_pending ON webhook_deliveries USING btree (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_by_created_at ON webhook_deliveries USING btree (created_at) WHERE status <> 'pending';
+
+-- +migrate Down
+
+DROP TABLE webhook_deliveries cascade;
+DROP TABLE webhooks cascade;
@@ -0,0 +1,24 @@
+-- +migrate Up
+
+-- Transactions submitted to stellar-core when the persistent submission queue
+-- is enabled. The table is shared by all the Horizon instances using the same
+-- DB, so that any of them can track a pending submission and report its
+-- status, including after the instance which submitted it was restarted.
+CREATE TABLE txsub_submissions (
+    transaction_hash character varying(64) NOT NULL PRIMARY KEY,
+    envelope_xdr text NOT NULL,
+    submitted_at timestamp without time zone NOT NULL,
+    core_status character varying(32) NOT NULL,
+    core_error_result_xdr text,
+    status character varying(16) NOT NULL,
+    ledger_sequence integer,
+    result_xdr text,
+    finished_at timestamp without time zone
+);
+
+CREATE INDEX txsub_submissions_pending ON txsub_submissions USING btree (submitted_at) WHERE status = 'pending';
+CREATE INDEX txsub_submissions_finished ON txsub_submissions USING btree (finished_at) WHERE status <> 'pending';
+
+-- +migrate Down
+
+DROP TABLE txsub_submissions cascade;
@@ -0,0 +1,27 @@
+-- +migrate Up
+
+-- Accounts allowed to authenticate with API keys and their rate limits, in
+-- requests per hour. A per_hour_rate_limit of 0 disables the limit, stream and
+-- path finding limits of 0 count these requests against per_hour_rate_limit.
+CREATE TABLE rate_limit_accounts (
+    name character varying(256) NOT NULL PRIMARY KEY,
+    per_hour_rate_limit integer NOT NULL,
+    per_hour_stream_rate_limit integer NOT NULL DEFAULT 0,
+    per_hour_path_finding_rate_limit integer NOT NULL DEFAULT 0,
+    max_burst integer NOT NULL DEFAULT 100
+);
+
+-- API keys of the accounts. Only the hex encoded SHA-256 hash of the keys is
+-- stored.
+CREATE TABLE rate_limit_api_keys (
+    key_hash character varying(64) NOT NULL PRIMARY KEY,
+    account character varying(256) NOT NULL REFERENCES rate_limit_accounts (name) ON DELETE CASCADE,
+    created_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX rate_limit_api_keys_account ON rate_limit_api_keys USING btree (account);
+
+-- +migrate Down
+
+DROP TABLE rate_limit_api_keys cascade;
+DROP TABLE rate_limit_accounts cascade;
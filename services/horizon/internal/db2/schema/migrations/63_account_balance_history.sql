@@ -0,0 +1,29 @@
+-- +migrate Up
+
+-- Balances of the accounts and trust lines after every ledger in which they
+-- changed. history_ledger_id is the toid of the ledger so the rows are cleared
+-- by the reaper and reingestion with the rest of the history. Removed accounts
+-- and trust lines have a balance of 0 and removed set to true.
+--
+-- Snapshot rows hold the balances of all the accounts and trust lines as of a
+-- ledger, they are written when the state is ingested and moved forward by the
+-- reaper, so that the balances which did not change since are known.
+CREATE TABLE history_account_balances (
+    history_ledger_id bigint NOT NULL,
+    ledger_sequence integer NOT NULL,
+    closed_at timestamp without time zone NOT NULL,
+    account_id character varying(56) NOT NULL,
+    asset_type character varying(64) NOT NULL,
+    asset_code character varying(12) NOT NULL,
+    asset_issuer character varying(56) NOT NULL,
+    balance bigint NOT NULL,
+    removed boolean NOT NULL DEFAULT false,
+    snapshot boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (account_id, asset_type, asset_code, asset_issuer, history_ledger_id, snapshot)
+);
+
+CREATE INDEX history_account_balances_ledger ON history_account_balances USING btree (history_ledger_id);
+
+-- +migrate Down
+
+DROP TABLE history_account_balances cascade;
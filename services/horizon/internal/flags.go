@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -15,6 +16,7 @@ import (
 	"github.com/stellar/go/ingest/ledgerbackend"
 	"github.com/stellar/go/network"
 	"github.com/stellar/go/services/horizon/internal/db2/schema"
+	"github.com/stellar/go/services/horizon/internal/ratelimit"
 	apkg "github.com/stellar/go/support/app"
 	support "github.com/stellar/go/support/config"
 	"github.com/stellar/go/support/db"
@@ -54,6 +56,16 @@ func validateBothOrNeither(option1, option2 string) error {
 	return nil
 }
 
+// setPerHourRateQuota sets a *throttled.RateQuota option to a quota of the
+// given number of requests per hour, a value of 0 leaves the quota unset.
+func setPerHourRateQuota(co *support.ConfigOption) error {
+	quota := ratelimit.PerHour(viper.GetInt(co.Name), ratelimit.DefaultMaxBurst)
+	if quota != nil {
+		*(co.ConfigKey.(**throttled.RateQuota)) = quota
+	}
+	return nil
+}
+
 func applyMigrations(config Config) error {
 	dbConn, err := db.Open("postgres", config.DatabaseURL)
 	if err != nil {
@@ -264,6 +276,26 @@ func Flags() (*Config, support.ConfigOptions) {
 			},
 			Usage: "comma-separated list of stellar history archives to connect with",
 		},
+		&support.ConfigOption{
+			Name:        "history-archive-cache-path",
+			ConfigKey:   &config.HistoryArchiveCache.Path,
+			OptType:     types.String,
+			Required:    false,
+			FlagDefault: "",
+			Usage:       "directory where files read from the history archive are cached, caching is disabled if empty",
+		},
+		&support.ConfigOption{
+			Name:        "history-archive-cache-size",
+			ConfigKey:   &config.HistoryArchiveCache.MaxSize,
+			OptType:     types.Uint,
+			Required:    false,
+			FlagDefault: uint(1024),
+			CustomSetValue: func(co *support.ConfigOption) error {
+				*(co.ConfigKey.(*int64)) = int64(viper.GetInt(co.Name)) << 20
+				return nil
+			},
+			Usage: "maximum size in MB of the history archive cache",
+		},
 		&support.ConfigOption{
 			Name:        "port",
 			ConfigKey:   &config.Port,
@@ -316,23 +348,51 @@ func Flags() (*Config, support.ConfigOptions) {
 			Usage:          "defines the timeout of connection after which 504 response will be sent or stream will be closed, if Horizon is behind a load balancer with idle connection timeout, this should be set to a few seconds less that idle timeout, does not apply to POST /transactions",
 		},
 		&support.ConfigOption{
-			Name:        "per-hour-rate-limit",
-			ConfigKey:   &config.RateQuota,
-			OptType:     types.Int,
-			FlagDefault: 3600,
-			CustomSetValue: func(co *support.ConfigOption) error {
-				var rateLimit *throttled.RateQuota = nil
-				perHourRateLimit := viper.GetInt(co.Name)
-				if perHourRateLimit != 0 {
-					rateLimit = &throttled.RateQuota{
-						MaxRate:  throttled.PerHour(perHourRateLimit),
-						MaxBurst: 100,
-					}
-					*(co.ConfigKey.(**throttled.RateQuota)) = rateLimit
-				}
-				return nil
-			},
-			Usage: "max count of requests allowed in a one hour period, by remote ip address",
+			Name:           "per-hour-rate-limit",
+			ConfigKey:      &config.RateQuota,
+			OptType:        types.Int,
+			FlagDefault:    3600,
+			CustomSetValue: setPerHourRateQuota,
+			Usage:          "max count of requests allowed in a one hour period, by remote ip address",
+		},
+		&support.ConfigOption{
+			Name:           "per-hour-stream-rate-limit",
+			ConfigKey:      &config.StreamRateQuota,
+			OptType:        types.Int,
+			FlagDefault:    0,
+			CustomSetValue: setPerHourRateQuota,
+			Usage:          "max count of streaming updates allowed in a one hour period, by remote ip address, streaming updates are counted against --per-hour-rate-limit when 0 (the default)",
+		},
+		&support.ConfigOption{
+			Name:           "per-hour-path-finding-rate-limit",
+			ConfigKey:      &config.PathFindingRateQuota,
+			OptType:        types.Int,
+			FlagDefault:    0,
+			CustomSetValue: setPerHourRateQuota,
+			Usage:          "max count of path finding requests allowed in a one hour period, by remote ip address, path finding requests are counted against --per-hour-rate-limit when 0 (the default)",
+		},
+		&support.ConfigOption{
+			Name:        "rate-limit-accounts-path",
+			ConfigKey:   &config.RateLimitAccountsPath,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage:       "path to a TOML file listing the accounts which can authenticate with API keys (X-API-Key header or api_key parameter) and their rate limits, reloaded every 10 seconds",
+		},
+		&support.ConfigOption{
+			Name:        "rate-limit-accounts-from-db",
+			ConfigKey:   &config.RateLimitAccountsFromDB,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage:       "loads the accounts which can authenticate with API keys and their rate limits from the rate_limit_accounts and rate_limit_api_keys tables of the Horizon DB, reloaded every 10 seconds",
+		},
+		&support.ConfigOption{
+			Name:        "rate-limit-redis-url",
+			ConfigKey:   &config.RateLimitRedisURL,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage:       "URL of a Redis compatible server (redis://[:password@]host[:port][/db]) storing the state of the rate limits, so that they are shared by all the Horizon instances using it, the state is kept in memory when not set",
 		},
 		&support.ConfigOption{
 			Name:           "friendbot-url",
@@ -489,6 +549,61 @@ func Flags() (*Config, support.ConfigOptions) {
 			FlagDefault: false,
 			Usage:       "enables extended ledger stats in the log (ledger entry changes and operations stats)",
 		},
+		&support.ConfigOption{
+			Name:        "webhook-max-attempts",
+			ConfigKey:   &config.WebhookMaxAttempts,
+			OptType:     types.Uint,
+			FlagDefault: uint(10),
+			Usage:       "number of attempts after which a webhook delivery is marked as failed, deliveries are retried with an exponential backoff",
+		},
+		&support.ConfigOption{
+			Name:           "webhook-timeout",
+			ConfigKey:      &config.WebhookTimeout,
+			OptType:        types.Int,
+			FlagDefault:    10,
+			CustomSetValue: support.SetDuration,
+			Usage:          "defines the timeout of webhook delivery requests (in seconds)",
+		},
+		&support.ConfigOption{
+			Name:        "webhook-delivery-retention",
+			ConfigKey:   &config.WebhookDeliveryRetention,
+			OptType:     types.Uint,
+			FlagDefault: uint(168),
+			CustomSetValue: func(co *support.ConfigOption) error {
+				*(co.ConfigKey.(*time.Duration)) = time.Duration(viper.GetInt(co.Name)) * time.Hour
+				return nil
+			},
+			Usage: "number of hours the delivered and failed webhook deliveries are kept in the delivery log, 0 keeps them all",
+		},
+		&support.ConfigOption{
+			Name:        "txsub-persistent-queue",
+			ConfigKey:   &config.TxSubPersistentQueue,
+			OptType:     types.Bool,
+			FlagDefault: false,
+			Usage:       "records the transactions submitted to stellar-core in the Horizon DB, so that their submission is tracked across restarts and by all the Horizon instances sharing the DB",
+		},
+		&support.ConfigOption{
+			Name:        "txsub-pending-expiry",
+			ConfigKey:   &config.TxSubPendingExpiry,
+			OptType:     types.Uint,
+			FlagDefault: uint(10),
+			CustomSetValue: func(co *support.ConfigOption) error {
+				*(co.ConfigKey.(*time.Duration)) = time.Duration(viper.GetInt(co.Name)) * time.Minute
+				return nil
+			},
+			Usage: "number of minutes after which the pending submissions of the persistent queue which were not ingested expire",
+		},
+		&support.ConfigOption{
+			Name:        "txsub-retention",
+			ConfigKey:   &config.TxSubRetention,
+			OptType:     types.Uint,
+			FlagDefault: uint(24),
+			CustomSetValue: func(co *support.ConfigOption) error {
+				*(co.ConfigKey.(*time.Duration)) = time.Duration(viper.GetInt(co.Name)) * time.Hour
+				return nil
+			},
+			Usage: "number of hours the finished submissions of the persistent queue are kept",
+		},
 		&support.ConfigOption{
 			Name:        "apply-migrations",
 			ConfigKey:   &config.ApplyMigrations,
@@ -529,6 +644,56 @@ func Flags() (*Config, support.ConfigOptions) {
 			Required:    false,
 			Usage:       "excludes trades from /trade_aggregations unless their rounding slippage is <x bps",
 		},
+		&support.ConfigOption{
+			Name:        "kinesis-treasury-config-path",
+			ConfigKey:   &config.KinesisTreasuryConfigPath,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage:       "path to a TOML file listing the kinesis treasury accounts (root, emission, hot_wallets and feepool) used to compute the coin in circulation",
+		},
+		&support.ConfigOption{
+			Name:        "kinesis-root-account",
+			ConfigKey:   &config.KinesisTreasuryAccounts.RootAccount,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage:       "kinesis root account, defaults to the account derived from the network passphrase",
+		},
+		&support.ConfigOption{
+			Name:        "kinesis-emission-account",
+			ConfigKey:   &config.KinesisTreasuryAccounts.EmissionAccount,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage:       "kinesis emission account, defaults to the account derived from the network passphrase and \"emission\"",
+		},
+		&support.ConfigOption{
+			Name:        "kinesis-hot-wallet-accounts",
+			ConfigKey:   &config.KinesisTreasuryAccounts.HotWalletAccounts,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			CustomSetValue: func(co *support.ConfigOption) error {
+				var accounts []string
+				for _, account := range strings.Split(viper.GetString(co.Name), ",") {
+					if account = strings.TrimSpace(account); account != "" {
+						accounts = append(accounts, account)
+					}
+				}
+				*(co.ConfigKey.(*[]string)) = accounts
+				return nil
+			},
+			Usage: "comma-separated list of kinesis hot wallet accounts, defaults to the account derived from the network passphrase and \"exchange\"",
+		},
+		&support.ConfigOption{
+			Name:        "kinesis-feepool-account",
+			ConfigKey:   &config.KinesisTreasuryAccounts.FeepoolAccount,
+			OptType:     types.String,
+			FlagDefault: "",
+			Required:    false,
+			Usage:       "kinesis fee pool account, defaults to the account derived from the network passphrase and \"feepool\"",
+		},
 	}
 
 	return config, flags
@@ -576,6 +741,10 @@ func ApplyFlags(config *Config, flags support.ConfigOptions, options ApplyOption
 		return err
 	}
 
+	if err := loadKinesisTreasuryAccounts(config); err != nil {
+		return err
+	}
+
 	if options.AlwaysIngest {
 		config.Ingest = true
 	}
@@ -2,8 +2,10 @@ package httpx
 
 import (
 	"database/sql"
+	"encoding/csv"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
 	horizonContext "github.com/stellar/go/services/horizon/internal/context"
@@ -29,6 +31,12 @@ type ObjectActionHandler struct {
 	Action objectAction
 }
 
+// responseWithStatus is implemented by the resources which are not rendered
+// with a 200 status code.
+type responseWithStatus interface {
+	GetStatus() int
+}
+
 func (handler ObjectActionHandler) ServeHTTP(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -41,8 +49,14 @@ func (handler ObjectActionHandler) ServeHTTP(
 			return
 		}
 
-		httpjson.Render(
+		statusCode := http.StatusOK
+		if withStatus, ok := response.(responseWithStatus); ok && withStatus.GetStatus() != 0 {
+			statusCode = withStatus.GetStatus()
+		}
+
+		httpjson.RenderStatus(
 			w,
+			statusCode,
 			response,
 			httpjson.HALJSON,
 		)
@@ -52,6 +66,43 @@ func (handler ObjectActionHandler) ServeHTTP(
 	problem.Render(r.Context(), w, hProblem.NotAcceptable)
 }
 
+// transactionBatchHandler serves batch submissions. The results are rendered
+// at once when all the transactions of the batch are final, or streamed as
+// Server Sent Events as soon as each of them is final.
+type transactionBatchHandler struct {
+	action actions.SubmitTransactionBatchHandler
+}
+
+func (handler transactionBatchHandler) ServeHTTP(
+	w http.ResponseWriter,
+	r *http.Request,
+) {
+	switch render.Negotiate(r) {
+	case render.MimeHal, render.MimeJSON:
+		ObjectActionHandler{handler.action}.ServeHTTP(w, r)
+		return
+	case render.MimeEventStream:
+		results, err := handler.action.Submit(r)
+		if err != nil {
+			problem.Render(r.Context(), w, err)
+			return
+		}
+		if !sse.WritePreamble(r.Context(), w) {
+			return
+		}
+		for result := range results {
+			sse.WriteEvent(r.Context(), w, sse.Event{
+				ID:   strconv.Itoa(result.Index),
+				Data: result,
+			})
+		}
+		sse.WriteEvent(r.Context(), w, sse.Event{Event: "close", Data: "byebye"})
+		return
+	}
+
+	problem.Render(r.Context(), w, hProblem.NotAcceptable)
+}
+
 const defaultObjectStreamLimit = 10
 
 type streamableObjectAction interface {
@@ -151,6 +202,14 @@ type pageAction interface {
 	GetResourcePage(w actions.HeaderWriter, r *http.Request) ([]hal.Pageable, error)
 }
 
+// csvPageAction is a pageAction which can also render its records as
+// text/csv.
+type csvPageAction interface {
+	pageAction
+	CSVHeader() []string
+	CSVRecord(record hal.Pageable) []string
+}
+
 type pageActionHandler struct {
 	action         pageAction
 	streamable     bool
@@ -216,6 +275,28 @@ func (handler pageActionHandler) renderPage(w http.ResponseWriter, r *http.Reque
 	)
 }
 
+func (handler pageActionHandler) renderCSV(w http.ResponseWriter, r *http.Request, action csvPageAction) {
+	records, err := action.GetResourcePage(w, r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", render.MimeCSV)
+	w.WriteHeader(http.StatusOK)
+
+	out := csv.NewWriter(w)
+	if err := out.Write(action.CSVHeader()); err != nil {
+		return
+	}
+	for _, record := range records {
+		if err := out.Write(action.CSVRecord(record)); err != nil {
+			return
+		}
+	}
+	out.Flush()
+}
+
 func (handler pageActionHandler) renderStream(w http.ResponseWriter, r *http.Request) {
 	// Use pq to Get SSE limit.
 	pq, err := actions.GetPageQuery(handler.ledgerState, r)
@@ -274,6 +355,11 @@ func (handler pageActionHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 			handler.renderStream(w, r)
 			return
 		}
+	case render.MimeCSV:
+		if action, ok := handler.action.(csvPageAction); ok {
+			handler.renderCSV(w, r, action)
+			return
+		}
 	}
 
 	problem.Render(r.Context(), w, hProblem.NotAcceptable)
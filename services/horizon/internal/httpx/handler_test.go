@@ -0,0 +1,85 @@
+package httpx
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stellar/go/services/horizon/internal/actions"
+	"github.com/stellar/go/services/horizon/internal/corestate"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/render"
+	"github.com/stellar/go/support/render/hal"
+	"github.com/stretchr/testify/assert"
+)
+
+type testCSVPageAction struct {
+	testPageAction
+}
+
+func (action *testCSVPageAction) CSVHeader() []string {
+	return []string{"paging_token", "value"}
+}
+
+func (action *testCSVPageAction) CSVRecord(record hal.Pageable) []string {
+	return []string{record.PagingToken(), record.(testPage).Value}
+}
+
+func TestPageCSV(t *testing.T) {
+	ledgerSource := ledger.NewTestingSource(3)
+	action := &testCSVPageAction{testPageAction{
+		objects:      map[uint32][]string{3: {"a", "b,c", "d"}},
+		ledgerSource: ledgerSource,
+	}}
+
+	request := streamRequest(t, "cursor=1")
+	request.Header.Set("Accept", render.MimeCSV)
+	w := httptest.NewRecorder()
+	restPageHandler(&ledger.State{}, action).ServeHTTP(w, request)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, render.MimeCSV, w.Header().Get("Content-Type"))
+	assert.Equal(t, "paging_token,value\n2,\"b,c\"\n3,d\n", w.Body.String())
+
+	// actions which can't render csv are not acceptable
+	request = streamRequest(t, "")
+	request.Header.Set("Accept", render.MimeCSV)
+	w = httptest.NewRecorder()
+	restPageHandler(&ledger.State{}, &action.testPageAction).ServeHTTP(w, request)
+	assert.Equal(t, 406, w.Code)
+}
+
+type syncedCoreStateGetter struct{}
+
+func (syncedCoreStateGetter) GetCoreState() corestate.State {
+	return corestate.State{Synced: true}
+}
+
+func TestTransactionBatchStream(t *testing.T) {
+	handler := transactionBatchHandler{actions.SubmitTransactionBatchHandler{
+		CoreStateGetter: syncedCoreStateGetter{},
+	}}
+
+	request := httptest.NewRequest("POST", "/transactions_batch", strings.NewReader(`{"transactions": ["AAAA"]}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", render.MimeEventStream)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/event-stream; charset=utf-8", w.Header().Get("Content-Type"))
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	if assert.Len(t, events, 3) {
+		assert.Contains(t, events[0], "event: open")
+		assert.Contains(t, events[1], "id: 0\n")
+		assert.Contains(t, events[1], `"type":"transaction_malformed"`)
+		assert.Contains(t, events[2], "event: close")
+	}
+
+	// invalid batches are rendered as problems
+	request = httptest.NewRequest("POST", "/transactions_batch", strings.NewReader(`{"transactions": []}`))
+	request.Header.Set("Accept", render.MimeEventStream)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+	assert.Equal(t, 400, w.Code)
+}
@@ -92,6 +92,13 @@ func loggerMiddleware(serverMetrics *ServerMetrics) func(next http.Handler) http
 func timeoutMiddleware(timeout time.Duration) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
+			// WebSocket connections are kept alive with pings, their
+			// subscriptions are requests with their own timeout.
+			if isWebSocketRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			mw := newWrapResponseWriter(w, r)
 			ctx, cancel := context.WithTimeout(r.Context(), timeout)
 			defer func() {
@@ -1,18 +1,29 @@
 package httpx
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stellar/throttled"
 
 	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/ratelimit"
+	"github.com/stellar/go/services/horizon/internal/render"
 	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/support/log"
 	"github.com/stellar/go/support/render/problem"
 )
 
-const lruCacheSize = 50000
+const (
+	// apiKeyHeader and apiKeyQueryParam carry the API key of a request, the
+	// header takes precedence.
+	apiKeyHeader     = "X-API-Key"
+	apiKeyQueryParam = "api_key"
+)
 
 type historyLedgerSourceFactory struct {
 	updateFrequency time.Duration
@@ -33,24 +44,99 @@ func remoteAddrIP(r *http.Request) string {
 	}
 }
 
-type VaryByRemoteIP struct{}
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	return r.URL.Query().Get(apiKeyQueryParam)
+}
+
+func isPathFindingRequest(r *http.Request) bool {
+	return r.URL.Path == "/paths" || strings.HasPrefix(r.URL.Path, "/paths/")
+}
+
+// rateLimitMiddleware limits the rate of the requests by API key, or by IP
+// address for anonymous requests. Streaming requests are only checked for a
+// valid API key, their updates are rate limited by StreamHandler.ServeStream()
+// through streamRateLimiter. The requests are let through when the state of
+// the limits cannot be read from the store, e.g. when Redis is unreachable,
+// rather than failing every request; these errors are counted by errors.
+type rateLimitMiddleware struct {
+	limiter *ratelimit.Limiter
+	errors  prometheus.Counter
+}
+
+func (m rateLimitMiddleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := requestAPIKey(r)
+		if render.Negotiate(r) == render.MimeEventStream {
+			if key != "" && !m.limiter.HasAPIKey(key) {
+				problem.Render(r.Context(), w, hProblem.InvalidAPIKey)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		category := ratelimit.Requests
+		if isPathFindingRequest(r) {
+			category = ratelimit.PathFinding
+		}
+		limited, result, err := m.limiter.RateLimit(key, remoteAddrIP(r), category)
+		if err == ratelimit.ErrInvalidAPIKey {
+			problem.Render(r.Context(), w, hProblem.InvalidAPIKey)
+			return
+		} else if err != nil {
+			logRateLimitError(r, m.errors, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		setRateLimitHeaders(w, result)
+		if limited {
+			problem.Render(r.Context(), w, hProblem.RateLimitExceeded)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setRateLimitHeaders sets the X-RateLimit-* and Retry-After headers like
+// throttled.HTTPRateLimiter.
+func setRateLimitHeaders(w http.ResponseWriter, result throttled.RateLimitResult) {
+	if v := result.Limit; v >= 0 {
+		w.Header().Add("X-RateLimit-Limit", strconv.Itoa(v))
+	}
+	if v := result.Remaining; v >= 0 {
+		w.Header().Add("X-RateLimit-Remaining", strconv.Itoa(v))
+	}
+	if v := result.ResetAfter; v >= 0 {
+		w.Header().Add("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(v.Seconds()))))
+	}
+	if v := result.RetryAfter; v >= 0 {
+		w.Header().Add("Retry-After", strconv.Itoa(int(math.Ceil(v.Seconds()))))
+	}
+}
 
-func (v VaryByRemoteIP) Key(r *http.Request) string {
-	return remoteAddrIP(r)
+// streamRateLimiter limits the rate of the updates of streams with the
+// streams quota of the client.
+type streamRateLimiter struct {
+	limiter *ratelimit.Limiter
+	errors  prometheus.Counter
 }
 
-func newRateLimiter(rateQuota *throttled.RateQuota) (*throttled.HTTPRateLimiter, error) {
-	rateLimiter, err := throttled.NewGCRARateLimiter(lruCacheSize, *rateQuota)
-	if err != nil {
-		return nil, err
+func (s streamRateLimiter) RateLimit(r *http.Request) (bool, error) {
+	limited, _, err := s.limiter.RateLimit(requestAPIKey(r), remoteAddrIP(r), ratelimit.Streams)
+	if err != nil && err != ratelimit.ErrInvalidAPIKey {
+		logRateLimitError(r, s.errors, err)
+		return false, nil
 	}
+	return limited, err
+}
 
-	result := &throttled.HTTPRateLimiter{
-		RateLimiter: rateLimiter,
-		DeniedHandler: http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
-			problem.Render(request.Context(), w, hProblem.RateLimitExceeded)
-		}),
-		VaryBy: VaryByRemoteIP{},
+func logRateLimitError(r *http.Request, errors prometheus.Counter, err error) {
+	log.Ctx(r.Context()).WithError(err).Warn("Could not rate limit the request, letting it through")
+	if errors != nil {
+		errors.Inc()
 	}
-	return result, nil
 }
@@ -0,0 +1,152 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/ratelimit"
+)
+
+func newRateLimitTestHandler(t *testing.T) http.Handler {
+	store, err := ratelimit.NewMemoryStore(100)
+	require.NoError(t, err)
+	limiter, err := ratelimit.NewLimiter(store, ratelimit.Quotas{
+		Requests:    ratelimit.PerHour(10, 1),
+		PathFinding: ratelimit.PerHour(10, 0),
+	})
+	require.NoError(t, err)
+	require.NoError(t, limiter.SetAccounts([]ratelimit.Account{{
+		Name:      "partner",
+		Quotas:    ratelimit.Quotas{Requests: ratelimit.PerHour(100, 2)},
+		KeyHashes: []string{ratelimit.HashAPIKey("secret")},
+	}}))
+
+	return rateLimitMiddleware{limiter: limiter}.Wrap(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+}
+
+func rateLimitTestRequest(handler http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	request.RemoteAddr = "1.2.3.4:5678"
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, request)
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	handler := newRateLimitTestHandler(t)
+
+	w := rateLimitTestRequest(handler, "/ledgers", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "360", w.Header().Get("X-RateLimit-Reset"))
+
+	w = rateLimitTestRequest(handler, "/ledgers", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = rateLimitTestRequest(handler, "/ledgers", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "360", w.Header().Get("Retry-After"))
+
+	// path finding requests have their own quota
+	w = rateLimitTestRequest(handler, "/paths/strict-send", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	w = rateLimitTestRequest(handler, "/paths", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// streams are not limited by the middleware
+	w = rateLimitTestRequest(handler, "/ledgers", http.Header{"Accept": []string{"text/event-stream"}})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestRateLimitMiddlewareAPIKeys(t *testing.T) {
+	handler := newRateLimitTestHandler(t)
+
+	for i := 0; i < 3; i++ {
+		w := rateLimitTestRequest(handler, "/ledgers", http.Header{"X-Api-Key": []string{"secret"}})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+	}
+	// the query parameter shares the quota of the header
+	w := rateLimitTestRequest(handler, "/ledgers?api_key=secret", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// anonymous requests are limited separately
+	w = rateLimitTestRequest(handler, "/ledgers", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+
+	for _, w := range []*httptest.ResponseRecorder{
+		rateLimitTestRequest(handler, "/ledgers?api_key=wrong", nil),
+		rateLimitTestRequest(handler, "/ledgers", http.Header{"X-Api-Key": []string{"wrong"}}),
+		rateLimitTestRequest(handler, "/ledgers", http.Header{
+			"X-Api-Key": []string{"wrong"},
+			"Accept":    []string{"text/event-stream"},
+		}),
+	} {
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "invalid_api_key")
+	}
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) GetWithTime(key string) (int64, time.Time, error) {
+	return 0, time.Time{}, errors.New("connection refused")
+}
+
+func (failingRateLimitStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func (failingRateLimitStore) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestRateLimitMiddlewareStoreError(t *testing.T) {
+	limiter, err := ratelimit.NewLimiter(failingRateLimitStore{}, ratelimit.Quotas{
+		Requests: ratelimit.PerHour(10, 1),
+	})
+	require.NoError(t, err)
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "rate_limit_errors_count"})
+	handler := rateLimitMiddleware{limiter: limiter, errors: counter}.Wrap(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	// the requests are let through and the errors are counted
+	w := rateLimitTestRequest(handler, "/ledgers", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = rateLimitTestRequest(handler, "/ledgers", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+
+	// invalid API keys are still rejected
+	w = rateLimitTestRequest(handler, "/ledgers?api_key=unknown", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	limited, err := streamRateLimiter{limiter: limiter, errors: counter}.RateLimit(
+		httptest.NewRequest(http.MethodGet, "/ledgers", nil),
+	)
+	assert.NoError(t, err)
+	assert.False(t, limited)
+	assert.Equal(t, float64(3), testutil.ToFloat64(counter))
+}
@@ -2,7 +2,6 @@ package httpx
 
 import (
 	"compress/flate"
-	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"net/url"
@@ -13,13 +12,12 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
-	"github.com/stellar/throttled"
 
 	"github.com/stellar/go/services/horizon/internal/actions"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/services/horizon/internal/paths"
-	"github.com/stellar/go/services/horizon/internal/render"
+	"github.com/stellar/go/services/horizon/internal/ratelimit"
 	"github.com/stellar/go/services/horizon/internal/render/sse"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/support/db"
@@ -31,7 +29,9 @@ type RouterConfig struct {
 	DBSession        db.SessionInterface
 	PrimaryDBSession db.SessionInterface
 	TxSubmitter      *txsub.System
-	RateQuota        *throttled.RateQuota
+	// RateLimiter limits the rate of the requests, rate limiting is disabled
+	// when it is nil.
+	RateLimiter *ratelimit.Limiter
 
 	BehindCloudflare        bool
 	BehindAWSLoadBalancer   bool
@@ -47,6 +47,7 @@ type RouterConfig struct {
 	HorizonVersion          string
 	FriendbotURL            *url.URL
 	HealthCheck             http.Handler
+	KinesisTreasuryAccounts history.KinesisTreasuryAccounts
 }
 
 type Router struct {
@@ -59,21 +60,12 @@ func NewRouter(config *RouterConfig, serverMetrics *ServerMetrics, ledgerState *
 		Mux:      chi.NewMux(),
 		Internal: chi.NewMux(),
 	}
-	var rateLimiter *throttled.HTTPRateLimiter
-	if config.RateQuota != nil {
-		var err error
-		rateLimiter, err = newRateLimiter(config.RateQuota)
-		if err != nil {
-			return nil, fmt.Errorf("unable to create RateLimiter: %v", err)
-		}
-	}
-	result.addMiddleware(config, rateLimiter, serverMetrics)
-	result.addRoutes(config, rateLimiter, ledgerState)
+	result.addMiddleware(config, serverMetrics)
+	result.addRoutes(config, serverMetrics, ledgerState)
 	return &result, nil
 }
 
 func (r *Router) addMiddleware(config *RouterConfig,
-	rateLimitter *throttled.HTTPRateLimiter,
 	serverMetrics *ServerMetrics) {
 
 	r.Use(chimiddleware.StripSlashes)
@@ -97,19 +89,11 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	})
 	r.Use(c.Handler)
 
-	if rateLimitter != nil {
-		r.Use(func(handler http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Exempt streaming requests from rate limits via the HTTP middleware
-				// because rate limiting for streaming requests are already implemented in
-				// StreamHandler.ServeStream().
-				if render.Negotiate(r) == render.MimeEventStream {
-					handler.ServeHTTP(w, r)
-					return
-				}
-				rateLimitter.RateLimit(handler).ServeHTTP(w, r)
-			})
-		})
+	if config.RateLimiter != nil {
+		r.Use(rateLimitMiddleware{
+			limiter: config.RateLimiter,
+			errors:  serverMetrics.RateLimitErrorsCounter,
+		}.Wrap)
 	}
 
 	if config.PrimaryDBSession != nil {
@@ -127,7 +111,7 @@ func (r *Router) addMiddleware(config *RouterConfig,
 	r.Internal.Use(loggerMiddleware(serverMetrics))
 }
 
-func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRateLimiter, ledgerState *ledger.State) {
+func (r *Router) addRoutes(config *RouterConfig, serverMetrics *ServerMetrics, ledgerState *ledger.State) {
 	stateMiddleware := StateMiddleware{
 		HorizonSession: config.DBSession,
 	}
@@ -143,9 +127,14 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	}})
 
 	streamHandler := sse.StreamHandler{
-		RateLimiter:         rateLimiter,
 		LedgerSourceFactory: historyLedgerSourceFactory{ledgerState: ledgerState, updateFrequency: config.SSEUpdateFrequency},
 	}
+	if config.RateLimiter != nil {
+		streamHandler.RateLimiter = streamRateLimiter{
+			limiter: config.RateLimiter,
+			errors:  serverMetrics.RateLimitErrorsCounter,
+		}
+	}
 
 	historyMiddleware := NewHistoryMiddleware(ledgerState, int32(config.StaleThreshold), config.DBSession)
 	// State endpoints behind stateMiddleware
@@ -158,7 +147,7 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 					"/",
 					streamableObjectActionHandler{
 						streamHandler: streamHandler,
-						action:        actions.GetAccountByIDHandler{},
+						action:        actions.GetAccountByIDHandler{LedgerState: ledgerState},
 					},
 				)
 				accountData := actions.GetAccountDataHandler{}
@@ -237,6 +226,8 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		}, streamHandler))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
 		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/transactions", streamableHistoryPageHandler(ledgerState, actions.GetTransactionsHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/balances/history", streamableHistoryPageHandler(ledgerState, actions.GetAccountBalanceHistoryHandler{LedgerState: ledgerState}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/accounts/{account_id:\\w+}/statement", HandleRaw(actions.GetAccountStatementHandler{}))
 	})
 	// ledger actions
 	r.Route("/ledgers", func(r chi.Router) {
@@ -312,6 +303,10 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		r.With(historyMiddleware).Method(http.MethodGet, "/offers/{offer_id}/trades", streamableHistoryPageHandler(ledgerState, actions.GetTradesHandler{LedgerState: ledgerState, CoreStateGetter: config.CoreGetter}, streamHandler))
 	})
 
+	// streams multiplexed over WebSocket connections, the subscriptions are
+	// routed like any other streaming request
+	r.Method(http.MethodGet, "/ws", webSocketHandler{handler: r.Mux})
+
 	// Transaction submission API
 	r.Method(http.MethodPost, "/transactions", ObjectActionHandler{actions.SubmitTransactionHandler{
 		Submitter:         config.TxSubmitter,
@@ -319,19 +314,44 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 		CoreStateGetter:   config.CoreGetter,
 	}})
 
+	r.Method(http.MethodPost, "/transactions_async", ObjectActionHandler{actions.AsyncSubmitTransactionHandler{
+		Submitter:         config.TxSubmitter,
+		NetworkPassphrase: config.NetworkPassphrase,
+		CoreStateGetter:   config.CoreGetter,
+	}})
+	r.With(historyMiddleware).Method(http.MethodGet, "/transactions_async/{tx_id}", ObjectActionHandler{actions.GetAsyncTransactionStatusHandler{
+		Submitter: config.TxSubmitter,
+	}})
+
+	r.Method(http.MethodPost, "/transactions_batch", transactionBatchHandler{actions.SubmitTransactionBatchHandler{
+		Submitter:         config.TxSubmitter,
+		NetworkPassphrase: config.NetworkPassphrase,
+		CoreStateGetter:   config.CoreGetter,
+	}})
+
+	r.With(stateMiddleware.Wrap).Method(http.MethodPost, "/transactions/simulate", ObjectActionHandler{actions.SimulateTransactionHandler{
+		NetworkPassphrase: config.NetworkPassphrase,
+	}})
+
 	// Network state related endpoints
 	r.Method(http.MethodGet, "/fee_stats", ObjectActionHandler{actions.FeeStatsHandler{}})
 
 	// Kinesis Coin-in-Circulation dataset
 	r.Route("/coin_in_circulation", func(r chi.Router) {
 		r.With(historyMiddleware).Method(http.MethodGet, "/", ObjectActionHandler{actions.KinesisCoinInCirculationHandler{
-			NetworkPassphrase: config.NetworkPassphrase,
-			LedgerState:       ledgerState,
+			LedgerState:      ledgerState,
+			TreasuryAccounts: config.KinesisTreasuryAccounts,
 		}})
 		r.With(historyMiddleware).Method(http.MethodGet, "/ledger/{ledger_id}", ObjectActionHandler{actions.GetKinesisCoinInCirculationByLedgerHandler{
-			NetworkPassphrase: config.NetworkPassphrase,
-			LedgerState:       ledgerState,
+			LedgerState: ledgerState,
 		}})
+		r.With(historyMiddleware).Method(http.MethodGet, "/records", streamableHistoryPageHandler(ledgerState, actions.KinesisCoinInCirculationRecordsHandler{
+			LedgerState: ledgerState,
+		}, streamHandler))
+		r.With(historyMiddleware).Method(http.MethodGet, "/events", streamableHistoryPageHandler(ledgerState, actions.KinesisCoinInCirculationEventsHandler{
+			LedgerState:      ledgerState,
+			TreasuryAccounts: config.KinesisTreasuryAccounts,
+		}, streamHandler))
 	})
 
 	// friendbot
@@ -352,4 +372,24 @@ func (r *Router) addRoutes(config *RouterConfig, rateLimiter *throttled.HTTPRate
 	r.Internal.Get("/metrics", promhttp.HandlerFor(config.PrometheusRegistry, promhttp.HandlerOpts{}).ServeHTTP)
 	r.Internal.Get("/debug/pprof/heap", pprof.Index)
 	r.Internal.Get("/debug/pprof/profile", pprof.Profile)
+	// the rules of the ingestion filters and the webhooks are written to the
+	// primary database
+	adminSession := config.DBSession
+	if config.PrimaryDBSession != nil {
+		adminSession = config.PrimaryDBSession
+	}
+	r.Internal.Route("/ingestion/filters", func(r chi.Router) {
+		r.Use(NewHistoryMiddleware(ledgerState, 0, adminSession))
+		r.Method(http.MethodGet, "/", ObjectActionHandler{actions.GetFilterRulesHandler{}})
+		r.Method(http.MethodGet, "/{name}", ObjectActionHandler{actions.GetFilterRuleHandler{}})
+		r.Method(http.MethodPut, "/{name}", ObjectActionHandler{actions.UpdateFilterRuleHandler{}})
+	})
+	r.Internal.Route("/webhooks", func(r chi.Router) {
+		r.Use(NewHistoryMiddleware(ledgerState, 0, adminSession))
+		r.Method(http.MethodGet, "/", ObjectActionHandler{actions.GetWebhooksHandler{}})
+		r.Method(http.MethodPost, "/", ObjectActionHandler{actions.CreateWebhookHandler{}})
+		r.Method(http.MethodGet, "/{id}", ObjectActionHandler{actions.GetWebhookHandler{}})
+		r.Method(http.MethodDelete, "/{id}", ObjectActionHandler{actions.DeleteWebhookHandler{}})
+		r.Method(http.MethodGet, "/{id}/deliveries", ObjectActionHandler{actions.GetWebhookDeliveriesHandler{}})
+	})
 }
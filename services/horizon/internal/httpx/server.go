@@ -24,6 +24,7 @@ import (
 type ServerMetrics struct {
 	RequestDurationSummary  *prometheus.SummaryVec
 	ReplicaLagErrorsCounter prometheus.Counter
+	RateLimitErrorsCounter  prometheus.Counter
 }
 
 type TLSConfig struct {
@@ -77,6 +78,12 @@ func NewServer(serverConfig ServerConfig, routerConfig RouterConfig, ledgerState
 				Help: "Count of HTTP errors returned due to replica lag",
 			},
 		),
+		RateLimitErrorsCounter: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "horizon", Subsystem: "http", Name: "rate_limit_errors_count",
+				Help: "Count of requests let through because the rate limit store failed",
+			},
+		),
 	}
 	router, err := NewRouter(&routerConfig, sm, ledgerState)
 	if err != nil {
@@ -109,6 +116,7 @@ func NewServer(serverConfig ServerConfig, routerConfig RouterConfig, ledgerState
 func (s *Server) RegisterMetrics(registry *prometheus.Registry) {
 	registry.MustRegister(s.Metrics.RequestDurationSummary)
 	registry.MustRegister(s.Metrics.ReplicaLagErrorsCounter)
+	registry.MustRegister(s.Metrics.RateLimitErrorsCounter)
 }
 
 func (s *Server) Serve() error {
@@ -0,0 +1,510 @@
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/render"
+	hProblem "github.com/stellar/go/services/horizon/internal/render/problem"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/render/problem"
+)
+
+const (
+	// maxWebSocketSubscriptions is the maximum number of subscriptions of a
+	// WebSocket connection.
+	maxWebSocketSubscriptions = 100
+	// maxWebSocketRequestSize is the maximum size of the messages sent by
+	// clients.
+	maxWebSocketRequestSize = 4096
+	webSocketWriteTimeout   = 10 * time.Second
+	// the connections are closed when no pong is received for
+	// webSocketPongTimeout, pings are sent every webSocketPingPeriod.
+	webSocketPongTimeout = 60 * time.Second
+	webSocketPingPeriod  = 30 * time.Second
+)
+
+// webSocketRequestHeaders are the headers of the upgrade request which are
+// not copied to the requests of the subscriptions.
+var webSocketRequestHeaders = []string{
+	"Accept",
+	"Accept-Encoding",
+	"Connection",
+	"Last-Event-ID",
+	"Sec-WebSocket-Extensions",
+	"Sec-WebSocket-Key",
+	"Sec-WebSocket-Protocol",
+	"Sec-WebSocket-Version",
+	"Upgrade",
+}
+
+var webSocketUpgrader = websocket.Upgrader{
+	// Horizon can be requested from any origin, see the cors middleware.
+	CheckOrigin: func(r *http.Request) bool { return true },
+	Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+		p := problem.BadRequest
+		p.Status = status
+		p.Detail = reason.Error()
+		problem.Render(r.Context(), w, p)
+	},
+}
+
+func isWebSocketRequest(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r)
+}
+
+// webSocketHandler serves the /ws endpoint, which multiplexes streams over a
+// single WebSocket connection. Clients subscribe to the path of any streaming
+// endpoint, each subscription is served by handler as a streaming request of
+// its path whose events are sent over the connection instead of Server Sent
+// Events. This way the subscriptions go through the same middlewares, action
+// handlers and stream rate limits as SSE streams.
+type webSocketHandler struct {
+	handler http.Handler
+}
+
+func (h webSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := webSocketUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied to the client
+		return
+	}
+	newWebSocketConnection(h.handler, conn, r).serve()
+}
+
+type webSocketSubscription struct {
+	cancel       context.CancelFunc
+	unsubscribed bool
+}
+
+type webSocketConnection struct {
+	handler http.Handler
+	conn    *websocket.Conn
+	// request is the upgrade request, its headers are copied to the
+	// requests of the subscriptions.
+	request  *http.Request
+	ctx      context.Context
+	cancel   context.CancelFunc
+	messages chan hProtocol.WebSocketMessage
+	wg       sync.WaitGroup
+
+	mutex         sync.Mutex
+	subscriptions map[string]*webSocketSubscription
+}
+
+func newWebSocketConnection(handler http.Handler, conn *websocket.Conn, r *http.Request) *webSocketConnection {
+	// The requests of the subscriptions are routed from scratch, so their
+	// context must not be derived from the context of r.
+	ctx, cancel := context.WithCancel(context.Background())
+	return &webSocketConnection{
+		handler:       handler,
+		conn:          conn,
+		request:       r,
+		ctx:           ctx,
+		cancel:        cancel,
+		messages:      make(chan hProtocol.WebSocketMessage, maxWebSocketSubscriptions),
+		subscriptions: map[string]*webSocketSubscription{},
+	}
+}
+
+// serve handles the requests of the client until the connection is closed.
+func (c *webSocketConnection) serve() {
+	defer c.conn.Close()
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		c.writeMessages()
+	}()
+
+	c.readRequests()
+	c.cancel()
+	c.wg.Wait()
+	<-writerDone
+}
+
+func (c *webSocketConnection) readRequests() {
+	c.conn.SetReadLimit(maxWebSocketRequestSize)
+	c.conn.SetReadDeadline(time.Now().Add(webSocketPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(webSocketPongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request hProtocol.WebSocketRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			p := problem.BadRequest
+			p.Detail = "The message could not be parsed as a JSON object."
+			c.sendError("", p)
+			continue
+		}
+
+		switch request.Type {
+		case hProtocol.WebSocketSubscribe:
+			c.subscribe(request)
+		case hProtocol.WebSocketUnsubscribe:
+			c.unsubscribe(request)
+		default:
+			c.sendError(request.ID, *problem.MakeInvalidFieldProblem(
+				"type",
+				errors.New("type must be subscribe or unsubscribe"),
+			))
+		}
+	}
+}
+
+// writeMessages sends the messages of the subscriptions and keeps the
+// connection alive until it is closed. It is the only writer of the
+// connection.
+func (c *webSocketConnection) writeMessages() {
+	ticker := time.NewTicker(webSocketPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-c.messages:
+			c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+			if err := c.conn.WriteJSON(message); err != nil {
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close()
+				return
+			}
+		case <-c.ctx.Done():
+			c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(webSocketWriteTimeout),
+			)
+			return
+		}
+	}
+}
+
+// close ends the subscriptions and unblocks readRequests when the connection
+// is broken.
+func (c *webSocketConnection) close() {
+	c.cancel()
+	c.conn.Close()
+}
+
+// send queues a message, it returns false if ctx is done first.
+func (c *webSocketConnection) send(ctx context.Context, message hProtocol.WebSocketMessage) bool {
+	select {
+	case c.messages <- message:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (c *webSocketConnection) sendError(id string, p problem.P) {
+	c.send(c.ctx, hProtocol.WebSocketMessage{
+		Type:  hProtocol.WebSocketError,
+		ID:    id,
+		Error: renderWebSocketProblem(c.ctx, p),
+	})
+}
+
+// problemResponse is the http.ResponseWriter problems are rendered to before
+// being sent over WebSocket connections.
+type problemResponse struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *problemResponse) Header() http.Header            { return r.header }
+func (r *problemResponse) Write(data []byte) (int, error) { return r.body.Write(data) }
+func (r *problemResponse) WriteHeader(int)                {}
+
+// renderWebSocketProblem returns p as it is rendered in HTTP responses, i.e.
+// with the service host prepended to its type.
+func renderWebSocketProblem(ctx context.Context, p problem.P) *problem.P {
+	response := &problemResponse{header: http.Header{}}
+	problem.Render(ctx, response, p)
+	var rendered problem.P
+	if err := json.Unmarshal(response.body.Bytes(), &rendered); err != nil {
+		return &p
+	}
+	return &rendered
+}
+
+func (c *webSocketConnection) subscribe(request hProtocol.WebSocketRequest) {
+	if request.ID == "" {
+		c.sendError("", *problem.MakeInvalidFieldProblem("id", errors.New("id is required")))
+		return
+	}
+	path, err := webSocketSubscriptionPath(request.Path)
+	if err != nil {
+		c.sendError(request.ID, *problem.MakeInvalidFieldProblem("path", err))
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.subscriptions[request.ID]; ok {
+		c.sendError(request.ID, *problem.MakeInvalidFieldProblem(
+			"id",
+			errors.New("a subscription with this id already exists"),
+		))
+		return
+	}
+	if len(c.subscriptions) >= maxWebSocketSubscriptions {
+		p := problem.BadRequest
+		p.Detail = "The connection has too many subscriptions, unsubscribe from a stream or open a new connection."
+		c.sendError(request.ID, p)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	c.subscriptions[request.ID] = &webSocketSubscription{cancel: cancel}
+	stream := &webSocketStream{connection: c, ctx: ctx, id: request.ID, path: path}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		stream.serve()
+		c.endSubscription(request.ID)
+	}()
+}
+
+func (c *webSocketConnection) unsubscribe(request hProtocol.WebSocketRequest) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	subscription, ok := c.subscriptions[request.ID]
+	if !ok {
+		c.sendError(request.ID, *problem.MakeInvalidFieldProblem(
+			"id",
+			errors.New("there is no subscription with this id"),
+		))
+		return
+	}
+	// the subscription replies once its stream is done
+	subscription.unsubscribed = true
+	subscription.cancel()
+}
+
+func (c *webSocketConnection) endSubscription(id string) {
+	c.mutex.Lock()
+	subscription := c.subscriptions[id]
+	delete(c.subscriptions, id)
+	c.mutex.Unlock()
+
+	subscription.cancel()
+	if subscription.unsubscribed {
+		c.send(c.ctx, hProtocol.WebSocketMessage{Type: hProtocol.WebSocketUnsubscribed, ID: id})
+	}
+}
+
+// webSocketSubscriptionPath validates the path of a subscription.
+func webSocketSubscriptionPath(path string) (string, error) {
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return "", errors.New("path must be the absolute path of a streaming endpoint, e.g. /ledgers?cursor=now")
+	}
+	return u.RequestURI(), nil
+}
+
+// webSocketStream serves a subscription as a sequence of streaming requests
+// of its path, it is the http.ResponseWriter and the sse.EventWriter of these
+// requests. When Horizon ends a stream, e.g. because its limit is reached or
+// the connection timeout elapsed, a new request resumes it from the last event
+// like an SSE client would do.
+type webSocketStream struct {
+	connection *webSocketConnection
+	ctx        context.Context
+	id         string
+	path       string
+
+	// started is set once the first request of the subscription started
+	// streaming, failed once an error was sent to the client.
+	started     bool
+	failed      bool
+	lastEventID string
+	// lastData is the data of the last event without ID, the streams of
+	// objects send their current state whenever they are restarted.
+	lastData []byte
+
+	// the state of the current request
+	header http.Header
+	status int
+	body   bytes.Buffer
+	opened bool
+	closed bool
+	retry  int
+}
+
+func (s *webSocketStream) serve() {
+	for {
+		s.serveRequest()
+		if s.failed || !s.closed {
+			return
+		}
+
+		select {
+		case <-time.After(time.Duration(s.retry) * time.Millisecond):
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *webSocketStream) serveRequest() {
+	s.header = http.Header{}
+	s.status = 0
+	s.body.Reset()
+	s.opened = false
+	s.closed = false
+	s.retry = 0
+
+	r, err := s.newRequest()
+	if err != nil {
+		s.fail(problem.ServerError)
+		return
+	}
+	s.connection.handler.ServeHTTP(s, r)
+
+	if !s.opened && !s.failed && s.ctx.Err() == nil {
+		s.fail(responseProblem(s.status, s.body.Bytes()))
+	}
+}
+
+func (s *webSocketStream) newRequest() (*http.Request, error) {
+	upgrade := s.connection.request
+	r, err := http.NewRequest(http.MethodGet, s.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	r = r.WithContext(sse.WithEventWriter(s.ctx, s))
+	r.Host = upgrade.Host
+	r.RemoteAddr = upgrade.RemoteAddr
+
+	r.Header = upgrade.Header.Clone()
+	for _, header := range webSocketRequestHeaders {
+		r.Header.Del(header)
+	}
+	// the API key of the upgrade request may be a query parameter
+	if apiKey := requestAPIKey(upgrade); apiKey != "" && r.Header.Get(apiKeyHeader) == "" {
+		r.Header.Set(apiKeyHeader, apiKey)
+	}
+	r.Header.Set("Accept", render.MimeEventStream)
+	if s.lastEventID != "" {
+		r.Header.Set("Last-Event-ID", s.lastEventID)
+	}
+	return r, nil
+}
+
+// responseProblem returns the problem of a response which did not start a
+// stream.
+func responseProblem(status int, body []byte) problem.P {
+	var p problem.P
+	if err := json.Unmarshal(body, &p); err == nil && p.Type != "" {
+		return p
+	}
+	if status == 0 || status == http.StatusOK {
+		return hProblem.NotAcceptable
+	}
+	return problem.ServerError
+}
+
+func (s *webSocketStream) fail(p problem.P) {
+	s.failed = true
+	s.connection.send(s.ctx, hProtocol.WebSocketMessage{
+		Type:  hProtocol.WebSocketError,
+		ID:    s.id,
+		Error: renderWebSocketProblem(s.ctx, p),
+	})
+}
+
+// WriteEvent implements sse.EventWriter.
+func (s *webSocketStream) WriteEvent(e sse.Event) {
+	if e.Error != nil {
+		switch p := errors.Cause(e.Error).(type) {
+		case problem.P:
+			s.fail(p)
+		case *problem.P:
+			s.fail(*p)
+		default:
+			s.fail(problem.ServerError)
+		}
+		return
+	}
+
+	switch e.Event {
+	case "open":
+		s.opened = true
+		if !s.started {
+			s.started = true
+			s.connection.send(s.ctx, hProtocol.WebSocketMessage{
+				Type: hProtocol.WebSocketSubscribed,
+				ID:   s.id,
+			})
+		}
+		return
+	case "close":
+		s.closed = true
+		s.retry = e.Retry
+		return
+	}
+
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		s.fail(problem.ServerError)
+		return
+	}
+	if e.ID != "" {
+		s.lastEventID = e.ID
+	} else if bytes.Equal(data, s.lastData) {
+		return
+	} else {
+		s.lastData = data
+	}
+
+	s.connection.send(s.ctx, hProtocol.WebSocketMessage{
+		Type:    hProtocol.WebSocketEvent,
+		ID:      s.id,
+		EventID: e.ID,
+		Data:    data,
+	})
+}
+
+// Header implements http.ResponseWriter.
+func (s *webSocketStream) Header() http.Header {
+	return s.header
+}
+
+// Write implements http.ResponseWriter, only the body of the responses which
+// did not start a stream is kept.
+func (s *webSocketStream) Write(data []byte) (int, error) {
+	if !s.opened {
+		s.body.Write(data)
+	}
+	return len(data), nil
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (s *webSocketStream) WriteHeader(status int) {
+	s.status = status
+}
+
+// Flush implements http.Flusher, the events are sent as soon as they are
+// written.
+func (s *webSocketStream) Flush() {}
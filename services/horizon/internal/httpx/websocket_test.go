@@ -0,0 +1,220 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/ledger"
+	"github.com/stellar/go/services/horizon/internal/render/sse"
+	"github.com/stellar/go/support/render/problem"
+)
+
+func newWebSocketTestServer(t *testing.T) (*httptest.Server, *websocket.Conn) {
+	ledgerSource := ledger.NewTestingSource(3)
+	streamHandler := sse.StreamHandler{LedgerSourceFactory: &testingFactory{ledgerSource}}
+	pageAction := &testPageAction{
+		objects:      map[uint32][]string{3: {"a", "b", "c", "d", "e"}},
+		ledgerSource: ledgerSource,
+	}
+	objectAction := &testObjectAction{
+		objects:      map[uint32]stringObject{3: "a"},
+		ledgerSource: ledgerSource,
+	}
+
+	router := chi.NewRouter()
+	router.Method(http.MethodGet, "/ws", webSocketHandler{handler: router})
+	router.Method(http.MethodGet, "/letters", streamableHistoryPageHandler(&ledger.State{}, pageAction, streamHandler))
+	router.Method(http.MethodGet, "/letter", streamableObjectActionHandler{action: objectAction, streamHandler: streamHandler})
+	router.Method(http.MethodGet, "/letters/count", ObjectActionHandler{})
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.Render(r.Context(), w, problem.NotFound)
+	})
+	server := httptest.NewServer(router)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	return server, conn
+}
+
+func sendWebSocketRequest(t *testing.T, conn *websocket.Conn, request hProtocol.WebSocketRequest) {
+	require.NoError(t, conn.WriteJSON(request))
+}
+
+func readWebSocketMessage(t *testing.T, conn *websocket.Conn) hProtocol.WebSocketMessage {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var message hProtocol.WebSocketMessage
+	require.NoError(t, conn.ReadJSON(&message))
+	return message
+}
+
+func readWebSocketEvent(t *testing.T, conn *websocket.Conn, id string) (string, string) {
+	message := readWebSocketMessage(t, conn)
+	require.Equal(t, hProtocol.WebSocketEvent, message.Type, "%+v", message)
+	require.Equal(t, id, message.ID)
+	var page testPage
+	require.NoError(t, json.Unmarshal(message.Data, &page))
+	return message.EventID, page.Value
+}
+
+func TestWebSocketSubscription(t *testing.T) {
+	server, conn := newWebSocketTestServer(t)
+	defer server.Close()
+	defer conn.Close()
+
+	// the stream is restarted from the last event when its limit is reached
+	sendWebSocketRequest(t, conn, hProtocol.WebSocketRequest{
+		Type: hProtocol.WebSocketSubscribe,
+		ID:   "letters",
+		Path: "/letters?limit=2&cursor=1",
+	})
+	assert.Equal(t, hProtocol.WebSocketMessage{
+		Type: hProtocol.WebSocketSubscribed,
+		ID:   "letters",
+	}, readWebSocketMessage(t, conn))
+	for _, expected := range []struct{ id, value string }{
+		{"2", "b"}, {"3", "c"}, {"4", "d"}, {"5", "e"},
+	} {
+		id, value := readWebSocketEvent(t, conn, "letters")
+		assert.Equal(t, expected.id, id)
+		assert.Equal(t, expected.value, value)
+	}
+
+	sendWebSocketRequest(t, conn, hProtocol.WebSocketRequest{
+		Type: hProtocol.WebSocketUnsubscribe,
+		ID:   "letters",
+	})
+	assert.Equal(t, hProtocol.WebSocketMessage{
+		Type: hProtocol.WebSocketUnsubscribed,
+		ID:   "letters",
+	}, readWebSocketMessage(t, conn))
+
+	// the id can be used again once unsubscribed
+	sendWebSocketRequest(t, conn, hProtocol.WebSocketRequest{
+		Type: hProtocol.WebSocketSubscribe,
+		ID:   "letters",
+		Path: "/letter",
+	})
+	assert.Equal(t, hProtocol.WebSocketSubscribed, readWebSocketMessage(t, conn).Type)
+	message := readWebSocketMessage(t, conn)
+	assert.Equal(t, hProtocol.WebSocketEvent, message.Type)
+	assert.Equal(t, "", message.EventID)
+	assert.JSONEq(t, `"a"`, string(message.Data))
+}
+
+func TestWebSocketErrors(t *testing.T) {
+	server, conn := newWebSocketTestServer(t)
+	defer server.Close()
+	defer conn.Close()
+
+	for _, testCase := range []struct {
+		name     string
+		request  interface{}
+		id       string
+		expected string
+	}{
+		{
+			"invalid message",
+			"subscribe",
+			"",
+			"bad_request",
+		},
+		{
+			"invalid type",
+			hProtocol.WebSocketRequest{Type: "stream", ID: "a"},
+			"a",
+			"bad_request",
+		},
+		{
+			"missing id",
+			hProtocol.WebSocketRequest{Type: hProtocol.WebSocketSubscribe, Path: "/letters"},
+			"",
+			"bad_request",
+		},
+		{
+			"invalid path",
+			hProtocol.WebSocketRequest{Type: hProtocol.WebSocketSubscribe, ID: "a", Path: "http://example.com/letters"},
+			"a",
+			"bad_request",
+		},
+		{
+			"unknown subscription",
+			hProtocol.WebSocketRequest{Type: hProtocol.WebSocketUnsubscribe, ID: "a"},
+			"a",
+			"bad_request",
+		},
+		{
+			"unknown path",
+			hProtocol.WebSocketRequest{Type: hProtocol.WebSocketSubscribe, ID: "a", Path: "/missing"},
+			"a",
+			"not_found",
+		},
+		{
+			"not streamable",
+			hProtocol.WebSocketRequest{Type: hProtocol.WebSocketSubscribe, ID: "a", Path: "/letters/count"},
+			"a",
+			"not_acceptable",
+		},
+		{
+			"invalid cursor",
+			hProtocol.WebSocketRequest{Type: hProtocol.WebSocketSubscribe, ID: "a", Path: "/letters?cursor=-1"},
+			"a",
+			"bad_request",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.NoError(t, conn.WriteJSON(testCase.request))
+			message := readWebSocketMessage(t, conn)
+			assert.Equal(t, hProtocol.WebSocketError, message.Type)
+			assert.Equal(t, testCase.id, message.ID)
+			require.NotNil(t, message.Error)
+			assert.Equal(t, problem.DefaultServiceHost+testCase.expected, message.Error.Type)
+		})
+	}
+
+	t.Run("duplicate id", func(t *testing.T) {
+		request := hProtocol.WebSocketRequest{Type: hProtocol.WebSocketSubscribe, ID: "b", Path: "/letters"}
+		sendWebSocketRequest(t, conn, request)
+		assert.Equal(t, hProtocol.WebSocketSubscribed, readWebSocketMessage(t, conn).Type)
+		for i := 0; i < 5; i++ {
+			readWebSocketEvent(t, conn, "b")
+		}
+
+		sendWebSocketRequest(t, conn, request)
+		message := readWebSocketMessage(t, conn)
+		assert.Equal(t, hProtocol.WebSocketError, message.Type)
+		assert.Equal(t, "b", message.ID)
+		assert.Equal(t, "id", message.Error.Extras["invalid_field"])
+	})
+}
+
+func TestWebSocketSubscriptionPath(t *testing.T) {
+	path, err := webSocketSubscriptionPath("/accounts/GABC/payments?cursor=now")
+	assert.NoError(t, err)
+	assert.Equal(t, "/accounts/GABC/payments?cursor=now", path)
+
+	for _, invalid := range []string{"", "ledgers", "//example.com/ledgers", "https://example.com/ledgers", "%zz"} {
+		_, err := webSocketSubscriptionPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTimeoutMiddlewareWebSocket(t *testing.T) {
+	handler := timeoutMiddleware(time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline := r.Context().Deadline()
+		assert.False(t, hasDeadline)
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+}
@@ -5,6 +5,7 @@ package ingest
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/ingest/ledgerbackend"
@@ -278,6 +279,9 @@ func (s *BuildStateTestSuite) TestUpdateLastLedgerIngestAfterIngestReturnsError(
 	s.historyQ.On("UpdateIngestVersion", s.ctx, CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.MockQAccountBalances.On("SnapshotAccountBalances", s.ctx, s.checkpointLedger, time.Unix(0, 0).UTC()).
+		Return(nil).
+		Once()
 	s.historyQ.On("UpdateLastLedgerIngest", s.ctx, s.checkpointLedger).
 		Return(errors.New("my error")).
 		Once()
@@ -316,6 +320,9 @@ func (s *BuildStateTestSuite) TestUpdateCommitReturnsError() {
 	s.historyQ.On("UpdateIngestVersion", s.ctx, CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.MockQAccountBalances.On("SnapshotAccountBalances", s.ctx, s.checkpointLedger, time.Unix(0, 0).UTC()).
+		Return(nil).
+		Once()
 	s.historyQ.On("Commit").
 		Return(errors.New("my error")).
 		Once()
@@ -338,6 +345,9 @@ func (s *BuildStateTestSuite) TestBuildStateSucceeds() {
 	s.historyQ.On("UpdateIngestVersion", s.ctx, CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.MockQAccountBalances.On("SnapshotAccountBalances", s.ctx, s.checkpointLedger, time.Unix(0, 0).UTC()).
+		Return(nil).
+		Once()
 	s.historyQ.On("Commit").
 		Return(nil).
 		Once()
@@ -366,6 +376,9 @@ func (s *BuildStateTestSuite) TestUpdateCommitReturnsErrorStop() {
 	s.historyQ.On("UpdateIngestVersion", s.ctx, CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.MockQAccountBalances.On("SnapshotAccountBalances", s.ctx, s.checkpointLedger, time.Unix(0, 0).UTC()).
+		Return(nil).
+		Once()
 	s.historyQ.On("Commit").
 		Return(errors.New("my error")).
 		Once()
@@ -388,6 +401,9 @@ func (s *BuildStateTestSuite) TestBuildStateSucceedStop() {
 	s.historyQ.On("UpdateIngestVersion", s.ctx, CurrentVersion).
 		Return(nil).
 		Once()
+	s.historyQ.MockQAccountBalances.On("SnapshotAccountBalances", s.ctx, s.checkpointLedger, time.Unix(0, 0).UTC()).
+		Return(nil).
+		Once()
 	s.historyQ.On("Commit").
 		Return(nil).
 		Once()
@@ -0,0 +1,55 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ingest/processors"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountFilterRules are the rules of the account filter, which matches the
+// transactions in which any of the whitelisted accounts participates.
+type AccountFilterRules struct {
+	Whitelist []string `json:"whitelist"`
+}
+
+type accountFilter struct {
+	whitelist map[string]struct{}
+}
+
+func newAccountFilter(rules json.RawMessage) (*accountFilter, error) {
+	var r AccountFilterRules
+	if err := json.Unmarshal(rules, &r); err != nil {
+		return nil, errors.Wrap(err, "could not decode account filter rules")
+	}
+
+	f := &accountFilter{whitelist: make(map[string]struct{}, len(r.Whitelist))}
+	for _, address := range r.Whitelist {
+		if _, err := xdr.AddressToAccountId(address); err != nil {
+			return nil, errors.Errorf("%s is not a valid account", address)
+		}
+		f.whitelist[address] = struct{}{}
+	}
+	return f, nil
+}
+
+func (f *accountFilter) Name() string {
+	return history.FilterAccountName
+}
+
+func (f *accountFilter) FilterTransaction(ctx context.Context, sequence uint32, tx ingest.LedgerTransaction) (bool, error) {
+	participants, err := processors.ParticipantsForTransaction(sequence, tx)
+	if err != nil {
+		return false, err
+	}
+	for _, participant := range participants {
+		if _, ok := f.whitelist[participant.Address()]; ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,84 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ingest/processors"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// AssetFilterRules are the rules of the asset filter, which matches the
+// transactions whose operations or ledger entry changes involve any of the
+// whitelisted assets. Assets are in the canonical form, `native` or
+// `CODE:ISSUER`.
+type AssetFilterRules struct {
+	Whitelist []string `json:"whitelist"`
+}
+
+type assetFilter struct {
+	whitelist map[string]struct{}
+}
+
+func newAssetFilter(rules json.RawMessage) (*assetFilter, error) {
+	var r AssetFilterRules
+	if err := json.Unmarshal(rules, &r); err != nil {
+		return nil, errors.Wrap(err, "could not decode asset filter rules")
+	}
+
+	f := &assetFilter{whitelist: make(map[string]struct{}, len(r.Whitelist))}
+	for _, s := range r.Whitelist {
+		assets, err := xdr.BuildAssets(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(assets) != 1 {
+			return nil, errors.Errorf("%s is not a valid asset", s)
+		}
+		f.whitelist[assets[0].StringCanonical()] = struct{}{}
+	}
+	return f, nil
+}
+
+func (f *assetFilter) Name() string {
+	return history.FilterAssetName
+}
+
+func (f *assetFilter) FilterTransaction(ctx context.Context, sequence uint32, tx ingest.LedgerTransaction) (bool, error) {
+	for _, op := range tx.Envelope.Operations() {
+		source := tx.Envelope.SourceAccount()
+		if op.SourceAccount != nil {
+			source = *op.SourceAccount
+		}
+		if f.matches(processors.OperationAssets(op, source.ToAccountId())) {
+			return true, nil
+		}
+	}
+
+	// the changes include the assets traded by path payments and offers,
+	// which are not part of the operations
+	changes, err := tx.GetChanges()
+	if err != nil {
+		return false, errors.Wrap(err, "could not get transaction changes")
+	}
+	for _, change := range changes {
+		for _, entry := range []*xdr.LedgerEntry{change.Pre, change.Post} {
+			if entry != nil && f.matches(processors.LedgerEntryAssets(*entry)) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (f *assetFilter) matches(assets []xdr.Asset) bool {
+	for _, asset := range assets {
+		if _, ok := f.whitelist[asset.StringCanonical()]; ok {
+			return true
+		}
+	}
+	return false
+}
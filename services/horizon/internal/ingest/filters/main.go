@@ -0,0 +1,95 @@
+// Package filters contains the ingestion filters, which select the
+// transactions whose history is ingested by Horizon.
+package filters
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+)
+
+// Filter decides if the history of a transaction is ingested.
+type Filter interface {
+	// Name is the name of the filter rules in the database.
+	Name() string
+	// FilterTransaction returns true if the transaction matches the rules of
+	// the filter.
+	FilterTransaction(ctx context.Context, sequence uint32, tx ingest.LedgerTransaction) (bool, error)
+}
+
+// ParseRules parses and validates the rules of the filter with the given
+// name.
+func ParseRules(name string, rules json.RawMessage) (Filter, error) {
+	switch name {
+	case history.FilterAccountName:
+		return newAccountFilter(rules)
+	case history.FilterAssetName:
+		return newAssetFilter(rules)
+	default:
+		return nil, errors.Errorf("unknown filter %s", name)
+	}
+}
+
+// Filters is the set of enabled filters. A transaction is ingested when it
+// matches the rules of any of the enabled filters, every transaction is
+// ingested when no filter is enabled.
+type Filters struct {
+	filters []Filter
+	// Version is the version of the rules of the filters, 0 when no filter
+	// is enabled.
+	Version int64
+}
+
+// Load returns the filters enabled in the database.
+func Load(ctx context.Context, q history.QFilterRules) (*Filters, error) {
+	rules, err := q.GetFilterRules(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load filter rules")
+	}
+
+	f := &Filters{}
+	var version int64
+	for _, rule := range rules {
+		if rule.Version > version {
+			version = rule.Version
+		}
+		if !rule.Enabled {
+			continue
+		}
+		filter, err := ParseRules(rule.Name, rule.Rules)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s filter rules", rule.Name)
+		}
+		f.filters = append(f.filters, filter)
+	}
+	if len(f.filters) > 0 {
+		f.Version = version
+	}
+	return f, nil
+}
+
+// Enabled returns true if any filter is enabled.
+func (f *Filters) Enabled() bool {
+	return len(f.filters) > 0
+}
+
+// FilterTransaction returns true if the history of the transaction must be
+// ingested.
+func (f *Filters) FilterTransaction(ctx context.Context, sequence uint32, tx ingest.LedgerTransaction) (bool, error) {
+	if !f.Enabled() {
+		return true, nil
+	}
+	for _, filter := range f.filters {
+		include, err := filter.FilterTransaction(ctx, sequence, tx)
+		if err != nil {
+			return false, errors.Wrapf(err, "error in %s filter", filter.Name())
+		}
+		if include {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,213 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sourceAddress      = "GAOQJGUAB7NI7K7I62ORBXMN3J4SSWQUQ7FOEPSDJ322W2HMCNWPHXFB"
+	destinationAddress = "GACAR2AEYEKITE2LKI5RMXF5MIVZ6Q7XILROGDT22O7JX4DSWFS7FDDP"
+	issuerAddress      = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	otherAddress       = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+)
+
+func paymentTransaction(destination string, asset xdr.Asset) ingest.LedgerTransaction {
+	return operationTransaction(xdr.OperationBody{
+		Type: xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{
+			Destination: xdr.MustMuxedAddress(destination),
+			Asset:       asset,
+			Amount:      100,
+		},
+	})
+}
+
+func operationTransaction(body xdr.OperationBody) ingest.LedgerTransaction {
+	return ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: xdr.MustMuxedAddress(sourceAddress),
+					Operations:    []xdr.Operation{{Body: body}},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{V: 1, V1: &xdr.TransactionMetaV1{}},
+	}
+}
+
+func mustRules(t *testing.T, rules interface{}) json.RawMessage {
+	raw, err := json.Marshal(rules)
+	require.NoError(t, err)
+	return raw
+}
+
+func TestAccountFilter(t *testing.T) {
+	ctx := context.Background()
+	usd := xdr.MustNewCreditAsset("USD", issuerAddress)
+
+	filter, err := ParseRules(history.FilterAccountName, mustRules(t, AccountFilterRules{
+		Whitelist: []string{destinationAddress},
+	}))
+	require.NoError(t, err)
+
+	include, err := filter.FilterTransaction(ctx, 2, paymentTransaction(destinationAddress, usd))
+	assert.NoError(t, err)
+	assert.True(t, include)
+
+	include, err = filter.FilterTransaction(ctx, 2, paymentTransaction(otherAddress, usd))
+	assert.NoError(t, err)
+	assert.False(t, include)
+
+	_, err = ParseRules(history.FilterAccountName, mustRules(t, AccountFilterRules{
+		Whitelist: []string{"GABC"},
+	}))
+	assert.EqualError(t, err, "GABC is not a valid account")
+}
+
+func TestAssetFilter(t *testing.T) {
+	ctx := context.Background()
+	usd := xdr.MustNewCreditAsset("USD", issuerAddress)
+	eur := xdr.MustNewCreditAsset("EUR", issuerAddress)
+
+	filter, err := ParseRules(history.FilterAssetName, mustRules(t, AssetFilterRules{
+		Whitelist: []string{"USD:" + issuerAddress},
+	}))
+	require.NoError(t, err)
+
+	include, err := filter.FilterTransaction(ctx, 2, paymentTransaction(destinationAddress, usd))
+	assert.NoError(t, err)
+	assert.True(t, include)
+
+	include, err = filter.FilterTransaction(ctx, 2, paymentTransaction(destinationAddress, eur))
+	assert.NoError(t, err)
+	assert.False(t, include)
+
+	include, err = filter.FilterTransaction(ctx, 2, paymentTransaction(destinationAddress, xdr.MustNewNativeAsset()))
+	assert.NoError(t, err)
+	assert.False(t, include)
+
+	_, err = ParseRules(history.FilterAssetName, mustRules(t, AssetFilterRules{
+		Whitelist: []string{"USD"},
+	}))
+	assert.EqualError(t, err, "USD is not a valid asset")
+}
+
+func TestAssetFilterNativeOperations(t *testing.T) {
+	ctx := context.Background()
+	destination := xdr.MustAddress(destinationAddress)
+
+	native, err := ParseRules(history.FilterAssetName, mustRules(t, AssetFilterRules{
+		Whitelist: []string{"native"},
+	}))
+	require.NoError(t, err)
+	usd, err := ParseRules(history.FilterAssetName, mustRules(t, AssetFilterRules{
+		Whitelist: []string{"USD:" + issuerAddress},
+	}))
+	require.NoError(t, err)
+
+	for _, body := range []xdr.OperationBody{
+		{
+			Type: xdr.OperationTypeCreateAccount,
+			CreateAccountOp: &xdr.CreateAccountOp{
+				Destination:     destination,
+				StartingBalance: 100,
+			},
+		},
+		{
+			Type:        xdr.OperationTypeAccountMerge,
+			Destination: xdr.MustMuxedAddressPtr(destinationAddress),
+		},
+		{Type: xdr.OperationTypeInflation},
+	} {
+		include, err := native.FilterTransaction(ctx, 2, operationTransaction(body))
+		assert.NoError(t, err)
+		assert.True(t, include, body.Type.String())
+
+		include, err = usd.FilterTransaction(ctx, 2, operationTransaction(body))
+		assert.NoError(t, err)
+		assert.False(t, include, body.Type.String())
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	usd := xdr.MustNewCreditAsset("USD", issuerAddress)
+	eur := xdr.MustNewCreditAsset("EUR", issuerAddress)
+
+	q := &history.MockQFilterRules{}
+	q.On("GetFilterRules", ctx).Return([]history.FilterRule{}, nil).Once()
+	f, err := Load(ctx, q)
+	require.NoError(t, err)
+	assert.False(t, f.Enabled())
+	assert.Equal(t, int64(0), f.Version)
+	include, err := f.FilterTransaction(ctx, 2, paymentTransaction(otherAddress, eur))
+	assert.NoError(t, err)
+	assert.True(t, include, "all transactions are kept without filters")
+
+	q.On("GetFilterRules", ctx).Return([]history.FilterRule{
+		{
+			Name:    history.FilterAccountName,
+			Enabled: true,
+			Rules:   mustRules(t, AccountFilterRules{Whitelist: []string{destinationAddress}}),
+			Version: 3,
+		},
+		{
+			Name:    history.FilterAssetName,
+			Enabled: true,
+			Rules:   mustRules(t, AssetFilterRules{Whitelist: []string{"USD:" + issuerAddress}}),
+			Version: 5,
+		},
+	}, nil).Once()
+	f, err = Load(ctx, q)
+	require.NoError(t, err)
+	assert.True(t, f.Enabled())
+	assert.Equal(t, int64(5), f.Version)
+
+	// transactions are kept when they match any of the filters
+	for _, tc := range []struct {
+		destination string
+		asset       xdr.Asset
+		include     bool
+	}{
+		{destinationAddress, eur, true},
+		{otherAddress, usd, true},
+		{otherAddress, eur, false},
+	} {
+		include, err := f.FilterTransaction(ctx, 2, paymentTransaction(tc.destination, tc.asset))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.include, include)
+	}
+
+	q.On("GetFilterRules", ctx).Return([]history.FilterRule{
+		{
+			Name:    history.FilterAccountName,
+			Enabled: false,
+			Rules:   mustRules(t, AccountFilterRules{Whitelist: []string{destinationAddress}}),
+			Version: 6,
+		},
+	}, nil).Once()
+	f, err = Load(ctx, q)
+	require.NoError(t, err)
+	assert.False(t, f.Enabled())
+	assert.Equal(t, int64(0), f.Version)
+	q.AssertExpectations(t)
+}
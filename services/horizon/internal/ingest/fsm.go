@@ -342,6 +342,16 @@ func (b buildState) run(s *system) (transition, error) {
 		return nextFailState, errors.Wrap(err, "Error updating ingestion version")
 	}
 
+	// The balance history only records the balances which change, the
+	// balances of the state are the starting point.
+	closedAt := time.Unix(0, 0).UTC()
+	if b.checkpointLedger != 1 {
+		closedAt = time.Unix(int64(ledgerCloseMeta.MustV0().LedgerHeader.Header.ScpValue.CloseTime), 0).UTC()
+	}
+	if err = s.historyQ.SnapshotAccountBalances(s.ctx, b.checkpointLedger, closedAt); err != nil {
+		return nextFailState, errors.Wrap(err, "Error recording account balances")
+	}
+
 	if err = s.completeIngestion(s.ctx, b.checkpointLedger); err != nil {
 		return nextFailState, err
 	}
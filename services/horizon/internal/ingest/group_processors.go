@@ -49,8 +49,18 @@ func (g groupChangeProcessors) Commit(ctx context.Context) error {
 	return nil
 }
 
+// transactionFilter decides if a transaction is processed by the filtered
+// processors of a groupTransactionProcessors.
+type transactionFilter interface {
+	FilterTransaction(ctx context.Context, sequence uint32, tx ingest.LedgerTransaction) (bool, error)
+}
+
 type groupTransactionProcessors struct {
 	processors []horizonTransactionProcessor
+	// filteredProcessors only process the transactions kept by filter.
+	filteredProcessors []horizonTransactionProcessor
+	filter             transactionFilter
+	sequence           uint32
 	processorsRunDurations
 }
 
@@ -61,6 +71,22 @@ func newGroupTransactionProcessors(processors []horizonTransactionProcessor) *gr
 	}
 }
 
+// newFilteredGroupTransactionProcessors returns a group running processors on
+// every transaction of the ledger with the given sequence and
+// filteredProcessors on the transactions kept by filter only.
+func newFilteredGroupTransactionProcessors(
+	processors []horizonTransactionProcessor,
+	filteredProcessors []horizonTransactionProcessor,
+	filter transactionFilter,
+	sequence uint32,
+) *groupTransactionProcessors {
+	g := newGroupTransactionProcessors(processors)
+	g.filteredProcessors = filteredProcessors
+	g.filter = filter
+	g.sequence = sequence
+	return g
+}
+
 func (g groupTransactionProcessors) ProcessTransaction(ctx context.Context, tx ingest.LedgerTransaction) error {
 	for _, p := range g.processors {
 		startTime := time.Now()
@@ -69,16 +95,40 @@ func (g groupTransactionProcessors) ProcessTransaction(ctx context.Context, tx i
 		}
 		g.AddRunDuration(fmt.Sprintf("%T", p), startTime)
 	}
+
+	if len(g.filteredProcessors) == 0 {
+		return nil
+	}
+	if g.filter != nil {
+		startTime := time.Now()
+		include, err := g.filter.FilterTransaction(ctx, g.sequence, tx)
+		if err != nil {
+			return errors.Wrap(err, "error filtering transaction")
+		}
+		g.AddRunDuration(fmt.Sprintf("%T", g.filter), startTime)
+		if !include {
+			return nil
+		}
+	}
+	for _, p := range g.filteredProcessors {
+		startTime := time.Now()
+		if err := p.ProcessTransaction(ctx, tx); err != nil {
+			return errors.Wrapf(err, "error in %T.ProcessTransaction", p)
+		}
+		g.AddRunDuration(fmt.Sprintf("%T", p), startTime)
+	}
 	return nil
 }
 
 func (g groupTransactionProcessors) Commit(ctx context.Context) error {
-	for _, p := range g.processors {
-		startTime := time.Now()
-		if err := p.Commit(ctx); err != nil {
-			return errors.Wrapf(err, "error in %T.Commit", p)
+	for _, group := range [][]horizonTransactionProcessor{g.processors, g.filteredProcessors} {
+		for _, p := range group {
+			startTime := time.Now()
+			if err := p.Commit(ctx); err != nil {
+				return errors.Wrapf(err, "error in %T.Commit", p)
+			}
+			g.AddRunDuration(fmt.Sprintf("%T", p), startTime)
 		}
-		g.AddRunDuration(fmt.Sprintf("%T", p), startTime)
 	}
 	return nil
 }
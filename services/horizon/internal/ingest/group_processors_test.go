@@ -7,6 +7,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
@@ -190,3 +191,46 @@ func (s *GroupTransactionProcessorsTestSuiteLedger) TestCommitSucceeds() {
 	err := s.processors.Commit(s.ctx)
 	s.Assert().NoError(err)
 }
+
+type mockTransactionFilter struct {
+	mock.Mock
+}
+
+func (m *mockTransactionFilter) FilterTransaction(ctx context.Context, sequence uint32, transaction ingest.LedgerTransaction) (bool, error) {
+	args := m.Called(ctx, sequence, transaction)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestFilteredGroupTransactionProcessors(t *testing.T) {
+	ctx := context.Background()
+	processorA := &mockHorizonTransactionProcessor{}
+	processorB := &mockHorizonTransactionProcessor{}
+	filter := &mockTransactionFilter{}
+	defer mock.AssertExpectationsForObjects(t, processorA, processorB, filter)
+
+	group := newFilteredGroupTransactionProcessors(
+		[]horizonTransactionProcessor{processorA},
+		[]horizonTransactionProcessor{processorB},
+		filter,
+		10,
+	)
+
+	kept := ingest.LedgerTransaction{Index: 1}
+	filtered := ingest.LedgerTransaction{Index: 2}
+	filter.On("FilterTransaction", ctx, uint32(10), kept).Return(true, nil).Once()
+	filter.On("FilterTransaction", ctx, uint32(10), filtered).Return(false, nil).Once()
+	processorA.On("ProcessTransaction", ctx, kept).Return(nil).Once()
+	processorA.On("ProcessTransaction", ctx, filtered).Return(nil).Once()
+	processorB.On("ProcessTransaction", ctx, kept).Return(nil).Once()
+	processorA.On("Commit", ctx).Return(nil).Once()
+	processorB.On("Commit", ctx).Return(nil).Once()
+
+	assert.NoError(t, group.ProcessTransaction(ctx, kept))
+	assert.NoError(t, group.ProcessTransaction(ctx, filtered))
+	assert.NoError(t, group.Commit(ctx))
+
+	failing := ingest.LedgerTransaction{Index: 3}
+	filter.On("FilterTransaction", ctx, uint32(10), failing).Return(false, errors.New("transient error")).Once()
+	processorA.On("ProcessTransaction", ctx, failing).Return(nil).Once()
+	assert.EqualError(t, group.ProcessTransaction(ctx, failing), "error filtering transaction: transient error")
+}
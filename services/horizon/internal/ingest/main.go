@@ -52,7 +52,9 @@ const (
 	// - 13: Trigger state rebuild to include more than just authorized assets.
 	// - 14: Trigger state rebuild to include claimable balances in the asset stats processor.
 	// - 15: Fixed bug in asset stat ingestion where clawback is enabled (#3846).
-	CurrentVersion = 15
+	// - 16: Trigger state rebuild to record the balances of the state in the
+	//       account balance history.
+	CurrentVersion = 16
 
 	// MaxDBConnections is the size of the postgres connection pool dedicated to Horizon ingestion:
 	//  * Ledger ingestion,
@@ -80,6 +82,8 @@ type Config struct {
 
 	HistorySession    db.SessionInterface
 	HistoryArchiveURL string
+	// HistoryArchiveCache configures the on-disk cache of the history archive.
+	HistoryArchiveCache historyarchive.CacheOptions
 
 	DisableStateVerification     bool
 	EnableExtendedLogLedgerStats bool
@@ -91,6 +95,10 @@ type Config struct {
 	CheckpointFrequency uint32
 
 	RoundingSlippageFilter int
+
+	// KinesisTreasuryAccounts are the accounts used to classify payments as
+	// coin mints or redemptions.
+	KinesisTreasuryAccounts history.KinesisTreasuryAccounts
 }
 
 const (
@@ -178,6 +186,7 @@ type system struct {
 	runner   ProcessorRunnerInterface
 
 	ledgerBackend  ledgerbackend.LedgerBackend
+	archive        *historyarchive.Archive
 	historyAdapter historyArchiveAdapterInterface
 
 	stellarCoreClient stellarCoreClient
@@ -206,6 +215,7 @@ func NewSystem(config Config) (System, error) {
 			Context:             ctx,
 			NetworkPassphrase:   config.NetworkPassphrase,
 			CheckpointFrequency: config.CheckpointFrequency,
+			Cache:               config.HistoryArchiveCache,
 		},
 	)
 	if err != nil {
@@ -260,6 +270,7 @@ func NewSystem(config Config) (System, error) {
 		config:                      config,
 		ctx:                         ctx,
 		disableStateVerification:    config.DisableStateVerification,
+		archive:                     archive,
 		historyAdapter:              historyAdapter,
 		historyQ:                    historyQ,
 		ledgerBackend:               ledgerBackend,
@@ -445,6 +456,9 @@ func (s *system) RegisterMetrics(registry *prometheus.Registry) {
 	registry.MustRegister(s.metrics.CaptiveCoreSupportedProtocolVersion)
 	registry.MustRegister(s.metrics.LedgerFetchDurationSummary)
 	registry.MustRegister(s.metrics.StateVerifyLedgerEntriesCount)
+	if s.archive != nil {
+		s.archive.RegisterMetrics(registry)
+	}
 }
 
 // Run starts ingestion system. Ingestion system supports distributed ingestion
@@ -230,19 +230,23 @@ type mockDBQ struct {
 	mock.Mock
 
 	history.MockQAccounts
+	history.MockQAccountBalances
 	history.MockQClaimableBalances
 	history.MockQHistoryClaimableBalances
 	history.MockQLiquidityPools
 	history.MockQHistoryLiquidityPools
+	history.MockQKinesisCoinInCirculation
 	history.MockQAssetStats
 	history.MockQData
 	history.MockQEffects
+	history.MockQFilterRules
 	history.MockQLedgers
 	history.MockQOffers
 	history.MockQOperations
 	history.MockQSigners
 	history.MockQTransactions
 	history.MockQTrustLines
+	history.MockQWebhooks
 }
 
 func (m *mockDBQ) Begin() error {
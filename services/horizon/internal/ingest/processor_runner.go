@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/services/horizon/internal/ingest/filters"
 	"github.com/stellar/go/services/horizon/internal/ingest/processors"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
@@ -19,6 +21,12 @@ const (
 	historyArchiveSource = ingestionSource(iota)
 	ledgerSource         = ingestionSource(iota)
 	logFrequency         = 50000
+	// filtersRefreshInterval is how often the rules of the ingestion filters
+	// are reloaded from the database.
+	filtersRefreshInterval = 10 * time.Second
+	// webhooksRefreshInterval is how often the registered webhooks are
+	// reloaded from the database.
+	webhooksRefreshInterval = 5 * time.Second
 )
 
 type horizonChangeProcessor interface {
@@ -86,6 +94,12 @@ type ProcessorRunner struct {
 	historyQ       history.IngestionQ
 	historyAdapter historyArchiveAdapterInterface
 	logMemoryStats bool
+
+	filters         *filters.Filters
+	filtersLoadedAt time.Time
+
+	webhooks         []history.Webhook
+	webhooksLoadedAt time.Time
 }
 
 func (s *ProcessorRunner) SetHistoryAdapter(historyAdapter historyArchiveAdapterInterface) {
@@ -128,23 +142,86 @@ func (s *ProcessorRunner) buildTransactionProcessor(
 	ledgerTransactionStats *processors.StatsLedgerTransactionProcessor,
 	tradeProcessor *processors.TradeProcessor,
 	ledger xdr.LedgerHeaderHistoryEntry,
+	webhooks []history.Webhook,
 ) *groupTransactionProcessors {
 	statsLedgerTransactionProcessor := &statsLedgerTransactionProcessor{
 		StatsLedgerTransactionProcessor: ledgerTransactionStats,
 	}
 	*tradeProcessor = *processors.NewTradeProcessor(s.historyQ, ledger)
 	sequence := uint32(ledger.Header.LedgerSeq)
-	return newGroupTransactionProcessors([]horizonTransactionProcessor{
-		statsLedgerTransactionProcessor,
+	treasuryAccounts := s.config.KinesisTreasuryAccounts
+	treasuryAccounts.PopulateAccounts(s.config.NetworkPassphrase)
+
+	var filter transactionFilter
+	var filterVersion int64
+	if s.filters != nil && s.filters.Enabled() {
+		filter = s.filters
+		filterVersion = s.filters.Version
+	}
+	filteredProcessors := []horizonTransactionProcessor{
 		processors.NewEffectProcessor(s.historyQ, sequence),
-		processors.NewLedgerProcessor(s.historyQ, ledger, CurrentVersion),
 		processors.NewOperationProcessor(s.historyQ, sequence),
 		tradeProcessor,
 		processors.NewParticipantsProcessor(s.historyQ, sequence),
 		processors.NewTransactionProcessor(s.historyQ, sequence),
 		processors.NewClaimableBalancesTransactionProcessor(s.historyQ, sequence),
 		processors.NewLiquidityPoolsTransactionProcessor(s.historyQ, sequence),
-	})
+	}
+
+	// The ledger stats, the coin in circulation, the balance history and the
+	// webhooks cover all the transactions of the ledger, the history is only
+	// ingested for the transactions kept by the ingestion filters.
+	unfilteredProcessors := []horizonTransactionProcessor{
+		statsLedgerTransactionProcessor,
+		processors.NewLedgerProcessor(s.historyQ, ledger, CurrentVersion, filterVersion),
+		processors.NewKinesisCoinInCirculationProcessor(s.historyQ, ledger, treasuryAccounts),
+		processors.NewAccountBalancesProcessor(s.historyQ, ledger),
+	}
+	if len(webhooks) > 0 {
+		unfilteredProcessors = append(
+			unfilteredProcessors,
+			processors.NewWebhooksProcessor(s.historyQ, webhooks, ledger),
+		)
+	}
+	return newFilteredGroupTransactionProcessors(
+		unfilteredProcessors,
+		filteredProcessors,
+		filter,
+		sequence,
+	)
+}
+
+// loadFilters reloads the rules of the ingestion filters when they were
+// loaded more than filtersRefreshInterval ago.
+func (s *ProcessorRunner) loadFilters() error {
+	if s.filters != nil && time.Since(s.filtersLoadedAt) < filtersRefreshInterval {
+		return nil
+	}
+	f, err := filters.Load(s.ctx, s.historyQ)
+	if err != nil {
+		return err
+	}
+	if s.filters != nil && s.filters.Version != f.Version {
+		log.WithField("version", f.Version).Info("Ingestion filter rules updated")
+	}
+	s.filters = f
+	s.filtersLoadedAt = time.Now()
+	return nil
+}
+
+// loadWebhooks reloads the registered webhooks when they were loaded more
+// than webhooksRefreshInterval ago.
+func (s *ProcessorRunner) loadWebhooks() error {
+	if !s.webhooksLoadedAt.IsZero() && time.Since(s.webhooksLoadedAt) < webhooksRefreshInterval {
+		return nil
+	}
+	webhooks, err := s.historyQ.GetWebhooks(s.ctx)
+	if err != nil {
+		return err
+	}
+	s.webhooks = webhooks
+	s.webhooksLoadedAt = time.Now()
+	return nil
 }
 
 // checkIfProtocolVersionSupported checks if this Horizon version supports the
@@ -273,6 +350,18 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger xdr.LedgerClos
 	transactionDurations processorsRunDurations,
 	tradeStats processors.TradeStats,
 	err error,
+) {
+	return s.runTransactionProcessorsOnLedger(ledger, false)
+}
+
+// runTransactionProcessorsOnLedger runs the transaction processors on the
+// ledger. The webhook deliveries are only queued when notify is true, so
+// that reingesting old ledgers does not notify the webhooks again.
+func (s *ProcessorRunner) runTransactionProcessorsOnLedger(ledger xdr.LedgerCloseMeta, notify bool) (
+	transactionStats processors.StatsLedgerTransactionProcessorResults,
+	transactionDurations processorsRunDurations,
+	tradeStats processors.TradeStats,
+	err error,
 ) {
 	var (
 		ledgerTransactionStats processors.StatsLedgerTransactionProcessor
@@ -291,8 +380,22 @@ func (s *ProcessorRunner) RunTransactionProcessorsOnLedger(ledger xdr.LedgerClos
 		return
 	}
 
+	if err = s.loadFilters(); err != nil {
+		err = errors.Wrap(err, "Error loading ingestion filters")
+		return
+	}
+
+	var webhooks []history.Webhook
+	if notify {
+		if err = s.loadWebhooks(); err != nil {
+			err = errors.Wrap(err, "Error loading webhooks")
+			return
+		}
+		webhooks = s.webhooks
+	}
+
 	groupTransactionProcessors := s.buildTransactionProcessor(
-		&ledgerTransactionStats, &tradeProcessor, transactionReader.GetHeader())
+		&ledgerTransactionStats, &tradeProcessor, transactionReader.GetHeader(), webhooks)
 	err = processors.StreamLedgerTransactions(s.ctx, groupTransactionProcessors, transactionReader)
 	if err != nil {
 		err = errors.Wrap(err, "Error streaming changes from ledger")
@@ -332,7 +435,7 @@ func (s *ProcessorRunner) RunAllProcessorsOnLedger(ledger xdr.LedgerCloseMeta) (
 	stats.changeDurations = groupChangeProcessors.processorsRunDurations
 
 	stats.transactionStats, stats.transactionDurations, stats.tradeStats, err =
-		s.RunTransactionProcessorsOnLedger(ledger)
+		s.runTransactionProcessorsOnLedger(ledger, true)
 	if err != nil {
 		return
 	}
@@ -230,16 +230,23 @@ func TestProcessorRunnerBuildTransactionProcessor(t *testing.T) {
 	stats := &processors.StatsLedgerTransactionProcessor{}
 	trades := &processors.TradeProcessor{}
 	ledger := xdr.LedgerHeaderHistoryEntry{}
-	processor := runner.buildTransactionProcessor(stats, trades, ledger)
+	processor := runner.buildTransactionProcessor(stats, trades, ledger, nil)
 	assert.IsType(t, &groupTransactionProcessors{}, processor)
 
 	assert.IsType(t, &statsLedgerTransactionProcessor{}, processor.processors[0])
-	assert.IsType(t, &processors.EffectProcessor{}, processor.processors[1])
-	assert.IsType(t, &processors.LedgersProcessor{}, processor.processors[2])
-	assert.IsType(t, &processors.OperationProcessor{}, processor.processors[3])
-	assert.IsType(t, &processors.TradeProcessor{}, processor.processors[4])
-	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.processors[5])
-	assert.IsType(t, &processors.TransactionProcessor{}, processor.processors[6])
+	assert.IsType(t, &processors.LedgersProcessor{}, processor.processors[1])
+	assert.IsType(t, &processors.KinesisCoinInCirculationProcessor{}, processor.processors[2])
+	assert.IsType(t, &processors.AccountBalancesProcessor{}, processor.processors[3])
+	assert.IsType(t, &processors.EffectProcessor{}, processor.filteredProcessors[0])
+	assert.IsType(t, &processors.OperationProcessor{}, processor.filteredProcessors[1])
+	assert.IsType(t, &processors.TradeProcessor{}, processor.filteredProcessors[2])
+	assert.IsType(t, &processors.ParticipantsProcessor{}, processor.filteredProcessors[3])
+	assert.IsType(t, &processors.TransactionProcessor{}, processor.filteredProcessors[4])
+	assert.Nil(t, processor.filter)
+
+	webhooks := []history.Webhook{{ID: 1, URL: "http://localhost/webhook"}}
+	processor = runner.buildTransactionProcessor(stats, trades, ledger, webhooks)
+	assert.IsType(t, &processors.WebhooksProcessor{}, processor.processors[len(processor.processors)-1])
 }
 
 func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
@@ -281,9 +288,21 @@ func TestProcessorRunnerRunAllProcessorsOnLedger(t *testing.T) {
 	q.MockQTransactions.On("NewTransactionBatchInsertBuilder", maxBatchSize).
 		Return(mockTransactionsBatchInsertBuilder).Twice()
 
-	q.MockQLedgers.On("InsertLedger", ctx, ledger.V0.LedgerHeader, 0, 0, 0, 0, CurrentVersion).
+	q.MockQLedgers.On("InsertLedger", ctx, ledger.V0.LedgerHeader, 0, 0, 0, 0, CurrentVersion, int64(0)).
 		Return(int64(1), nil).Once()
 
+	q.MockQFilterRules.On("GetFilterRules", ctx).
+		Return([]history.FilterRule{}, nil).Once()
+
+	q.MockQWebhooks.On("GetWebhooks", ctx).
+		Return([]history.Webhook{}, nil).Once()
+
+	q.MockQKinesisCoinInCirculation.On("DeleteKinesisCoinInCirculationLedger", ctx, uint32(0)).
+		Return(int64(0), nil).Once()
+
+	q.MockQAccountBalances.On("DeleteAccountBalancesLedger", ctx, uint32(0)).
+		Return(int64(0), nil).Once()
+
 	runner := ProcessorRunner{
 		ctx:      ctx,
 		config:   config,
@@ -0,0 +1,197 @@
+package processors
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+type accountBalanceKey struct {
+	accountID   string
+	assetType   string
+	assetCode   string
+	assetIssuer string
+}
+
+type accountBalanceChange struct {
+	// initial is the balance before the first change, nil when the account
+	// or trust line was created.
+	initial *int64
+	// final is the balance after the last change, nil when the account or
+	// trust line was removed.
+	final *int64
+}
+
+// AccountBalancesProcessor records the native balance of the accounts and the
+// balance of the trust lines which changed in a ledger.
+//
+// The fees of all the transactions of a ledger are charged before the
+// transactions are applied so the fee changes are kept apart from the changes
+// of the transactions and the latter take precedence.
+type AccountBalancesProcessor struct {
+	balancesQ  history.QAccountBalances
+	ledger     xdr.LedgerHeaderHistoryEntry
+	feeChanges map[accountBalanceKey]*accountBalanceChange
+	txChanges  map[accountBalanceKey]*accountBalanceChange
+}
+
+func NewAccountBalancesProcessor(
+	balancesQ history.QAccountBalances,
+	ledger xdr.LedgerHeaderHistoryEntry,
+) *AccountBalancesProcessor {
+	return &AccountBalancesProcessor{
+		balancesQ:  balancesQ,
+		ledger:     ledger,
+		feeChanges: map[accountBalanceKey]*accountBalanceChange{},
+		txChanges:  map[accountBalanceKey]*accountBalanceChange{},
+	}
+}
+
+// ProcessTransaction process the given transaction
+func (p *AccountBalancesProcessor) ProcessTransaction(ctx context.Context, transaction ingest.LedgerTransaction) error {
+	for _, change := range transaction.GetFeeChanges() {
+		if err := p.addChange(p.feeChanges, change); err != nil {
+			return err
+		}
+	}
+
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return errors.Wrap(err, "could not get transaction changes")
+	}
+	for _, change := range changes {
+		if err := p.addChange(p.txChanges, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *AccountBalancesProcessor) addChange(changes map[accountBalanceKey]*accountBalanceChange, change ingest.Change) error {
+	var entry *xdr.LedgerEntry
+	switch {
+	case change.Post != nil:
+		entry = change.Post
+	case change.Pre != nil:
+		entry = change.Pre
+	default:
+		return nil
+	}
+
+	key, ok, err := accountBalanceKeyFromEntry(*entry)
+	if err != nil || !ok {
+		return err
+	}
+
+	existing, seen := changes[key]
+	if !seen {
+		existing = &accountBalanceChange{}
+		if change.Pre != nil {
+			balance := ledgerEntryBalance(*change.Pre)
+			existing.initial = &balance
+		}
+		changes[key] = existing
+	}
+	existing.final = nil
+	if change.Post != nil {
+		balance := ledgerEntryBalance(*change.Post)
+		existing.final = &balance
+	}
+	return nil
+}
+
+func accountBalanceKeyFromEntry(entry xdr.LedgerEntry) (accountBalanceKey, bool, error) {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return accountBalanceKey{
+			accountID: entry.Data.MustAccount().AccountId.Address(),
+			assetType: xdr.AssetTypeToString[xdr.AssetTypeAssetTypeNative],
+		}, true, nil
+	case xdr.LedgerEntryTypeTrustline:
+		trustLine := entry.Data.MustTrustLine()
+		if trustLine.Asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			return accountBalanceKey{}, false, nil
+		}
+		key := accountBalanceKey{accountID: trustLine.AccountId.Address()}
+		err := trustLine.Asset.ToAsset().Extract(&key.assetType, &key.assetCode, &key.assetIssuer)
+		if err != nil {
+			return accountBalanceKey{}, false, errors.Wrap(err, "could not extract trust line asset")
+		}
+		return key, true, nil
+	default:
+		return accountBalanceKey{}, false, nil
+	}
+}
+
+func ledgerEntryBalance(entry xdr.LedgerEntry) int64 {
+	if entry.Data.Type == xdr.LedgerEntryTypeAccount {
+		return int64(entry.Data.MustAccount().Balance)
+	}
+	return int64(entry.Data.MustTrustLine().Balance)
+}
+
+func (p *AccountBalancesProcessor) Commit(ctx context.Context) error {
+	sequence := uint32(p.ledger.Header.LedgerSeq)
+	closedAt := time.Unix(int64(p.ledger.Header.ScpValue.CloseTime), 0).UTC()
+
+	var balances []history.AccountBalance
+	for key, feeChange := range p.feeChanges {
+		if txChange, ok := p.txChanges[key]; ok {
+			txChange.initial = feeChange.initial
+		} else {
+			p.txChanges[key] = feeChange
+		}
+	}
+	for key, change := range p.txChanges {
+		switch {
+		case change.initial == nil && change.final == nil:
+			// created and removed within the ledger
+			continue
+		case change.initial != nil && change.final != nil && *change.initial == *change.final:
+			continue
+		}
+		balance := history.AccountBalance{
+			LedgerSequence: sequence,
+			ClosedAt:       closedAt,
+			AccountID:      key.accountID,
+			AssetType:      key.assetType,
+			AssetCode:      key.assetCode,
+			AssetIssuer:    key.assetIssuer,
+			Removed:        change.final == nil,
+		}
+		if change.final != nil {
+			balance.Balance = *change.final
+		}
+		balances = append(balances, balance)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		a, b := balances[i], balances[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.AssetType != b.AssetType {
+			return a.AssetType < b.AssetType
+		}
+		if a.AssetCode != b.AssetCode {
+			return a.AssetCode < b.AssetCode
+		}
+		return a.AssetIssuer < b.AssetIssuer
+	})
+
+	// Clear the ledger first so that ingesting it again is idempotent.
+	if _, err := p.balancesQ.DeleteAccountBalancesLedger(ctx, sequence); err != nil {
+		return errors.Wrap(err, "Could not clear account balances")
+	}
+	if len(balances) == 0 {
+		return nil
+	}
+	if err := p.balancesQ.InsertAccountBalances(ctx, balances, maxBatchSize); err != nil {
+		return errors.Wrap(err, "Could not insert account balances")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/xdr"
+)
+
+type AccountBalancesProcessorTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	processor *AccountBalancesProcessor
+	mockQ     *history.MockQAccountBalances
+	account   xdr.AccountId
+	issuer    xdr.AccountId
+	usd       xdr.Asset
+}
+
+func TestAccountBalancesProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(AccountBalancesProcessorTestSuite))
+}
+
+func (s *AccountBalancesProcessorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.mockQ = &history.MockQAccountBalances{}
+	s.account = xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	s.issuer = xdr.MustAddress("GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML")
+	s.usd = xdr.MustNewCreditAsset("USD", s.issuer.Address())
+	s.processor = NewAccountBalancesProcessor(s.mockQ, xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq: xdr.Uint32(20),
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+		},
+	})
+}
+
+func (s *AccountBalancesProcessorTestSuite) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+}
+
+func accountBalanceEntry(account xdr.AccountId, balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: account, Balance: balance},
+		},
+	}
+}
+
+func trustLineBalanceEntry(account xdr.AccountId, asset xdr.TrustLineAsset, balance xdr.Int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: account,
+				Asset:     asset,
+				Balance:   balance,
+			},
+		},
+	}
+}
+
+// updated returns the changes of an entry updated from pre to post.
+func updated(pre, post xdr.LedgerEntry) xdr.LedgerEntryChanges {
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+func balancesTransaction(feeChanges, txChanges xdr.LedgerEntryChanges) ingest.LedgerTransaction {
+	return ingest.LedgerTransaction{
+		FeeChanges: feeChanges,
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 2,
+			V2: &xdr.TransactionMetaV2{
+				Operations: []xdr.OperationMeta{{Changes: txChanges}},
+			},
+		},
+	}
+}
+
+func (s *AccountBalancesProcessorTestSuite) expectBalances(balances []history.AccountBalance) {
+	s.mockQ.On("DeleteAccountBalancesLedger", s.ctx, uint32(20)).Return(int64(0), nil).Once()
+	if len(balances) > 0 {
+		s.mockQ.On("InsertAccountBalances", s.ctx, balances, maxBatchSize).Return(nil).Once()
+	}
+}
+
+func (s *AccountBalancesProcessorTestSuite) balance(assetType, code, issuer string, amount int64, removed bool) history.AccountBalance {
+	return history.AccountBalance{
+		LedgerSequence: 20,
+		ClosedAt:       time.Unix(1000, 0).UTC(),
+		AccountID:      s.account.Address(),
+		AssetType:      assetType,
+		AssetCode:      code,
+		AssetIssuer:    issuer,
+		Balance:        amount,
+		Removed:        removed,
+	}
+}
+
+func (s *AccountBalancesProcessorTestSuite) TestLastBalanceOfLedger() {
+	usd := s.usd.ToTrustLineAsset()
+	created := trustLineBalanceEntry(s.account, usd, 0)
+	trustLineCreated := xdr.LedgerEntryChanges{{
+		Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+		Created: &created,
+	}}
+
+	// the fee of the second transaction is charged before the first one is
+	// applied
+	s.Assert().NoError(s.processor.ProcessTransaction(s.ctx, balancesTransaction(
+		updated(accountBalanceEntry(s.account, 1000), accountBalanceEntry(s.account, 990)),
+		append(trustLineCreated, updated(accountBalanceEntry(s.account, 980), accountBalanceEntry(s.account, 500))...),
+	)))
+	s.Assert().NoError(s.processor.ProcessTransaction(s.ctx, balancesTransaction(
+		updated(accountBalanceEntry(s.account, 990), accountBalanceEntry(s.account, 980)),
+		updated(trustLineBalanceEntry(s.account, usd, 0), trustLineBalanceEntry(s.account, usd, 25)),
+	)))
+
+	s.expectBalances([]history.AccountBalance{
+		s.balance("credit_alphanum4", "USD", s.issuer.Address(), 25, false),
+		s.balance("native", "", "", 500, false),
+	})
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
+func (s *AccountBalancesProcessorTestSuite) TestUnchangedAndRemovedBalances() {
+	other := xdr.MustAddress("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+	usd := s.usd.ToTrustLineAsset()
+	removedTrustLine := trustLineBalanceEntry(s.account, usd, 0)
+	poolShare := xdr.TrustLineAsset{Type: xdr.AssetTypeAssetTypePoolShare, LiquidityPoolId: &xdr.PoolId{1}}
+
+	changes := updated(accountBalanceEntry(other, 100), accountBalanceEntry(other, 100))
+	changes = append(changes, updated(
+		trustLineBalanceEntry(s.account, poolShare, 10),
+		trustLineBalanceEntry(s.account, poolShare, 20),
+	)...)
+	changes = append(changes,
+		xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &removedTrustLine},
+		xdr.LedgerEntryChange{
+			Type:    xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+			Removed: &xdr.LedgerKey{Type: xdr.LedgerEntryTypeTrustline},
+		},
+	)
+	s.Assert().NoError(s.processor.ProcessTransaction(s.ctx, balancesTransaction(nil, changes)))
+
+	s.expectBalances([]history.AccountBalance{
+		s.balance("credit_alphanum4", "USD", s.issuer.Address(), 0, true),
+	})
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
+func (s *AccountBalancesProcessorTestSuite) TestNoChanges() {
+	s.expectBalances(nil)
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+	s.mockQ.AssertNotCalled(s.T(), "InsertAccountBalances", mock.Anything, mock.Anything, mock.Anything)
+}
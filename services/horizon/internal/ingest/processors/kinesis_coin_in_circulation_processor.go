@@ -0,0 +1,133 @@
+package processors
+
+import (
+	"context"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// KinesisCoinInCirculationProcessor computes the amount of coins minted and
+// redeemed by the treasury accounts in a ledger.
+//
+// Coins are minted when the emission account pays any account other than the
+// root account and redeemed when a hot wallet pays the emission or the root
+// account. Payments to or from the fee pool are ignored.
+type KinesisCoinInCirculationProcessor struct {
+	coinInCirculationQ history.QKinesisCoinInCirculation
+	ledger             xdr.LedgerHeaderHistoryEntry
+	accounts           history.KinesisTreasuryAccounts
+	mint               int64
+	redemption         int64
+}
+
+func NewKinesisCoinInCirculationProcessor(
+	coinInCirculationQ history.QKinesisCoinInCirculation,
+	ledger xdr.LedgerHeaderHistoryEntry,
+	accounts history.KinesisTreasuryAccounts,
+) *KinesisCoinInCirculationProcessor {
+	return &KinesisCoinInCirculationProcessor{
+		coinInCirculationQ: coinInCirculationQ,
+		ledger:             ledger,
+		accounts:           accounts,
+	}
+}
+
+func (p *KinesisCoinInCirculationProcessor) ProcessTransaction(ctx context.Context, transaction ingest.LedgerTransaction) error {
+	if !transaction.Result.Successful() {
+		return nil
+	}
+	results, ok := transaction.Result.OperationResults()
+	if !ok {
+		return errors.New("could not get operation results")
+	}
+
+	txSource := transaction.Envelope.SourceAccount()
+	for i, op := range transaction.Envelope.Operations() {
+		source := txSource
+		if op.SourceAccount != nil {
+			source = *op.SourceAccount
+		}
+
+		var destination xdr.MuxedAccount
+		var amount xdr.Int64
+		switch op.Body.Type {
+		case xdr.OperationTypeCreateAccount:
+			createAccount := op.Body.MustCreateAccountOp()
+			destination = createAccount.Destination.ToMuxedAccount()
+			amount = createAccount.StartingBalance
+		case xdr.OperationTypePayment:
+			payment := op.Body.MustPaymentOp()
+			if payment.Asset.Type != xdr.AssetTypeAssetTypeNative {
+				continue
+			}
+			destination = payment.Destination
+			amount = payment.Amount
+		case xdr.OperationTypeAccountMerge:
+			if i >= len(results) || results[i].Tr == nil {
+				return errors.Errorf("missing result of operation %d", i)
+			}
+			balance := results[i].MustTr().MustAccountMergeResult().SourceAccountBalance
+			if balance == nil {
+				continue
+			}
+			destination = op.Body.MustDestination()
+			amount = *balance
+		default:
+			continue
+		}
+
+		p.add(source.ToAccountId().Address(), destination.ToAccountId().Address(), int64(amount))
+	}
+
+	return nil
+}
+
+func (p *KinesisCoinInCirculationProcessor) add(source, destination string, amount int64) {
+	if source == p.accounts.FeepoolAccount || destination == p.accounts.FeepoolAccount {
+		return
+	}
+
+	switch {
+	case source == p.accounts.EmissionAccount && destination != p.accounts.RootAccount:
+		p.mint += amount
+	case p.accounts.IsHotWallet(source) &&
+		(destination == p.accounts.EmissionAccount || destination == p.accounts.RootAccount):
+		p.redemption += amount
+	}
+}
+
+func (p *KinesisCoinInCirculationProcessor) Commit(ctx context.Context) error {
+	sequence := uint32(p.ledger.Header.LedgerSeq)
+
+	// Clear the ledger first so that reingesting it is idempotent.
+	deleted, err := p.coinInCirculationQ.DeleteKinesisCoinInCirculationLedger(ctx, sequence)
+	if err != nil {
+		return errors.Wrap(err, "Could not clear coin in circulation ledger")
+	}
+
+	if p.mint == 0 && p.redemption == 0 {
+		if deleted == 0 {
+			// Nothing changed, the running totals are still valid.
+			return nil
+		}
+	} else {
+		err = p.coinInCirculationQ.InsertKinesisCoinInCirculationLedger(ctx, history.KinesisCoinInCirculationLedger{
+			LedgerSequence: sequence,
+			ClosedAt:       time.Unix(int64(p.ledger.Header.ScpValue.CloseTime), 0).UTC(),
+			Mint:           p.mint,
+			Redemption:     p.redemption,
+		})
+		if err != nil {
+			return errors.Wrap(err, "Could not insert coin in circulation ledger")
+		}
+	}
+
+	if err = p.coinInCirculationQ.RebuildKinesisCoinInCirculation(ctx, sequence); err != nil {
+		return errors.Wrap(err, "Could not rebuild coin in circulation")
+	}
+	return nil
+}
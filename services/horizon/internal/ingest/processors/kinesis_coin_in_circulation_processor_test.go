@@ -0,0 +1,243 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/suite"
+)
+
+const coinInCirculationTestPassphrase = "Kinesis UAT"
+
+type KinesisCoinInCirculationProcessorTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	processor *KinesisCoinInCirculationProcessor
+	mockQ     *history.MockQKinesisCoinInCirculation
+	accounts  history.KinesisTreasuryAccounts
+	header    xdr.LedgerHeaderHistoryEntry
+	customer  xdr.AccountId
+}
+
+func TestKinesisCoinInCirculationProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(KinesisCoinInCirculationProcessorTestSuite))
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.mockQ = &history.MockQKinesisCoinInCirculation{}
+	s.accounts = history.KinesisTreasuryAccounts{
+		HotWalletAccounts: []string{
+			keypair.Root(coinInCirculationTestPassphrase + "exchange").Address(),
+			"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+		},
+	}
+	s.accounts.PopulateAccounts(coinInCirculationTestPassphrase)
+	s.customer = xdr.MustAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	s.header = xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq: xdr.Uint32(20),
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+		},
+	}
+	s.processor = NewKinesisCoinInCirculationProcessor(s.mockQ, s.header, s.accounts)
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+}
+
+func paymentOp(source *xdr.AccountId, destination xdr.AccountId, asset xdr.Asset, amount xdr.Int64) xdr.Operation {
+	op := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: destination.ToMuxedAccount(),
+				Asset:       asset,
+				Amount:      amount,
+			},
+		},
+	}
+	if source != nil {
+		muxed := source.ToMuxedAccount()
+		op.SourceAccount = &muxed
+	}
+	return op
+}
+
+func createAccountOp(destination xdr.AccountId, amount xdr.Int64) xdr.Operation {
+	return xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeCreateAccount,
+			CreateAccountOp: &xdr.CreateAccountOp{
+				Destination:     destination,
+				StartingBalance: amount,
+			},
+		},
+	}
+}
+
+func accountMergeOp(destination xdr.AccountId) xdr.Operation {
+	muxed := destination.ToMuxedAccount()
+	return xdr.Operation{
+		Body: xdr.OperationBody{
+			Type:        xdr.OperationTypeAccountMerge,
+			Destination: &muxed,
+		},
+	}
+}
+
+func createCoinInCirculationTransaction(
+	successful bool,
+	source xdr.AccountId,
+	ops []xdr.Operation,
+	results []xdr.OperationResult,
+) ingest.LedgerTransaction {
+	code := xdr.TransactionResultCodeTxSuccess
+	if !successful {
+		code = xdr.TransactionResultCodeTxFailed
+	}
+	if results == nil {
+		results = make([]xdr.OperationResult, len(ops))
+		for i, op := range ops {
+			results[i] = xdr.OperationResult{
+				Code: xdr.OperationResultCodeOpInner,
+				Tr:   &xdr.OperationResultTr{Type: op.Body.Type},
+			}
+		}
+	}
+
+	return ingest.LedgerTransaction{
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    code,
+					Results: &results,
+				},
+			},
+		},
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source.ToMuxedAccount(),
+					Operations:    ops,
+				},
+			},
+		},
+	}
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) process(transactions ...ingest.LedgerTransaction) {
+	for _, transaction := range transactions {
+		s.Assert().NoError(s.processor.ProcessTransaction(s.ctx, transaction))
+	}
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) TestMintAndRedemption() {
+	root := xdr.MustAddress(s.accounts.RootAccount)
+	emission := xdr.MustAddress(s.accounts.EmissionAccount)
+	hotWallet := xdr.MustAddress(s.accounts.HotWalletAccounts[0])
+	otherHotWallet := xdr.MustAddress(s.accounts.HotWalletAccounts[1])
+	feepool := xdr.MustAddress(s.accounts.FeepoolAccount)
+	credit := xdr.MustNewCreditAsset("KAU", s.customer.Address())
+	native := xdr.MustNewNativeAsset()
+
+	mergedBalance := xdr.Int64(700)
+	s.process(
+		// minted: emission to a customer and to the hot wallet
+		createCoinInCirculationTransaction(true, emission, []xdr.Operation{
+			paymentOp(nil, s.customer, native, 100),
+			createAccountOp(hotWallet, 200),
+			// not minted: emission to root, credit assets and the fee pool
+			paymentOp(nil, root, native, 1000),
+			paymentOp(nil, s.customer, credit, 1000),
+			paymentOp(nil, feepool, native, 1000),
+		}, nil),
+		// redeemed: hot wallets to emission and root, using the operation source account
+		createCoinInCirculationTransaction(true, s.customer, []xdr.Operation{
+			paymentOp(&hotWallet, emission, native, 30),
+			paymentOp(&otherHotWallet, root, native, 40),
+			// not redeemed: customer to emission
+			paymentOp(nil, emission, native, 1000),
+		}, nil),
+		// failed transactions are ignored
+		createCoinInCirculationTransaction(false, emission, []xdr.Operation{
+			paymentOp(nil, s.customer, native, 1000),
+		}, nil),
+		// redeemed: hot wallet merged into emission
+		createCoinInCirculationTransaction(true, hotWallet, []xdr.Operation{
+			accountMergeOp(emission),
+		}, []xdr.OperationResult{
+			{
+				Code: xdr.OperationResultCodeOpInner,
+				Tr: &xdr.OperationResultTr{
+					Type: xdr.OperationTypeAccountMerge,
+					AccountMergeResult: &xdr.AccountMergeResult{
+						Code:                 xdr.AccountMergeResultCodeAccountMergeSuccess,
+						SourceAccountBalance: &mergedBalance,
+					},
+				},
+			},
+		}),
+	)
+
+	s.mockQ.On("DeleteKinesisCoinInCirculationLedger", s.ctx, uint32(20)).
+		Return(int64(0), nil).Once()
+	s.mockQ.On("InsertKinesisCoinInCirculationLedger", s.ctx, history.KinesisCoinInCirculationLedger{
+		LedgerSequence: 20,
+		ClosedAt:       time.Unix(1000, 0).UTC(),
+		Mint:           300,
+		Redemption:     770,
+	}).Return(nil).Once()
+	s.mockQ.On("RebuildKinesisCoinInCirculation", s.ctx, uint32(20)).
+		Return(nil).Once()
+
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) TestNoMintOrRedemption() {
+	s.process(createCoinInCirculationTransaction(true, s.customer, []xdr.Operation{
+		paymentOp(nil, xdr.MustAddress(s.accounts.EmissionAccount), xdr.MustNewNativeAsset(), 1000),
+	}, nil))
+
+	s.mockQ.On("DeleteKinesisCoinInCirculationLedger", s.ctx, uint32(20)).
+		Return(int64(0), nil).Once()
+
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) TestReingestRemovesLedger() {
+	s.mockQ.On("DeleteKinesisCoinInCirculationLedger", s.ctx, uint32(20)).
+		Return(int64(1), nil).Once()
+	s.mockQ.On("RebuildKinesisCoinInCirculation", s.ctx, uint32(20)).
+		Return(nil).Once()
+
+	s.Assert().NoError(s.processor.Commit(s.ctx))
+}
+
+func (s *KinesisCoinInCirculationProcessorTestSuite) TestRebuildError() {
+	s.process(createCoinInCirculationTransaction(true, xdr.MustAddress(s.accounts.EmissionAccount), []xdr.Operation{
+		paymentOp(nil, s.customer, xdr.MustNewNativeAsset(), 100),
+	}, nil))
+
+	s.mockQ.On("DeleteKinesisCoinInCirculationLedger", s.ctx, uint32(20)).
+		Return(int64(0), nil).Once()
+	s.mockQ.On("InsertKinesisCoinInCirculationLedger", s.ctx, history.KinesisCoinInCirculationLedger{
+		LedgerSequence: 20,
+		ClosedAt:       time.Unix(1000, 0).UTC(),
+		Mint:           100,
+	}).Return(nil).Once()
+	s.mockQ.On("RebuildKinesisCoinInCirculation", s.ctx, uint32(20)).
+		Return(errors.New("transient error")).Once()
+
+	s.Assert().EqualError(s.processor.Commit(s.ctx), "Could not rebuild coin in circulation: transient error")
+}
@@ -13,6 +13,7 @@ type LedgersProcessor struct {
 	ledgersQ       history.QLedgers
 	ledger         xdr.LedgerHeaderHistoryEntry
 	ingestVersion  int
+	filterVersion  int64
 	successTxCount int
 	failedTxCount  int
 	opCount        int
@@ -23,11 +24,13 @@ func NewLedgerProcessor(
 	ledgerQ history.QLedgers,
 	ledger xdr.LedgerHeaderHistoryEntry,
 	ingestVersion int,
+	filterVersion int64,
 ) *LedgersProcessor {
 	return &LedgersProcessor{
 		ledger:        ledger,
 		ledgersQ:      ledgerQ,
 		ingestVersion: ingestVersion,
+		filterVersion: filterVersion,
 	}
 }
 
@@ -52,6 +55,7 @@ func (p *LedgersProcessor) Commit(ctx context.Context) error {
 		p.opCount,
 		p.txSetOpCount,
 		p.ingestVersion,
+		p.filterVersion,
 	)
 
 	if err != nil {
@@ -23,6 +23,7 @@ type LedgersProcessorTestSuiteLedger struct {
 	failedCount   int
 	opCount       int
 	ingestVersion int
+	filterVersion int64
 	txs           []ingest.LedgerTransaction
 	txSetOpCount  int
 }
@@ -80,6 +81,7 @@ func createTransaction(successful bool, numOps int) ingest.LedgerTransaction {
 func (s *LedgersProcessorTestSuiteLedger) SetupTest() {
 	s.mockQ = &history.MockQLedgers{}
 	s.ingestVersion = 100
+	s.filterVersion = 3
 	s.header = xdr.LedgerHeaderHistoryEntry{
 		Header: xdr.LedgerHeader{
 			LedgerSeq: xdr.Uint32(20),
@@ -89,6 +91,7 @@ func (s *LedgersProcessorTestSuiteLedger) SetupTest() {
 		s.mockQ,
 		s.header,
 		s.ingestVersion,
+		s.filterVersion,
 	)
 
 	s.txs = []ingest.LedgerTransaction{
@@ -118,6 +121,7 @@ func (s *LedgersProcessorTestSuiteLedger) TestInsertLedgerSucceeds() {
 		s.opCount,
 		s.txSetOpCount,
 		s.ingestVersion,
+		s.filterVersion,
 	).Return(int64(1), nil)
 
 	for _, tx := range s.txs {
@@ -140,6 +144,7 @@ func (s *LedgersProcessorTestSuiteLedger) TestInsertLedgerReturnsError() {
 		mock.Anything,
 		mock.Anything,
 		mock.Anything,
+		mock.Anything,
 	).Return(int64(0), errors.New("transient error"))
 
 	err := s.processor.Commit(ctx)
@@ -158,6 +163,7 @@ func (s *LedgersProcessorTestSuiteLedger) TestInsertLedgerNoRowsAffected() {
 		mock.Anything,
 		mock.Anything,
 		mock.Anything,
+		mock.Anything,
 	).Return(int64(0), nil)
 
 	err := s.processor.Commit(ctx)
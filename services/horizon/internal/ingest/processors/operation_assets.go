@@ -0,0 +1,74 @@
+package processors
+
+import (
+	"github.com/stellar/go/xdr"
+)
+
+// OperationAssets returns the assets of an operation, source is the source
+// account of the operation, which issues the asset of allow trust operations.
+// The assets of liquidity pool deposits and withdrawals are not part of the
+// operation, they are found in the changes of the liquidity pool entry.
+// Account creations, merges and inflation transfer the native asset.
+func OperationAssets(op xdr.Operation, source xdr.AccountId) []xdr.Asset {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount, xdr.OperationTypeAccountMerge, xdr.OperationTypeInflation:
+		return []xdr.Asset{xdr.MustNewNativeAsset()}
+	case xdr.OperationTypePayment:
+		return []xdr.Asset{op.Body.MustPaymentOp().Asset}
+	case xdr.OperationTypePathPaymentStrictReceive:
+		body := op.Body.MustPathPaymentStrictReceiveOp()
+		return append([]xdr.Asset{body.SendAsset, body.DestAsset}, body.Path...)
+	case xdr.OperationTypePathPaymentStrictSend:
+		body := op.Body.MustPathPaymentStrictSendOp()
+		return append([]xdr.Asset{body.SendAsset, body.DestAsset}, body.Path...)
+	case xdr.OperationTypeManageSellOffer:
+		body := op.Body.MustManageSellOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeManageBuyOffer:
+		body := op.Body.MustManageBuyOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		body := op.Body.MustCreatePassiveSellOfferOp()
+		return []xdr.Asset{body.Selling, body.Buying}
+	case xdr.OperationTypeChangeTrust:
+		line := op.Body.MustChangeTrustOp().Line
+		if line.Type == xdr.AssetTypeAssetTypePoolShare {
+			params := line.LiquidityPool.MustConstantProduct()
+			return []xdr.Asset{params.AssetA, params.AssetB}
+		}
+		return []xdr.Asset{line.ToAsset()}
+	case xdr.OperationTypeAllowTrust:
+		return []xdr.Asset{op.Body.MustAllowTrustOp().Asset.ToAsset(source)}
+	case xdr.OperationTypeSetTrustLineFlags:
+		return []xdr.Asset{op.Body.MustSetTrustLineFlagsOp().Asset}
+	case xdr.OperationTypeClawback:
+		return []xdr.Asset{op.Body.MustClawbackOp().Asset}
+	case xdr.OperationTypeCreateClaimableBalance:
+		return []xdr.Asset{op.Body.MustCreateClaimableBalanceOp().Asset}
+	default:
+		return nil
+	}
+}
+
+// LedgerEntryAssets returns the assets of a trust line, offer, claimable
+// balance or liquidity pool entry.
+func LedgerEntryAssets(entry xdr.LedgerEntry) []xdr.Asset {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeTrustline:
+		asset := entry.Data.MustTrustLine().Asset
+		if asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			return nil
+		}
+		return []xdr.Asset{asset.ToAsset()}
+	case xdr.LedgerEntryTypeOffer:
+		offer := entry.Data.MustOffer()
+		return []xdr.Asset{offer.Selling, offer.Buying}
+	case xdr.LedgerEntryTypeClaimableBalance:
+		return []xdr.Asset{entry.Data.MustClaimableBalance().Asset}
+	case xdr.LedgerEntryTypeLiquidityPool:
+		params := entry.Data.MustLiquidityPool().Body.MustConstantProduct().Params
+		return []xdr.Asset{params.AssetA, params.AssetB}
+	default:
+		return nil
+	}
+}
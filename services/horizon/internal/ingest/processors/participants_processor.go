@@ -139,7 +139,9 @@ func participantsForMeta(
 	return participants, nil
 }
 
-func participantsForTransaction(
+// ParticipantsForTransaction returns the accounts participating in the
+// transaction, the same accounts the transaction is indexed by in history.
+func ParticipantsForTransaction(
 	sequence uint32,
 	transaction ingest.LedgerTransaction,
 ) ([]xdr.AccountId, error) {
@@ -188,7 +190,7 @@ func (p *ParticipantsProcessor) addTransactionParticipants(
 	transaction ingest.LedgerTransaction,
 ) error {
 	transactionID := toid.New(int32(sequence), int32(transaction.Index), 0).ToInt64()
-	transactionParticipants, err := participantsForTransaction(
+	transactionParticipants, err := ParticipantsForTransaction(
 		sequence,
 		transaction,
 	)
@@ -0,0 +1,283 @@
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/protocols/horizon/effects"
+	"github.com/stellar/go/protocols/horizon/operations"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// Types of the webhook events.
+const (
+	WebhookEventTypeOperation = history.WebhookEventTypeOperation
+	WebhookEventTypeEffect    = history.WebhookEventTypeEffect
+)
+
+// WebhooksProcessor queues a delivery for every operation and effect of the
+// successful transactions of a ledger matching a webhook notified of their
+// event type.
+type WebhooksProcessor struct {
+	webhooksQ  history.QWebhooks
+	webhooks   []history.Webhook
+	ledger     xdr.LedgerHeaderHistoryEntry
+	deliveries []history.WebhookDelivery
+}
+
+func NewWebhooksProcessor(
+	webhooksQ history.QWebhooks,
+	webhooks []history.Webhook,
+	ledger xdr.LedgerHeaderHistoryEntry,
+) *WebhooksProcessor {
+	return &WebhooksProcessor{
+		webhooksQ: webhooksQ,
+		webhooks:  webhooks,
+		ledger:    ledger,
+	}
+}
+
+// ProcessTransaction process the given transaction
+func (p *WebhooksProcessor) ProcessTransaction(ctx context.Context, transaction ingest.LedgerTransaction) error {
+	if len(p.webhooks) == 0 || !transaction.Result.Successful() {
+		return nil
+	}
+
+	sequence := uint32(p.ledger.Header.LedgerSeq)
+	for i, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(i),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: sequence,
+		}
+		if err := p.processOperation(operation); err != nil {
+			return err
+		}
+		if err := p.processEffects(operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *WebhooksProcessor) processOperation(operation transactionOperationWrapper) error {
+	var event *protocol.WebhookEvent
+	for _, webhook := range p.webhooks {
+		if !webhook.HasEventType(WebhookEventTypeOperation) {
+			continue
+		}
+		matches, err := webhookMatches(webhook, operation)
+		if err != nil {
+			return errors.Wrapf(err, "could not match operation %v", operation.ID())
+		}
+		if !matches {
+			continue
+		}
+		if event == nil {
+			if event, err = p.webhookEvent(operation); err != nil {
+				return err
+			}
+		}
+		if err = p.addDelivery(webhook, operation, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *WebhooksProcessor) processEffects(operation transactionOperationWrapper) error {
+	var effectWebhooks []history.Webhook
+	for _, webhook := range p.webhooks {
+		if webhook.HasEventType(WebhookEventTypeEffect) {
+			effectWebhooks = append(effectWebhooks, webhook)
+		}
+	}
+	if len(effectWebhooks) == 0 {
+		return nil
+	}
+
+	operationEffects, err := operation.effects()
+	if err != nil {
+		return errors.Wrapf(err, "could not get effects of operation %v", operation.ID())
+	}
+	var operationEvent *protocol.WebhookEvent
+	for _, effect := range operationEffects {
+		var event *protocol.WebhookEvent
+		for _, webhook := range effectWebhooks {
+			if !webhookMatchesEffect(webhook, operation, effect) {
+				continue
+			}
+			if event == nil {
+				if operationEvent == nil {
+					if operationEvent, err = p.webhookEvent(operation); err != nil {
+						return err
+					}
+				}
+				e := *operationEvent
+				e.Type = WebhookEventTypeEffect
+				e.Effect = webhookEffect(effect)
+				event = &e
+			}
+			if err = p.addDelivery(webhook, operation, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *WebhooksProcessor) addDelivery(
+	webhook history.Webhook,
+	operation transactionOperationWrapper,
+	event *protocol.WebhookEvent,
+) error {
+	event.WebhookID = webhook.ID
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrapf(err, "could not marshal webhook event of operation %v", operation.ID())
+	}
+	p.deliveries = append(p.deliveries, history.WebhookDelivery{
+		WebhookID:      webhook.ID,
+		LedgerSequence: int32(p.ledger.Header.LedgerSeq),
+		OperationID:    operation.ID(),
+		Payload:        payload,
+	})
+	return nil
+}
+
+func webhookEffect(e effect) *protocol.WebhookEffect {
+	return &protocol.WebhookEffect{
+		ID:           fmt.Sprintf("%019d-%010d", e.operationID, e.order),
+		PT:           fmt.Sprintf("%d-%d", e.operationID, e.order),
+		Account:      e.address,
+		AccountMuxed: e.addressMuxed.String,
+		Type:         effects.EffectTypeNames[effects.EffectType(e.effectType)],
+		TypeI:        int32(e.effectType),
+		Details:      e.details,
+	}
+}
+
+func (p *WebhooksProcessor) webhookEvent(operation transactionOperationWrapper) (*protocol.WebhookEvent, error) {
+	details, err := operation.Details()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get details of operation %v", operation.ID())
+	}
+	source := operation.SourceAccount().ToAccountId()
+	id := strconv.FormatInt(operation.ID(), 10)
+	return &protocol.WebhookEvent{
+		Type:            WebhookEventTypeOperation,
+		Ledger:          uint32(p.ledger.Header.LedgerSeq),
+		LedgerClosedAt:  time.Unix(int64(p.ledger.Header.ScpValue.CloseTime), 0).UTC(),
+		TransactionHash: operation.transaction.Result.TransactionHash.HexString(),
+		Operation: protocol.WebhookOperation{
+			ID:            id,
+			PT:            id,
+			SourceAccount: source.Address(),
+			Type:          operations.TypeNames[operation.OperationType()],
+			TypeI:         int32(operation.OperationType()),
+			Details:       details,
+		},
+	}, nil
+}
+
+func webhookMatches(webhook history.Webhook, operation transactionOperationWrapper) (bool, error) {
+	if webhook.OperationType.Valid && webhook.OperationType.Int64 != int64(operation.OperationType()) {
+		return false, nil
+	}
+
+	if webhook.Asset.Valid {
+		matches := false
+		source := operation.SourceAccount().ToAccountId()
+		for _, asset := range OperationAssets(operation.operation, source) {
+			if asset.StringCanonical() == webhook.Asset.String {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+
+	if webhook.Account.Valid {
+		participants, err := operation.Participants()
+		if err != nil {
+			return false, err
+		}
+		for _, participant := range participants {
+			if participant.Address() == webhook.Account.String {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// webhookMatchesEffect returns true when an effect satisfies the filters of a
+// webhook: the account filter matches the account of the effect, the asset
+// filter the assets in the details of the effect, or else the assets of its
+// operation, and the operation type filter its operation.
+func webhookMatchesEffect(webhook history.Webhook, operation transactionOperationWrapper, e effect) bool {
+	if webhook.OperationType.Valid && webhook.OperationType.Int64 != int64(operation.OperationType()) {
+		return false
+	}
+	if webhook.Account.Valid && webhook.Account.String != e.address {
+		return false
+	}
+	if webhook.Asset.Valid {
+		assets := effectAssets(e.details)
+		if len(assets) == 0 {
+			source := operation.SourceAccount().ToAccountId()
+			for _, asset := range OperationAssets(operation.operation, source) {
+				assets = append(assets, asset.StringCanonical())
+			}
+		}
+		for _, asset := range assets {
+			if asset == webhook.Asset.String {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// effectAssets returns the canonical form of the assets in the details of an
+// effect, as added by addAssetDetails.
+func effectAssets(details map[string]interface{}) []string {
+	var assets []string
+	if asset, ok := details["asset"].(string); ok {
+		assets = append(assets, asset)
+	}
+	for _, prefix := range []string{"", "sold_", "bought_"} {
+		assetType, _ := details[prefix+"asset_type"].(string)
+		code, _ := details[prefix+"asset_code"].(string)
+		issuer, _ := details[prefix+"asset_issuer"].(string)
+		switch {
+		case assetType == "native":
+			assets = append(assets, "native")
+		case code != "" && issuer != "":
+			assets = append(assets, code+":"+issuer)
+		}
+	}
+	return assets
+}
+
+func (p *WebhooksProcessor) Commit(ctx context.Context) error {
+	if len(p.deliveries) == 0 {
+		return nil
+	}
+	if err := p.webhooksQ.InsertWebhookDeliveries(ctx, p.deliveries); err != nil {
+		return errors.Wrap(err, "could not queue webhook deliveries")
+	}
+	return nil
+}
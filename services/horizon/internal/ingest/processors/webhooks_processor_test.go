@@ -0,0 +1,215 @@
+//lint:file-ignore U1001 Ignore all unused code, staticcheck doesn't understand testify/suite
+
+package processors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	protocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhooksProcessorTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	mockQ       *history.MockQWebhooks
+	header      xdr.LedgerHeaderHistoryEntry
+	source      string
+	destination string
+	asset       xdr.Asset
+}
+
+func TestWebhooksProcessorTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhooksProcessorTestSuite))
+}
+
+func (s *WebhooksProcessorTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.mockQ = &history.MockQWebhooks{}
+	s.header = xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq: xdr.Uint32(20),
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+		},
+	}
+	s.source = "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	s.destination = "GCQZP3IU7XU6EJ63JZXKCQOYT2RNXN3HB5CNHENNUEUHSMA4VUJJJSEN"
+	s.asset = xdr.MustNewCreditAsset("USD", "GCQZP3IU7XU6EJ63JZXKCQOYT2RNXN3HB5CNHENNUEUHSMA4VUJJJSEN")
+}
+
+func (s *WebhooksProcessorTestSuite) TearDownTest() {
+	s.mockQ.AssertExpectations(s.T())
+}
+
+func (s *WebhooksProcessorTestSuite) transaction(successful bool, asset xdr.Asset) ingest.LedgerTransaction {
+	tx := createTransaction(successful, 2)
+	tx.Index = 1
+	tx.Envelope.V1.Tx.SourceAccount = xdr.MustMuxedAddress(s.source)
+	tx.Envelope.V1.Tx.Operations[1] = xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: xdr.MustMuxedAddress(s.destination),
+				Asset:       asset,
+				Amount:      100,
+			},
+		},
+	}
+	return tx
+}
+
+func (s *WebhooksProcessorTestSuite) process(webhooks []history.Webhook, txs ...ingest.LedgerTransaction) {
+	processor := NewWebhooksProcessor(s.mockQ, webhooks, s.header)
+	for _, tx := range txs {
+		s.Assert().NoError(processor.ProcessTransaction(s.ctx, tx))
+	}
+	s.Assert().NoError(processor.Commit(s.ctx))
+}
+
+func (s *WebhooksProcessorTestSuite) TestNoWebhooks() {
+	s.process(nil, s.transaction(true, s.asset))
+}
+
+func (s *WebhooksProcessorTestSuite) TestFailedTransactionsAreIgnored() {
+	webhooks := []history.Webhook{{ID: 1}}
+	s.process(webhooks, s.transaction(false, s.asset))
+}
+
+func (s *WebhooksProcessorTestSuite) TestMatchingOperations() {
+	webhooks := []history.Webhook{
+		{ID: 1, Account: null.StringFrom(s.destination)},
+		{ID: 2, Asset: null.StringFrom(s.asset.StringCanonical())},
+		{ID: 3, OperationType: null.IntFrom(int64(xdr.OperationTypeBumpSequence))},
+		{
+			ID:            4,
+			Account:       null.StringFrom(s.source),
+			OperationType: null.IntFrom(int64(xdr.OperationTypePayment)),
+		},
+		{ID: 5, Asset: null.StringFrom("native")},
+		{
+			ID:            6,
+			Account:       null.StringFrom(s.destination),
+			OperationType: null.IntFrom(int64(xdr.OperationTypeBumpSequence)),
+		},
+	}
+
+	paymentID := toid.New(20, 1, 2).ToInt64()
+	bumpID := toid.New(20, 1, 1).ToInt64()
+	s.mockQ.On("InsertWebhookDeliveries", s.ctx, mock.AnythingOfType("[]history.WebhookDelivery")).
+		Run(func(args mock.Arguments) {
+			deliveries := args.Get(1).([]history.WebhookDelivery)
+			var ids []int64
+			for _, delivery := range deliveries {
+				ids = append(ids, delivery.WebhookID)
+				s.Assert().Equal(int32(20), delivery.LedgerSequence)
+
+				var event protocol.WebhookEvent
+				s.Assert().NoError(json.Unmarshal(delivery.Payload, &event))
+				s.Assert().Equal(delivery.WebhookID, event.WebhookID)
+				s.Assert().Equal(WebhookEventTypeOperation, event.Type)
+				s.Assert().Equal(uint32(20), event.Ledger)
+				s.Assert().Equal(int64(1000), event.LedgerClosedAt.Unix())
+				s.Assert().Equal(s.source, event.Operation.SourceAccount)
+
+				if delivery.WebhookID == 3 {
+					s.Assert().Equal(bumpID, delivery.OperationID)
+					s.Assert().Equal("bump_sequence", event.Operation.Type)
+				} else {
+					s.Assert().Equal(paymentID, delivery.OperationID)
+					s.Assert().Equal("payment", event.Operation.Type)
+					s.Assert().Equal(s.destination, event.Operation.Details["to"])
+				}
+			}
+			s.Assert().Equal([]int64{3, 1, 2, 4}, ids)
+		}).
+		Return(nil).Once()
+
+	s.process(webhooks, s.transaction(true, s.asset))
+}
+
+func (s *WebhooksProcessorTestSuite) TestInsertError() {
+	webhooks := []history.Webhook{{ID: 1}}
+	s.mockQ.On("InsertWebhookDeliveries", s.ctx, mock.AnythingOfType("[]history.WebhookDelivery")).
+		Return(errors.New("transient error")).Once()
+
+	processor := NewWebhooksProcessor(s.mockQ, webhooks, s.header)
+	s.Assert().NoError(processor.ProcessTransaction(s.ctx, s.transaction(true, s.asset)))
+	err := processor.Commit(s.ctx)
+	s.Assert().EqualError(err, "could not queue webhook deliveries: transient error")
+}
+
+func (s *WebhooksProcessorTestSuite) TestMatchingEffects() {
+	webhooks := []history.Webhook{
+		{
+			ID:         1,
+			Account:    null.StringFrom(s.destination),
+			EventTypes: []string{WebhookEventTypeEffect},
+		},
+		{
+			ID:         2,
+			Asset:      null.StringFrom(s.asset.StringCanonical()),
+			EventTypes: []string{WebhookEventTypeEffect},
+		},
+		{
+			ID:         3,
+			Account:    null.StringFrom(s.source),
+			EventTypes: []string{WebhookEventTypeOperation, WebhookEventTypeEffect},
+		},
+		{
+			ID:         4,
+			Asset:      null.StringFrom("native"),
+			EventTypes: []string{WebhookEventTypeEffect},
+		},
+	}
+
+	paymentID := toid.New(20, 1, 2).ToInt64()
+	s.mockQ.On("InsertWebhookDeliveries", s.ctx, mock.AnythingOfType("[]history.WebhookDelivery")).
+		Run(func(args mock.Arguments) {
+			deliveries := args.Get(1).([]history.WebhookDelivery)
+			var received []string
+			for _, delivery := range deliveries {
+				var event protocol.WebhookEvent
+				s.Assert().NoError(json.Unmarshal(delivery.Payload, &event))
+				s.Assert().Equal(delivery.WebhookID, event.WebhookID)
+				if event.Type == WebhookEventTypeOperation {
+					s.Assert().Nil(event.Effect)
+					received = append(received, fmt.Sprintf("%d:%s", delivery.WebhookID, event.Operation.Type))
+					continue
+				}
+
+				s.Assert().Equal(WebhookEventTypeEffect, event.Type)
+				s.Assert().Equal(paymentID, delivery.OperationID)
+				s.Assert().Equal("payment", event.Operation.Type)
+				s.Require().NotNil(event.Effect)
+				order := 1
+				if event.Effect.Type == "account_debited" {
+					order = 2
+				}
+				s.Assert().Equal(fmt.Sprintf("%d-%d", paymentID, order), event.Effect.PT)
+				s.Assert().Equal(fmt.Sprintf("%019d-%010d", paymentID, order), event.Effect.ID)
+				s.Assert().Equal("0.0000100", event.Effect.Details["amount"])
+				received = append(received, fmt.Sprintf("%d:%s:%s", delivery.WebhookID, event.Effect.Type, event.Effect.Account))
+			}
+			s.Assert().Equal([]string{
+				"3:bump_sequence",
+				"3:payment",
+				"1:account_credited:" + s.destination,
+				"2:account_credited:" + s.destination,
+				"2:account_debited:" + s.source,
+				"3:account_debited:" + s.source,
+			}, received)
+		}).
+		Return(nil).Once()
+
+	s.process(webhooks, s.transaction(true, s.asset))
+}
@@ -5,15 +5,18 @@ import (
 	"github.com/stellar/go/services/horizon/internal/paths"
 	"net/http"
 	"runtime"
+	"time"
 
 	"github.com/getsentry/raven-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stellar/go/exp/orderbook"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/ingest"
+	"github.com/stellar/go/services/horizon/internal/ratelimit"
 	"github.com/stellar/go/services/horizon/internal/simplepath"
 	"github.com/stellar/go/services/horizon/internal/txsub"
 	"github.com/stellar/go/services/horizon/internal/txsub/sequence"
+	"github.com/stellar/go/services/horizon/internal/webhooks"
 	"github.com/stellar/go/support/db"
 	"github.com/stellar/go/support/log"
 )
@@ -91,6 +94,7 @@ func initIngester(app *App) {
 		// Use the first archive for now. We don't have a mechanism to
 		// use multiple archives at the same time currently.
 		HistoryArchiveURL:            app.config.HistoryArchiveURLs[0],
+		HistoryArchiveCache:          app.config.HistoryArchiveCache,
 		CheckpointFrequency:          app.config.CheckpointFrequency,
 		StellarCoreURL:               app.config.StellarCoreURL,
 		StellarCoreCursor:            app.config.CursorName,
@@ -103,6 +107,7 @@ func initIngester(app *App) {
 		DisableStateVerification:     app.config.IngestDisableStateVerification,
 		EnableExtendedLogLedgerStats: app.config.IngestEnableExtendedLogLedgerStats,
 		RoundingSlippageFilter:       app.config.RoundingSlippageFilter,
+		KinesisTreasuryAccounts:      app.config.KinesisTreasuryAccounts,
 	})
 
 	if err != nil {
@@ -110,6 +115,13 @@ func initIngester(app *App) {
 	}
 }
 
+func initWebhooks(app *App) {
+	app.webhooks = webhooks.New(app.HorizonSession(), webhooks.Options{
+		MaxAttempts: int(app.config.WebhookMaxAttempts),
+		Timeout:     app.config.WebhookTimeout,
+	})
+}
+
 func initPathFinder(app *App) {
 	orderBookGraph := orderbook.NewOrderBookGraph()
 	app.orderBookStream = ingest.NewOrderBookStream(
@@ -224,4 +236,68 @@ func initSubmissionSystem(app *App) {
 			return &history.Q{SessionInterface: app.HorizonSession()}
 		},
 	}
+	if app.config.TxSubPersistentQueue {
+		app.submitter.Store = &history.Q{SessionInterface: app.HorizonSession()}
+		app.submitter.StorePendingExpiry = app.config.TxSubPendingExpiry
+		app.submitter.StoreRetention = app.config.TxSubRetention
+	}
+}
+
+const (
+	// rateLimitMemoryStoreSize is the number of clients whose rate limit
+	// state is kept when it is stored in memory.
+	rateLimitMemoryStoreSize = 50000
+	// rateLimitAccountsReloadInterval is how often the accounts which can
+	// authenticate with API keys are reloaded.
+	rateLimitAccountsReloadInterval = 10 * time.Second
+)
+
+func initRateLimiter(app *App) error {
+	config := app.config
+
+	var sources ratelimit.AccountSources
+	if config.RateLimitAccountsPath != "" {
+		sources = append(sources, ratelimit.FileAccountSource{Path: config.RateLimitAccountsPath})
+	}
+	if config.RateLimitAccountsFromDB {
+		sources = append(sources, ratelimit.DBAccountSource{Q: &history.Q{app.HorizonSession()}})
+	}
+	if config.RateQuota == nil && config.StreamRateQuota == nil &&
+		config.PathFindingRateQuota == nil && len(sources) == 0 {
+		return nil
+	}
+
+	var store ratelimit.Store
+	if config.RateLimitRedisURL != "" {
+		redisStore, err := ratelimit.NewRedisStore(config.RateLimitRedisURL, ratelimit.DefaultRedisKeyPrefix)
+		if err != nil {
+			return err
+		}
+		store = redisStore
+	} else {
+		memoryStore, err := ratelimit.NewMemoryStore(rateLimitMemoryStoreSize)
+		if err != nil {
+			return err
+		}
+		store = memoryStore
+	}
+
+	limiter, err := ratelimit.NewLimiter(store, ratelimit.Quotas{
+		Requests:    config.RateQuota,
+		Streams:     config.StreamRateQuota,
+		PathFinding: config.PathFindingRateQuota,
+	})
+	if err != nil {
+		return err
+	}
+	if len(sources) > 0 {
+		// load the accounts before serving requests so that their API keys
+		// are accepted from the start
+		if err := limiter.Reload(app.ctx, sources); err != nil {
+			return err
+		}
+		app.rateLimitSource = sources
+	}
+	app.rateLimiter = limiter
+	return nil
 }
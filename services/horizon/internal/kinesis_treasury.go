@@ -0,0 +1,90 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/strkey"
+	support "github.com/stellar/go/support/config"
+)
+
+// kinesisTreasuryFile is the format of the file set with
+// --kinesis-treasury-config-path, e.g.:
+//
+//	root = "GA..."
+//	emission = "GB..."
+//	hot_wallets = ["GC...", "GD..."]
+//	feepool = "GE..."
+type kinesisTreasuryFile struct {
+	Root       string   `toml:"root" valid:"optional,stellar_accountid"`
+	Emission   string   `toml:"emission" valid:"optional,stellar_accountid"`
+	HotWallets []string `toml:"hot_wallets" valid:"optional"`
+	Feepool    string   `toml:"feepool" valid:"optional,stellar_accountid"`
+}
+
+// loadKinesisTreasuryAccounts populates config.KinesisTreasuryAccounts. Accounts
+// set with flags take precedence over the ones listed in
+// config.KinesisTreasuryConfigPath, the remaining ones are derived from the
+// network passphrase.
+func loadKinesisTreasuryAccounts(config *Config) error {
+	accounts := &config.KinesisTreasuryAccounts
+
+	if config.KinesisTreasuryConfigPath != "" {
+		var file kinesisTreasuryFile
+		if err := support.Read(config.KinesisTreasuryConfigPath, &file); err != nil {
+			return fmt.Errorf("Invalid kinesis treasury config file %s: %v", config.KinesisTreasuryConfigPath, err)
+		}
+		if accounts.RootAccount == "" {
+			accounts.RootAccount = file.Root
+		}
+		if accounts.EmissionAccount == "" {
+			accounts.EmissionAccount = file.Emission
+		}
+		if len(accounts.HotWalletAccounts) == 0 {
+			accounts.HotWalletAccounts = file.HotWallets
+		}
+		if accounts.FeepoolAccount == "" {
+			accounts.FeepoolAccount = file.Feepool
+		}
+	}
+
+	accounts.PopulateAccounts(config.NetworkPassphrase)
+
+	if err := validateKinesisAccount("root", accounts.RootAccount); err != nil {
+		return err
+	}
+	if err := validateKinesisAccount("emission", accounts.EmissionAccount); err != nil {
+		return err
+	}
+	for _, account := range accounts.HotWalletAccounts {
+		if err := validateKinesisAccount("hot wallet", account); err != nil {
+			return err
+		}
+	}
+	return validateKinesisAccount("feepool", accounts.FeepoolAccount)
+}
+
+func validateKinesisAccount(role, account string) error {
+	if !strkey.IsValidEd25519PublicKey(account) {
+		return fmt.Errorf("Invalid config: kinesis %s account %q is not a valid account ID", role, account)
+	}
+	return nil
+}
+
+// CheckKinesisTreasuryAccounts returns an error if the coin in circulation
+// stored in the Horizon DB was computed with other treasury accounts than
+// accounts, whose reports would not match the stored data. The accounts are
+// recorded the first time if record is true, i.e. by the ingesting instances.
+func CheckKinesisTreasuryAccounts(ctx context.Context, q *history.Q, accounts history.KinesisTreasuryAccounts, record bool) error {
+	err := q.CheckKinesisTreasuryAccounts(ctx, accounts, record)
+	if err == history.ErrKinesisTreasuryAccountsChanged {
+		stored, _, _ := q.GetKinesisTreasuryAccounts(ctx)
+		return fmt.Errorf(
+			"the coin in circulation was computed with other kinesis treasury accounts (%+v) than the configured ones (%+v), "+
+				"restore their configuration or run `horizon db rebackfill-coin-in-circulation` to recompute it",
+			stored, accounts,
+		)
+	}
+	return err
+}
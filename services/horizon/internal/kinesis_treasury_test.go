@@ -0,0 +1,74 @@
+package horizon
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kinesisTestPassphrase = "Kinesis UAT"
+
+func TestLoadKinesisTreasuryAccountsDefaults(t *testing.T) {
+	config := Config{NetworkPassphrase: kinesisTestPassphrase}
+	require.NoError(t, loadKinesisTreasuryAccounts(&config))
+
+	assert.Equal(t, history.KinesisTreasuryAccounts{
+		RootAccount:       keypair.Root(kinesisTestPassphrase).Address(),
+		EmissionAccount:   keypair.Root(kinesisTestPassphrase + "emission").Address(),
+		HotWalletAccounts: []string{keypair.Root(kinesisTestPassphrase + "exchange").Address()},
+		FeepoolAccount:    keypair.Root(kinesisTestPassphrase + "feepool").Address(),
+	}, config.KinesisTreasuryAccounts)
+}
+
+func TestLoadKinesisTreasuryAccountsFromFile(t *testing.T) {
+	root := keypair.MustRandom().Address()
+	hotWallet1 := keypair.MustRandom().Address()
+	hotWallet2 := keypair.MustRandom().Address()
+	flagEmission := keypair.MustRandom().Address()
+
+	path := filepath.Join(t.TempDir(), "treasury.toml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+root = "`+root+`"
+emission = "`+keypair.MustRandom().Address()+`"
+hot_wallets = ["`+hotWallet1+`", "`+hotWallet2+`"]
+`), 0644))
+
+	config := Config{
+		NetworkPassphrase:         kinesisTestPassphrase,
+		KinesisTreasuryConfigPath: path,
+		// flags take precedence over the file
+		KinesisTreasuryAccounts: history.KinesisTreasuryAccounts{EmissionAccount: flagEmission},
+	}
+	require.NoError(t, loadKinesisTreasuryAccounts(&config))
+
+	assert.Equal(t, history.KinesisTreasuryAccounts{
+		RootAccount:       root,
+		EmissionAccount:   flagEmission,
+		HotWalletAccounts: []string{hotWallet1, hotWallet2},
+		FeepoolAccount:    keypair.Root(kinesisTestPassphrase + "feepool").Address(),
+	}, config.KinesisTreasuryAccounts)
+}
+
+func TestLoadKinesisTreasuryAccountsInvalid(t *testing.T) {
+	config := Config{
+		NetworkPassphrase: kinesisTestPassphrase,
+		KinesisTreasuryAccounts: history.KinesisTreasuryAccounts{
+			HotWalletAccounts: []string{"GINVALID"},
+		},
+	}
+	assert.EqualError(
+		t,
+		loadKinesisTreasuryAccounts(&config),
+		`Invalid config: kinesis hot wallet account "GINVALID" is not a valid account ID`,
+	)
+
+	path := filepath.Join(t.TempDir(), "treasury.toml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`unknown = "value"`), 0644))
+	config = Config{NetworkPassphrase: kinesisTestPassphrase, KinesisTreasuryConfigPath: path}
+	assert.Error(t, loadKinesisTreasuryAccounts(&config))
+}
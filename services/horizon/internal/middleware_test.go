@@ -289,7 +289,7 @@ func TestStateMiddleware(t *testing.T) {
 					LedgerSeq:          testCase.latestHistoryLedger,
 					PreviousLedgerHash: xdr.Hash{byte(i)},
 				},
-			}, 0, 0, 0, 0, 0)
+			}, 0, 0, 0, 0, 0, 0)
 			tt.Assert.NoError(err)
 			tt.Assert.NoError(q.UpdateLastLedgerIngest(context.Background(), testCase.lastIngestedLedger))
 			tt.Assert.NoError(q.UpdateIngestVersion(context.Background(), testCase.ingestionVersion))
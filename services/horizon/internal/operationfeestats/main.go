@@ -43,9 +43,27 @@ type State struct {
 	MaxFeeP95  int64
 	MaxFeeP99  int64
 
-	LastBaseFee         int64
-	LastLedger          uint32
-	LedgerCapacityUsage string
+	// FeeChargedPercentage is the fee charged in basis points of the
+	// native amount transferred by a transaction
+	FeeChargedPercentageMax  int64
+	FeeChargedPercentageMin  int64
+	FeeChargedPercentageMode int64
+	FeeChargedPercentageP10  int64
+	FeeChargedPercentageP20  int64
+	FeeChargedPercentageP30  int64
+	FeeChargedPercentageP40  int64
+	FeeChargedPercentageP50  int64
+	FeeChargedPercentageP60  int64
+	FeeChargedPercentageP70  int64
+	FeeChargedPercentageP80  int64
+	FeeChargedPercentageP90  int64
+	FeeChargedPercentageP95  int64
+	FeeChargedPercentageP99  int64
+
+	LastBaseFee           int64
+	LastBasePercentageFee int64
+	LastLedger            uint32
+	LedgerCapacityUsage   string
 }
 
 // CurrentState returns the cached snapshot of operation fee state and a boolean indicating
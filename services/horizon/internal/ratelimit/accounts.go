@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/stellar/throttled"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	support "github.com/stellar/go/support/config"
+	"github.com/stellar/go/support/errors"
+)
+
+// DefaultMaxBurst is the number of requests a client can make at once when no
+// burst is configured.
+const DefaultMaxBurst = 100
+
+// PerHour returns a quota of n requests per hour allowing bursts of maxBurst
+// requests, or nil if n is 0.
+func PerHour(n, maxBurst int) *throttled.RateQuota {
+	if n == 0 {
+		return nil
+	}
+	return &throttled.RateQuota{
+		MaxRate:  throttled.PerHour(n),
+		MaxBurst: maxBurst,
+	}
+}
+
+// accountQuotas returns the quotas of an account configured with limits per
+// hour, a stream or path finding limit of 0 counts these requests against the
+// requests limit.
+func accountQuotas(perHour, streamPerHour, pathFindingPerHour, maxBurst int) Quotas {
+	return Quotas{
+		Requests:    PerHour(perHour, maxBurst),
+		Streams:     PerHour(streamPerHour, maxBurst),
+		PathFinding: PerHour(pathFindingPerHour, maxBurst),
+	}
+}
+
+// accountsFile is the format of the file loaded by FileAccountSource, e.g.:
+//
+//	[[accounts]]
+//	name = "partner"
+//	per_hour_rate_limit = 100000
+//	per_hour_stream_rate_limit = 20000
+//	per_hour_path_finding_rate_limit = 5000
+//	max_burst = 500
+//	api_keys = ["..."]
+//	api_key_hashes = ["<hex encoded SHA-256 hash of a key>"]
+type accountsFile struct {
+	Accounts []accountsFileEntry `toml:"accounts" valid:"optional"`
+}
+
+type accountsFileEntry struct {
+	Name                        string   `toml:"name" valid:"required"`
+	PerHourRateLimit            int      `toml:"per_hour_rate_limit" valid:"optional"`
+	PerHourStreamRateLimit      int      `toml:"per_hour_stream_rate_limit" valid:"optional"`
+	PerHourPathFindingRateLimit int      `toml:"per_hour_path_finding_rate_limit" valid:"optional"`
+	MaxBurst                    *int     `toml:"max_burst" valid:"optional"`
+	APIKeys                     []string `toml:"api_keys" valid:"optional"`
+	APIKeyHashes                []string `toml:"api_key_hashes" valid:"optional"`
+}
+
+// FileAccountSource loads the accounts from a TOML file.
+type FileAccountSource struct {
+	Path string
+}
+
+// Accounts implements AccountSource.
+func (s FileAccountSource) Accounts(ctx context.Context) ([]Account, error) {
+	var file accountsFile
+	if err := support.Read(s.Path, &file); err != nil {
+		return nil, errors.Wrapf(err, "invalid rate limit accounts file %s", s.Path)
+	}
+
+	accounts := make([]Account, 0, len(file.Accounts))
+	for _, entry := range file.Accounts {
+		maxBurst := DefaultMaxBurst
+		if entry.MaxBurst != nil {
+			maxBurst = *entry.MaxBurst
+		}
+		account := Account{
+			Name: entry.Name,
+			Quotas: accountQuotas(
+				entry.PerHourRateLimit,
+				entry.PerHourStreamRateLimit,
+				entry.PerHourPathFindingRateLimit,
+				maxBurst,
+			),
+			KeyHashes: entry.APIKeyHashes,
+		}
+		for _, key := range entry.APIKeys {
+			account.KeyHashes = append(account.KeyHashes, HashAPIKey(key))
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// DBAccountSource loads the accounts from the rate_limit_accounts and
+// rate_limit_api_keys tables of the Horizon DB.
+type DBAccountSource struct {
+	Q history.QRateLimitAccounts
+}
+
+// Accounts implements AccountSource.
+func (s DBAccountSource) Accounts(ctx context.Context) ([]Account, error) {
+	rows, err := s.Q.GetRateLimitAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]Account, 0, len(rows))
+	for _, row := range rows {
+		accounts = append(accounts, Account{
+			Name: row.Name,
+			Quotas: accountQuotas(
+				int(row.PerHourRateLimit),
+				int(row.PerHourStreamRateLimit),
+				int(row.PerHourPathFindingRateLimit),
+				int(row.MaxBurst),
+			),
+			KeyHashes: row.KeyHashes,
+		})
+	}
+	return accounts, nil
+}
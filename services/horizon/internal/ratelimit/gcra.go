@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/stellar/throttled"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// maxCASAttempts is the number of times the state of a key is read and
+// updated before giving up when it is concurrently updated by other requests.
+const maxCASAttempts = 10
+
+// GCRARateLimiter is a throttled.RateLimiter implementing the generic cell
+// rate algorithm like throttled.GCRARateLimiter, with its state kept in a
+// Store.
+type GCRARateLimiter struct {
+	store Store
+
+	limit int
+	// delayVariationTolerance is the time a key can be ahead of the nominal
+	// schedule of its requests, the size of the bucket.
+	delayVariationTolerance time.Duration
+	// emissionInterval is the time between two requests in the nominal
+	// schedule.
+	emissionInterval time.Duration
+}
+
+// NewGCRARateLimiter returns a GCRARateLimiter enforcing quota, see
+// throttled.NewGCRARateLimiter.
+func NewGCRARateLimiter(store Store, quota throttled.RateQuota) (*GCRARateLimiter, error) {
+	emissionInterval, limit, err := quotaParameters(quota)
+	if err != nil {
+		return nil, err
+	}
+	return &GCRARateLimiter{
+		store:                   store,
+		limit:                   limit,
+		delayVariationTolerance: emissionInterval * time.Duration(limit),
+		emissionInterval:        emissionInterval,
+	}, nil
+}
+
+// quotaParameters returns the emission interval and the limit of a quota. The
+// fields of throttled.Rate are not exported so they are read from the result
+// of the first request to a throttled.GCRARateLimiter, which also validates
+// the quota.
+func quotaParameters(quota throttled.RateQuota) (time.Duration, int, error) {
+	limiter, err := throttled.NewGCRARateLimiter(1, quota)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid rate quota")
+	}
+	now := time.Now()
+	limiter.Clock = throttled.ClockFunc(func() time.Time { return now })
+	_, result, err := limiter.RateLimit("", 1)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid rate quota")
+	}
+	return result.ResetAfter, result.Limit, nil
+}
+
+// RateLimit implements throttled.RateLimiter.
+func (g *GCRARateLimiter) RateLimit(key string, quantity int) (bool, throttled.RateLimitResult, error) {
+	var tat, newTat, now time.Time
+	var ttl time.Duration
+	result := throttled.RateLimitResult{Limit: g.limit, RetryAfter: -1}
+	limited, updated := false, false
+
+	for i := 0; i < maxCASAttempts && !updated; i++ {
+		// tat is the theoretical arrival time of the next request if the
+		// requests were equally spaced at exactly the rate limit.
+		var tatVal int64
+		var err error
+		tatVal, now, err = g.store.GetWithTime(key)
+		if err != nil {
+			return false, result, err
+		}
+		if tatVal == -1 {
+			tat = now
+		} else {
+			tat = time.Unix(0, tatVal)
+		}
+
+		increment := time.Duration(quantity) * g.emissionInterval
+		if now.After(tat) {
+			newTat = now.Add(increment)
+		} else {
+			newTat = tat.Add(increment)
+		}
+
+		// Block the request if the next permitted time is in the future
+		allowAt := newTat.Add(-g.delayVariationTolerance)
+		if diff := now.Sub(allowAt); diff < 0 {
+			if increment <= g.delayVariationTolerance {
+				result.RetryAfter = -diff
+			}
+			ttl = tat.Sub(now)
+			limited = true
+			break
+		}
+
+		ttl = newTat.Sub(now)
+		if quantity == 0 {
+			break
+		}
+		if tatVal == -1 {
+			updated, err = g.store.SetIfNotExistsWithTTL(key, newTat.UnixNano(), ttl)
+		} else {
+			updated, err = g.store.CompareAndSwapWithTTL(key, tatVal, newTat.UnixNano(), ttl)
+		}
+		if err != nil {
+			return false, result, err
+		}
+	}
+
+	if !limited && !updated && quantity != 0 {
+		return false, result, errors.Errorf(
+			"could not update the rate limit state of key %s after %d attempts",
+			key, maxCASAttempts,
+		)
+	}
+
+	next := g.delayVariationTolerance - ttl
+	if next > -g.emissionInterval {
+		result.Remaining = int(next / g.emissionInterval)
+	}
+	result.ResetAfter = ttl
+
+	return limited, result, nil
+}
@@ -0,0 +1,252 @@
+// Package ratelimit limits the rate of the requests made to Horizon. Anonymous
+// requests are limited by IP address, requests authenticated with an API key
+// are limited by the account the key belongs to, each account having its own
+// quotas. Separate quotas can be set for streaming and path finding requests.
+// The state of the limits is kept in a Store, which can be shared by all the
+// Horizon instances of a deployment.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/stellar/throttled"
+
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+// ErrInvalidAPIKey is returned by Limiter.RateLimit when the API key of a
+// request does not belong to any account.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// Category is the kind of a rate limited request.
+type Category int
+
+const (
+	// Requests are the requests which are neither streams nor path finding
+	// requests.
+	Requests Category = iota
+	// Streams are the updates sent to streaming (SSE) requests, every update
+	// queries the DB.
+	Streams
+	// PathFinding are the requests to the /paths endpoints.
+	PathFinding
+
+	numCategories = 3
+)
+
+func (c Category) String() string {
+	switch c {
+	case Streams:
+		return "streams"
+	case PathFinding:
+		return "path_finding"
+	default:
+		return "requests"
+	}
+}
+
+// Quotas are the rate quotas of a client. When the Streams or PathFinding
+// quota is nil, these requests are counted against the Requests quota, in the
+// same bucket as the other requests. A nil Requests quota disables the limit.
+type Quotas struct {
+	Requests    *throttled.RateQuota
+	Streams     *throttled.RateQuota
+	PathFinding *throttled.RateQuota
+}
+
+// Account is a client of Horizon authenticating with API keys. All the keys
+// of an account share its quotas.
+type Account struct {
+	Name   string
+	Quotas Quotas
+	// KeyHashes are the hex encoded SHA-256 hashes of the API keys of the
+	// account, see HashAPIKey.
+	KeyHashes []string
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of an API key. Only the hashes
+// of the keys are stored in the DB and kept in memory.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// AccountSource loads the accounts allowed to use API keys.
+type AccountSource interface {
+	Accounts(ctx context.Context) ([]Account, error)
+}
+
+// AccountSources is an AccountSource returning the accounts of all its
+// sources.
+type AccountSources []AccountSource
+
+// Accounts implements AccountSource.
+func (s AccountSources) Accounts(ctx context.Context) ([]Account, error) {
+	var accounts []Account
+	for _, source := range s {
+		sourceAccounts, err := source.Accounts(ctx)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, sourceAccounts...)
+	}
+	return accounts, nil
+}
+
+// limit is the limiter of a category of requests of a client and the bucket,
+// the prefix of its keys in the Store, it is counted in.
+type limit struct {
+	limiter *GCRARateLimiter
+	bucket  string
+}
+
+type client struct {
+	id     string
+	limits [numCategories]limit
+}
+
+func newClient(store Store, id string, quotas Quotas) (*client, error) {
+	c := &client{id: id}
+	if quotas.Requests != nil {
+		limiter, err := NewGCRARateLimiter(store, *quotas.Requests)
+		if err != nil {
+			return nil, err
+		}
+		c.limits[Requests] = limit{limiter: limiter, bucket: Requests.String()}
+	}
+
+	for category, quota := range map[Category]*throttled.RateQuota{
+		Streams:     quotas.Streams,
+		PathFinding: quotas.PathFinding,
+	} {
+		if quota == nil {
+			c.limits[category] = c.limits[Requests]
+			continue
+		}
+		limiter, err := NewGCRARateLimiter(store, *quota)
+		if err != nil {
+			return nil, err
+		}
+		c.limits[category] = limit{limiter: limiter, bucket: category.String()}
+	}
+	return c, nil
+}
+
+// Limiter rate limits the requests of anonymous clients and accounts.
+type Limiter struct {
+	store     Store
+	anonymous *client
+
+	mutex sync.RWMutex
+	// keys maps the hashes of the API keys to their account.
+	keys map[string]*client
+}
+
+// NewLimiter returns a Limiter limiting the anonymous requests with the given
+// quotas. No API key is accepted until SetAccounts is called.
+func NewLimiter(store Store, anonymous Quotas) (*Limiter, error) {
+	c, err := newClient(store, "ip", anonymous)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid anonymous quotas")
+	}
+	return &Limiter{
+		store:     store,
+		anonymous: c,
+		keys:      map[string]*client{},
+	}, nil
+}
+
+// SetAccounts replaces the accounts allowed to use API keys.
+func (l *Limiter) SetAccounts(accounts []Account) error {
+	keys := map[string]*client{}
+	for _, account := range accounts {
+		c, err := newClient(l.store, "account:"+account.Name, account.Quotas)
+		if err != nil {
+			return errors.Wrapf(err, "invalid quotas for account %s", account.Name)
+		}
+		for _, hash := range account.KeyHashes {
+			if _, ok := keys[hash]; ok {
+				return errors.Errorf("API key %s is used by several accounts", hash)
+			}
+			keys[hash] = c
+		}
+	}
+
+	l.mutex.Lock()
+	l.keys = keys
+	l.mutex.Unlock()
+	return nil
+}
+
+// Run reloads the accounts from source every interval until ctx is
+// cancelled. Errors are logged and the previous accounts are kept.
+func (l *Limiter) Run(ctx context.Context, source AccountSource, interval time.Duration) {
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+
+		if err := l.Reload(ctx, source); err != nil {
+			log.Errorf("could not reload the rate limit accounts: %s", err)
+		}
+	}
+}
+
+// Reload loads the accounts from source.
+func (l *Limiter) Reload(ctx context.Context, source AccountSource) error {
+	accounts, err := source.Accounts(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not load the rate limit accounts")
+	}
+	return l.SetAccounts(accounts)
+}
+
+// HasAPIKey returns true if the API key belongs to an account.
+func (l *Limiter) HasAPIKey(apiKey string) bool {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	_, ok := l.keys[HashAPIKey(apiKey)]
+	return ok
+}
+
+// RateLimit counts a request of the given category made with apiKey, or by
+// remoteIP if apiKey is empty. It returns true if the request exceeds the
+// quota of the client. The fields of the result are negative when the request
+// is not limited by any quota.
+func (l *Limiter) RateLimit(apiKey, remoteIP string, category Category) (bool, throttled.RateLimitResult, error) {
+	c, key := l.anonymous, remoteIP
+	if apiKey != "" {
+		l.mutex.RLock()
+		account, ok := l.keys[HashAPIKey(apiKey)]
+		l.mutex.RUnlock()
+		if !ok {
+			return false, unlimited(), ErrInvalidAPIKey
+		}
+		c, key = account, ""
+	}
+
+	lim := c.limits[category]
+	if lim.limiter == nil {
+		return false, unlimited(), nil
+	}
+	storeKey := lim.bucket + ":" + c.id
+	if key != "" {
+		storeKey += ":" + key
+	}
+	limited, result, err := lim.limiter.RateLimit(storeKey, 1)
+	if err != nil {
+		return false, unlimited(), errors.Wrap(err, "could not rate limit request")
+	}
+	return limited, result, nil
+}
+
+func unlimited() throttled.RateLimitResult {
+	return throttled.RateLimitResult{Limit: -1, Remaining: -1, ResetAfter: -1, RetryAfter: -1}
+}
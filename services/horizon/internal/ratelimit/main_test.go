@@ -0,0 +1,241 @@
+package ratelimit
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+)
+
+func newTestLimiter(t *testing.T, anonymous Quotas) (*Limiter, *MemoryStore) {
+	store, err := NewMemoryStore(100)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	store.Clock = func() time.Time { return now }
+	limiter, err := NewLimiter(store, anonymous)
+	require.NoError(t, err)
+	return limiter, store
+}
+
+// requests makes n requests and returns the number of requests which were
+// not limited.
+func requests(t *testing.T, limiter *Limiter, apiKey, ip string, category Category, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		limited, _, err := limiter.RateLimit(apiKey, ip, category)
+		require.NoError(t, err)
+		if !limited {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestGCRARateLimiter(t *testing.T) {
+	store, err := NewMemoryStore(10)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	store.Clock = func() time.Time { return now }
+
+	limiter, err := NewGCRARateLimiter(store, *PerHour(10, 9))
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		limited, result, err := limiter.RateLimit("key", 1)
+		require.NoError(t, err)
+		assert.False(t, limited)
+		assert.Equal(t, 10, result.Limit)
+		assert.Equal(t, 10-(i+1), result.Remaining)
+		assert.Equal(t, time.Duration(i+1)*6*time.Minute, result.ResetAfter)
+		assert.Equal(t, time.Duration(-1), result.RetryAfter)
+	}
+
+	limited, result, err := limiter.RateLimit("key", 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 6*time.Minute, result.RetryAfter)
+
+	limited, _, err = limiter.RateLimit("other", 1)
+	require.NoError(t, err)
+	assert.False(t, limited)
+
+	now = now.Add(6 * time.Minute)
+	limited, _, err = limiter.RateLimit("key", 1)
+	require.NoError(t, err)
+	assert.False(t, limited)
+	limited, _, err = limiter.RateLimit("key", 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+}
+
+func TestLimiterAnonymous(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Quotas{Requests: PerHour(10, 4)})
+
+	assert.Equal(t, 5, requests(t, limiter, "", "1.1.1.1", Requests, 10))
+	assert.Equal(t, 5, requests(t, limiter, "", "2.2.2.2", Requests, 10))
+	// streams and path finding requests are counted against the requests quota
+	assert.Equal(t, 0, requests(t, limiter, "", "1.1.1.1", Streams, 1))
+	assert.Equal(t, 0, requests(t, limiter, "", "1.1.1.1", PathFinding, 1))
+}
+
+func TestLimiterCategories(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Quotas{
+		Requests:    PerHour(10, 4),
+		PathFinding: PerHour(10, 1),
+	})
+
+	assert.Equal(t, 2, requests(t, limiter, "", "1.1.1.1", PathFinding, 10))
+	assert.Equal(t, 5, requests(t, limiter, "", "1.1.1.1", Requests, 10))
+	assert.Equal(t, 0, requests(t, limiter, "", "1.1.1.1", Streams, 10))
+
+	limiter, _ = newTestLimiter(t, Quotas{Streams: PerHour(10, 2)})
+	assert.Equal(t, 3, requests(t, limiter, "", "1.1.1.1", Streams, 10))
+	assert.Equal(t, 10, requests(t, limiter, "", "1.1.1.1", Requests, 10))
+
+	limited, result, err := limiter.RateLimit("", "1.1.1.1", Requests)
+	require.NoError(t, err)
+	assert.False(t, limited)
+	assert.Equal(t, unlimited(), result)
+}
+
+func TestLimiterAPIKeys(t *testing.T) {
+	limiter, _ := newTestLimiter(t, Quotas{Requests: PerHour(10, 0)})
+
+	_, _, err := limiter.RateLimit("key-a", "1.1.1.1", Requests)
+	assert.Equal(t, ErrInvalidAPIKey, err)
+	assert.False(t, limiter.HasAPIKey("key-a"))
+
+	require.NoError(t, limiter.SetAccounts([]Account{
+		{
+			Name:      "a",
+			Quotas:    Quotas{Requests: PerHour(100, 4), Streams: PerHour(100, 1)},
+			KeyHashes: []string{HashAPIKey("key-a"), HashAPIKey("key-a2")},
+		},
+		{
+			Name:      "b",
+			KeyHashes: []string{HashAPIKey("key-b")},
+		},
+	}))
+	assert.True(t, limiter.HasAPIKey("key-a"))
+
+	// the keys of an account share its quotas, whatever the IP address
+	assert.Equal(t, 3, requests(t, limiter, "key-a", "1.1.1.1", Requests, 3))
+	assert.Equal(t, 2, requests(t, limiter, "key-a2", "2.2.2.2", Requests, 10))
+	assert.Equal(t, 2, requests(t, limiter, "key-a", "1.1.1.1", Streams, 10))
+	// anonymous requests are limited separately
+	assert.Equal(t, 1, requests(t, limiter, "", "1.1.1.1", Requests, 10))
+	// accounts without quotas are not limited
+	assert.Equal(t, 100, requests(t, limiter, "key-b", "1.1.1.1", Requests, 100))
+
+	err = limiter.SetAccounts([]Account{
+		{Name: "a", KeyHashes: []string{HashAPIKey("key-a")}},
+		{Name: "b", KeyHashes: []string{HashAPIKey("key-a")}},
+	})
+	assert.EqualError(t, err, "API key "+HashAPIKey("key-a")+" is used by several accounts")
+	// the previous accounts are kept
+	assert.True(t, limiter.HasAPIKey("key-b"))
+
+	require.NoError(t, limiter.SetAccounts(nil))
+	_, _, err = limiter.RateLimit("key-a", "1.1.1.1", Requests)
+	assert.Equal(t, ErrInvalidAPIKey, err)
+}
+
+func TestFileAccountSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ratelimit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "accounts.toml")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+[[accounts]]
+name = "partner"
+per_hour_rate_limit = 1000
+per_hour_path_finding_rate_limit = 10
+max_burst = 5
+api_keys = ["secret"]
+api_key_hashes = ["`+HashAPIKey("other")+`"]
+
+[[accounts]]
+name = "unlimited"
+api_keys = ["unlimited"]
+`), 0600))
+
+	accounts, err := FileAccountSource{Path: path}.Accounts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Account{
+		{
+			Name: "partner",
+			Quotas: Quotas{
+				Requests:    PerHour(1000, 5),
+				PathFinding: PerHour(10, 5),
+			},
+			KeyHashes: []string{HashAPIKey("other"), HashAPIKey("secret")},
+		},
+		{
+			Name:      "unlimited",
+			KeyHashes: []string{HashAPIKey("unlimited")},
+		},
+	}, accounts)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+[[accounts]]
+name = "partner"
+unknown = 1
+`), 0600))
+	_, err = FileAccountSource{Path: path}.Accounts(context.Background())
+	assert.Error(t, err)
+}
+
+type mockQRateLimitAccounts struct {
+	mock.Mock
+}
+
+func (m *mockQRateLimitAccounts) GetRateLimitAccounts(ctx context.Context) ([]history.RateLimitAccount, error) {
+	a := m.Called(ctx)
+	return a.Get(0).([]history.RateLimitAccount), a.Error(1)
+}
+
+func (m *mockQRateLimitAccounts) UpsertRateLimitAccount(ctx context.Context, account history.RateLimitAccount) error {
+	return m.Called(ctx, account).Error(0)
+}
+
+func (m *mockQRateLimitAccounts) InsertRateLimitAPIKey(ctx context.Context, account, keyHash string) error {
+	return m.Called(ctx, account, keyHash).Error(0)
+}
+
+func TestDBAccountSource(t *testing.T) {
+	q := &mockQRateLimitAccounts{}
+	ctx := context.Background()
+	q.On("GetRateLimitAccounts", ctx).Return([]history.RateLimitAccount{
+		{
+			Name:                   "partner",
+			PerHourRateLimit:       1000,
+			PerHourStreamRateLimit: 100,
+			MaxBurst:               10,
+			KeyHashes:              []string{"aa"},
+		},
+	}, nil).Once()
+
+	accounts, err := DBAccountSource{Q: q}.Accounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Account{
+		{
+			Name: "partner",
+			Quotas: Quotas{
+				Requests: PerHour(1000, 10),
+				Streams:  PerHour(100, 10),
+			},
+			KeyHashes: []string{"aa"},
+		},
+	}, accounts)
+	q.AssertExpectations(t)
+}
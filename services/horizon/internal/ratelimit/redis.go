@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/stellar/go/support/errors"
+)
+
+// DefaultRedisKeyPrefix is the prefix of the keys written by RedisStore.
+const DefaultRedisKeyPrefix = "horizon:ratelimit:"
+
+// casScript sets the value of a key if it exists and its value is ARGV[1].
+var casScript = redis.NewScript(1, `
+local v = redis.call('get', KEYS[1])
+if v == false or v ~= ARGV[1] then
+	return 0
+end
+redis.call('psetex', KEYS[1], ARGV[3], ARGV[2])
+return 1
+`)
+
+// RedisStore is a Store backed by a server speaking the Redis protocol
+// (Redis, KeyDB, Dragonfly, ...), so that the rate limits are shared by all
+// the Horizon instances using it. The time of the server is used, so the
+// clocks of the Horizon instances do not need to be synchronized.
+type RedisStore struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+// NewRedisStore returns a RedisStore connecting to the server at the given
+// URL (redis://[:password@]host[:port][/db]). Keys are prefixed with prefix.
+func NewRedisStore(url, prefix string) (*RedisStore, error) {
+	pool := &redis.Pool{
+		MaxIdle:     16,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(
+				url,
+				redis.DialConnectTimeout(5*time.Second),
+				redis.DialReadTimeout(time.Second),
+				redis.DialWriteTimeout(time.Second),
+			)
+		},
+		TestOnBorrow: func(conn redis.Conn, idleSince time.Time) error {
+			if time.Since(idleSince) < time.Minute {
+				return nil
+			}
+			_, err := conn.Do("PING")
+			return err
+		},
+	}
+
+	conn := pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("PING"); err != nil {
+		pool.Close()
+		return nil, errors.Wrap(err, "could not connect to the rate limit store")
+	}
+	return &RedisStore{pool: pool, prefix: prefix}, nil
+}
+
+// Close closes the connections to the server.
+func (s *RedisStore) Close() error {
+	return s.pool.Close()
+}
+
+func (s *RedisStore) GetWithTime(key string) (int64, time.Time, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "could not send MULTI")
+	}
+	if err := conn.Send("GET", s.prefix+key); err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "could not send GET")
+	}
+	if err := conn.Send("TIME"); err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "could not send TIME")
+	}
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "could not get rate limit state")
+	}
+	if len(replies) != 2 {
+		return 0, time.Time{}, errors.Errorf("unexpected reply to EXEC: %v", replies)
+	}
+
+	serverTime, err := redis.Int64s(replies[1], nil)
+	if err != nil || len(serverTime) != 2 {
+		return 0, time.Time{}, errors.Errorf("unexpected reply to TIME: %v", replies[1])
+	}
+	now := time.Unix(serverTime[0], serverTime[1]*int64(time.Microsecond))
+
+	value, err := redis.Int64(replies[0], nil)
+	if err == redis.ErrNil {
+		return -1, now, nil
+	} else if err != nil {
+		return 0, now, errors.Wrap(err, "unexpected reply to GET")
+	}
+	return value, now, nil
+}
+
+func (s *RedisStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", s.prefix+key, value, "PX", ttlMillis(ttl), "NX"))
+	if err == redis.ErrNil {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "could not set rate limit state")
+	}
+	return true, nil
+}
+
+func (s *RedisStore) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	swapped, err := redis.Bool(casScript.Do(conn, s.prefix+key, old, new, ttlMillis(ttl)))
+	if err != nil {
+		return false, errors.Wrap(err, "could not update rate limit state")
+	}
+	return swapped, nil
+}
+
+// ttlMillis rounds ttl up to a number of milliseconds, the resolution of
+// Redis expirations.
+func ttlMillis(ttl time.Duration) int64 {
+	ms := int64((ttl + time.Millisecond - 1) / time.Millisecond)
+	if ms < 1 {
+		ms = 1
+	}
+	return ms
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Store holds the state of the rate limiters, the theoretical arrival time of
+// the next request of every key. It is implemented in memory by MemoryStore
+// and, to share the limits between Horizon instances, by RedisStore.
+type Store interface {
+	// GetWithTime returns the value of the key, or -1 if it does not exist,
+	// and the current time of the store.
+	GetWithTime(key string) (int64, time.Time, error)
+	// SetIfNotExistsWithTTL sets the value of the key if it does not exist
+	// and returns true if it was set.
+	SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error)
+	// CompareAndSwapWithTTL sets the value of the key if its current value is
+	// old and returns true if it was set.
+	CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error)
+}
+
+type memoryEntry struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// MemoryStore is a Store keeping the most recently used keys in memory. It
+// only limits the requests served by a single Horizon instance.
+type MemoryStore struct {
+	// Clock returns the current time, it defaults to time.Now.
+	Clock func() time.Time
+
+	mutex sync.Mutex
+	keys  *lru.Cache
+}
+
+// NewMemoryStore returns a MemoryStore holding up to maxKeys keys.
+func NewMemoryStore(maxKeys int) (*MemoryStore, error) {
+	keys, err := lru.New(maxKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &MemoryStore{Clock: time.Now, keys: keys}, nil
+}
+
+// get returns the entry of the key if it exists and did not expire. The
+// mutex must be held.
+func (s *MemoryStore) get(key string, now time.Time) (*memoryEntry, bool) {
+	value, ok := s.keys.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := value.(*memoryEntry)
+	if !now.Before(entry.expiresAt) {
+		s.keys.Remove(key)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *MemoryStore) GetWithTime(key string) (int64, time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.Clock()
+	entry, ok := s.get(key, now)
+	if !ok {
+		return -1, now, nil
+	}
+	return entry.value, now, nil
+}
+
+func (s *MemoryStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.Clock()
+	if _, ok := s.get(key, now); ok {
+		return false, nil
+	}
+	s.keys.Add(key, &memoryEntry{value: value, expiresAt: now.Add(ttl)})
+	return true, nil
+}
+
+func (s *MemoryStore) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.Clock()
+	entry, ok := s.get(key, now)
+	if !ok || entry.value != old {
+		return false, nil
+	}
+	entry.value = new
+	entry.expiresAt = now.Add(ttl)
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore checks the behaviour of a Store, key must not exist in the store.
+func testStore(t *testing.T, store Store, key string) {
+	value, _, err := store.GetWithTime(key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), value)
+
+	swapped, err := store.CompareAndSwapWithTTL(key, -1, 1, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	set, err := store.SetIfNotExistsWithTTL(key, 5, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, set)
+	set, err = store.SetIfNotExistsWithTTL(key, 6, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	value, _, err = store.GetWithTime(key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	swapped, err = store.CompareAndSwapWithTTL(key, 4, 7, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, swapped)
+	swapped, err = store.CompareAndSwapWithTTL(key, 5, 7, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, swapped)
+
+	value, _, err = store.GetWithTime(key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), value)
+}
+
+func TestMemoryStore(t *testing.T) {
+	store, err := NewMemoryStore(10)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	store.Clock = func() time.Time { return now }
+
+	testStore(t, store, "key")
+
+	_, storeTime, err := store.GetWithTime("key")
+	require.NoError(t, err)
+	assert.Equal(t, now, storeTime)
+
+	now = now.Add(time.Minute)
+	value, _, err := store.GetWithTime("key")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), value)
+}
+
+// TestRedisStore runs against the server set with the REDIS_URL environment
+// variable, e.g. REDIS_URL=redis://localhost:6379/0.
+func TestRedisStore(t *testing.T) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		t.Skip("REDIS_URL is not set")
+	}
+
+	prefix := "horizon-test:" + strconv.FormatInt(time.Now().UnixNano(), 10) + ":"
+	store, err := NewRedisStore(url, prefix)
+	require.NoError(t, err)
+	defer store.Close()
+
+	testStore(t, store, "key")
+
+	set, err := store.SetIfNotExistsWithTTL("expiring", 1, time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, set)
+	time.Sleep(10 * time.Millisecond)
+	value, _, err := store.GetWithTime("expiring")
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), value)
+
+	limiter, err := NewGCRARateLimiter(store, *PerHour(10, 1))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		limited, _, err := limiter.RateLimit("limited", 1)
+		require.NoError(t, err)
+		assert.False(t, limited)
+	}
+	limited, result, err := limiter.RateLimit("limited", 1)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestNewRedisStoreUnreachable(t *testing.T) {
+	_, err := NewRedisStore("redis://127.0.0.1:1", DefaultRedisKeyPrefix)
+	assert.Error(t, err)
+}
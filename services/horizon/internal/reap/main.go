@@ -6,6 +6,7 @@ package reap
 
 import (
 	"context"
+	"time"
 
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/ledger"
@@ -16,22 +17,26 @@ import (
 type System struct {
 	HistoryQ       *history.Q
 	RetentionCount uint
-	ledgerState    *ledger.State
-	ctx            context.Context
-	cancel         context.CancelFunc
+	// WebhookDeliveryRetention is the duration the delivered and failed
+	// webhook deliveries are kept for, 0 keeps them all.
+	WebhookDeliveryRetention time.Duration
+	ledgerState              *ledger.State
+	ctx                      context.Context
+	cancel                   context.CancelFunc
 }
 
 // New initializes the reaper, causing it to begin polling the stellar-core
 // database for now ledgers and ingesting data into the horizon database.
-func New(retention uint, dbSession db.SessionInterface, ledgerState *ledger.State) *System {
+func New(retention uint, webhookDeliveryRetention time.Duration, dbSession db.SessionInterface, ledgerState *ledger.State) *System {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	r := &System{
-		HistoryQ:       &history.Q{dbSession.Clone()},
-		RetentionCount: retention,
-		ledgerState:    ledgerState,
-		ctx:            ctx,
-		cancel:         cancel,
+		HistoryQ:                 &history.Q{dbSession.Clone()},
+		RetentionCount:           retention,
+		WebhookDeliveryRetention: webhookDeliveryRetention,
+		ledgerState:              ledgerState,
+		ctx:                      ctx,
+		cancel:                   cancel,
 	}
 
 	return r
@@ -26,7 +26,12 @@ func (r *System) DeleteUnretainedHistory(ctx context.Context) error {
 		return nil
 	}
 
-	err := r.clearBefore(ctx, latest.HistoryElder, targetElder)
+	err := r.rebaseAccountBalances(ctx, targetElder)
+	if err != nil {
+		return err
+	}
+
+	err = r.clearBefore(ctx, latest.HistoryElder, targetElder)
 	if err != nil {
 		return err
 	}
@@ -38,6 +43,26 @@ func (r *System) DeleteUnretainedHistory(ctx context.Context) error {
 	return nil
 }
 
+// DeleteExpiredWebhookDeliveries removes the delivered and failed webhook
+// deliveries older than WebhookDeliveryRetention.
+func (r *System) DeleteExpiredWebhookDeliveries(ctx context.Context) error {
+	// WebhookDeliveryRetention of 0 indicates "keep all deliveries"
+	if r.WebhookDeliveryRetention == 0 {
+		return nil
+	}
+
+	deleted, err := r.HistoryQ.DeleteWebhookDeliveriesBefore(ctx, time.Now().Add(-r.WebhookDeliveryRetention))
+	if err != nil {
+		return err
+	}
+
+	log.
+		WithField("deleted", deleted).
+		Info("reaper removed expired webhook deliveries")
+
+	return nil
+}
+
 // Run triggers the reaper system to update itself, deleted unretained history
 // if it is the appropriate time.
 func (r *System) Run() {
@@ -68,6 +93,11 @@ func (r *System) runOnce(ctx context.Context) {
 	if err != nil {
 		log.Errorf("reaper failed: %s", err)
 	}
+
+	err = r.DeleteExpiredWebhookDeliveries(ctx)
+	if err != nil {
+		log.Errorf("reaper failed to remove webhook deliveries: %s", err)
+	}
 }
 
 // Work backwards in 100k ledger blocks to prevent using all the CPU.
@@ -82,6 +112,29 @@ func (r *System) runOnce(ctx context.Context) {
 var batchSize = int32(100_000)
 var sleep = 1 * time.Second
 
+// rebaseAccountBalances moves the balances recorded before the new elder to a
+// snapshot at the new elder, the balances which did not change since are lost
+// otherwise.
+func (r *System) rebaseAccountBalances(ctx context.Context, elder int32) error {
+	err := r.HistoryQ.Begin()
+	if err != nil {
+		return errors.Wrap(err, "Error in begin")
+	}
+	defer r.HistoryQ.Rollback()
+
+	err = r.HistoryQ.RebaseAccountBalances(ctx, uint32(elder))
+	if err != nil {
+		return errors.Wrap(err, "Error in RebaseAccountBalances")
+	}
+
+	err = r.HistoryQ.Commit()
+	if err != nil {
+		return errors.Wrap(err, "Error in commit")
+	}
+
+	return nil
+}
+
 func (r *System) clearBefore(ctx context.Context, startSeq, endSeq int32) error {
 	for batchEndSeq := endSeq - 1; batchEndSeq >= startSeq; batchEndSeq -= batchSize {
 		batchStartSeq := batchEndSeq - batchSize
@@ -11,7 +11,7 @@ import (
 // what the most appropriate response type should be.  Defaults to HAL.
 func Negotiate(r *http.Request) string {
 	ctx := r.Context()
-	alternatives := []string{MimeHal, MimeJSON, MimeEventStream, MimeRaw}
+	alternatives := []string{MimeHal, MimeJSON, MimeEventStream, MimeRaw, MimeCSV}
 	accept := r.Header.Get("Accept")
 
 	if accept == "" {
@@ -20,6 +20,7 @@ func TestNegotiate(t *testing.T) {
 		// Obeys the Accept header's prioritization
 		{"application/hal+json", MimeHal},
 		{"text/event-stream,application/hal+json", MimeEventStream},
+		{"text/csv", MimeCSV},
 		// Defaults to HAL
 		{"text/event-stream;q=0.5,application/hal+json", MimeHal},
 		{"", MimeHal},
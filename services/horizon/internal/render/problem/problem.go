@@ -34,12 +34,22 @@ var (
 		Type:   "rate_limit_exceeded",
 		Title:  "Rate Limit Exceeded",
 		Status: 429,
-		Detail: "The rate limit for the requesting IP address is over its alloted " +
+		Detail: "The rate limit for the requesting IP address or API key is over its alloted " +
 			"limit.  The allowed limit and requests left per time period are " +
 			"communicated to clients via the http response headers 'X-RateLimit-*' " +
 			"headers.",
 	}
 
+	// InvalidAPIKey is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	InvalidAPIKey = problem.P{
+		Type:   "invalid_api_key",
+		Title:  "Invalid API Key",
+		Status: http.StatusUnauthorized,
+		Detail: "The API key sent with the 'X-API-Key' header or the 'api_key' " +
+			"query parameter is not valid. Remove it to make anonymous requests.",
+	}
+
 	// NotImplemented is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	NotImplemented = problem.P{
@@ -103,6 +113,16 @@ var (
 			"this horizon instance.",
 	}
 
+	// AfterHistory is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	AfterHistory = problem.P{
+		Type:   "after_history",
+		Title:  "Data Requested Is After Recorded History",
+		Status: http.StatusNotFound,
+		Detail: "This request is asking for results of a ledger which has not " +
+			"been ingested by this horizon instance yet. Please try again later.",
+	}
+
 	// StaleHistory is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	StaleHistory = problem.P{
@@ -132,4 +152,13 @@ var (
 		Detail: "This horizon service did not ingested full ledger." +
 			" Coin-in-circulation can only generate from full node.",
 	}
+
+	KinesisCoinInCirculationBackfilling = problem.P{
+		Type:   "coin_in_circulation_backfilling",
+		Title:  "Coin In Circulation Backfilling",
+		Status: http.StatusServiceUnavailable,
+		Detail: "The coin in circulation of the ledgers ingested before the last upgrade " +
+			"is still being backfilled by the ingesting instance. Please wait for " +
+			"several minutes before trying your request again.",
+	}
 )
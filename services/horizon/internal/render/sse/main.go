@@ -17,6 +17,21 @@ type Event struct {
 	Retry int
 }
 
+// EventWriter receives the events of a stream in place of the
+// http.ResponseWriter of the request, see WithEventWriter.
+type EventWriter interface {
+	WriteEvent(e Event)
+}
+
+type eventWriterContextKey struct{}
+
+// WithEventWriter returns a context making the streams of the requests using
+// it send their events to ew instead of formatting them in the response. It
+// lets the streams be served over other transports than Server Sent Events.
+func WithEventWriter(ctx context.Context, ew EventWriter) context.Context {
+	return context.WithValue(ctx, eventWriterContextKey{}, ew)
+}
+
 // WritePreamble prepares this http connection for streaming using Server Sent
 // Events. It sends the initial http response with the appropriate headers to
 // do so.
@@ -40,8 +55,14 @@ func WritePreamble(ctx context.Context, w http.ResponseWriter) bool {
 }
 
 // WriteEvent does the actual work of formatting an SSE compliant message
-// sending it over the provided ResponseWriter and flushing.
+// sending it over the provided ResponseWriter and flushing. The event is sent
+// to the EventWriter of ctx instead when there is one.
 func WriteEvent(ctx context.Context, w http.ResponseWriter, e Event) {
+	if ew, ok := ctx.Value(eventWriterContextKey{}).(EventWriter); ok {
+		ew.WriteEvent(e)
+		return
+	}
+
 	if e.Error != nil {
 		fmt.Fprint(w, "event: error\n")
 		fmt.Fprintf(w, "data: %s\n\n", e.Error.Error())
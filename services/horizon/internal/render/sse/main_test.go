@@ -53,3 +53,24 @@ func TestWritePreamble(t *testing.T) {
 	assert.Equal(t, 200, w.Code)
 	assert.Contains(t, w.Body.String(), "retry: 1000\nevent: open\ndata: \"hello\"\n\n")
 }
+
+type eventRecorder []Event
+
+func (r *eventRecorder) WriteEvent(e Event) {
+	*r = append(*r, e)
+}
+
+// Tests that the events are sent to the EventWriter of the context instead of
+// the response.
+func TestWithEventWriter(t *testing.T) {
+	ctx, _ := test.ContextWithLogBuffer()
+	var events eventRecorder
+	ctx = WithEventWriter(ctx, &events)
+
+	w := httptest.NewRecorder()
+	assert.True(t, WritePreamble(ctx, w))
+	WriteEvent(ctx, w, Event{ID: "1", Data: "test"})
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, eventRecorder{helloEvent, {ID: "1", Data: "test"}}, events)
+}
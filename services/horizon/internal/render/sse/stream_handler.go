@@ -5,16 +5,22 @@ import (
 
 	"github.com/stellar/go/services/horizon/internal/ledger"
 	"github.com/stellar/go/support/errors"
-	"github.com/stellar/throttled"
 )
 
 type LedgerSourceFactory interface {
 	Get() ledger.Source
 }
 
+// RateLimiter limits the rate of the updates sent to streams.
+type RateLimiter interface {
+	// RateLimit returns true if the update of the stream of the request
+	// exceeds the rate limit.
+	RateLimit(r *http.Request) (bool, error)
+}
+
 // StreamHandler represents a stream handling action
 type StreamHandler struct {
-	RateLimiter         *throttled.HTTPRateLimiter
+	RateLimiter         RateLimiter
 	LedgerSourceFactory LedgerSourceFactory
 }
 
@@ -43,7 +49,7 @@ func (handler StreamHandler) ServeStream(
 		// https://github.com/stellar/go/issues/715 for more details.
 		rateLimiter := handler.RateLimiter
 		if rateLimiter != nil {
-			limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
+			limited, err := rateLimiter.RateLimit(r)
 			if err != nil {
 				stream.Err(errors.Wrap(err, "RateLimiter error"))
 				return
@@ -0,0 +1,241 @@
+package simulation
+
+import (
+	"context"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// ledgerView caches the ledger entries loaded from the state and records the
+// changes made by the simulated operations. Entries which do not exist are
+// cached as nil.
+type ledgerView struct {
+	ctx   context.Context
+	state State
+
+	accounts          map[string]*history.AccountEntry
+	signerWeights     map[string]map[string]int32
+	trustLines        map[string]*history.TrustLine
+	offers            map[int64]*history.Offer
+	claimableBalances map[string]*history.ClaimableBalance
+	liquidityPools    map[string]*history.LiquidityPool
+}
+
+func newLedgerView(ctx context.Context, state State) *ledgerView {
+	return &ledgerView{
+		ctx:               ctx,
+		state:             state,
+		accounts:          map[string]*history.AccountEntry{},
+		signerWeights:     map[string]map[string]int32{},
+		trustLines:        map[string]*history.TrustLine{},
+		offers:            map[int64]*history.Offer{},
+		claimableBalances: map[string]*history.ClaimableBalance{},
+		liquidityPools:    map[string]*history.LiquidityPool{},
+	}
+}
+
+// account returns the account with the given id, or nil if it does not exist.
+func (v *ledgerView) account(id string) (*history.AccountEntry, error) {
+	if account, ok := v.accounts[id]; ok {
+		return account, nil
+	}
+
+	rows, err := v.state.GetAccountsByIDs(v.ctx, []string{id})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load account")
+	}
+	var account *history.AccountEntry
+	if len(rows) > 0 {
+		account = &rows[0]
+	}
+	v.accounts[id] = account
+	return account, nil
+}
+
+// signers returns the weights of the signers of an account, including its
+// master key.
+func (v *ledgerView) signers(id string) (map[string]int32, error) {
+	if weights, ok := v.signerWeights[id]; ok {
+		return weights, nil
+	}
+
+	rows, err := v.state.SignersForAccounts(v.ctx, []string{id})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load account signers")
+	}
+	weights := map[string]int32{}
+	for _, row := range rows {
+		weights[row.Signer] = row.Weight
+	}
+	v.signerWeights[id] = weights
+	return weights, nil
+}
+
+func (v *ledgerView) createAccount(id string, balance int64, sequence int64) *history.AccountEntry {
+	account := &history.AccountEntry{
+		AccountID:      id,
+		Balance:        balance,
+		SequenceNumber: sequence,
+		MasterWeight:   1,
+	}
+	v.accounts[id] = account
+	v.signerWeights[id] = map[string]int32{id: 1}
+	return account
+}
+
+func (v *ledgerView) removeAccount(id string) {
+	v.accounts[id] = nil
+	v.signerWeights[id] = map[string]int32{}
+}
+
+// trustLine returns the trust line of an account for the given asset, or nil
+// if it does not exist.
+func (v *ledgerView) trustLine(accountID string, asset xdr.TrustLineAsset) (*history.TrustLine, error) {
+	key, err := trustLineKey(accountID, asset)
+	if err != nil {
+		return nil, err
+	}
+	if trustLine, ok := v.trustLines[key]; ok {
+		return trustLine, nil
+	}
+
+	rows, err := v.state.GetTrustLinesByKeys(v.ctx, []string{key})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load trust line")
+	}
+	var trustLine *history.TrustLine
+	if len(rows) > 0 {
+		trustLine = &rows[0]
+	}
+	v.trustLines[key] = trustLine
+	return trustLine, nil
+}
+
+func (v *ledgerView) createTrustLine(accountID string, asset xdr.TrustLineAsset, limit int64, flags uint32) (*history.TrustLine, error) {
+	key, err := trustLineKey(accountID, asset)
+	if err != nil {
+		return nil, err
+	}
+	trustLine := &history.TrustLine{
+		AccountID: accountID,
+		AssetType: asset.Type,
+		LedgerKey: key,
+		Limit:     limit,
+		Flags:     flags,
+	}
+	v.trustLines[key] = trustLine
+	return trustLine, nil
+}
+
+func (v *ledgerView) removeTrustLine(trustLine *history.TrustLine) {
+	v.trustLines[trustLine.LedgerKey] = nil
+}
+
+// offer returns the offer with the given id, or nil if it does not exist.
+func (v *ledgerView) offer(id int64) (*history.Offer, error) {
+	if offer, ok := v.offers[id]; ok {
+		return offer, nil
+	}
+
+	rows, err := v.state.GetOffersByIDs(v.ctx, []int64{id})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load offer")
+	}
+	var offer *history.Offer
+	if len(rows) > 0 && !rows[0].Deleted {
+		offer = &rows[0]
+	}
+	v.offers[id] = offer
+	return offer, nil
+}
+
+// claimableBalance returns the claimable balance with the given id, or nil if
+// it does not exist.
+func (v *ledgerView) claimableBalance(id xdr.ClaimableBalanceId) (*history.ClaimableBalance, error) {
+	hexID, err := xdr.MarshalHex(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode claimable balance id")
+	}
+	if balance, ok := v.claimableBalances[hexID]; ok {
+		return balance, nil
+	}
+
+	rows, err := v.state.GetClaimableBalancesByID(v.ctx, []string{hexID})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load claimable balance")
+	}
+	var balance *history.ClaimableBalance
+	if len(rows) > 0 {
+		balance = &rows[0]
+	}
+	v.claimableBalances[hexID] = balance
+	return balance, nil
+}
+
+func (v *ledgerView) removeClaimableBalance(balance *history.ClaimableBalance) {
+	v.claimableBalances[balance.BalanceID] = nil
+}
+
+// liquidityPool returns the liquidity pool with the given id, or nil if it
+// does not exist.
+func (v *ledgerView) liquidityPool(id xdr.PoolId) (*history.LiquidityPool, error) {
+	hexID := xdr.Hash(id).HexString()
+	if pool, ok := v.liquidityPools[hexID]; ok {
+		return pool, nil
+	}
+
+	rows, err := v.state.GetLiquidityPoolsByID(v.ctx, []string{hexID})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load liquidity pool")
+	}
+	var pool *history.LiquidityPool
+	if len(rows) > 0 && !rows[0].Deleted {
+		pool = &rows[0]
+	}
+	v.liquidityPools[hexID] = pool
+	return pool, nil
+}
+
+func trustLineKey(accountID string, asset xdr.TrustLineAsset) (string, error) {
+	var account xdr.AccountId
+	if err := account.SetAddress(accountID); err != nil {
+		return "", errors.Wrapf(err, "invalid account %s", accountID)
+	}
+	var key xdr.LedgerKey
+	if err := key.SetTrustline(account, asset); err != nil {
+		return "", errors.Wrap(err, "could not build trust line key")
+	}
+	encoded, err := key.MarshalBinaryBase64()
+	if err != nil {
+		return "", errors.Wrap(err, "could not encode trust line key")
+	}
+	return encoded, nil
+}
+
+// minBalance returns the minimum native balance of an account, in stroops.
+func minBalance(account *history.AccountEntry, baseReserve int32) int64 {
+	entries := int64(2) + int64(account.NumSubEntries) + int64(account.NumSponsoring) - int64(account.NumSponsored)
+	return entries * int64(baseReserve)
+}
+
+// availableNative returns the native balance an account can spend, in
+// stroops.
+func availableNative(account *history.AccountEntry, baseReserve int32) int64 {
+	return account.Balance - minBalance(account, baseReserve) - account.SellingLiabilities
+}
+
+// availableCredit returns the balance of a trust line which can be spent.
+func availableCredit(trustLine *history.TrustLine) int64 {
+	return trustLine.Balance - trustLine.SellingLiabilities
+}
+
+// availableLimit returns the amount a trust line can receive.
+func availableLimit(trustLine *history.TrustLine) int64 {
+	return trustLine.Limit - trustLine.Balance - trustLine.BuyingLiabilities
+}
+
+func isAuthorized(trustLine *history.TrustLine) bool {
+	return xdr.TrustLineFlags(trustLine.Flags)&xdr.TrustLineFlagsAuthorizedFlag != 0
+}
@@ -0,0 +1,422 @@
+// Package simulation checks whether a transaction would obviously fail when
+// applied on top of the ledger state ingested by Horizon, without submitting
+// it to stellar-core.
+//
+// The simulation replicates the validity checks of stellar-core which only
+// depend on the ingested state: timebounds, fees (including the Kinesis
+// percentage fee), sequence numbers, signatures and thresholds, balances,
+// trust lines and their authorization, offers, claimable balances and
+// liquidity pools. Operations are simulated in order and their obvious effects
+// (balances moved, accounts and trust lines created) are visible to the
+// following operations. Effects which depend on the order book, like the
+// amounts exchanged by path payments and offers, are not simulated, so a
+// successful simulation does not guarantee that the transaction will succeed.
+package simulation
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/services/horizon/internal/codes"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrInvalidTransaction is returned when the simulated transaction envelope
+// cannot be decoded.
+var ErrInvalidTransaction = errors.New("invalid transaction envelope")
+
+// State provides the ledger entries ingested by Horizon.
+type State interface {
+	GetAccountsByIDs(ctx context.Context, ids []string) ([]history.AccountEntry, error)
+	SignersForAccounts(ctx context.Context, accounts []string) ([]history.AccountSigner, error)
+	GetTrustLinesByKeys(ctx context.Context, ledgerKeys []string) ([]history.TrustLine, error)
+	GetOffersByIDs(ctx context.Context, ids []int64) ([]history.Offer, error)
+	GetClaimableBalancesByID(ctx context.Context, ids []string) ([]history.ClaimableBalance, error)
+	GetLiquidityPoolsByID(ctx context.Context, poolIDs []string) ([]history.LiquidityPool, error)
+}
+
+// Simulator simulates transactions on top of the state of a ledger.
+type Simulator struct {
+	State             State
+	NetworkPassphrase string
+	// Ledger is the ledger whose state is provided by State. Its base fee,
+	// base percentage fee and base reserve are used to compute the minimum
+	// fee and balances of the transactions, and its close time to check their
+	// timebounds.
+	Ledger history.Ledger
+}
+
+// Result is the outcome of a transaction simulation. The codes use the
+// vocabulary of the result codes of transactions submitted to the network.
+type Result struct {
+	Hash string
+	// TransactionCode is the result code of the transaction, and
+	// TransactionReason describes why the transaction failed.
+	TransactionCode   string
+	TransactionReason string
+	// InnerTransactionHash, InnerTransactionCode and InnerTransactionReason
+	// describe the inner transaction of a fee bump transaction.
+	InnerTransactionHash   string
+	InnerTransactionCode   string
+	InnerTransactionReason string
+	// MinFee is the minimum fee, in stroops, accepted by the network for the
+	// transaction and FeeCharged the fee the transaction would be charged.
+	MinFee     int64
+	FeeCharged int64
+	// Operations are the results of the operations of the transaction. They
+	// are empty when the transaction failed before its operations were
+	// simulated.
+	Operations []OperationResult
+}
+
+// Successful returns true if the transaction is not expected to fail.
+func (r Result) Successful() bool {
+	return r.TransactionCode == txSuccess || r.TransactionCode == txFeeBumpInnerSuccess
+}
+
+// OperationResult is the outcome of the simulation of an operation.
+type OperationResult struct {
+	Code   string
+	Reason string
+}
+
+var (
+	txSuccess             = mustCode(xdr.TransactionResultCodeTxSuccess)
+	txFailed              = mustCode(xdr.TransactionResultCodeTxFailed)
+	txFeeBumpInnerSuccess = mustCode(xdr.TransactionResultCodeTxFeeBumpInnerSuccess)
+	txFeeBumpInnerFailed  = mustCode(xdr.TransactionResultCodeTxFeeBumpInnerFailed)
+)
+
+// failure is the result code of a failed check with the reason of the
+// failure.
+type failure struct {
+	code   string
+	reason string
+}
+
+func fail(code interface{}, format string, args ...interface{}) *failure {
+	return &failure{code: mustCode(code), reason: errors.Errorf(format, args...).Error()}
+}
+
+func mustCode(code interface{}) string {
+	str, err := codes.String(code)
+	if err != nil {
+		panic(errors.Wrapf(err, "unexpected result code %v", code))
+	}
+	return str
+}
+
+// Simulate simulates the transaction encoded in the given base64 envelope.
+// ErrInvalidTransaction is returned if the envelope cannot be decoded.
+func (s Simulator) Simulate(ctx context.Context, envelope string) (Result, error) {
+	parsed, err := txnbuild.TransactionFromXDR(envelope)
+	if err != nil {
+		return Result{}, errors.Wrap(ErrInvalidTransaction, err.Error())
+	}
+
+	view := newLedgerView(ctx, s.State)
+	var result Result
+	if feeBump, ok := parsed.FeeBump(); ok {
+		err = s.simulateFeeBump(view, feeBump, &result)
+	} else {
+		tx, _ := parsed.Transaction()
+		err = s.simulateTransaction(view, tx, &result, true)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+func (s Simulator) simulateFeeBump(view *ledgerView, feeBump *txnbuild.FeeBumpTransaction, result *Result) error {
+	hash, err := feeBump.Hash(s.NetworkPassphrase)
+	if err != nil {
+		return errors.Wrap(err, "could not hash transaction")
+	}
+	result.Hash = hex.EncodeToString(hash[:])
+
+	inner := feeBump.InnerTransaction()
+	innerMinFee, err := s.minFee(view, inner)
+	if err != nil {
+		return err
+	}
+	// The fee bump counts as one more operation paying the base fee.
+	result.MinFee = innerMinFee + int64(s.Ledger.BaseFee)
+	result.FeeCharged = result.MinFee
+
+	feeAccount, err := accountID(feeBump.FeeAccount())
+	if err != nil {
+		return errors.Wrap(ErrInvalidTransaction, err.Error())
+	}
+	failed, err := s.checkFeeBump(view, feeBump, hash, feeAccount, result.MinFee)
+	if err != nil {
+		return err
+	}
+	if failed != nil {
+		result.TransactionCode, result.TransactionReason = failed.code, failed.reason
+		return nil
+	}
+	account, err := view.account(feeAccount)
+	if err != nil {
+		return err
+	}
+	account.Balance -= result.FeeCharged
+
+	var innerResult Result
+	if err = s.simulateTransaction(view, inner, &innerResult, false); err != nil {
+		return err
+	}
+	result.InnerTransactionHash = innerResult.Hash
+	result.InnerTransactionCode = innerResult.TransactionCode
+	result.InnerTransactionReason = innerResult.TransactionReason
+	result.Operations = innerResult.Operations
+	if innerResult.Successful() {
+		result.TransactionCode = txFeeBumpInnerSuccess
+	} else {
+		result.TransactionCode = txFeeBumpInnerFailed
+		result.TransactionReason = "the inner transaction failed"
+	}
+	return nil
+}
+
+// checkFeeBump checks the fee, fee account and signatures of a fee bump
+// transaction.
+func (s Simulator) checkFeeBump(
+	view *ledgerView,
+	feeBump *txnbuild.FeeBumpTransaction,
+	hash [32]byte,
+	feeAccount string,
+	minFee int64,
+) (*failure, error) {
+	if failed := checkFee(feeBump.MaxFee(), minFee); failed != nil {
+		return failed, nil
+	}
+
+	account, err := view.account(feeAccount)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return fail(xdr.TransactionResultCodeTxNoAccount, "the fee account %s does not exist", feeAccount), nil
+	}
+
+	signatures := newSignatureChecker(hash, feeBump.Signatures())
+	if ok, err := s.checkSignatures(view, signatures, feeAccount, thresholdLow); err != nil {
+		return nil, err
+	} else if !ok {
+		return fail(
+			xdr.TransactionResultCodeTxBadAuth,
+			"the signatures do not meet the low threshold of the fee account %s", feeAccount,
+		), nil
+	}
+	if !signatures.allUsed() {
+		return fail(xdr.TransactionResultCodeTxBadAuthExtra, "the fee bump transaction has unused signatures"), nil
+	}
+
+	return s.checkFeeBalance(account, minFee), nil
+}
+
+// simulateTransaction simulates a transaction, which pays its own fee unless
+// it is the inner transaction of a fee bump.
+func (s Simulator) simulateTransaction(view *ledgerView, tx *txnbuild.Transaction, result *Result, paysFee bool) error {
+	hash, err := tx.Hash(s.NetworkPassphrase)
+	if err != nil {
+		return errors.Wrap(err, "could not hash transaction")
+	}
+	result.Hash = hex.EncodeToString(hash[:])
+
+	if paysFee {
+		result.MinFee, err = s.minFee(view, tx)
+		if err != nil {
+			return err
+		}
+		result.FeeCharged = result.MinFee
+	}
+
+	envelope := tx.ToXDR()
+	signatures := newSignatureChecker(hash, envelope.Signatures())
+	failed, err := s.checkTransaction(view, envelope, signatures, result.MinFee, paysFee)
+	if err != nil {
+		return err
+	}
+	if failed != nil {
+		result.TransactionCode, result.TransactionReason = failed.code, failed.reason
+		return nil
+	}
+
+	source, err := view.account(envelope.SourceAccount().ToAccountId().Address())
+	if err != nil {
+		return err
+	}
+	source.SequenceNumber = envelope.SeqNum()
+	if paysFee {
+		source.Balance -= result.FeeCharged
+	}
+
+	result.TransactionCode = txSuccess
+	for i, op := range envelope.Operations() {
+		opResult, opErr := s.simulateOperation(view, signatures, source.AccountID, op)
+		if opErr != nil {
+			return errors.Wrapf(opErr, "could not simulate operation %d", i)
+		}
+		if opResult.Code != codes.OpSuccess {
+			result.TransactionCode = txFailed
+			result.TransactionReason = "one of the operations failed"
+		}
+		result.Operations = append(result.Operations, opResult)
+	}
+
+	if result.TransactionCode == txSuccess && !signatures.allUsed() {
+		failed = fail(xdr.TransactionResultCodeTxBadAuthExtra, "the transaction has unused signatures")
+		result.TransactionCode, result.TransactionReason = failed.code, failed.reason
+	}
+	return nil
+}
+
+// checkTransaction checks the validity of a transaction before its operations
+// are applied, in the order stellar-core does.
+func (s Simulator) checkTransaction(
+	view *ledgerView,
+	envelope xdr.TransactionEnvelope,
+	signatures *signatureChecker,
+	minFee int64,
+	paysFee bool,
+) (*failure, error) {
+	closedAt := s.Ledger.ClosedAt.UTC()
+	if timeBounds := envelope.TimeBounds(); timeBounds != nil {
+		if timeBounds.MinTime > 0 && closedAt.Unix() < int64(timeBounds.MinTime) {
+			return fail(
+				xdr.TransactionResultCodeTxTooEarly,
+				"the transaction is valid from %s, the last ledger closed at %s",
+				time.Unix(int64(timeBounds.MinTime), 0).UTC().Format(time.RFC3339),
+				closedAt.Format(time.RFC3339),
+			), nil
+		}
+		if timeBounds.MaxTime > 0 && closedAt.Unix() > int64(timeBounds.MaxTime) {
+			return fail(
+				xdr.TransactionResultCodeTxTooLate,
+				"the transaction is valid until %s, the last ledger closed at %s",
+				time.Unix(int64(timeBounds.MaxTime), 0).UTC().Format(time.RFC3339),
+				closedAt.Format(time.RFC3339),
+			), nil
+		}
+	}
+
+	if len(envelope.Operations()) == 0 {
+		return fail(xdr.TransactionResultCodeTxMissingOperation, "the transaction has no operations"), nil
+	}
+
+	if paysFee {
+		if failed := checkFee(int64(envelope.Fee()), minFee); failed != nil {
+			return failed, nil
+		}
+	}
+
+	sourceID := envelope.SourceAccount().ToAccountId().Address()
+	source, err := view.account(sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return fail(xdr.TransactionResultCodeTxNoAccount, "the source account %s does not exist", sourceID), nil
+	}
+	if envelope.SeqNum() != source.SequenceNumber+1 {
+		return fail(
+			xdr.TransactionResultCodeTxBadSeq,
+			"the sequence number is %d, the next sequence number of %s is %d",
+			envelope.SeqNum(), sourceID, source.SequenceNumber+1,
+		), nil
+	}
+
+	if ok, err := s.checkSignatures(view, signatures, sourceID, thresholdLow); err != nil {
+		return nil, err
+	} else if !ok {
+		return fail(
+			xdr.TransactionResultCodeTxBadAuth,
+			"the signatures do not meet the low threshold of the source account %s", sourceID,
+		), nil
+	}
+
+	if paysFee {
+		return s.checkFeeBalance(source, minFee), nil
+	}
+	return nil, nil
+}
+
+func checkFee(fee, minFee int64) *failure {
+	if fee < minFee {
+		return fail(
+			xdr.TransactionResultCodeTxInsufficientFee,
+			"the fee is %d stroops, the minimum fee is %d stroops",
+			fee, minFee,
+		)
+	}
+	return nil
+}
+
+func (s Simulator) checkFeeBalance(account *history.AccountEntry, fee int64) *failure {
+	if available := availableNative(account, s.Ledger.BaseReserve); available < fee {
+		return fail(
+			xdr.TransactionResultCodeTxInsufficientBalance,
+			"the available balance of %s is %d stroops, the fee is %d stroops",
+			account.AccountID, available, fee,
+		)
+	}
+	return nil
+}
+
+// minFee returns the minimum fee of a transaction which is not a fee bump.
+func (s Simulator) minFee(view *ledgerView, tx *txnbuild.Transaction) (int64, error) {
+	estimator := txnbuild.FeeEstimator{
+		BaseFee:              int64(s.Ledger.BaseFee),
+		BasePercentageFee:    int64(s.Ledger.BasePercentageFee),
+		AccountMergeBalances: map[string]string{},
+	}
+	ops := tx.Operations()
+	if len(ops) == 0 {
+		return 0, nil
+	}
+
+	// the source account of the transaction may be muxed, the accounts are
+	// looked up by their G-address.
+	envelope := tx.ToXDR()
+	source := envelope.SourceAccount().ToAccountId()
+	for _, op := range envelope.Operations() {
+		if op.Body.Type != xdr.OperationTypeAccountMerge {
+			continue
+		}
+		merged := source.Address()
+		if op.SourceAccount != nil {
+			merged = op.SourceAccount.ToAccountId().Address()
+		}
+		account, err := view.account(merged)
+		if err != nil {
+			return 0, err
+		}
+		balance := int64(0)
+		if account != nil {
+			balance = account.Balance
+		}
+		estimator.AccountMergeBalances[merged] = amount.StringFromInt64(balance)
+	}
+
+	minFee, err := estimator.MinFee(source.Address(), ops)
+	if err != nil {
+		return 0, errors.Wrap(ErrInvalidTransaction, err.Error())
+	}
+	return minFee, nil
+}
+
+func accountID(address string) (string, error) {
+	muxed, err := xdr.AddressToMuxedAccount(address)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid account %s", address)
+	}
+	id := muxed.ToAccountId()
+	return id.Address(), nil
+}
@@ -0,0 +1,510 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/services/horizon/internal/codes"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+type mockState struct {
+	accounts          []history.AccountEntry
+	signers           []history.AccountSigner
+	trustLines        []history.TrustLine
+	offers            []history.Offer
+	claimableBalances []history.ClaimableBalance
+	liquidityPools    []history.LiquidityPool
+}
+
+func (m *mockState) GetAccountsByIDs(ctx context.Context, ids []string) ([]history.AccountEntry, error) {
+	var rows []history.AccountEntry
+	for _, account := range m.accounts {
+		if contains(ids, account.AccountID) {
+			rows = append(rows, account)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockState) SignersForAccounts(ctx context.Context, accounts []string) ([]history.AccountSigner, error) {
+	var rows []history.AccountSigner
+	for _, signer := range m.signers {
+		if contains(accounts, signer.Account) {
+			rows = append(rows, signer)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockState) GetTrustLinesByKeys(ctx context.Context, ledgerKeys []string) ([]history.TrustLine, error) {
+	var rows []history.TrustLine
+	for _, trustLine := range m.trustLines {
+		if contains(ledgerKeys, trustLine.LedgerKey) {
+			rows = append(rows, trustLine)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockState) GetOffersByIDs(ctx context.Context, ids []int64) ([]history.Offer, error) {
+	var rows []history.Offer
+	for _, offer := range m.offers {
+		for _, id := range ids {
+			if offer.OfferID == id {
+				rows = append(rows, offer)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockState) GetClaimableBalancesByID(ctx context.Context, ids []string) ([]history.ClaimableBalance, error) {
+	var rows []history.ClaimableBalance
+	for _, balance := range m.claimableBalances {
+		if contains(ids, balance.BalanceID) {
+			rows = append(rows, balance)
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockState) GetLiquidityPoolsByID(ctx context.Context, poolIDs []string) ([]history.LiquidityPool, error) {
+	var rows []history.LiquidityPool
+	for _, pool := range m.liquidityPools {
+		if contains(poolIDs, pool.PoolID) {
+			rows = append(rows, pool)
+		}
+	}
+	return rows, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// addAccount adds an account whose master key is its only signer.
+func (m *mockState) addAccount(kp keypair.KP, balance, sequence int64) {
+	m.accounts = append(m.accounts, history.AccountEntry{
+		AccountID:      kp.Address(),
+		Balance:        balance,
+		SequenceNumber: sequence,
+		MasterWeight:   1,
+	})
+	m.signers = append(m.signers, history.AccountSigner{Account: kp.Address(), Signer: kp.Address(), Weight: 1})
+}
+
+func (m *mockState) addTrustLine(t *testing.T, kp keypair.KP, asset txnbuild.Asset, balance, limit int64, flags uint32) {
+	xdrAsset, err := asset.ToXDR()
+	require.NoError(t, err)
+	key, err := trustLineKey(kp.Address(), xdrAsset.ToTrustLineAsset())
+	require.NoError(t, err)
+	m.trustLines = append(m.trustLines, history.TrustLine{
+		AccountID: kp.Address(),
+		AssetType: xdrAsset.Type,
+		LedgerKey: key,
+		Balance:   balance,
+		Limit:     limit,
+		Flags:     flags,
+	})
+}
+
+var closedAt = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newSimulator(state *mockState) Simulator {
+	return Simulator{
+		State:             state,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		Ledger: history.Ledger{
+			Sequence:          10,
+			BaseFee:           100,
+			BasePercentageFee: 45,
+			BaseReserve:       5000000,
+			ClosedAt:          closedAt,
+		},
+	}
+}
+
+func buildTx(t *testing.T, source *keypair.Full, sequence, baseFee int64, ops ...txnbuild.Operation) *txnbuild.Transaction {
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: source.Address(), Sequence: sequence - 1},
+		Operations:           ops,
+		IncrementSequenceNum: true,
+		BaseFee:              baseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	return tx
+}
+
+func simulate(t *testing.T, simulator Simulator, tx interface{ Base64() (string, error) }) Result {
+	raw, err := tx.Base64()
+	require.NoError(t, err)
+	result, err := simulator.Simulate(context.Background(), raw)
+	require.NoError(t, err)
+	return result
+}
+
+func sign(t *testing.T, tx *txnbuild.Transaction, signers ...*keypair.Full) *txnbuild.Transaction {
+	tx, err := tx.Sign(network.TestNetworkPassphrase, signers...)
+	require.NoError(t, err)
+	return tx
+}
+
+func payment(destination keypair.KP, amount string, asset txnbuild.Asset) *txnbuild.Payment {
+	return &txnbuild.Payment{Destination: destination.Address(), Amount: amount, Asset: asset}
+}
+
+// paymentFee is the minimum fee of a transaction with a single payment of 10
+// native units: a base fee of 100 stroops and a percentage fee of 0.45%.
+const paymentFee = 100 + 450000
+
+func TestSimulatePayment(t *testing.T) {
+	source, destination := keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.addAccount(destination, 10*10000000, 1)
+	simulator := newSimulator(state)
+
+	tx := sign(t, buildTx(t, source, 6, paymentFee, payment(destination, "10", txnbuild.NativeAsset{})), source)
+	result := simulate(t, simulator, tx)
+	hash, err := tx.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, hash, result.Hash)
+	assert.True(t, result.Successful())
+	assert.Equal(t, "tx_success", result.TransactionCode)
+	assert.Equal(t, int64(paymentFee), result.MinFee)
+	assert.Equal(t, []OperationResult{{Code: codes.OpSuccess}}, result.Operations)
+}
+
+func TestSimulateMuxedSourceAccountMerge(t *testing.T) {
+	source, destination := keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 100*10000000, 5)
+	state.addAccount(destination, 10*10000000, 1)
+	simulator := newSimulator(state)
+
+	muxed, err := xdr.MuxedAccountFromAccountId(source.Address(), 7)
+	require.NoError(t, err)
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: muxed.Address(), Sequence: 5},
+		Operations:           []txnbuild.Operation{&txnbuild.AccountMerge{Destination: destination.Address()}},
+		IncrementSequenceNum: true,
+		BaseFee:              5000000,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	result := simulate(t, simulator, sign(t, tx, source))
+	assert.True(t, result.Successful(), result.TransactionCode)
+	// the percentage fee of the merge is computed from the balance of the
+	// account of the muxed source: 0.45% of 100 native units.
+	assert.Equal(t, int64(100+4500000), result.MinFee)
+}
+
+func TestSimulateTransactionFailures(t *testing.T) {
+	source, destination := keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.addAccount(destination, 10*10000000, 1)
+	simulator := newSimulator(state)
+	pay := payment(destination, "10", txnbuild.NativeAsset{})
+
+	for _, testCase := range []struct {
+		name string
+		tx   *txnbuild.Transaction
+		code string
+	}{
+		{"insufficient fee", sign(t, buildTx(t, source, 6, txnbuild.MinBaseFee, pay), source), "tx_insufficient_fee"},
+		{"bad sequence", sign(t, buildTx(t, source, 7, paymentFee, pay), source), "tx_bad_seq"},
+		{"missing signature", buildTx(t, source, 6, paymentFee, pay), "tx_bad_auth"},
+		{"wrong signer", sign(t, buildTx(t, source, 6, paymentFee, pay), destination), "tx_bad_auth"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := simulate(t, simulator, testCase.tx)
+			assert.Equal(t, testCase.code, result.TransactionCode)
+			assert.NotEmpty(t, result.TransactionReason)
+			assert.Empty(t, result.Operations)
+		})
+	}
+
+	// unused signatures are reported once the operations were simulated
+	result := simulate(t, simulator, sign(t, buildTx(t, source, 6, paymentFee, pay), source, destination))
+	assert.Equal(t, "tx_bad_auth_extra", result.TransactionCode)
+	assert.Equal(t, []OperationResult{{Code: codes.OpSuccess}}, result.Operations)
+
+	unknown := keypair.MustRandom()
+	result = simulate(t, simulator, sign(t, buildTx(t, unknown, 1, paymentFee, pay), unknown))
+	assert.Equal(t, "tx_no_source_account", result.TransactionCode)
+
+	result = simulate(t, simulator, sign(t, buildTx(t, source, 6, paymentFee, payment(unknown, "10", txnbuild.NativeAsset{})), source))
+	assert.Equal(t, "tx_failed", result.TransactionCode)
+	assert.Equal(t, "op_no_destination", result.Operations[0].Code)
+}
+
+func TestSimulateTimeBounds(t *testing.T) {
+	source := keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	simulator := newSimulator(state)
+
+	for _, testCase := range []struct {
+		timebounds txnbuild.Timebounds
+		code       string
+	}{
+		{txnbuild.NewTimebounds(closedAt.Add(time.Hour).Unix(), 0), "tx_too_early"},
+		{txnbuild.NewTimebounds(0, closedAt.Add(-time.Hour).Unix()), "tx_too_late"},
+		{txnbuild.NewTimebounds(closedAt.Add(-time.Hour).Unix(), closedAt.Add(time.Hour).Unix()), "tx_success"},
+	} {
+		tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+			SourceAccount:        &txnbuild.SimpleAccount{AccountID: source.Address(), Sequence: 5},
+			Operations:           []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 100}},
+			IncrementSequenceNum: true,
+			BaseFee:              txnbuild.MinBaseFee,
+			Timebounds:           testCase.timebounds,
+		})
+		require.NoError(t, err)
+		result := simulate(t, simulator, sign(t, tx, source))
+		assert.Equal(t, testCase.code, result.TransactionCode)
+	}
+}
+
+func TestSimulateInsufficientBalance(t *testing.T) {
+	source, destination := keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	// the minimum balance of an account is 2 base reserves
+	state.addAccount(source, 10000000+paymentFee-1, 5)
+	state.addAccount(destination, 10*10000000, 1)
+	simulator := newSimulator(state)
+
+	tx := sign(t, buildTx(t, source, 6, paymentFee, payment(destination, "10", txnbuild.NativeAsset{})), source)
+	result := simulate(t, simulator, tx)
+	assert.Equal(t, "tx_insufficient_balance", result.TransactionCode)
+}
+
+func TestSimulateCreditPayments(t *testing.T) {
+	source, destination, issuer := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer.Address()}
+	eur := txnbuild.CreditAsset{Code: "EUR", Issuer: issuer.Address()}
+	gbp := txnbuild.CreditAsset{Code: "GBP", Issuer: issuer.Address()}
+	authorized := uint32(xdr.TrustLineFlagsAuthorizedFlag)
+
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.addAccount(destination, 10*10000000, 1)
+	state.addAccount(issuer, 10*10000000, 1)
+	state.addTrustLine(t, source, usd, 200*10000000, 1000*10000000, authorized)
+	state.addTrustLine(t, source, eur, 100*10000000, 1000*10000000, 0)
+	state.addTrustLine(t, destination, usd, 0, 150*10000000, authorized)
+	state.addTrustLine(t, source, gbp, 100*10000000, 1000*10000000, authorized)
+	state.addTrustLine(t, destination, gbp, 0, 150*10000000, 0)
+	simulator := newSimulator(state)
+
+	// the last payment is sent by the issuer
+	tx := buildTx(t, source, 6, txnbuild.MinBaseFee*7,
+		payment(destination, "100", usd),
+		payment(destination, "60", usd),
+		payment(destination, "1", eur),
+		payment(destination, "1", gbp),
+		payment(destination, "1", txnbuild.CreditAsset{Code: "JPY", Issuer: issuer.Address()}),
+		payment(destination, "1000", usd),
+		&txnbuild.Payment{Destination: source.Address(), Amount: "500", Asset: usd, SourceAccount: issuer.Address()},
+	)
+	result := simulate(t, simulator, sign(t, tx, source, issuer))
+	assert.Equal(t, "tx_failed", result.TransactionCode)
+
+	var resultCodes []string
+	for _, op := range result.Operations {
+		resultCodes = append(resultCodes, op.Code)
+	}
+	assert.Equal(t, []string{
+		"op_success",
+		// the destination received 100 USD of its 150 USD limit
+		"op_line_full",
+		"op_src_not_authorized",
+		"op_not_authorized",
+		"op_src_no_trust",
+		// the source sent 100 of its 200 USD
+		"op_underfunded",
+		// the issuer of an asset has an unlimited balance
+		"op_success",
+	}, resultCodes)
+	assert.Equal(t, "the account "+destination.Address()+" can receive 50.0000000 "+usd.Code+":"+issuer.Address()+
+		", the amount received is 60.0000000", result.Operations[1].Reason)
+}
+
+func TestSimulateOperationsDependingOnEachOther(t *testing.T) {
+	source, destination, issuer := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	usd := txnbuild.CreditAsset{Code: "USD", Issuer: issuer.Address()}
+
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.addAccount(issuer, 10*10000000, 1)
+	simulator := newSimulator(state)
+
+	createAccount := &txnbuild.CreateAccount{Destination: destination.Address(), Amount: "10"}
+	changeTrust := &txnbuild.ChangeTrust{
+		Line:          txnbuild.ChangeTrustAssetWrapper{Asset: usd},
+		Limit:         "100",
+		SourceAccount: destination.Address(),
+	}
+	pay := &txnbuild.Payment{Destination: destination.Address(), Amount: "10", Asset: usd, SourceAccount: issuer.Address()}
+
+	// the percentage fee is charged on the starting balance
+	fee := int64(3*100 + 450000)
+	tx := sign(t, buildTx(t, source, 6, fee/3+1, createAccount, changeTrust, pay), source, destination, issuer)
+	result := simulate(t, simulator, tx)
+	assert.Equal(t, fee, result.MinFee)
+	assert.Equal(t, "tx_success", result.TransactionCode)
+	require.Len(t, result.Operations, 3)
+
+	tx = sign(t, buildTx(t, source, 6, fee/2+1, changeTrust, pay), source, destination, issuer)
+	result = simulate(t, simulator, tx)
+	assert.Equal(t, "tx_failed", result.TransactionCode)
+	assert.Equal(t, "op_no_source_account", result.Operations[0].Code)
+	assert.Equal(t, "op_no_destination", result.Operations[1].Code)
+}
+
+func TestSimulateOperationThresholds(t *testing.T) {
+	source, signer := keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.accounts[0].ThresholdMedium = 2
+	state.signers = append(state.signers, history.AccountSigner{Account: source.Address(), Signer: signer.Address(), Weight: 1})
+	simulator := newSimulator(state)
+
+	bump := &txnbuild.BumpSequence{BumpTo: 100}
+	setOptions := &txnbuild.SetOptions{HomeDomain: txnbuild.NewHomeDomain("example.com")}
+
+	result := simulate(t, simulator, sign(t, buildTx(t, source, 6, txnbuild.MinBaseFee*2, bump, setOptions), source))
+	assert.Equal(t, "tx_failed", result.TransactionCode)
+	assert.Equal(t, []OperationResult{
+		{Code: codes.OpSuccess},
+		{
+			Code:   "op_bad_auth",
+			Reason: "the signatures do not meet the medium threshold of the source account " + source.Address(),
+		},
+	}, result.Operations)
+
+	result = simulate(t, simulator, sign(t, buildTx(t, source, 6, txnbuild.MinBaseFee*2, bump, setOptions), source, signer))
+	assert.Equal(t, "tx_success", result.TransactionCode)
+}
+
+func TestSimulateFeeBump(t *testing.T) {
+	source, feeAccount, destination := keypair.MustRandom(), keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.addAccount(feeAccount, 1000*10000000, 1)
+	state.addAccount(destination, 10*10000000, 1)
+	simulator := newSimulator(state)
+
+	inner := sign(t, buildTx(t, source, 6, txnbuild.MinBaseFee, payment(destination, "10", txnbuild.NativeAsset{})), source)
+	feeBump, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: feeAccount.Address(),
+		BaseFee:    paymentFee,
+	})
+	require.NoError(t, err)
+	feeBump, err = feeBump.Sign(network.TestNetworkPassphrase, feeAccount)
+	require.NoError(t, err)
+
+	result := simulate(t, simulator, feeBump)
+	assert.Equal(t, "tx_fee_bump_inner_success", result.TransactionCode)
+	assert.Equal(t, "tx_success", result.InnerTransactionCode)
+	assert.Equal(t, int64(paymentFee+100), result.MinFee)
+	innerHash, err := inner.HashHex(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.Equal(t, innerHash, result.InnerTransactionHash)
+	assert.Len(t, result.Operations, 1)
+
+	unsigned, err := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: feeAccount.Address(),
+		BaseFee:    paymentFee,
+	})
+	require.NoError(t, err)
+	result = simulate(t, simulator, unsigned)
+	assert.Equal(t, "tx_bad_auth", result.TransactionCode)
+	assert.Empty(t, result.InnerTransactionCode)
+}
+
+func TestSimulateClaimableBalance(t *testing.T) {
+	source, claimant := keypair.MustRandom(), keypair.MustRandom()
+	state := &mockState{}
+	state.addAccount(source, 1000*10000000, 5)
+	state.addAccount(claimant, 10*10000000, 1)
+
+	balanceID := xdr.ClaimableBalanceId{Type: xdr.ClaimableBalanceIdTypeClaimableBalanceIdTypeV0, V0: &xdr.Hash{1}}
+	hexID, err := xdr.MarshalHex(balanceID)
+	require.NoError(t, err)
+	expired := xdr.Int64(closedAt.Add(-time.Hour).Unix())
+	state.claimableBalances = []history.ClaimableBalance{{
+		BalanceID: hexID,
+		Claimants: history.Claimants{
+			{
+				Destination: claimant.Address(),
+				Predicate:   xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional},
+			},
+			{
+				Destination: source.Address(),
+				Predicate:   xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime, AbsBefore: &expired},
+			},
+		},
+		Asset:  xdr.MustNewNativeAsset(),
+		Amount: 10000000,
+	}}
+	simulator := newSimulator(state)
+
+	claim := &txnbuild.ClaimClaimableBalance{BalanceID: hexID}
+	result := simulate(t, simulator, sign(t, buildTx(t, source, 6, txnbuild.MinBaseFee, claim), source))
+	assert.Equal(t, "op_cannot_claim", result.Operations[0].Code)
+
+	result = simulate(t, simulator, sign(t, buildTx(t, claimant, 2, txnbuild.MinBaseFee, claim, claim), claimant))
+	assert.Equal(t, codes.OpSuccess, result.Operations[0].Code)
+	assert.Equal(t, "op_does_not_exist", result.Operations[1].Code)
+}
+
+func TestSimulateInvalidEnvelope(t *testing.T) {
+	_, err := newSimulator(&mockState{}).Simulate(context.Background(), "AAAA")
+	assert.Equal(t, ErrInvalidTransaction, errors.Cause(err))
+}
+
+func TestResultCodes(t *testing.T) {
+	for _, c := range []transferCodes{
+		createAccountCodes,
+		paymentCodes,
+		pathPaymentStrictSendCodes,
+		pathPaymentStrictReceiveCodes,
+		createClaimableBalanceCodes,
+		claimClaimableBalanceCodes,
+		liquidityPoolDepositCodes,
+	} {
+		for _, code := range []interface{}{
+			c.underfunded, c.srcNoTrust, c.srcNotAuthorized, c.noDestination, c.noTrust, c.notAuthorized, c.lineFull,
+		} {
+			if code != nil {
+				assert.NotPanics(t, func() { mustCode(code) })
+			}
+		}
+	}
+	for _, c := range []offerCodes{manageSellOfferCodes, manageBuyOfferCodes} {
+		for _, code := range []interface{}{
+			c.sellNoTrust, c.sellNotAuthorized, c.buyNoTrust, c.buyNotAuthorized, c.underfunded, c.lineFull, c.lowReserve, c.notFound,
+		} {
+			assert.NotPanics(t, func() { mustCode(code) })
+		}
+	}
+}
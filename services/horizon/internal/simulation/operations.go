@@ -0,0 +1,802 @@
+package simulation
+
+import (
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/services/horizon/internal/codes"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// transferCodes are the result codes of an operation moving an asset from its
+// source account to a destination.
+type transferCodes struct {
+	underfunded      interface{}
+	srcNoTrust       interface{}
+	srcNotAuthorized interface{}
+	noDestination    interface{}
+	noTrust          interface{}
+	notAuthorized    interface{}
+	lineFull         interface{}
+}
+
+var (
+	createAccountCodes = transferCodes{
+		underfunded: xdr.CreateAccountResultCodeCreateAccountUnderfunded,
+	}
+	paymentCodes = transferCodes{
+		underfunded:      xdr.PaymentResultCodePaymentUnderfunded,
+		srcNoTrust:       xdr.PaymentResultCodePaymentSrcNoTrust,
+		srcNotAuthorized: xdr.PaymentResultCodePaymentSrcNotAuthorized,
+		noDestination:    xdr.PaymentResultCodePaymentNoDestination,
+		noTrust:          xdr.PaymentResultCodePaymentNoTrust,
+		notAuthorized:    xdr.PaymentResultCodePaymentNotAuthorized,
+		lineFull:         xdr.PaymentResultCodePaymentLineFull,
+	}
+	pathPaymentStrictSendCodes = transferCodes{
+		underfunded:      xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendUnderfunded,
+		srcNoTrust:       xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSrcNoTrust,
+		srcNotAuthorized: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSrcNotAuthorized,
+		noDestination:    xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoDestination,
+		noTrust:          xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNoTrust,
+		notAuthorized:    xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendNotAuthorized,
+		lineFull:         xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendLineFull,
+	}
+	pathPaymentStrictReceiveCodes = transferCodes{
+		underfunded:      xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveUnderfunded,
+		srcNoTrust:       xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSrcNoTrust,
+		srcNotAuthorized: xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSrcNotAuthorized,
+		noDestination:    xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoDestination,
+		noTrust:          xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNoTrust,
+		notAuthorized:    xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveNotAuthorized,
+		lineFull:         xdr.PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveLineFull,
+	}
+	createClaimableBalanceCodes = transferCodes{
+		underfunded:      xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceUnderfunded,
+		srcNoTrust:       xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceNoTrust,
+		srcNotAuthorized: xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceNotAuthorized,
+	}
+	claimClaimableBalanceCodes = transferCodes{
+		noTrust:       xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceNoTrust,
+		notAuthorized: xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceNotAuthorized,
+		lineFull:      xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceLineFull,
+	}
+	liquidityPoolDepositCodes = transferCodes{
+		underfunded:      xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositUnderfunded,
+		srcNoTrust:       xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNoTrust,
+		srcNotAuthorized: xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNotAuthorized,
+	}
+)
+
+// offerCodes are the result codes of an operation managing an offer.
+type offerCodes struct {
+	sellNoTrust       interface{}
+	sellNotAuthorized interface{}
+	buyNoTrust        interface{}
+	buyNotAuthorized  interface{}
+	underfunded       interface{}
+	lineFull          interface{}
+	lowReserve        interface{}
+	notFound          interface{}
+}
+
+var (
+	manageSellOfferCodes = offerCodes{
+		sellNoTrust:       xdr.ManageSellOfferResultCodeManageSellOfferSellNoTrust,
+		sellNotAuthorized: xdr.ManageSellOfferResultCodeManageSellOfferSellNotAuthorized,
+		buyNoTrust:        xdr.ManageSellOfferResultCodeManageSellOfferBuyNoTrust,
+		buyNotAuthorized:  xdr.ManageSellOfferResultCodeManageSellOfferBuyNotAuthorized,
+		underfunded:       xdr.ManageSellOfferResultCodeManageSellOfferUnderfunded,
+		lineFull:          xdr.ManageSellOfferResultCodeManageSellOfferLineFull,
+		lowReserve:        xdr.ManageSellOfferResultCodeManageSellOfferLowReserve,
+		notFound:          xdr.ManageSellOfferResultCodeManageSellOfferNotFound,
+	}
+	manageBuyOfferCodes = offerCodes{
+		sellNoTrust:       xdr.ManageBuyOfferResultCodeManageBuyOfferSellNoTrust,
+		sellNotAuthorized: xdr.ManageBuyOfferResultCodeManageBuyOfferSellNotAuthorized,
+		buyNoTrust:        xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNoTrust,
+		buyNotAuthorized:  xdr.ManageBuyOfferResultCodeManageBuyOfferBuyNotAuthorized,
+		underfunded:       xdr.ManageBuyOfferResultCodeManageBuyOfferUnderfunded,
+		lineFull:          xdr.ManageBuyOfferResultCodeManageBuyOfferLineFull,
+		lowReserve:        xdr.ManageBuyOfferResultCodeManageBuyOfferLowReserve,
+		notFound:          xdr.ManageBuyOfferResultCodeManageBuyOfferNotFound,
+	}
+)
+
+// operationThreshold returns the threshold of the source account an
+// operation needs to meet, see stellar-core's OperationFrame::getThresholdLevel.
+func operationThreshold(op xdr.Operation) thresholdLevel {
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust,
+		xdr.OperationTypeSetTrustLineFlags,
+		xdr.OperationTypeBumpSequence,
+		xdr.OperationTypeClaimClaimableBalance,
+		xdr.OperationTypeInflation:
+		return thresholdLow
+	case xdr.OperationTypeAccountMerge:
+		return thresholdHigh
+	case xdr.OperationTypeSetOptions:
+		options := op.Body.MustSetOptionsOp()
+		if options.MasterWeight != nil ||
+			options.LowThreshold != nil ||
+			options.MedThreshold != nil ||
+			options.HighThreshold != nil ||
+			options.Signer != nil {
+			return thresholdHigh
+		}
+	}
+	return thresholdMedium
+}
+
+func (s Simulator) simulateOperation(
+	view *ledgerView,
+	signatures *signatureChecker,
+	txSource string,
+	op xdr.Operation,
+) (OperationResult, error) {
+	sourceID := txSource
+	if op.SourceAccount != nil {
+		sourceID = op.SourceAccount.ToAccountId().Address()
+	}
+	source, err := view.account(sourceID)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	if source == nil {
+		failed := fail(xdr.OperationResultCodeOpNoAccount, "the source account %s does not exist", sourceID)
+		return OperationResult{Code: failed.code, Reason: failed.reason}, nil
+	}
+
+	level := operationThreshold(op)
+	if ok, err := s.checkSignatures(view, signatures, sourceID, level); err != nil {
+		return OperationResult{}, err
+	} else if !ok {
+		failed := fail(
+			xdr.OperationResultCodeOpBadAuth,
+			"the signatures do not meet the %s threshold of the source account %s",
+			level, sourceID,
+		)
+		return OperationResult{Code: failed.code, Reason: failed.reason}, nil
+	}
+
+	failed, err := s.applyOperation(view, source, op)
+	if err != nil {
+		return OperationResult{}, err
+	}
+	if failed != nil {
+		return OperationResult{Code: failed.code, Reason: failed.reason}, nil
+	}
+	return OperationResult{Code: codes.OpSuccess}, nil
+}
+
+// applyOperation checks an operation and applies its effects to the view when
+// it does not fail.
+func (s Simulator) applyOperation(view *ledgerView, source *history.AccountEntry, op xdr.Operation) (*failure, error) {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		return s.createAccount(view, source, op.Body.MustCreateAccountOp())
+	case xdr.OperationTypePayment:
+		payment := op.Body.MustPaymentOp()
+		return s.transfer(
+			view, source, payment.Destination.ToAccountId().Address(),
+			payment.Asset, int64(payment.Amount),
+			payment.Asset, int64(payment.Amount),
+			paymentCodes,
+		)
+	case xdr.OperationTypePathPaymentStrictSend:
+		payment := op.Body.MustPathPaymentStrictSendOp()
+		// The amount received depends on the order book, only the minimum
+		// amount received is known.
+		return s.transfer(
+			view, source, payment.Destination.ToAccountId().Address(),
+			payment.SendAsset, int64(payment.SendAmount),
+			payment.DestAsset, int64(payment.DestMin),
+			pathPaymentStrictSendCodes,
+		)
+	case xdr.OperationTypePathPaymentStrictReceive:
+		payment := op.Body.MustPathPaymentStrictReceiveOp()
+		// The amount sent depends on the order book, so the balance of the
+		// source account is not checked.
+		return s.transfer(
+			view, source, payment.Destination.ToAccountId().Address(),
+			payment.SendAsset, -1,
+			payment.DestAsset, int64(payment.DestAmount),
+			pathPaymentStrictReceiveCodes,
+		)
+	case xdr.OperationTypeManageSellOffer:
+		offer := op.Body.MustManageSellOfferOp()
+		return s.manageOffer(view, source, offer.Selling, offer.Buying, int64(offer.Amount), int64(offer.OfferId), manageSellOfferCodes)
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		offer := op.Body.MustCreatePassiveSellOfferOp()
+		return s.manageOffer(view, source, offer.Selling, offer.Buying, int64(offer.Amount), 0, manageSellOfferCodes)
+	case xdr.OperationTypeManageBuyOffer:
+		offer := op.Body.MustManageBuyOfferOp()
+		return s.manageOffer(view, source, offer.Selling, offer.Buying, int64(offer.BuyAmount), int64(offer.OfferId), manageBuyOfferCodes)
+	case xdr.OperationTypeChangeTrust:
+		return s.changeTrust(view, source, op.Body.MustChangeTrustOp())
+	case xdr.OperationTypeAccountMerge:
+		destination := op.Body.MustDestination()
+		return s.mergeAccount(view, source, destination.ToAccountId().Address())
+	case xdr.OperationTypeBumpSequence:
+		bumpTo := int64(op.Body.MustBumpSequenceOp().BumpTo)
+		if bumpTo < 0 {
+			return fail(xdr.BumpSequenceResultCodeBumpSequenceBadSeq, "cannot bump the sequence number to %d", bumpTo), nil
+		}
+		if bumpTo > source.SequenceNumber {
+			source.SequenceNumber = bumpTo
+		}
+		return nil, nil
+	case xdr.OperationTypeCreateClaimableBalance:
+		return s.createClaimableBalance(view, source, op.Body.MustCreateClaimableBalanceOp())
+	case xdr.OperationTypeClaimClaimableBalance:
+		return s.claimClaimableBalance(view, source, op.Body.MustClaimClaimableBalanceOp())
+	case xdr.OperationTypeLiquidityPoolDeposit:
+		return s.depositLiquidity(view, source, op.Body.MustLiquidityPoolDepositOp())
+	case xdr.OperationTypeLiquidityPoolWithdraw:
+		return s.withdrawLiquidity(view, source, op.Body.MustLiquidityPoolWithdrawOp())
+	}
+	return nil, nil
+}
+
+func (s Simulator) createAccount(view *ledgerView, source *history.AccountEntry, op xdr.CreateAccountOp) (*failure, error) {
+	destinationID := op.Destination.Address()
+	destination, err := view.account(destinationID)
+	if err != nil {
+		return nil, err
+	}
+	if destination != nil {
+		return fail(xdr.CreateAccountResultCodeCreateAccountAlreadyExist, "the account %s already exists", destinationID), nil
+	}
+
+	startingBalance := int64(op.StartingBalance)
+	if reserve := 2 * int64(s.Ledger.BaseReserve); startingBalance < reserve {
+		return fail(
+			xdr.CreateAccountResultCodeCreateAccountLowReserve,
+			"the starting balance is %s, the minimum balance of an account is %s",
+			amount.StringFromInt64(startingBalance), amount.StringFromInt64(reserve),
+		), nil
+	}
+	if failed, err := s.checkDebit(view, source, xdr.MustNewNativeAsset(), startingBalance, createAccountCodes); err != nil || failed != nil {
+		return failed, err
+	}
+
+	source.Balance -= startingBalance
+	view.createAccount(destinationID, startingBalance, int64(s.Ledger.Sequence+1)<<32)
+	return nil, nil
+}
+
+// transfer moves sendAmount of sendAsset from the source account and
+// destAmount of destAsset to the destination account. A negative amount is
+// not checked nor moved.
+func (s Simulator) transfer(
+	view *ledgerView,
+	source *history.AccountEntry,
+	destinationID string,
+	sendAsset xdr.Asset,
+	sendAmount int64,
+	destAsset xdr.Asset,
+	destAmount int64,
+	c transferCodes,
+) (*failure, error) {
+	destination, err := view.account(destinationID)
+	if err != nil {
+		return nil, err
+	}
+	if destination == nil {
+		return fail(c.noDestination, "the destination account %s does not exist", destinationID), nil
+	}
+
+	if failed, err := s.checkDebit(view, source, sendAsset, sendAmount, c); err != nil || failed != nil {
+		return failed, err
+	}
+	if failed, err := s.checkCredit(view, destination, destAsset, destAmount, c); err != nil || failed != nil {
+		return failed, err
+	}
+
+	if sendAmount >= 0 {
+		if err := s.debit(view, source, sendAsset, sendAmount); err != nil {
+			return nil, err
+		}
+	}
+	if destAmount >= 0 {
+		if err := s.credit(view, destination, destAsset, destAmount); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// checkDebit checks that an account can send an amount of an asset. The
+// balance is not checked when the amount is negative.
+func (s Simulator) checkDebit(view *ledgerView, account *history.AccountEntry, asset xdr.Asset, amt int64, c transferCodes) (*failure, error) {
+	var available int64
+	switch {
+	case asset.Type == xdr.AssetTypeAssetTypeNative:
+		available = availableNative(account, s.Ledger.BaseReserve)
+	case asset.GetIssuer() == account.AccountID:
+		return nil, nil
+	default:
+		trustLine, err := view.trustLine(account.AccountID, asset.ToTrustLineAsset())
+		if err != nil {
+			return nil, err
+		}
+		if trustLine == nil {
+			return fail(c.srcNoTrust, "the account %s does not trust %s", account.AccountID, asset.StringCanonical()), nil
+		}
+		if !isAuthorized(trustLine) {
+			return fail(
+				c.srcNotAuthorized, "the account %s is not authorized to send %s",
+				account.AccountID, asset.StringCanonical(),
+			), nil
+		}
+		available = availableCredit(trustLine)
+	}
+
+	if amt >= 0 && available < amt {
+		return fail(
+			c.underfunded, "the available balance of %s is %s %s, the amount sent is %s",
+			account.AccountID, amount.StringFromInt64(available), asset.StringCanonical(), amount.StringFromInt64(amt),
+		), nil
+	}
+	return nil, nil
+}
+
+// checkCredit checks that an account can receive an amount of an asset. The
+// limit is not checked when the amount is negative.
+func (s Simulator) checkCredit(view *ledgerView, account *history.AccountEntry, asset xdr.Asset, amt int64, c transferCodes) (*failure, error) {
+	if asset.Type == xdr.AssetTypeAssetTypeNative || asset.GetIssuer() == account.AccountID {
+		return nil, nil
+	}
+
+	trustLine, err := view.trustLine(account.AccountID, asset.ToTrustLineAsset())
+	if err != nil {
+		return nil, err
+	}
+	if trustLine == nil {
+		return fail(c.noTrust, "the account %s does not trust %s", account.AccountID, asset.StringCanonical()), nil
+	}
+	if !isAuthorized(trustLine) {
+		return fail(
+			c.notAuthorized, "the account %s is not authorized to receive %s",
+			account.AccountID, asset.StringCanonical(),
+		), nil
+	}
+	if limit := availableLimit(trustLine); amt >= 0 && limit < amt {
+		return fail(
+			c.lineFull, "the account %s can receive %s %s, the amount received is %s",
+			account.AccountID, amount.StringFromInt64(limit), asset.StringCanonical(), amount.StringFromInt64(amt),
+		), nil
+	}
+	return nil, nil
+}
+
+func (s Simulator) debit(view *ledgerView, account *history.AccountEntry, asset xdr.Asset, amt int64) error {
+	switch {
+	case asset.Type == xdr.AssetTypeAssetTypeNative:
+		account.Balance -= amt
+	case asset.GetIssuer() != account.AccountID:
+		trustLine, err := view.trustLine(account.AccountID, asset.ToTrustLineAsset())
+		if err != nil {
+			return err
+		}
+		trustLine.Balance -= amt
+	}
+	return nil
+}
+
+func (s Simulator) credit(view *ledgerView, account *history.AccountEntry, asset xdr.Asset, amt int64) error {
+	switch {
+	case asset.Type == xdr.AssetTypeAssetTypeNative:
+		account.Balance += amt
+	case asset.GetIssuer() != account.AccountID:
+		trustLine, err := view.trustLine(account.AccountID, asset.ToTrustLineAsset())
+		if err != nil {
+			return err
+		}
+		trustLine.Balance += amt
+	}
+	return nil
+}
+
+// manageOffer checks an offer. Offers are not applied since their effects
+// depend on the order book.
+func (s Simulator) manageOffer(
+	view *ledgerView,
+	source *history.AccountEntry,
+	selling, buying xdr.Asset,
+	amt, offerID int64,
+	c offerCodes,
+) (*failure, error) {
+	if offerID != 0 {
+		offer, err := view.offer(offerID)
+		if err != nil {
+			return nil, err
+		}
+		if offer == nil || offer.SellerID != source.AccountID {
+			return fail(c.notFound, "the account %s has no offer %d", source.AccountID, offerID), nil
+		}
+		if amt == 0 {
+			return nil, nil
+		}
+	}
+
+	// The balances of the issuers of the assets are unlimited.
+	var sellingAvailable, buyingLimit int64
+	sellingLimited, buyingLimited := true, false
+	switch {
+	case selling.Type == xdr.AssetTypeAssetTypeNative:
+		sellingAvailable = availableNative(source, s.Ledger.BaseReserve)
+	case selling.GetIssuer() == source.AccountID:
+		sellingLimited = false
+	default:
+		trustLine, err := view.trustLine(source.AccountID, selling.ToTrustLineAsset())
+		if err != nil {
+			return nil, err
+		}
+		if trustLine == nil {
+			return fail(c.sellNoTrust, "the account %s does not trust %s", source.AccountID, selling.StringCanonical()), nil
+		}
+		if !isAuthorized(trustLine) {
+			return fail(
+				c.sellNotAuthorized, "the account %s is not authorized to sell %s",
+				source.AccountID, selling.StringCanonical(),
+			), nil
+		}
+		sellingAvailable = availableCredit(trustLine)
+	}
+
+	if buying.Type != xdr.AssetTypeAssetTypeNative && buying.GetIssuer() != source.AccountID {
+		trustLine, err := view.trustLine(source.AccountID, buying.ToTrustLineAsset())
+		if err != nil {
+			return nil, err
+		}
+		if trustLine == nil {
+			return fail(c.buyNoTrust, "the account %s does not trust %s", source.AccountID, buying.StringCanonical()), nil
+		}
+		if !isAuthorized(trustLine) {
+			return fail(
+				c.buyNotAuthorized, "the account %s is not authorized to buy %s",
+				source.AccountID, buying.StringCanonical(),
+			), nil
+		}
+		buyingLimit, buyingLimited = availableLimit(trustLine), true
+	}
+
+	// The liabilities of an updated offer are released before the offer is
+	// updated, so the balances are only checked for new offers.
+	if offerID != 0 {
+		return nil, nil
+	}
+	if sellingLimited && sellingAvailable <= 0 {
+		return fail(
+			c.underfunded, "the account %s has no available balance of %s to sell",
+			source.AccountID, selling.StringCanonical(),
+		), nil
+	}
+	if buyingLimited && buyingLimit <= 0 {
+		return fail(
+			c.lineFull, "the account %s cannot receive more %s",
+			source.AccountID, buying.StringCanonical(),
+		), nil
+	}
+	if availableNative(source, s.Ledger.BaseReserve) < int64(s.Ledger.BaseReserve) {
+		return fail(
+			c.lowReserve, "the account %s does not have the balance to reserve a new offer",
+			source.AccountID,
+		), nil
+	}
+	return nil, nil
+}
+
+func (s Simulator) changeTrust(view *ledgerView, source *history.AccountEntry, op xdr.ChangeTrustOp) (*failure, error) {
+	line := op.Line
+	asset := xdr.TrustLineAsset{Type: line.Type, AlphaNum4: line.AlphaNum4, AlphaNum12: line.AlphaNum12}
+	subEntries := uint32(1)
+	flags := uint32(xdr.TrustLineFlagsAuthorizedFlag)
+
+	if line.Type == xdr.AssetTypeAssetTypePoolShare {
+		params := line.LiquidityPool.MustConstantProduct()
+		poolID, err := xdr.NewPoolId(params.AssetA, params.AssetB, params.Fee)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not compute liquidity pool id")
+		}
+		asset = xdr.TrustLineAsset{Type: line.Type, LiquidityPoolId: &poolID}
+		subEntries = 2
+
+		if op.Limit > 0 {
+			for _, poolAsset := range []xdr.Asset{params.AssetA, params.AssetB} {
+				if poolAsset.Type == xdr.AssetTypeAssetTypeNative || poolAsset.GetIssuer() == source.AccountID {
+					continue
+				}
+				trustLine, err := view.trustLine(source.AccountID, poolAsset.ToTrustLineAsset())
+				if err != nil {
+					return nil, err
+				}
+				if trustLine == nil {
+					return fail(
+						xdr.ChangeTrustResultCodeChangeTrustTrustLineMissing,
+						"the account %s does not trust %s", source.AccountID, poolAsset.StringCanonical(),
+					), nil
+				}
+			}
+		}
+	} else {
+		lineAsset := asset.ToAsset()
+		issuerID := lineAsset.GetIssuer()
+		if issuerID == source.AccountID {
+			return fail(
+				xdr.ChangeTrustResultCodeChangeTrustSelfNotAllowed,
+				"the account %s is the issuer of the asset", source.AccountID,
+			), nil
+		}
+		issuer, err := view.account(issuerID)
+		if err != nil {
+			return nil, err
+		}
+		if issuer == nil && op.Limit > 0 {
+			return fail(xdr.ChangeTrustResultCodeChangeTrustNoIssuer, "the issuer %s does not exist", issuerID), nil
+		}
+		if issuer != nil && xdr.AccountFlags(issuer.Flags).IsAuthRequired() {
+			flags = 0
+		}
+	}
+
+	trustLine, err := view.trustLine(source.AccountID, asset)
+	if err != nil {
+		return nil, err
+	}
+	limit := int64(op.Limit)
+
+	if limit == 0 {
+		if trustLine == nil {
+			return fail(xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, "the account %s has no trust line to remove", source.AccountID), nil
+		}
+		if trustLine.Balance > 0 || trustLine.BuyingLiabilities > 0 {
+			return fail(
+				xdr.ChangeTrustResultCodeChangeTrustInvalidLimit,
+				"the trust line of %s has a balance of %s and cannot be removed",
+				source.AccountID, amount.StringFromInt64(trustLine.Balance),
+			), nil
+		}
+		view.removeTrustLine(trustLine)
+		source.NumSubEntries -= subEntries
+		return nil, nil
+	}
+
+	if trustLine != nil {
+		if minLimit := trustLine.Balance + trustLine.BuyingLiabilities; limit < minLimit {
+			return fail(
+				xdr.ChangeTrustResultCodeChangeTrustInvalidLimit,
+				"the limit is %s, the trust line of %s requires a limit of at least %s",
+				amount.StringFromInt64(limit), source.AccountID, amount.StringFromInt64(minLimit),
+			), nil
+		}
+		trustLine.Limit = limit
+		return nil, nil
+	}
+
+	if reserve := int64(subEntries) * int64(s.Ledger.BaseReserve); availableNative(source, s.Ledger.BaseReserve) < reserve {
+		return fail(
+			xdr.ChangeTrustResultCodeChangeTrustLowReserve,
+			"the account %s does not have the balance to reserve a new trust line",
+			source.AccountID,
+		), nil
+	}
+	if _, err := view.createTrustLine(source.AccountID, asset, limit, flags); err != nil {
+		return nil, err
+	}
+	source.NumSubEntries += subEntries
+	return nil, nil
+}
+
+func (s Simulator) mergeAccount(view *ledgerView, source *history.AccountEntry, destinationID string) (*failure, error) {
+	if destinationID == source.AccountID {
+		return fail(xdr.AccountMergeResultCodeAccountMergeMalformed, "the account %s cannot be merged into itself", destinationID), nil
+	}
+	destination, err := view.account(destinationID)
+	if err != nil {
+		return nil, err
+	}
+	if destination == nil {
+		return fail(xdr.AccountMergeResultCodeAccountMergeNoAccount, "the destination account %s does not exist", destinationID), nil
+	}
+	if xdr.AccountFlags(source.Flags).IsAuthImmutable() {
+		return fail(xdr.AccountMergeResultCodeAccountMergeImmutableSet, "the account %s has the immutable flag set", source.AccountID), nil
+	}
+
+	signers, err := view.signers(source.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	// Signers other than the master key are sub entries which are removed
+	// with the account.
+	extraSigners := uint32(len(signers))
+	if _, ok := signers[source.AccountID]; ok {
+		extraSigners--
+	}
+	if source.NumSubEntries > extraSigners {
+		return fail(
+			xdr.AccountMergeResultCodeAccountMergeHasSubEntries,
+			"the account %s has %d sub entries",
+			source.AccountID, source.NumSubEntries-extraSigners,
+		), nil
+	}
+	if source.SequenceNumber >= int64(s.Ledger.Sequence+1)<<32 {
+		return fail(
+			xdr.AccountMergeResultCodeAccountMergeSeqnumTooFar,
+			"the sequence number of %s is too far to merge the account", source.AccountID,
+		), nil
+	}
+	if source.NumSponsoring > 0 {
+		return fail(xdr.AccountMergeResultCodeAccountMergeIsSponsor, "the account %s is sponsoring entries", source.AccountID), nil
+	}
+
+	destination.Balance += source.Balance
+	view.removeAccount(source.AccountID)
+	return nil, nil
+}
+
+func (s Simulator) createClaimableBalance(view *ledgerView, source *history.AccountEntry, op xdr.CreateClaimableBalanceOp) (*failure, error) {
+	amt := int64(op.Amount)
+	if failed, err := s.checkDebit(view, source, op.Asset, amt, createClaimableBalanceCodes); err != nil || failed != nil {
+		return failed, err
+	}
+
+	reserve := int64(len(op.Claimants)) * int64(s.Ledger.BaseReserve)
+	available := availableNative(source, s.Ledger.BaseReserve)
+	if op.Asset.Type == xdr.AssetTypeAssetTypeNative {
+		available -= amt
+	}
+	if available < reserve {
+		return fail(
+			xdr.CreateClaimableBalanceResultCodeCreateClaimableBalanceLowReserve,
+			"the account %s does not have the balance to reserve the claimable balance",
+			source.AccountID,
+		), nil
+	}
+
+	if err := s.debit(view, source, op.Asset, amt); err != nil {
+		return nil, err
+	}
+	source.NumSponsoring += uint32(len(op.Claimants))
+	return nil, nil
+}
+
+func (s Simulator) claimClaimableBalance(view *ledgerView, source *history.AccountEntry, op xdr.ClaimClaimableBalanceOp) (*failure, error) {
+	balance, err := view.claimableBalance(op.BalanceId)
+	if err != nil {
+		return nil, err
+	}
+	if balance == nil {
+		return fail(
+			xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceDoesNotExist,
+			"the claimable balance does not exist",
+		), nil
+	}
+
+	claimable := false
+	for _, claimant := range balance.Claimants {
+		if claimant.Destination == source.AccountID && predicateSatisfied(claimant.Predicate, s.Ledger.ClosedAt.Unix()) {
+			claimable = true
+			break
+		}
+	}
+	if !claimable {
+		return fail(
+			xdr.ClaimClaimableBalanceResultCodeClaimClaimableBalanceCannotClaim,
+			"the account %s cannot claim the claimable balance", source.AccountID,
+		), nil
+	}
+
+	amt := int64(balance.Amount)
+	if failed, err := s.checkCredit(view, source, balance.Asset, amt, claimClaimableBalanceCodes); err != nil || failed != nil {
+		return failed, err
+	}
+	if err := s.credit(view, source, balance.Asset, amt); err != nil {
+		return nil, err
+	}
+	view.removeClaimableBalance(balance)
+	return nil, nil
+}
+
+// predicateSatisfied evaluates a claim predicate at the given close time. The
+// relative predicates of ledger entries were converted to absolute ones when
+// the claimable balances were created.
+func predicateSatisfied(predicate xdr.ClaimPredicate, closeTime int64) bool {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return true
+	case xdr.ClaimPredicateTypeClaimPredicateAnd:
+		for _, p := range predicate.MustAndPredicates() {
+			if !predicateSatisfied(p, closeTime) {
+				return false
+			}
+		}
+		return true
+	case xdr.ClaimPredicateTypeClaimPredicateOr:
+		for _, p := range predicate.MustOrPredicates() {
+			if predicateSatisfied(p, closeTime) {
+				return true
+			}
+		}
+		return false
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		not := predicate.MustNotPredicate()
+		return not != nil && !predicateSatisfied(*not, closeTime)
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		return closeTime < int64(predicate.MustAbsBefore())
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime:
+		return closeTime < int64(predicate.MustRelBefore())
+	}
+	return false
+}
+
+// depositLiquidity checks a deposit to a liquidity pool. Deposits are not
+// applied since the amounts deposited depend on the pool reserves.
+func (s Simulator) depositLiquidity(view *ledgerView, source *history.AccountEntry, op xdr.LiquidityPoolDepositOp) (*failure, error) {
+	poolID := op.LiquidityPoolId
+	trustLine, err := view.trustLine(source.AccountID, xdr.TrustLineAsset{
+		Type:            xdr.AssetTypeAssetTypePoolShare,
+		LiquidityPoolId: &poolID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if trustLine == nil {
+		return fail(
+			xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNoTrust,
+			"the account %s does not trust the liquidity pool", source.AccountID,
+		), nil
+	}
+	pool, err := view.liquidityPool(poolID)
+	if err != nil {
+		return nil, err
+	}
+	if pool == nil || len(pool.AssetReserves) != 2 {
+		return fail(
+			xdr.LiquidityPoolDepositResultCodeLiquidityPoolDepositNoTrust,
+			"the liquidity pool does not exist",
+		), nil
+	}
+
+	// The maximum amounts are deposited to an empty pool, otherwise the
+	// amounts depend on the pool price so only empty balances are reported.
+	maxAmounts := []int64{int64(op.MaxAmountA), int64(op.MaxAmountB)}
+	for i, reserve := range pool.AssetReserves {
+		required := int64(1)
+		if pool.ShareCount == 0 {
+			required = maxAmounts[i]
+		}
+		if failed, err := s.checkDebit(view, source, reserve.Asset, required, liquidityPoolDepositCodes); err != nil || failed != nil {
+			return failed, err
+		}
+	}
+	return nil, nil
+}
+
+// withdrawLiquidity checks a withdrawal from a liquidity pool. Withdrawals
+// are not applied since the amounts withdrawn depend on the pool reserves.
+func (s Simulator) withdrawLiquidity(view *ledgerView, source *history.AccountEntry, op xdr.LiquidityPoolWithdrawOp) (*failure, error) {
+	poolID := op.LiquidityPoolId
+	trustLine, err := view.trustLine(source.AccountID, xdr.TrustLineAsset{
+		Type:            xdr.AssetTypeAssetTypePoolShare,
+		LiquidityPoolId: &poolID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if trustLine == nil {
+		return fail(
+			xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawNoTrust,
+			"the account %s does not trust the liquidity pool", source.AccountID,
+		), nil
+	}
+	if amt := int64(op.Amount); trustLine.Balance < amt {
+		return fail(
+			xdr.LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawUnderfunded,
+			"the account %s has %s pool shares, the amount withdrawn is %s",
+			source.AccountID, amount.StringFromInt64(trustLine.Balance), amount.StringFromInt64(amt),
+		), nil
+	}
+	return nil, nil
+}
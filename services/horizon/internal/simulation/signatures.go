@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+type thresholdLevel int
+
+const (
+	thresholdLow thresholdLevel = iota
+	thresholdMedium
+	thresholdHigh
+)
+
+func (l thresholdLevel) String() string {
+	switch l {
+	case thresholdLow:
+		return "low"
+	case thresholdMedium:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+// signatureChecker matches the signatures of a transaction with the signers of
+// accounts and tracks which signatures were used, like stellar-core does.
+type signatureChecker struct {
+	hash       [32]byte
+	signatures []xdr.DecoratedSignature
+	used       []bool
+}
+
+func newSignatureChecker(hash [32]byte, signatures []xdr.DecoratedSignature) *signatureChecker {
+	return &signatureChecker{
+		hash:       hash,
+		signatures: signatures,
+		used:       make([]bool, len(signatures)),
+	}
+}
+
+// weight returns the total weight of the given signers which signed the
+// transaction. Signer weights are capped to 255 as in stellar-core.
+func (c *signatureChecker) weight(signers map[string]int32) (int32, error) {
+	var total int32
+	for signer, weight := range signers {
+		if weight > 255 {
+			weight = 255
+		}
+		signed, err := c.signedBy(signer)
+		if err != nil {
+			return 0, err
+		}
+		if signed {
+			total += weight
+		}
+	}
+	return total, nil
+}
+
+func (c *signatureChecker) signedBy(signer string) (bool, error) {
+	version, key, err := strkey.DecodeAny(signer)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid signer %s", signer)
+	}
+
+	switch version {
+	case strkey.VersionByteAccountID:
+		kp, err := keypair.ParseAddress(signer)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid signer %s", signer)
+		}
+		hint := xdr.SignatureHint(kp.Hint())
+		for i, signature := range c.signatures {
+			if signature.Hint != hint {
+				continue
+			}
+			if kp.Verify(c.hash[:], signature.Signature) == nil {
+				c.used[i] = true
+				return true, nil
+			}
+		}
+	case strkey.VersionByteHashTx:
+		return bytes.Equal(key, c.hash[:]), nil
+	case strkey.VersionByteHashX:
+		for i, signature := range c.signatures {
+			preimage := sha256.Sum256(signature.Signature)
+			if bytes.Equal(key, preimage[:]) {
+				c.used[i] = true
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (c *signatureChecker) allUsed() bool {
+	for _, used := range c.used {
+		if !used {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSignatures returns true if the signatures of the transaction meet the
+// given threshold of an account.
+func (s Simulator) checkSignatures(view *ledgerView, checker *signatureChecker, accountID string, level thresholdLevel) (bool, error) {
+	account, err := view.account(accountID)
+	if err != nil {
+		return false, err
+	}
+	signers, err := view.signers(accountID)
+	if err != nil {
+		return false, err
+	}
+
+	weight, err := checker.weight(signers)
+	if err != nil {
+		return false, err
+	}
+
+	var threshold byte
+	switch level {
+	case thresholdLow:
+		threshold = account.ThresholdLow
+	case thresholdMedium:
+		threshold = account.ThresholdMedium
+	default:
+		threshold = account.ThresholdHigh
+	}
+	return weight > 0 && weight >= int32(threshold), nil
+}
@@ -0,0 +1,340 @@
+package statement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/support/errors"
+)
+
+// Format is the format a statement is written in.
+type Format string
+
+// Formats of statements.
+const (
+	FormatCSV       Format = "csv"
+	FormatJSONLines Format = "jsonl"
+	FormatOFX       Format = "ofx"
+)
+
+// Formats are the formats statements can be written in.
+var Formats = []Format{FormatCSV, FormatJSONLines, FormatOFX}
+
+// ParseFormat returns the format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(s) {
+			return format, nil
+		}
+	}
+	return "", errors.Errorf("invalid statement format: %s", s)
+}
+
+// ContentType returns the MIME type of a format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONLines:
+		return "application/x-ndjson"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "text/csv"
+	}
+}
+
+// Write writes a statement to w in the given format.
+func Write(w io.Writer, format Format, s Statement) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, s)
+	case FormatJSONLines:
+		return writeJSONLines(w, s)
+	case FormatOFX:
+		return writeOFX(w, s)
+	default:
+		return errors.Errorf("invalid statement format: %s", format)
+	}
+}
+
+// Records of the CSV and JSON Lines statements.
+const (
+	recordStatement      = "statement"
+	recordOpeningBalance = "opening_balance"
+	recordClosingBalance = "closing_balance"
+)
+
+// record is a row of a CSV statement and a line of a JSON Lines statement.
+// The statement starts with the opening balances, then lists the entries and
+// ends with the closing balances.
+type record struct {
+	Record          string `json:"record"`
+	ID              string `json:"id,omitempty"`
+	Asset           string `json:"asset"`
+	Amount          string `json:"amount,omitempty"`
+	Ledger          uint32 `json:"ledger"`
+	ClosedAt        string `json:"closed_at,omitempty"`
+	TransactionHash string `json:"transaction_hash,omitempty"`
+	OperationID     string `json:"operation_id,omitempty"`
+	Effect          string `json:"effect,omitempty"`
+	BaseFee         string `json:"base_fee,omitempty"`
+	PercentageFee   string `json:"percentage_fee,omitempty"`
+	InclusionFee    string `json:"inclusion_fee,omitempty"`
+}
+
+var csvHeader = []string{
+	"record",
+	"id",
+	"asset",
+	"amount",
+	"ledger",
+	"closed_at",
+	"transaction_hash",
+	"operation_id",
+	"effect",
+	"base_fee",
+	"percentage_fee",
+	"inclusion_fee",
+}
+
+func (r record) csv() []string {
+	return []string{
+		r.Record,
+		r.ID,
+		r.Asset,
+		r.Amount,
+		strconv.FormatUint(uint64(r.Ledger), 10),
+		r.ClosedAt,
+		r.TransactionHash,
+		r.OperationID,
+		r.Effect,
+		r.BaseFee,
+		r.PercentageFee,
+		r.InclusionFee,
+	}
+}
+
+func records(s Statement) []record {
+	result := make([]record, 0, len(s.Entries)+2*len(s.Balances))
+	balanceRecord := func(name, asset string, value int64, ledger uint32) record {
+		return record{Record: name, Asset: asset, Amount: amount.StringFromInt64(value), Ledger: ledger}
+	}
+	for _, balance := range s.Balances {
+		result = append(result, balanceRecord(recordOpeningBalance, balance.Asset, balance.Opening, s.StartLedger-1))
+	}
+	for _, entry := range s.Entries {
+		r := record{
+			Record:          entry.Type,
+			ID:              entry.ID,
+			Asset:           entry.Asset,
+			Amount:          amount.StringFromInt64(entry.Amount),
+			Ledger:          entry.Ledger,
+			ClosedAt:        entry.ClosedAt.UTC().Format(time.RFC3339),
+			TransactionHash: entry.TransactionHash,
+			Effect:          entry.Effect,
+		}
+		if entry.OperationID != 0 {
+			r.OperationID = strconv.FormatInt(entry.OperationID, 10)
+		}
+		if entry.Type == EntryFee {
+			r.BaseFee = amount.StringFromInt64(entry.BaseFee)
+			r.PercentageFee = amount.StringFromInt64(entry.PercentageFee)
+			r.InclusionFee = amount.StringFromInt64(entry.InclusionFee)
+		}
+		result = append(result, r)
+	}
+	endLedger := s.EndLedger
+	if endLedger < s.StartLedger-1 {
+		endLedger = s.StartLedger - 1
+	}
+	for _, balance := range s.Balances {
+		result = append(result, balanceRecord(recordClosingBalance, balance.Asset, balance.Closing, endLedger))
+	}
+	return result
+}
+
+func writeCSV(w io.Writer, s Statement) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return errors.Wrap(err, "could not write csv header")
+	}
+	for _, r := range records(s) {
+		if err := writer.Write(r.csv()); err != nil {
+			return errors.Wrap(err, "could not write csv record")
+		}
+	}
+	writer.Flush()
+	return errors.Wrap(writer.Error(), "could not write csv")
+}
+
+// statementHeader is the first line of a JSON Lines statement.
+type statementHeader struct {
+	Record      string `json:"record"`
+	AccountID   string `json:"account_id"`
+	Asset       string `json:"asset,omitempty"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	StartLedger uint32 `json:"start_ledger"`
+	EndLedger   uint32 `json:"end_ledger"`
+}
+
+func writeJSONLines(w io.Writer, s Statement) error {
+	encoder := json.NewEncoder(w)
+	err := encoder.Encode(statementHeader{
+		Record:      recordStatement,
+		AccountID:   s.AccountID,
+		Asset:       s.Asset,
+		From:        s.From.UTC().Format(time.RFC3339),
+		To:          s.To.UTC().Format(time.RFC3339),
+		StartLedger: s.StartLedger,
+		EndLedger:   s.EndLedger,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not write statement header")
+	}
+	for _, r := range records(s) {
+		if err := encoder.Encode(r); err != nil {
+			return errors.Wrap(err, "could not write statement record")
+		}
+	}
+	return nil
+}
+
+// The OFX 2.2 aggregates of a statement. Each asset of the account is listed
+// as a bank account in the currency of the asset code.
+type ofxDocument struct {
+	XMLName xml.Name         `xml:"OFX"`
+	SignOn  ofxSignOn        `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    []ofxTransaction `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxTransaction struct {
+	TRNUID    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"STMTRS"`
+}
+
+type ofxAccount struct {
+	BankID   string `xml:"BANKID"`
+	AcctID   string `xml:"ACCTID"`
+	AcctType string `xml:"ACCTTYPE"`
+}
+
+type ofxStatement struct {
+	CurDef    string     `xml:"CURDEF"`
+	Account   ofxAccount `xml:"BANKACCTFROM"`
+	List      ofxList    `xml:"BANKTRANLIST"`
+	LedgerBal ofxBalance `xml:"LEDGERBAL"`
+}
+
+type ofxList struct {
+	DTStart      string     `xml:"DTSTART"`
+	DTEnd        string     `xml:"DTEND"`
+	Transactions []ofxEntry `xml:"STMTTRN"`
+}
+
+type ofxEntry struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FITID    string `xml:"FITID"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+const (
+	ofxTimeLayout = "20060102150405"
+	// ofxNativeCurrency is the ISO 4217 code for transactions where no
+	// currency is involved.
+	ofxNativeCurrency = "XXX"
+)
+
+const ofxHeader = xml.Header +
+	`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+func writeOFX(w io.Writer, s Statement) error {
+	success := ofxStatus{Code: 0, Severity: "INFO"}
+	document := ofxDocument{
+		SignOn: ofxSignOn{
+			Status:   success,
+			DTServer: time.Now().UTC().Format(ofxTimeLayout),
+			Language: "ENG",
+		},
+	}
+	for i, balance := range s.Balances {
+		currency := ofxNativeCurrency
+		if balance.Asset != NativeAsset {
+			currency = strings.SplitN(balance.Asset, ":", 2)[0]
+		}
+		statement := ofxStatement{
+			CurDef: currency,
+			Account: ofxAccount{
+				BankID:   balance.Asset,
+				AcctID:   s.AccountID,
+				AcctType: "CHECKING",
+			},
+			LedgerBal: ofxBalance{
+				BalAmt: amount.StringFromInt64(balance.Closing),
+				DTAsOf: s.To.UTC().Format(ofxTimeLayout),
+			},
+			List: ofxList{
+				DTStart: s.From.UTC().Format(ofxTimeLayout),
+				DTEnd:   s.To.UTC().Format(ofxTimeLayout),
+			},
+		}
+		for _, entry := range s.Entries {
+			if entry.Asset != balance.Asset {
+				continue
+			}
+			value := entry.Amount
+			if entry.Type != EntryCredit {
+				value = -value
+			}
+			statement.List.Transactions = append(statement.List.Transactions, ofxEntry{
+				TrnType:  strings.ToUpper(entry.Type),
+				DTPosted: entry.ClosedAt.UTC().Format(ofxTimeLayout),
+				TrnAmt:   amount.StringFromInt64(value),
+				FITID:    entry.ID,
+				Name:     entry.Effect,
+				Memo:     entry.TransactionHash,
+			})
+		}
+		document.Bank = append(document.Bank, ofxTransaction{
+			TRNUID:    strconv.Itoa(i + 1),
+			Status:    success,
+			Statement: statement,
+		})
+	}
+
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return errors.Wrap(err, "could not write ofx header")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return errors.Wrap(err, "could not write ofx statement")
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
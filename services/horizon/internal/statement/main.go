@@ -0,0 +1,714 @@
+// Package statement builds the statements of an account over a period of
+// time: the credits, debits and fees of the account in every asset along
+// with its opening and closing balances. Statements are built from the
+// effects and transactions of the account and its balance history, they
+// can be written as CSV, JSON Lines or OFX.
+package statement
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stellar/go/services/horizon/internal/db2"
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+// Types of the entries of a statement.
+const (
+	EntryCredit = "credit"
+	EntryDebit  = "debit"
+	EntryFee    = "fee"
+)
+
+// NativeAsset is the asset of native balances and fees.
+const NativeAsset = "native"
+
+// MaxEntries is the maximum number of entries of a statement, longer
+// statements must be split in shorter periods.
+const MaxEntries = 10000
+
+// ErrTooManyEntries is returned by Build when the statement would have more
+// than MaxEntries entries.
+var ErrTooManyEntries = errors.Errorf("statement has more than %d entries", MaxEntries)
+
+// ErrBeforeHistory is returned by Build when the statement starts before the
+// oldest ledger of the history or before the balances of the accounts were
+// first recorded in the balance history.
+var ErrBeforeHistory = errors.New("statement starts before the history")
+
+// Params are the parameters of a statement.
+type Params struct {
+	AccountID string
+	// Asset restricts the statement to one asset, `native` or `CODE:ISSUER`.
+	// The statement covers all the assets of the account when it is empty.
+	Asset string
+	// The statement covers the ledgers closed within [From, To).
+	From time.Time
+	To   time.Time
+}
+
+// Entry is a credit, a debit or a fee of a statement. Amounts are in stroops.
+type Entry struct {
+	// ID identifies the entry within the history of the account.
+	ID              string
+	Type            string
+	Ledger          uint32
+	ClosedAt        time.Time
+	TransactionHash string
+	// OperationID is the operation of the entry, 0 for fees.
+	OperationID int64
+	// Effect is the type of the effect the entry comes from, `fee` for fees.
+	Effect string
+	Asset  string
+	Amount int64
+	// BaseFee, PercentageFee and InclusionFee split the fees charged into
+	// the base fee of the operations, the percentage of the native amount
+	// transferred and the part of the fee bid above the minimum fee, which
+	// is charged when the network is surge pricing.
+	BaseFee       int64
+	PercentageFee int64
+	InclusionFee  int64
+
+	// order sorts the entries in the order they were applied.
+	order int64
+}
+
+// Balance sums up the entries of a statement in an asset.
+type Balance struct {
+	Asset   string
+	Opening int64
+	Closing int64
+	Credits int64
+	Debits  int64
+	Fees    int64
+}
+
+// Statement is the statement of an account.
+type Statement struct {
+	AccountID string
+	Asset     string
+	From      time.Time
+	To        time.Time
+	// The statement covers the ledgers within [StartLedger, EndLedger],
+	// EndLedger is lower than StartLedger when no ledger closed within the
+	// period.
+	StartLedger uint32
+	EndLedger   uint32
+	Entries     []Entry
+	Balances    []Balance
+}
+
+// pageSize is the number of effects or transactions read by query.
+const pageSize = 200
+
+// Build returns the statement described by params.
+func Build(ctx context.Context, q *history.Q, params Params) (Statement, error) {
+	opening, err := q.LedgerSequenceClosedBefore(ctx, params.From)
+	if err != nil {
+		return Statement{}, err
+	}
+	closing, err := q.LedgerSequenceClosedBefore(ctx, params.To)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	s := Statement{
+		AccountID:   params.AccountID,
+		Asset:       params.Asset,
+		From:        params.From,
+		To:          params.To,
+		StartLedger: opening + 1,
+		EndLedger:   closing,
+	}
+	if err = checkHistory(ctx, q, opening); err != nil {
+		return Statement{}, err
+	}
+	if closing > opening {
+		if s.Entries, err = loadEffectEntries(ctx, q, s); err != nil {
+			return Statement{}, err
+		}
+		if params.Asset == "" || params.Asset == NativeAsset {
+			fees, err := loadFeeEntries(ctx, q, s)
+			if err != nil {
+				return Statement{}, err
+			}
+			s.Entries = append(s.Entries, fees...)
+		}
+		if len(s.Entries) > MaxEntries {
+			return Statement{}, ErrTooManyEntries
+		}
+		sort.SliceStable(s.Entries, func(i, j int) bool {
+			return s.Entries[i].order < s.Entries[j].order
+		})
+	}
+
+	openingBalances, err := balancesAtLedger(ctx, q, params.AccountID, opening)
+	if err != nil {
+		return Statement{}, err
+	}
+	closingBalances, err := balancesAtLedger(ctx, q, params.AccountID, closing)
+	if err != nil {
+		return Statement{}, err
+	}
+	s.Balances = sumBalances(s, openingBalances, closingBalances)
+	return s, nil
+}
+
+// checkHistory returns ErrBeforeHistory when the history of the ledgers
+// following opening or the balances as of opening are not known.
+func checkHistory(ctx context.Context, q *history.Q, opening uint32) error {
+	var elder uint32
+	if err := q.ElderLedger(ctx, &elder); err != nil {
+		return errors.Wrap(err, "could not load the elder ledger")
+	}
+	if opening+1 < elder {
+		return ErrBeforeHistory
+	}
+	snapshot, err := q.GetAccountBalancesSnapshotLedger(ctx)
+	if err != nil {
+		return err
+	}
+	if snapshot == 0 || opening < snapshot {
+		return ErrBeforeHistory
+	}
+	return nil
+}
+
+// ledgerRange returns the range of the ids of the history of the statement.
+func (s Statement) ledgerRange() (int64, int64) {
+	return toid.New(int32(s.StartLedger), 0, 0).ToInt64(),
+		toid.New(int32(s.EndLedger)+1, 0, 0).ToInt64()
+}
+
+func loadEffectEntries(ctx context.Context, q *history.Q, s Statement) ([]Entry, error) {
+	start, end := s.ledgerRange()
+	var effects []history.Effect
+	cursor := strconv.FormatInt(start, 10) + "-0"
+	for {
+		var page []history.Effect
+		err := q.Effects().ForAccount(ctx, s.AccountID).Page(db2.PageQuery{
+			Cursor: cursor,
+			Order:  db2.OrderAscending,
+			Limit:  pageSize,
+		}).Select(ctx, &page)
+		if q.NoRows(err) {
+			// the account is not in the history
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load effects")
+		}
+
+		done := len(page) < pageSize
+		for _, effect := range page {
+			if effect.HistoryOperationID >= end {
+				done = true
+				break
+			}
+			effects = append(effects, effect)
+		}
+		if len(effects) > MaxEntries {
+			return nil, ErrTooManyEntries
+		}
+		if done {
+			break
+		}
+		cursor = page[len(page)-1].PagingToken()
+	}
+
+	transactions, err := effectTransactions(ctx, q, effects)
+	if err != nil {
+		return nil, err
+	}
+	pathPayments, err := tradePathPayments(ctx, q, effects)
+	if err != nil {
+		return nil, err
+	}
+	return entriesFromEffects(s, effects, transactions, pathPayments)
+}
+
+// entriesFromEffects returns the entries of the effects of the statement in
+// its asset, given the transactions of the effects and the path payments of
+// their trades by id.
+func entriesFromEffects(
+	s Statement,
+	effects []history.Effect,
+	transactions map[int64]history.Transaction,
+	pathPayments map[int64]history.Operation,
+) ([]Entry, error) {
+	var entries []Entry
+	for _, effect := range effects {
+		if isPathPaymentSourceTrade(effect, pathPayments) {
+			continue
+		}
+		transaction := transactions[transactionID(effect.HistoryOperationID)]
+		effectEntries, err := entriesFromEffect(effect, transaction)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range effectEntries {
+			if s.Asset == "" || entry.Asset == s.Asset {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// isPathPaymentSourceTrade returns true for the trades of the path payments
+// sent by the account of the effect. The path payment already debits the
+// asset sent from the account and credits the destination, the assets
+// traded along the path never reach the account.
+func isPathPaymentSourceTrade(effect history.Effect, pathPayments map[int64]history.Operation) bool {
+	if effect.Type != history.EffectTrade {
+		return false
+	}
+	operation, ok := pathPayments[effect.HistoryOperationID]
+	return ok && operation.SourceAccount == effect.Account
+}
+
+func transactionID(operationID int64) int64 {
+	id := toid.Parse(operationID)
+	return toid.New(id.LedgerSequence, id.TransactionOrder, 0).ToInt64()
+}
+
+// effectTransactions returns the transactions of effects by id.
+func effectTransactions(ctx context.Context, q *history.Q, effects []history.Effect) (map[int64]history.Transaction, error) {
+	transactions := map[int64]history.Transaction{}
+	var ids []int64
+	for _, effect := range effects {
+		id := transactionID(effect.HistoryOperationID)
+		if len(ids) == 0 || ids[len(ids)-1] != id {
+			ids = append(ids, id)
+		}
+	}
+	for start := 0; start < len(ids); start += pageSize {
+		end := start + pageSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		page, err := q.TransactionsByIDs(ctx, ids[start:end]...)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load transactions")
+		}
+		for id, transaction := range page {
+			transactions[id] = transaction
+		}
+	}
+	return transactions, nil
+}
+
+// tradePathPayments returns the path payments among the operations of the
+// trade effects, by id.
+func tradePathPayments(ctx context.Context, q *history.Q, effects []history.Effect) (map[int64]history.Operation, error) {
+	pathPayments := map[int64]history.Operation{}
+	var ids []int64
+	for _, effect := range effects {
+		if effect.Type != history.EffectTrade {
+			continue
+		}
+		if len(ids) == 0 || ids[len(ids)-1] != effect.HistoryOperationID {
+			ids = append(ids, effect.HistoryOperationID)
+		}
+	}
+	for start := 0; start < len(ids); start += pageSize {
+		end := start + pageSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		page, err := q.OperationsByIDs(ctx, ids[start:end]...)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load operations")
+		}
+		for id, operation := range page {
+			switch operation.Type {
+			case xdr.OperationTypePathPaymentStrictReceive, xdr.OperationTypePathPaymentStrictSend:
+				pathPayments[id] = operation
+			}
+		}
+	}
+	return pathPayments, nil
+}
+
+type liquidityPoolDetails struct {
+	ReservesDeposited []base.AssetAmount `json:"reserves_deposited"`
+	ReservesReceived  []base.AssetAmount `json:"reserves_received"`
+}
+
+type effectAmountDetails struct {
+	Amount          string `json:"amount"`
+	StartingBalance string `json:"starting_balance"`
+	AssetType       string `json:"asset_type"`
+	AssetCode       string `json:"asset_code"`
+	AssetIssuer     string `json:"asset_issuer"`
+}
+
+// entriesFromEffect returns the entries of the effects which credit or debit
+// an account.
+func entriesFromEffect(effect history.Effect, transaction history.Transaction) ([]Entry, error) {
+	base := Entry{
+		ID:              effect.PagingToken(),
+		Ledger:          uint32(toid.Parse(effect.HistoryOperationID).LedgerSequence),
+		ClosedAt:        transaction.LedgerCloseTime,
+		TransactionHash: transaction.TransactionHash,
+		OperationID:     effect.HistoryOperationID,
+		Effect:          effectTypeName(effect.Type),
+		order:           effect.HistoryOperationID,
+	}
+
+	var entries []Entry
+	add := func(entryType, asset, value string) error {
+		parsed, err := amount.ParseInt64(value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid amount in effect %s", effect.PagingToken())
+		}
+		entry := base
+		entry.Type = entryType
+		entry.Asset = asset
+		entry.Amount = parsed
+		if len(entries) > 0 {
+			entry.ID += "-" + strconv.Itoa(len(entries))
+		}
+		entries = append(entries, entry)
+		return nil
+	}
+
+	var err error
+	switch effect.Type {
+	case history.EffectAccountCreated:
+		var details effectAmountDetails
+		if err = effect.UnmarshalDetails(&details); err == nil {
+			err = add(EntryCredit, NativeAsset, details.StartingBalance)
+		}
+	case history.EffectAccountCredited, history.EffectAccountDebited:
+		var details effectAmountDetails
+		if err = effect.UnmarshalDetails(&details); err == nil {
+			entryType := EntryCredit
+			if effect.Type == history.EffectAccountDebited {
+				entryType = EntryDebit
+			}
+			err = add(entryType, assetString(details.AssetType, details.AssetCode, details.AssetIssuer), details.Amount)
+		}
+	case history.EffectTrade:
+		var details history.TradeEffectDetails
+		if err = effect.UnmarshalDetails(&details); err == nil {
+			err = add(EntryDebit, assetString(details.SoldAssetType, details.SoldAssetCode, details.SoldAssetIssuer), details.SoldAmount)
+		}
+		if err == nil {
+			err = add(EntryCredit, assetString(details.BoughtAssetType, details.BoughtAssetCode, details.BoughtAssetIssuer), details.BoughtAmount)
+		}
+	case history.EffectLiquidityPoolDeposited:
+		// the pool shares received are not part of the balance history
+		var details liquidityPoolDetails
+		if err = effect.UnmarshalDetails(&details); err == nil {
+			for _, reserve := range details.ReservesDeposited {
+				if err = add(EntryDebit, reserve.Asset, reserve.Amount); err != nil {
+					break
+				}
+			}
+		}
+	case history.EffectLiquidityPoolWithdrew:
+		var details liquidityPoolDetails
+		if err = effect.UnmarshalDetails(&details); err == nil {
+			for _, reserve := range details.ReservesReceived {
+				if err = add(EntryCredit, reserve.Asset, reserve.Amount); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read effect %s", effect.PagingToken())
+	}
+	return entries, nil
+}
+
+func effectTypeName(effectType history.EffectType) string {
+	switch effectType {
+	case history.EffectAccountCreated:
+		return "account_created"
+	case history.EffectAccountCredited:
+		return "account_credited"
+	case history.EffectAccountDebited:
+		return "account_debited"
+	case history.EffectTrade:
+		return "trade"
+	case history.EffectLiquidityPoolDeposited:
+		return "liquidity_pool_deposited"
+	case history.EffectLiquidityPoolWithdrew:
+		return "liquidity_pool_withdrew"
+	default:
+		return strconv.Itoa(int(effectType))
+	}
+}
+
+func assetString(assetType, code, issuer string) string {
+	if assetType == xdr.AssetTypeToString[xdr.AssetTypeAssetTypeNative] {
+		return NativeAsset
+	}
+	return code + ":" + issuer
+}
+
+// loadFeeEntries returns the fees paid by the account, including the fees of
+// failed transactions and of the fee bump transactions it paid for.
+func loadFeeEntries(ctx context.Context, q *history.Q, s Statement) ([]Entry, error) {
+	start, end := s.ledgerRange()
+	var transactions []history.Transaction
+	cursor := strconv.FormatInt(start, 10)
+	for {
+		var page []history.Transaction
+		err := q.Transactions().ForAccount(ctx, s.AccountID).IncludeFailed().Page(db2.PageQuery{
+			Cursor: cursor,
+			Order:  db2.OrderAscending,
+			Limit:  pageSize,
+		}).Select(ctx, &page)
+		if q.NoRows(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load transactions")
+		}
+
+		done := len(page) < pageSize
+		for _, transaction := range page {
+			if transaction.ID >= end {
+				done = true
+				break
+			}
+			feeAccount := transaction.Account
+			if transaction.FeeAccount.Valid {
+				feeAccount = transaction.FeeAccount.String
+			}
+			if feeAccount == s.AccountID {
+				transactions = append(transactions, transaction)
+			}
+		}
+		if len(transactions) > MaxEntries {
+			return nil, ErrTooManyEntries
+		}
+		if done {
+			break
+		}
+		cursor = page[len(page)-1].PagingToken()
+	}
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	ledgers, err := transactionLedgers(ctx, q, transactions)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(transactions))
+	for _, transaction := range transactions {
+		ledger := ledgers[transaction.LedgerSequence]
+		percentageFee, err := transactionPercentageFee(ctx, q, transaction, ledger.BasePercentageFee)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, feeEntry(transaction, int64(ledger.BaseFee), percentageFee))
+	}
+	return entries, nil
+}
+
+func transactionLedgers(ctx context.Context, q *history.Q, transactions []history.Transaction) (map[int32]history.Ledger, error) {
+	var sequences []int32
+	for _, transaction := range transactions {
+		if len(sequences) == 0 || sequences[len(sequences)-1] != transaction.LedgerSequence {
+			sequences = append(sequences, transaction.LedgerSequence)
+		}
+	}
+	bySequence := map[int32]history.Ledger{}
+	for start := 0; start < len(sequences); start += pageSize {
+		end := start + pageSize
+		if end > len(sequences) {
+			end = len(sequences)
+		}
+		var ledgers []history.Ledger
+		if err := q.LedgersBySequence(ctx, &ledgers, sequences[start:end]...); err != nil {
+			return nil, errors.Wrap(err, "could not load ledgers")
+		}
+		for _, ledger := range ledgers {
+			bySequence[ledger.Sequence] = ledger
+		}
+	}
+	return bySequence, nil
+}
+
+// transactionPercentageFee returns the minimum percentage fee of a
+// transaction, computed from the native amounts its operations transfer like
+// the network does. The amounts moved by account merges are read from their
+// effects, the merges of failed transactions did not move any amount.
+func transactionPercentageFee(ctx context.Context, q *history.Q, transaction history.Transaction, basePercentageFee int32) (int64, error) {
+	if basePercentageFee == 0 {
+		return 0, nil
+	}
+	generic, err := txnbuild.TransactionFromXDR(transaction.TxEnvelope)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not decode transaction %s", transaction.TransactionHash)
+	}
+	tx, ok := generic.Transaction()
+	if !ok {
+		feeBump, _ := generic.FeeBump()
+		tx = feeBump.InnerTransaction()
+	}
+	source := tx.ToXDR().SourceAccount().ToAccountId()
+
+	estimator := txnbuild.FeeEstimator{
+		BasePercentageFee:    int64(basePercentageFee),
+		AccountMergeBalances: map[string]string{},
+	}
+	ops := tx.Operations()
+	for i, op := range ops {
+		merge, ok := op.(*txnbuild.AccountMerge)
+		if !ok {
+			continue
+		}
+		merged := source.Address()
+		if merge.SourceAccount != "" {
+			muxed, err := xdr.AddressToMuxedAccount(merge.SourceAccount)
+			if err != nil {
+				return 0, errors.Wrapf(err, "invalid merged account in transaction %s", transaction.TransactionHash)
+			}
+			merged = muxed.ToAccountId().Address()
+		}
+		if _, ok := estimator.AccountMergeBalances[merged]; !ok {
+			estimator.AccountMergeBalances[merged] = "0"
+		}
+		if !transaction.Successful {
+			continue
+		}
+
+		id := toid.Parse(transaction.ID)
+		var effects []history.Effect
+		err := q.Effects().ForOperation(toid.New(id.LedgerSequence, id.TransactionOrder, int32(i+1)).ToInt64()).Select(ctx, &effects)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not load account merge effects")
+		}
+		for _, effect := range effects {
+			if effect.Type != history.EffectAccountDebited || effect.Account != merged {
+				continue
+			}
+			var details effectAmountDetails
+			if err := effect.UnmarshalDetails(&details); err != nil {
+				return 0, errors.Wrapf(err, "could not read effect %s", effect.PagingToken())
+			}
+			estimator.AccountMergeBalances[merged] = details.Amount
+		}
+	}
+
+	// without a base fee the minimum fee is the percentage fee
+	fee, err := estimator.MinFee(source.Address(), ops)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not compute the percentage fee of transaction %s", transaction.TransactionHash)
+	}
+	return fee, nil
+}
+
+// feeEntry returns the fee charged for a transaction, split into the base
+// fee of its operations, its percentage fee and the rest of the fee bid.
+func feeEntry(transaction history.Transaction, baseFee, percentageFee int64) Entry {
+	operations := int64(transaction.OperationCount)
+	if transaction.InnerTransactionHash.Valid {
+		// fee bump transactions pay for one more operation
+		operations++
+	}
+	base := baseFee * operations
+	if base > transaction.FeeCharged {
+		base = transaction.FeeCharged
+	}
+	if percentageFee > transaction.FeeCharged-base {
+		percentageFee = transaction.FeeCharged - base
+	}
+	// the fees of a ledger are charged before its transactions are applied
+	id := toid.Parse(transaction.ID)
+	return Entry{
+		ID:              transaction.PagingToken() + "-fee",
+		Type:            EntryFee,
+		Ledger:          uint32(transaction.LedgerSequence),
+		ClosedAt:        transaction.LedgerCloseTime,
+		TransactionHash: transaction.TransactionHash,
+		Effect:          EntryFee,
+		Asset:           NativeAsset,
+		Amount:          transaction.FeeCharged,
+		BaseFee:         base,
+		PercentageFee:   percentageFee,
+		InclusionFee:    transaction.FeeCharged - base - percentageFee,
+		order:           toid.New(id.LedgerSequence, 0, id.TransactionOrder).ToInt64(),
+	}
+}
+
+// balancesAtLedger returns the balances of an account as of a ledger by
+// asset.
+func balancesAtLedger(ctx context.Context, q *history.Q, accountID string, sequence uint32) (map[string]int64, error) {
+	balances := map[string]int64{}
+	if sequence == 0 {
+		return balances, nil
+	}
+	rows, err := q.AccountBalancesAtLedger(ctx, accountID, sequence)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Removed {
+			continue
+		}
+		balances[assetString(row.AssetType, row.AssetCode, row.AssetIssuer)] = row.Balance
+	}
+	return balances, nil
+}
+
+// sumBalances sums up the entries of the statement by asset. The balances
+// missing at one end of the statement are 0.
+func sumBalances(s Statement, opening, closing map[string]int64) []Balance {
+	byAsset := map[string]*Balance{}
+	balance := func(asset string) *Balance {
+		if b, ok := byAsset[asset]; ok {
+			return b
+		}
+		b := &Balance{Asset: asset}
+		byAsset[asset] = b
+		return b
+	}
+	for asset, value := range opening {
+		balance(asset).Opening = value
+	}
+	for asset, value := range closing {
+		balance(asset).Closing = value
+	}
+	for _, entry := range s.Entries {
+		b := balance(entry.Asset)
+		switch entry.Type {
+		case EntryCredit:
+			b.Credits += entry.Amount
+		case EntryDebit:
+			b.Debits += entry.Amount
+		case EntryFee:
+			b.Fees += entry.Amount
+		}
+	}
+
+	balances := make([]Balance, 0, len(byAsset))
+	for asset, b := range byAsset {
+		if s.Asset != "" && asset != s.Asset {
+			continue
+		}
+		balances = append(balances, *b)
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		// the native balance first
+		if (balances[i].Asset == NativeAsset) != (balances[j].Asset == NativeAsset) {
+			return balances[i].Asset == NativeAsset
+		}
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances
+}
@@ -0,0 +1,311 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	testAccount = "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	testIssuer  = "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+)
+
+var closedAt = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func testStatement(t *testing.T) Statement {
+	operationID := toid.New(11, 1, 1).ToInt64()
+	transaction := history.Transaction{LedgerCloseTime: closedAt}
+	transaction.TransactionHash = "a1"
+
+	trade := history.Effect{
+		HistoryOperationID: operationID,
+		Order:              1,
+		Type:               history.EffectTrade,
+		DetailsString: null.StringFrom(`{
+			"sold_amount": "10.0000000", "sold_asset_type": "native",
+			"bought_amount": "2.5000000", "bought_asset_type": "credit_alphanum4",
+			"bought_asset_code": "USD", "bought_asset_issuer": "` + testIssuer + `"
+		}`),
+	}
+	entries, err := entriesFromEffect(trade, transaction)
+	require.NoError(t, err)
+
+	fee := history.Transaction{LedgerCloseTime: closedAt}
+	fee.TransactionHash = "a1"
+	fee.ID = toid.New(11, 1, 0).ToInt64()
+	fee.LedgerSequence = 11
+	fee.OperationCount = 1
+	fee.FeeCharged = 250
+	entries = append(entries, feeEntry(fee, 100, 100))
+
+	s := Statement{
+		AccountID:   testAccount,
+		From:        closedAt.Add(-time.Hour),
+		To:          closedAt.Add(time.Hour),
+		StartLedger: 10,
+		EndLedger:   12,
+		Entries:     entries,
+	}
+	s.Balances = sumBalances(
+		s,
+		map[string]int64{NativeAsset: 1000000000},
+		map[string]int64{NativeAsset: 1000000000 - 100000000 - 250, "USD:" + testIssuer: 25000000},
+	)
+	return s
+}
+
+func TestEntriesFromEffect(t *testing.T) {
+	s := testStatement(t)
+	require.Len(t, s.Entries, 3)
+
+	sold, bought, fee := s.Entries[0], s.Entries[1], s.Entries[2]
+	assert.Equal(t, EntryDebit, sold.Type)
+	assert.Equal(t, NativeAsset, sold.Asset)
+	assert.Equal(t, int64(100000000), sold.Amount)
+	assert.Equal(t, uint32(11), sold.Ledger)
+	assert.Equal(t, "trade", sold.Effect)
+	assert.Equal(t, EntryCredit, bought.Type)
+	assert.Equal(t, "USD:"+testIssuer, bought.Asset)
+	assert.Equal(t, int64(25000000), bought.Amount)
+	assert.NotEqual(t, sold.ID, bought.ID)
+
+	assert.Equal(t, EntryFee, fee.Type)
+	assert.Equal(t, int64(100), fee.BaseFee)
+	assert.Equal(t, int64(100), fee.PercentageFee)
+	assert.Equal(t, int64(50), fee.InclusionFee)
+	// fees are charged before the operations are applied
+	assert.Less(t, fee.order, sold.order)
+
+	if assert.Len(t, s.Balances, 2) {
+		assert.Equal(t, NativeAsset, s.Balances[0].Asset)
+		assert.Equal(t, int64(1000000000), s.Balances[0].Opening)
+		assert.Equal(t, int64(250), s.Balances[0].Fees)
+		// the USD trust line did not exist at the opening
+		assert.Equal(t, int64(0), s.Balances[1].Opening)
+		assert.Equal(t, int64(25000000), s.Balances[1].Closing)
+		assert.Equal(t, int64(25000000), s.Balances[1].Credits)
+	}
+}
+
+func TestPathPaymentEntries(t *testing.T) {
+	operationID := toid.New(11, 1, 1).ToInt64()
+	transaction := history.Transaction{LedgerCloseTime: closedAt}
+	transaction.TransactionHash = "a1"
+	transaction.ID = toid.New(11, 1, 0).ToInt64()
+	transaction.LedgerSequence = 11
+	transaction.OperationCount = 1
+	transaction.FeeCharged = 250
+
+	// the account sends 10 XLM through EUR, the destination receives USD
+	effects := []history.Effect{
+		{
+			Account:            testAccount,
+			HistoryOperationID: operationID,
+			Order:              1,
+			Type:               history.EffectAccountDebited,
+			DetailsString:      null.StringFrom(`{"amount": "10.0000000", "asset_type": "native"}`),
+		},
+		{
+			Account:            testAccount,
+			HistoryOperationID: operationID,
+			Order:              2,
+			Type:               history.EffectTrade,
+			DetailsString: null.StringFrom(`{
+				"sold_amount": "10.0000000", "sold_asset_type": "native",
+				"bought_amount": "4.0000000", "bought_asset_type": "credit_alphanum4",
+				"bought_asset_code": "EUR", "bought_asset_issuer": "` + testIssuer + `"
+			}`),
+		},
+		{
+			Account:            testAccount,
+			HistoryOperationID: operationID,
+			Order:              3,
+			Type:               history.EffectTrade,
+			DetailsString: null.StringFrom(`{
+				"sold_amount": "4.0000000", "sold_asset_type": "credit_alphanum4",
+				"sold_asset_code": "EUR", "sold_asset_issuer": "` + testIssuer + `",
+				"bought_amount": "2.5000000", "bought_asset_type": "credit_alphanum4",
+				"bought_asset_code": "USD", "bought_asset_issuer": "` + testIssuer + `"
+			}`),
+		},
+	}
+	pathPayments := map[int64]history.Operation{
+		operationID: {
+			Type:          xdr.OperationTypePathPaymentStrictSend,
+			SourceAccount: testAccount,
+		},
+	}
+
+	s := Statement{AccountID: testAccount, StartLedger: 10, EndLedger: 12}
+	entries, err := entriesFromEffects(
+		s,
+		effects,
+		map[int64]history.Transaction{transaction.ID: transaction},
+		pathPayments,
+	)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, EntryDebit, entries[0].Type)
+	assert.Equal(t, NativeAsset, entries[0].Asset)
+	assert.Equal(t, int64(100000000), entries[0].Amount)
+
+	s.Entries = append(entries, feeEntry(transaction, 100, 100))
+	s.Balances = sumBalances(
+		s,
+		map[string]int64{NativeAsset: 1000000000},
+		map[string]int64{NativeAsset: 1000000000 - 100000000 - 250},
+	)
+	require.Len(t, s.Balances, 1)
+	for _, balance := range s.Balances {
+		assert.Equal(t, balance.Closing, balance.Opening+balance.Credits-balance.Debits-balance.Fees, balance.Asset)
+	}
+
+	// the trades of the offers of the account are its only balance effects
+	entries, err = entriesFromEffects(s, effects[1:2], nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestLiquidityPoolEntries(t *testing.T) {
+	transaction := history.Transaction{LedgerCloseTime: closedAt}
+	reserves := `[
+		{"asset": "native", "amount": "10.0000000"},
+		{"asset": "USD:` + testIssuer + `", "amount": "5.0000000"}
+	]`
+
+	deposit := history.Effect{
+		HistoryOperationID: toid.New(11, 1, 1).ToInt64(),
+		Order:              1,
+		Type:               history.EffectLiquidityPoolDeposited,
+		DetailsString:      null.StringFrom(`{"reserves_deposited": ` + reserves + `, "shares_received": "7.0000000"}`),
+	}
+	entries, err := entriesFromEffect(deposit, transaction)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EntryDebit, entries[0].Type)
+	assert.Equal(t, NativeAsset, entries[0].Asset)
+	assert.Equal(t, int64(100000000), entries[0].Amount)
+	assert.Equal(t, EntryDebit, entries[1].Type)
+	assert.Equal(t, "USD:"+testIssuer, entries[1].Asset)
+	assert.Equal(t, int64(50000000), entries[1].Amount)
+	assert.Equal(t, "liquidity_pool_deposited", entries[1].Effect)
+	assert.NotEqual(t, entries[0].ID, entries[1].ID)
+
+	withdrawal := history.Effect{
+		HistoryOperationID: toid.New(12, 1, 1).ToInt64(),
+		Order:              1,
+		Type:               history.EffectLiquidityPoolWithdrew,
+		DetailsString:      null.StringFrom(`{"reserves_received": ` + reserves + `, "shares_redeemed": "7.0000000"}`),
+	}
+	entries, err = entriesFromEffect(withdrawal, transaction)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, EntryCredit, entries[0].Type)
+	assert.Equal(t, EntryCredit, entries[1].Type)
+	assert.Equal(t, int64(50000000), entries[1].Amount)
+}
+
+func TestTransactionPercentageFee(t *testing.T) {
+	tx, err := txnbuild.NewTransaction(txnbuild.TransactionParams{
+		SourceAccount:        &txnbuild.SimpleAccount{AccountID: testAccount, Sequence: 1},
+		IncrementSequenceNum: true,
+		BaseFee:              txnbuild.MinBaseFee,
+		Timebounds:           txnbuild.NewInfiniteTimeout(),
+		Operations: []txnbuild.Operation{
+			&txnbuild.Payment{Destination: testIssuer, Amount: "100", Asset: txnbuild.NativeAsset{}},
+			&txnbuild.Payment{Destination: testIssuer, Amount: "100", Asset: txnbuild.CreditAsset{Code: "USD", Issuer: testIssuer}},
+		},
+	})
+	require.NoError(t, err)
+	envelope, err := tx.Base64()
+	require.NoError(t, err)
+
+	transaction := history.Transaction{}
+	transaction.TxEnvelope = envelope
+	// 0.45% of the native amount, transactions without merges do not read
+	// the history
+	fee, err := transactionPercentageFee(context.Background(), nil, transaction, 45)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4500000), fee)
+
+	fee, err = transactionPercentageFee(context.Background(), nil, transaction, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), fee)
+}
+
+func TestFeeEntry(t *testing.T) {
+	transaction := history.Transaction{LedgerCloseTime: closedAt}
+	transaction.ID = toid.New(11, 1, 0).ToInt64()
+	transaction.OperationCount = 2
+
+	// surge pricing
+	transaction.FeeCharged = 1000
+	fee := feeEntry(transaction, 100, 300)
+	assert.Equal(t, int64(200), fee.BaseFee)
+	assert.Equal(t, int64(300), fee.PercentageFee)
+	assert.Equal(t, int64(500), fee.InclusionFee)
+
+	// the minimum fee was not charged in full
+	transaction.FeeCharged = 250
+	fee = feeEntry(transaction, 100, 300)
+	assert.Equal(t, int64(200), fee.BaseFee)
+	assert.Equal(t, int64(50), fee.PercentageFee)
+	assert.Equal(t, int64(0), fee.InclusionFee)
+}
+
+func TestWrite(t *testing.T) {
+	s := testStatement(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCSV, s))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, strings.Join(csvHeader, ","), lines[0])
+	assert.Equal(t, "opening_balance,,native,100.0000000,9,,,,,,,", lines[1])
+	assert.True(t, strings.HasPrefix(lines[3], "debit,"))
+	assert.True(t, strings.HasSuffix(lines[5], ",fee,0.0000100,0.0000100,0.0000050"))
+	assert.Equal(t, "closing_balance,,USD:"+testIssuer+",2.5000000,12,,,,,,,", lines[7])
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatJSONLines, s))
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 8)
+	var header statementHeader
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, recordStatement, header.Record)
+	assert.Equal(t, uint32(10), header.StartLedger)
+	var fee record
+	require.NoError(t, json.Unmarshal([]byte(lines[5]), &fee))
+	assert.Equal(t, "fee", fee.Record)
+	assert.Equal(t, "0.0000250", fee.Amount)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatOFX, s))
+	ofx := buf.String()
+	assert.True(t, strings.HasPrefix(ofx, "<?xml"))
+	assert.Equal(t, 2, strings.Count(ofx, "<STMTTRNRS>"))
+	assert.Contains(t, ofx, "<CURDEF>XXX</CURDEF>")
+	assert.Contains(t, ofx, "<CURDEF>USD</CURDEF>")
+	assert.Contains(t, ofx, "<TRNAMT>-0.0000250</TRNAMT>")
+	assert.Contains(t, ofx, "<DTPOSTED>20210601120000</DTPOSTED>")
+	assert.Equal(t, 2, strings.Count(ofx, "<LEDGERBAL>"))
+
+	format, err := ParseFormat("OFX")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatOFX, format)
+	_, err = ParseFormat("pdf")
+	assert.EqualError(t, err, "invalid statement format: pdf")
+}
@@ -72,6 +72,8 @@ DROP TABLE IF EXISTS public.history_operations;
 DROP SEQUENCE IF EXISTS public.history_operation_participants_id_seq;
 DROP TABLE IF EXISTS public.history_operation_participants;
 DROP TABLE IF EXISTS public.history_ledgers;
+DROP TABLE IF EXISTS public.history_kinesis_coin_in_circulation_ledgers;
+DROP TABLE IF EXISTS public.history_kinesis_coin_in_circulation_days;
 DROP TABLE IF EXISTS public.history_effects;
 DROP SEQUENCE IF EXISTS public.history_assets_id_seq;
 DROP TABLE IF EXISTS public.history_assets;
@@ -320,6 +322,36 @@ CREATE TABLE history_ledgers (
 );
 
 
+--
+-- Name: history_kinesis_coin_in_circulation_days; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE history_kinesis_coin_in_circulation_days (
+    day date NOT NULL PRIMARY KEY,
+    ledger_sequence integer NOT NULL,
+    mint bigint NOT NULL,
+    redemption bigint NOT NULL,
+    total_mint bigint NOT NULL,
+    total_redemption bigint NOT NULL,
+    circulation bigint NOT NULL
+);
+
+
+--
+-- Name: history_kinesis_coin_in_circulation_ledgers; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE history_kinesis_coin_in_circulation_ledgers (
+    ledger_sequence integer NOT NULL PRIMARY KEY,
+    closed_at timestamp without time zone NOT NULL,
+    mint bigint NOT NULL,
+    redemption bigint NOT NULL,
+    total_mint bigint DEFAULT 0 NOT NULL,
+    total_redemption bigint DEFAULT 0 NOT NULL,
+    circulation bigint DEFAULT 0 NOT NULL
+);
+
+
 --
 -- Name: history_operation_participants; Type: TABLE; Schema: public; Owner: -
 --
@@ -450,7 +482,8 @@ CREATE TABLE history_transactions (
     inner_transaction_hash character varying(64),
     fee_account character varying(64),
     inner_signatures character varying(96)[],
-    new_max_fee bigint
+    new_max_fee bigint,
+    transferred_amount bigint
 );
 
 
@@ -9,6 +9,7 @@ package txsub
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stretchr/testify/mock"
@@ -67,3 +68,37 @@ func (m *mockDBQ) TransactionByHash(ctx context.Context, dest interface{}, hash
 	args := m.Called(ctx, dest, hash)
 	return args.Error(0)
 }
+
+type mockStore struct {
+	mock.Mock
+}
+
+func (m *mockStore) UpsertTxSubmission(ctx context.Context, submission history.TxSubmission) error {
+	args := m.Called(ctx, submission)
+	return args.Error(0)
+}
+
+func (m *mockStore) GetTxSubmission(ctx context.Context, hash string) (history.TxSubmission, error) {
+	args := m.Called(ctx, hash)
+	return args.Get(0).(history.TxSubmission), args.Error(1)
+}
+
+func (m *mockStore) GetPendingTxSubmissions(ctx context.Context) ([]history.TxSubmission, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]history.TxSubmission), args.Error(1)
+}
+
+func (m *mockStore) FinishTxSubmission(ctx context.Context, hash, status string, ledger int32, resultXDR string) (int64, error) {
+	args := m.Called(ctx, hash, status, ledger, resultXDR)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockStore) ExpireTxSubmissions(ctx context.Context, submittedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, submittedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockStore) DeleteTxSubmissionsFinishedBefore(ctx context.Context, finishedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, finishedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
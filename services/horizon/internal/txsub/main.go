@@ -38,6 +38,20 @@ type OpenSubmissionList interface {
 	Pending(context.Context) []string
 }
 
+// SubmissionStore persists the submissions to stellar-core so that they
+// outlive the process which submitted them. The store is shared by all the
+// Horizon instances using the same DB: every instance tracks the pending
+// submissions of the store until they are ingested or expire, and can report
+// their status.
+type SubmissionStore interface {
+	UpsertTxSubmission(ctx context.Context, submission history.TxSubmission) error
+	GetTxSubmission(ctx context.Context, hash string) (history.TxSubmission, error)
+	GetPendingTxSubmissions(ctx context.Context) ([]history.TxSubmission, error)
+	FinishTxSubmission(ctx context.Context, hash, status string, ledger int32, resultXDR string) (int64, error)
+	ExpireTxSubmissions(ctx context.Context, submittedBefore time.Time) (int64, error)
+	DeleteTxSubmissionsFinishedBefore(ctx context.Context, finishedBefore time.Time) (int64, error)
+}
+
 // Submitter represents the low-level "submit a transaction to stellar-core"
 // provider.
 type Submitter interface {
@@ -65,6 +79,10 @@ type SubmissionResult struct {
 	// inclusion in the ledger (i.e. A successful submission).
 	Err error
 
+	// Status is the status of the transaction returned by stellar-core, see
+	// proto.TXStatus*. It is empty when stellar-core could not be reached.
+	Status string
+
 	// Duration records the time it took to submit a transaction
 	// to stellar-core
 	Duration time.Duration
@@ -20,6 +20,7 @@ func TestDefaultSubmitter(t *testing.T) {
 	s := NewDefaultSubmitter(http.DefaultClient, server.URL)
 	sr := s.Submit(ctx, "hello")
 	assert.Nil(t, sr.Err)
+	assert.Equal(t, "PENDING", sr.Status)
 	assert.True(t, sr.Duration > 0)
 	assert.Equal(t, "hello", server.LastRequest.URL.Query().Get("blob"))
 
@@ -84,6 +85,7 @@ func TestDefaultSubmitter(t *testing.T) {
 	s = NewDefaultSubmitter(http.DefaultClient, server.URL)
 	sr = s.Submit(ctx, "hello")
 	assert.IsType(t, &FailedTransactionError{}, sr.Err)
+	assert.Equal(t, "ERROR", sr.Status)
 	ferr := sr.Err.(*FailedTransactionError)
 	assert.Equal(t, "1234", ferr.ResultXDR)
 }
@@ -7,7 +7,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/prometheus/client_golang/prometheus"
+	proto "github.com/stellar/go/protocols/stellarcore"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/txsub/sequence"
 	"github.com/stellar/go/support/log"
@@ -42,6 +44,17 @@ type System struct {
 	SubmissionTimeout time.Duration
 	Log               *log.Entry
 
+	// Store is the optional persistent store of the submissions. When it is
+	// nil the submissions are only tracked in memory, by Pending.
+	Store SubmissionStore
+	// StorePendingExpiry is the time after which the pending submissions of
+	// Store which were not ingested expire. It is longer than
+	// SubmissionTimeout so that the submissions outlive restarts.
+	StorePendingExpiry time.Duration
+	// StoreRetention is the time for which the finished submissions are kept
+	// in Store.
+	StoreRetention time.Duration
+
 	Metrics struct {
 		// SubmissionDuration exposes timing metrics about the rate and latency of
 		// submissions to stellar-core
@@ -154,6 +167,7 @@ func (sys *System) Submit(
 
 		sr := sys.submitOnce(ctx, rawTx)
 		sys.updateTransactionTypeMetrics(envelope)
+		sys.storeSubmission(ctx, hash, rawTx, sr)
 
 		// if submission succeeded
 		if sr.Err == nil {
@@ -199,6 +213,200 @@ func (sys *System) Submit(
 	return
 }
 
+// SubmitAsync submits the provided base64 encoded transaction envelope to
+// stellar-core and returns as soon as stellar-core responded, without waiting
+// for the transaction to be included in a ledger. Transactions accepted by
+// stellar-core are added to the open submission list, so that their status
+// can be polled with IsPending until they are ingested.
+func (sys *System) SubmitAsync(
+	ctx context.Context,
+	rawTx string,
+	envelope xdr.TransactionEnvelope,
+	hash string,
+) SubmissionResult {
+	sys.Init()
+
+	sys.Log.Ctx(ctx).WithFields(log.F{
+		"hash":    hash,
+		"tx_type": envelope.Type.String(),
+		"tx":      rawTx,
+	}).Info("Processing asynchronous transaction")
+
+	sr := sys.submitOnce(ctx, rawTx)
+	sys.updateTransactionTypeMetrics(envelope)
+	sys.storeSubmission(ctx, hash, rawTx, sr)
+
+	if sr.Err == nil && sr.Status != proto.TXStatusTryAgainLater {
+		// nobody waits for the result, the listener only needs to be able
+		// to hold it until the submission is finished
+		listener := make(chan Result, 1)
+		if err := sys.Pending.Add(ctx, hash, listener); err != nil {
+			sys.Log.Ctx(ctx).WithError(err).WithField("hash", hash).Warn("cannot track asynchronous transaction")
+		}
+	}
+	return sr
+}
+
+// IsPending returns true if the transaction with the given hash was accepted
+// by stellar-core and is still waiting to be ingested. When the system has a
+// Store, the submissions of all the instances sharing it are considered.
+func (sys *System) IsPending(ctx context.Context, hash string) bool {
+	sys.Init()
+	if sys.Store != nil {
+		submission, err := sys.Store.GetTxSubmission(ctx, hash)
+		if err == nil {
+			return submission.Status == history.TxSubmissionPending
+		}
+		if err != sql.ErrNoRows {
+			sys.Log.Ctx(ctx).WithError(err).WithField("hash", hash).Warn("cannot load stored submission")
+		}
+	}
+	for _, pending := range sys.Pending.Pending(ctx) {
+		if pending == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// storeSubmission records a submission to stellar-core in the Store. Errors
+// are only logged, they do not affect the submission.
+func (sys *System) storeSubmission(ctx context.Context, hash, rawTx string, sr SubmissionResult) {
+	if sys.Store == nil || sr.Status == "" {
+		return
+	}
+
+	submission := history.TxSubmission{
+		TransactionHash: hash,
+		EnvelopeXDR:     rawTx,
+		SubmittedAt:     time.Now(),
+		CoreStatus:      sr.Status,
+		Status:          history.TxSubmissionPending,
+	}
+	if sr.Err != nil || sr.Status == proto.TXStatusTryAgainLater {
+		submission.Status = history.TxSubmissionRejected
+		submission.FinishedAt = null.TimeFrom(time.Now().UTC())
+	}
+	if fte, ok := sr.Err.(*FailedTransactionError); ok {
+		submission.CoreErrorResultXDR = null.StringFrom(fte.ResultXDR)
+	}
+
+	if err := sys.Store.UpsertTxSubmission(ctx, submission); err != nil {
+		sys.Log.Ctx(ctx).WithError(err).WithField("hash", hash).Warn("cannot store submission")
+	}
+}
+
+// pendingHashes returns the hashes of the open submissions of this instance
+// and of the pending submissions in the Store.
+func (sys *System) pendingHashes(ctx context.Context) []string {
+	pending := sys.Pending.Pending(ctx)
+	if sys.Store == nil {
+		return pending
+	}
+
+	stored, err := sys.Store.GetPendingTxSubmissions(ctx)
+	if err != nil {
+		log.Ctx(ctx).WithError(err).Error("error getting stored submissions")
+		return pending
+	}
+	seen := make(map[string]bool, len(pending))
+	for _, hash := range pending {
+		seen[hash] = true
+	}
+	for _, submission := range stored {
+		if !seen[submission.TransactionHash] {
+			seen[submission.TransactionHash] = true
+			pending = append(pending, submission.TransactionHash)
+		}
+	}
+	return pending
+}
+
+// finishStoredSubmission records the result of an ingested transaction in
+// the Store.
+func (sys *System) finishStoredSubmission(ctx context.Context, hash, status string, tx history.Transaction) {
+	if sys.Store == nil {
+		return
+	}
+	if _, err := sys.Store.FinishTxSubmission(ctx, hash, status, tx.LedgerSequence, tx.TxResult); err != nil {
+		log.Ctx(ctx).WithError(err).WithField("hash", hash).Error("error finishing stored submission")
+	}
+}
+
+// Recover resubmits the pending submissions of the Store to stellar-core, so
+// that the transactions which stellar-core lost while Horizon was down are
+// submitted again. The submissions stay pending, Tick tracks them until they
+// are ingested or expire. The state of SubmissionQueue is not stored: the
+// transactions which were queued behind a sequence number when Horizon
+// stopped were never submitted and must be submitted again by their clients.
+func (sys *System) Recover(ctx context.Context) {
+	sys.Init()
+	if sys.Store == nil {
+		return
+	}
+	logger := log.Ctx(ctx)
+
+	stored, err := sys.Store.GetPendingTxSubmissions(ctx)
+	if err != nil {
+		logger.WithError(err).Error("error getting stored submissions")
+		return
+	}
+	for _, submission := range stored {
+		sr := sys.submitOnce(ctx, submission.EnvelopeXDR)
+		entry := logger.WithFields(log.F{
+			"hash":        submission.TransactionHash,
+			"core_status": sr.Status,
+		})
+		if sr.Err != nil {
+			entry = entry.WithError(sr.Err)
+		}
+		entry.Info("Resubmitted stored submission")
+	}
+	if len(stored) > 0 {
+		logger.WithField("resubmitted", len(stored)).Info("Recovered stored submissions")
+	}
+}
+
+// pendingLedgers returns the number of recent ledgers in which Tick looks for
+// the pending transactions.
+func (sys *System) pendingLedgers() int32 {
+	// In Tick we only check txs in a queue so those which did not have results before Tick
+	// so we check for them in the last 5 mins of ledgers: 60.
+	ledgers := int32(60)
+	if sys.Store != nil {
+		// the stored submissions are pending until they expire, 5 seconds
+		// is the average ledger close time.
+		if l := int32(sys.StorePendingExpiry / (5 * time.Second)); l > ledgers {
+			ledgers = l
+		}
+	}
+	return ledgers
+}
+
+// cleanStore expires the stored submissions which were not ingested within
+// the store pending expiry and removes the submissions finished for longer
+// than the store retention.
+func (sys *System) cleanStore(ctx context.Context) {
+	if sys.Store == nil {
+		return
+	}
+	logger := log.Ctx(ctx)
+	now := time.Now()
+
+	expired, err := sys.Store.ExpireTxSubmissions(ctx, now.Add(-sys.StorePendingExpiry))
+	if err != nil {
+		logger.WithError(err).Error("error expiring stored submissions")
+		return
+	}
+	if expired > 0 {
+		logger.WithField("expired", expired).Warn("Expired stored submissions due to timeout")
+	}
+
+	if _, err := sys.Store.DeleteTxSubmissionsFinishedBefore(ctx, now.Add(-sys.StoreRetention)); err != nil {
+		logger.WithError(err).Error("error removing finished stored submissions")
+	}
+}
+
 // waitUntilAccountSequence blocks until either the context times out or the sequence number of the
 // given source account is greater than or equal to `seq`
 func (sys *System) waitUntilAccountSequence(ctx context.Context, db HorizonDB, sourceAddress string, seq uint64) error {
@@ -330,7 +538,7 @@ func (sys *System) Tick(ctx context.Context) {
 		}
 	}
 
-	pending := sys.Pending.Pending(ctx)
+	pending := sys.pendingHashes(ctx)
 
 	if len(pending) > 0 {
 		latestLedger, err := db.GetLatestHistoryLedger(ctx)
@@ -339,9 +547,7 @@ func (sys *System) Tick(ctx context.Context) {
 			return
 		}
 
-		// In Tick we only check txs in a queue so those which did not have results before Tick
-		// so we check for them in the last 5 mins of ledgers: 60.
-		sinceLedgerSeq := int32(latestLedger) - 60
+		sinceLedgerSeq := int32(latestLedger) - sys.pendingLedgers()
 		if sinceLedgerSeq < 0 {
 			sinceLedgerSeq = 0
 		}
@@ -370,12 +576,14 @@ func (sys *System) Tick(ctx context.Context) {
 			if err == nil {
 				logger.WithField("hash", hash).Debug("finishing open submission")
 				sys.Pending.Finish(ctx, hash, Result{Transaction: tx})
+				sys.finishStoredSubmission(ctx, hash, history.TxSubmissionSuccess, tx)
 				continue
 			}
 
 			if _, ok := err.(*FailedTransactionError); ok {
 				logger.WithField("hash", hash).Debug("finishing open submission")
 				sys.Pending.Finish(ctx, hash, Result{Transaction: tx, Err: err})
+				sys.finishStoredSubmission(ctx, hash, history.TxSubmissionFailed, tx)
 				continue
 			}
 
@@ -390,6 +598,7 @@ func (sys *System) Tick(ctx context.Context) {
 		logger.WithStack(err).Error(err)
 		return
 	}
+	sys.cleanStore(ctx)
 
 	sys.Metrics.OpenSubmissionsGauge.Set(float64(stillOpen))
 	sys.Metrics.BufferedSubmissionsGauge.Set(float64(sys.SubmissionQueue.Size()))
@@ -437,6 +646,13 @@ func (sys *System) Init() {
 			// by sending a Timeout response.
 			sys.SubmissionTimeout = 30 * time.Second
 		}
+
+		if sys.StorePendingExpiry == 0 {
+			sys.StorePendingExpiry = 10 * time.Minute
+		}
+		if sys.StoreRetention == 0 {
+			sys.StoreRetention = 24 * time.Hour
+		}
 	})
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
+	proto "github.com/stellar/go/protocols/stellarcore"
 	"github.com/stellar/go/services/horizon/internal/db2/history"
 	"github.com/stellar/go/services/horizon/internal/test"
 	"github.com/stellar/go/services/horizon/internal/txsub/sequence"
@@ -346,6 +347,53 @@ func (suite *SystemTestSuite) TestSubmit_OpenTransactionList() {
 	assert.Equal(suite.T(), uint64(1), getMetricValue(suite.system.Metrics.SubmissionDuration).GetSummary().GetSampleCount())
 }
 
+func (suite *SystemTestSuite) TestSubmitAsync_Pending() {
+	suite.submitter.R = SubmissionResult{Status: "PENDING"}
+
+	result := suite.system.SubmitAsync(
+		suite.ctx,
+		suite.successTx.Transaction.TxEnvelope,
+		suite.successXDR,
+		suite.successTx.Transaction.TransactionHash,
+	)
+	assert.NoError(suite.T(), result.Err)
+	assert.Equal(suite.T(), "PENDING", result.Status)
+	assert.True(suite.T(), suite.submitter.WasSubmittedTo)
+	assert.True(suite.T(), suite.system.IsPending(suite.ctx, suite.successTx.Transaction.TransactionHash))
+	assert.False(suite.T(), suite.system.IsPending(suite.ctx, "unknown"))
+	assert.Equal(suite.T(), float64(1), getMetricValue(suite.system.Metrics.SuccessfulSubmissionsCounter).GetCounter().GetValue())
+
+	// the transaction is no longer pending once it is ingested
+	suite.db.On("BeginTx", &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}).Return(nil).Once()
+	suite.db.On("Rollback").Return(nil).Once()
+	suite.db.On("TransactionsByHashesSinceLedger", suite.ctx, []string{suite.successTx.Transaction.TransactionHash}, uint32(940)).
+		Return([]history.Transaction{suite.successTx.Transaction}, nil).Once()
+
+	suite.system.Tick(suite.ctx)
+	assert.False(suite.T(), suite.system.IsPending(suite.ctx, suite.successTx.Transaction.TransactionHash))
+}
+
+func (suite *SystemTestSuite) TestSubmitAsync_NotTracked() {
+	for _, result := range []SubmissionResult{
+		{Status: "TRY_AGAIN_LATER"},
+		{Status: "ERROR", Err: &FailedTransactionError{"AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA="}},
+		{Err: errors.New("failed to submit")},
+	} {
+		suite.submitter.R = result
+		submission := suite.system.SubmitAsync(
+			suite.ctx,
+			suite.successTx.Transaction.TxEnvelope,
+			suite.successXDR,
+			suite.successTx.Transaction.TransactionHash,
+		)
+		assert.Equal(suite.T(), result, submission)
+		assert.Empty(suite.T(), suite.system.Pending.Pending(suite.ctx))
+	}
+}
+
 // Tick should be a no-op if there are no open submissions.
 func (suite *SystemTestSuite) TestTick_Noop() {
 	suite.db.On("BeginTx", &sql.TxOptions{
@@ -415,6 +463,104 @@ func (suite *SystemTestSuite) TestTick_FinishesTransactions() {
 	assert.Equal(suite.T(), 0, len(suite.system.Pending.Pending(suite.ctx)))
 }
 
+func (suite *SystemTestSuite) TestSubmitAsync_Store() {
+	store := &mockStore{}
+	suite.system.Store = store
+	hash := suite.successTx.Transaction.TransactionHash
+
+	store.On("UpsertTxSubmission", suite.ctx, mock.MatchedBy(func(submission history.TxSubmission) bool {
+		return submission.TransactionHash == hash &&
+			submission.EnvelopeXDR == suite.successTx.Transaction.TxEnvelope &&
+			submission.CoreStatus == "PENDING" &&
+			submission.Status == history.TxSubmissionPending &&
+			!submission.FinishedAt.Valid
+	})).Return(nil).Once()
+	suite.submitter.R = SubmissionResult{Status: "PENDING"}
+	suite.system.SubmitAsync(suite.ctx, suite.successTx.Transaction.TxEnvelope, suite.successXDR, hash)
+
+	store.On("UpsertTxSubmission", suite.ctx, mock.MatchedBy(func(submission history.TxSubmission) bool {
+		return submission.CoreStatus == "ERROR" &&
+			submission.Status == history.TxSubmissionRejected &&
+			submission.CoreErrorResultXDR.String == "AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA=" &&
+			submission.FinishedAt.Valid
+	})).Return(nil).Once()
+	suite.submitter.R = SubmissionResult{
+		Status: "ERROR",
+		Err:    &FailedTransactionError{"AAAAAAAAAGT/////AAAAAQAAAAAAAAAB////+wAAAAA="},
+	}
+	suite.system.SubmitAsync(suite.ctx, suite.successTx.Transaction.TxEnvelope, suite.successXDR, hash)
+
+	// submissions which did not reach stellar-core are not stored
+	suite.submitter.R = SubmissionResult{Err: errors.New("failed to submit")}
+	suite.system.SubmitAsync(suite.ctx, suite.successTx.Transaction.TxEnvelope, suite.successXDR, hash)
+
+	store.AssertExpectations(suite.T())
+}
+
+func (suite *SystemTestSuite) TestIsPending_Store() {
+	store := &mockStore{}
+	suite.system.Store = store
+
+	store.On("GetTxSubmission", suite.ctx, "pending").
+		Return(history.TxSubmission{Status: history.TxSubmissionPending}, nil).Once()
+	store.On("GetTxSubmission", suite.ctx, "expired").
+		Return(history.TxSubmission{Status: history.TxSubmissionExpired}, nil).Once()
+	store.On("GetTxSubmission", suite.ctx, "unknown").
+		Return(history.TxSubmission{}, sql.ErrNoRows).Once()
+
+	assert.True(suite.T(), suite.system.IsPending(suite.ctx, "pending"))
+	assert.False(suite.T(), suite.system.IsPending(suite.ctx, "expired"))
+	assert.False(suite.T(), suite.system.IsPending(suite.ctx, "unknown"))
+	store.AssertExpectations(suite.T())
+}
+
+// Tick tracks the pending submissions of the store, including those which
+// were submitted by another instance or before a restart.
+func (suite *SystemTestSuite) TestTick_FinishesStoredSubmissions() {
+	store := &mockStore{}
+	suite.system.Store = store
+	hash := suite.successTx.Transaction.TransactionHash
+
+	store.On("GetPendingTxSubmissions", suite.ctx).Return([]history.TxSubmission{
+		{TransactionHash: hash, Status: history.TxSubmissionPending},
+		{TransactionHash: "aa168f12124b7c196c0adaee7c73a64d37f99428cacb59a91ff389626845e7cf", Status: history.TxSubmissionPending},
+	}, nil).Once()
+	store.On("FinishTxSubmission", suite.ctx, hash, history.TxSubmissionSuccess, int32(2), suite.successTx.Transaction.TxResult).
+		Return(int64(1), nil).Once()
+	store.On("ExpireTxSubmissions", suite.ctx, mock.AnythingOfType("time.Time")).Return(int64(1), nil).Once()
+	store.On("DeleteTxSubmissionsFinishedBefore", suite.ctx, mock.AnythingOfType("time.Time")).Return(int64(0), nil).Once()
+
+	suite.db.On("BeginTx", &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	}).Return(nil).Once()
+	suite.db.On("Rollback").Return(nil).Once()
+	suite.db.On("TransactionsByHashesSinceLedger", suite.ctx, []string{hash, "aa168f12124b7c196c0adaee7c73a64d37f99428cacb59a91ff389626845e7cf"}, uint32(880)).
+		Return([]history.Transaction{suite.successTx.Transaction}, nil).Once()
+
+	suite.system.Tick(suite.ctx)
+	store.AssertExpectations(suite.T())
+}
+
+// Recover resubmits the pending submissions of the store from their envelope.
+func (suite *SystemTestSuite) TestRecover_ResubmitsStoredSubmissions() {
+	store := &mockStore{}
+	suite.system.Store = store
+	suite.submitter.R = SubmissionResult{Status: proto.TXStatusDuplicate}
+
+	store.On("GetPendingTxSubmissions", suite.ctx).Return([]history.TxSubmission{
+		{
+			TransactionHash: suite.successTx.Transaction.TransactionHash,
+			EnvelopeXDR:     suite.successTx.Transaction.TxEnvelope,
+			Status:          history.TxSubmissionPending,
+		},
+	}, nil).Once()
+
+	suite.system.Recover(suite.ctx)
+	suite.Assert().True(suite.submitter.WasSubmittedTo)
+	store.AssertExpectations(suite.T())
+}
+
 func (suite *SystemTestSuite) TestTickFinishFeeBumpTransaction() {
 	innerTxEnvelope := "AAAAAAMDAwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAYwAAAAAAAABhAAAAAQAAAAAAAAACAAAAAAAAAAQAAAAAAAAAAQAAAAAAAAALAAAAAAAAAGIAAAAAAAAAAQICAgIAAAADFBQUAA=="
 	innerHash := "e98869bba8bce08c10b78406202127f3888c25454cd37b02600862452751f526"
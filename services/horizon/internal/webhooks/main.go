@@ -0,0 +1,163 @@
+// Package webhooks contains the webhook delivery subsystem for horizon. The
+// ingestion system queues a delivery in the horizon database for every
+// ingested operation matching a registered webhook, this system sends the
+// queued deliveries to the webhook URLs, retrying the failed ones with an
+// exponential backoff.
+//
+// The deliveries are sent at least once and in no particular order. Every
+// delivery is signed with the secret of its webhook, see Sign.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	"github.com/stellar/go/support/db"
+)
+
+const (
+	// SignatureHeader is the header holding the signature of a delivery.
+	SignatureHeader = "X-Horizon-Signature"
+	// WebhookIDHeader is the header holding the id of the webhook.
+	WebhookIDHeader = "X-Horizon-Webhook-Id"
+	// DeliveryIDHeader is the header holding the id of the delivery, which
+	// allows receivers to discard the deliveries sent more than once.
+	DeliveryIDHeader = "X-Horizon-Delivery-Id"
+)
+
+// Defaults of the Options.
+const (
+	DefaultMaxAttempts  = 10
+	DefaultTimeout      = 10 * time.Second
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 100
+	DefaultMinBackoff   = 10 * time.Second
+	DefaultMaxBackoff   = time.Hour
+)
+
+// Options configures the webhook delivery system, the zero values are
+// replaced by the defaults.
+type Options struct {
+	// MaxAttempts is the number of attempts after which a delivery is marked
+	// as failed.
+	MaxAttempts int
+	// Timeout is the timeout of a delivery request.
+	Timeout time.Duration
+	// PollInterval is how often the queue is polled for due deliveries.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of deliveries sent per poll.
+	BatchSize int
+	// MinBackoff is the delay before the first retry of a delivery, which
+	// doubles with every attempt up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (o *Options) setDefaults() {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultMaxAttempts
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultBatchSize
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = DefaultMinBackoff
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = DefaultMaxBackoff
+		if o.MaxBackoff < o.MinBackoff {
+			o.MaxBackoff = o.MinBackoff
+		}
+	}
+}
+
+// deliveriesQ is the delivery queue in the horizon database.
+type deliveriesQ interface {
+	ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]history.PendingWebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery history.WebhookDelivery) error
+}
+
+// System represents the webhook delivery subsystem of horizon.
+type System struct {
+	HistoryQ deliveriesQ
+	Client   *http.Client
+	options  Options
+	now      func() time.Time
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// New initializes the webhook delivery system, which sends the deliveries
+// queued in the given horizon database once Run is called.
+func New(dbSession db.SessionInterface, options Options) *System {
+	options.setDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &System{
+		HistoryQ: &history.Q{SessionInterface: dbSession.Clone()},
+		Client:   &http.Client{Timeout: options.Timeout},
+		options:  options,
+		now:      time.Now,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Sign returns the signature of a delivery payload sent at the given time.
+// The signature has the form `t=<unix timestamp>,v1=<hex HMAC-SHA256>`, the
+// HMAC being computed with the webhook secret over `<unix timestamp>.<payload>`.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, hex.EncodeToString(computeHMAC(secret, t, payload)))
+}
+
+// Verify checks that signature is a valid signature of payload created with
+// secret. Receivers are expected to also check that the timestamp of the
+// signature is recent.
+func Verify(secret string, signature string, payload []byte) (time.Time, bool) {
+	var t, v1 string
+	for _, part := range strings.Split(signature, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return time.Time{}, false
+		}
+		switch kv[0] {
+		case "t":
+			t = kv[1]
+		case "v1":
+			v1 = kv[1]
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	mac, err := hex.DecodeString(v1)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(unix, 0), hmac.Equal(mac, computeHMAC(secret, t, payload))
+}
+
+func computeHMAC(secret, timestamp string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,179 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guregu/null"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+	herrors "github.com/stellar/go/services/horizon/internal/errors"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/support/log"
+)
+
+// leaseMargin is added to the request timeout to compute how long the claimed
+// deliveries are hidden from the other horizon instances.
+const leaseMargin = time.Minute
+
+// maxErrorLength is the maximum length of the error stored for a failed
+// delivery attempt.
+const maxErrorLength = 512
+
+// Run triggers the webhook delivery system to send the due deliveries until
+// it is shut down.
+func (s *System) Run() {
+	for {
+		select {
+		case <-time.After(s.options.PollInterval):
+			s.runOnce(s.ctx)
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *System) Shutdown() {
+	s.cancel()
+}
+
+func (s *System) runOnce(ctx context.Context) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err := herrors.FromPanic(rec)
+			log.Errorf("webhook delivery panicked: %s", err)
+			herrors.ReportToSentry(err, nil)
+		}
+	}()
+
+	for {
+		n, err := s.DeliverPending(ctx)
+		if err != nil {
+			log.Errorf("webhook delivery failed: %s", err)
+			return
+		}
+		// keep going while the queue is backlogged
+		if n < s.options.BatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// DeliverPending sends a batch of due deliveries concurrently and stores the
+// outcome of every attempt. It returns the number of deliveries sent.
+func (s *System) DeliverPending(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	deliveries, err := s.HistoryQ.ClaimWebhookDeliveries(
+		ctx, now, now.Add(s.options.Timeout+leaseMargin), s.options.BatchSize,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(deliveries))
+	for i := range deliveries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.deliver(ctx, deliveries[i])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// deliver sends a delivery to its webhook and updates it with the outcome.
+func (s *System) deliver(ctx context.Context, pending history.PendingWebhookDelivery) error {
+	delivery := pending.WebhookDelivery
+	statusCode, err := s.send(ctx, pending)
+
+	now := s.now().UTC()
+	delivery.Attempts++
+	delivery.LastAttemptAt = null.TimeFrom(now)
+	delivery.LastStatusCode = null.NewInt(int64(statusCode), statusCode != 0)
+	delivery.LastError = null.String{}
+
+	switch {
+	case err == nil:
+		delivery.Status = history.WebhookDeliveryDelivered
+	case ctx.Err() != nil:
+		// horizon is shutting down, the delivery is sent again once its
+		// lease expires
+		return nil
+	default:
+		message := err.Error()
+		if len(message) > maxErrorLength {
+			message = message[:maxErrorLength]
+		}
+		delivery.LastError = null.StringFrom(message)
+		if int(delivery.Attempts) >= s.options.MaxAttempts {
+			delivery.Status = history.WebhookDeliveryFailed
+		} else {
+			delivery.NextAttemptAt = now.Add(s.backoff(int(delivery.Attempts)))
+		}
+		log.WithFields(log.F{
+			"webhook_id":  delivery.WebhookID,
+			"delivery_id": delivery.ID,
+			"attempts":    delivery.Attempts,
+			"status":      delivery.Status,
+		}).Infof("webhook delivery attempt failed: %s", message)
+	}
+
+	if err := s.HistoryQ.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return errors.Wrapf(err, "could not update webhook delivery %d", delivery.ID)
+	}
+	return nil
+}
+
+// send posts the payload of a delivery to its webhook. Any response other
+// than 2xx is an error.
+func (s *System) send(ctx context.Context, delivery history.PendingWebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not create request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, strconv.FormatInt(delivery.WebhookID, 10))
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, s.now(), delivery.Payload))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain the body so that the connection can be reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt of a delivery which was
+// attempted the given number of times.
+func (s *System) backoff(attempts int) time.Duration {
+	delay := s.options.MinBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.options.MaxBackoff {
+			return s.options.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,306 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/horizon/internal/db2/history"
+)
+
+// memoryQ is an in-memory delivery queue.
+type memoryQ struct {
+	sync.Mutex
+	webhooks   map[int64]history.Webhook
+	deliveries []history.WebhookDelivery
+}
+
+func (q *memoryQ) ClaimWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]history.PendingWebhookDelivery, error) {
+	q.Lock()
+	defer q.Unlock()
+	var claimed []history.PendingWebhookDelivery
+	for i := range q.deliveries {
+		d := &q.deliveries[i]
+		if len(claimed) == limit {
+			break
+		}
+		if d.Status != history.WebhookDeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.NextAttemptAt = leaseUntil
+		webhook := q.webhooks[d.WebhookID]
+		claimed = append(claimed, history.PendingWebhookDelivery{
+			WebhookDelivery: *d,
+			URL:             webhook.URL,
+			Secret:          webhook.Secret,
+		})
+	}
+	return claimed, nil
+}
+
+func (q *memoryQ) UpdateWebhookDelivery(ctx context.Context, delivery history.WebhookDelivery) error {
+	q.Lock()
+	defer q.Unlock()
+	for i := range q.deliveries {
+		if q.deliveries[i].ID == delivery.ID {
+			q.deliveries[i] = delivery
+		}
+	}
+	return nil
+}
+
+func (q *memoryQ) delivery(id int64) history.WebhookDelivery {
+	q.Lock()
+	defer q.Unlock()
+	for _, d := range q.deliveries {
+		if d.ID == id {
+			return d
+		}
+	}
+	return history.WebhookDelivery{}
+}
+
+type receivedDelivery struct {
+	webhookID  string
+	deliveryID string
+	signature  string
+	body       []byte
+}
+
+// receiver is a local HTTP server receiving webhook deliveries.
+type receiver struct {
+	sync.Mutex
+	server   *httptest.Server
+	status   int
+	received []receivedDelivery
+}
+
+func newReceiver() *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		r.Lock()
+		defer r.Unlock()
+		r.received = append(r.received, receivedDelivery{
+			webhookID:  req.Header.Get(WebhookIDHeader),
+			deliveryID: req.Header.Get(DeliveryIDHeader),
+			signature:  req.Header.Get(SignatureHeader),
+			body:       body,
+		})
+		w.WriteHeader(r.status)
+	}))
+	return r
+}
+
+func (r *receiver) setStatus(status int) {
+	r.Lock()
+	defer r.Unlock()
+	r.status = status
+}
+
+func (r *receiver) deliveries() []receivedDelivery {
+	r.Lock()
+	defer r.Unlock()
+	return append([]receivedDelivery(nil), r.received...)
+}
+
+func newTestSystem(q deliveriesQ, now *time.Time, options Options) *System {
+	options.setDefaults()
+	return &System{
+		HistoryQ: q,
+		Client:   &http.Client{Timeout: options.Timeout},
+		options:  options,
+		now:      func() time.Time { return *now },
+		ctx:      context.Background(),
+		cancel:   func() {},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	payload := []byte(`{"type":"operation"}`)
+	timestamp := time.Unix(1600000000, 0)
+
+	signature := Sign("secret", timestamp, payload)
+	assert.Equal(t, "t=1600000000,v1=", signature[:16])
+
+	signedAt, ok := Verify("secret", signature, payload)
+	assert.True(t, ok)
+	assert.Equal(t, timestamp, signedAt)
+
+	_, ok = Verify("other secret", signature, payload)
+	assert.False(t, ok)
+	_, ok = Verify("secret", signature, []byte(`{"type":"effect"}`))
+	assert.False(t, ok)
+	_, ok = Verify("secret", "v1=abc", payload)
+	assert.False(t, ok)
+	_, ok = Verify("secret", "garbage", payload)
+	assert.False(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	s := newTestSystem(&memoryQ{}, nil, Options{
+		MinBackoff: time.Second,
+		MaxBackoff: 10 * time.Second,
+	})
+	assert.Equal(t, time.Second, s.backoff(1))
+	assert.Equal(t, 2*time.Second, s.backoff(2))
+	assert.Equal(t, 8*time.Second, s.backoff(4))
+	assert.Equal(t, 10*time.Second, s.backoff(5))
+	assert.Equal(t, 10*time.Second, s.backoff(100))
+}
+
+func TestDeliverPending(t *testing.T) {
+	r := newReceiver()
+	defer r.server.Close()
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	payload := json.RawMessage(`{"webhook_id":1,"type":"operation"}`)
+	q := &memoryQ{
+		webhooks: map[int64]history.Webhook{
+			1: {ID: 1, URL: r.server.URL, Secret: "s3cr3t"},
+		},
+		deliveries: []history.WebhookDelivery{
+			{ID: 10, WebhookID: 1, Payload: payload, Status: history.WebhookDeliveryPending, NextAttemptAt: now},
+			{ID: 11, WebhookID: 1, Payload: payload, Status: history.WebhookDeliveryPending, NextAttemptAt: now.Add(time.Minute)},
+			{ID: 12, WebhookID: 1, Payload: payload, Status: history.WebhookDeliveryDelivered, NextAttemptAt: now},
+		},
+	}
+	s := newTestSystem(q, &now, Options{})
+
+	n, err := s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	received := r.deliveries()
+	require.Len(t, received, 1)
+	assert.Equal(t, "1", received[0].webhookID)
+	assert.Equal(t, "10", received[0].deliveryID)
+	assert.JSONEq(t, string(payload), string(received[0].body))
+	signedAt, ok := Verify("s3cr3t", received[0].signature, received[0].body)
+	assert.True(t, ok)
+	assert.Equal(t, now, signedAt.UTC())
+
+	delivered := q.delivery(10)
+	assert.Equal(t, history.WebhookDeliveryDelivered, delivered.Status)
+	assert.Equal(t, int32(1), delivered.Attempts)
+	assert.Equal(t, now, delivered.LastAttemptAt.Time)
+	assert.Equal(t, int64(http.StatusOK), delivered.LastStatusCode.Int64)
+	assert.False(t, delivered.LastError.Valid)
+
+	// nothing is due anymore
+	n, err = s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Len(t, r.deliveries(), 1)
+}
+
+func TestDeliverPendingRetries(t *testing.T) {
+	r := newReceiver()
+	defer r.server.Close()
+	r.setStatus(http.StatusInternalServerError)
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := &memoryQ{
+		webhooks: map[int64]history.Webhook{
+			1: {ID: 1, URL: r.server.URL, Secret: "s3cr3t"},
+		},
+		deliveries: []history.WebhookDelivery{
+			{ID: 10, WebhookID: 1, Payload: json.RawMessage(`{}`), Status: history.WebhookDeliveryPending, NextAttemptAt: now},
+		},
+	}
+	s := newTestSystem(q, &now, Options{
+		MaxAttempts: 3,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+	})
+
+	_, err := s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	delivery := q.delivery(10)
+	assert.Equal(t, history.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, int32(1), delivery.Attempts)
+	assert.Equal(t, now.Add(time.Second), delivery.NextAttemptAt)
+	assert.Equal(t, int64(http.StatusInternalServerError), delivery.LastStatusCode.Int64)
+	assert.Equal(t, "unexpected response status 500", delivery.LastError.String)
+
+	// not due yet
+	n, err := s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	now = now.Add(time.Second)
+	_, err = s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	delivery = q.delivery(10)
+	assert.Equal(t, history.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, int32(2), delivery.Attempts)
+	assert.Equal(t, now.Add(2*time.Second), delivery.NextAttemptAt)
+
+	// the last attempt marks the delivery as failed
+	now = now.Add(2 * time.Second)
+	_, err = s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	delivery = q.delivery(10)
+	assert.Equal(t, history.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, int32(3), delivery.Attempts)
+
+	now = now.Add(time.Hour)
+	n, err = s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Len(t, r.deliveries(), 3)
+}
+
+func TestDeliverPendingRecovers(t *testing.T) {
+	r := newReceiver()
+	defer r.server.Close()
+	r.setStatus(http.StatusServiceUnavailable)
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	q := &memoryQ{
+		webhooks: map[int64]history.Webhook{
+			1: {ID: 1, URL: r.server.URL, Secret: "s3cr3t"},
+			2: {ID: 2, URL: "http://127.0.0.1:1/unreachable", Secret: "s3cr3t"},
+		},
+	}
+	for i := int64(1); i <= 4; i++ {
+		q.deliveries = append(q.deliveries, history.WebhookDelivery{
+			ID:            i,
+			WebhookID:     1 + i%2,
+			Payload:       json.RawMessage(`{"id":` + strconv.FormatInt(i, 10) + `}`),
+			Status:        history.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	s := newTestSystem(q, &now, Options{MinBackoff: time.Second})
+
+	n, err := s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	r.setStatus(http.StatusNoContent)
+	now = now.Add(time.Second)
+	n, err = s.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	for _, id := range []int64{2, 4} {
+		assert.Equal(t, history.WebhookDeliveryDelivered, q.delivery(id).Status)
+		assert.Equal(t, int32(2), q.delivery(id).Attempts)
+	}
+	for _, id := range []int64{1, 3} {
+		delivery := q.delivery(id)
+		assert.Equal(t, history.WebhookDeliveryPending, delivery.Status)
+		assert.False(t, delivery.LastStatusCode.Valid)
+		assert.True(t, delivery.LastError.Valid)
+	}
+}
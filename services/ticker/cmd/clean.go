@@ -10,10 +10,12 @@ import (
 )
 
 var DaysToKeep int
+var SnapshotDaysToKeep int
 
 func init() {
 	rootCmd.AddCommand(cmdClean)
 	cmdClean.AddCommand(cmdCleanTrades)
+	cmdClean.AddCommand(cmdCleanOrderbookSnapshots)
 
 	cmdCleanTrades.Flags().IntVarP(
 		&DaysToKeep,
@@ -22,6 +24,14 @@ func init() {
 		7,
 		"Trade entries older than keep-days will be deleted",
 	)
+
+	cmdCleanOrderbookSnapshots.Flags().IntVarP(
+		&SnapshotDaysToKeep,
+		"keep-days",
+		"k",
+		7,
+		"Orderbook snapshots older than keep-days will be deleted",
+	)
 }
 
 var cmdClean = &cobra.Command{
@@ -52,3 +62,27 @@ var cmdCleanTrades = &cobra.Command{
 		}
 	},
 }
+
+var cmdCleanOrderbookSnapshots = &cobra.Command{
+	Use:   "orderbook-snapshots",
+	Short: "Cleans up old orderbook depth snapshots from the database",
+	Run: func(cmd *cobra.Command, args []string) {
+		dbInfo, err := pq.ParseURL(DatabaseURL)
+		if err != nil {
+			Logger.Fatal("could not parse db-url:", err)
+		}
+
+		session, err := tickerdb.CreateSession("postgres", dbInfo)
+		if err != nil {
+			Logger.Fatal("could not connect to db:", err)
+		}
+
+		now := time.Now()
+		minDate := now.AddDate(0, 0, -SnapshotDaysToKeep)
+		Logger.Infof("Deleting orderbook snapshots older than %d days", SnapshotDaysToKeep)
+		err = session.DeleteOldOrderbookSnapshots(context.Background(), minDate)
+		if err != nil {
+			Logger.Fatal("could not delete orderbook snapshots:", err)
+		}
+	},
+}
@@ -2,21 +2,35 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
 	ticker "github.com/stellar/go/services/ticker/internal"
 	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/support/errors"
 )
 
 var ShouldStream bool
 var BackfillHours int
+var NetworkPassphrase string
+var HistoryArchiveURLs []string
+var CaptiveCoreBinaryPath string
+var CaptiveCoreConfigPath string
+var CaptiveCoreStoragePath string
+var RemoteCaptiveCoreURL string
+var BackfillLedgers uint32
+var OrderbookRefreshInterval time.Duration
 
 func init() {
 	rootCmd.AddCommand(cmdIngest)
 	cmdIngest.AddCommand(cmdIngestAssets)
 	cmdIngest.AddCommand(cmdIngestTrades)
 	cmdIngest.AddCommand(cmdIngestOrderbooks)
+	cmdIngest.AddCommand(cmdIngestLedgers)
 
 	cmdIngestTrades.Flags().BoolVar(
 		&ShouldStream,
@@ -31,6 +45,55 @@ func init() {
 		7*24,
 		"Number of past hours to backfill trade data",
 	)
+
+	cmdIngestLedgers.Flags().StringVar(
+		&NetworkPassphrase,
+		"network-passphrase",
+		getEnv("NETWORK_PASSPHRASE", ""),
+		"passphrase of the network, the Stellar Public or Test Network passphrase by default (see --testnet)",
+	)
+	cmdIngestLedgers.Flags().StringSliceVar(
+		&HistoryArchiveURLs,
+		"history-archive-urls",
+		nil,
+		"comma-separated list of history archive URLs, the first one is used to backfill new deployments",
+	)
+	cmdIngestLedgers.Flags().StringVar(
+		&CaptiveCoreBinaryPath,
+		"captive-core-binary-path",
+		getEnv("CAPTIVE_CORE_BINARY_PATH", ""),
+		"path to the stellar-core binary run as captive core",
+	)
+	cmdIngestLedgers.Flags().StringVar(
+		&CaptiveCoreConfigPath,
+		"captive-core-config-path",
+		getEnv("CAPTIVE_CORE_CONFIG_PATH", ""),
+		"path to the captive core configuration file",
+	)
+	cmdIngestLedgers.Flags().StringVar(
+		&CaptiveCoreStoragePath,
+		"captive-core-storage-path",
+		"",
+		"directory in which captive core stores its buckets, the current directory by default",
+	)
+	cmdIngestLedgers.Flags().StringVar(
+		&RemoteCaptiveCoreURL,
+		"remote-captive-core-url",
+		getEnv("REMOTE_CAPTIVE_CORE_URL", ""),
+		"URL of a remote captive core server to read ledgers from, instead of running captive core",
+	)
+	cmdIngestLedgers.Flags().Uint32Var(
+		&BackfillLedgers,
+		"backfill-ledgers",
+		7*24*720,
+		"Number of past ledgers to backfill when there is no ingest checkpoint in the database (about 7 days by default)",
+	)
+	cmdIngestLedgers.Flags().DurationVar(
+		&OrderbookRefreshInterval,
+		"orderbook-refresh-interval",
+		time.Minute,
+		"Interval at which the orderbook stats are computed from the ingested offers",
+	)
 }
 
 var cmdIngest = &cobra.Command{
@@ -121,3 +184,86 @@ var cmdIngestOrderbooks = &cobra.Command{
 		}
 	},
 }
+
+var cmdIngestLedgers = &cobra.Command{
+	Use:   "ledgers",
+	Short: "Continuously ingests trades and offers directly from ledgers, resuming from the last ingested ledger.",
+	Run: func(cmd *cobra.Command, args []string) {
+		dbInfo, err := pq.ParseURL(DatabaseURL)
+		if err != nil {
+			Logger.Fatal("could not parse db-url:", err)
+		}
+
+		session, err := tickerdb.CreateSession("postgres", dbInfo)
+		if err != nil {
+			Logger.Fatal("could not connect to db:", err)
+		}
+		defer session.DB.Close()
+
+		if len(HistoryArchiveURLs) == 0 {
+			Logger.Fatal("--history-archive-urls is required")
+		}
+		passphrase := NetworkPassphrase
+		if passphrase == "" {
+			passphrase = network.PublicNetworkPassphrase
+			if UseTestNet {
+				passphrase = network.TestNetworkPassphrase
+			}
+		}
+
+		ctx := context.Background()
+		archive, err := historyarchive.Connect(HistoryArchiveURLs[0], historyarchive.ConnectOptions{
+			Context:           ctx,
+			NetworkPassphrase: passphrase,
+		})
+		if err != nil {
+			Logger.Fatal("could not connect to history archive:", err)
+		}
+
+		var backend ledgerbackend.LedgerBackend
+		if RemoteCaptiveCoreURL != "" {
+			backend, err = ledgerbackend.NewRemoteCaptive(RemoteCaptiveCoreURL)
+		} else {
+			backend, err = newCaptiveCore(ctx, passphrase)
+		}
+		if err != nil {
+			Logger.Fatal("could not create ledger backend:", err)
+		}
+		defer backend.Close()
+
+		Logger.Info("Ingesting ledgers (this is a continuous process)")
+		err = ticker.IngestLedgers(ctx, &session, ticker.LedgerIngestConfig{
+			Backend:                  backend,
+			Archive:                  archive,
+			NetworkPassphrase:        passphrase,
+			BackfillLedgers:          BackfillLedgers,
+			OrderbookRefreshInterval: OrderbookRefreshInterval,
+		}, Logger)
+		if err != nil {
+			Logger.Fatal("could not ingest ledgers:", err)
+		}
+	},
+}
+
+func newCaptiveCore(ctx context.Context, passphrase string) (ledgerbackend.LedgerBackend, error) {
+	if CaptiveCoreBinaryPath == "" || CaptiveCoreConfigPath == "" {
+		return nil, errors.New("--captive-core-binary-path and --captive-core-config-path are required without --remote-captive-core-url")
+	}
+	toml, err := ledgerbackend.NewCaptiveCoreTomlFromFile(CaptiveCoreConfigPath, ledgerbackend.CaptiveCoreTomlParams{
+		NetworkPassphrase:  passphrase,
+		HistoryArchiveURLs: HistoryArchiveURLs,
+		Strict:             true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ledgerbackend.NewCaptive(ledgerbackend.CaptiveCoreConfig{
+		BinaryPath:         CaptiveCoreBinaryPath,
+		NetworkPassphrase:  passphrase,
+		HistoryArchiveURLs: HistoryArchiveURLs,
+		Toml:               toml,
+		Log:                Logger.WithField("subservice", "stellar-core"),
+		Context:            ctx,
+		StoragePath:        CaptiveCoreStoragePath,
+	})
+}
@@ -13,6 +13,7 @@ import (
 var DatabaseURL string
 var Client *horizonclient.Client
 var UseTestNet bool
+var HorizonURL string
 var Logger = hlog.New()
 
 var defaultDatabaseURL = getEnv("DB_URL", "postgres://localhost:5432/stellarticker01?sslmode=disable")
@@ -46,12 +47,21 @@ func init() {
 		false,
 		"use the Stellar Test Network, instead of the Stellar Public Network",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&HorizonURL,
+		"horizon-url",
+		getEnv("HORIZON_URL", ""),
+		"URL of the Horizon instance to scrape, instead of the SDF Horizon of the selected network",
+	)
 
 	Logger.SetLevel(logrus.DebugLevel)
 }
 
 func initConfig() {
-	if UseTestNet {
+	if HorizonURL != "" {
+		Logger.Debug("Using Horizon at ", HorizonURL)
+		Client = &horizonclient.Client{HorizonURL: HorizonURL}
+	} else if UseTestNet {
 		Logger.Debug("Using Stellar Default Test Network")
 		Client = horizonclient.DefaultTestNetClient
 	} else {
@@ -1,13 +1,20 @@
 package cmd
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/lib/pq"
 	"github.com/spf13/cobra"
 	ticker "github.com/stellar/go/services/ticker/internal"
+	"github.com/stellar/go/services/ticker/internal/refprice"
 	"github.com/stellar/go/services/ticker/internal/tickerdb"
 )
 
 var ServerAddr string
+var ReferencePriceURL string
+var MetalAssets string
+var ReferencePriceTTL time.Duration
 
 func init() {
 	rootCmd.AddCommand(cmdServe)
@@ -18,11 +25,29 @@ func init() {
 		"0.0.0.0:3000",
 		"Server address and port",
 	)
+	cmdServe.Flags().StringVar(
+		&ReferencePriceURL,
+		"reference-price-url",
+		getEnv("REFERENCE_PRICE_URL", ""),
+		"URL or file path of a JSON document with the USD spot prices of metals and currencies, used to compute the premiums of metal-backed assets",
+	)
+	cmdServe.Flags().StringVar(
+		&MetalAssets,
+		"metal-assets",
+		getEnv("METAL_ASSETS", "KAU=XAU:0.0321507466,KAG=XAG:1"),
+		"metal-backed asset codes, as CODE=METAL:TROY_OUNCES_PER_UNIT separated by commas",
+	)
+	cmdServe.Flags().DurationVar(
+		&ReferencePriceTTL,
+		"reference-price-ttl",
+		time.Minute,
+		"how long the reference prices are cached for",
+	)
 }
 
 var cmdServe = &cobra.Command{
 	Use:   "serve",
-	Short: "Runs a GraphQL interface to get Ticker data",
+	Short: "Runs a GraphQL interface and the exchange REST APIs to get Ticker data",
 	Run: func(cmd *cobra.Command, args []string) {
 		Logger.Info("Starting GraphQL Server")
 		dbInfo, err := pq.ParseURL(DatabaseURL)
@@ -36,6 +61,21 @@ var cmdServe = &cobra.Command{
 		}
 		defer session.DB.Close()
 
-		ticker.StartGraphQLServer(&session, Logger, ServerAddr)
+		var refPrices refprice.Source
+		metals, err := refprice.ParseMetals(MetalAssets)
+		if err != nil {
+			Logger.Fatal("could not parse metal-assets:", err)
+		}
+		if ReferencePriceURL != "" {
+			refPrices = &refprice.CachedSource{
+				Source: refprice.JSONSource{
+					Location: ReferencePriceURL,
+					Client:   &http.Client{Timeout: 10 * time.Second},
+				},
+				TTL: ReferencePriceTTL,
+			}
+		}
+
+		ticker.StartGraphQLServer(&session, Logger, ServerAddr, refPrices, metals)
 	},
 }
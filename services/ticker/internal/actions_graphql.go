@@ -1,13 +1,26 @@
 package ticker
 
 import (
+	"net/http"
+
+	"github.com/stellar/go/services/ticker/internal/exchangeapi"
 	"github.com/stellar/go/services/ticker/internal/gql"
+	"github.com/stellar/go/services/ticker/internal/refprice"
 	"github.com/stellar/go/services/ticker/internal/tickerdb"
 	hlog "github.com/stellar/go/support/log"
 )
 
-func StartGraphQLServer(s *tickerdb.TickerSession, l *hlog.Entry, port string) {
+// StartGraphQLServer serves the GraphQL interface and the exchange REST APIs.
+// The premiums of metal-backed assets are only served if refPrices is set.
+func StartGraphQLServer(s *tickerdb.TickerSession, l *hlog.Entry, port string, refPrices refprice.Source, metals map[string]refprice.Metal) {
 	graphql := gql.New(s, l)
+	if refPrices != nil {
+		graphql.UseReferencePrices(refPrices, metals)
+	}
+	api := exchangeapi.New(s, l)
 
-	graphql.Serve(port)
+	graphql.Serve(port, map[string]http.Handler{
+		"/coingecko/": api,
+		"/cmc/":       api,
+	})
 }
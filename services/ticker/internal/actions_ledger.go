@@ -0,0 +1,261 @@
+package ticker
+
+import (
+	"context"
+	"time"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/ticker/internal/ingester"
+	"github.com/stellar/go/services/ticker/internal/scraper"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/services/ticker/internal/utils"
+	"github.com/stellar/go/support/errors"
+	hlog "github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// LedgerIngestCheckpoint is the name of the checkpoint of the ledger
+// ingestion in the ingest_checkpoints table.
+const LedgerIngestCheckpoint = "ledgers"
+
+// checkpointBatchSize is the number of offers or liquidity pools inserted at
+// once when loading the orderbook from a history archive checkpoint.
+const checkpointBatchSize = 1000
+
+// LedgerIngestConfig configures the ingestion of trades, offers and liquidity pools from ledgers.
+type LedgerIngestConfig struct {
+	Backend           ledgerbackend.LedgerBackend
+	Archive           historyarchive.ArchiveInterface
+	NetworkPassphrase string
+	// BackfillLedgers is the number of ledgers before the latest history
+	// archive checkpoint ingested by new deployments.
+	BackfillLedgers uint32
+	// OrderbookRefreshInterval is the interval at which the orderbook stats
+	// are computed from the ingested offers.
+	OrderbookRefreshInterval time.Duration
+}
+
+// IngestLedgers constantly ingests the trades, offers and liquidity pools of new ledgers. It
+// resumes from the last ledger stored in the database, or starts from a
+// history archive checkpoint for new deployments. Each ledger is stored in a
+// single transaction along with the checkpoint so that ingestion resumes
+// exactly where it stopped.
+func IngestLedgers(ctx context.Context, s *tickerdb.TickerSession, c LedgerIngestConfig, l *hlog.Entry) error {
+	sequence, found, err := s.GetIngestCheckpoint(ctx, LedgerIngestCheckpoint)
+	if err != nil {
+		return errors.Wrap(err, "could not get ingest checkpoint")
+	}
+	if found {
+		l.Infof("Resuming ingestion after ledger %d", sequence)
+	} else {
+		sequence, err = backfillOffers(ctx, s, c, l)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.Backend.PrepareRange(ctx, ledgerbackend.UnboundedRange(sequence+1))
+	if err != nil {
+		return errors.Wrap(err, "could not prepare ledger range")
+	}
+
+	var lastRefresh time.Time
+	for sequence++; ; sequence++ {
+		meta, err := c.Backend.GetLedger(ctx, sequence)
+		if err != nil {
+			return errors.Wrapf(err, "could not get ledger %d", sequence)
+		}
+		if err = ingestLedger(ctx, s, c.NetworkPassphrase, meta, l); err != nil {
+			return errors.Wrapf(err, "could not ingest ledger %d", sequence)
+		}
+
+		if time.Since(lastRefresh) >= c.OrderbookRefreshInterval {
+			if err = RefreshOrderbookEntriesFromOffers(ctx, s, l); err != nil {
+				l.Error(errors.Wrap(err, "could not refresh orderbook stats"))
+			}
+			lastRefresh = time.Now()
+		}
+	}
+}
+
+// backfillOffers loads the orderbook and liquidity pools of the history archive
+// checkpoint BackfillLedgers before the latest one and returns its sequence. The
+// trades of the following ledgers are backfilled as they are ingested.
+func backfillOffers(ctx context.Context, s *tickerdb.TickerSession, c LedgerIngestConfig, l *hlog.Entry) (uint32, error) {
+	root, err := c.Archive.GetRootHAS()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get history archive state")
+	}
+	manager := c.Archive.GetCheckpointManager()
+	sequence := manager.PrevCheckpoint(0)
+	if root.CurrentLedger > c.BackfillLedgers {
+		sequence = manager.PrevCheckpoint(root.CurrentLedger - c.BackfillLedgers)
+	}
+	l.Infof("Loading the orderbook of checkpoint ledger %d", sequence)
+
+	if err = s.Begin(); err != nil {
+		return 0, errors.Wrap(err, "could not start transaction")
+	}
+	defer s.Rollback()
+
+	if err = s.DeleteAllOffers(ctx); err != nil {
+		return 0, errors.Wrap(err, "could not clear offers")
+	}
+	if err = s.DeleteAllLiquidityPools(ctx); err != nil {
+		return 0, errors.Wrap(err, "could not clear liquidity pools")
+	}
+	numOffers, numPools := 0, 0
+	err = ingester.CheckpointEntries(ctx, c.Archive, sequence, checkpointBatchSize,
+		func(offers []tickerdb.Offer) error {
+			numOffers += len(offers)
+			return s.BulkUpsertOffers(ctx, offers)
+		},
+		func(pools []hProtocol.LiquidityPool) error {
+			dbPools, err := liquidityPoolsToDBLiquidityPools(ctx, s, pools)
+			if err != nil {
+				return err
+			}
+			numPools += len(dbPools)
+			return upsertLiquidityPools(ctx, s, dbPools)
+		},
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not load checkpoint entries")
+	}
+	if err = s.UpdateIngestCheckpoint(ctx, LedgerIngestCheckpoint, sequence); err != nil {
+		return 0, errors.Wrap(err, "could not update ingest checkpoint")
+	}
+	if err = s.Commit(); err != nil {
+		return 0, errors.Wrap(err, "could not commit checkpoint offers")
+	}
+
+	l.Infof("Loaded %d offers and %d liquidity pools, backfilling trades from ledger %d", numOffers, numPools, sequence+1)
+	return sequence, nil
+}
+
+// ingestLedger stores the trades, offer and liquidity pool changes of a ledger.
+func ingestLedger(ctx context.Context, s *tickerdb.TickerSession, networkPassphrase string, meta xdr.LedgerCloseMeta, l *hlog.Entry) error {
+	trades, err := ingester.LedgerTrades(networkPassphrase, meta)
+	if err != nil {
+		return err
+	}
+	updated, removed, err := ingester.LedgerOffers(networkPassphrase, meta)
+	if err != nil {
+		return err
+	}
+	updatedPools, removedPools, err := ingester.LedgerLiquidityPools(networkPassphrase, meta)
+	if err != nil {
+		return err
+	}
+	dbPools, err := liquidityPoolsToDBLiquidityPools(ctx, s, updatedPools)
+	if err != nil {
+		return err
+	}
+
+	var dbTrades []tickerdb.Trade
+	for _, trade := range trades {
+		scraper.NormalizeTradeAssets(&trade)
+		bID, cID, err := findBaseAndCounter(ctx, s, trade)
+		if err == errAssetNotFound {
+			// trades of assets which were not ingested are ignored, as
+			// when scraping trades from Horizon.
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not find the assets of a trade")
+		}
+		dbTrade, err := hProtocolTradeToDBTrade(trade, bID, cID)
+		if err != nil {
+			l.Error("Could not convert entry to DB Trade: ", err)
+			continue
+		}
+		dbTrades = append(dbTrades, dbTrade)
+	}
+
+	if err = s.Begin(); err != nil {
+		return errors.Wrap(err, "could not start transaction")
+	}
+	defer s.Rollback()
+
+	if err = s.BulkInsertTrades(ctx, dbTrades); err != nil {
+		return errors.Wrap(err, "could not insert trades")
+	}
+	if err = s.BulkUpsertOffers(ctx, updated); err != nil {
+		return errors.Wrap(err, "could not update offers")
+	}
+	if err = s.DeleteOffers(ctx, removed); err != nil {
+		return errors.Wrap(err, "could not delete offers")
+	}
+	if err = upsertLiquidityPools(ctx, s, dbPools); err != nil {
+		return errors.Wrap(err, "could not update liquidity pools")
+	}
+	if err = s.DeleteLiquidityPools(ctx, removedPools); err != nil {
+		return errors.Wrap(err, "could not delete liquidity pools")
+	}
+	if err = s.UpdateIngestCheckpoint(ctx, LedgerIngestCheckpoint, meta.LedgerSequence()); err != nil {
+		return errors.Wrap(err, "could not update ingest checkpoint")
+	}
+	if err = s.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit ledger")
+	}
+
+	if len(dbTrades) > 0 {
+		l.Infof("Ledger %d: %d trade(s), %d offer(s) updated, %d removed", meta.LedgerSequence(), len(dbTrades), len(updated), len(removed))
+	}
+	return nil
+}
+
+// RefreshOrderbookEntriesFromOffers updates the orderbook entries for the relevant markets
+// that were active in the past 7-day interval from the offers and liquidity pools ingested
+// from ledgers, and records a snapshot of their depth.
+func RefreshOrderbookEntriesFromOffers(ctx context.Context, s *tickerdb.TickerSession, l *hlog.Entry) error {
+	mkts, err := s.Retrieve7DRelevantMarkets(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not retrieve partial markets")
+	}
+
+	for _, mkt := range mkts {
+		base := utils.GetAssetString(mkt.BaseAssetType, mkt.BaseAssetCode, mkt.BaseAssetIssuer)
+		counter := utils.GetAssetString(mkt.CounterAssetType, mkt.CounterAssetCode, mkt.CounterAssetIssuer)
+		offers, err := s.GetMarketOffers(ctx, base, counter)
+		if err != nil {
+			return errors.Wrap(err, "could not retrieve market offers")
+		}
+
+		ob, err := scraper.OrderbookStatsFromSummary(
+			mkt.BaseAssetType,
+			mkt.BaseAssetCode,
+			mkt.BaseAssetIssuer,
+			mkt.CounterAssetType,
+			mkt.CounterAssetCode,
+			mkt.CounterAssetIssuer,
+			ingester.OrderbookSummary(offers, base, counter),
+		)
+		if err != nil {
+			l.Error(errors.Wrap(err, "could not calculate orderbook stats"))
+			continue
+		}
+
+		pools, err := s.GetMarketLiquidityPools(ctx, mkt.BaseAssetID, mkt.CounterAssetID)
+		if err != nil {
+			return errors.Wrap(err, "could not retrieve market liquidity pools")
+		}
+		scraper.AddLiquidityPoolQuotes(&ob, dbLiquidityPoolsToLiquidityPoolStats(pools))
+
+		dbOS := orderbookStatsToDBOrderbookStats(ob, mkt.BaseAssetID, mkt.CounterAssetID)
+		err = s.InsertOrUpdateOrderbookStats(ctx, &dbOS, []string{"base_asset_id", "counter_asset_id"})
+		if err != nil {
+			l.Error(errors.Wrap(err, "could not insert orderbook stats into db"))
+		}
+
+		dbSnapshot := orderbookStatsToDBOrderbookSnapshot(ob, mkt.BaseAssetID, mkt.CounterAssetID)
+		err = s.InsertOrderbookSnapshot(ctx, &dbSnapshot)
+		if err != nil {
+			l.Error(errors.Wrap(err, "could not insert orderbook snapshot into db"))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package ticker
+
+import (
+	"context"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/ticker/internal/scraper"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/support/errors"
+)
+
+// liquidityPoolsToDBLiquidityPools converts Horizon liquidity pools into tickerdb.LiquidityPool
+// entries. Pools of assets which were not ingested are ignored, as are their trades.
+func liquidityPoolsToDBLiquidityPools(ctx context.Context, s *tickerdb.TickerSession, pools []hProtocol.LiquidityPool) ([]tickerdb.LiquidityPool, error) {
+	var dbPools []tickerdb.LiquidityPool
+	for _, p := range pools {
+		pool, err := scraper.NormalizeLiquidityPool(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid liquidity pool %s", p.ID)
+		}
+
+		bFound, bID, err := s.GetAssetByCodeAndIssuerAccount(ctx, pool.BaseAssetCode, pool.BaseAssetIssuer)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not retrieve base asset")
+		}
+		cFound, cID, err := s.GetAssetByCodeAndIssuerAccount(ctx, pool.CounterAssetCode, pool.CounterAssetIssuer)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not retrieve counter asset")
+		}
+		if !bFound || !cFound {
+			continue
+		}
+		dbPools = append(dbPools, liquidityPoolStatsToDBLiquidityPool(pool, bID, cID))
+	}
+	return dbPools, nil
+}
+
+func liquidityPoolStatsToDBLiquidityPool(p scraper.LiquidityPoolStats, bID, cID int32) tickerdb.LiquidityPool {
+	return tickerdb.LiquidityPool{
+		PoolID:          p.PoolID,
+		BaseAssetID:     bID,
+		CounterAssetID:  cID,
+		FeeBP:           p.FeeBP,
+		BaseReserve:     p.BaseReserve,
+		CounterReserve:  p.CounterReserve,
+		TotalShares:     p.TotalShares,
+		TotalTrustlines: p.TotalTrustlines,
+		UpdatedAt:       time.Now(),
+	}
+}
+
+func dbLiquidityPoolsToLiquidityPoolStats(pools []tickerdb.LiquidityPool) []scraper.LiquidityPoolStats {
+	stats := make([]scraper.LiquidityPoolStats, len(pools))
+	for i, p := range pools {
+		stats[i] = scraper.LiquidityPoolStats{
+			PoolID:          p.PoolID,
+			FeeBP:           p.FeeBP,
+			BaseReserve:     p.BaseReserve,
+			CounterReserve:  p.CounterReserve,
+			TotalShares:     p.TotalShares,
+			TotalTrustlines: p.TotalTrustlines,
+		}
+	}
+	return stats
+}
+
+// upsertLiquidityPools inserts or updates liquidity pools on the database.
+func upsertLiquidityPools(ctx context.Context, s *tickerdb.TickerSession, pools []tickerdb.LiquidityPool) error {
+	for _, p := range pools {
+		if err := s.InsertOrUpdateLiquidityPool(ctx, &p, []string{"pool_id"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
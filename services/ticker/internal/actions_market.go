@@ -65,6 +65,7 @@ func dbMarketToMarketStats(m tickerdb.Market) MarketStats {
 	closeTime := utils.TimeToRFC3339(m.LastPriceCloseTime)
 
 	spread, spreadMidPoint := utils.CalcSpread(m.HighestBid, m.LowestAsk)
+	pool := tickerdb.LiquidityPool{BaseReserve: m.PoolBaseReserve, CounterReserve: m.PoolCounterReserve}
 	return MarketStats{
 		TradePairName:    m.TradePair,
 		BaseVolume24h:    m.BaseVolume24h,
@@ -92,5 +93,10 @@ func dbMarketToMarketStats(m tickerdb.Market) MarketStats {
 		Spread:           spread,
 		SpreadMidPoint:   spreadMidPoint,
 		CloseTime:        closeTime,
+
+		PoolPrice:          pool.Price(),
+		PoolBaseReserve:    pool.BaseReserve,
+		PoolCounterReserve: pool.CounterReserve,
+		PoolTVL:            pool.TVL(),
 	}
 }
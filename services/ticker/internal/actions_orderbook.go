@@ -11,8 +11,8 @@ import (
 	hlog "github.com/stellar/go/support/log"
 )
 
-// RefreshOrderbookEntries updates the orderbook entries for the relevant markets that were active
-// in the past 7-day interval
+// RefreshOrderbookEntries updates the orderbook entries and liquidity pools for the relevant markets
+// that were active in the past 7-day interval, and records a snapshot of their depth
 func RefreshOrderbookEntries(s *tickerdb.TickerSession, c *horizonclient.Client, l *hlog.Entry) error {
 	sc := scraper.ScraperConfig{
 		Client: c,
@@ -40,11 +40,38 @@ func RefreshOrderbookEntries(s *tickerdb.TickerSession, c *horizonclient.Client,
 			continue
 		}
 
+		pools, err := sc.FetchLiquidityPoolsForAssets(
+			mkt.BaseAssetType,
+			mkt.BaseAssetCode,
+			mkt.BaseAssetIssuer,
+			mkt.CounterAssetType,
+			mkt.CounterAssetCode,
+			mkt.CounterAssetIssuer,
+		)
+		if err != nil {
+			l.Error(errors.Wrap(err, "could not fetch liquidity pools for assets"))
+		}
+		dbPools := make([]tickerdb.LiquidityPool, len(pools))
+		for i, p := range pools {
+			dbPools[i] = liquidityPoolStatsToDBLiquidityPool(p, mkt.BaseAssetID, mkt.CounterAssetID)
+		}
+		err = upsertLiquidityPools(ctx, s, dbPools)
+		if err != nil {
+			l.Error(errors.Wrap(err, "could not insert liquidity pools into db"))
+		}
+		scraper.AddLiquidityPoolQuotes(&ob, pools)
+
 		dbOS := orderbookStatsToDBOrderbookStats(ob, mkt.BaseAssetID, mkt.CounterAssetID)
 		err = s.InsertOrUpdateOrderbookStats(ctx, &dbOS, []string{"base_asset_id", "counter_asset_id"})
 		if err != nil {
 			l.Error(errors.Wrap(err, "could not insert orderbook stats into db"))
 		}
+
+		dbSnapshot := orderbookStatsToDBOrderbookSnapshot(ob, mkt.BaseAssetID, mkt.CounterAssetID)
+		err = s.InsertOrderbookSnapshot(ctx, &dbSnapshot)
+		if err != nil {
+			l.Error(errors.Wrap(err, "could not insert orderbook snapshot into db"))
+		}
 	}
 
 	return nil
@@ -65,3 +92,20 @@ func orderbookStatsToDBOrderbookStats(os scraper.OrderbookStats, bID, cID int32)
 		UpdatedAt:      time.Now(),
 	}
 }
+
+func orderbookStatsToDBOrderbookSnapshot(os scraper.OrderbookStats, bID, cID int32) tickerdb.OrderbookSnapshot {
+	levels := func(obLevels []scraper.OrderbookLevel) tickerdb.DepthLevels {
+		dbLevels := make(tickerdb.DepthLevels, len(obLevels))
+		for i, level := range obLevels {
+			dbLevels[i] = tickerdb.DepthLevel{Price: level.Price, Amount: level.Amount}
+		}
+		return dbLevels
+	}
+	return tickerdb.OrderbookSnapshot{
+		BaseAssetID:    bID,
+		CounterAssetID: cID,
+		Bids:           levels(os.Bids),
+		Asks:           levels(os.Asks),
+		CreatedAt:      time.Now(),
+	}
+}
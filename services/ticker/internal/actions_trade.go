@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	horizonclient "github.com/stellar/go/clients/horizonclient"
@@ -13,9 +14,16 @@ import (
 	"github.com/stellar/go/services/ticker/internal/scraper"
 	"github.com/stellar/go/services/ticker/internal/tickerdb"
 	hlog "github.com/stellar/go/support/log"
+	"github.com/stellar/go/toid"
 )
 
+// TradesIngestCheckpoint is the name of the checkpoint of the trades
+// streamed from Horizon in the ingest_checkpoints table.
+const TradesIngestCheckpoint = "horizon_trades"
+
 // StreamTrades constantly streams and ingests new trades directly from horizon.
+// The paging token of the last streamed trade is stored along with the trade,
+// so that streaming resumes exactly where it stopped after a restart.
 func StreamTrades(
 	ctx context.Context,
 	s *tickerdb.TickerSession,
@@ -29,32 +37,61 @@ func StreamTrades(
 	}
 	handler := func(trade hProtocol.Trade) {
 		l.Infof("New trade arrived. ID: %v; Close Time: %v\n", trade.ID, trade.LedgerCloseTime)
-		scraper.NormalizeTradeAssets(&trade)
-		bID, cID, err := findBaseAndCounter(ctx, s, trade)
-		if err != nil {
+		if err := ingestStreamedTrade(ctx, s, trade); err != nil {
 			l.Error(err)
-			return
 		}
-		dbTrade, err := hProtocolTradeToDBTrade(trade, bID, cID)
-		if err != nil {
-			l.Error(err)
-			return
+	}
+
+	// Ensure we start streaming after the last streamed trade, or from the
+	// last stored trade when no trade was streamed yet
+	cursor, err := s.GetIngestPagingToken(ctx, TradesIngestCheckpoint)
+	if err != nil {
+		return err
+	}
+	if cursor == "" {
+		lastTrade, err := s.GetLastTrade(ctx)
+		if err != nil && !s.NoRows(err) {
+			return err
 		}
+		cursor = lastTrade.HorizonID
+	}
+	return sc.StreamNewTrades(cursor, handler)
+}
 
-		err = s.BulkInsertTrades(ctx, []tickerdb.Trade{dbTrade})
+// ingestStreamedTrade stores a trade streamed from Horizon along with its
+// paging token. Trades of assets which were not ingested are skipped.
+func ingestStreamedTrade(ctx context.Context, s *tickerdb.TickerSession, trade hProtocol.Trade) error {
+	var dbTrades []tickerdb.Trade
+	scraper.NormalizeTradeAssets(&trade)
+	bID, cID, err := findBaseAndCounter(ctx, s, trade)
+	if err == nil {
+		dbTrade, err := hProtocolTradeToDBTrade(trade, bID, cID)
 		if err != nil {
-			l.Error("Could not insert trade in database: ", trade.ID)
+			return err
 		}
+		dbTrades = append(dbTrades, dbTrade)
+	} else if err != errAssetNotFound {
+		return fmt.Errorf("could not find the assets of trade %s: %w", trade.ID, err)
 	}
 
-	// Ensure we start streaming from the last stored trade
-	lastTrade, err := s.GetLastTrade(ctx)
+	operationID, err := strconv.ParseInt(strings.SplitN(trade.PagingToken(), "-", 2)[0], 10, 64)
 	if err != nil {
+		return fmt.Errorf("invalid paging token of trade %s: %w", trade.ID, err)
+	}
+	sequence := uint32(toid.Parse(operationID).LedgerSequence)
+
+	if err = s.Begin(); err != nil {
 		return err
 	}
+	defer s.Rollback()
 
-	cursor := lastTrade.HorizonID
-	return sc.StreamNewTrades(cursor, handler)
+	if err = s.BulkInsertTrades(ctx, dbTrades); err != nil {
+		return fmt.Errorf("could not insert trade %s in database: %w", trade.ID, err)
+	}
+	if err = s.UpdateIngestPagingToken(ctx, TradesIngestCheckpoint, sequence, trade.PagingToken()); err != nil {
+		return err
+	}
+	return s.Commit()
 }
 
 // BackfillTrades ingest the most recent trades (limited to numDays) directly from Horizon
@@ -105,6 +142,10 @@ func BackfillTrades(
 	return nil
 }
 
+// errAssetNotFound is returned by findBaseAndCounter when the base or the
+// counter asset of a trade is not in the database.
+var errAssetNotFound = errors.New("base or counter asset not found")
+
 // findBaseAndCounter tries to find the Base and Counter assets IDs in the database,
 // and returns an error if it doesn't find any.
 func findBaseAndCounter(ctx context.Context, s *tickerdb.TickerSession, trade hProtocol.Trade) (bID int32, cID int32, err error) {
@@ -127,7 +168,7 @@ func findBaseAndCounter(ctx context.Context, s *tickerdb.TickerSession, trade hP
 	}
 
 	if !bFound || !cFound {
-		err = errors.New("base or counter asset no found")
+		err = errAssetNotFound
 		return
 	}
 
@@ -149,6 +190,12 @@ func hProtocolTradeToDBTrade(
 		return
 	}
 
+	// trades streamed from older Horizon versions have no trade type
+	tradeType := hpt.TradeType
+	if tradeType == "" {
+		tradeType = tickerdb.TradeTypeOrderbook
+	}
+
 	rPrice := big.NewRat(int64(hpt.Price.D), int64(hpt.Price.N))
 	fPrice, _ := rPrice.Float64()
 
@@ -166,6 +213,11 @@ func hProtocolTradeToDBTrade(
 		CounterAssetID:  counterAssetID,
 		BaseIsSeller:    hpt.BaseIsSeller,
 		Price:           fPrice,
+
+		TradeType:              tradeType,
+		BaseLiquidityPoolID:    hpt.BaseLiquidityPoolID,
+		CounterLiquidityPoolID: hpt.CounterLiquidityPoolID,
+		LiquidityPoolFeeBP:     hpt.LiquidityPoolFeeBP,
 	}
 
 	return
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -36,3 +37,26 @@ func TestProtocolTradeToDBTrade_priceMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestProtocolTradeToDBTrade_liquidityPool(t *testing.T) {
+	hpt := hProtocol.Trade{
+		BaseAmount:          "0",
+		CounterAmount:       "0",
+		Price:               hProtocol.TradePrice{N: 1, D: 1},
+		TradeType:           "liquidity_pool",
+		BaseLiquidityPoolID: "abcdef",
+		LiquidityPoolFeeBP:  30,
+	}
+	dbTrade, err := hProtocolTradeToDBTrade(hpt, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, tickerdb.TradeTypeLiquidityPool, dbTrade.TradeType)
+	assert.Equal(t, "abcdef", dbTrade.BaseLiquidityPoolID)
+	assert.Equal(t, "", dbTrade.CounterLiquidityPoolID)
+	assert.Equal(t, uint32(30), dbTrade.LiquidityPoolFeeBP)
+
+	// trades without a type are orderbook trades
+	hpt.TradeType = ""
+	dbTrade, err = hProtocolTradeToDBTrade(hpt, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, tickerdb.TradeTypeOrderbook, dbTrade.TradeType)
+}
@@ -0,0 +1,191 @@
+package exchangeapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/services/ticker/internal/utils"
+)
+
+// coingeckoOrderbook is the orderbook depth in the CoinGecko exchange API
+// format.
+type coingeckoOrderbook struct {
+	TickerID  string      `json:"ticker_id"`
+	Timestamp string      `json:"timestamp"`
+	Bids      [][2]string `json:"bids"`
+	Asks      [][2]string `json:"asks"`
+}
+
+// coingeckoCandle is a candle with the volume naming of the CoinGecko
+// exchange API, where the counter asset is the target currency.
+type coingeckoCandle struct {
+	Timestamp    int64   `json:"timestamp"`
+	Open         float64 `json:"open"`
+	High         float64 `json:"high"`
+	Low          float64 `json:"low"`
+	Close        float64 `json:"close"`
+	BaseVolume   float64 `json:"base_volume"`
+	TargetVolume float64 `json:"target_volume"`
+	TradeCount   int32   `json:"trade_count"`
+}
+
+type coingeckoCandles struct {
+	TickerID   string            `json:"ticker_id"`
+	Resolution string            `json:"resolution"`
+	Candles    []coingeckoCandle `json:"candles"`
+}
+
+// cmcOrderbook is the orderbook depth in the CoinMarketCap exchange API
+// format.
+type cmcOrderbook struct {
+	Timestamp int64       `json:"timestamp"`
+	Bids      [][2]string `json:"bids"`
+	Asks      [][2]string `json:"asks"`
+}
+
+// cmcCandle is a candle with the volume naming of the CoinMarketCap exchange
+// API, where the counter asset is the quote currency.
+type cmcCandle struct {
+	Timestamp   int64   `json:"timestamp"`
+	Open        float64 `json:"open"`
+	High        float64 `json:"high"`
+	Low         float64 `json:"low"`
+	Close       float64 `json:"close"`
+	BaseVolume  float64 `json:"base_volume"`
+	QuoteVolume float64 `json:"quote_volume"`
+	TradeCount  int32   `json:"trade_count"`
+}
+
+type cmcCandles struct {
+	MarketPair string      `json:"market_pair"`
+	Resolution string      `json:"resolution"`
+	Candles    []cmcCandle `json:"candles"`
+}
+
+func (h *Handler) coingeckoOrderbook(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := parseOrderbookParams(q.Get("ticker_id"), q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	snapshot, status, err := h.snapshot(r.Context(), p)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, coingeckoOrderbook{
+		TickerID:  p.market.pairID,
+		Timestamp: strconv.FormatInt(utils.TimeToUnixEpoch(snapshot.CreatedAt), 10),
+		Bids:      formatLevels(truncateLevels(snapshot.Bids, p.depth)),
+		Asks:      formatLevels(truncateLevels(snapshot.Asks, p.depth)),
+	})
+}
+
+func (h *Handler) coingeckoCandles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := parseCandleParams(q.Get("ticker_id"), q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	candles, status, err := h.candles(r.Context(), p)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	resp := coingeckoCandles{
+		TickerID:   p.market.pairID,
+		Resolution: q.Get("resolution"),
+		Candles:    make([]coingeckoCandle, len(candles)),
+	}
+	for i, c := range candles {
+		resp.Candles[i] = coingeckoCandle{
+			Timestamp:    utils.TimeToUnixEpoch(c.StartTime),
+			Open:         c.Open,
+			High:         c.High,
+			Low:          c.Low,
+			Close:        c.Close,
+			BaseVolume:   c.BaseVolume,
+			TargetVolume: c.CounterVolume,
+			TradeCount:   c.TradeCount,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) cmcOrderbook(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := parseOrderbookParams(strings.TrimPrefix(r.URL.Path, "/cmc/orderbook/"), q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// level 1 is the best bid and ask, level 2 the orderbook aggregated by
+	// price. snapshots are aggregated, so level 3 is not supported.
+	level, err := parseInt(q, "level", 2)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	switch level {
+	case 1:
+		p.depth = 2
+	case 2:
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("level must be 1 or 2"))
+		return
+	}
+
+	snapshot, status, err := h.snapshot(r.Context(), p)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, cmcOrderbook{
+		Timestamp: utils.TimeToUnixEpoch(snapshot.CreatedAt),
+		Bids:      formatLevels(truncateLevels(snapshot.Bids, p.depth)),
+		Asks:      formatLevels(truncateLevels(snapshot.Asks, p.depth)),
+	})
+}
+
+func (h *Handler) cmcCandles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	p, err := parseCandleParams(strings.TrimPrefix(r.URL.Path, "/cmc/candles/"), q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	candles, status, err := h.candles(r.Context(), p)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+
+	resp := cmcCandles{
+		MarketPair: p.market.pairID,
+		Resolution: q.Get("resolution"),
+		Candles:    make([]cmcCandle, len(candles)),
+	}
+	for i, c := range candles {
+		resp.Candles[i] = cmcCandle{
+			Timestamp:   utils.TimeToUnixEpoch(c.StartTime),
+			Open:        c.Open,
+			High:        c.High,
+			Low:         c.Low,
+			Close:       c.Close,
+			BaseVolume:  c.BaseVolume,
+			QuoteVolume: c.CounterVolume,
+			TradeCount:  c.TradeCount,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,266 @@
+// Package exchangeapi serves the candles and orderbook depth snapshots of the
+// ticker in the formats of the CoinGecko and CoinMarketCap exchange APIs.
+//
+// Markets are identified by a pair ID such as `XLM_USD:GABC...`, where each
+// asset is either `CODE:ISSUER` or `XLM` for the native asset. Timestamps are
+// Unix epochs in milliseconds.
+package exchangeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/services/ticker/internal/utils"
+	hlog "github.com/stellar/go/support/log"
+)
+
+var errRetrieveData = errors.New("could not retrieve the requested data")
+var errMarketNotFound = errors.New("market not found")
+var errSnapshotNotFound = errors.New("no orderbook snapshot found")
+
+// Handler serves the exchange API endpoints:
+//
+//	GET /coingecko/orderbook?ticker_id=&depth=&timestamp=
+//	GET /coingecko/candles?ticker_id=&resolution=&from=&to=
+//	GET /cmc/orderbook/{market_pair}?depth=&level=&timestamp=
+//	GET /cmc/candles/{market_pair}?resolution=&from=&to=
+type Handler struct {
+	db     *tickerdb.TickerSession
+	logger *hlog.Entry
+	mux    *http.ServeMux
+}
+
+// New creates a new exchange API handler
+func New(s *tickerdb.TickerSession, l *hlog.Entry) *Handler {
+	h := &Handler{db: s, logger: l, mux: http.NewServeMux()}
+	h.mux.HandleFunc("/coingecko/orderbook", h.coingeckoOrderbook)
+	h.mux.HandleFunc("/coingecko/candles", h.coingeckoCandles)
+	h.mux.HandleFunc("/cmc/orderbook/", h.cmcOrderbook)
+	h.mux.HandleFunc("/cmc/candles/", h.cmcCandles)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+// market represents the base and counter assets of a market.
+type market struct {
+	pairID             string
+	baseAssetCode      string
+	baseAssetIssuer    string
+	counterAssetCode   string
+	counterAssetIssuer string
+}
+
+// parsePairID parses a pair ID in the `BASE_COUNTER` format, where each asset
+// is either `CODE:ISSUER` or `XLM` for the native asset.
+func parsePairID(pairID string) (m market, err error) {
+	assets := strings.Split(pairID, "_")
+	if len(assets) != 2 {
+		return m, errors.New("invalid pair id, must be BASE_COUNTER")
+	}
+	m.pairID = pairID
+	m.baseAssetCode, m.baseAssetIssuer, err = parseAsset(assets[0])
+	if err != nil {
+		return
+	}
+	m.counterAssetCode, m.counterAssetIssuer, err = parseAsset(assets[1])
+	return
+}
+
+// parseAsset parses an asset of a pair ID. The native asset is stored with
+// the XLM code and the "native" issuer.
+func parseAsset(asset string) (code, issuer string, err error) {
+	if asset == "XLM" {
+		return "XLM", "native", nil
+	}
+	parts := strings.Split(asset, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.New("invalid asset, must be CODE:ISSUER or XLM")
+	}
+	return parts[0], parts[1], nil
+}
+
+// orderbookParams are the parameters of the orderbook endpoints.
+type orderbookParams struct {
+	market market
+	// depth is the number of levels returned on both sides, 0 for all of them
+	depth int
+	at    time.Time
+}
+
+func parseOrderbookParams(pairID string, q map[string][]string) (p orderbookParams, err error) {
+	p.market, err = parsePairID(pairID)
+	if err != nil {
+		return
+	}
+	p.depth, err = parseInt(q, "depth", 0)
+	if err != nil {
+		return
+	}
+	if p.depth < 0 {
+		return p, errors.New("depth cannot be negative")
+	}
+	p.at, err = parseTimestamp(q, "timestamp", time.Now())
+	return
+}
+
+// candleParams are the parameters of the candle endpoints.
+type candleParams struct {
+	market     market
+	resolution time.Duration
+	from       time.Time
+	to         time.Time
+}
+
+func parseCandleParams(pairID string, q map[string][]string) (p candleParams, err error) {
+	p.market, err = parsePairID(pairID)
+	if err != nil {
+		return
+	}
+	p.resolution, err = utils.ParseCandleResolution(first(q, "resolution"))
+	if err != nil {
+		return
+	}
+	if first(q, "from") == "" {
+		return p, errors.New("from is required")
+	}
+	p.from, err = parseTimestamp(q, "from", time.Time{})
+	if err != nil {
+		return
+	}
+	p.to, err = parseTimestamp(q, "to", time.Now())
+	if err != nil {
+		return
+	}
+	err = utils.ValidateCandleRange(p.from, p.to, p.resolution)
+	return
+}
+
+func first(q map[string][]string, name string) string {
+	if values := q[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func parseInt(q map[string][]string, name string, def int) (int, error) {
+	v := first(q, name)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, errors.New(name + " must be an integer")
+	}
+	return i, nil
+}
+
+func parseTimestamp(q map[string][]string, name string, def time.Time) (time.Time, error) {
+	v := first(q, name)
+	if v == "" {
+		return def, nil
+	}
+	millis, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || millis < 0 {
+		return time.Time{}, errors.New(name + " must be a Unix timestamp in milliseconds")
+	}
+	return time.Unix(0, millis*int64(time.Millisecond)), nil
+}
+
+// snapshot returns the last orderbook snapshot of a market at or before the
+// requested time.
+func (h *Handler) snapshot(ctx context.Context, p orderbookParams) (tickerdb.OrderbookSnapshot, int, error) {
+	found, bID, cID, err := h.db.GetMarketAssetIDs(ctx, p.market.baseAssetCode, p.market.baseAssetIssuer, p.market.counterAssetCode, p.market.counterAssetIssuer)
+	if err != nil {
+		h.logger.Error("could not retrieve market assets: ", err)
+		return tickerdb.OrderbookSnapshot{}, http.StatusInternalServerError, errRetrieveData
+	}
+	if !found {
+		return tickerdb.OrderbookSnapshot{}, http.StatusNotFound, errMarketNotFound
+	}
+
+	snapshot, found, err := h.db.GetOrderbookSnapshotAt(ctx, bID, cID, p.at)
+	if err != nil {
+		h.logger.Error("could not retrieve orderbook snapshot: ", err)
+		return snapshot, http.StatusInternalServerError, errRetrieveData
+	}
+	if !found {
+		return snapshot, http.StatusNotFound, errSnapshotNotFound
+	}
+	return snapshot, http.StatusOK, nil
+}
+
+// candles returns the candles of a market.
+func (h *Handler) candles(ctx context.Context, p candleParams) ([]tickerdb.Candle, int, error) {
+	found, bID, cID, err := h.db.GetMarketAssetIDs(ctx, p.market.baseAssetCode, p.market.baseAssetIssuer, p.market.counterAssetCode, p.market.counterAssetIssuer)
+	if err != nil {
+		h.logger.Error("could not retrieve market assets: ", err)
+		return nil, http.StatusInternalServerError, errRetrieveData
+	}
+	if !found {
+		return nil, http.StatusNotFound, errMarketNotFound
+	}
+
+	candles, err := h.db.RetrieveCandles(ctx, bID, cID, p.resolution, p.from, p.to)
+	if err != nil {
+		h.logger.Error("could not retrieve candles: ", err)
+		return nil, http.StatusInternalServerError, errRetrieveData
+	}
+	return candles, http.StatusOK, nil
+}
+
+// truncateLevels returns the first depth/2 levels of each side of an
+// orderbook snapshot, or all of them if depth is 0.
+func truncateLevels(levels tickerdb.DepthLevels, depth int) tickerdb.DepthLevels {
+	if depth == 0 {
+		return levels
+	}
+	n := depth / 2
+	if n == 0 {
+		n = 1
+	}
+	if len(levels) > n {
+		return levels[:n]
+	}
+	return levels
+}
+
+// formatLevels formats price levels as [price, amount] pairs of decimal
+// strings.
+func formatLevels(levels tickerdb.DepthLevels) [][2]string {
+	formatted := make([][2]string, len(levels))
+	for i, l := range levels {
+		formatted[i] = [2]string{formatFloat(l.Price), formatFloat(l.Amount)}
+	}
+	return formatted
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,76 @@
+package exchangeapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	hlog "github.com/stellar/go/support/log"
+)
+
+const btcIssuer = "GATEMHCCKCY67ZUCKTROYN24ZYT5GK4EQZ65JJLDHKHRUZI3EUEKMTCH"
+
+func TestParsePairID(t *testing.T) {
+	m, err := parsePairID("XLM_BTC:" + btcIssuer)
+	require.NoError(t, err)
+	assert.Equal(t, market{
+		pairID:             "XLM_BTC:" + btcIssuer,
+		baseAssetCode:      "XLM",
+		baseAssetIssuer:    "native",
+		counterAssetCode:   "BTC",
+		counterAssetIssuer: btcIssuer,
+	}, m)
+
+	_, err = parsePairID("XLM")
+	assert.EqualError(t, err, "invalid pair id, must be BASE_COUNTER")
+	_, err = parsePairID("XLM_BTC")
+	assert.EqualError(t, err, "invalid asset, must be CODE:ISSUER or XLM")
+}
+
+func TestTruncateLevels(t *testing.T) {
+	levels := tickerdb.DepthLevels{{Price: 1, Amount: 1}, {Price: 2, Amount: 2}, {Price: 3, Amount: 3}}
+
+	assert.Equal(t, levels, truncateLevels(levels, 0))
+	assert.Equal(t, levels[:2], truncateLevels(levels, 4))
+	assert.Equal(t, levels[:1], truncateLevels(levels, 1))
+	assert.Equal(t, levels, truncateLevels(levels, 100))
+}
+
+func TestFormatLevels(t *testing.T) {
+	assert.Equal(t,
+		[][2]string{{"0.0000223", "28.0762332"}, {"2", "0.5"}},
+		formatLevels(tickerdb.DepthLevels{{Price: 0.0000223, Amount: 28.0762332}, {Price: 2, Amount: 0.5}}),
+	)
+}
+
+func TestInvalidRequests(t *testing.T) {
+	h := New(nil, hlog.New())
+
+	for _, tc := range []struct {
+		method string
+		url    string
+		status int
+		body   string
+	}{
+		{"POST", "/coingecko/orderbook", http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+		{"GET", "/coingecko/orderbook?ticker_id=XLM", http.StatusBadRequest, `{"error":"invalid pair id, must be BASE_COUNTER"}`},
+		{"GET", "/coingecko/orderbook?ticker_id=XLM_BTC:" + btcIssuer + "&depth=-1", http.StatusBadRequest, `{"error":"depth cannot be negative"}`},
+		{"GET", "/coingecko/candles?ticker_id=XLM_BTC:" + btcIssuer + "&resolution=1h", http.StatusBadRequest, `{"error":"from is required"}`},
+		{"GET", "/coingecko/candles?ticker_id=XLM_BTC:" + btcIssuer + "&resolution=1h&from=abc", http.StatusBadRequest, `{"error":"from must be a Unix timestamp in milliseconds"}`},
+		{"GET", "/cmc/orderbook/XLM_BTC:" + btcIssuer + "?level=3", http.StatusBadRequest, `{"error":"level must be 1 or 2"}`},
+		{"GET", "/cmc/candles/XLM_BTC:" + btcIssuer + "?resolution=1m&from=0&to=86400000", http.StatusBadRequest, `{"error":"the interval cannot span more than 1000 candles"}`},
+		{"GET", "/cmc/candles/XLM_BTC:" + btcIssuer + "?resolution=1w&from=0", http.StatusBadRequest, `{"error":"invalid resolution \"1w\", must be one of 1m, 5m, 15m, 1h or 1d"}`},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tc.method, tc.url, nil))
+			assert.Equal(t, tc.status, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			assert.JSONEq(t, tc.body, w.Body.String())
+		})
+	}
+}
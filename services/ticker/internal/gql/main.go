@@ -7,6 +7,7 @@ import (
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/stellar/go/services/ticker/internal/gql/static"
+	"github.com/stellar/go/services/ticker/internal/refprice"
 	"github.com/stellar/go/services/ticker/internal/tickerdb"
 	hlog "github.com/stellar/go/support/log"
 )
@@ -77,9 +78,70 @@ type orderbookStats struct {
 	SpreadMidPoint float64
 }
 
+// candle represents the OHLCV data of a market for the
+// interval starting at StartTime
+type candle struct {
+	StartTime     graphql.Time
+	Open          float64
+	High          float64
+	Low           float64
+	Close         float64
+	BaseVolume    float64
+	CounterVolume float64
+	TradeCount    int32
+}
+
+// orderbookSnapshot represents the depth of a market at a
+// given time
+type orderbookSnapshot struct {
+	Time graphql.Time
+	Bids []*depthLevel
+	Asks []*depthLevel
+}
+
+// depthLevel represents a price level of an orderbook snapshot
+type depthLevel struct {
+	Price  float64
+	Amount float64
+}
+
+// liquidityPool represents the reserves of a liquidity pool
+type liquidityPool struct {
+	PoolID             string
+	BaseAssetCode      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetIssuer string
+	FeeBP              int32
+	BaseReserve        float64
+	CounterReserve     float64
+	TotalShares        float64
+	TotalTrustlines    BigInt
+	Price              float64
+	TVL                float64
+	UpdatedAt          graphql.Time
+}
+
+// referencePremium represents the premium of the market price of a
+// metal-backed asset over the spot price of the metal backing it
+type referencePremium struct {
+	TradePair          string
+	BaseAssetCode      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetIssuer string
+	Metal              string
+	Currency           string
+	DexPrice           float64
+	ReferencePrice     float64
+	Premium            float64
+}
+
 type resolver struct {
-	db     *tickerdb.TickerSession
-	logger *hlog.Entry
+	db        *tickerdb.TickerSession
+	logger    *hlog.Entry
+	refPrices refprice.Source
+	metals    map[string]refprice.Metal
 }
 
 // New creates a new GraphQL resolver
@@ -90,8 +152,16 @@ func New(s *tickerdb.TickerSession, l *hlog.Entry) *resolver {
 	return &resolver{db: s, logger: l}
 }
 
-// Serve creates a GraphQL interface on <address>/graphql and a GraphiQL explorer on /graphiql
-func (r *resolver) Serve(address string) {
+// UseReferencePrices enables the referencePremiums query, comparing the
+// prices of the metal-backed assets to the spot prices provided by src.
+func (r *resolver) UseReferencePrices(src refprice.Source, metals map[string]refprice.Metal) {
+	r.refPrices = src
+	r.metals = metals
+}
+
+// Serve creates a GraphQL interface on <address>/graphql and a GraphiQL explorer on /graphiql,
+// along with the additional handlers, such as the REST APIs, mapped to their path patterns
+func (r *resolver) Serve(address string, handlers map[string]http.Handler) {
 	relayHandler := r.NewRelayHandler()
 	mux := http.NewServeMux()
 	mux.Handle("/graphql", http.HandlerFunc(func(wr http.ResponseWriter, re *http.Request) {
@@ -99,6 +169,13 @@ func (r *resolver) Serve(address string) {
 		relayHandler.ServeHTTP(wr, re)
 	}))
 	mux.Handle("/graphiql", GraphiQL{})
+	for pattern, handler := range handlers {
+		h := handler
+		mux.Handle(pattern, http.HandlerFunc(func(wr http.ResponseWriter, re *http.Request) {
+			r.logger.Infof("%s %s %s\n", re.RemoteAddr, re.Method, re.URL)
+			h.ServeHTTP(wr, re)
+		}))
+	}
 
 	server := &http.Server{
 		Addr:        address,
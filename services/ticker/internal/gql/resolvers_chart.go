@@ -0,0 +1,155 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/services/ticker/internal/utils"
+)
+
+// Candles resolves the candles() GraphQL query.
+func (r *resolver) Candles(ctx context.Context, args struct {
+	BaseAssetCode      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetIssuer string
+	Resolution         string
+	From               graphql.Time
+	To                 *graphql.Time
+}) (candles []*candle, err error) {
+	resolution, err := utils.ParseCandleResolution(args.Resolution)
+	if err != nil {
+		return
+	}
+	from, to := args.From.Time, timeOrNow(args.To)
+	if err = utils.ValidateCandleRange(from, to, resolution); err != nil {
+		return
+	}
+
+	bID, cID, err := r.marketAssetIDs(ctx, args.BaseAssetCode, args.BaseAssetIssuer, args.CounterAssetCode, args.CounterAssetIssuer)
+	if err != nil {
+		return
+	}
+
+	dbCandles, err := r.db.RetrieveCandles(ctx, bID, cID, resolution, from, to)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	for _, dbCandle := range dbCandles {
+		candles = append(candles, dbCandleToCandle(dbCandle))
+	}
+	return
+}
+
+// OrderbookDepth resolves the orderbookDepth() GraphQL query.
+func (r *resolver) OrderbookDepth(ctx context.Context, args struct {
+	BaseAssetCode      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetIssuer string
+	From               graphql.Time
+	To                 *graphql.Time
+	Limit              *int32
+}) (snapshots []*orderbookSnapshot, err error) {
+	limit, err := validateSnapshotLimit(args.Limit)
+	if err != nil {
+		return
+	}
+	from, to := args.From.Time, timeOrNow(args.To)
+	if !from.Before(to) {
+		err = errors.New("from must be before to")
+		return
+	}
+
+	bID, cID, err := r.marketAssetIDs(ctx, args.BaseAssetCode, args.BaseAssetIssuer, args.CounterAssetCode, args.CounterAssetIssuer)
+	if err != nil {
+		return
+	}
+
+	dbSnapshots, err := r.db.RetrieveOrderbookSnapshots(ctx, bID, cID, from, to, limit)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	for _, dbSnapshot := range dbSnapshots {
+		snapshots = append(snapshots, dbSnapshotToOrderbookSnapshot(dbSnapshot))
+	}
+	return
+}
+
+// marketAssetIDs returns the database IDs of the base and counter assets of
+// a market.
+func (r *resolver) marketAssetIDs(ctx context.Context, bCode, bIssuer, cCode, cIssuer string) (bID, cID int32, err error) {
+	found, bID, cID, err := r.db.GetMarketAssetIDs(ctx, bCode, bIssuer, cCode, cIssuer)
+	if err != nil {
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+	if !found {
+		err = errors.New("market not found")
+	}
+	return
+}
+
+// validateSnapshotLimit validates if the limit parameter of orderbookDepth
+// is within an acceptable range (at most 1000 snapshots)
+func validateSnapshotLimit(n *int32) (int, error) {
+	if n == nil {
+		return 100, nil // default limit = 100
+	}
+
+	if *n > 0 && *n <= 1000 {
+		return int(*n), nil
+	}
+
+	return 0, errors.New("limit must be between 1 and 1000")
+}
+
+// timeOrNow returns the time of an optional time argument, defaulting to now
+func timeOrNow(t *graphql.Time) time.Time {
+	if t == nil {
+		return time.Now()
+	}
+	return t.Time
+}
+
+// dbCandleToCandle converts a tickerdb.Candle to a *candle
+func dbCandleToCandle(dbCandle tickerdb.Candle) *candle {
+	return &candle{
+		StartTime:     graphql.Time{Time: dbCandle.StartTime},
+		Open:          dbCandle.Open,
+		High:          dbCandle.High,
+		Low:           dbCandle.Low,
+		Close:         dbCandle.Close,
+		BaseVolume:    dbCandle.BaseVolume,
+		CounterVolume: dbCandle.CounterVolume,
+		TradeCount:    dbCandle.TradeCount,
+	}
+}
+
+// dbSnapshotToOrderbookSnapshot converts a tickerdb.OrderbookSnapshot to a
+// *orderbookSnapshot
+func dbSnapshotToOrderbookSnapshot(dbSnapshot tickerdb.OrderbookSnapshot) *orderbookSnapshot {
+	levels := func(dbLevels tickerdb.DepthLevels) []*depthLevel {
+		levels := make([]*depthLevel, len(dbLevels))
+		for i, l := range dbLevels {
+			levels[i] = &depthLevel{Price: l.Price, Amount: l.Amount}
+		}
+		return levels
+	}
+	return &orderbookSnapshot{
+		Time: graphql.Time{Time: dbSnapshot.CreatedAt},
+		Bids: levels(dbSnapshot.Bids),
+		Asks: levels(dbSnapshot.Asks),
+	}
+}
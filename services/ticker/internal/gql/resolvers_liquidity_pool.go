@@ -0,0 +1,55 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+)
+
+// LiquidityPools resolves the liquidityPools() GraphQL query.
+func (r *resolver) LiquidityPools(ctx context.Context, args struct {
+	BaseAssetCode      *string
+	BaseAssetIssuer    *string
+	CounterAssetCode   *string
+	CounterAssetIssuer *string
+}) (pools []*liquidityPool, err error) {
+	dbPools, err := r.db.RetrieveLiquidityPools(
+		ctx,
+		args.BaseAssetCode,
+		args.BaseAssetIssuer,
+		args.CounterAssetCode,
+		args.CounterAssetIssuer,
+	)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	for _, dbPool := range dbPools {
+		pools = append(pools, dbLiquidityPoolToLiquidityPool(dbPool))
+	}
+	return
+}
+
+// dbLiquidityPoolToLiquidityPool converts a tickerdb.LiquidityPoolWithAssets to a *liquidityPool
+func dbLiquidityPoolToLiquidityPool(p tickerdb.LiquidityPoolWithAssets) *liquidityPool {
+	return &liquidityPool{
+		PoolID:             p.PoolID,
+		BaseAssetCode:      p.BaseAssetCode,
+		BaseAssetIssuer:    p.BaseAssetIssuer,
+		CounterAssetCode:   p.CounterAssetCode,
+		CounterAssetIssuer: p.CounterAssetIssuer,
+		FeeBP:              int32(p.FeeBP),
+		BaseReserve:        p.BaseReserve,
+		CounterReserve:     p.CounterReserve,
+		TotalShares:        p.TotalShares,
+		TotalTrustlines:    BigInt(p.TotalTrustlines),
+		Price:              p.Price(),
+		TVL:                p.TVL(),
+		UpdatedAt:          graphql.Time{Time: p.UpdatedAt},
+	}
+}
@@ -0,0 +1,125 @@
+package gql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stellar/go/services/ticker/internal/refprice"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+)
+
+// ReferencePremiums resolves the referencePremiums() GraphQL query.
+func (r *resolver) ReferencePremiums(ctx context.Context) (premiums []*referencePremium, err error) {
+	premiums = []*referencePremium{}
+	if r.refPrices == nil || len(r.metals) == 0 {
+		return
+	}
+
+	prices, err := r.refPrices.Prices(ctx)
+	if err != nil {
+		r.logger.Error("could not get reference prices: ", err)
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	dbMarkets, err := r.db.RetrievePartialMarkets(ctx, nil, nil, nil, nil, 24)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+	dbAssets, err := r.db.GetAllValidAssets(ctx)
+	if err != nil {
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	currencies := fiatCurrencies(dbAssets)
+	for _, dbMarket := range dbMarkets {
+		p, ok := marketReferencePremium(dbMarket, currencies, r.metals, prices)
+		if ok {
+			premiums = append(premiums, p)
+		}
+	}
+	return
+}
+
+// fiatCurrencies maps the CODE:ISSUER of the assets anchored to a fiat
+// currency to the code of that currency.
+func fiatCurrencies(assets []tickerdb.Asset) map[string]string {
+	currencies := map[string]string{}
+	for _, a := range assets {
+		if a.AnchorAssetType == "fiat" && a.AnchorAssetCode != "" {
+			currencies[a.Code+":"+a.IssuerAccount] = a.AnchorAssetCode
+		}
+	}
+	return currencies
+}
+
+// marketReferencePremium compares the price of a market between a metal-backed
+// asset and a fiat-anchored asset to the spot price of the metal. The returned
+// premium always has the metal-backed asset as its base. It returns false for
+// other markets, markets without a price and metals or currencies without a
+// reference price.
+func marketReferencePremium(
+	m tickerdb.PartialMarket,
+	currencies map[string]string,
+	metals map[string]refprice.Metal,
+	prices map[string]float64,
+) (*referencePremium, bool) {
+	p := &referencePremium{
+		TradePair:          m.TradePairName,
+		BaseAssetCode:      m.BaseAssetCode,
+		BaseAssetIssuer:    m.BaseAssetIssuer,
+		CounterAssetCode:   m.CounterAssetCode,
+		CounterAssetIssuer: m.CounterAssetIssuer,
+		DexPrice:           marketMidPrice(m),
+	}
+
+	metal, ok := metals[m.BaseAssetCode]
+	if !ok {
+		metal, ok = metals[m.CounterAssetCode]
+		if !ok {
+			return nil, false
+		}
+		p.BaseAssetCode, p.CounterAssetCode = m.CounterAssetCode, m.BaseAssetCode
+		p.BaseAssetIssuer, p.CounterAssetIssuer = m.CounterAssetIssuer, m.BaseAssetIssuer
+		if p.DexPrice != 0 {
+			p.DexPrice = 1 / p.DexPrice
+		}
+	}
+	if p.DexPrice == 0 {
+		return nil, false
+	}
+
+	currency, ok := currencies[p.CounterAssetCode+":"+p.CounterAssetIssuer]
+	if !ok {
+		return nil, false
+	}
+	refPrice, err := refprice.ReferencePrice(prices, metal, currency)
+	if err != nil {
+		return nil, false
+	}
+
+	p.Metal = metal.Symbol
+	p.Currency = currency
+	p.ReferencePrice = refPrice
+	p.Premium = refprice.PremiumPct(p.DexPrice, refPrice)
+	return p, true
+}
+
+// marketMidPrice returns the mid price of the orderbook of a market, the best
+// bid or ask if only one side has offers, or else its last price.
+func marketMidPrice(m tickerdb.PartialMarket) float64 {
+	switch {
+	case m.HighestBid > 0 && m.LowestAsk > 0:
+		return (m.HighestBid + m.LowestAsk) / 2
+	case m.HighestBid > 0:
+		return m.HighestBid
+	case m.LowestAsk > 0:
+		return m.LowestAsk
+	default:
+		return m.Close
+	}
+}
@@ -0,0 +1,83 @@
+package gql
+
+import (
+	"testing"
+
+	"github.com/stellar/go/services/ticker/internal/refprice"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketReferencePremium(t *testing.T) {
+	const (
+		kauIssuer = "GCKAUZ6RPXYGOGQ7UJTNMV4GCXV4ZSJQB2FHDS7LWN6WCQMZ7CT2MBGK"
+		usdIssuer = "GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX"
+	)
+	currencies := fiatCurrencies([]tickerdb.Asset{
+		{Code: "KAU", IssuerAccount: kauIssuer},
+		{Code: "USD", IssuerAccount: usdIssuer, AnchorAssetCode: "USD", AnchorAssetType: "fiat"},
+	})
+	metals := map[string]refprice.Metal{"KAU": {Symbol: "XAU", Ounces: 0.0321507466}}
+	prices := map[string]float64{"XAU": 2000}
+
+	// KAU as base, priced from the orderbook mid price
+	p, ok := marketReferencePremium(tickerdb.PartialMarket{
+		TradePairName:      "KAU:" + kauIssuer + " / USD:" + usdIssuer,
+		BaseAssetCode:      "KAU",
+		BaseAssetIssuer:    kauIssuer,
+		CounterAssetCode:   "USD",
+		CounterAssetIssuer: usdIssuer,
+		HighestBid:         63,
+		LowestAsk:          65,
+		Close:              70,
+	}, currencies, metals, prices)
+	require.True(t, ok)
+	assert.Equal(t, "KAU", p.BaseAssetCode)
+	assert.Equal(t, "XAU", p.Metal)
+	assert.Equal(t, "USD", p.Currency)
+	assert.Equal(t, 64.0, p.DexPrice)
+	assert.InDelta(t, 64.3014932, p.ReferencePrice, 1e-7)
+	assert.InDelta(t, -0.46887, p.Premium, 1e-5)
+
+	// KAU as counter, priced from the last price
+	p, ok = marketReferencePremium(tickerdb.PartialMarket{
+		BaseAssetCode:      "USD",
+		BaseAssetIssuer:    usdIssuer,
+		CounterAssetCode:   "KAU",
+		CounterAssetIssuer: kauIssuer,
+		Close:              0.015,
+	}, currencies, metals, prices)
+	require.True(t, ok)
+	assert.Equal(t, "KAU", p.BaseAssetCode)
+	assert.Equal(t, kauIssuer, p.BaseAssetIssuer)
+	assert.Equal(t, "USD", p.CounterAssetCode)
+	assert.InDelta(t, 66.66667, p.DexPrice, 1e-5)
+
+	// no metal-backed asset
+	_, ok = marketReferencePremium(tickerdb.PartialMarket{
+		BaseAssetCode:    "XLM",
+		CounterAssetCode: "USD",
+		Close:            0.1,
+	}, currencies, metals, prices)
+	assert.False(t, ok)
+
+	// counter asset not anchored to a fiat currency
+	_, ok = marketReferencePremium(tickerdb.PartialMarket{
+		BaseAssetCode:    "KAU",
+		BaseAssetIssuer:  kauIssuer,
+		CounterAssetCode: "XLM",
+		Close:            500,
+	}, currencies, metals, prices)
+	assert.False(t, ok)
+
+	// no reference price for the metal
+	_, ok = marketReferencePremium(tickerdb.PartialMarket{
+		BaseAssetCode:      "KAU",
+		BaseAssetIssuer:    kauIssuer,
+		CounterAssetCode:   "USD",
+		CounterAssetIssuer: usdIssuer,
+		Close:              64,
+	}, currencies, metals, map[string]float64{})
+	assert.False(t, ok)
+}
@@ -1,7 +1,7 @@
 // Code generated by go-bindata. DO NOT EDIT.
 // sources:
 // graphiql.html (1.182kB)
-// schema.gql (2.42kB)
+// schema.gql (4.982kB)
 
 package static
 
@@ -90,7 +90,7 @@ func graphiqlHtml() (*asset, error) {
 	return a, nil
 }
 
-var _schemaGql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xe4\x54\x51\x6f\xe3\x36\x0c\x7e\xb6\x7f\x05\xdb\xbd\xb4\x2f\x79\x18\xf6\x64\x6c\x03\xd2\x76\xc3\x8a\x35\xb7\xdb\xa5\x37\x0c\x28\x86\x81\xb1\x18\x87\x88\x2c\xf9\x28\x29\x6d\x70\xe8\x7f\x1f\x24\x27\xa9\x6c\xb7\xdd\x0f\xb8\x27\x5b\x24\x3f\x8a\xfc\xf4\x91\xae\xde\x50\x8b\xf0\xb5\x2c\xbe\x04\x92\x7d\x05\xc5\x9f\xf1\x5b\x3e\x97\xa5\xdf\x77\x04\xe9\x14\xdd\xdf\x81\x90\x17\xa6\x1d\x01\x6a\x0d\x3b\xd4\xac\xd0\x93\x02\x74\x8e\xbc\x03\x6b\xc0\x6f\x08\x96\x9e\xb4\x46\x01\x43\xfe\xd1\xca\x76\x56\x16\xbd\xbf\x82\x87\x79\xfc\x39\xfb\xe7\xac\x7c\x27\x19\x3b\x17\x48\xde\xc9\x76\x08\xa8\xe0\xe1\x36\xfd\x4d\xf2\x79\x41\x45\xe0\x3c\x7a\x07\x6b\xb1\x6d\xca\xa3\xd1\x79\xf8\xd1\x84\xf6\x37\x1b\xc4\xcd\x1b\xfb\x33\x6c\xe2\x5f\x44\x5e\x28\x5a\x63\xd0\x1e\x7e\x82\xef\x7f\xe8\xcd\x97\x33\xb0\x9d\x67\x6b\x50\xeb\x3d\x74\x62\x77\xac\x08\x6a\x1b\x8c\x27\x01\x34\x2a\xe2\x56\xe8\xa8\x6f\x1e\xd8\xac\x2d\xac\xad\xc0\x9a\xb5\x27\x61\xd3\xcc\xca\xa2\x45\xd9\x92\x77\x17\x65\x51\xc4\xd0\xd4\xfd\xb5\x55\x54\xc1\xd2\xc7\x90\xdc\xde\xf7\x92\x79\x0e\x77\xbd\x06\xca\x5d\x13\x5c\xd6\x62\x05\xb7\xc6\x97\xc5\x65\x05\x0f\x8b\x54\xca\x84\xf9\xa6\x11\x6a\x12\xed\x03\xd2\xac\xbc\xc1\x59\x44\x27\x7e\x5e\xa5\x07\xa1\x43\x96\x0f\xd8\x12\x5c\xd0\xac\x99\xc1\xf9\xdf\x77\x8b\x7f\xaf\xee\xaf\xcf\xc1\x0a\x20\x44\xb4\x63\xd3\x68\x82\x3a\x88\x90\xa9\xf7\x59\xe0\xf9\xe5\x90\x40\x10\x72\x41\x7b\x37\x2b\x0b\xcf\xf5\x96\x24\xf2\x78\xbc\xe0\x7f\x1b\x9e\x9f\x5a\x3b\xb5\xfe\x5c\x96\xae\xc6\x28\xa6\x2b\x6e\x62\xe0\xe1\x74\xcf\x2d\x1d\xb4\x9e\x28\x8d\x5a\xaf\x33\xc6\xcf\x8e\x9a\x9b\xd7\x89\xf9\xcc\x1e\x41\xd9\xd1\x84\xf6\x10\xe3\x52\x29\x67\x65\x81\xc1\x6f\x3e\xd1\x97\xc0\x42\xaa\x82\x2b\x6b\x35\xa1\x39\xd9\x77\xb6\xc6\x95\xa6\x81\xa3\xed\xef\xf8\x55\x5b\x4c\x09\x7a\x01\x18\x2f\x56\x6b\x52\x57\xfb\x1b\xdb\x22\x9b\x01\xc4\xd4\x1b\x3b\x55\xca\xd0\x73\x3f\x2c\x95\x5d\xb2\xce\x53\xc0\xb0\x34\xc5\xae\xd3\xb8\xbf\xa1\x9a\x5b\xd4\xae\x3a\xd0\x15\xfb\xcb\x98\x8f\x81\xe4\xea\xec\x58\x5b\xa3\x38\x8a\xc2\x65\xc6\x35\x3f\x91\xfa\x10\xda\x55\x14\xe9\x29\x51\x8b\x4f\x13\x1b\xbb\xcf\x46\x73\xcb\x7e\x58\x8d\x90\xa2\x36\x69\xed\xd6\x38\x2f\xa1\x1e\xdf\x50\x5b\xad\xd1\x93\xa0\x9e\x2b\x25\xe4\x1c\xbd\xeb\x5d\x72\x63\xd0\x07\x19\x45\x05\x13\x67\x22\xb7\xc5\x59\x08\x6e\x22\x82\xdb\x9b\xc3\xd3\x1e\xf7\x63\xaf\xaf\x28\x9a\x34\x43\x1f\x91\x25\x03\xbd\x3a\xf8\xb9\x7d\x38\xc0\xc7\x5a\x5e\x19\xfc\x91\x6b\x82\x8b\x19\xff\xb2\x3a\xc4\x27\x3a\x8a\xe7\x00\x18\x9b\x53\xa1\xd7\xbd\xce\x7a\xf2\x6d\x47\xe6\xc5\xaf\xed\xe3\xcb\x61\xc3\xcd\x26\xcb\xb8\x41\xd3\xe4\x37\x68\xeb\xb2\x23\xc7\xeb\x76\xa8\x97\x1e\xc5\x57\x69\xb4\x92\x08\xc4\xf9\x3b\x52\x0d\xc9\x75\x8c\x8f\xe6\x93\x33\x6e\x99\xb7\x7c\x56\x14\xc9\xca\xda\xed\x32\x2e\xa6\x0a\xfe\x18\x9c\x5f\xde\x60\x3c\xed\xef\xbd\xc6\xb7\xca\xd1\xd0\x0e\x5f\x4b\x28\x56\xac\x0e\x1d\x9e\xa6\x70\xc5\x6a\xcc\xc4\x8a\xd5\x02\x9f\xf2\x8d\xb4\x1d\xa3\xd0\x6d\xc7\x28\x74\xdb\x05\x67\x7c\xb9\x4e\x08\xd5\xf8\xbc\x60\xf5\xd1\x72\xb6\xef\x8e\xd5\xf6\xf2\x8e\xef\xd8\x85\x95\xe6\xfa\x77\xda\xe7\x8b\x76\xb8\x88\x82\xe8\x7c\x29\xdb\x56\x7f\xfe\x74\x97\x2f\x21\x52\x24\x18\x17\xc7\x92\x64\x37\x98\x9a\xb8\x87\x27\x46\x2f\x68\xdc\x9a\x64\xe2\x78\xa4\xd5\x3c\xf8\xcd\x2f\x46\x75\x7d\xd5\xd9\x2e\xec\xac\x63\x3f\x41\x58\x69\xee\x1f\xd9\xfb\xdc\xf8\x5c\xfe\x17\x00\x00\xff\xff\x20\x26\x65\x82\x74\x09\x00\x00")
+var _schemaGql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xe4\x58\x51\x6f\xdb\x38\x12\x7e\x96\x7e\xc5\x38\x79\x49\x00\x9f\x91\x1c\xee\x5e\x8c\x5c\x00\x27\xb9\xbb\x06\x75\xda\x6c\x9c\x16\x05\x82\xc5\x82\x16\x47\x16\x61\x8a\x54\x49\xca\x8e\x51\xe4\xbf\x2f\x86\x94\x65\x4a\x8e\xbd\x0f\x0b\x74\x1f\xf6\x25\x15\x87\x1c\xce\x7c\xdf\x0c\x67\xc6\xb5\x59\x81\x25\x83\x1f\x69\xf2\xbd\x46\xb3\x19\x43\xf2\x0b\xfd\x9b\xbe\xa5\xa9\xdb\x54\x08\x7e\x45\xdb\xa7\x60\xd0\x19\x81\x2b\x04\x26\x25\xac\x98\x14\x9c\x39\xe4\xc0\xac\x45\x67\x41\x2b\x70\x05\xc2\xcc\xa1\x94\xcc\x80\x42\xb7\xd6\x66\x39\x4a\x93\xb0\x3f\x86\x97\x09\x7d\x0c\x7e\x1d\xa4\x47\x2e\x13\xd6\xd6\x68\x8e\xdc\xd6\x1c\x18\xc3\xcb\xbd\xff\xda\xbb\xcf\x19\xc6\x11\xac\x63\xce\x42\x6e\x74\xe9\xef\x91\xcc\x3a\xb8\x52\x75\xf9\x41\xd7\xc6\x4e\x16\xfa\x1a\x0a\xfa\x22\xcd\x33\x8e\x39\xab\xa5\x83\xff\xc0\x3f\xff\x15\xc4\xe7\x23\xd0\x95\x13\x5a\x31\x29\x37\x50\x19\xbd\x12\x1c\x21\xd3\xb5\x72\x68\x80\x29\x4e\x7a\x73\x66\x31\x80\x07\xa1\x72\x0d\xb9\x36\x90\x0b\xe9\xd0\x08\xb5\x18\xa5\x49\xc9\xcc\x12\x9d\x3d\x4b\x93\x84\x8e\x7a\xf4\xb7\x9a\xe3\x18\x66\x8e\x8e\xc4\xf2\x80\x25\xda\x69\x6c\xbd\xa7\x14\x6f\xed\xe9\x45\x10\xc7\x70\xaf\x5c\x9a\x9c\x8f\xe1\xe5\xc1\xbb\xb2\xc7\xfc\x62\x61\x70\xe1\x69\xef\x90\xa6\xcd\x01\xce\x08\xb5\xe7\xe7\x5d\x7a\x18\x54\x4c\x98\x4f\xac\x44\x38\xc3\xd1\x62\x04\x27\xdf\xa6\x0f\xbf\xdd\x3c\xdf\x9e\x80\x36\xc0\x80\xb4\xad\x50\x0b\x89\x90\xd5\xc6\xa0\xca\x36\xd1\xc1\x93\xf3\x2e\x81\x60\xd0\xd6\xd2\xd9\x51\x9a\x38\x91\x2d\xd1\x10\x8f\x5b\x03\x7f\x08\x78\xd2\x42\x7b\x1f\x3a\xe1\xfb\xfc\x61\x7a\xfb\x15\x32\xa6\xb8\x44\x0b\x3a\x07\x06\x21\x64\x21\x6d\xae\xe8\xef\x35\xd4\x15\x38\x4d\xae\x5f\x39\x7d\x1d\xe7\x8a\xd2\xeb\xf3\x21\x30\x07\x8c\x5c\xd5\xb2\x26\x42\xe8\x9a\xcb\x72\x08\xff\x2e\x87\x70\xe9\xff\x14\xa4\xab\x0d\x5c\xf2\x11\x1d\x2e\xb5\x75\x70\x79\x71\x71\xd1\x1a\xce\x98\x82\x39\xb6\xae\x71\x3a\xa5\x55\x86\x23\x0a\x02\x29\x2b\xe6\xc4\xaa\xcd\x34\x0b\x82\xa3\x72\x22\x17\xc8\x61\xbe\xa1\x43\xf0\x6d\xfa\x00\x99\xa6\x10\x28\xbe\xd5\x3a\x09\x6a\x27\x10\x1e\xcc\x28\x4d\x1a\x83\x07\xf3\x71\x70\x38\x21\x07\x47\x32\x72\x70\x34\x25\x69\x77\x47\x4f\x2c\x25\x7a\xc7\xf0\x2c\x4a\xa4\x95\xd3\xe1\x3b\xe4\xeb\xad\x77\x75\xff\x69\x17\x08\xda\x70\x34\x73\xad\x97\xc0\xb1\x72\x05\x58\xc5\x2a\x5b\x68\xd7\x8d\xa0\x63\x4b\x54\xa4\xbb\x17\xca\x03\x71\xd4\x92\xa3\x75\x90\x0b\x63\x1d\x05\x8a\x74\x7d\xac\xae\xa4\x28\x85\xbb\x8e\xec\x30\xe3\xa3\x55\x1b\x85\x3c\xbe\xe8\xf2\xe2\x62\xe8\xd5\xd8\x6b\x58\x5d\x9c\x8f\xd2\xa4\xf5\xf7\x8e\xdc\xfd\xf9\xe4\x1f\xa2\x39\xf1\xb8\xa2\x27\xf3\x79\xeb\xe8\xac\x41\xfa\x2e\xfd\x52\x7c\xaf\x05\x17\x6e\x03\x95\xd6\xd2\xc2\x1c\xdd\x1a\x51\xed\xb5\x03\xcf\x84\x64\x66\x11\xb1\x7a\xbc\xa4\x46\xf5\x94\x74\xdf\x2d\xa9\xad\xf5\x47\x32\x7e\x90\xcc\xc3\x5c\x1e\xa1\xf2\x28\x93\x21\x2d\xa7\xb1\xf9\x77\xe9\xa9\x0c\x96\xa2\x2e\xe1\x4c\x1b\xe0\xc2\xfa\xd0\x0c\x41\xe4\xa0\xa8\xd0\x8a\x15\x9e\x53\x9a\x36\x4f\xd4\x37\xa5\x6d\xcb\x69\x32\xd7\xa7\x71\x89\x8e\xc9\x7f\xcc\x59\xb6\xdc\xb5\xd7\x50\x29\x3f\x4e\xbe\x90\x4d\xa6\x38\x7c\x9c\xfc\xff\x1c\xf4\x0a\x43\xb1\xb6\x95\x76\x50\x19\x91\x61\x63\x20\x5c\x32\x04\x11\xda\x72\x2e\x42\x4e\xb7\x95\x97\x84\x0d\xde\x5d\x69\x61\x2a\x2b\xb4\xa1\x7e\xa0\x47\x80\x65\xe5\x36\x50\x2b\x89\xd6\x92\x2a\xd5\xb9\x1c\xa9\x6e\x63\x63\xc9\xea\xda\x64\x48\x8a\x99\x56\xb9\x58\xd4\x06\xf9\x28\x4d\xda\x63\x8f\x81\x0d\x3b\x86\x97\xa7\x9e\x8c\xd8\x7b\x4b\x53\x9b\x31\x1a\x16\x6e\xc4\x82\xf2\xb0\x59\xf9\x04\x0d\xd3\x87\x0f\x05\x4d\x1f\x59\x14\xa9\xc1\x76\x0a\x98\x64\x1e\x41\x24\x27\xa5\x68\xa9\xea\xb2\x39\x63\x7d\xa6\x0f\xd2\x84\xd5\xae\x78\xc2\xef\xb5\x30\xc8\xc7\x70\xa3\xb5\x44\xa6\x5a\xf9\x4a\x67\x6c\x2e\xb1\xb3\x51\x06\x1b\xff\x93\x9a\xb9\x41\x33\xce\xdc\x6a\xe5\x8c\x96\x12\xf9\xcd\xe6\x4e\x97\x4c\xa8\x8e\x8a\xa7\x71\x2f\xc3\xba\x3b\xcf\x5d\x57\x85\xf5\xe7\x27\x4d\x04\xe2\xeb\xb8\xb0\x95\x64\x9b\x3b\xcc\x44\xc9\xa4\x1d\x37\x74\x11\xbe\xa8\x17\x0e\xd2\x84\xa3\xcd\xa2\x65\xa6\x15\x17\xf4\xe4\x6c\x24\xcc\xc5\x2b\xf2\x4f\x75\x39\x47\x13\x5d\x54\xb2\xd7\x3d\x99\xb0\x5f\x94\x2f\x12\x5d\x6f\x0c\x72\xca\x0c\xa1\xd5\xbd\xb2\xce\xd4\x59\xdf\x42\xa6\xa5\x64\x0e\x0d\x93\x13\xce\x0d\x5a\x8b\x47\x77\x67\x62\xa1\x98\xab\x4d\xef\x54\xad\xe8\x59\xc5\x32\x9a\x4e\xea\x58\x10\x92\xe0\xfe\xae\x09\xed\x76\x62\x0d\x1d\x9f\x92\xc6\x4f\x35\x8f\x4c\xb4\xcf\x78\x90\xbe\x5f\x30\x06\xe9\xa1\x82\x31\x48\x3b\x55\xa1\xa7\x74\xb8\x60\x34\x37\x7e\xd5\xb2\x2e\x71\x97\x3c\x8d\x42\x5f\xec\x1d\xbd\xa5\xbd\x6d\x9a\xea\x0a\xd5\x6e\x5f\xea\xf5\x6e\x51\x88\x45\xb1\x5b\x65\x05\x53\x8b\xd8\x82\xd4\x36\x5a\x0a\x72\x7d\xc5\xe4\xcc\x31\xe3\xda\x3e\xe0\x0b\xf2\x14\xf9\x02\xcd\x2d\x9d\x27\x71\xbb\x29\xd9\xe1\xbd\xb6\x9b\xcd\x68\xbe\x1e\xc3\xae\x69\xd0\x7a\x17\x83\xfe\xfc\x75\x2c\x1a\x7f\x57\x8e\xba\x72\xf8\x91\x42\x32\x17\xbc\x41\xd8\xbe\xc2\xb9\xe0\x7d\x26\xe6\x82\x3f\xb0\xd7\xdd\x9a\xd9\x65\x5f\x8b\xd9\x65\x5f\x8b\xd9\xe5\x83\x88\xf8\xb2\x95\x41\xc6\xfb\xeb\x07\xc1\x1f\xb5\x88\xea\xdd\xd6\xdb\x30\x92\x51\x1c\x2d\xd1\xd4\x45\xdc\x09\x44\x97\xfb\x4e\x58\x7a\xc4\xff\x99\xe8\xef\xb3\xd8\x8c\x2d\xe4\xa2\x8b\xbd\x9b\x0b\x4e\x1d\xc8\xcf\x5f\x53\x5c\xa1\xa4\xde\x93\x30\xbb\xdc\x97\xbe\xa5\x29\xf5\xb9\xd0\xdd\x24\x49\xa9\x9b\x32\x15\x4d\x9d\xdb\x39\x70\x08\x6b\xe1\x8a\xa6\xe9\xd3\x71\xa1\xd2\x53\xa8\x95\x08\x93\x68\x03\x63\x3b\x91\x43\xe8\x22\xd4\x8d\xdb\x23\x84\x7e\x14\x40\xec\xbc\x20\xef\xbd\xf9\x1d\xf4\x5e\x03\x6a\x7c\xec\x0e\x62\x8d\x37\x74\xb3\x41\x8b\x66\x45\xbf\x68\xc8\x67\xea\xe5\x05\x82\xa5\x5f\x65\x6b\xb6\x01\x66\xd3\x53\x2f\xd9\xfe\x6a\x0f\xc3\x82\x7f\x9b\xde\x2b\xba\x22\x8c\x22\xa3\x18\x9c\x6d\x5d\x4f\x4f\x63\x7c\x15\x1a\x0f\x7a\x0b\x68\xd8\x22\x7e\xfe\x3a\xed\xc0\x6d\x34\x1a\xc4\x9d\x51\xca\x83\xd6\x5a\xde\xdf\xf5\x4a\xc3\xcf\x29\xd4\x39\xe2\xcd\xe3\xb6\xa8\xd0\xf5\x4f\x81\xc2\x5d\x08\x1a\xf5\x3d\xb9\xd3\x8e\xc9\x59\xc1\x7c\xf3\xea\x08\x9f\x4d\x6d\x9d\x14\x0a\xe3\x76\xdd\x0b\xac\x5b\xc9\xdd\xa2\xae\xfc\xff\xa6\x4c\xda\x02\xb4\x4d\xf0\xfe\xe4\xf4\xd7\x37\x36\x3f\x59\xc6\x87\x9b\x99\x32\x12\x71\x7c\x7d\xec\x82\x8d\x86\xc2\x8e\xbc\x99\x98\x5b\xc1\x16\x76\x68\xa7\x04\xb6\xaa\xe7\x52\x64\x1f\x31\xbe\xbf\x37\xf8\xd4\x46\x46\x2b\xa7\x4b\xf9\xe5\x69\x1a\x49\x72\xe4\x68\x18\x0d\x2a\x33\x0a\x6c\x0c\x86\xe6\xc1\x3d\xa1\x33\x4c\xd9\x1c\xcd\xde\xc6\x1a\xe7\x93\xda\x15\xff\x55\xbc\x0a\x55\xb2\xdd\xe1\x58\x69\x2b\xdc\x9e\x86\x36\x8b\xe7\xb5\x70\x2e\x16\xbe\xa5\xbf\x0f\x00\x95\x8d\xf3\xef\x76\x13\x00\x00")
 
 func schemaGqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -106,7 +106,7 @@ func schemaGql() (*asset, error) {
 	}
 
 	info := bindataFileInfo{name: "schema.gql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
-	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xf7, 0x3c, 0xa2, 0xb0, 0x7, 0x29, 0xef, 0xb6, 0x66, 0x0, 0x23, 0x1e, 0xf8, 0xf4, 0x6a, 0x79, 0x4f, 0xa5, 0xd8, 0xec, 0x24, 0x85, 0xfe, 0xa, 0xfd, 0xe9, 0xc6, 0x27, 0x6c, 0xf1, 0xd8, 0x46}}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xba, 0x67, 0x6e, 0x1a, 0x8e, 0x81, 0xd2, 0xc2, 0x19, 0xbf, 0x24, 0xff, 0xa4, 0xef, 0x25, 0xc9, 0x34, 0xa4, 0x64, 0xe6, 0xb9, 0x2b, 0x2d, 0xa2, 0x8, 0x6c, 0xd7, 0x66, 0x1, 0xc8, 0x53, 0x23}}
 	return a, nil
 }
 
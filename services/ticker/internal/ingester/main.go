@@ -0,0 +1,470 @@
+// Package ingester reads the trades and offers of the ticker directly from
+// ledgers and history archives, instead of scraping them from Horizon.
+package ingester
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/services/ticker/internal/utils"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+// orderbookLevels is the number of price levels of each side of an orderbook
+// summary, the limit used when scraping orderbooks from Horizon.
+const orderbookLevels = 200
+
+// syntheticOfferIDBit marks the offer ids Horizon derives from operation ids
+// for the offers which were fully filled when they were created.
+const syntheticOfferIDBit = 1 << 62
+
+// LedgerTrades returns the trades of a ledger in the format of the Horizon
+// trades endpoint, with the same ids, so that they go through the same
+// normalization as the trades scraped from Horizon.
+func LedgerTrades(networkPassphrase string, meta xdr.LedgerCloseMeta) ([]hProtocol.Trade, error) {
+	reader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, meta)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read ledger transactions")
+	}
+	defer reader.Close()
+
+	header := reader.GetHeader().Header
+	closeTime := time.Unix(int64(header.ScpValue.CloseTime), 0).UTC()
+	var trades []hProtocol.Trade
+	for {
+		transaction, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read ledger transaction")
+		}
+		if !transaction.Result.Successful() {
+			continue
+		}
+		transactionTrades, err := transactionTrades(int32(header.LedgerSeq), closeTime, transaction)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, transactionTrades...)
+	}
+	return trades, nil
+}
+
+func transactionTrades(sequence int32, closeTime time.Time, transaction ingest.LedgerTransaction) ([]hProtocol.Trade, error) {
+	opResults, ok := transaction.Result.OperationResults()
+	if !ok {
+		return nil, errors.New("transaction has no operation results")
+	}
+
+	var trades []hProtocol.Trade
+	for opidx, op := range transaction.Envelope.Operations() {
+		var claims []xdr.ClaimAtom
+		var buyOffer xdr.OfferEntry
+		var buyOfferExists bool
+
+		switch op.Body.Type {
+		case xdr.OperationTypePathPaymentStrictReceive:
+			claims = opResults[opidx].MustTr().MustPathPaymentStrictReceiveResult().MustSuccess().Offers
+		case xdr.OperationTypePathPaymentStrictSend:
+			claims = opResults[opidx].MustTr().MustPathPaymentStrictSendResult().MustSuccess().Offers
+		case xdr.OperationTypeManageBuyOffer:
+			result := opResults[opidx].MustTr().MustManageBuyOfferResult().MustSuccess()
+			claims = result.OffersClaimed
+			buyOffer, buyOfferExists = result.Offer.GetOffer()
+		case xdr.OperationTypeManageSellOffer:
+			result := opResults[opidx].MustTr().MustManageSellOfferResult().MustSuccess()
+			claims = result.OffersClaimed
+			buyOffer, buyOfferExists = result.Offer.GetOffer()
+		case xdr.OperationTypeCreatePassiveSellOffer:
+			// stellar-core creates results for CreatePassiveOffer operations
+			// with the wrong result arm set.
+			tr := opResults[opidx].MustTr()
+			if tr.Type == xdr.OperationTypeManageSellOffer {
+				result := tr.MustManageSellOfferResult().MustSuccess()
+				claims = result.OffersClaimed
+				buyOffer, buyOfferExists = result.Offer.GetOffer()
+			} else {
+				result := tr.MustCreatePassiveSellOfferResult().MustSuccess()
+				claims = result.OffersClaimed
+				buyOffer, buyOfferExists = result.Offer.GetOffer()
+			}
+		default:
+			continue
+		}
+
+		opID := toid.New(sequence, int32(transaction.Index), int32(opidx+1)).ToInt64()
+		counterOfferID := strconv.FormatInt(opID|syntheticOfferIDBit, 10)
+		if buyOfferExists {
+			counterOfferID = strconv.FormatInt(int64(buyOffer.OfferId), 10)
+		}
+		buyer := transaction.Envelope.SourceAccount().ToAccountId()
+		if op.SourceAccount != nil {
+			buyer = op.SourceAccount.ToAccountId()
+		}
+
+		for order, claim := range claims {
+			// offers garbage collected by stellar-core are claimed with
+			// zero amounts, they are not trades.
+			if claim.AmountBought() == 0 && claim.AmountSold() == 0 {
+				continue
+			}
+			trade := hProtocol.Trade{
+				ID:              fmt.Sprintf("%d-%d", opID, order),
+				LedgerCloseTime: closeTime,
+				BaseAmount:      amount.String(claim.AmountSold()),
+				CounterOfferID:  counterOfferID,
+				CounterAccount:  buyer.Address(),
+				CounterAmount:   amount.String(claim.AmountBought()),
+				BaseIsSeller:    true,
+			}
+			trade.PT = trade.ID
+			if err := claim.AssetSold().Extract(&trade.BaseAssetType, &trade.BaseAssetCode, &trade.BaseAssetIssuer); err != nil {
+				return nil, errors.Wrap(err, "could not read sold asset")
+			}
+			if err := claim.AssetBought().Extract(&trade.CounterAssetType, &trade.CounterAssetCode, &trade.CounterAssetIssuer); err != nil {
+				return nil, errors.Wrap(err, "could not read bought asset")
+			}
+
+			if claim.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+				poolID := claim.MustLiquidityPool().LiquidityPoolId
+				trade.TradeType = "liquidity_pool"
+				trade.BaseLiquidityPoolID = hex.EncodeToString(poolID[:])
+				trade.Price = hProtocol.TradePrice{N: int64(claim.AmountBought()), D: int64(claim.AmountSold())}
+				fee, err := claimedPoolFee(transaction, opidx, poolID)
+				if err != nil {
+					return nil, err
+				}
+				trade.LiquidityPoolFeeBP = fee
+			} else {
+				trade.TradeType = "orderbook"
+				trade.BaseOfferID = strconv.FormatInt(int64(claim.OfferId()), 10)
+				trade.OfferID = trade.BaseOfferID
+				trade.BaseAccount = claim.SellerId().Address()
+				price, err := claimedOfferPrice(transaction, opidx, claim)
+				if err != nil {
+					return nil, err
+				}
+				trade.Price = hProtocol.TradePrice{N: int64(price.N), D: int64(price.D)}
+			}
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// claimedOfferPrice returns the price of a claimed offer before the operation
+// claimed it, the price Horizon reports for the trade.
+func claimedOfferPrice(transaction ingest.LedgerTransaction, opidx int, claim xdr.ClaimAtom) (xdr.Price, error) {
+	key := xdr.LedgerKey{}
+	if err := key.SetOffer(claim.SellerId(), uint64(claim.OfferId())); err != nil {
+		return xdr.Price{}, errors.Wrap(err, "could not create offer ledger key")
+	}
+	changes, err := transaction.GetOperationChanges(uint32(opidx))
+	if err != nil {
+		return xdr.Price{}, errors.Wrap(err, "could not determine changes for operation")
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].Pre != nil && key.Equals(changes[i].Pre.LedgerKey()) {
+			return changes[i].Pre.Data.MustOffer().Price, nil
+		}
+	}
+	return xdr.Price{}, errors.Errorf("could not find change for offer %d", claim.OfferId())
+}
+
+// claimedPoolFee returns the fee of a liquidity pool claimed by an operation.
+func claimedPoolFee(transaction ingest.LedgerTransaction, opidx int, poolID xdr.PoolId) (uint32, error) {
+	key := xdr.LedgerKey{}
+	if err := key.SetLiquidityPool(poolID); err != nil {
+		return 0, errors.Wrap(err, "could not create liquidity pool ledger key")
+	}
+	changes, err := transaction.GetOperationChanges(uint32(opidx))
+	if err != nil {
+		return 0, errors.Wrap(err, "could not determine changes for operation")
+	}
+	for _, change := range changes {
+		if change.Pre != nil && key.Equals(change.Pre.LedgerKey()) {
+			params := change.Pre.Data.MustLiquidityPool().Body.MustConstantProduct().Params
+			return uint32(params.Fee), nil
+		}
+	}
+	return 0, errors.Errorf("could not find change for liquidity pool %x", poolID)
+}
+
+// ledgerChanges returns the compacted changes of a ledger to the entries of
+// the given type.
+func ledgerChanges(networkPassphrase string, meta xdr.LedgerCloseMeta, entryType xdr.LedgerEntryType) ([]ingest.Change, error) {
+	reader, err := ingest.NewLedgerChangeReaderFromLedgerCloseMeta(networkPassphrase, meta)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read ledger changes")
+	}
+	defer reader.Close()
+
+	compactor := ingest.NewChangeCompactor()
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "could not read ledger change")
+		}
+		if change.Type != entryType {
+			continue
+		}
+		if err = compactor.AddChange(change); err != nil {
+			return nil, errors.Wrap(err, "could not compact ledger change")
+		}
+	}
+	return compactor.GetChanges(), nil
+}
+
+// LedgerOffers returns the offers created or updated by a ledger and the ids
+// of the offers it removed.
+func LedgerOffers(networkPassphrase string, meta xdr.LedgerCloseMeta) (updated []tickerdb.Offer, removed []int64, err error) {
+	changes, err := ledgerChanges(networkPassphrase, meta, xdr.LedgerEntryTypeOffer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, change := range changes {
+		if change.Post == nil {
+			removed = append(removed, int64(change.Pre.Data.MustOffer().OfferId))
+			continue
+		}
+		offer, err := offerFromEntry(*change.Post)
+		if err != nil {
+			return nil, nil, err
+		}
+		updated = append(updated, offer)
+	}
+	return updated, removed, nil
+}
+
+// LedgerLiquidityPools returns the liquidity pools created or updated by a
+// ledger, in the format of the Horizon liquidity pools endpoint, and the ids
+// of the pools it removed.
+func LedgerLiquidityPools(networkPassphrase string, meta xdr.LedgerCloseMeta) (updated []hProtocol.LiquidityPool, removed []string, err error) {
+	changes, err := ledgerChanges(networkPassphrase, meta, xdr.LedgerEntryTypeLiquidityPool)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, change := range changes {
+		if change.Post == nil {
+			poolID := change.Pre.Data.MustLiquidityPool().LiquidityPoolId
+			removed = append(removed, hex.EncodeToString(poolID[:]))
+			continue
+		}
+		pool, err := liquidityPoolFromEntry(*change.Post)
+		if err != nil {
+			return nil, nil, err
+		}
+		updated = append(updated, pool)
+	}
+	return updated, removed, nil
+}
+
+// CheckpointEntries reads the offers and liquidity pools of a history archive
+// checkpoint and passes them to offerHandler and poolHandler in batches of
+// batchSize entries.
+func CheckpointEntries(
+	ctx context.Context,
+	archive historyarchive.ArchiveInterface,
+	sequence uint32,
+	batchSize int,
+	offerHandler func([]tickerdb.Offer) error,
+	poolHandler func([]hProtocol.LiquidityPool) error,
+) error {
+	reader, err := ingest.NewCheckpointChangeReader(ctx, archive, sequence)
+	if err != nil {
+		return errors.Wrap(err, "could not read checkpoint")
+	}
+	defer reader.Close()
+
+	offers := make([]tickerdb.Offer, 0, batchSize)
+	pools := make([]hProtocol.LiquidityPool, 0, batchSize)
+	for {
+		change, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read checkpoint change")
+		}
+		if change.Post == nil {
+			continue
+		}
+
+		switch change.Type {
+		case xdr.LedgerEntryTypeOffer:
+			offer, err := offerFromEntry(*change.Post)
+			if err != nil {
+				return err
+			}
+			offers = append(offers, offer)
+			if len(offers) == batchSize {
+				if err = offerHandler(offers); err != nil {
+					return err
+				}
+				offers = offers[:0]
+			}
+		case xdr.LedgerEntryTypeLiquidityPool:
+			pool, err := liquidityPoolFromEntry(*change.Post)
+			if err != nil {
+				return err
+			}
+			pools = append(pools, pool)
+			if len(pools) == batchSize {
+				if err = poolHandler(pools); err != nil {
+					return err
+				}
+				pools = pools[:0]
+			}
+		}
+	}
+	if len(offers) > 0 {
+		if err = offerHandler(offers); err != nil {
+			return err
+		}
+	}
+	if len(pools) > 0 {
+		return poolHandler(pools)
+	}
+	return nil
+}
+
+func offerFromEntry(entry xdr.LedgerEntry) (tickerdb.Offer, error) {
+	offer := entry.Data.MustOffer()
+	selling, err := assetString(offer.Selling)
+	if err != nil {
+		return tickerdb.Offer{}, err
+	}
+	buying, err := assetString(offer.Buying)
+	if err != nil {
+		return tickerdb.Offer{}, err
+	}
+	return tickerdb.Offer{
+		OfferID:            int64(offer.OfferId),
+		SellerID:           offer.SellerId.Address(),
+		SellingAsset:       selling,
+		BuyingAsset:        buying,
+		Amount:             int64(offer.Amount),
+		PriceN:             int32(offer.Price.N),
+		PriceD:             int32(offer.Price.D),
+		LastModifiedLedger: uint32(entry.LastModifiedLedgerSeq),
+	}, nil
+}
+
+// liquidityPoolFromEntry converts a liquidity pool entry to the format of the
+// Horizon liquidity pools endpoint.
+func liquidityPoolFromEntry(entry xdr.LedgerEntry) (hProtocol.LiquidityPool, error) {
+	lp := entry.Data.MustLiquidityPool()
+	cp := lp.Body.MustConstantProduct()
+	assetA, err := assetString(cp.Params.AssetA)
+	if err != nil {
+		return hProtocol.LiquidityPool{}, err
+	}
+	assetB, err := assetString(cp.Params.AssetB)
+	if err != nil {
+		return hProtocol.LiquidityPool{}, err
+	}
+	return hProtocol.LiquidityPool{
+		ID:              hex.EncodeToString(lp.LiquidityPoolId[:]),
+		FeeBP:           uint32(cp.Params.Fee),
+		Type:            "constant_product",
+		TotalTrustlines: uint64(cp.PoolSharesTrustLineCount),
+		TotalShares:     amount.String(cp.TotalPoolShares),
+		Reserves: []hProtocol.LiquidityPoolReserve{
+			{Asset: assetA, Amount: amount.String(cp.ReserveA)},
+			{Asset: assetB, Amount: amount.String(cp.ReserveB)},
+		},
+		LastModifiedLedger: uint32(entry.LastModifiedLedgerSeq),
+	}, nil
+}
+
+func assetString(asset xdr.Asset) (string, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return "", errors.Wrap(err, "could not read asset")
+	}
+	return utils.GetAssetString(assetType, code, issuer), nil
+}
+
+// OrderbookSummary returns the summary of the orderbook of the market
+// between the base and counter assets, aggregated by price level the way the
+// Horizon orderbook endpoint does. Asks sell the base asset and their amounts
+// are in base units, bids sell the counter asset and their amounts are in
+// counter units. Prices are in counter units per base unit.
+func OrderbookSummary(offers []tickerdb.Offer, baseAsset, counterAsset string) hProtocol.OrderBookSummary {
+	var asks, bids []tickerdb.Offer
+	for _, offer := range offers {
+		switch {
+		case offer.SellingAsset == baseAsset && offer.BuyingAsset == counterAsset:
+			asks = append(asks, offer)
+		case offer.SellingAsset == counterAsset && offer.BuyingAsset == baseAsset:
+			// bids are priced in counter units per base unit
+			offer.PriceN, offer.PriceD = offer.PriceD, offer.PriceN
+			bids = append(bids, offer)
+		}
+	}
+	return hProtocol.OrderBookSummary{
+		Asks: priceLevels(asks, false),
+		Bids: priceLevels(bids, true),
+	}
+}
+
+func priceLevels(offers []tickerdb.Offer, descending bool) []hProtocol.PriceLevel {
+	price := func(offer tickerdb.Offer) *big.Rat {
+		return big.NewRat(int64(offer.PriceN), int64(offer.PriceD))
+	}
+	sort.SliceStable(offers, func(i, j int) bool {
+		cmp := price(offers[i]).Cmp(price(offers[j]))
+		if descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+
+	var levels []hProtocol.PriceLevel
+	var levelPrice *big.Rat
+	var levelAmount int64
+	flush := func() {
+		levels = append(levels, hProtocol.PriceLevel{
+			PriceR: hProtocol.Price{N: int32(levelPrice.Num().Int64()), D: int32(levelPrice.Denom().Int64())},
+			Price:  levelPrice.FloatString(7),
+			Amount: amount.StringFromInt64(levelAmount),
+		})
+	}
+	for _, offer := range offers {
+		p := price(offer)
+		if levelPrice != nil && p.Cmp(levelPrice) == 0 {
+			levelAmount += offer.Amount
+			continue
+		}
+		if levelPrice != nil {
+			flush()
+			if len(levels) == orderbookLevels {
+				return levels
+			}
+		}
+		levelPrice, levelAmount = p, offer.Amount
+	}
+	if levelPrice != nil {
+		flush()
+	}
+	return levels
+}
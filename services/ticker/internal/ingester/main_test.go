@@ -0,0 +1,275 @@
+package ingester
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/go/network"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/ticker/internal/tickerdb"
+	"github.com/stellar/go/toid"
+	"github.com/stellar/go/xdr"
+)
+
+const (
+	buyer  = "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"
+	seller = "GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+)
+
+var usd = xdr.MustNewCreditAsset("USD", seller)
+
+func offerEntry(offerID int64, selling, buying xdr.Asset, amount int64, price xdr.Price) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 10,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{
+				SellerId: xdr.MustAddress(seller),
+				OfferId:  xdr.Int64(offerID),
+				Selling:  selling,
+				Buying:   buying,
+				Amount:   xdr.Int64(amount),
+				Price:    price,
+			},
+		},
+	}
+}
+
+// testLedger is a ledger in which the buyer sells 50 XLM for 25 USD, taking
+// offer 7 of the seller and creating offer 8 with the rest of its order.
+// Offer 9 is removed in the same operation.
+func testLedger(t *testing.T) xdr.LedgerCloseMeta {
+	native := xdr.MustNewNativeAsset()
+	price := xdr.Price{N: 2, D: 1}
+	envelope := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: xdr.MustMuxedAddress(buyer),
+				Operations: []xdr.Operation{{
+					Body: xdr.OperationBody{
+						Type: xdr.OperationTypeManageSellOffer,
+						ManageSellOfferOp: &xdr.ManageSellOfferOp{
+							Selling: native,
+							Buying:  usd,
+							Amount:  1000000000,
+							Price:   xdr.Price{N: 1, D: 2},
+						},
+					},
+				}},
+			},
+		},
+	}
+	hash, err := network.HashTransactionInEnvelope(envelope, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+
+	buyOffer := offerEntry(8, native, usd, 500000000, xdr.Price{N: 1, D: 2})
+	buyOffer.Data.Offer.SellerId = xdr.MustAddress(buyer)
+	results := []xdr.OperationResult{{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferResult: &xdr.ManageSellOfferResult{
+				Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+				Success: &xdr.ManageOfferSuccessResult{
+					OffersClaimed: []xdr.ClaimAtom{
+						{
+							Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+							OrderBook: &xdr.ClaimOfferAtom{
+								SellerId:     xdr.MustAddress(seller),
+								OfferId:      9,
+								AssetSold:    usd,
+								AssetBought:  native,
+								AmountSold:   0,
+								AmountBought: 0,
+							},
+						},
+						{
+							Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+							OrderBook: &xdr.ClaimOfferAtom{
+								SellerId:     xdr.MustAddress(seller),
+								OfferId:      7,
+								AssetSold:    usd,
+								AssetBought:  native,
+								AmountSold:   250000000,
+								AmountBought: 500000000,
+							},
+						},
+					},
+					Offer: xdr.ManageOfferSuccessResultOffer{
+						Effect: xdr.ManageOfferEffectManageOfferCreated,
+						Offer:  buyOffer.Data.Offer,
+					},
+				},
+			},
+		},
+	}}
+
+	return xdr.LedgerCloseMeta{
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{
+				LedgerSeq:     11,
+				LedgerVersion: 18,
+				ScpValue:      xdr.StellarValue{CloseTime: 1622548800},
+			}},
+			TxSet: xdr.TransactionSet{Txs: []xdr.TransactionEnvelope{envelope}},
+			TxProcessing: []xdr.TransactionResultMeta{{
+				Result: xdr.TransactionResultPair{
+					TransactionHash: hash,
+					Result: xdr.TransactionResult{
+						Result: xdr.TransactionResultResult{
+							Code:    xdr.TransactionResultCodeTxSuccess,
+							Results: &results,
+						},
+					},
+				},
+				TxApplyProcessing: xdr.TransactionMeta{
+					V: 2,
+					V2: &xdr.TransactionMetaV2{
+						Operations: []xdr.OperationMeta{{
+							Changes: xdr.LedgerEntryChanges{
+								{
+									Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+									State: offerEntry(9, usd, native, 0, price),
+								},
+								{
+									Type:    xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+									Removed: &xdr.LedgerKey{Type: xdr.LedgerEntryTypeOffer, Offer: &xdr.LedgerKeyOffer{SellerId: xdr.MustAddress(seller), OfferId: 9}},
+								},
+								{
+									Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+									State: offerEntry(7, usd, native, 1000000000, price),
+								},
+								{
+									Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+									Updated: offerEntry(7, usd, native, 750000000, price),
+								},
+								{
+									Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+									Created: buyOffer,
+								},
+							},
+						}},
+					},
+				},
+			}},
+		},
+	}
+}
+
+func TestLedgerTrades(t *testing.T) {
+	trades, err := LedgerTrades(network.TestNetworkPassphrase, testLedger(t))
+	require.NoError(t, err)
+	// the garbage collected offer is not a trade
+	require.Len(t, trades, 1)
+
+	opID := toid.New(11, 1, 1).ToInt64()
+	trade := trades[0]
+	assert.Equal(t, fmt.Sprintf("%d-1", opID), trade.ID)
+	assert.Equal(t, trade.ID, trade.PagingToken())
+	assert.True(t, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC).Equal(trade.LedgerCloseTime))
+	assert.Equal(t, "orderbook", trade.TradeType)
+	assert.Equal(t, "7", trade.BaseOfferID)
+	assert.Equal(t, seller, trade.BaseAccount)
+	assert.Equal(t, "25.0000000", trade.BaseAmount)
+	assert.Equal(t, "credit_alphanum4", trade.BaseAssetType)
+	assert.Equal(t, "USD", trade.BaseAssetCode)
+	assert.Equal(t, "8", trade.CounterOfferID)
+	assert.Equal(t, buyer, trade.CounterAccount)
+	assert.Equal(t, "50.0000000", trade.CounterAmount)
+	assert.Equal(t, "native", trade.CounterAssetType)
+	assert.True(t, trade.BaseIsSeller)
+	// the price of the offer before it was taken
+	assert.Equal(t, hProtocol.TradePrice{N: 2, D: 1}, trade.Price)
+}
+
+func TestLedgerOffers(t *testing.T) {
+	updated, removed, err := LedgerOffers(network.TestNetworkPassphrase, testLedger(t))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9}, removed)
+	require.Len(t, updated, 2)
+
+	byID := map[int64]tickerdb.Offer{}
+	for _, offer := range updated {
+		byID[offer.OfferID] = offer
+	}
+	assert.Equal(t, tickerdb.Offer{
+		OfferID:            7,
+		SellerID:           seller,
+		SellingAsset:       "USD:" + seller,
+		BuyingAsset:        "native",
+		Amount:             750000000,
+		PriceN:             2,
+		PriceD:             1,
+		LastModifiedLedger: 10,
+	}, byID[7])
+	assert.Equal(t, buyer, byID[8].SellerID)
+	assert.Equal(t, "native", byID[8].SellingAsset)
+}
+
+func TestOrderbookSummary(t *testing.T) {
+	base, counter := "native", "USD:"+seller
+	offers := []tickerdb.Offer{
+		// asks, in USD per XLM
+		{OfferID: 1, SellingAsset: base, BuyingAsset: counter, Amount: 100, PriceN: 1, PriceD: 2},
+		{OfferID: 2, SellingAsset: base, BuyingAsset: counter, Amount: 50, PriceN: 2, PriceD: 4},
+		{OfferID: 3, SellingAsset: base, BuyingAsset: counter, Amount: 10, PriceN: 1, PriceD: 4},
+		// bids, in XLM per USD
+		{OfferID: 4, SellingAsset: counter, BuyingAsset: base, Amount: 30, PriceN: 5, PriceD: 1},
+		{OfferID: 5, SellingAsset: counter, BuyingAsset: base, Amount: 20, PriceN: 10, PriceD: 1},
+		// another market
+		{OfferID: 6, SellingAsset: base, BuyingAsset: "EUR:" + seller, Amount: 10, PriceN: 1, PriceD: 1},
+	}
+
+	summary := OrderbookSummary(offers, base, counter)
+	if assert.Len(t, summary.Asks, 2) {
+		assert.Equal(t, hProtocol.PriceLevel{PriceR: hProtocol.Price{N: 1, D: 4}, Price: "0.2500000", Amount: "0.0000010"}, summary.Asks[0])
+		assert.Equal(t, hProtocol.PriceLevel{PriceR: hProtocol.Price{N: 1, D: 2}, Price: "0.5000000", Amount: "0.0000150"}, summary.Asks[1])
+	}
+	if assert.Len(t, summary.Bids, 2) {
+		assert.Equal(t, "0.2000000", summary.Bids[0].Price)
+		assert.Equal(t, "0.0000030", summary.Bids[0].Amount)
+		assert.Equal(t, hProtocol.Price{N: 1, D: 10}, summary.Bids[1].PriceR)
+	}
+}
+
+func TestLiquidityPoolFromEntry(t *testing.T) {
+	poolID := xdr.PoolId{0xca, 0xfe}
+	pool, err := liquidityPoolFromEntry(xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 10,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeLiquidityPool,
+			LiquidityPool: &xdr.LiquidityPoolEntry{
+				LiquidityPoolId: poolID,
+				Body: xdr.LiquidityPoolEntryBody{
+					Type: xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+					ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{
+						Params: xdr.LiquidityPoolConstantProductParameters{
+							AssetA: xdr.MustNewNativeAsset(),
+							AssetB: usd,
+							Fee:    30,
+						},
+						ReserveA:                 1000000000,
+						ReserveB:                 500000000,
+						TotalPoolShares:          700000000,
+						PoolSharesTrustLineCount: 3,
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "cafe000000000000000000000000000000000000000000000000000000000000", pool.ID)
+	assert.Equal(t, uint32(30), pool.FeeBP)
+	assert.Equal(t, uint64(3), pool.TotalTrustlines)
+	assert.Equal(t, "70.0000000", pool.TotalShares)
+	assert.Equal(t, []hProtocol.LiquidityPoolReserve{
+		{Asset: "native", Amount: "100.0000000"},
+		{Asset: "USD:" + seller, Amount: "50.0000000"},
+	}, pool.Reserves)
+	assert.Equal(t, uint32(10), pool.LastModifiedLedger)
+}
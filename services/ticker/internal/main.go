@@ -41,6 +41,12 @@ type MarketStats struct {
 	AskMin           float64 `json:"ask_min"`
 	Spread           float64 `json:"spread"`
 	SpreadMidPoint   float64 `json:"spread_mid_point"`
+
+	// Liquidity pool data, where the TVL is in units of counter
+	PoolPrice          float64 `json:"pool_price"`
+	PoolBaseReserve    float64 `json:"pool_base_reserve"`
+	PoolCounterReserve float64 `json:"pool_counter_reserve"`
+	PoolTVL            float64 `json:"pool_tvl"`
 }
 
 // Asset Sumary represents the collection of valid assets.
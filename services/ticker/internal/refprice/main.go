@@ -0,0 +1,162 @@
+// Package refprice provides the spot prices of metals and fiat currencies the
+// ticker compares the prices of metal-backed assets, such as KAU and KAG, to.
+package refprice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Source provides the spot prices of metals and fiat currencies, in USD per
+// unit. Metal prices are per troy ounce, e.g. "XAU" and "XAG".
+type Source interface {
+	Prices(ctx context.Context) (map[string]float64, error)
+}
+
+// priceDoc is the JSON document read by a JSONSource, e.g.
+// {"prices": {"XAU": 2350.1, "XAG": 29.6, "EUR": 1.08}}
+type priceDoc struct {
+	Prices map[string]float64 `json:"prices"`
+}
+
+// JSONSource reads reference prices from a JSON document, either served over
+// HTTP(S) or stored in a local file.
+type JSONSource struct {
+	Location string
+	Client   *http.Client
+}
+
+// Prices implements Source.
+func (s JSONSource) Prices(ctx context.Context) (map[string]float64, error) {
+	body, err := s.read(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read reference prices")
+	}
+
+	var doc priceDoc
+	if err = json.Unmarshal(body, &doc); err != nil {
+		return nil, errors.Wrap(err, "could not parse reference prices")
+	}
+	if len(doc.Prices) == 0 {
+		return nil, errors.Errorf("no reference prices in %s", s.Location)
+	}
+	return doc.Prices, nil
+}
+
+func (s JSONSource) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.Location, "http://") && !strings.HasPrefix(s.Location, "https://") {
+		return ioutil.ReadFile(strings.TrimPrefix(s.Location, "file://"))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.Location, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("got status code %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// CachedSource caches the prices of a Source for TTL, so that they are not
+// fetched on every query.
+type CachedSource struct {
+	Source Source
+	TTL    time.Duration
+
+	mu        sync.Mutex
+	prices    map[string]float64
+	updatedAt time.Time
+}
+
+// Prices implements Source.
+func (s *CachedSource) Prices(ctx context.Context) (map[string]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.prices != nil && time.Since(s.updatedAt) < s.TTL {
+		return s.prices, nil
+	}
+	prices, err := s.Source.Prices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.prices, s.updatedAt = prices, time.Now()
+	return prices, nil
+}
+
+// Metal describes the backing of a metal-backed asset: the metal and the troy
+// ounces of it backing one unit of the asset.
+type Metal struct {
+	Symbol string
+	Ounces float64
+}
+
+// ParseMetals parses the metals backing asset codes from a comma separated list
+// of CODE=SYMBOL:OUNCES entries, e.g. "KAU=XAU:0.0321507466,KAG=XAG:1".
+func ParseMetals(s string) (map[string]Metal, error) {
+	metals := map[string]Metal{}
+	if s == "" {
+		return metals, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		parts := strings.Split(entry, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid metal asset %q, must be CODE=SYMBOL:OUNCES", entry)
+		}
+		backing := strings.Split(parts[1], ":")
+		if len(backing) != 2 || backing[0] == "" {
+			return nil, fmt.Errorf("invalid metal asset %q, must be CODE=SYMBOL:OUNCES", entry)
+		}
+		ounces, err := strconv.ParseFloat(backing[1], 64)
+		if err != nil || ounces <= 0 {
+			return nil, fmt.Errorf("invalid ounces in metal asset %q", entry)
+		}
+		metals[parts[0]] = Metal{Symbol: backing[0], Ounces: ounces}
+	}
+	return metals, nil
+}
+
+// ReferencePrice returns the spot price of the metal backing one unit of an
+// asset, in units of a fiat currency.
+func ReferencePrice(prices map[string]float64, metal Metal, currency string) (float64, error) {
+	metalUSDPrice, ok := prices[metal.Symbol]
+	if !ok {
+		return 0, errors.Errorf("no reference price for %s", metal.Symbol)
+	}
+
+	currencyUSDPrice := 1.0
+	if currency != "USD" {
+		if currencyUSDPrice, ok = prices[currency]; !ok || currencyUSDPrice == 0 {
+			return 0, errors.Errorf("no reference price for %s", currency)
+		}
+	}
+	return metalUSDPrice * metal.Ounces / currencyUSDPrice, nil
+}
+
+// PremiumPct returns the percentage by which a market price is above
+// (premium) or below (discount) the reference price.
+func PremiumPct(marketPrice, refPrice float64) float64 {
+	if marketPrice == 0 || refPrice == 0 {
+		return 0
+	}
+	return 100 * (marketPrice - refPrice) / refPrice
+}
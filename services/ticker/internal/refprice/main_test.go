@@ -0,0 +1,118 @@
+package refprice
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSourceHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/prices.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"prices": {"XAU": 2000, "XAG": 25, "EUR": 1.25}}`))
+	}))
+	defer server.Close()
+
+	prices, err := JSONSource{Location: server.URL + "/prices.json"}.Prices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"XAU": 2000, "XAG": 25, "EUR": 1.25}, prices)
+
+	_, err = JSONSource{Location: server.URL + "/missing.json"}.Prices(context.Background())
+	assert.EqualError(t, err, "could not read reference prices: got status code 404")
+}
+
+func TestJSONSourceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "refprice")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "prices.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"prices": {"XAU": 2000}}`), 0644))
+	prices, err := JSONSource{Location: "file://" + path}.Prices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"XAU": 2000}, prices)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"prices": {}}`), 0644))
+	_, err = JSONSource{Location: path}.Prices(context.Background())
+	assert.EqualError(t, err, "no reference prices in "+path)
+}
+
+type countingSource struct {
+	calls int
+}
+
+func (s *countingSource) Prices(ctx context.Context) (map[string]float64, error) {
+	s.calls++
+	return map[string]float64{"XAU": float64(s.calls)}, nil
+}
+
+func TestCachedSource(t *testing.T) {
+	src := &countingSource{}
+	cached := &CachedSource{Source: src, TTL: time.Hour}
+
+	for i := 0; i < 3; i++ {
+		prices, err := cached.Prices(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, prices["XAU"])
+	}
+	assert.Equal(t, 1, src.calls)
+
+	cached.TTL = 0
+	prices, err := cached.Prices(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, prices["XAU"])
+}
+
+func TestParseMetals(t *testing.T) {
+	metals, err := ParseMetals("KAU=XAU:0.0321507466,KAG=XAG:1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Metal{
+		"KAU": {Symbol: "XAU", Ounces: 0.0321507466},
+		"KAG": {Symbol: "XAG", Ounces: 1},
+	}, metals)
+
+	metals, err = ParseMetals("")
+	require.NoError(t, err)
+	assert.Empty(t, metals)
+
+	for _, s := range []string{"KAU", "KAU=XAU", "=XAU:1", "KAU=XAU:abc", "KAU=XAU:0"} {
+		_, err = ParseMetals(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestReferencePrice(t *testing.T) {
+	prices := map[string]float64{"XAU": 2000, "XAG": 25, "EUR": 1.25}
+	kau := Metal{Symbol: "XAU", Ounces: 0.0321507466}
+
+	price, err := ReferencePrice(prices, kau, "USD")
+	require.NoError(t, err)
+	assert.InDelta(t, 64.3014932, price, 1e-7)
+
+	price, err = ReferencePrice(prices, Metal{Symbol: "XAG", Ounces: 1}, "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, price)
+
+	_, err = ReferencePrice(prices, kau, "GBP")
+	assert.EqualError(t, err, "no reference price for GBP")
+	_, err = ReferencePrice(prices, Metal{Symbol: "XPT", Ounces: 1}, "USD")
+	assert.EqualError(t, err, "no reference price for XPT")
+}
+
+func TestPremiumPct(t *testing.T) {
+	assert.Equal(t, 10.0, PremiumPct(22, 20))
+	assert.Equal(t, -5.0, PremiumPct(19, 20))
+	assert.Equal(t, 0.0, PremiumPct(0, 20))
+	assert.Equal(t, 0.0, PremiumPct(20, 0))
+}
@@ -0,0 +1,127 @@
+package scraper
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	horizonclient "github.com/stellar/go/clients/horizonclient"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/services/ticker/internal/utils"
+)
+
+// fetchLiquidityPools fetches the liquidity pools between the base and counter assets
+// provided in the parameters
+func (c *ScraperConfig) fetchLiquidityPools(bType, bCode, bIssuer, cType, cCode, cIssuer string) (pools []LiquidityPoolStats, err error) {
+	var page hProtocol.LiquidityPoolsPage
+	r := horizonclient.LiquidityPoolsRequest{
+		Reserves: []string{
+			utils.GetAssetString(bType, bCode, bIssuer),
+			utils.GetAssetString(cType, cCode, cIssuer),
+		},
+		// There is a single pool per fee for a given pair of assets.
+		Limit: 200,
+	}
+
+	err = utils.Retry(5, 5*time.Second, c.Logger, func() error {
+		page, err = c.Client.LiquidityPools(r)
+		if err != nil {
+			c.Logger.Info("Horizon rate limit reached!")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch liquidity pools")
+	}
+
+	for _, p := range page.Embedded.Records {
+		pool, err := NormalizeLiquidityPool(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid liquidity pool %s", p.ID)
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+// NormalizeLiquidityPool converts a Horizon liquidity pool into LiquidityPoolStats, ordering
+// its reserves the same way NormalizeTradeAssets orders the assets of a trade.
+func NormalizeLiquidityPool(p hProtocol.LiquidityPool) (pool LiquidityPoolStats, err error) {
+	if len(p.Reserves) != 2 {
+		return pool, errors.Errorf("expected 2 reserves, got %d", len(p.Reserves))
+	}
+
+	pool = LiquidityPoolStats{
+		PoolID:          p.ID,
+		FeeBP:           p.FeeBP,
+		TotalTrustlines: int64(p.TotalTrustlines),
+	}
+	pool.TotalShares, err = strconv.ParseFloat(p.TotalShares, 64)
+	if err != nil {
+		return pool, errors.Wrap(err, "invalid total shares")
+	}
+
+	base, counter := p.Reserves[0], p.Reserves[1]
+	if counter.Asset == "native" || (base.Asset != "native" && base.Asset > counter.Asset) {
+		base, counter = counter, base
+	}
+
+	pool.BaseAssetType, pool.BaseAssetCode, pool.BaseAssetIssuer, err = parseReserveAsset(base.Asset)
+	if err != nil {
+		return
+	}
+	pool.CounterAssetType, pool.CounterAssetCode, pool.CounterAssetIssuer, err = parseReserveAsset(counter.Asset)
+	if err != nil {
+		return
+	}
+	pool.BaseReserve, err = strconv.ParseFloat(base.Amount, 64)
+	if err != nil {
+		return pool, errors.Wrap(err, "invalid base reserve")
+	}
+	pool.CounterReserve, err = strconv.ParseFloat(counter.Amount, 64)
+	if err != nil {
+		return pool, errors.Wrap(err, "invalid counter reserve")
+	}
+	return
+}
+
+// parseReserveAsset parses a reserve asset in the `native` or `CODE:ISSUER`
+// format. The native asset has the "XLM" code and the "native" issuer.
+func parseReserveAsset(asset string) (assetType, code, issuer string, err error) {
+	if asset == "native" {
+		return string(horizonclient.AssetTypeNative), "XLM", "native", nil
+	}
+	parts := strings.Split(asset, ":")
+	if len(parts) != 2 {
+		return "", "", "", errors.Errorf("invalid reserve asset %s", asset)
+	}
+	assetType = string(horizonclient.AssetType4)
+	if len(parts[0]) > 4 {
+		assetType = string(horizonclient.AssetType12)
+	}
+	return assetType, parts[0], parts[1], nil
+}
+
+// AddLiquidityPoolQuotes includes the marginal quotes of the liquidity pools in the highest
+// bid, lowest ask and spread of the orderbook stats. Selling base to a pool yields its price
+// minus the fee, while buying base from it costs its price plus the fee.
+func AddLiquidityPoolQuotes(obStats *OrderbookStats, pools []LiquidityPoolStats) {
+	for _, p := range pools {
+		price := p.Price()
+		if price == 0 {
+			continue
+		}
+		fee := float64(p.FeeBP) / 10000
+		bid := price * (1 - fee)
+		ask := price / (1 - fee)
+
+		if bid > obStats.HighestBid {
+			obStats.HighestBid = bid
+		}
+		if obStats.LowestAsk == 0 || ask < obStats.LowestAsk {
+			obStats.LowestAsk = ask
+		}
+	}
+	obStats.Spread, obStats.SpreadMidPoint = utils.CalcSpread(obStats.HighestBid, obStats.LowestAsk)
+}
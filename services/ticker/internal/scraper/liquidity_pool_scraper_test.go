@@ -0,0 +1,83 @@
+package scraper
+
+import (
+	"testing"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	btcIssuer = "GATEMHCCKCY67ZUCKTROYN24ZYT5GK4EQZ65JJLDHKHRUZI3EUEKMTCH"
+	usdIssuer = "GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX"
+)
+
+func TestNormalizeLiquidityPool(t *testing.T) {
+	pool, err := NormalizeLiquidityPool(hProtocol.LiquidityPool{
+		ID:              "abcdef",
+		FeeBP:           30,
+		TotalTrustlines: 5,
+		TotalShares:     "70.0000000",
+		Reserves: []hProtocol.LiquidityPoolReserve{
+			{Asset: "USD:" + usdIssuer, Amount: "50.0000000"},
+			{Asset: "native", Amount: "100.0000000"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, LiquidityPoolStats{
+		PoolID:             "abcdef",
+		FeeBP:              30,
+		BaseAssetCode:      "XLM",
+		BaseAssetType:      "native",
+		BaseAssetIssuer:    "native",
+		CounterAssetCode:   "USD",
+		CounterAssetType:   "credit_alphanum4",
+		CounterAssetIssuer: usdIssuer,
+		BaseReserve:        100,
+		CounterReserve:     50,
+		TotalShares:        70,
+		TotalTrustlines:    5,
+	}, pool)
+	assert.Equal(t, 0.5, pool.Price())
+
+	// non-native assets are ordered alphabetically
+	pool, err = NormalizeLiquidityPool(hProtocol.LiquidityPool{
+		TotalShares: "1",
+		Reserves: []hProtocol.LiquidityPoolReserve{
+			{Asset: "USD:" + usdIssuer, Amount: "2"},
+			{Asset: "BTC:" + btcIssuer, Amount: "1"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", pool.BaseAssetCode)
+	assert.Equal(t, 1.0, pool.BaseReserve)
+	assert.Equal(t, "USD", pool.CounterAssetCode)
+	assert.Equal(t, 2.0, pool.CounterReserve)
+
+	_, err = NormalizeLiquidityPool(hProtocol.LiquidityPool{TotalShares: "1"})
+	assert.EqualError(t, err, "expected 2 reserves, got 0")
+}
+
+func TestAddLiquidityPoolQuotes(t *testing.T) {
+	pools := []LiquidityPoolStats{{FeeBP: 100, BaseReserve: 100, CounterReserve: 200}}
+
+	// the pool quotes are better than the orderbook
+	obStats := OrderbookStats{HighestBid: 1.5, LowestAsk: 2.5}
+	AddLiquidityPoolQuotes(&obStats, pools)
+	assert.Equal(t, 1.98, obStats.HighestBid)
+	assert.InDelta(t, 2.0202, obStats.LowestAsk, 0.0001)
+	assert.InDelta(t, 0.0199, obStats.Spread, 0.0001)
+
+	// the orderbook quotes are better than the pool
+	obStats = OrderbookStats{HighestBid: 1.99, LowestAsk: 2.01}
+	AddLiquidityPoolQuotes(&obStats, pools)
+	assert.Equal(t, 1.99, obStats.HighestBid)
+	assert.Equal(t, 2.01, obStats.LowestAsk)
+
+	// an empty orderbook
+	obStats = OrderbookStats{}
+	AddLiquidityPoolQuotes(&obStats, pools)
+	assert.Equal(t, 1.98, obStats.HighestBid)
+	assert.InDelta(t, 2.0202, obStats.LowestAsk, 0.0001)
+}
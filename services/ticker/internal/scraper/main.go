@@ -2,6 +2,7 @@ package scraper
 
 import (
 	"context"
+	"math"
 	"time"
 
 	horizonclient "github.com/stellar/go/clients/horizonclient"
@@ -108,6 +109,41 @@ type OrderbookStats struct {
 	LowestAsk          float64
 	Spread             float64
 	SpreadMidPoint     float64
+	Bids               []OrderbookLevel
+	Asks               []OrderbookLevel
+}
+
+// OrderbookLevel represents a price level of an orderbook, with the price in
+// units of counter per unit of base and the amount in units of base.
+type OrderbookLevel struct {
+	Price  float64
+	Amount float64
+}
+
+// LiquidityPoolStats represents the reserves of a liquidity pool, ordered the
+// same way as the assets of the trades of its market
+type LiquidityPoolStats struct {
+	PoolID             string
+	FeeBP              uint32
+	BaseAssetCode      string
+	BaseAssetType      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetType   string
+	CounterAssetIssuer string
+	BaseReserve        float64
+	CounterReserve     float64
+	TotalShares        float64
+	TotalTrustlines    int64
+}
+
+// Price returns the price implied by the reserves of the pool, in units of
+// counter per unit of base.
+func (p LiquidityPoolStats) Price() float64 {
+	if p.BaseReserve == 0 {
+		return 0
+	}
+	return p.CounterReserve / p.BaseReserve
 }
 
 // ProcessAllAssets fetches assets from the Horizon public net. If limit = 0, will fetch all assets.
@@ -153,6 +189,31 @@ func (c *ScraperConfig) FetchOrderbookForAssets(bType, bCode, bIssuer, cType, cC
 	return c.fetchOrderbook(bType, bCode, bIssuer, cType, cCode, cIssuer)
 }
 
+// FetchLiquidityPoolsForAssets fetches the liquidity pools between the base and counter assets
+// provided in the parameters
+func (c *ScraperConfig) FetchLiquidityPoolsForAssets(bType, bCode, bIssuer, cType, cCode, cIssuer string) ([]LiquidityPoolStats, error) {
+	c.Logger.Infof("Fetching liquidity pools for %s:%s / %s:%s\n", bCode, bIssuer, cCode, cIssuer)
+	return c.fetchLiquidityPools(bType, bCode, bIssuer, cType, cCode, cIssuer)
+}
+
+// OrderbookStatsFromSummary calculates the orderbook stats for the base and counter assets
+// provided in the parameters from an orderbook summary, such as the ones built from the offers
+// ingested from ledgers.
+func OrderbookStatsFromSummary(bType, bCode, bIssuer, cType, cCode, cIssuer string, summary hProtocol.OrderBookSummary) (OrderbookStats, error) {
+	obStats := OrderbookStats{
+		BaseAssetCode:      bCode,
+		BaseAssetType:      bType,
+		BaseAssetIssuer:    bIssuer,
+		CounterAssetCode:   cCode,
+		CounterAssetType:   cType,
+		CounterAssetIssuer: cIssuer,
+		HighestBid:         math.Inf(-1),
+		LowestAsk:          math.Inf(1),
+	}
+	err := calcOrderbookStats(&obStats, summary)
+	return obStats, err
+}
+
 // NormalizeTradeAssets enforces the following rules:
 // 1. native asset type refers to a "XLM" code and a "native" issuer
 // 2. native is always the base asset (and if not, base and counter are swapped)
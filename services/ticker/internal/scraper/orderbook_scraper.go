@@ -49,7 +49,7 @@ func (c *ScraperConfig) fetchOrderbook(bType, bCode, bIssuer, cType, cCode, cIss
 }
 
 // calcOrderbookStats calculates the NumBids, BidVolume, BidMax, NumAsks, AskVolume and AskMin
-// statistics for a given OrdebookStats instance
+// statistics for a given OrdebookStats instance, along with its price levels
 func calcOrderbookStats(obStats *OrderbookStats, summary hProtocol.OrderBookSummary) error {
 	// Calculate Bid Data:
 	obStats.NumBids = len(summary.Bids)
@@ -67,6 +67,10 @@ func calcOrderbookStats(obStats *OrderbookStats, summary hProtocol.OrderBookSumm
 			return errors.Wrap(err, "invalid bid amount")
 		}
 		obStats.BidVolume += amountf
+		// bid amounts are in units of counter
+		if pricef > 0 {
+			obStats.Bids = append(obStats.Bids, OrderbookLevel{Price: pricef, Amount: amountf / pricef})
+		}
 	}
 
 	// Calculate Ask Data:
@@ -85,6 +89,7 @@ func calcOrderbookStats(obStats *OrderbookStats, summary hProtocol.OrderBookSumm
 		// amount is in units of base. Therefore, real amount = amount * price
 		// See: https://github.com/stellar/go/issues/612
 		obStats.AskVolume += pricef * amountf
+		obStats.Asks = append(obStats.Asks, OrderbookLevel{Price: pricef, Amount: amountf})
 		if pricef < obStats.LowestAsk {
 			obStats.LowestAsk = pricef
 		}
@@ -113,6 +113,7 @@ func reverseAssets(trade *hProtocol.Trade) {
 	trade.BaseAssetCode, trade.CounterAssetCode = trade.CounterAssetCode, trade.BaseAssetCode
 	trade.BaseAssetType, trade.CounterAssetType = trade.CounterAssetType, trade.BaseAssetType
 	trade.BaseAssetIssuer, trade.CounterAssetIssuer = trade.CounterAssetIssuer, trade.BaseAssetIssuer
+	trade.BaseLiquidityPoolID, trade.CounterLiquidityPoolID = trade.CounterLiquidityPoolID, trade.BaseLiquidityPoolID
 
 	trade.BaseIsSeller = !trade.BaseIsSeller
 	trade.Price.N, trade.Price.D = trade.Price.D, trade.Price.N
@@ -44,6 +44,8 @@ func TestReverseAssets(t *testing.T) {
 		CounterAssetIssuer: counterAssetIssuer,
 		BaseIsSeller:       baseIsSeller,
 		Price:              price,
+
+		CounterLiquidityPoolID: "POOLID",
 	}
 
 	fmt.Println(trade1)
@@ -66,6 +68,9 @@ func TestReverseAssets(t *testing.T) {
 
 	assert.Equal(t, d, trade1.Price.N)
 	assert.Equal(t, n, trade1.Price.D)
+
+	assert.Equal(t, "POOLID", trade1.BaseLiquidityPoolID)
+	assert.Equal(t, "", trade1.CounterLiquidityPoolID)
 }
 
 func TestAddNativeData(t *testing.T) {
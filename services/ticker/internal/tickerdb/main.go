@@ -1,6 +1,9 @@
 package tickerdb
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -81,8 +84,20 @@ type Trade struct {
 	CounterAssetID  int32     `db:"counter_asset_id"`
 	BaseIsSeller    bool      `db:"base_is_seller"`
 	Price           float64   `db:"price"`
+
+	// TradeType is either "orderbook" or "liquidity_pool"
+	TradeType              string `db:"trade_type"`
+	BaseLiquidityPoolID    string `db:"base_liquidity_pool_id"`
+	CounterLiquidityPoolID string `db:"counter_liquidity_pool_id"`
+	LiquidityPoolFeeBP     uint32 `db:"liquidity_pool_fee_bp"`
 }
 
+// Trade types, as defined by Horizon.
+const (
+	TradeTypeOrderbook     = "orderbook"
+	TradeTypeLiquidityPool = "liquidity_pool"
+)
+
 // OrderbookStats represents an entry on the orderbook_stats table
 type OrderbookStats struct {
 	ID             int32     `db:"id"`
@@ -99,6 +114,122 @@ type OrderbookStats struct {
 	UpdatedAt      time.Time `db:"updated_at"`
 }
 
+// OrderbookSnapshot represents an entry on the orderbook_snapshots table,
+// the depth of a market at a given time.
+type OrderbookSnapshot struct {
+	ID             int32       `db:"id"`
+	BaseAssetID    int32       `db:"base_asset_id"`
+	CounterAssetID int32       `db:"counter_asset_id"`
+	Bids           DepthLevels `db:"bids"`
+	Asks           DepthLevels `db:"asks"`
+	CreatedAt      time.Time   `db:"created_at"`
+}
+
+// DepthLevel represents a price level of an orderbook snapshot. Prices are
+// in units of counter per unit of base and amounts in units of base.
+type DepthLevel struct {
+	Price  float64 `json:"price"`
+	Amount float64 `json:"amount"`
+}
+
+// DepthLevels is a list of price levels, stored as a jsonb column.
+type DepthLevels []DepthLevel
+
+// Value implements the database/sql/driver Valuer interface.
+func (l DepthLevels) Value() (driver.Value, error) {
+	if l == nil {
+		l = DepthLevels{}
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements the database/sql Scanner interface.
+func (l *DepthLevels) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return errors.New("could not scan depth levels: type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, l)
+}
+
+// Candle represents the OHLCV data of a market for the interval starting at
+// StartTime.
+// Note: this struct does *not* directly map to a db entity.
+type Candle struct {
+	StartTime     time.Time `db:"start_time"`
+	Open          float64   `db:"open_price"`
+	High          float64   `db:"highest_price"`
+	Low           float64   `db:"lowest_price"`
+	Close         float64   `db:"last_price"`
+	BaseVolume    float64   `db:"base_volume"`
+	CounterVolume float64   `db:"counter_volume"`
+	TradeCount    int32     `db:"trade_count"`
+}
+
+// IngestCheckpoint represents an entry on the ingest_checkpoints table, the
+// last ledger ingested by an ingestion mode and, for the modes streaming from
+// Horizon, the paging token of the last record ingested.
+type IngestCheckpoint struct {
+	Name           string    `db:"name"`
+	LedgerSequence uint32    `db:"ledger_sequence"`
+	PagingToken    string    `db:"paging_token"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// Offer represents an entry on the offers table, an offer of the orderbook
+// as of the last ingested ledger. Assets are stored as `native` or
+// `CODE:ISSUER` and amounts in stroops.
+type Offer struct {
+	OfferID            int64  `db:"offer_id"`
+	SellerID           string `db:"seller_id"`
+	SellingAsset       string `db:"selling_asset"`
+	BuyingAsset        string `db:"buying_asset"`
+	Amount             int64  `db:"amount"`
+	PriceN             int32  `db:"price_n"`
+	PriceD             int32  `db:"price_d"`
+	LastModifiedLedger uint32 `db:"last_modified_ledger"`
+}
+
+// LiquidityPool represents an entry on the liquidity_pools table. Reserves
+// follow the base/counter ordering of the trades of the same market.
+type LiquidityPool struct {
+	ID              int32     `db:"id"`
+	PoolID          string    `db:"pool_id"`
+	BaseAssetID     int32     `db:"base_asset_id"`
+	CounterAssetID  int32     `db:"counter_asset_id"`
+	FeeBP           uint32    `db:"fee_bp"`
+	BaseReserve     float64   `db:"base_reserve"`
+	CounterReserve  float64   `db:"counter_reserve"`
+	TotalShares     float64   `db:"total_shares"`
+	TotalTrustlines int64     `db:"total_trustlines"`
+	UpdatedAt       time.Time `db:"updated_at"`
+}
+
+// Price returns the price implied by the reserves of the pool, in units of
+// counter per unit of base.
+func (p LiquidityPool) Price() float64 {
+	if p.BaseReserve == 0 {
+		return 0
+	}
+	return p.CounterReserve / p.BaseReserve
+}
+
+// TVL returns the total value locked in the pool, in units of counter.
+func (p LiquidityPool) TVL() float64 {
+	return 2 * p.CounterReserve
+}
+
+// LiquidityPoolWithAssets represents a liquidity pool along with the codes and
+// issuers of its assets.
+// Note: this struct does *not* directly map to a db entity.
+type LiquidityPoolWithAssets struct {
+	LiquidityPool
+	BaseAssetCode      string `db:"base_asset_code"`
+	BaseAssetIssuer    string `db:"base_asset_issuer"`
+	CounterAssetCode   string `db:"counter_asset_code"`
+	CounterAssetIssuer string `db:"counter_asset_issuer"`
+}
+
 // Market represent the aggregated market data retrieved from the database.
 // Note: this struct does *not* directly map to a db entity.
 type Market struct {
@@ -125,6 +256,8 @@ type Market struct {
 	NumAsks            int       `db:"num_asks"`
 	AskVolume          float64   `db:"ask_volume"`
 	LowestAsk          float64   `db:"lowest_ask"`
+	PoolBaseReserve    float64   `db:"pool_base_reserve"`
+	PoolCounterReserve float64   `db:"pool_counter_reserve"`
 }
 
 // PartialMarket represents the aggregated market data for a
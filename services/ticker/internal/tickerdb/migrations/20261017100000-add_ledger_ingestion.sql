@@ -0,0 +1,24 @@
+
+-- +migrate Up
+CREATE TABLE ingest_checkpoints (
+    name text NOT NULL PRIMARY KEY,
+    ledger_sequence bigint NOT NULL,
+    paging_token text NOT NULL DEFAULT '',
+    updated_at timestamptz NOT NULL
+);
+
+CREATE TABLE offers (
+    offer_id bigint NOT NULL PRIMARY KEY,
+    seller_id text NOT NULL,
+    selling_asset text NOT NULL,
+    buying_asset text NOT NULL,
+    amount bigint NOT NULL,
+    price_n integer NOT NULL,
+    price_d integer NOT NULL,
+    last_modified_ledger bigint NOT NULL
+);
+CREATE INDEX offers_selling_buying_asset ON offers USING btree (selling_asset, buying_asset);
+
+-- +migrate Down
+DROP TABLE offers;
+DROP TABLE ingest_checkpoints;
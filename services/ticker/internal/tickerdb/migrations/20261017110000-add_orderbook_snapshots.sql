@@ -0,0 +1,20 @@
+
+-- +migrate Up
+CREATE TABLE orderbook_snapshots (
+    id serial NOT NULL PRIMARY KEY,
+
+    base_asset_id integer REFERENCES assets (id) NOT NULL,
+    counter_asset_id integer REFERENCES assets (id) NOT NULL,
+
+    bids jsonb NOT NULL,
+    asks jsonb NOT NULL,
+
+    created_at timestamptz NOT NULL
+);
+CREATE INDEX orderbook_snapshots_market_created_at ON orderbook_snapshots USING btree (base_asset_id, counter_asset_id, created_at);
+
+CREATE INDEX trades_market_ledger_close_time ON trades USING btree (base_asset_id, counter_asset_id, ledger_close_time);
+
+-- +migrate Down
+DROP INDEX trades_market_ledger_close_time;
+DROP TABLE orderbook_snapshots;
@@ -0,0 +1,35 @@
+
+-- +migrate Up
+ALTER TABLE trades
+    ADD COLUMN trade_type text NOT NULL DEFAULT 'orderbook',
+    ADD COLUMN base_liquidity_pool_id text NOT NULL DEFAULT '',
+    ADD COLUMN counter_liquidity_pool_id text NOT NULL DEFAULT '',
+    ADD COLUMN liquidity_pool_fee_bp integer NOT NULL DEFAULT 0;
+
+CREATE TABLE liquidity_pools (
+    id serial NOT NULL PRIMARY KEY,
+    pool_id text NOT NULL,
+
+    base_asset_id integer REFERENCES assets (id) NOT NULL,
+    counter_asset_id integer REFERENCES assets (id) NOT NULL,
+
+    fee_bp integer NOT NULL,
+    base_reserve double precision NOT NULL,
+    counter_reserve double precision NOT NULL,
+    total_shares double precision NOT NULL,
+    total_trustlines bigint NOT NULL,
+
+    updated_at timestamptz NOT NULL
+);
+ALTER TABLE ONLY public.liquidity_pools
+    ADD CONSTRAINT liquidity_pools_pool_id_key UNIQUE (pool_id);
+CREATE INDEX liquidity_pools_base_counter_asset ON liquidity_pools USING btree (base_asset_id, counter_asset_id);
+
+-- +migrate Down
+DROP TABLE liquidity_pools;
+
+ALTER TABLE trades
+    DROP COLUMN trade_type,
+    DROP COLUMN base_liquidity_pool_id,
+    DROP COLUMN counter_liquidity_pool_id,
+    DROP COLUMN liquidity_pool_fee_bp;
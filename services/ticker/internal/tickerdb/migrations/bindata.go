@@ -10,6 +10,9 @@
 // migrations/20190411165735-data_seed_and_indices.sql (1.522kB)
 // migrations/20190425110313-add_orderbook_stats.sql (749B)
 // migrations/20190426092321-add_aggregated_orderbook_view.sql (831B)
+// migrations/20261017100000-add_ledger_ingestion.sql (658B)
+// migrations/20261017110000-add_orderbook_snapshots.sql (649B)
+// migrations/20261017120000-add_liquidity_pools.sql (1.187kB)
 
 package bdata
 
@@ -278,6 +281,66 @@ func migrations20190426092321Add_aggregated_orderbook_viewSql() (*asset, error)
 	return a, nil
 }
 
+var _migrations20261017100000Add_ledger_ingestionSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x92\xdd\x72\xb2\x30\x10\x86\xcf\x73\x15\x7b\xa6\xdf\x7c\x7a\x05\x1e\xd1\x42\x3b\x4e\x29\x3a\x14\x66\xea\x51\x26\x92\x35\xdd\x11\x02\x25\xcb\xf4\xe7\xea\x3b\x8a\x56\x83\xda\x43\x78\x5f\xc8\x93\x67\x57\x4c\xa7\xf0\xbf\x22\xd3\x2a\x46\xc8\x1b\x71\x9f\x46\x41\x16\x41\x16\xdc\xc5\x11\x90\x35\xe8\x58\x16\x6f\x58\x6c\x9b\x9a\x2c\x3b\x18\x0b\x00\x00\xab\x2a\x04\xc6\x4f\x86\x64\x91\x41\x92\xc7\x31\x2c\xd3\xf9\x73\x90\xae\xe0\x29\x5a\x4d\xf6\x9d\x12\xb5\xc1\x56\x3a\x7c\xef\xd0\x16\x08\x6b\x32\x64\x4f\x1f\xf4\xa5\x46\x19\xb2\x46\x72\xbd\x45\x3b\xf8\x61\x18\x3d\x04\x79\x9c\xc1\x68\xd4\x57\xbb\x46\x2b\x46\x2d\x15\x03\x53\x85\x8e\x55\xd5\xf0\xf7\x6f\x5f\xfc\x9b\x09\x9f\xbe\xde\x6c\xb0\x3d\x12\xef\x1f\x24\xe9\x21\xc6\x25\xb7\xc3\xb2\xec\xab\x1e\xcf\x29\xdc\x01\x2b\xe7\x90\xaf\x15\xd6\xdd\xd7\x9f\xb9\xaa\xea\xce\xf2\x0d\x19\x2d\x15\x28\x2d\x90\x65\x34\xd8\x5e\x4d\xf5\x8d\xb4\x54\x8e\x65\x55\x6b\xda\x10\x6a\xd9\xbb\x1f\x9e\xb2\x53\x74\x30\x34\x4f\xc2\xe8\xf5\x60\x48\x1e\x2f\xe5\xb1\x2f\x92\xa3\xc0\xfc\x65\x9e\x3c\xc2\x9a\x5b\x44\x18\x7b\x02\x26\xde\x75\x77\x03\x38\xdf\xa6\xb0\xfe\xb0\x22\x4c\x17\x4b\x6f\x1e\xb3\xf3\x57\x97\x0b\x36\x13\x3f\x03\x00\xb8\x73\x6d\xb3\x92\x02\x00\x00")
+
+func migrations20261017100000Add_ledger_ingestionSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations20261017100000Add_ledger_ingestionSql,
+		"migrations/20261017100000-add_ledger_ingestion.sql",
+	)
+}
+
+func migrations20261017100000Add_ledger_ingestionSql() (*asset, error) {
+	bytes, err := migrations20261017100000Add_ledger_ingestionSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/20261017100000-add_ledger_ingestion.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x74, 0xb6, 0x7e, 0x13, 0xc3, 0x10, 0xee, 0xfc, 0xe7, 0xb, 0x6f, 0x87, 0x80, 0xfb, 0x6a, 0xfc, 0x6b, 0x3b, 0x9c, 0x5e, 0x4d, 0x70, 0x99, 0xb7, 0xb2, 0xfa, 0x2a, 0xe1, 0xad, 0x6d, 0xcf, 0xad}}
+	return a, nil
+}
+
+var _migrations20261017110000Add_orderbook_snapshotsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\x92\x41\x4f\xc2\x40\x10\x85\xef\xfb\x2b\xde\x11\x62\xf9\x05\x3d\x21\xac\x86\x88\x5b\x52\x20\x91\xd3\x66\xcb\x4e\x70\x85\x76\xc9\xce\x18\x13\x7f\xbd\xa1\x2a\x82\xc5\x44\x3d\xcf\x7b\xdf\x9b\x79\x19\x35\x18\xe0\xaa\x0e\x9b\xe4\x84\xb0\xdc\xab\x51\xa9\x87\x0b\x8d\xc5\xf0\x7a\xaa\x11\x93\xa7\x54\xc5\xb8\xb5\xdc\xb8\x3d\x3f\x46\x61\xf4\x14\x00\x04\x0f\xa6\x14\xdc\x0e\xa6\x58\xc0\x2c\xa7\x53\xcc\xca\xc9\xfd\xb0\x5c\xe1\x4e\xaf\x32\xd5\x8a\x2a\xc7\x64\x1d\x33\x89\x0d\x1e\xa1\x11\xda\x50\x42\xa9\x6f\x74\xa9\xcd\x48\xcf\xd1\xce\x18\xbd\xe0\xfb\x47\x4e\xd6\x5a\xd7\xf1\xb9\x11\x4a\xff\x70\xb7\xf6\x2a\x78\xc6\x13\xc7\xa6\xfa\xc6\x75\xbc\xed\x0e\xda\xc9\x3a\x91\x13\xf2\xd6\x09\x24\xd4\xc4\xe2\xea\xbd\xbc\x1e\x55\xaa\x9f\x7f\x76\x33\x31\x63\xfd\x70\xa9\x1b\x5b\xbb\xb4\x25\xb1\x27\xa8\xc2\x5c\x2c\x71\x39\x9f\x98\x5b\x54\x92\x88\xd0\x3b\xab\x29\xeb\x9c\x9e\xe1\x8b\xd7\xcf\xd5\xf9\x16\x92\x9c\xa7\x63\xf0\x8e\xfc\x86\x92\x5d\xef\x22\x93\x3d\x5c\x81\xc2\x7c\x48\xfe\x18\xd9\x21\x1d\x92\x4f\x5f\x65\x1c\x5f\x1a\x35\x2e\x8b\xd9\xef\x16\xc9\xdf\xb5\x3f\xbe\x55\xae\xde\x06\x00\xcf\x0c\xcd\xfa\x89\x02\x00\x00")
+
+func migrations20261017110000Add_orderbook_snapshotsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations20261017110000Add_orderbook_snapshotsSql,
+		"migrations/20261017110000-add_orderbook_snapshots.sql",
+	)
+}
+
+func migrations20261017110000Add_orderbook_snapshotsSql() (*asset, error) {
+	bytes, err := migrations20261017110000Add_orderbook_snapshotsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/20261017110000-add_orderbook_snapshots.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1f, 0xea, 0x2e, 0x63, 0xd2, 0xb0, 0x8e, 0x5, 0x33, 0xd8, 0x6d, 0xad, 0xb9, 0x57, 0x65, 0x19, 0xab, 0x89, 0x70, 0xa5, 0x31, 0x61, 0xb5, 0x26, 0xd1, 0x47, 0xb8, 0xfc, 0xa8, 0x16, 0xe2, 0xb0}}
+	return a, nil
+}
+
+var _migrations20261017120000Add_liquidity_poolsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x53\xc1\x6e\x9c\x30\x10\xbd\xfb\x2b\xe6\x96\x5d\x95\x54\xbd\x73\xa2\xc1\xa9\x50\x89\x49\x59\x90\xba\x27\xcb\xac\x27\x5b\x2b\x2c\x76\xed\xa1\xed\xf6\xeb\xab\x90\xd0\x04\xd8\x95\xb6\xea\xd5\xf3\xde\xcc\xf3\x9b\x37\xec\xfa\x1a\xde\x1d\xcc\xde\x2b\x42\xa8\x1d\x4b\xf2\x8a\x97\x50\x25\x1f\x73\x0e\xe4\x95\xc6\xc0\x00\x00\x92\x34\x85\x9b\x22\xaf\xef\xc4\xf3\xab\xa4\xa3\x43\x20\xfc\x45\x20\x8a\x0a\x44\x9d\xe7\x90\xf2\xdb\xa4\xce\x2b\xb8\xb2\x5e\xa3\x6f\xac\x7d\xbc\x8a\xe6\xe4\x46\x05\x94\xad\xf9\xde\x1b\x6d\xe8\x28\x9d\xb5\xad\x34\xfa\x5c\xa3\x25\x7f\x67\xfb\x8e\xd0\xff\x4f\x8b\x19\xf5\x01\x51\x36\x0e\x4c\x47\xb8\x47\xbf\xec\xf0\x21\x66\xec\xa6\xe4\x49\xc5\x5f\x5c\x99\xf2\x03\xac\x86\x01\x46\x43\x40\x6f\x54\xfb\xda\xe1\xbe\xcc\xee\x92\x72\x0b\x9f\xf9\xf6\xf9\x1f\x27\xa5\x46\x6c\xa8\x0d\xc6\xa8\x10\x90\x9e\x10\xa3\x9a\x92\xdf\xf2\x92\x8b\x1b\xbe\x81\xa1\x16\x60\x65\xf4\xfa\x0d\xf7\x89\x3a\x7a\xf2\xef\xec\x61\xf2\x19\x03\xa2\x57\x59\x1e\x03\xfa\x1f\x08\xda\xf6\x4d\x8b\xe0\x3c\xee\x4c\x30\xb6\x3b\xa3\xe3\x42\x38\x59\x52\xad\x0c\xdf\x94\xc7\x70\x19\x96\x7c\x1f\xa8\x35\x1d\x06\x68\xcc\xde\x74\x0b\x13\x7b\xa7\x15\xa1\x96\x8a\x80\xcc\x01\x03\xa9\x83\xa3\xdf\x7f\x51\x6c\x1d\x4f\xf2\x5d\x88\x7c\x0b\xae\x6f\x5a\xb3\x7b\x3f\xdb\xea\x9b\xd0\x88\x4d\x55\x26\x99\xa8\x66\xc1\x09\x63\xf2\xe4\x23\x1e\xa1\x16\xd9\x97\x9a\xc3\xea\xe5\x6d\x1d\x8f\x99\xc9\x44\xca\xbf\x2e\xa8\x83\xad\x93\xbd\x41\x31\x4f\x66\x80\x7a\x93\x89\x4f\xd0\x90\x47\x84\xd5\x24\x20\xd1\x62\xe9\xeb\x98\x4d\x4e\x39\xb5\x3f\x3b\x96\x96\xc5\xfd\xe9\xd4\xc6\xec\xdc\xa5\x0f\x9c\xc5\xa9\x47\x8b\xda\xe9\x4b\x5e\xe2\x46\xa1\x17\x40\x67\x90\x07\x44\xd9\xb8\x98\xfd\x19\x00\xd9\xf3\x1d\xf6\xa3\x04\x00\x00")
+
+func migrations20261017120000Add_liquidity_poolsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations20261017120000Add_liquidity_poolsSql,
+		"migrations/20261017120000-add_liquidity_pools.sql",
+	)
+}
+
+func migrations20261017120000Add_liquidity_poolsSql() (*asset, error) {
+	bytes, err := migrations20261017120000Add_liquidity_poolsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/20261017120000-add_liquidity_pools.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3a, 0x37, 0x4a, 0x3c, 0x95, 0x9d, 0x8b, 0x14, 0xe3, 0x78, 0x31, 0xf1, 0x76, 0x65, 0xaa, 0x5f, 0xe5, 0x2c, 0xb, 0x28, 0xdf, 0xf6, 0x8f, 0x9a, 0xa5, 0xd5, 0x67, 0x67, 0x8b, 0xd7, 0x98, 0x32}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -379,6 +442,9 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/20190411165735-data_seed_and_indices.sql":           migrations20190411165735Data_seed_and_indicesSql,
 	"migrations/20190425110313-add_orderbook_stats.sql":             migrations20190425110313Add_orderbook_statsSql,
 	"migrations/20190426092321-add_aggregated_orderbook_view.sql":   migrations20190426092321Add_aggregated_orderbook_viewSql,
+	"migrations/20261017100000-add_ledger_ingestion.sql":            migrations20261017100000Add_ledger_ingestionSql,
+	"migrations/20261017110000-add_orderbook_snapshots.sql":         migrations20261017110000Add_orderbook_snapshotsSql,
+	"migrations/20261017120000-add_liquidity_pools.sql":             migrations20261017120000Add_liquidity_poolsSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -433,6 +499,9 @@ var _bintree = &bintree{nil, map[string]*bintree{
 		"20190411165735-data_seed_and_indices.sql":           &bintree{migrations20190411165735Data_seed_and_indicesSql, map[string]*bintree{}},
 		"20190425110313-add_orderbook_stats.sql":             &bintree{migrations20190425110313Add_orderbook_statsSql, map[string]*bintree{}},
 		"20190426092321-add_aggregated_orderbook_view.sql":   &bintree{migrations20190426092321Add_aggregated_orderbook_viewSql, map[string]*bintree{}},
+		"20261017100000-add_ledger_ingestion.sql":            &bintree{migrations20261017100000Add_ledger_ingestionSql, map[string]*bintree{}},
+		"20261017110000-add_orderbook_snapshots.sql":         &bintree{migrations20261017110000Add_orderbook_snapshotsSql, map[string]*bintree{}},
+		"20261017120000-add_liquidity_pools.sql":             &bintree{migrations20261017120000Add_liquidity_poolsSql, map[string]*bintree{}},
 	}},
 }}
 
@@ -36,6 +36,23 @@ func (s *TickerSession) GetAssetByCodeAndIssuerAccount(ctx context.Context,
 	return
 }
 
+// GetMarketAssetIDs searches for the base and counter assets of a market
+// with the given codes and public keys, and returns their IDs in case both
+// are found.
+func (s *TickerSession) GetMarketAssetIDs(ctx context.Context,
+	baseCode string,
+	baseIssuerAccount string,
+	counterCode string,
+	counterIssuerAccount string,
+) (found bool, baseID int32, counterID int32, err error) {
+	found, baseID, err = s.GetAssetByCodeAndIssuerAccount(ctx, baseCode, baseIssuerAccount)
+	if err != nil || !found {
+		return
+	}
+	found, counterID, err = s.GetAssetByCodeAndIssuerAccount(ctx, counterCode, counterIssuerAccount)
+	return
+}
+
 // GetAllValidAssets returns a slice with all assets in the database
 // with is_valid = true
 func (s *TickerSession) GetAllValidAssets(ctx context.Context) (assets []Asset, err error) {
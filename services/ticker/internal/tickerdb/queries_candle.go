@@ -0,0 +1,56 @@
+package tickerdb
+
+import (
+	"context"
+	"time"
+)
+
+// RetrieveCandles retrieves the OHLCV candles of the market between the base
+// and counter assets for the [from, to) interval, with one candle per
+// resolution interval with trades. Trades are stored with a normalized base
+// asset, so the prices and volumes of trades stored the other way around
+// are inverted.
+func (s *TickerSession) RetrieveCandles(ctx context.Context,
+	baseAssetID int32,
+	counterAssetID int32,
+	resolution time.Duration,
+	from time.Time,
+	to time.Time,
+) (candles []Candle, err error) {
+	seconds := int64(resolution / time.Second)
+	err = s.SelectRaw(ctx, &candles, candleQuery,
+		baseAssetID, baseAssetID, baseAssetID,
+		seconds, seconds,
+		baseAssetID, counterAssetID, counterAssetID, baseAssetID,
+		from, to,
+	)
+	return
+}
+
+var candleQuery = `
+SELECT
+	to_timestamp(t.bucket) AS start_time,
+	(array_agg(t.price ORDER BY t.ledger_close_time ASC, t.id ASC))[1] AS open_price,
+	max(t.price) AS highest_price,
+	min(t.price) AS lowest_price,
+	(array_agg(t.price ORDER BY t.ledger_close_time DESC, t.id DESC))[1] AS last_price,
+	sum(t.base_amount) AS base_volume,
+	sum(t.counter_amount) AS counter_volume,
+	count(*) AS trade_count
+FROM (
+	SELECT
+		id,
+		ledger_close_time,
+		CASE WHEN base_asset_id = ? THEN price ELSE 1 / NULLIF(price, 0) END AS price,
+		CASE WHEN base_asset_id = ? THEN base_amount ELSE counter_amount END AS base_amount,
+		CASE WHEN base_asset_id = ? THEN counter_amount ELSE base_amount END AS counter_amount,
+		floor(extract(epoch FROM ledger_close_time) / ?) * ? AS bucket
+	FROM trades
+	WHERE ((base_asset_id = ? AND counter_asset_id = ?) OR (base_asset_id = ? AND counter_asset_id = ?))
+		AND ledger_close_time >= ?
+		AND ledger_close_time < ?
+) t
+WHERE t.price IS NOT NULL
+GROUP BY t.bucket
+ORDER BY t.bucket ASC;
+`
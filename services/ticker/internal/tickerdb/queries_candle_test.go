@@ -0,0 +1,219 @@
+package tickerdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrieveCandles(t *testing.T) {
+	db := dbtest.Postgres(t)
+	defer db.Close()
+
+	var session TickerSession
+	session.DB = db.Open()
+	ctx := context.Background()
+	defer session.DB.Close()
+
+	// Run migrations to make sure the tests are run
+	// on the most updated schema version
+	migrations := &migrate.FileMigrationSource{
+		Dir: "./migrations",
+	}
+	_, err := migrate.Exec(session.DB.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	xlmID, btcID := seedMarketAssets(t, &session)
+
+	start := time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		Trade{
+			HorizonID:       "hrzid1",
+			BaseAssetID:     xlmID,
+			BaseAmount:      10,
+			CounterAssetID:  btcID,
+			CounterAmount:   10,
+			Price:           1,
+			LedgerCloseTime: start.Add(5 * time.Second),
+		},
+		Trade{
+			HorizonID:       "hrzid2",
+			BaseAssetID:     xlmID,
+			BaseAmount:      10,
+			CounterAssetID:  btcID,
+			CounterAmount:   40,
+			Price:           4,
+			LedgerCloseTime: start.Add(10 * time.Second),
+		},
+		Trade{
+			HorizonID:       "hrzid3",
+			BaseAssetID:     xlmID,
+			BaseAmount:      10,
+			CounterAssetID:  btcID,
+			CounterAmount:   20,
+			Price:           2,
+			LedgerCloseTime: start.Add(50 * time.Second),
+		},
+		Trade{
+			HorizonID:       "hrzid4",
+			BaseAssetID:     xlmID,
+			BaseAmount:      5,
+			CounterAssetID:  btcID,
+			CounterAmount:   40,
+			Price:           8,
+			LedgerCloseTime: start.Add(3 * time.Minute),
+		},
+		// outside of the requested interval
+		Trade{
+			HorizonID:       "hrzid5",
+			BaseAssetID:     xlmID,
+			BaseAmount:      1,
+			CounterAssetID:  btcID,
+			CounterAmount:   1,
+			Price:           1,
+			LedgerCloseTime: start.Add(time.Hour),
+		},
+	}
+	err = session.BulkInsertTrades(ctx, trades)
+	require.NoError(t, err)
+
+	candles, err := session.RetrieveCandles(ctx, xlmID, btcID, time.Minute, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+
+	assert.True(t, start.Equal(candles[0].StartTime))
+	assert.Equal(t, 1.0, candles[0].Open)
+	assert.Equal(t, 4.0, candles[0].High)
+	assert.Equal(t, 1.0, candles[0].Low)
+	assert.Equal(t, 2.0, candles[0].Close)
+	assert.Equal(t, 30.0, candles[0].BaseVolume)
+	assert.Equal(t, 70.0, candles[0].CounterVolume)
+	assert.Equal(t, int32(3), candles[0].TradeCount)
+
+	assert.True(t, start.Add(3*time.Minute).Equal(candles[1].StartTime))
+	assert.Equal(t, 8.0, candles[1].Open)
+	assert.Equal(t, int32(1), candles[1].TradeCount)
+
+	// Requesting the market the other way around inverts the candles
+	candles, err = session.RetrieveCandles(ctx, btcID, xlmID, time.Hour, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.Equal(t, 1.0, candles[0].Open)
+	assert.Equal(t, 1.0, candles[0].High)
+	assert.Equal(t, 0.125, candles[0].Low)
+	assert.Equal(t, 0.125, candles[0].Close)
+	assert.Equal(t, 110.0, candles[0].BaseVolume)
+	assert.Equal(t, 35.0, candles[0].CounterVolume)
+}
+
+func TestOrderbookSnapshots(t *testing.T) {
+	db := dbtest.Postgres(t)
+	defer db.Close()
+
+	var session TickerSession
+	session.DB = db.Open()
+	ctx := context.Background()
+	defer session.DB.Close()
+
+	// Run migrations to make sure the tests are run
+	// on the most updated schema version
+	migrations := &migrate.FileMigrationSource{
+		Dir: "./migrations",
+	}
+	_, err := migrate.Exec(session.DB.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	xlmID, btcID := seedMarketAssets(t, &session)
+
+	now := time.Now().Truncate(time.Second)
+	oneHourAgo := now.Add(-time.Hour)
+	oneDayAgo := now.AddDate(0, 0, -1)
+	for _, createdAt := range []time.Time{oneDayAgo, oneHourAgo} {
+		err = session.InsertOrderbookSnapshot(ctx, &OrderbookSnapshot{
+			BaseAssetID:    xlmID,
+			CounterAssetID: btcID,
+			Bids:           DepthLevels{{Price: 2, Amount: 10}},
+			Asks:           DepthLevels{{Price: 4, Amount: 5}, {Price: 5, Amount: 1}},
+			CreatedAt:      createdAt,
+		})
+		require.NoError(t, err)
+	}
+
+	snapshots, err := session.RetrieveOrderbookSnapshots(ctx, xlmID, btcID, oneDayAgo, now, 10)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.True(t, oneDayAgo.Equal(snapshots[0].CreatedAt))
+	assert.Equal(t, DepthLevels{{Price: 2, Amount: 10}}, snapshots[0].Bids)
+
+	snapshot, found, err := session.GetOrderbookSnapshotAt(ctx, btcID, xlmID, now)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.True(t, oneHourAgo.Equal(snapshot.CreatedAt))
+	assert.Equal(t, DepthLevels{{Price: 0.25, Amount: 20}, {Price: 0.2, Amount: 5}}, snapshot.Bids)
+	assert.Equal(t, DepthLevels{{Price: 0.5, Amount: 20}}, snapshot.Asks)
+
+	_, found, err = session.GetOrderbookSnapshotAt(ctx, xlmID, btcID, oneDayAgo.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	err = session.DeleteOldOrderbookSnapshots(ctx, oneHourAgo)
+	require.NoError(t, err)
+	snapshots, err = session.RetrieveOrderbookSnapshots(ctx, xlmID, btcID, oneDayAgo, now, 10)
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+}
+
+func TestInvertedOrderbookSnapshot(t *testing.T) {
+	snapshot := OrderbookSnapshot{
+		BaseAssetID:    1,
+		CounterAssetID: 2,
+		Bids:           DepthLevels{{Price: 2, Amount: 10}},
+		Asks:           DepthLevels{{Price: 4, Amount: 5}, {Price: 0, Amount: 1}},
+	}
+
+	inverted := snapshot.inverted()
+	assert.Equal(t, int32(2), inverted.BaseAssetID)
+	assert.Equal(t, int32(1), inverted.CounterAssetID)
+	assert.Equal(t, DepthLevels{{Price: 0.25, Amount: 20}}, inverted.Bids)
+	assert.Equal(t, DepthLevels{{Price: 0.5, Amount: 20}}, inverted.Asks)
+}
+
+// seedMarketAssets inserts the valid XLM and BTC assets, returning their IDs.
+func seedMarketAssets(t *testing.T, session *TickerSession) (xlmID, btcID int32) {
+	ctx := context.Background()
+	tbl := session.GetTable("issuers")
+	_, err := tbl.Insert(Issuer{
+		PublicKey: "GCF3TQXKZJNFJK7HCMNE2O2CUNKCJH2Y2ROISTBPLC7C5EIA5NNG2XZB",
+		Name:      "FOO BAR",
+	}).IgnoreCols("id").Exec(ctx)
+	require.NoError(t, err)
+	var issuer Issuer
+	err = session.GetRaw(ctx, &issuer, "SELECT * FROM issuers ORDER BY id DESC LIMIT 1")
+	require.NoError(t, err)
+
+	err = session.InsertOrUpdateAsset(ctx, &Asset{
+		Code:          "XLM",
+		IssuerAccount: "native",
+		IssuerID:      issuer.ID,
+		IsValid:       true,
+	}, []string{"code", "issuer_id"})
+	require.NoError(t, err)
+	err = session.InsertOrUpdateAsset(ctx, &Asset{
+		Code:          "BTC",
+		IssuerAccount: issuer.PublicKey,
+		IssuerID:      issuer.ID,
+		IsValid:       true,
+	}, []string{"code", "issuer_id"})
+	require.NoError(t, err)
+
+	found, xlmID, btcID, err := session.GetMarketAssetIDs(ctx, "XLM", "native", "BTC", issuer.PublicKey)
+	require.NoError(t, err)
+	require.True(t, found)
+	return
+}
@@ -0,0 +1,59 @@
+package tickerdb
+
+import (
+	"context"
+	"time"
+)
+
+// GetIngestCheckpoint returns the last ledger ingested by the ingestion
+// mode called name, and whether it ingested any ledger yet.
+func (s *TickerSession) GetIngestCheckpoint(ctx context.Context, name string) (sequence uint32, found bool, err error) {
+	var checkpoint IngestCheckpoint
+	err = s.GetRaw(ctx, &checkpoint, "SELECT * FROM ingest_checkpoints WHERE name = ?", name)
+	if s.NoRows(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return
+	}
+	return checkpoint.LedgerSequence, true, nil
+}
+
+// UpdateIngestCheckpoint stores the last ledger ingested by the ingestion
+// mode called name. It should be called in the same transaction as the
+// changes of the ledger so that ingestion resumes exactly where it stopped.
+func (s *TickerSession) UpdateIngestCheckpoint(ctx context.Context, name string, sequence uint32) error {
+	return s.performUpsertQuery(ctx, IngestCheckpoint{
+		Name:           name,
+		LedgerSequence: sequence,
+		UpdatedAt:      time.Now(),
+	}, "ingest_checkpoints", "ingest_checkpoints_pkey", nil)
+}
+
+// GetIngestPagingToken returns the paging token of the last record streamed
+// from Horizon by the ingestion mode called name, an empty string if it did
+// not ingest any record yet.
+func (s *TickerSession) GetIngestPagingToken(ctx context.Context, name string) (string, error) {
+	var checkpoint IngestCheckpoint
+	err := s.GetRaw(ctx, &checkpoint, "SELECT * FROM ingest_checkpoints WHERE name = ?", name)
+	if s.NoRows(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return checkpoint.PagingToken, nil
+}
+
+// UpdateIngestPagingToken stores the paging token of the last record, of the
+// given ledger, streamed from Horizon by the ingestion mode called name. It
+// should be called in the same transaction as the changes of the record so
+// that streaming resumes exactly where it stopped.
+func (s *TickerSession) UpdateIngestPagingToken(ctx context.Context, name string, sequence uint32, pagingToken string) error {
+	return s.performUpsertQuery(ctx, IngestCheckpoint{
+		Name:           name,
+		LedgerSequence: sequence,
+		PagingToken:    pagingToken,
+		UpdatedAt:      time.Now(),
+	}, "ingest_checkpoints", "ingest_checkpoints_pkey", nil)
+}
@@ -0,0 +1,88 @@
+package tickerdb
+
+import (
+	"context"
+	"strings"
+)
+
+// InsertOrUpdateLiquidityPool inserts a LiquidityPool entry on the database (if new),
+// or updates an existing one
+func (s *TickerSession) InsertOrUpdateLiquidityPool(ctx context.Context, p *LiquidityPool, preserveFields []string) (err error) {
+	return s.performUpsertQuery(ctx, *p, "liquidity_pools", "liquidity_pools_pool_id_key", preserveFields)
+}
+
+// DeleteLiquidityPools deletes the liquidity pools with the given pool ids.
+func (s *TickerSession) DeleteLiquidityPools(ctx context.Context, poolIDs []string) (err error) {
+	if len(poolIDs) == 0 {
+		return
+	}
+	args := make([]interface{}, len(poolIDs))
+	for i, id := range poolIDs {
+		args[i] = id
+	}
+	_, err = s.ExecRaw(ctx, "DELETE FROM liquidity_pools WHERE pool_id IN ("+generatePlaceholders(args)+")", args...)
+	return
+}
+
+// DeleteAllLiquidityPools empties the liquidity_pools table, before the pools
+// are loaded from a history archive checkpoint.
+func (s *TickerSession) DeleteAllLiquidityPools(ctx context.Context) (err error) {
+	_, err = s.ExecRaw(ctx, "DELETE FROM liquidity_pools")
+	return
+}
+
+// GetMarketLiquidityPools returns the liquidity pools of the market between
+// the base and counter assets.
+func (s *TickerSession) GetMarketLiquidityPools(ctx context.Context, baseAssetID, counterAssetID int32) (pools []LiquidityPool, err error) {
+	err = s.SelectRaw(ctx, &pools, `
+		SELECT * FROM liquidity_pools
+		WHERE base_asset_id = ? AND counter_asset_id = ?
+		ORDER BY pool_id`,
+		baseAssetID, counterAssetID,
+	)
+	return
+}
+
+// RetrieveLiquidityPools retrieves the liquidity pools between valid assets,
+// optionally filtered by the codes and issuers of their base and counter
+// assets.
+func (s *TickerSession) RetrieveLiquidityPools(ctx context.Context,
+	baseAssetCode *string,
+	baseAssetIssuer *string,
+	counterAssetCode *string,
+	counterAssetIssuer *string,
+) (pools []LiquidityPoolWithAssets, err error) {
+	sqlTrue := new(string)
+	*sqlTrue = "TRUE"
+
+	where, args := generateWhereClause([]optionalVar{
+		optionalVar{"bAsset.is_valid", sqlTrue},
+		optionalVar{"cAsset.is_valid", sqlTrue},
+		optionalVar{"bAsset.code", baseAssetCode},
+		optionalVar{"bAsset.issuer_account", baseAssetIssuer},
+		optionalVar{"cAsset.code", counterAssetCode},
+		optionalVar{"cAsset.issuer_account", counterAssetIssuer},
+	})
+	q := strings.Replace(liquidityPoolQuery, "__WHERECLAUSE__", where, -1)
+
+	argsInterface := make([]interface{}, len(args))
+	for i, v := range args {
+		argsInterface[i] = v
+	}
+	err = s.SelectRaw(ctx, &pools, q, argsInterface...)
+	return
+}
+
+var liquidityPoolQuery = `
+SELECT
+	lp.*,
+	bAsset.code AS base_asset_code,
+	bAsset.issuer_account AS base_asset_issuer,
+	cAsset.code AS counter_asset_code,
+	cAsset.issuer_account AS counter_asset_issuer
+FROM liquidity_pools AS lp
+	JOIN assets AS bAsset ON lp.base_asset_id = bAsset.id
+	JOIN assets AS cAsset ON lp.counter_asset_id = cAsset.id
+__WHERECLAUSE__
+ORDER BY lp.counter_reserve DESC, lp.pool_id;
+`
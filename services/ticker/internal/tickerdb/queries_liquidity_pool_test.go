@@ -0,0 +1,81 @@
+package tickerdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiquidityPools(t *testing.T) {
+	db := dbtest.Postgres(t)
+	defer db.Close()
+
+	var session TickerSession
+	session.DB = db.Open()
+	ctx := context.Background()
+	defer session.DB.Close()
+
+	// Run migrations to make sure the tests are run
+	// on the most updated schema version
+	migrations := &migrate.FileMigrationSource{
+		Dir: "./migrations",
+	}
+	_, err := migrate.Exec(session.DB.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	xlmID, btcID := seedMarketAssets(t, &session)
+
+	pool := LiquidityPool{
+		PoolID:          "abcdef",
+		BaseAssetID:     xlmID,
+		CounterAssetID:  btcID,
+		FeeBP:           30,
+		BaseReserve:     100,
+		CounterReserve:  50,
+		TotalShares:     70,
+		TotalTrustlines: 2,
+		UpdatedAt:       time.Now(),
+	}
+	err = session.InsertOrUpdateLiquidityPool(ctx, &pool, []string{"pool_id"})
+	require.NoError(t, err)
+
+	// Updating the reserves of the pool
+	pool.BaseReserve = 200
+	pool.CounterReserve = 25
+	err = session.InsertOrUpdateLiquidityPool(ctx, &pool, []string{"pool_id"})
+	require.NoError(t, err)
+
+	pools, err := session.GetMarketLiquidityPools(ctx, xlmID, btcID)
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+	assert.Equal(t, 200.0, pools[0].BaseReserve)
+	assert.Equal(t, 25.0, pools[0].CounterReserve)
+	assert.Equal(t, 0.125, pools[0].Price())
+	assert.Equal(t, 50.0, pools[0].TVL())
+
+	btc := "BTC"
+	poolsWithAssets, err := session.RetrieveLiquidityPools(ctx, nil, nil, &btc, nil)
+	require.NoError(t, err)
+	require.Len(t, poolsWithAssets, 1)
+	assert.Equal(t, "abcdef", poolsWithAssets[0].PoolID)
+	assert.Equal(t, "XLM", poolsWithAssets[0].BaseAssetCode)
+	assert.Equal(t, "native", poolsWithAssets[0].BaseAssetIssuer)
+	assert.Equal(t, "BTC", poolsWithAssets[0].CounterAssetCode)
+
+	xlm := "XLM"
+	poolsWithAssets, err = session.RetrieveLiquidityPools(ctx, nil, nil, &xlm, nil)
+	require.NoError(t, err)
+	assert.Len(t, poolsWithAssets, 0)
+
+	err = session.DeleteLiquidityPools(ctx, []string{"abcdef"})
+	require.NoError(t, err)
+	pools, err = session.GetMarketLiquidityPools(ctx, xlmID, btcID)
+	require.NoError(t, err)
+	assert.Len(t, pools, 0)
+}
@@ -137,7 +137,10 @@ SELECT
 	COALESCE(os.highest_bid, 0.0) as highest_bid,
 	COALESCE(os.num_asks, 0) as num_asks,
 	COALESCE(os.ask_volume, 0.0) as ask_volume,
-	COALESCE(os.lowest_ask, 0.0) as lowest_ask
+	COALESCE(os.lowest_ask, 0.0) as lowest_ask,
+
+	COALESCE(lp.base_reserve, 0.0) as pool_base_reserve,
+	COALESCE(lp.counter_reserve, 0.0) as pool_counter_reserve
 FROM (
 	SELECT
 			-- All valid trades for 24h period
@@ -189,7 +192,24 @@ FROM (
 			AND t.ledger_close_time > now() - interval '7 days'
 		GROUP BY trade_pair_name
 	) t2 ON t1.trade_pair_name = t2.trade_pair_name
-	LEFT JOIN aggregated_orderbook AS os ON t2.trade_pair_name = os.trade_pair_name;
+	LEFT JOIN aggregated_orderbook AS os ON t2.trade_pair_name = os.trade_pair_name
+	LEFT JOIN (
+	SELECT
+			-- Reserves of all valid liquidity pools
+			concat(
+				COALESCE(NULLIF(bAsset.anchor_asset_code, ''), bAsset.code),
+				'_',
+				COALESCE(NULLIF(cAsset.anchor_asset_code, ''), cAsset.code)
+			) as trade_pair_name,
+			sum(lp.base_reserve) AS base_reserve,
+			sum(lp.counter_reserve) AS counter_reserve
+		FROM liquidity_pools AS lp
+			JOIN assets AS bAsset ON lp.base_asset_id = bAsset.id
+			JOIN assets AS cAsset on lp.counter_asset_id = cAsset.id
+		WHERE bAsset.is_valid = TRUE
+			AND cAsset.is_valid = TRUE
+		GROUP BY trade_pair_name
+	) lp ON t2.trade_pair_name = lp.trade_pair_name;
 `
 
 var partialMarketQuery = `
@@ -0,0 +1,73 @@
+package tickerdb
+
+import (
+	"context"
+	"strings"
+)
+
+// BulkUpsertOffers inserts a slice of offers in the database, replacing the
+// offers which are already in the database.
+func (s *TickerSession) BulkUpsertOffers(ctx context.Context, offers []Offer) (err error) {
+	for start := 0; start < len(offers); start += 50 {
+		end := start + 50
+		if end > len(offers) {
+			end = len(offers)
+		}
+		err = performUpsertOffers(ctx, s, offers[start:end])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// DeleteOffers deletes the offers with the given ids.
+func (s *TickerSession) DeleteOffers(ctx context.Context, offerIDs []int64) (err error) {
+	if len(offerIDs) == 0 {
+		return
+	}
+	args := make([]interface{}, len(offerIDs))
+	for i, id := range offerIDs {
+		args[i] = id
+	}
+	_, err = s.ExecRaw(ctx, "DELETE FROM offers WHERE offer_id IN ("+generatePlaceholders(args)+")", args...)
+	return
+}
+
+// DeleteAllOffers empties the offers table, before the orderbook is loaded
+// from a history archive checkpoint.
+func (s *TickerSession) DeleteAllOffers(ctx context.Context) (err error) {
+	_, err = s.ExecRaw(ctx, "DELETE FROM offers")
+	return
+}
+
+// GetMarketOffers returns the offers of the market between the base and
+// counter assets, in both directions. Assets are `native` or `CODE:ISSUER`.
+func (s *TickerSession) GetMarketOffers(ctx context.Context, baseAsset, counterAsset string) (offers []Offer, err error) {
+	err = s.SelectRaw(ctx, &offers, `
+		SELECT * FROM offers
+		WHERE (selling_asset = ? AND buying_asset = ?) OR (selling_asset = ? AND buying_asset = ?)`,
+		baseAsset, counterAsset, counterAsset, baseAsset,
+	)
+	return
+}
+
+func performUpsertOffers(ctx context.Context, s *TickerSession, offers []Offer) (err error) {
+	var o Offer
+	var placeholders []string
+	var dbValues []interface{}
+
+	dbFields := getDBFieldTags(o, false)
+	for _, offer := range offers {
+		v := getDBFieldValues(offer, false)
+		placeholders = append(placeholders, "("+generatePlaceholders(v)+")")
+		dbValues = append(dbValues, v...)
+	}
+
+	qs := "INSERT INTO offers (" + strings.Join(dbFields, ", ") + ")"
+	qs += " VALUES " + strings.Join(placeholders, ",")
+	qs += " " + createOnConflictFragment("offers_pkey", dbFields) + ";"
+
+	_, err = s.ExecRaw(ctx, qs, dbValues...)
+	return
+}
@@ -0,0 +1,76 @@
+package tickerdb
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/lib/pq"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stellar/go/support/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffersAndIngestCheckpoint(t *testing.T) {
+	db := dbtest.Postgres(t)
+	defer db.Close()
+
+	var session TickerSession
+	session.DB = db.Open()
+	ctx := context.Background()
+	defer session.DB.Close()
+
+	// Run migrations to make sure the tests are run
+	// on the most updated schema version
+	migrations := &migrate.FileMigrationSource{
+		Dir: "./migrations",
+	}
+	_, err := migrate.Exec(session.DB.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	_, found, err := session.GetIngestCheckpoint(ctx, "ledgers")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, session.UpdateIngestCheckpoint(ctx, "ledgers", 100))
+	require.NoError(t, session.UpdateIngestCheckpoint(ctx, "ledgers", 101))
+	sequence, found, err := session.GetIngestCheckpoint(ctx, "ledgers")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint32(101), sequence)
+
+	pagingToken, err := session.GetIngestPagingToken(ctx, "horizon_trades")
+	require.NoError(t, err)
+	assert.Equal(t, "", pagingToken)
+	require.NoError(t, session.UpdateIngestPagingToken(ctx, "horizon_trades", 102, "438086668289-0"))
+	pagingToken, err = session.GetIngestPagingToken(ctx, "horizon_trades")
+	require.NoError(t, err)
+	assert.Equal(t, "438086668289-0", pagingToken)
+
+	usd := "USD:GC3C4AKRBQLHOJ45U4XG35ESVWRDECWO5XLDGYADO6DPR3L7KIDVUMML"
+	offers := []Offer{
+		{OfferID: 1, SellerID: "SELLER", SellingAsset: "native", BuyingAsset: usd, Amount: 10, PriceN: 1, PriceD: 2, LastModifiedLedger: 100},
+		{OfferID: 2, SellerID: "SELLER", SellingAsset: usd, BuyingAsset: "native", Amount: 20, PriceN: 2, PriceD: 1, LastModifiedLedger: 100},
+		{OfferID: 3, SellerID: "SELLER", SellingAsset: "native", BuyingAsset: "EUR:ISSUER", Amount: 30, PriceN: 1, PriceD: 1, LastModifiedLedger: 100},
+	}
+	require.NoError(t, session.BulkUpsertOffers(ctx, offers))
+
+	// Updating an offer replaces it
+	offers[0].Amount = 5
+	offers[0].LastModifiedLedger = 101
+	require.NoError(t, session.BulkUpsertOffers(ctx, offers[:1]))
+
+	marketOffers, err := session.GetMarketOffers(ctx, "native", usd)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, offers[:2], marketOffers)
+
+	require.NoError(t, session.DeleteOffers(ctx, []int64{2}))
+	marketOffers, err = session.GetMarketOffers(ctx, usd, "native")
+	require.NoError(t, err)
+	assert.Equal(t, offers[:1], marketOffers)
+
+	require.NoError(t, session.DeleteAllOffers(ctx))
+	marketOffers, err = session.GetMarketOffers(ctx, "native", "EUR:ISSUER")
+	require.NoError(t, err)
+	assert.Empty(t, marketOffers)
+}
@@ -2,6 +2,8 @@ package tickerdb
 
 import (
 	"context"
+	"strings"
+	"time"
 )
 
 // InsertOrUpdateOrderbookStats inserts an OrdebookStats entry on the database (if new),
@@ -9,3 +11,95 @@ import (
 func (s *TickerSession) InsertOrUpdateOrderbookStats(ctx context.Context, o *OrderbookStats, preserveFields []string) (err error) {
 	return s.performUpsertQuery(ctx, *o, "orderbook_stats", "orderbook_stats_base_counter_asset_key", preserveFields)
 }
+
+// InsertOrderbookSnapshot inserts an OrderbookSnapshot entry on the database.
+func (s *TickerSession) InsertOrderbookSnapshot(ctx context.Context, o *OrderbookSnapshot) (err error) {
+	dbFields := getDBFieldTags(*o, true)
+	dbValues := getDBFieldValues(*o, true)
+
+	qs := "INSERT INTO orderbook_snapshots (" + strings.Join(dbFields, ", ") + ")"
+	qs += " VALUES (" + generatePlaceholders(dbValues) + ");"
+	_, err = s.ExecRaw(ctx, qs, dbValues...)
+	return
+}
+
+// RetrieveOrderbookSnapshots retrieves at most limit snapshots of the market
+// between the base and counter assets taken during the [from, to) interval,
+// oldest first. Snapshots stored the other way around are inverted.
+func (s *TickerSession) RetrieveOrderbookSnapshots(ctx context.Context,
+	baseAssetID int32,
+	counterAssetID int32,
+	from time.Time,
+	to time.Time,
+	limit int,
+) (snapshots []OrderbookSnapshot, err error) {
+	err = s.SelectRaw(ctx, &snapshots, `
+		SELECT * FROM orderbook_snapshots
+		WHERE ((base_asset_id = ? AND counter_asset_id = ?) OR (base_asset_id = ? AND counter_asset_id = ?))
+			AND created_at >= ?
+			AND created_at < ?
+		ORDER BY created_at ASC
+		LIMIT ?`,
+		baseAssetID, counterAssetID, counterAssetID, baseAssetID, from, to, limit,
+	)
+	for i := range snapshots {
+		if snapshots[i].BaseAssetID != baseAssetID {
+			snapshots[i] = snapshots[i].inverted()
+		}
+	}
+	return
+}
+
+// GetOrderbookSnapshotAt returns the last snapshot of the market between the
+// base and counter assets taken at or before the given time. found is false
+// if there is none.
+func (s *TickerSession) GetOrderbookSnapshotAt(ctx context.Context,
+	baseAssetID int32,
+	counterAssetID int32,
+	at time.Time,
+) (snapshot OrderbookSnapshot, found bool, err error) {
+	err = s.GetRaw(ctx, &snapshot, `
+		SELECT * FROM orderbook_snapshots
+		WHERE ((base_asset_id = ? AND counter_asset_id = ?) OR (base_asset_id = ? AND counter_asset_id = ?))
+			AND created_at <= ?
+		ORDER BY created_at DESC
+		LIMIT 1`,
+		baseAssetID, counterAssetID, counterAssetID, baseAssetID, at,
+	)
+	if s.NoRows(err) {
+		return snapshot, false, nil
+	}
+	if err != nil {
+		return
+	}
+	if snapshot.BaseAssetID != baseAssetID {
+		snapshot = snapshot.inverted()
+	}
+	return snapshot, true, nil
+}
+
+// DeleteOldOrderbookSnapshots deletes orderbook snapshots in the database
+// older than minDate.
+func (s *TickerSession) DeleteOldOrderbookSnapshots(ctx context.Context, minDate time.Time) error {
+	_, err := s.ExecRaw(ctx, "DELETE FROM orderbook_snapshots WHERE created_at < ?", minDate)
+	return err
+}
+
+// inverted returns the snapshot of the market with the base and counter
+// assets swapped: bids become asks with inverted prices, and amounts are
+// converted to units of the new base asset.
+func (o OrderbookSnapshot) inverted() OrderbookSnapshot {
+	invert := func(levels DepthLevels) DepthLevels {
+		inv := make(DepthLevels, 0, len(levels))
+		for _, l := range levels {
+			if l.Price == 0 {
+				continue
+			}
+			inv = append(inv, DepthLevel{Price: 1 / l.Price, Amount: l.Amount * l.Price})
+		}
+		return inv
+	}
+	o.BaseAssetID, o.CounterAssetID = o.CounterAssetID, o.BaseAssetID
+	o.Bids, o.Asks = invert(o.Asks), invert(o.Bids)
+	return o
+}
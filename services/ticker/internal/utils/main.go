@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -81,6 +82,39 @@ func CalcSpread(bidMax float64, askMin float64) (spread float64, midPoint float6
 	return
 }
 
+// MaxCandles is the maximum number of candles returned for a single query.
+const MaxCandles = 1000
+
+// CandleResolutions are the supported candle resolutions.
+var CandleResolutions = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"1d":  24 * time.Hour,
+}
+
+// ParseCandleResolution parses a candle resolution (1m, 5m, 15m, 1h or 1d).
+func ParseCandleResolution(resolution string) (time.Duration, error) {
+	d, ok := CandleResolutions[resolution]
+	if !ok {
+		return 0, fmt.Errorf("invalid resolution %q, must be one of 1m, 5m, 15m, 1h or 1d", resolution)
+	}
+	return d, nil
+}
+
+// ValidateCandleRange validates that the [from, to) interval is not empty and
+// does not span more than MaxCandles candles of the given resolution.
+func ValidateCandleRange(from, to time.Time, resolution time.Duration) error {
+	if !from.Before(to) {
+		return errors.New("from must be before to")
+	}
+	if to.Sub(from)/resolution > MaxCandles {
+		return fmt.Errorf("the interval cannot span more than %d candles", MaxCandles)
+	}
+	return nil
+}
+
 // Retry retries running a function that returns an error numRetries times, multiplying
 // the sleep time by a factor of 2 each time it retries.
 func Retry(numRetries int, delay time.Duration, logger *hlog.Entry, f func() error) error {
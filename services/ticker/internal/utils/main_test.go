@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,3 +17,20 @@ func TestSliceDiff(t *testing.T) {
 	assert.NotContains(t, diff, "b")
 	assert.Equal(t, 1, len(diff))
 }
+
+func TestParseCandleResolution(t *testing.T) {
+	d, err := ParseCandleResolution("15m")
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, d)
+
+	_, err = ParseCandleResolution("2h")
+	assert.EqualError(t, err, `invalid resolution "2h", must be one of 1m, 5m, 15m, 1h or 1d`)
+}
+
+func TestValidateCandleRange(t *testing.T) {
+	from := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, ValidateCandleRange(from, from.Add(1000*time.Minute), time.Minute))
+	assert.EqualError(t, ValidateCandleRange(from, from.Add(1001*time.Minute), time.Minute), "the interval cannot span more than 1000 candles")
+	assert.EqualError(t, ValidateCandleRange(from, from, time.Hour), "from must be before to")
+}
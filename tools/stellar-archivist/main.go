@@ -50,6 +50,7 @@ type Options struct {
 	Profile     bool
 	Debug       bool
 	Trace       bool
+	CacheSize   int64
 	CommandOpts historyarchive.CommandOptions
 	ConnectOpts historyarchive.ConnectOptions
 }
@@ -94,6 +95,14 @@ func (opts *Options) SetRange(srcArch *historyarchive.Archive, dstArch *historya
 
 }
 
+// DestinationConnectOpts returns the options to connect to the archive
+// written by mirror and repair, which is not cached.
+func (opts *Options) DestinationConnectOpts() historyarchive.ConnectOptions {
+	connectOpts := opts.ConnectOpts
+	connectOpts.Cache = historyarchive.CacheOptions{}
+	return connectOpts
+}
+
 func (opts *Options) MaybeProfile() {
 	if opts.Profile {
 		go func() {
@@ -144,7 +153,7 @@ func scan(a string, opts *Options) {
 
 func mirror(src string, dst string, opts *Options) {
 	srcArch := historyarchive.MustConnect(src, opts.ConnectOpts)
-	dstArch := historyarchive.MustConnect(dst, opts.ConnectOpts)
+	dstArch := historyarchive.MustConnect(dst, opts.DestinationConnectOpts())
 	opts.SetRange(srcArch, dstArch)
 	log.Printf("mirroring %v -> %v\n", src, dst)
 	e := historyarchive.Mirror(srcArch, dstArch, &opts.CommandOpts)
@@ -155,7 +164,7 @@ func mirror(src string, dst string, opts *Options) {
 
 func repair(src string, dst string, opts *Options) {
 	srcArch := historyarchive.MustConnect(src, opts.ConnectOpts)
-	dstArch := historyarchive.MustConnect(dst, opts.ConnectOpts)
+	dstArch := historyarchive.MustConnect(dst, opts.DestinationConnectOpts())
 	opts.SetRange(srcArch, dstArch)
 	log.Printf("repairing %v -> %v\n", src, dst)
 	e := historyarchive.Repair(srcArch, dstArch, &opts.CommandOpts)
@@ -176,6 +185,9 @@ func main() {
 			cmd.Help()
 			os.Exit(0)
 		},
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			opts.ConnectOpts.Cache.MaxSize = opts.CacheSize << 20
+		},
 	}
 
 	rootCmd.PersistentFlags().IntVar(
@@ -229,6 +241,41 @@ func main() {
 		"S3 endpoint to use",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&opts.ConnectOpts.GCSEndpoint,
+		"gcs-endpoint",
+		"",
+		"Google Cloud Storage endpoint to use",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.ConnectOpts.AzureAccount,
+		"azure-account",
+		"",
+		"Azure storage account to connect to (default $AZURE_STORAGE_ACCOUNT)",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.ConnectOpts.AzureEndpoint,
+		"azure-endpoint",
+		"",
+		"Azure Blob Storage endpoint to use",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.ConnectOpts.Cache.Path,
+		"cache-path",
+		"",
+		"directory where files read from the source archive are cached (disabled if empty)",
+	)
+
+	rootCmd.PersistentFlags().Int64Var(
+		&opts.CacheSize,
+		"cache-size",
+		1024,
+		"maximum size in MB of the cache",
+	)
+
 	rootCmd.PersistentFlags().BoolVarP(
 		&opts.CommandOpts.DryRun,
 		"dryrun",
@@ -333,6 +380,27 @@ func main() {
 		},
 	})
 
+	var popts PublishOptions
+	publishCmd := &cobra.Command{
+		Use:   "publish",
+		Short: "write the checkpoints of an archive from the ledgers of a ledger backend",
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.SetupLogging()
+			opts.MaybeProfile()
+			publish(firstArg(args), &opts, &popts)
+		},
+	}
+	publishCmd.Flags().StringVar(&popts.NetworkPassphrase, "network-passphrase", "", "network passphrase of the ledgers")
+	publishCmd.Flags().StringVar(&popts.StellarCoreDBURL, "stellar-core-db-url", "", "stellar-core database to read the ledgers from")
+	publishCmd.Flags().StringVar(&popts.RemoteCaptiveCoreURL, "remote-captive-core-url", "", "remote captive core server to read the ledgers from")
+	publishCmd.Flags().StringVar(&popts.CaptiveCoreBinaryPath, "captive-core-binary-path", "", "stellar-core binary to run captive core with, to read the ledgers from")
+	publishCmd.Flags().StringVar(&popts.CaptiveCoreConfigPath, "captive-core-config-path", "", "captive core config file")
+	publishCmd.Flags().StringSliceVar(&popts.HistoryArchiveURLs, "history-archive-urls", nil, "history archives captive core catches up from")
+	publishCmd.Flags().StringVar(&popts.BucketSource, "bucket-source", "", "archive which the bucket list of checkpoints and buckets are copied from")
+	publishCmd.Flags().BoolVar(&popts.WithoutBuckets, "without-buckets", false, "publish checkpoints with an empty bucket list when --bucket-source is not set")
+	publishCmd.Flags().StringVar(&popts.Server, "server", "stellar-archivist", "server of the published HAS")
+	rootCmd.AddCommand(publishCmd)
+
 	rootCmd.AddCommand(&cobra.Command{
 		Use: "dumpxdr",
 		Run: func(cmd *cobra.Command, args []string) {
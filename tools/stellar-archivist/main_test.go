@@ -46,3 +46,49 @@ func TestRecentOption(t *testing.T) {
 	assert.Equal(t, uint32(0x3f), opts.CommandOpts.Range.Low)
 	assert.Equal(t, uint32(0xbf), opts.CommandOpts.Range.High)
 }
+
+func TestPublishRange(t *testing.T) {
+	from, to, err := publishRange(0, 0xffffffff)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), from)
+	assert.Equal(t, uint32(0xffffffff), to)
+
+	from, to, err = publishRange(100, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(64), from)
+	assert.Equal(t, uint32(191), to)
+
+	_, _, err = publishRange(100, 120)
+	assert.EqualError(t, err, "no complete checkpoint between ledger 64 and ledger 63")
+}
+
+func TestPublishOptionsValidate(t *testing.T) {
+	popts := PublishOptions{
+		NetworkPassphrase:    "test",
+		RemoteCaptiveCoreURL: "http://localhost:8000",
+		BucketSource:         "file://archive",
+	}
+	assert.NoError(t, popts.validate(64))
+	assert.EqualError(
+		t,
+		popts.validate(1),
+		"captive core cannot stream the genesis ledger, set --low to 64 or later "+
+			"or publish the first checkpoint from --stellar-core-db-url",
+	)
+
+	popts.StellarCoreDBURL = "postgres://localhost/core"
+	assert.NoError(t, popts.validate(1))
+
+	popts.BucketSource = ""
+	assert.EqualError(
+		t,
+		popts.validate(64),
+		"--bucket-source must be set to publish the bucket list of checkpoints, "+
+			"or --without-buckets to publish checkpoints which can only be used to replay ledgers",
+	)
+	popts.WithoutBuckets = true
+	assert.NoError(t, popts.validate(64))
+
+	popts.NetworkPassphrase = ""
+	assert.EqualError(t, popts.validate(64), "--network-passphrase must be set")
+}
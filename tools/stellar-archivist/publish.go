@@ -0,0 +1,151 @@
+// Copyright 2016 Stellar Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package main
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/errors"
+)
+
+// PublishOptions are the options of the publish command, which writes the
+// checkpoints of an archive from the ledgers of a ledger backend.
+type PublishOptions struct {
+	NetworkPassphrase     string
+	StellarCoreDBURL      string
+	RemoteCaptiveCoreURL  string
+	CaptiveCoreBinaryPath string
+	CaptiveCoreConfigPath string
+	HistoryArchiveURLs    []string
+	BucketSource          string
+	WithoutBuckets        bool
+	Server                string
+}
+
+// validate checks the options of a publication starting at ledger from.
+func (popts *PublishOptions) validate(from uint32) error {
+	if popts.NetworkPassphrase == "" {
+		return errors.New("--network-passphrase must be set")
+	}
+	if popts.BucketSource == "" && !popts.WithoutBuckets {
+		return errors.New("--bucket-source must be set to publish the bucket list of checkpoints, " +
+			"or --without-buckets to publish checkpoints which can only be used to replay ledgers")
+	}
+	if from < 2 && popts.StellarCoreDBURL == "" {
+		return errors.Errorf("captive core cannot stream the genesis ledger, set --low to %d or later "+
+			"or publish the first checkpoint from --stellar-core-db-url", checkpointFrequency)
+	}
+	return nil
+}
+
+func (popts *PublishOptions) ledgerBackend(ctx context.Context) (ledgerbackend.LedgerBackend, error) {
+	switch {
+	case popts.StellarCoreDBURL != "":
+		return ledgerbackend.NewDatabaseBackend(popts.StellarCoreDBURL, popts.NetworkPassphrase)
+	case popts.RemoteCaptiveCoreURL != "":
+		backend, err := ledgerbackend.NewRemoteCaptive(popts.RemoteCaptiveCoreURL)
+		return &backend, err
+	case popts.CaptiveCoreBinaryPath != "":
+		if len(popts.HistoryArchiveURLs) == 0 {
+			return nil, errors.New("--history-archive-urls must be set to run captive core")
+		}
+		params := ledgerbackend.CaptiveCoreTomlParams{
+			NetworkPassphrase:  popts.NetworkPassphrase,
+			HistoryArchiveURLs: popts.HistoryArchiveURLs,
+		}
+		var toml *ledgerbackend.CaptiveCoreToml
+		var err error
+		if popts.CaptiveCoreConfigPath != "" {
+			toml, err = ledgerbackend.NewCaptiveCoreTomlFromFile(popts.CaptiveCoreConfigPath, params)
+		} else {
+			toml, err = ledgerbackend.NewCaptiveCoreToml(params)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid captive core config")
+		}
+		return ledgerbackend.NewCaptive(ledgerbackend.CaptiveCoreConfig{
+			BinaryPath:          popts.CaptiveCoreBinaryPath,
+			NetworkPassphrase:   popts.NetworkPassphrase,
+			HistoryArchiveURLs:  popts.HistoryArchiveURLs,
+			Toml:                toml,
+			CheckpointFrequency: checkpointFrequency,
+			Context:             ctx,
+		})
+	default:
+		return nil, errors.New("one of --stellar-core-db-url, --remote-captive-core-url or --captive-core-binary-path must be set")
+	}
+}
+
+// publishRange returns the first and last ledgers of the complete checkpoints
+// between low and high. The last ledger is left unset when high is not set,
+// to publish the ledgers as they are closed.
+func publishRange(low int, high uint32) (uint32, uint32, error) {
+	checkpointMgr := historyarchive.NewCheckpointManager(checkpointFrequency)
+	from := checkpointMgr.GetCheckpointRange(uint32(low)).Low
+	if high == 0xffffffff {
+		return from, high, nil
+	}
+	if !checkpointMgr.IsCheckpoint(high) {
+		high = checkpointMgr.PrevCheckpoint(high)
+	}
+	if high < from {
+		return 0, 0, errors.Errorf("no complete checkpoint between ledger %d and ledger %d", from, high)
+	}
+	return from, high, nil
+}
+
+func publish(dst string, opts *Options, popts *PublishOptions) {
+	from, to, err := publishRange(opts.Low, opts.High)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = popts.validate(from); err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+
+	connectOpts := opts.DestinationConnectOpts()
+	connectOpts.NetworkPassphrase = popts.NetworkPassphrase
+	dstArch := historyarchive.MustConnect(dst, connectOpts)
+	writerOpts := historyarchive.CheckpointWriterOptions{
+		Server:         popts.Server,
+		WithoutBuckets: popts.WithoutBuckets,
+		CommandOptions: &opts.CommandOpts,
+	}
+	if popts.BucketSource != "" {
+		connectOpts = opts.ConnectOpts
+		connectOpts.NetworkPassphrase = popts.NetworkPassphrase
+		writerOpts.BucketSource = historyarchive.MustConnect(popts.BucketSource, connectOpts)
+	}
+	writer := historyarchive.NewCheckpointWriter(dstArch, writerOpts)
+
+	ledgerRange := ledgerbackend.BoundedRange(from, to)
+	if to == 0xffffffff {
+		ledgerRange = ledgerbackend.UnboundedRange(from)
+	}
+	backend, err := popts.ledgerBackend(ctx)
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "could not create ledger backend"))
+	}
+	defer backend.Close()
+
+	log.Printf("publishing %v -> %v\n", ledgerRange, dst)
+	if err = backend.PrepareRange(ctx, ledgerRange); err != nil {
+		log.Fatal(errors.Wrap(err, "could not prepare range"))
+	}
+	for seq := from; seq <= to; seq++ {
+		meta, err := backend.GetLedger(ctx, seq)
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "could not get ledger %d", seq))
+		}
+		if err = writer.AddLedger(meta); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package txnbuild
+
+import (
+	"math"
+	"math/bits"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/support/errors"
+	"github.com/stellar/go/xdr"
+)
+
+// BasisPointsDivisor is the divisor applied to a ledger's base percentage fee. Kinesis
+// ledgers express `BasePercentageFee` in basis points, so a value of 45 means 0.45%.
+const BasisPointsDivisor = 10000
+
+// FeeEstimator computes the minimum fee a Kinesis network will accept for a transaction.
+// On Kinesis the minimum fee is the flat base fee multiplied by the number of operations
+// plus a percentage of the native amount moved by Payment, CreateAccount, PathPayment*
+// and AccountMerge operations.
+//
+// BaseFee and BasePercentageFee should be populated from the most recent ledger, e.g.
+// `base_fee_in_stroops` and `base_percentage_fee` in Horizon's ledger resource.
+type FeeEstimator struct {
+	// BaseFee is the flat fee per operation in stroops.
+	BaseFee int64
+	// BasePercentageFee is the percentage fee in basis points.
+	BasePercentageFee int64
+	// AccountMergeBalances maps the address (G...) of an account which is merged by an
+	// AccountMerge operation to the native balance it will transfer. An AccountMerge
+	// operation does not carry an amount, so its percentage fee can only be computed
+	// when the balance is known.
+	AccountMergeBalances map[string]string
+}
+
+// MinFee returns the minimum total fee, in stroops, for a transaction with the given source
+// account and operations.
+func (fe FeeEstimator) MinFee(sourceAccount string, ops []Operation) (int64, error) {
+	if fe.BaseFee < 0 {
+		return 0, errors.New("base fee cannot be negative")
+	}
+	if fe.BasePercentageFee < 0 {
+		return 0, errors.New("base percentage fee cannot be negative")
+	}
+	if len(ops) == 0 {
+		return 0, errors.New("transaction has no operations")
+	}
+
+	hi, flatFee := bits.Mul64(uint64(fe.BaseFee), uint64(len(ops)))
+	if hi > 0 || flatFee > math.MaxInt64 {
+		return 0, errors.Errorf("base fee %d results in an overflow of min fee", fe.BaseFee)
+	}
+
+	var transferred int64
+	for _, op := range ops {
+		opAmount, err := fe.nativeAmount(sourceAccount, op)
+		if err != nil {
+			return 0, errors.Wrapf(err, "could not compute percentage fee for %T operation", op)
+		}
+		if transferred > math.MaxInt64-opAmount {
+			return 0, errors.New("total transferred amount overflows int64")
+		}
+		transferred += opAmount
+	}
+
+	percentageFee, err := fe.PercentageFee(transferred)
+	if err != nil {
+		return 0, err
+	}
+	if int64(flatFee) > math.MaxInt64-percentageFee {
+		return 0, errors.New("min fee overflows int64")
+	}
+	return int64(flatFee) + percentageFee, nil
+}
+
+// PercentageFee returns the percentage fee, in stroops, charged for transferring
+// the given amount of stroops. The result is rounded up so that it is never below
+// the fee computed by the network.
+func (fe FeeEstimator) PercentageFee(stroops int64) (int64, error) {
+	if stroops < 0 {
+		return 0, errors.New("amount cannot be negative")
+	}
+	if fe.BasePercentageFee < 0 {
+		return 0, errors.New("base percentage fee cannot be negative")
+	}
+
+	hi, lo := bits.Mul64(uint64(stroops), uint64(fe.BasePercentageFee))
+	if hi >= BasisPointsDivisor {
+		return 0, errors.New("percentage fee overflows int64")
+	}
+	quo, rem := bits.Div64(hi, lo, BasisPointsDivisor)
+	if rem > 0 {
+		quo++
+	}
+	if quo > math.MaxInt64 {
+		return 0, errors.New("percentage fee overflows int64")
+	}
+	return int64(quo), nil
+}
+
+// TransactionBaseFee returns the smallest per operation fee which, when used as
+// TransactionParams.BaseFee, results in a transaction whose total fee is at least MinFee.
+func (fe FeeEstimator) TransactionBaseFee(sourceAccount string, ops []Operation) (int64, error) {
+	minFee, err := fe.MinFee(sourceAccount, ops)
+	if err != nil {
+		return 0, err
+	}
+	return divideRoundingUp(minFee, int64(len(ops))), nil
+}
+
+// FeeBumpBaseFee returns the smallest per operation fee which, when used as
+// FeeBumpTransactionParams.BaseFee, covers the minimum fee of the inner transaction.
+// A fee bump transaction counts as one more operation than its inner transaction.
+func (fe FeeEstimator) FeeBumpBaseFee(inner *Transaction) (int64, error) {
+	if inner == nil {
+		return 0, errors.New("inner transaction is missing")
+	}
+	ops := inner.Operations()
+	minFee, err := fe.MinFee(inner.SourceAccount().AccountID, ops)
+	if err != nil {
+		return 0, err
+	}
+	// The extra operation of the fee bump only pays the flat base fee.
+	if minFee > math.MaxInt64-fe.BaseFee {
+		return 0, errors.New("min fee overflows int64")
+	}
+	baseFee := divideRoundingUp(minFee+fe.BaseFee, int64(len(ops)+1))
+	if baseFee < MinBaseFee {
+		baseFee = MinBaseFee
+	}
+	if innerBaseFee := inner.BaseFee(); baseFee < innerBaseFee {
+		baseFee = innerBaseFee
+	}
+	return baseFee, nil
+}
+
+// nativeAmount returns the amount of native asset, in stroops, transferred by op.
+func (fe FeeEstimator) nativeAmount(sourceAccount string, op Operation) (int64, error) {
+	switch o := op.(type) {
+	case *Payment:
+		if o.Asset == nil || !o.Asset.IsNative() {
+			return 0, nil
+		}
+		return amount.ParseInt64(o.Amount)
+	case *CreateAccount:
+		return amount.ParseInt64(o.Amount)
+	case *PathPaymentStrictReceive:
+		if o.SendAsset == nil || !o.SendAsset.IsNative() {
+			return 0, nil
+		}
+		return amount.ParseInt64(o.SendMax)
+	case *PathPaymentStrictSend:
+		if o.SendAsset == nil || !o.SendAsset.IsNative() {
+			return 0, nil
+		}
+		return amount.ParseInt64(o.SendAmount)
+	case *AccountMerge:
+		merged := o.SourceAccount
+		if merged == "" {
+			merged = sourceAccount
+		}
+		muxed, err := xdr.AddressToMuxedAccount(merged)
+		if err != nil {
+			return 0, errors.Wrap(err, "invalid merged account address")
+		}
+		accountID := muxed.ToAccountId()
+		balance, ok := fe.AccountMergeBalances[accountID.Address()]
+		if !ok {
+			return 0, errors.Errorf("balance of merged account %s is unknown", accountID.Address())
+		}
+		return amount.ParseInt64(balance)
+	default:
+		return 0, nil
+	}
+}
+
+func divideRoundingUp(n, d int64) int64 {
+	q := n / d
+	if n%d != 0 {
+		q++
+	}
+	return q
+}
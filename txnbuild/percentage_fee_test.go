@@ -0,0 +1,145 @@
+package txnbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeEstimatorFlatFeeOnly(t *testing.T) {
+	fe := FeeEstimator{BaseFee: 100, BasePercentageFee: 45}
+	source := newKeypair0().Address()
+
+	fee, err := fe.MinFee(source, []Operation{&Inflation{}, &BumpSequence{BumpTo: 10}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), fee)
+}
+
+func TestFeeEstimatorPayments(t *testing.T) {
+	fe := FeeEstimator{BaseFee: 100, BasePercentageFee: 45}
+	source := newKeypair0().Address()
+	dest := newKeypair1().Address()
+	credit := CreditAsset{"KAU", newKeypair2().Address()}
+
+	ops := []Operation{
+		// 1000 native => 4.5 native => 45000000 stroops
+		&Payment{Destination: dest, Amount: "1000", Asset: NativeAsset{}},
+		// credit payments do not pay a percentage fee
+		&Payment{Destination: dest, Amount: "1000", Asset: credit},
+		// 10 native => 0.045 native => 450000 stroops
+		&CreateAccount{Destination: dest, Amount: "10"},
+		// 100 native => 0.45 native => 4500000 stroops
+		&PathPaymentStrictReceive{
+			SendAsset:   NativeAsset{},
+			SendMax:     "100",
+			Destination: dest,
+			DestAsset:   credit,
+			DestAmount:  "1",
+		},
+		// 1 native => 0.0045 native => 45000 stroops
+		&PathPaymentStrictSend{
+			SendAsset:   NativeAsset{},
+			SendAmount:  "1",
+			Destination: dest,
+			DestAsset:   credit,
+			DestMin:     "1",
+		},
+	}
+
+	fee, err := fe.MinFee(source, ops)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5*100+45000000+450000+4500000+45000), fee)
+
+	baseFee, err := fe.TransactionBaseFee(source, ops)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9999100), baseFee)
+
+	tx, err := NewTransaction(TransactionParams{
+		SourceAccount: &SimpleAccount{AccountID: source, Sequence: 1},
+		Operations:    ops,
+		BaseFee:       baseFee,
+		Timebounds:    NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, tx.MaxFee(), fee)
+}
+
+func TestFeeEstimatorRoundsUp(t *testing.T) {
+	fe := FeeEstimator{BaseFee: 100, BasePercentageFee: 45}
+	fee, err := fe.PercentageFee(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), fee)
+
+	fee, err = fe.PercentageFee(0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), fee)
+}
+
+func TestFeeEstimatorAccountMerge(t *testing.T) {
+	source := newKeypair0().Address()
+	merged := newKeypair1().Address()
+	ops := []Operation{
+		&AccountMerge{Destination: newKeypair2().Address(), SourceAccount: merged},
+	}
+
+	fe := FeeEstimator{BaseFee: 100, BasePercentageFee: 45}
+	_, err := fe.MinFee(source, ops)
+	assert.EqualError(
+		t,
+		err,
+		"could not compute percentage fee for *txnbuild.AccountMerge operation: balance of merged account "+merged+" is unknown",
+	)
+
+	fe.AccountMergeBalances = map[string]string{merged: "200"}
+	fee, err := fe.MinFee(source, ops)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100+9000000), fee)
+
+	// the transaction source account is merged when the operation has no source account
+	fe.AccountMergeBalances = map[string]string{source: "200"}
+	fee, err = fe.MinFee(source, []Operation{&AccountMerge{Destination: merged}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100+9000000), fee)
+}
+
+func TestFeeEstimatorInvalidParams(t *testing.T) {
+	source := newKeypair0().Address()
+
+	_, err := FeeEstimator{BaseFee: -1}.MinFee(source, []Operation{&Inflation{}})
+	assert.EqualError(t, err, "base fee cannot be negative")
+
+	_, err = FeeEstimator{BasePercentageFee: -1}.MinFee(source, []Operation{&Inflation{}})
+	assert.EqualError(t, err, "base percentage fee cannot be negative")
+
+	_, err = FeeEstimator{BaseFee: 100}.MinFee(source, nil)
+	assert.EqualError(t, err, "transaction has no operations")
+}
+
+func TestFeeEstimatorFeeBump(t *testing.T) {
+	source := NewSimpleAccount(newKeypair0().Address(), 1)
+	fe := FeeEstimator{BaseFee: 100, BasePercentageFee: 45}
+	ops := []Operation{
+		&Payment{Destination: newKeypair1().Address(), Amount: "1000", Asset: NativeAsset{}},
+	}
+
+	inner, err := NewTransaction(TransactionParams{
+		SourceAccount: &source,
+		Operations:    ops,
+		BaseFee:       MinBaseFee,
+		Timebounds:    NewInfiniteTimeout(),
+	})
+	require.NoError(t, err)
+
+	baseFee, err := fe.FeeBumpBaseFee(inner)
+	require.NoError(t, err)
+	assert.Equal(t, int64(22500100), baseFee)
+
+	feeBump, err := NewFeeBumpTransaction(FeeBumpTransactionParams{
+		Inner:      inner,
+		FeeAccount: newKeypair2().Address(),
+		BaseFee:    baseFee,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(45000200), feeBump.MaxFee())
+}